@@ -1,4 +1,4 @@
-// Copyright 2025 EMQ Technologies Co., Ltd.
+// Copyright 2025-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -21,7 +21,9 @@ import (
 )
 
 const (
-	LblTarget = "target"
+	LblTarget    = "target"
+	LblTopic     = "topic"
+	LblPartition = "partition"
 )
 
 var (
@@ -53,6 +55,13 @@ var (
 		Name:      "gauge",
 		Help:      "Gauge of Kafka Source IO",
 	}, []string{metrics.LblType, metrics.LblRuleIDType, metrics.LblOpIDType})
+
+	KafkaSourcePartitionLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "kuiper",
+		Subsystem: "kafka_source",
+		Name:      "partition_lag",
+		Help:      "Lag of each partition consumed by Kafka Source",
+	}, []string{metrics.LblRuleIDType, metrics.LblOpIDType, LblTopic, LblPartition})
 )
 
 func init() {
@@ -60,4 +69,5 @@ func init() {
 	prometheus.MustRegister(KafkaSinkCollectDurationHist)
 	prometheus.MustRegister(KafkaSourceCounter)
 	prometheus.MustRegister(KafkaSourceGauge)
+	prometheus.MustRegister(KafkaSourcePartitionLag)
 }
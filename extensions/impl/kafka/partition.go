@@ -0,0 +1,528 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/protocol"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+const (
+	StartModeEarliest  = "earliest"
+	StartModeLatest    = "latest"
+	StartModeTimestamp = "timestamp"
+	StartModeCommitted = "committed"
+)
+
+const (
+	fetchRetryInterval = time.Second
+	fetchMaxWait       = 500 * time.Millisecond
+	commitInterval     = 5 * time.Second
+)
+
+// partitionClient is the part of the kafka client to consume the partitions
+type partitionClient interface {
+	Metadata(ctx context.Context, req *kafkago.MetadataRequest) (*kafkago.MetadataResponse, error)
+	ListOffsets(ctx context.Context, req *kafkago.ListOffsetsRequest) (*kafkago.ListOffsetsResponse, error)
+	Fetch(ctx context.Context, req *kafkago.FetchRequest) (*kafkago.FetchResponse, error)
+	OffsetFetch(ctx context.Context, req *kafkago.OffsetFetchRequest) (*kafkago.OffsetFetchResponse, error)
+	OffsetCommit(ctx context.Context, req *kafkago.OffsetCommitRequest) (*kafkago.OffsetCommitResponse, error)
+}
+
+// fetchedMsg is a record or an error from the partition fetchers
+type fetchedMsg struct {
+	tp            topicPartition
+	offset        int64
+	highWatermark int64
+	key           []byte
+	value         []byte
+	err           error
+}
+
+// partitionConsumer consumes all partitions of the topics. Each partition has a fetcher to read from its next offset,
+// and the offsets are only advanced after the records are ingested, so that they can be saved in the checkpoint.
+type partitionConsumer struct {
+	sc      *kafkaSourceConf
+	client  partitionClient
+	topicRe *regexp.Regexp
+	msgCh   chan fetchedMsg
+
+	mu sync.Mutex
+	// next offset to consume of each partition
+	offsets map[topicPartition]int64
+	// the partitions with running fetcher
+	fetching map[topicPartition]struct{}
+	// the last committed offsets of the group
+	committed map[topicPartition]int64
+}
+
+func newPartitionConsumer(sc *kafkaSourceConf, client partitionClient) (*partitionConsumer, error) {
+	c := &partitionConsumer{
+		sc:        sc,
+		client:    client,
+		msgCh:     make(chan fetchedMsg, 1024),
+		offsets:   make(map[topicPartition]int64),
+		fetching:  make(map[topicPartition]struct{}),
+		committed: make(map[topicPartition]int64),
+	}
+	if sc.TopicRegex {
+		re, err := regexp.Compile("^(?:" + sc.Topic + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid topic regex %s: %v", sc.Topic, err)
+		}
+		c.topicRe = re
+	}
+	return c, nil
+}
+
+// init resolves the partitions and their start offsets by the start mode
+func (c *partitionConsumer) init(ctx context.Context) error {
+	tps, err := c.partitions(ctx)
+	if err != nil {
+		return err
+	}
+	if len(tps) == 0 {
+		return fmt.Errorf("no partition found for topic %s", c.sc.Topic)
+	}
+	offsets, err := c.startOffsets(ctx, tps, c.sc.StartMode)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.offsets = offsets
+	c.mu.Unlock()
+	return nil
+}
+
+// partitions lists the partitions of the topic or the topics matched by the regex
+func (c *partitionConsumer) partitions(ctx context.Context) ([]topicPartition, error) {
+	req := &kafkago.MetadataRequest{}
+	if c.topicRe == nil {
+		req.Topics = []string{c.sc.Topic}
+	}
+	meta, err := c.client.Metadata(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("get metadata error: %v", err)
+	}
+	var tps []topicPartition
+	for _, t := range meta.Topics {
+		if c.topicRe != nil && (t.Internal || !c.topicRe.MatchString(t.Name)) {
+			continue
+		}
+		if t.Error != nil {
+			return nil, fmt.Errorf("get metadata of topic %s error: %v", t.Name, t.Error)
+		}
+		for _, p := range t.Partitions {
+			tps = append(tps, topicPartition{topic: t.Name, partition: p.ID})
+		}
+	}
+	sort.Slice(tps, func(i, j int) bool {
+		if tps[i].topic != tps[j].topic {
+			return tps[i].topic < tps[j].topic
+		}
+		return tps[i].partition < tps[j].partition
+	})
+	return tps, nil
+}
+
+func (c *partitionConsumer) startOffsets(ctx context.Context, tps []topicPartition, mode string) (map[topicPartition]int64, error) {
+	offsets := make(map[topicPartition]int64, len(tps))
+	var rest []topicPartition
+	if mode == StartModeCommitted {
+		committed, err := c.committedOffsets(ctx, tps)
+		if err != nil {
+			return nil, err
+		}
+		for _, tp := range tps {
+			if o, ok := committed[tp]; ok && o >= 0 {
+				offsets[tp] = o
+			} else {
+				rest = append(rest, tp)
+			}
+		}
+		// the partitions without committed offset start from the latest like the kafka consumer
+		mode = StartModeLatest
+	} else {
+		rest = tps
+	}
+	if len(rest) == 0 {
+		return offsets, nil
+	}
+	topics := make(map[string][]kafkago.OffsetRequest)
+	for _, tp := range rest {
+		var r kafkago.OffsetRequest
+		switch mode {
+		case StartModeEarliest:
+			r = kafkago.FirstOffsetOf(tp.partition)
+		case StartModeTimestamp:
+			r = kafkago.TimeOffsetOf(tp.partition, time.UnixMilli(c.sc.StartTimestamp))
+		default:
+			r = kafkago.LastOffsetOf(tp.partition)
+		}
+		topics[tp.topic] = append(topics[tp.topic], r)
+	}
+	resp, err := c.client.ListOffsets(ctx, &kafkago.ListOffsetsRequest{Topics: topics})
+	if err != nil {
+		return nil, fmt.Errorf("list offsets error: %v", err)
+	}
+	var latest []topicPartition
+	for topic, partitions := range resp.Topics {
+		for _, p := range partitions {
+			tp := topicPartition{topic: topic, partition: p.Partition}
+			if p.Error != nil {
+				return nil, fmt.Errorf("list offsets of topic %s partition %d error: %v", topic, p.Partition, p.Error)
+			}
+			switch mode {
+			case StartModeEarliest:
+				offsets[tp] = p.FirstOffset
+			case StartModeTimestamp:
+				found := false
+				for o := range p.Offsets {
+					if o >= 0 {
+						offsets[tp] = o
+						found = true
+					}
+				}
+				// no record after the timestamp
+				if !found {
+					latest = append(latest, tp)
+				}
+			default:
+				offsets[tp] = p.LastOffset
+			}
+		}
+	}
+	if len(latest) > 0 {
+		lo, err := c.startOffsets(ctx, latest, StartModeLatest)
+		if err != nil {
+			return nil, err
+		}
+		for tp, o := range lo {
+			offsets[tp] = o
+		}
+	}
+	return offsets, nil
+}
+
+func (c *partitionConsumer) committedOffsets(ctx context.Context, tps []topicPartition) (map[topicPartition]int64, error) {
+	topics := make(map[string][]int)
+	for _, tp := range tps {
+		topics[tp.topic] = append(topics[tp.topic], tp.partition)
+	}
+	resp, err := c.client.OffsetFetch(ctx, &kafkago.OffsetFetchRequest{GroupID: c.sc.GroupID, Topics: topics})
+	if err == nil {
+		err = resp.Error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetch committed offsets of group %s error: %v", c.sc.GroupID, err)
+	}
+	result := make(map[topicPartition]int64)
+	for topic, partitions := range resp.Topics {
+		for _, p := range partitions {
+			if p.Error == nil {
+				result[topicPartition{topic: topic, partition: p.Partition}] = p.CommittedOffset
+			}
+		}
+	}
+	return result, nil
+}
+
+// run starts the fetchers and refreshes the partitions periodically to consume the new partitions and topics
+func (c *partitionConsumer) run(ctx context.Context, logger api.Logger) {
+	c.mu.Lock()
+	for tp, offset := range c.offsets {
+		c.startFetcher(ctx, tp, offset)
+	}
+	c.mu.Unlock()
+	if c.sc.MetadataRefreshInterval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(c.sc.MetadataRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := c.refresh(ctx, logger); err != nil {
+					logger.Warnf("refresh kafka partitions error: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+func (c *partitionConsumer) refresh(ctx context.Context, logger api.Logger) error {
+	tps, err := c.partitions(ctx)
+	if err != nil {
+		return err
+	}
+	var added []topicPartition
+	c.mu.Lock()
+	for _, tp := range tps {
+		if _, ok := c.fetching[tp]; !ok {
+			added = append(added, tp)
+		}
+	}
+	c.mu.Unlock()
+	if len(added) == 0 {
+		return nil
+	}
+	// the new partitions are consumed from the beginning to not lose the records written before discovered
+	offsets, err := c.startOffsets(ctx, added, StartModeEarliest)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for tp, offset := range offsets {
+		logger.Infof("start consuming new kafka topic %s partition %d", tp.topic, tp.partition)
+		c.offsets[tp] = offset
+		c.startFetcher(ctx, tp, offset)
+	}
+	return nil
+}
+
+// startFetcher must be called with the lock
+func (c *partitionConsumer) startFetcher(ctx context.Context, tp topicPartition, offset int64) {
+	if _, ok := c.fetching[tp]; ok {
+		return
+	}
+	c.fetching[tp] = struct{}{}
+	go c.fetch(ctx, tp, offset)
+}
+
+func (c *partitionConsumer) fetch(ctx context.Context, tp topicPartition, offset int64) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		resp, err := c.client.Fetch(ctx, &kafkago.FetchRequest{
+			Topic:     tp.topic,
+			Partition: tp.partition,
+			Offset:    offset,
+			MinBytes:  1,
+			MaxBytes:  int64(c.sc.MaxBytes),
+			MaxWait:   fetchMaxWait,
+		})
+		if err == nil && resp.Error != nil {
+			err = resp.Error
+		}
+		if errors.Is(err, kafkago.OffsetOutOfRange) {
+			// the records are deleted by retention, restart from the earliest available one
+			offsets, lerr := c.startOffsets(ctx, []topicPartition{tp}, StartModeEarliest)
+			if lerr == nil {
+				offset = offsets[tp]
+				continue
+			}
+			err = lerr
+		}
+		if err != nil {
+			if !c.send(ctx, fetchedMsg{tp: tp, err: fmt.Errorf("fetch topic %s partition %d error: %v", tp.topic, tp.partition, err)}) {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(fetchRetryInterval):
+			}
+			continue
+		}
+		err = readRecords(resp.Records, func(r *protocol.Record) error {
+			// the batch may start before the requested offset
+			if r.Offset < offset {
+				return nil
+			}
+			msg := fetchedMsg{tp: tp, offset: r.Offset, highWatermark: resp.HighWatermark}
+			var err error
+			if r.Key != nil {
+				if msg.key, err = protocol.ReadAll(r.Key); err != nil {
+					return err
+				}
+			}
+			if r.Value != nil {
+				if msg.value, err = protocol.ReadAll(r.Value); err != nil {
+					return err
+				}
+			}
+			if !c.send(ctx, msg) {
+				return ctx.Err()
+			}
+			offset = r.Offset + 1
+			return nil
+		})
+		if err != nil && ctx.Err() == nil {
+			c.send(ctx, fetchedMsg{tp: tp, err: fmt.Errorf("read topic %s partition %d error: %v", tp.topic, tp.partition, err)})
+		}
+	}
+}
+
+func (c *partitionConsumer) send(ctx context.Context, msg fetchedMsg) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case c.msgCh <- msg:
+		return true
+	}
+}
+
+// readRecords reads the records of the fetch response. The control batches of the transactions are skipped.
+func readRecords(records kafkago.RecordReader, f func(r *protocol.Record) error) error {
+	if records == nil {
+		return nil
+	}
+	if rs, ok := records.(*protocol.RecordStream); ok {
+		for _, batch := range rs.Records {
+			if _, isControl := batch.(*protocol.ControlBatch); isControl {
+				continue
+			}
+			if err := readRecords(batch, f); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for {
+		r, err := records.ReadRecord()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := f(r); err != nil {
+			return err
+		}
+	}
+}
+
+// consumed advances the offset after the record is ingested and returns the lag of the partition
+func (c *partitionConsumer) consumed(msg fetchedMsg) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.offsets[msg.tp] = msg.offset + 1
+	return max(msg.highWatermark-msg.offset-1, 0)
+}
+
+// getOffsets returns the next offsets of all partitions as the state, keyed by topic:partition
+func (c *partitionConsumer) getOffsets() map[string]any {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result := make(map[string]any, len(c.offsets))
+	for tp, o := range c.offsets {
+		result[fmt.Sprintf("%s:%d", tp.topic, tp.partition)] = o
+	}
+	return result
+}
+
+// rewind restores the offsets of the checkpoint. The partitions not in the checkpoint keep the start offsets.
+func (c *partitionConsumer) rewind(offset any) error {
+	offsets, err := parsePartitionOffsets(offset)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for tp, o := range offsets {
+		c.offsets[tp] = o
+	}
+	return nil
+}
+
+// commit commits the offsets to the consumer group. Only the changed offsets are committed.
+func (c *partitionConsumer) commit(ctx context.Context, offsets map[topicPartition]int64) error {
+	if c.sc.GroupID == "" {
+		return nil
+	}
+	topics := make(map[string][]kafkago.OffsetCommit)
+	for tp, o := range offsets {
+		if co, ok := c.committed[tp]; ok && co == o {
+			continue
+		}
+		topics[tp.topic] = append(topics[tp.topic], kafkago.OffsetCommit{Partition: tp.partition, Offset: o})
+	}
+	if len(topics) == 0 {
+		return nil
+	}
+	resp, err := c.client.OffsetCommit(ctx, &kafkago.OffsetCommitRequest{
+		GroupID:      c.sc.GroupID,
+		GenerationID: -1,
+		Topics:       topics,
+	})
+	if err != nil {
+		return fmt.Errorf("commit offsets to group %s error: %v", c.sc.GroupID, err)
+	}
+	for topic, partitions := range resp.Topics {
+		for _, p := range partitions {
+			if p.Error != nil {
+				return fmt.Errorf("commit offset of topic %s partition %d error: %v", topic, p.Partition, p.Error)
+			}
+			tp := topicPartition{topic: topic, partition: p.Partition}
+			c.committed[tp] = offsets[tp]
+		}
+	}
+	return nil
+}
+
+func (c *partitionConsumer) currentOffsets() map[topicPartition]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result := make(map[topicPartition]int64, len(c.offsets))
+	for tp, o := range c.offsets {
+		result[tp] = o
+	}
+	return result
+}
+
+func parsePartitionOffsets(offset any) (map[topicPartition]int64, error) {
+	m, ok := offset.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%v can't be set as partition offsets", offset)
+	}
+	result := make(map[topicPartition]int64, len(m))
+	for k, v := range m {
+		i := strings.LastIndex(k, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid partition %s", k)
+		}
+		p, err := strconv.Atoi(k[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid partition %s", k)
+		}
+		o, err := cast.ToInt64(v, cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return nil, fmt.Errorf("invalid offset %v of partition %s", v, k)
+		}
+		result[topicPartition{topic: k[:i], partition: p}] = o
+	}
+	return result, nil
+}
@@ -0,0 +1,379 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/protocol"
+	"github.com/stretchr/testify/require"
+
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+// mockPartitionClient serves the records of each partition. The offset of a record is its index and the timestamp
+// of the record is (offset+1) seconds.
+type mockPartitionClient struct {
+	sync.Mutex
+	records   map[topicPartition][]string
+	committed map[topicPartition]int64
+}
+
+func (m *mockPartitionClient) Metadata(_ context.Context, req *kafkago.MetadataRequest) (*kafkago.MetadataResponse, error) {
+	m.Lock()
+	defer m.Unlock()
+	topics := make(map[string][]kafkago.Partition)
+	for tp := range m.records {
+		topics[tp.topic] = append(topics[tp.topic], kafkago.Partition{Topic: tp.topic, ID: tp.partition})
+	}
+	resp := &kafkago.MetadataResponse{}
+	if req.Topics == nil {
+		resp.Topics = append(resp.Topics, kafkago.Topic{Name: "__consumer_offsets", Internal: true, Partitions: []kafkago.Partition{{ID: 0}}})
+		for name, ps := range topics {
+			resp.Topics = append(resp.Topics, kafkago.Topic{Name: name, Partitions: ps})
+		}
+	}
+	for _, name := range req.Topics {
+		resp.Topics = append(resp.Topics, kafkago.Topic{Name: name, Partitions: topics[name]})
+	}
+	return resp, nil
+}
+
+func (m *mockPartitionClient) ListOffsets(_ context.Context, req *kafkago.ListOffsetsRequest) (*kafkago.ListOffsetsResponse, error) {
+	m.Lock()
+	defer m.Unlock()
+	resp := &kafkago.ListOffsetsResponse{Topics: make(map[string][]kafkago.PartitionOffsets)}
+	for topic, reqs := range req.Topics {
+		for _, r := range reqs {
+			records := m.records[topicPartition{topic: topic, partition: r.Partition}]
+			po := kafkago.PartitionOffsets{Partition: r.Partition, FirstOffset: -1, LastOffset: -1, Offsets: map[int64]time.Time{}}
+			switch r.Timestamp {
+			case kafkago.FirstOffset:
+				po.FirstOffset = 0
+			case kafkago.LastOffset:
+				po.LastOffset = int64(len(records))
+			default:
+				o := int64(-1)
+				for i := range records {
+					if int64(i+1)*1000 >= r.Timestamp {
+						o = int64(i)
+						break
+					}
+				}
+				po.Offsets[o] = time.UnixMilli(r.Timestamp)
+			}
+			resp.Topics[topic] = append(resp.Topics[topic], po)
+		}
+	}
+	return resp, nil
+}
+
+func (m *mockPartitionClient) Fetch(ctx context.Context, req *kafkago.FetchRequest) (*kafkago.FetchResponse, error) {
+	m.Lock()
+	records := m.records[topicPartition{topic: req.Topic, partition: req.Partition}]
+	m.Unlock()
+	resp := &kafkago.FetchResponse{Topic: req.Topic, Partition: req.Partition, HighWatermark: int64(len(records))}
+	if req.Offset > int64(len(records)) {
+		resp.Error = kafkago.OffsetOutOfRange
+		return resp, nil
+	}
+	if req.Offset == int64(len(records)) {
+		select {
+		case <-ctx.Done():
+		case <-time.After(10 * time.Millisecond):
+		}
+		resp.Records = kafkago.NewRecordReader()
+		return resp, nil
+	}
+	// return the batch from the previous offset with a control batch like the broker
+	start := max(req.Offset-1, 0)
+	var rs []kafkago.Record
+	for i := start; i < int64(len(records)); i++ {
+		rs = append(rs, kafkago.Record{Offset: i, Key: kafkago.NewBytes([]byte("k")), Value: kafkago.NewBytes([]byte(records[i]))})
+	}
+	resp.Records = &protocol.RecordStream{Records: []protocol.RecordReader{&protocol.ControlBatch{}, kafkago.NewRecordReader(rs...)}}
+	return resp, nil
+}
+
+func (m *mockPartitionClient) OffsetFetch(_ context.Context, req *kafkago.OffsetFetchRequest) (*kafkago.OffsetFetchResponse, error) {
+	m.Lock()
+	defer m.Unlock()
+	resp := &kafkago.OffsetFetchResponse{Topics: make(map[string][]kafkago.OffsetFetchPartition)}
+	for topic, ps := range req.Topics {
+		for _, p := range ps {
+			o, ok := m.committed[topicPartition{topic: topic, partition: p}]
+			if !ok {
+				o = -1
+			}
+			resp.Topics[topic] = append(resp.Topics[topic], kafkago.OffsetFetchPartition{Partition: p, CommittedOffset: o})
+		}
+	}
+	return resp, nil
+}
+
+func (m *mockPartitionClient) OffsetCommit(_ context.Context, req *kafkago.OffsetCommitRequest) (*kafkago.OffsetCommitResponse, error) {
+	m.Lock()
+	defer m.Unlock()
+	resp := &kafkago.OffsetCommitResponse{Topics: make(map[string][]kafkago.OffsetCommitPartition)}
+	for topic, ps := range req.Topics {
+		for _, p := range ps {
+			m.committed[topicPartition{topic: topic, partition: p.Partition}] = p.Offset
+			resp.Topics[topic] = append(resp.Topics[topic], kafkago.OffsetCommitPartition{Partition: p.Partition})
+		}
+	}
+	return resp, nil
+}
+
+func newMockPartitionClient() *mockPartitionClient {
+	return &mockPartitionClient{
+		records: map[topicPartition][]string{
+			{topic: "t1", partition: 0}:    {"a", "b", "c"},
+			{topic: "t1", partition: 1}:    {"d"},
+			{topic: "t2", partition: 0}:    {"e", "f"},
+			{topic: "other", partition: 0}: {"g"},
+		},
+		committed: map[topicPartition]int64{
+			{topic: "t1", partition: 0}: 1,
+		},
+	}
+}
+
+func TestPartitionStartOffsets(t *testing.T) {
+	testcases := []struct {
+		props  map[string]any
+		expect map[string]any
+	}{
+		{
+			props:  map[string]any{"startMode": "earliest"},
+			expect: map[string]any{"t1:0": int64(0), "t1:1": int64(0), "t2:0": int64(0)},
+		},
+		{
+			props:  map[string]any{},
+			expect: map[string]any{"t1:0": int64(3), "t1:1": int64(1), "t2:0": int64(2)},
+		},
+		{
+			props:  map[string]any{"startMode": "timestamp", "startTimestamp": 2000},
+			expect: map[string]any{"t1:0": int64(1), "t1:1": int64(1), "t2:0": int64(1)},
+		},
+		{
+			props:  map[string]any{"startMode": "committed", "groupID": "g"},
+			expect: map[string]any{"t1:0": int64(1), "t1:1": int64(1), "t2:0": int64(2)},
+		},
+		{
+			props:  map[string]any{"datasource": "t1", "topicRegex": false, "partition": -1, "startMode": "earliest"},
+			expect: map[string]any{"t1:0": int64(0), "t1:1": int64(0)},
+		},
+	}
+	ctx := mockContext.NewMockContext("rule1", "kafka1")
+	for i, tc := range testcases {
+		props := map[string]any{
+			"datasource": "t.*",
+			"brokers":    "localhost:9092",
+			"topicRegex": true,
+		}
+		for k, v := range tc.props {
+			props[k] = v
+		}
+		ks := &KafkaSource{client: newMockPartitionClient()}
+		require.NoError(t, ks.Provision(ctx, props), i)
+		require.NoError(t, ks.Connect(ctx, func(status string, message string) {}), i)
+		offset, err := ks.GetOffset()
+		require.NoError(t, err)
+		require.Equal(t, tc.expect, offset, i)
+		require.NoError(t, ks.Close(ctx))
+	}
+}
+
+func TestPartitionConfError(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule1", "kafka1")
+	for _, props := range []map[string]any{
+		{"partition": -2},
+		{"startMode": "none"},
+		{"startMode": "timestamp"},
+		{"startMode": "committed"},
+		{"startMode": "timestamp", "startTimestamp": 1000, "groupID": "g"},
+	} {
+		props["datasource"] = "t1"
+		props["brokers"] = "localhost:9092"
+		require.Error(t, (&KafkaSource{}).Provision(ctx, props), props)
+	}
+	ks := &KafkaSource{client: newMockPartitionClient()}
+	require.NoError(t, ks.Provision(ctx, map[string]any{"datasource": "(", "brokers": "localhost:9092", "topicRegex": true}))
+	require.Error(t, ks.Connect(ctx, func(status string, message string) {}))
+	require.NoError(t, ks.Provision(ctx, map[string]any{"datasource": "none", "brokers": "localhost:9092", "topicRegex": true}))
+	require.Error(t, ks.Connect(ctx, func(status string, message string) {}))
+}
+
+func TestKafkaSourcePartitions(t *testing.T) {
+	ctx, cancel := mockContext.NewMockContext("rule1", "kafka1").WithCancel()
+	client := newMockPartitionClient()
+	ks := &KafkaSource{client: client}
+	require.NoError(t, ks.Provision(ctx, map[string]any{
+		"datasource":              "t.*",
+		"brokers":                 "localhost:9092",
+		"topicRegex":              true,
+		"groupID":                 "g",
+		"startMode":               "earliest",
+		"metadataRefreshInterval": "50ms",
+	}))
+	require.NoError(t, ks.Connect(ctx, func(status string, message string) {}))
+	// restore from the checkpoint, the partition out of range restarts from the earliest
+	require.NoError(t, ks.Rewind(map[string]any{"t1:0": int64(2), "t2:0": 10}))
+	require.Error(t, ks.Rewind(int64(1)))
+	require.Error(t, ks.Rewind(map[string]any{"t1": int64(1)}))
+	require.NoError(t, ks.EnableCheckpointCommit(time.Minute))
+
+	type received struct {
+		value string
+		meta  map[string]any
+	}
+	ch := make(chan received, 10)
+	go func() {
+		_ = ks.Subscribe(ctx, func(ctx api.StreamContext, data []byte, meta map[string]any, ts time.Time) {
+			ch <- received{value: string(data), meta: meta}
+		}, func(ctx api.StreamContext, err error) {
+			t.Log(err)
+		})
+	}()
+	recv := func(n int) map[string]map[string]any {
+		result := make(map[string]map[string]any)
+		for i := 0; i < n; i++ {
+			select {
+			case r := <-ch:
+				result[r.value] = r.meta
+			case <-time.After(5 * time.Second):
+				require.Fail(t, fmt.Sprintf("timeout waiting for %d messages, got %v", n, result))
+			}
+		}
+		return result
+	}
+	result := recv(4)
+	require.Equal(t, map[string]map[string]any{
+		"c": {"topic": "t1", "partition": 0, "offset": int64(2), "key": "k"},
+		"d": {"topic": "t1", "partition": 1, "offset": int64(0), "key": "k"},
+		"e": {"topic": "t2", "partition": 0, "offset": int64(0), "key": "k"},
+		"f": {"topic": "t2", "partition": 0, "offset": int64(1), "key": "k"},
+	}, result)
+
+	// new partition is consumed from the beginning and new records are consumed
+	client.Lock()
+	client.records[topicPartition{topic: "t3", partition: 0}] = []string{"h"}
+	client.records[topicPartition{topic: "t1", partition: 1}] = []string{"d", "i"}
+	client.Unlock()
+	result = recv(2)
+	require.Contains(t, result, "h")
+	require.Contains(t, result, "i")
+	require.Equal(t, float64(0), testutil.ToFloat64(KafkaSourcePartitionLag.WithLabelValues("rule1", "kafka1", "t1", "1")))
+
+	offset, err := ks.GetOffset()
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"t1:0": int64(3), "t1:1": int64(2), "t2:0": int64(2), "t3:0": int64(1)}, offset)
+	require.NoError(t, ks.Commit(ctx, offset))
+	client.Lock()
+	require.Equal(t, map[topicPartition]int64{
+		{topic: "t1", partition: 0}: 3,
+		{topic: "t1", partition: 1}: 2,
+		{topic: "t2", partition: 0}: 2,
+		{topic: "t3", partition: 0}: 1,
+	}, client.committed)
+	client.Unlock()
+	cancel()
+	require.NoError(t, ks.Close(ctx))
+}
+
+// TestKafkaSourceRewindNoReplay saves the offset during the ingestion like the source node and verifies that no
+// message is consumed again after restoring from it.
+func TestKafkaSourceRewindNoReplay(t *testing.T) {
+	client := newMockPartitionClient()
+	props := map[string]any{
+		"datasource": "t1",
+		"brokers":    "localhost:9092",
+		"partition":  -1,
+		"startMode":  "earliest",
+	}
+	consume := func(state any, n int, produce func()) ([]string, any) {
+		ctx, cancel := mockContext.NewMockContext("rule1", "kafka1").WithCancel()
+		defer cancel()
+		ks := &KafkaSource{client: client}
+		require.NoError(t, ks.Provision(ctx, props))
+		require.NoError(t, ks.Connect(ctx, func(status string, message string) {}))
+		if state != nil {
+			require.NoError(t, ks.Rewind(state))
+		}
+		type received struct {
+			value string
+			state any
+		}
+		ch := make(chan received, 10)
+		go func() {
+			_ = ks.Subscribe(ctx, func(ctx api.StreamContext, data []byte, meta map[string]any, ts time.Time) {
+				s, err := ks.GetOffset()
+				require.NoError(t, err)
+				ch <- received{value: string(data), state: s}
+			}, func(ctx api.StreamContext, err error) {
+				t.Log(err)
+			})
+		}()
+		if produce != nil {
+			produce()
+		}
+		var (
+			values []string
+			last   any
+		)
+		for i := 0; i < n; i++ {
+			select {
+			case r := <-ch:
+				values = append(values, r.value)
+				last = r.state
+			case <-time.After(5 * time.Second):
+				require.Fail(t, fmt.Sprintf("timeout waiting for %d messages, got %v", n, values))
+			}
+		}
+		require.NoError(t, ks.Close(ctx))
+		return values, last
+	}
+	values, state := consume(nil, 4, nil)
+	require.ElementsMatch(t, []string{"a", "b", "c", "d"}, values)
+	require.Equal(t, map[string]any{"t1:0": int64(3), "t1:1": int64(1)}, state)
+	// only the new message is consumed after the restore
+	values, _ = consume(state, 1, func() {
+		client.Lock()
+		client.records[topicPartition{topic: "t1", partition: 1}] = []string{"d", "x"}
+		client.Unlock()
+	})
+	require.Equal(t, []string{"x"}, values)
+}
+
+func TestGetReaderConfig(t *testing.T) {
+	for mode, expected := range map[string]int64{
+		"":                 kafkago.FirstOffset,
+		StartModeEarliest:  kafkago.FirstOffset,
+		StartModeLatest:    kafkago.LastOffset,
+		StartModeCommitted: kafkago.LastOffset,
+	} {
+		c, err := getSourceConf(map[string]any{"datasource": "t", "brokers": "localhost:9092", "groupID": "g", "startMode": mode})
+		require.NoError(t, err)
+		require.NoError(t, c.validate())
+		require.Equal(t, expected, c.GetReaderConfig().StartOffset, mode)
+	}
+}
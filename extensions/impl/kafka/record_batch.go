@@ -0,0 +1,144 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+
+	kafkago "github.com/segmentio/kafka-go"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+// The record batch of magic version 2 defined by the kafka protocol. kafka-go always writes the batch without the
+// producer fields, so the transactional batch is encoded here by the protocol directly.
+//
+//	baseOffset: int64
+//	batchLength: int32
+//	partitionLeaderEpoch: int32
+//	magic: int8 (2)
+//	crc: uint32, castagnoli crc of the bytes from attributes to the end
+//	attributes: int16, bit 0~2 compression, bit 4 transactional
+//	lastOffsetDelta: int32
+//	baseTimestamp: int64
+//	maxTimestamp: int64
+//	producerId: int64
+//	producerEpoch: int16
+//	baseSequence: int32
+//	records count: int32
+//	records, compressed as a whole if compression is set
+const (
+	batchMagic            = 2
+	batchTransactionalBit = 0x10
+	// the offset of the crc and the attributes in the batch
+	batchCrcOffset        = 17
+	batchAttributesOffset = 21
+)
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// encodeTxnBatch encodes the messages as a transactional record batch of the producer. The result is the records
+// field of the produce request, which is the batch prefixed by its size.
+func encodeTxnBatch(msgs []kafkago.Message, compression kafkago.Compression, producerId int, epoch int, sequence int) ([]byte, error) {
+	if len(msgs) == 0 {
+		return nil, fmt.Errorf("cannot encode an empty record batch")
+	}
+	now := timex.GetNow()
+	timestamps := make([]int64, len(msgs))
+	for i, msg := range msgs {
+		t := msg.Time
+		if t.IsZero() {
+			t = now
+		}
+		timestamps[i] = t.UnixMilli()
+	}
+	baseTs, maxTs := timestamps[0], timestamps[0]
+	for _, ts := range timestamps {
+		if ts > maxTs {
+			maxTs = ts
+		}
+	}
+	records := &bytes.Buffer{}
+	for i, msg := range msgs {
+		appendRecord(records, msg, timestamps[i]-baseTs, i)
+	}
+	body := records.Bytes()
+	if codec := compression.Codec(); codec != nil {
+		cb := &bytes.Buffer{}
+		w := codec.NewWriter(cb)
+		if _, err := w.Write(body); err != nil {
+			return nil, fmt.Errorf("compress record batch with %s error: %v", codec.Name(), err)
+		}
+		if err := w.Close(); err != nil {
+			return nil, fmt.Errorf("compress record batch with %s error: %v", codec.Name(), err)
+		}
+		body = cb.Bytes()
+	}
+
+	b := make([]byte, 0, 4+61+len(body))
+	b = binary.BigEndian.AppendUint32(b, 0) // size placeholder
+	start := len(b)
+	b = binary.BigEndian.AppendUint64(b, 0) // base offset
+	b = binary.BigEndian.AppendUint32(b, 0) // batch length placeholder
+	b = binary.BigEndian.AppendUint32(b, 0xFFFFFFFF)
+	b = append(b, batchMagic)
+	b = binary.BigEndian.AppendUint32(b, 0) // crc placeholder
+	b = binary.BigEndian.AppendUint16(b, uint16(compression)|batchTransactionalBit)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(msgs)-1))
+	b = binary.BigEndian.AppendUint64(b, uint64(baseTs))
+	b = binary.BigEndian.AppendUint64(b, uint64(maxTs))
+	b = binary.BigEndian.AppendUint64(b, uint64(producerId))
+	b = binary.BigEndian.AppendUint16(b, uint16(epoch))
+	b = binary.BigEndian.AppendUint32(b, uint32(sequence))
+	b = binary.BigEndian.AppendUint32(b, uint32(len(msgs)))
+	b = append(b, body...)
+
+	batch := b[start:]
+	binary.BigEndian.PutUint32(b, uint32(len(batch)))
+	// the batch length counts the bytes after itself
+	binary.BigEndian.PutUint32(batch[8:], uint32(len(batch)-12))
+	binary.BigEndian.PutUint32(batch[batchCrcOffset:], crc32.Checksum(batch[batchAttributesOffset:], castagnoliTable))
+	return b, nil
+}
+
+// appendRecord appends a record which is prefixed by its length. The integers of a record are zigzag varints.
+func appendRecord(buf *bytes.Buffer, msg kafkago.Message, timestampDelta int64, offsetDelta int) {
+	r := make([]byte, 0, 16+len(msg.Key)+len(msg.Value))
+	r = append(r, 0) // attributes, unused
+	r = binary.AppendVarint(r, timestampDelta)
+	r = binary.AppendVarint(r, int64(offsetDelta))
+	r = appendVarBytes(r, msg.Key)
+	r = appendVarBytes(r, msg.Value)
+	r = binary.AppendVarint(r, int64(len(msg.Headers)))
+	for _, h := range msg.Headers {
+		r = binary.AppendVarint(r, int64(len(h.Key)))
+		r = append(r, h.Key...)
+		r = appendVarBytes(r, h.Value)
+	}
+	buf.Write(binary.AppendVarint(nil, int64(len(r))))
+	buf.Write(r)
+}
+
+// appendVarBytes appends the bytes prefixed by the varint length, and the length is -1 for nil
+func appendVarBytes(b []byte, v []byte) []byte {
+	if v == nil {
+		return binary.AppendVarint(b, -1)
+	}
+	b = binary.AppendVarint(b, int64(len(v)))
+	return append(b, v...)
+}
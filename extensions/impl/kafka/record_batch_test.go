@@ -0,0 +1,113 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"testing"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeTxnBatch(t *testing.T) {
+	ts := time.UnixMilli(1700000000000)
+	msgs := []kafkago.Message{
+		{Key: []byte("k1"), Value: []byte(`{"a":1}`), Time: ts},
+		{Value: []byte(`{"a":2}`), Headers: []kafkago.Header{{Key: "h", Value: []byte("v")}}, Time: ts.Add(5 * time.Millisecond)},
+		{Value: nil, Time: ts.Add(2 * time.Millisecond)},
+	}
+	for _, c := range []kafkago.Compression{0, kafkago.Gzip, kafkago.Snappy, kafkago.Lz4, kafkago.Zstd} {
+		t.Run(c.String(), func(t *testing.T) {
+			b, err := encodeTxnBatch(msgs, c, 1000, 3, 5)
+			require.NoError(t, err)
+
+			// the header fields at the offsets defined by the protocol
+			require.Equal(t, uint32(len(b)-4), binary.BigEndian.Uint32(b[0:]))
+			batch := b[4:]
+			require.Equal(t, uint64(0), binary.BigEndian.Uint64(batch[0:]))
+			require.Equal(t, uint32(len(batch)-12), binary.BigEndian.Uint32(batch[8:]))
+			require.Equal(t, int32(-1), int32(binary.BigEndian.Uint32(batch[12:])))
+			require.Equal(t, byte(2), batch[16])
+			require.Equal(t, crc32.Checksum(batch[21:], crc32.MakeTable(crc32.Castagnoli)), binary.BigEndian.Uint32(batch[17:]))
+			require.Equal(t, uint16(c)|0x10, binary.BigEndian.Uint16(batch[21:]))
+			require.Equal(t, uint32(2), binary.BigEndian.Uint32(batch[23:]))
+			require.Equal(t, uint64(ts.UnixMilli()), binary.BigEndian.Uint64(batch[27:]))
+			require.Equal(t, uint64(ts.UnixMilli()+5), binary.BigEndian.Uint64(batch[35:]))
+			require.Equal(t, uint64(1000), binary.BigEndian.Uint64(batch[43:]))
+			require.Equal(t, uint16(3), binary.BigEndian.Uint16(batch[51:]))
+			require.Equal(t, uint32(5), binary.BigEndian.Uint32(batch[53:]))
+			require.Equal(t, uint32(3), binary.BigEndian.Uint32(batch[57:]))
+
+			// decode by kafka-go
+			rs := &protocol.RecordSet{}
+			_, err = rs.ReadFrom(bytes.NewReader(b))
+			require.NoError(t, err)
+			require.True(t, rs.Attributes.Transactional())
+			require.Equal(t, c, kafkago.Compression(rs.Attributes.Compression()))
+			rb := rs.Records.(*protocol.RecordStream).Records[0].(*protocol.RecordBatch)
+			require.Equal(t, int64(1000), rb.ProducerID)
+			require.Equal(t, int16(3), rb.ProducerEpoch)
+			require.Equal(t, int32(5), rb.BaseSequence)
+			var (
+				keys    []string
+				values  []string
+				offsets []int64
+				times   []time.Time
+				headers [][]kafkago.Header
+			)
+			for {
+				r, err := rb.ReadRecord()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				require.NoError(t, err)
+				offsets = append(offsets, r.Offset)
+				times = append(times, r.Time)
+				headers = append(headers, r.Headers)
+				if r.Key != nil {
+					k, err := protocol.ReadAll(r.Key)
+					require.NoError(t, err)
+					keys = append(keys, string(k))
+				} else {
+					keys = append(keys, "<nil>")
+				}
+				if r.Value != nil {
+					v, err := protocol.ReadAll(r.Value)
+					require.NoError(t, err)
+					values = append(values, string(v))
+				} else {
+					values = append(values, "<nil>")
+				}
+			}
+			require.Equal(t, []string{"k1", "<nil>", "<nil>"}, keys)
+			require.Equal(t, []string{`{"a":1}`, `{"a":2}`, "<nil>"}, values)
+			require.Equal(t, []int64{0, 1, 2}, offsets)
+			for i, msg := range msgs {
+				require.Equal(t, msg.Time.UnixMilli(), times[i].UnixMilli())
+			}
+			require.Empty(t, headers[0])
+			require.Equal(t, []kafkago.Header{{Key: "h", Value: []byte("v")}}, headers[1])
+		})
+	}
+	_, err := encodeTxnBatch(nil, 0, 1000, 3, 5)
+	require.EqualError(t, err, "cannot encode an empty record batch")
+}
@@ -1,4 +1,4 @@
-// Copyright 2024-2025 EMQ Technologies Co., Ltd.
+// Copyright 2024-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
@@ -32,6 +33,7 @@ import (
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/cert"
 	"github.com/lf-edge/ekuiper/v2/pkg/model"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
 	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
 
@@ -67,6 +69,17 @@ type KafkaSink struct {
 	statManager    metric.StatManager
 	connected      bool
 	sch            api.StatusChangeHandler
+	// two-phase commit
+	twoPhaseCommit bool
+	txnMode        bool
+	txnClient      txnClient
+	txnLock        sync.Mutex
+	// the messages to write into the open transaction, at most batchSize
+	txnBuffer       []kafkago.Message
+	openTxn         *openTxn
+	producers       map[string]*txnProducer
+	topicPartitions map[string][]int
+	pendingTxns     []*pendingTxn
 }
 
 func (k *KafkaSink) setStatManager(ctx api.StreamContext) {
@@ -104,6 +117,12 @@ type kafkaConf struct {
 
 	// write config
 	Compression string `json:"compression"`
+
+	// transaction config, only used when the rule qos is exactly once
+	Transactional         bool          `json:"transactional"`
+	TransactionalIdPrefix string        `json:"transactionalIdPrefix"`
+	TransactionTimeout    time.Duration `json:"transactionTimeout"`
+	TransactionPoolSize   int           `json:"transactionPoolSize"`
 }
 
 type kafkaWriterConf struct {
@@ -119,6 +138,14 @@ func (c *kafkaConf) validate() error {
 	if len(c.Brokers) < 1 {
 		return fmt.Errorf("brokers can not be empty")
 	}
+	if c.Transactional {
+		if c.TransactionTimeout <= 0 {
+			return fmt.Errorf("transactionTimeout must be positive")
+		}
+		if c.TransactionPoolSize < 1 {
+			return fmt.Errorf("transactionPoolSize must be at least 1")
+		}
+	}
 	return nil
 }
 
@@ -239,13 +266,41 @@ func (k *KafkaSink) Connect(ctx api.StreamContext, sch api.StatusChangeHandler)
 	k.ruleID = ctx.GetRuleId()
 	k.opID = ctx.GetOpId()
 	k.buildKafkaWriter(ctx)
-	k.connected = true
-	sch(api.ConnectionConnected, "")
 	k.sch = sch
 	k.setStatManager(ctx)
+	if k.kc.Transactional {
+		if k.twoPhaseCommit {
+			k.txnMode = true
+			if err := k.connectTxn(ctx); err != nil {
+				sch(api.ConnectionDisconnected, err.Error())
+				return err
+			}
+		} else {
+			ctx.GetLogger().Warnf("transactional is only used when the rule qos is exactly once, write without transaction")
+		}
+	}
+	k.connected = true
+	sch(api.ConnectionConnected, "")
 	return nil
 }
 
+func (k *KafkaSink) connectTxn(ctx api.StreamContext) error {
+	if k.kc.TransactionalIdPrefix == "" {
+		k.kc.TransactionalIdPrefix = fmt.Sprintf("ekuiper-%s-%s", k.ruleID, k.opID)
+	}
+	if k.txnClient == nil {
+		k.txnClient = &kafkago.Client{
+			Addr: kafkago.TCP(strings.Split(k.kc.Brokers, ",")...),
+			Transport: &kafkago.Transport{
+				SASL: k.mechanism,
+				TLS:  k.tlsConfig,
+			},
+		}
+	}
+	ctx.GetLogger().Infof("kafka sink writes in transactions with transactional id prefix %s", k.kc.TransactionalIdPrefix)
+	return k.recoverTxns(ctx)
+}
+
 func (k *KafkaSink) runWithTickerAndBatchSize(ctx api.StreamContext) {
 	ctx.GetLogger().Infof("kafka sink batch run with batchSize %d, batchInterval %v", k.kc.BatchSize, k.kc.LingerInterval)
 	ticker := timex.GetTicker(k.kc.LingerInterval)
@@ -335,6 +390,10 @@ func (k *KafkaSink) collect(ctx api.StreamContext, item api.RawTuple) error {
 		return err
 	}
 	KafkaSinkCounter.WithLabelValues(LblCollect, LblMsg, k.ruleID, k.opID).Inc()
+	// the messages are written into the open transaction which is committed after the checkpoint completes
+	if k.txnMode {
+		return k.collectTxn(ctx, msg)
+	}
 	select {
 	case <-ctx.Done():
 	case k.msgQ <- &msg:
@@ -489,15 +548,18 @@ func GetSink() api.Sink {
 }
 
 var (
-	_ api.BytesCollector = &KafkaSink{}
-	_ util.PingableConn  = &KafkaSink{}
-	_ model.SinkInfoNode = &KafkaSink{}
+	_ api.BytesCollector        = &KafkaSink{}
+	_ util.PingableConn         = &KafkaSink{}
+	_ model.SinkInfoNode        = &KafkaSink{}
+	_ modules.TwoPhaseCommitter = &KafkaSink{}
 )
 
 func getDefaultKafkaConf() *kafkaConf {
 	c := &kafkaConf{
-		RequiredACKs: 1,
-		MaxAttempts:  3,
+		RequiredACKs:        1,
+		MaxAttempts:         3,
+		TransactionTimeout:  10 * time.Minute,
+		TransactionPoolSize: 5,
 	}
 	c.kafkaWriterConf = kafkaWriterConf{
 		BatchSize:    1,
@@ -1,4 +1,4 @@
-// Copyright 2024-2025 EMQ Technologies Co., Ltd.
+// Copyright 2024-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -15,9 +15,11 @@
 package kafka
 
 import (
+	"context"
 	"crypto/tls"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -33,6 +35,7 @@ import (
 	"github.com/lf-edge/ekuiper/v2/metrics"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/cert"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
 	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
 
@@ -45,6 +48,10 @@ type KafkaSource struct {
 	mechanism sasl.Mechanism
 	connected bool
 	sch       api.StatusChangeHandler
+	// consume multiple partitions with the offsets of each partition
+	client             partitionClient
+	pc                 *partitionConsumer
+	commitOnCheckpoint bool
 }
 
 type kafkaSourceConf struct {
@@ -54,6 +61,11 @@ type kafkaSourceConf struct {
 	Partition   int    `json:"partition"`
 	MaxAttempts int    `json:"maxAttempts"`
 	MaxBytes    int    `json:"maxBytes"`
+	// The datasource is a regex to match the topics
+	TopicRegex              bool          `json:"topicRegex"`
+	StartMode               string        `json:"startMode"`
+	StartTimestamp          int64         `json:"startTimestamp"`
+	MetadataRefreshInterval time.Duration `json:"metadataRefreshInterval"`
 }
 
 func (c *kafkaSourceConf) validate() error {
@@ -63,24 +75,55 @@ func (c *kafkaSourceConf) validate() error {
 	if len(c.Brokers) < 1 {
 		return fmt.Errorf("brokers can not be empty")
 	}
+	if c.Partition < -1 {
+		return fmt.Errorf("partition must be -1 or a partition id, got %d", c.Partition)
+	}
+	switch c.StartMode {
+	case "", StartModeEarliest, StartModeLatest:
+	case StartModeTimestamp:
+		if c.StartTimestamp <= 0 {
+			return fmt.Errorf("startTimestamp is required for startMode timestamp")
+		}
+		if !c.multiPartition() && c.GroupID != "" {
+			return fmt.Errorf("startMode timestamp is not supported with groupID, set partition to -1 to consume all partitions")
+		}
+	case StartModeCommitted:
+		if c.GroupID == "" {
+			return fmt.Errorf("groupID is required for startMode committed")
+		}
+	default:
+		return fmt.Errorf("invalid startMode %s, must be earliest, latest, timestamp or committed", c.StartMode)
+	}
 	return nil
 }
 
+// multiPartition returns whether to consume all partitions of the topics instead of using the reader
+func (c *kafkaSourceConf) multiPartition() bool {
+	return c.Partition == -1 || c.TopicRegex
+}
+
 func (c *kafkaSourceConf) GetReaderConfig() kafkago.ReaderConfig {
-	return kafkago.ReaderConfig{
+	rc := kafkago.ReaderConfig{
 		Brokers:     strings.Split(c.Brokers, ","),
 		GroupID:     c.GroupID,
 		Topic:       c.Topic,
 		Partition:   c.Partition,
 		MaxBytes:    c.MaxBytes,
 		MaxAttempts: c.MaxAttempts,
+		StartOffset: kafkago.FirstOffset,
+	}
+	// the consumer group starts from the earliest by default like kafka-go unless the start mode is set
+	if c.StartMode != "" && c.StartMode != StartModeEarliest {
+		rc.StartOffset = kafkago.LastOffset
 	}
+	return rc
 }
 
 func getSourceConf(props map[string]interface{}) (*kafkaSourceConf, error) {
 	c := &kafkaSourceConf{
-		MaxBytes:    1e6,
-		MaxAttempts: 3,
+		MaxBytes:                1e6,
+		MaxAttempts:             3,
+		MetadataRefreshInterval: time.Minute,
 	}
 	err := cast.MapToStruct(props, c)
 	if err != nil {
@@ -161,10 +204,16 @@ func (k *KafkaSource) ping(address string) error {
 }
 
 func (k *KafkaSource) Close(ctx api.StreamContext) error {
-	return k.reader.Close()
+	if k.reader != nil {
+		return k.reader.Close()
+	}
+	return nil
 }
 
 func (k *KafkaSource) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	if k.sc.multiPartition() {
+		return k.connectPartitions(ctx, sch)
+	}
 	readerConfig := k.sc.GetReaderConfig()
 	conf.Log.Infof("topic: %s, brokers: %v", readerConfig.Topic, readerConfig.Brokers)
 	readerConfig.Dialer = &kafkago.Dialer{
@@ -175,7 +224,18 @@ func (k *KafkaSource) Connect(ctx api.StreamContext, sch api.StatusChangeHandler
 	}
 	reader := kafkago.NewReader(readerConfig)
 	k.reader = reader
-	err := k.reader.SetOffset(kafkago.LastOffset)
+	var err error
+	// the consumer group starts from the committed offset or the start offset of the reader config
+	if k.sc.GroupID == "" {
+		switch k.sc.StartMode {
+		case StartModeEarliest:
+			err = k.reader.SetOffset(kafkago.FirstOffset)
+		case StartModeTimestamp:
+			err = k.reader.SetOffsetAt(ctx, time.UnixMilli(k.sc.StartTimestamp))
+		default:
+			err = k.reader.SetOffset(kafkago.LastOffset)
+		}
+	}
 	if err != nil {
 		k.connected = false
 		sch(api.ConnectionDisconnected, err.Error())
@@ -188,16 +248,48 @@ func (k *KafkaSource) Connect(ctx api.StreamContext, sch api.StatusChangeHandler
 	return nil
 }
 
+func (k *KafkaSource) connectPartitions(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	conf.Log.Infof("topic: %s, brokers: %v, consume all partitions from %s", k.sc.Topic, k.sc.Brokers, k.sc.StartMode)
+	if k.client == nil {
+		k.client = &kafkago.Client{
+			Addr: kafkago.TCP(strings.Split(k.sc.Brokers, ",")...),
+			Transport: &kafkago.Transport{
+				DialTimeout: 10 * time.Second,
+				SASL:        k.mechanism,
+				TLS:         k.tlsConfig,
+			},
+		}
+	}
+	pc, err := newPartitionConsumer(k.sc, k.client)
+	if err == nil {
+		err = pc.init(ctx)
+	}
+	k.sch = sch
+	if err != nil {
+		k.connected = false
+		sch(api.ConnectionDisconnected, err.Error())
+		return err
+	}
+	k.pc = pc
+	k.connected = true
+	sch(api.ConnectionConnected, "")
+	return nil
+}
+
 func (k *KafkaSource) handleConnectedSch(err error) {
 	if k.connected && err != nil {
 		k.connected = false
 		k.sch(api.ConnectionDisconnected, err.Error())
 	} else if !k.connected && err == nil {
+		k.connected = true
 		k.sch(api.ConnectionConnected, "")
 	}
 }
 
 func (k *KafkaSource) Subscribe(ctx api.StreamContext, ingest api.BytesIngest, ingestError api.ErrorIngest) error {
+	if k.pc != nil {
+		return k.subscribePartitions(ctx, ingest, ingestError)
+	}
 	for {
 		select {
 		case <-ctx.Done():
@@ -214,12 +306,61 @@ func (k *KafkaSource) Subscribe(ctx api.StreamContext, ingest api.BytesIngest, i
 		KafkaSourceCounter.WithLabelValues(LblMsg, ctx.GetRuleId(), ctx.GetOpId()).Inc()
 		KafkaSourceCounter.WithLabelValues(LblBytes, ctx.GetRuleId(), ctx.GetOpId()).Add(float64(len(msg.Value)))
 		KafkaSourceGauge.WithLabelValues(LblOffset, ctx.GetRuleId(), ctx.GetOpId()).Set(float64(msg.Offset))
+		// advance the offset before ingesting because the state is saved during the ingestion
+		k.offset = msg.Offset + 1
 		ingest(ctx, msg.Value, nil, timex.GetNow())
 	}
 }
 
+func (k *KafkaSource) subscribePartitions(ctx api.StreamContext, ingest api.BytesIngest, ingestError api.ErrorIngest) error {
+	fetchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	k.pc.run(fetchCtx, ctx.GetLogger())
+	ticker := time.NewTicker(commitInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			// commit the consumed offsets periodically if not committed by the checkpoint
+			if !k.commitOnCheckpoint {
+				if err := k.pc.commit(ctx, k.pc.currentOffsets()); err != nil {
+					ctx.GetLogger().Warnf("commit kafka offsets error: %v", err)
+				}
+			}
+		case msg := <-k.pc.msgCh:
+			k.handleConnectedSch(msg.err)
+			if msg.err != nil {
+				KafkaSourceCounter.WithLabelValues(metrics.LblException, ctx.GetRuleId(), ctx.GetOpId()).Inc()
+				ingestError(ctx, msg.err)
+				continue
+			}
+			KafkaSourceCounter.WithLabelValues(LblMsg, ctx.GetRuleId(), ctx.GetOpId()).Inc()
+			KafkaSourceCounter.WithLabelValues(LblBytes, ctx.GetRuleId(), ctx.GetOpId()).Add(float64(len(msg.value)))
+			KafkaSourceGauge.WithLabelValues(LblOffset, ctx.GetRuleId(), ctx.GetOpId()).Set(float64(msg.offset))
+			// advance the offset before ingesting because the state is saved during the ingestion
+			lag := k.pc.consumed(msg)
+			KafkaSourcePartitionLag.WithLabelValues(ctx.GetRuleId(), ctx.GetOpId(), msg.tp.topic, strconv.Itoa(msg.tp.partition)).Set(float64(lag))
+			ingest(ctx, msg.value, map[string]any{
+				"topic":     msg.tp.topic,
+				"partition": msg.tp.partition,
+				"offset":    msg.offset,
+				"key":       string(msg.key),
+			}, timex.GetNow())
+		}
+	}
+}
+
 func (k *KafkaSource) Rewind(offset interface{}) error {
 	conf.Log.Infof("set kafka source offset: %v", offset)
+	if k.pc != nil {
+		return k.pc.rewind(offset)
+	}
+	// the consumer group restarts from the committed offset
+	if k.sc.GroupID != "" {
+		return nil
+	}
 	offsetV := k.offset //nolint:staticcheck
 	switch v := offset.(type) {
 	case int64:
@@ -235,6 +376,7 @@ func (k *KafkaSource) Rewind(offset interface{}) error {
 		conf.Log.Errorf("kafka offset error: %v", err)
 		return fmt.Errorf("set kafka offset failed, err:%v", err)
 	}
+	k.offset = offsetV
 	return nil
 }
 
@@ -243,9 +385,29 @@ func (k *KafkaSource) ResetOffset(input map[string]interface{}) error {
 }
 
 func (k *KafkaSource) GetOffset() (interface{}, error) {
+	if k.pc != nil {
+		return k.pc.getOffsets(), nil
+	}
 	return k.offset, nil
 }
 
+// EnableCheckpointCommit commits the offsets of the consumer group only when the checkpoint completes
+func (k *KafkaSource) EnableCheckpointCommit(_ time.Duration) error {
+	k.commitOnCheckpoint = true
+	return nil
+}
+
+func (k *KafkaSource) Commit(ctx api.StreamContext, offset any) error {
+	if k.pc == nil {
+		return nil
+	}
+	offsets, err := parsePartitionOffsets(offset)
+	if err != nil {
+		return err
+	}
+	return k.pc.commit(ctx, offsets)
+}
+
 const (
 	SASL_NONE  = "none"
 	SASL_PLAIN = "plain"
@@ -330,6 +492,7 @@ func GetSource() api.Source {
 }
 
 var (
-	_ api.BytesSource   = &KafkaSource{}
-	_ util.PingableConn = &KafkaSource{}
+	_ api.BytesSource             = &KafkaSource{}
+	_ util.PingableConn           = &KafkaSource{}
+	_ modules.CheckpointCommitter = &KafkaSource{}
 )
@@ -0,0 +1,503 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/protocol"
+
+	"github.com/lf-edge/ekuiper/v2/metrics"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+// txnStateKey is the state key of the pre-committed transactions which are not committed yet
+const txnStateKey = "$kafkaPendingTxns"
+
+const txnRetryBackoff = 100 * time.Millisecond
+
+// txnClient is the part of the kafka client to run the transactions
+type txnClient interface {
+	Metadata(ctx context.Context, req *kafkago.MetadataRequest) (*kafkago.MetadataResponse, error)
+	InitProducerID(ctx context.Context, req *kafkago.InitProducerIDRequest) (*kafkago.InitProducerIDResponse, error)
+	AddPartitionsToTxn(ctx context.Context, req *kafkago.AddPartitionsToTxnRequest) (*kafkago.AddPartitionsToTxnResponse, error)
+	RawProduce(ctx context.Context, req *kafkago.RawProduceRequest) (*kafkago.ProduceResponse, error)
+	EndTxn(ctx context.Context, req *kafkago.EndTxnRequest) (*kafkago.EndTxnResponse, error)
+}
+
+// pendingTxn is a pre-committed transaction which waits for its checkpoint to complete. It is saved in the state so
+// that it can be committed after restart.
+type pendingTxn struct {
+	CheckpointId    int64  `json:"checkpointId"`
+	TransactionalId string `json:"transactionalId"`
+	ProducerId      int    `json:"producerId"`
+	ProducerEpoch   int    `json:"producerEpoch"`
+}
+
+type topicPartition struct {
+	topic     string
+	partition int
+}
+
+// txnProducer is the producer of a transactional id. It is initialized once and reused by the transactions of the id,
+// so the sequences of each partition continue across the transactions.
+type txnProducer struct {
+	transactionalId string
+	producerId      int
+	producerEpoch   int
+	sequences       map[topicPartition]int
+}
+
+// openTxn is the transaction which the messages are written into as they arrive until the next checkpoint
+type openTxn struct {
+	producer   *txnProducer
+	partitions map[topicPartition]struct{}
+	// the write error which aborted the transaction
+	err error
+}
+
+// EnableTwoPhaseCommit is called when the rule qos is exactly once. The transactions are only used if the
+// transactional property is set.
+func (k *KafkaSink) EnableTwoPhaseCommit() {
+	k.twoPhaseCommit = true
+}
+
+// PreCommit flushes the buffered messages into the open transaction and keeps it as pending until the checkpoint
+// completes. It fails if any write of the transaction failed, because the written messages were aborted.
+func (k *KafkaSink) PreCommit(ctx api.StreamContext, checkpointId int64) error {
+	if !k.txnMode {
+		return nil
+	}
+	k.txnLock.Lock()
+	defer k.txnLock.Unlock()
+	flushErr := k.flushTxn(ctx)
+	txn := k.openTxn
+	k.openTxn = nil
+	if txn != nil && txn.err != nil {
+		return txn.err
+	}
+	if flushErr != nil {
+		return flushErr
+	}
+	if txn != nil && len(txn.partitions) > 0 {
+		ctx.GetLogger().Debugf("pre-commit transaction %s for checkpoint %d", txn.producer.transactionalId, checkpointId)
+		k.pendingTxns = append(k.pendingTxns, &pendingTxn{
+			CheckpointId:    checkpointId,
+			TransactionalId: txn.producer.transactionalId,
+			ProducerId:      txn.producer.producerId,
+			ProducerEpoch:   txn.producer.producerEpoch,
+		})
+	}
+	return k.saveTxnState(ctx)
+}
+
+// collectTxn buffers the message and writes the buffer into the open transaction once it reaches the batch size
+func (k *KafkaSink) collectTxn(ctx api.StreamContext, msg kafkago.Message) error {
+	k.txnLock.Lock()
+	defer k.txnLock.Unlock()
+	k.txnBuffer = append(k.txnBuffer, msg)
+	if len(k.txnBuffer) < max(k.kc.BatchSize, 1) {
+		return nil
+	}
+	return k.flushTxn(ctx)
+}
+
+// flushTxn writes the buffered messages into the open transaction, and opens one with a free transactional id if
+// there is none. If the write fails, the transaction is aborted.
+func (k *KafkaSink) flushTxn(ctx api.StreamContext) error {
+	if len(k.txnBuffer) == 0 {
+		return nil
+	}
+	msgs := k.txnBuffer
+	k.txnBuffer = nil
+	KafkaSinkCounter.WithLabelValues(LblSend, LblReq, k.ruleID, k.opID).Inc()
+	start := time.Now()
+	err := k.writeOpenTxn(ctx, msgs)
+	metrics.IODurationHist.WithLabelValues(LblKafka, metrics.LblSinkIO, k.ruleID, k.opID).Observe(float64(time.Since(start).Microseconds()))
+	k.handleConnectedSch(err)
+	k.handleErrMsgs(ctx, err, len(msgs))
+	return err
+}
+
+func (k *KafkaSink) writeOpenTxn(ctx api.StreamContext, msgs []kafkago.Message) error {
+	if k.openTxn == nil {
+		if err := k.beginTxn(ctx); err != nil {
+			// the messages are dropped, so the checkpoint cannot pre-commit
+			k.openTxn = &openTxn{err: err}
+			return err
+		}
+	}
+	txn := k.openTxn
+	if txn.err != nil {
+		return txn.err
+	}
+	err := k.writeTxn(ctx, txn, msgs)
+	if err != nil {
+		tid := txn.producer.transactionalId
+		if len(txn.partitions) > 0 {
+			aborted := &pendingTxn{TransactionalId: tid, ProducerId: txn.producer.producerId, ProducerEpoch: txn.producer.producerEpoch}
+			if aerr := k.endTxn(ctx, aborted, false); aerr != nil {
+				ctx.GetLogger().Warnf("abort transaction %s error: %v", tid, aerr)
+			}
+		}
+		// the sequences are unknown after the failure, so the producer is initialized again with a new epoch
+		delete(k.producers, tid)
+		txn.err = fmt.Errorf("transaction %s is aborted: %v", tid, err)
+		return txn.err
+	}
+	return nil
+}
+
+// beginTxn opens a transaction with a transactional id which is not used by the pending transactions. Each
+// transactional id can only have one open transaction at the same time.
+func (k *KafkaSink) beginTxn(ctx api.StreamContext) error {
+	used := make(map[string]struct{}, len(k.pendingTxns))
+	for _, txn := range k.pendingTxns {
+		used[txn.TransactionalId] = struct{}{}
+	}
+	tid := ""
+	for i := 0; i < k.kc.TransactionPoolSize; i++ {
+		if _, ok := used[k.transactionalId(i)]; !ok {
+			tid = k.transactionalId(i)
+			break
+		}
+	}
+	if tid == "" {
+		return fmt.Errorf("all %d transactional ids are in use by the pending transactions", k.kc.TransactionPoolSize)
+	}
+	p, ok := k.producers[tid]
+	if !ok {
+		var err error
+		p, err = k.initProducer(ctx, tid)
+		if err != nil {
+			return err
+		}
+	}
+	k.openTxn = &openTxn{producer: p, partitions: make(map[topicPartition]struct{})}
+	// refresh the partitions of the topics for each transaction
+	k.topicPartitions = make(map[string][]int)
+	ctx.GetLogger().Debugf("begin transaction %s", tid)
+	return nil
+}
+
+// Commit commits the pending transactions of the completed checkpoint and the checkpoints before it. The transactions
+// which fail to commit for a temporary error are retried when the next checkpoint completes.
+func (k *KafkaSink) Commit(ctx api.StreamContext, checkpointId int64) error {
+	if !k.txnMode {
+		return nil
+	}
+	k.txnLock.Lock()
+	defer k.txnLock.Unlock()
+	var (
+		remain []*pendingTxn
+		errs   []error
+	)
+	for _, txn := range k.pendingTxns {
+		if txn.CheckpointId > checkpointId {
+			remain = append(remain, txn)
+			continue
+		}
+		err := k.endTxn(ctx, txn, true)
+		if err != nil {
+			if isRetriableTxnErr(err) {
+				remain = append(remain, txn)
+			} else {
+				delete(k.producers, txn.TransactionalId)
+			}
+			errs = append(errs, fmt.Errorf("commit transaction %s of checkpoint %d error: %v", txn.TransactionalId, txn.CheckpointId, err))
+			continue
+		}
+		ctx.GetLogger().Debugf("committed transaction %s of checkpoint %d", txn.TransactionalId, txn.CheckpointId)
+	}
+	k.pendingTxns = remain
+	err := errors.Join(errs...)
+	k.handleConnectedSch(err)
+	return err
+}
+
+// recoverTxns commits the pre-committed transactions of the restored checkpoint, and then aborts the dangling
+// transactions of all transactional ids, which were opened after the checkpoint.
+func (k *KafkaSink) recoverTxns(ctx api.StreamContext) error {
+	restored, err := ctx.GetState(txnStateKey)
+	if err != nil {
+		return err
+	}
+	if s, ok := restored.(string); ok && s != "" {
+		var txns []*pendingTxn
+		if err := json.Unmarshal([]byte(s), &txns); err != nil {
+			return fmt.Errorf("invalid kafka transaction state %s: %v", s, err)
+		}
+		for _, txn := range txns {
+			err := k.endTxn(ctx, txn, true)
+			switch {
+			case err == nil:
+				ctx.GetLogger().Infof("committed restored transaction %s of checkpoint %d", txn.TransactionalId, txn.CheckpointId)
+			case isRetriableTxnErr(err):
+				return fmt.Errorf("commit restored transaction %s error: %v", txn.TransactionalId, err)
+			default:
+				// The producer is fenced if the transaction was committed and the id was reused, or the transaction was
+				// aborted by timeout.
+				ctx.GetLogger().Warnf("cannot commit restored transaction %s of checkpoint %d: %v", txn.TransactionalId, txn.CheckpointId, err)
+			}
+		}
+	}
+	k.producers = make(map[string]*txnProducer, k.kc.TransactionPoolSize)
+	for i := 0; i < k.kc.TransactionPoolSize; i++ {
+		if _, err := k.initProducer(ctx, k.transactionalId(i)); err != nil {
+			return err
+		}
+	}
+	k.openTxn = nil
+	k.txnBuffer = nil
+	k.pendingTxns = nil
+	return k.saveTxnState(ctx)
+}
+
+func (k *KafkaSink) saveTxnState(ctx api.StreamContext) error {
+	b, err := json.Marshal(k.pendingTxns)
+	if err != nil {
+		return err
+	}
+	return ctx.PutState(txnStateKey, string(b))
+}
+
+func (k *KafkaSink) transactionalId(index int) string {
+	return fmt.Sprintf("%s-%d", k.kc.TransactionalIdPrefix, index)
+}
+
+// writeTxn writes the messages into the transaction. The partitions are added to the transaction before the first
+// write to them.
+func (k *KafkaSink) writeTxn(ctx api.StreamContext, txn *openTxn, msgs []kafkago.Message) error {
+	p := txn.producer
+	groups, order, err := k.partitionMessages(ctx, msgs)
+	if err != nil {
+		return err
+	}
+	topics := make(map[string][]kafkago.AddPartitionToTxn)
+	for _, tp := range order {
+		if _, ok := txn.partitions[tp]; !ok {
+			topics[tp.topic] = append(topics[tp.topic], kafkago.AddPartitionToTxn{Partition: tp.partition})
+		}
+	}
+	if len(topics) > 0 {
+		if err := k.addPartitions(ctx, p, topics); err != nil {
+			return err
+		}
+		for topic, partitions := range topics {
+			for _, ap := range partitions {
+				txn.partitions[topicPartition{topic: topic, partition: ap.Partition}] = struct{}{}
+			}
+		}
+	}
+	for _, tp := range order {
+		msgs := groups[tp]
+		for len(msgs) > 0 {
+			n := k.batchCount(msgs)
+			batch, err := encodeTxnBatch(msgs[:n], toCompression(k.kc.Compression), p.producerId, p.producerEpoch, p.sequences[tp])
+			if err != nil {
+				return err
+			}
+			resp, err := k.txnClient.RawProduce(ctx, &kafkago.RawProduceRequest{
+				Topic:           tp.topic,
+				Partition:       tp.partition,
+				RequiredAcks:    kafkago.RequireAll,
+				TransactionalID: p.transactionalId,
+				RawRecords:      protocol.RawRecordSet{Reader: bytes.NewReader(batch)},
+			})
+			if err == nil && resp.Error != nil {
+				err = resp.Error
+			}
+			if err != nil {
+				return fmt.Errorf("write to topic %s partition %d in transaction %s error: %v", tp.topic, tp.partition, p.transactionalId, err)
+			}
+			p.sequences[tp] += n
+			msgs = msgs[n:]
+		}
+	}
+	return nil
+}
+
+func (k *KafkaSink) addPartitions(ctx api.StreamContext, p *txnProducer, topics map[string][]kafkago.AddPartitionToTxn) error {
+	err := k.retryTxnRequest(ctx, func() error {
+		resp, err := k.txnClient.AddPartitionsToTxn(ctx, &kafkago.AddPartitionsToTxnRequest{
+			TransactionalID: p.transactionalId,
+			ProducerID:      p.producerId,
+			ProducerEpoch:   p.producerEpoch,
+			Topics:          topics,
+		})
+		if err != nil {
+			return err
+		}
+		for _, partitions := range resp.Topics {
+			for _, p := range partitions {
+				if p.Error != nil {
+					return p.Error
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("add partitions to transaction %s error: %v", p.transactionalId, err)
+	}
+	return nil
+}
+
+// partitionMessages groups the messages by the topic partitions with the same balancer as the writer. The partitions
+// of the topics are fetched once for each transaction.
+func (k *KafkaSink) partitionMessages(ctx api.StreamContext, msgs []kafkago.Message) (map[topicPartition][]kafkago.Message, []topicPartition, error) {
+	var topics []string
+	seen := make(map[string]struct{})
+	for _, msg := range msgs {
+		if _, ok := seen[msg.Topic]; ok {
+			continue
+		}
+		seen[msg.Topic] = struct{}{}
+		if _, ok := k.topicPartitions[msg.Topic]; !ok {
+			topics = append(topics, msg.Topic)
+		}
+	}
+	if len(topics) > 0 {
+		meta, err := k.txnClient.Metadata(ctx, &kafkago.MetadataRequest{Topics: topics})
+		if err != nil {
+			return nil, nil, fmt.Errorf("get metadata of topics %v error: %v", topics, err)
+		}
+		for _, t := range meta.Topics {
+			if t.Error != nil {
+				return nil, nil, fmt.Errorf("get metadata of topic %s error: %v", t.Name, t.Error)
+			}
+			ps := make([]int, 0, len(t.Partitions))
+			for _, p := range t.Partitions {
+				ps = append(ps, p.ID)
+			}
+			k.topicPartitions[t.Name] = ps
+		}
+	}
+	partitions := k.topicPartitions
+	balancer := &kafkago.Murmur2Balancer{}
+	groups := make(map[topicPartition][]kafkago.Message)
+	var order []topicPartition
+	for _, msg := range msgs {
+		ps := partitions[msg.Topic]
+		if len(ps) == 0 {
+			return nil, nil, fmt.Errorf("topic %s has no partition", msg.Topic)
+		}
+		tp := topicPartition{topic: msg.Topic, partition: balancer.Balance(msg, ps...)}
+		if _, ok := groups[tp]; !ok {
+			order = append(order, tp)
+		}
+		groups[tp] = append(groups[tp], msg)
+	}
+	return groups, order, nil
+}
+
+// batchCount returns how many messages can be written in a batch limited by batchBytes. The broker accepts only one
+// batch per partition in a transactional request.
+func (k *KafkaSink) batchCount(msgs []kafkago.Message) int {
+	size := int64(0)
+	for i, msg := range msgs {
+		size += int64(len(msg.Key) + len(msg.Value))
+		for _, h := range msg.Headers {
+			size += int64(len(h.Key) + len(h.Value))
+		}
+		if i > 0 && k.kc.BatchBytes > 0 && size > k.kc.BatchBytes {
+			return i
+		}
+	}
+	return len(msgs)
+}
+
+// initProducer initializes the producer of the transactional id, which aborts its open transaction if any. It is
+// only called again after a transaction of the id failed.
+func (k *KafkaSink) initProducer(ctx api.StreamContext, tid string) (*txnProducer, error) {
+	var session *kafkago.ProducerSession
+	err := k.retryTxnRequest(ctx, func() error {
+		resp, err := k.txnClient.InitProducerID(ctx, &kafkago.InitProducerIDRequest{
+			TransactionalID:      tid,
+			TransactionTimeoutMs: int(k.kc.TransactionTimeout.Milliseconds()),
+		})
+		if err != nil {
+			return err
+		}
+		if resp.Error != nil {
+			return resp.Error
+		}
+		session = resp.Producer
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("init producer of transactional id %s error: %v", tid, err)
+	}
+	p := &txnProducer{
+		transactionalId: tid,
+		producerId:      session.ProducerID,
+		producerEpoch:   session.ProducerEpoch,
+		sequences:       make(map[topicPartition]int),
+	}
+	k.producers[tid] = p
+	return p, nil
+}
+
+func (k *KafkaSink) endTxn(ctx api.StreamContext, txn *pendingTxn, commit bool) error {
+	return k.retryTxnRequest(ctx, func() error {
+		resp, err := k.txnClient.EndTxn(ctx, &kafkago.EndTxnRequest{
+			TransactionalID: txn.TransactionalId,
+			ProducerID:      txn.ProducerId,
+			ProducerEpoch:   txn.ProducerEpoch,
+			Committed:       commit,
+		})
+		if err != nil {
+			return err
+		}
+		return resp.Error
+	})
+}
+
+// retryTxnRequest retries the request for maxAttempts if the coordinator is not available or busy
+func (k *KafkaSink) retryTxnRequest(ctx api.StreamContext, f func() error) error {
+	backoff := txnRetryBackoff
+	var err error
+	for i := 0; i < max(k.kc.MaxAttempts, 1); i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return err
+			case <-timex.After(backoff):
+			}
+			backoff *= 2
+		}
+		err = f()
+		if err == nil || !isRetriableTxnErr(err) {
+			return err
+		}
+	}
+	return err
+}
+
+func isRetriableTxnErr(err error) bool {
+	var kerr kafkago.Error
+	if errors.As(err, &kerr) {
+		return kerr.Temporary() || kerr == kafkago.ConcurrentTransactions
+	}
+	// network errors
+	return true
+}
@@ -0,0 +1,117 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration
+
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/testx"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+// TestKafkaSinkTxnIntegration runs the transactions against a real broker set by KAFKA_BROKERS, localhost:9092 by default.
+// The committed messages are visible to a read committed consumer while the aborted ones are not.
+func TestKafkaSinkTxnIntegration(t *testing.T) {
+	brokers := os.Getenv("KAFKA_BROKERS")
+	if brokers == "" {
+		brokers = "localhost:9092"
+	}
+	// The retries of the transaction requests wait by the real clock
+	timex.Clock = clock.New()
+	defer timex.InitClock()
+
+	suffix := time.Now().UnixNano()
+	topic := fmt.Sprintf("ekuiper-txn-%d", suffix)
+	client := &kafkago.Client{Addr: kafkago.TCP(brokers)}
+	resp, err := client.CreateTopics(context.Background(), &kafkago.CreateTopicsRequest{
+		Topics: []kafkago.TopicConfig{{Topic: topic, NumPartitions: 1, ReplicationFactor: 1}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, resp.Errors[topic])
+
+	ctx := mockContext.NewMockContext("TestKafkaSinkTxnIntegration", "kafka1")
+	configs := map[string]any{
+		"topic":                 topic,
+		"brokers":               brokers,
+		"transactional":         true,
+		"transactionalIdPrefix": fmt.Sprintf("ekuiper-it-%d", suffix),
+		"transactionPoolSize":   2,
+		"batchSize":             1,
+		"maxAttempts":           10,
+	}
+	connect := func() *KafkaSink {
+		ks := &KafkaSink{}
+		require.NoError(t, ks.Provision(ctx, configs))
+		ks.EnableTwoPhaseCommit()
+		require.NoError(t, ks.Connect(ctx, func(status string, message string) {}))
+		return ks
+	}
+	collect := func(ks *KafkaSink, values ...string) {
+		for _, v := range values {
+			require.NoError(t, ks.Collect(ctx, &testx.MockRawTuple{Content: []byte(v)}))
+		}
+	}
+
+	// commit
+	ks := connect()
+	collect(ks, "a1", "a2")
+	require.NoError(t, ks.PreCommit(ctx, 1))
+	require.NoError(t, ks.Commit(ctx, 1))
+	// pre-committed but the checkpoint does not complete before the crash
+	collect(ks, "b1")
+	require.NoError(t, ks.PreCommit(ctx, 2))
+	// written after the checkpoint, which is aborted after restart
+	collect(ks, "c1")
+	require.NoError(t, ks.Close(ctx))
+
+	// restart, the pre-committed transaction is committed and the dangling one is aborted
+	ks = connect()
+	collect(ks, "d1")
+	require.NoError(t, ks.PreCommit(ctx, 3))
+	require.NoError(t, ks.Commit(ctx, 3))
+	require.NoError(t, ks.Close(ctx))
+
+	reader := kafkago.NewReader(kafkago.ReaderConfig{
+		Brokers:        []string{brokers},
+		Topic:          topic,
+		Partition:      0,
+		IsolationLevel: kafkago.ReadCommitted,
+		MaxWait:        100 * time.Millisecond,
+	})
+	defer reader.Close()
+	var result []string
+	for {
+		rctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		msg, err := reader.ReadMessage(rctx)
+		cancel()
+		if err != nil {
+			require.ErrorIs(t, err, context.DeadlineExceeded)
+			break
+		}
+		result = append(result, string(msg.Value))
+	}
+	require.Equal(t, []string{"a1", "a2", "b1", "d1"}, result)
+}
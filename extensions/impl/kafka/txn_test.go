@@ -0,0 +1,194 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/protocol"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/testx"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+type mockTxnClient struct {
+	sync.Mutex
+	epoch       int
+	inits       []string
+	added       map[string]int
+	produced    map[string][]int
+	ended       map[string]bool
+	failEnd     error
+	failProduce error
+}
+
+func newMockTxnClient() *mockTxnClient {
+	return &mockTxnClient{added: map[string]int{}, produced: map[string][]int{}, ended: map[string]bool{}}
+}
+
+func (m *mockTxnClient) Metadata(_ context.Context, req *kafkago.MetadataRequest) (*kafkago.MetadataResponse, error) {
+	resp := &kafkago.MetadataResponse{}
+	for _, topic := range req.Topics {
+		resp.Topics = append(resp.Topics, kafkago.Topic{Name: topic, Partitions: []kafkago.Partition{{Topic: topic, ID: 0}}})
+	}
+	return resp, nil
+}
+
+func (m *mockTxnClient) InitProducerID(_ context.Context, req *kafkago.InitProducerIDRequest) (*kafkago.InitProducerIDResponse, error) {
+	m.Lock()
+	defer m.Unlock()
+	m.epoch++
+	m.inits = append(m.inits, req.TransactionalID)
+	return &kafkago.InitProducerIDResponse{Producer: &kafkago.ProducerSession{ProducerID: 1, ProducerEpoch: m.epoch}}, nil
+}
+
+func (m *mockTxnClient) AddPartitionsToTxn(_ context.Context, req *kafkago.AddPartitionsToTxnRequest) (*kafkago.AddPartitionsToTxnResponse, error) {
+	m.Lock()
+	defer m.Unlock()
+	m.added[req.TransactionalID]++
+	return &kafkago.AddPartitionsToTxnResponse{}, nil
+}
+
+func (m *mockTxnClient) RawProduce(_ context.Context, req *kafkago.RawProduceRequest) (*kafkago.ProduceResponse, error) {
+	m.Lock()
+	defer m.Unlock()
+	if m.failProduce != nil {
+		return nil, m.failProduce
+	}
+	rs := &protocol.RecordSet{}
+	if _, err := rs.ReadFrom(req.RawRecords.Reader); err != nil {
+		return nil, err
+	}
+	batch := rs.Records.(*protocol.RecordStream).Records[0].(*protocol.RecordBatch)
+	m.produced[req.TransactionalID] = append(m.produced[req.TransactionalID], int(batch.BaseSequence))
+	return &kafkago.ProduceResponse{}, nil
+}
+
+func (m *mockTxnClient) EndTxn(_ context.Context, req *kafkago.EndTxnRequest) (*kafkago.EndTxnResponse, error) {
+	m.Lock()
+	defer m.Unlock()
+	if m.failEnd != nil {
+		return &kafkago.EndTxnResponse{Error: m.failEnd}, nil
+	}
+	m.ended[req.TransactionalID] = req.Committed
+	return &kafkago.EndTxnResponse{}, nil
+}
+
+func TestKafkaSinkTxn(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule1", "kafka1")
+	configs := map[string]any{
+		"topic":               "t",
+		"brokers":             "localhost:9092",
+		"transactional":       true,
+		"transactionPoolSize": 2,
+		"batchSize":           2,
+		"batchBytes":          10,
+	}
+	client := newMockTxnClient()
+	ks := &KafkaSink{txnClient: client}
+	require.NoError(t, ks.Provision(ctx, configs))
+	ks.EnableTwoPhaseCommit()
+	require.NoError(t, ks.Connect(ctx, func(status string, message string) {}))
+	require.Equal(t, []string{"ekuiper-rule1-kafka1-0", "ekuiper-rule1-kafka1-1"}, client.inits)
+
+	// written into the open transaction once the batch size is reached
+	require.NoError(t, ks.Collect(ctx, &testx.MockRawTuple{Content: []byte(`{"a":1}`)}))
+	require.Empty(t, client.produced)
+	require.NoError(t, ks.Collect(ctx, &testx.MockRawTuple{Content: []byte(`{"a":1}`)}))
+	// split by batchBytes with the continuous sequences
+	require.Equal(t, []int{0, 1}, client.produced["ekuiper-rule1-kafka1-0"])
+	require.NoError(t, ks.Collect(ctx, &testx.MockRawTuple{Content: []byte(`{"a":1}`)}))
+	require.Len(t, ks.txnBuffer, 1)
+	require.NoError(t, ks.PreCommit(ctx, 1))
+	require.Equal(t, []int{0, 1, 2}, client.produced["ekuiper-rule1-kafka1-0"])
+	// the partition is added once for the transaction
+	require.Equal(t, 1, client.added["ekuiper-rule1-kafka1-0"])
+
+	require.NoError(t, ks.Collect(ctx, &testx.MockRawTuple{Content: []byte(`{"a":2}`)}))
+	require.NoError(t, ks.PreCommit(ctx, 2))
+	require.Equal(t, []int{0}, client.produced["ekuiper-rule1-kafka1-1"])
+	// no free transactional id, the checkpoint fails
+	require.NoError(t, ks.Collect(ctx, &testx.MockRawTuple{Content: []byte(`{"a":3}`)}))
+	require.EqualError(t, ks.Collect(ctx, &testx.MockRawTuple{Content: []byte(`{"a":3}`)}), "all 2 transactional ids are in use by the pending transactions")
+	require.EqualError(t, ks.PreCommit(ctx, 3), "all 2 transactional ids are in use by the pending transactions")
+
+	require.NoError(t, ks.Commit(ctx, 1))
+	require.Equal(t, map[string]bool{"ekuiper-rule1-kafka1-0": true}, client.ended)
+	require.Len(t, ks.pendingTxns, 1)
+	// the producer is reused by the next transaction of the id, and the sequences continue
+	require.NoError(t, ks.Collect(ctx, &testx.MockRawTuple{Content: []byte(`{"a":4}`)}))
+	require.NoError(t, ks.PreCommit(ctx, 4))
+	require.Equal(t, []int{0, 1, 2, 3}, client.produced["ekuiper-rule1-kafka1-0"])
+	require.Len(t, client.inits, 2)
+	require.NoError(t, ks.Close(ctx))
+
+	// restart, the pending transactions in the state are committed
+	client = newMockTxnClient()
+	ks = &KafkaSink{txnClient: client}
+	require.NoError(t, ks.Provision(ctx, configs))
+	ks.EnableTwoPhaseCommit()
+	require.NoError(t, ks.Connect(ctx, func(status string, message string) {}))
+	require.Equal(t, map[string]bool{"ekuiper-rule1-kafka1-0": true, "ekuiper-rule1-kafka1-1": true}, client.ended)
+	require.Len(t, ks.pendingTxns, 0)
+
+	// the write fails, the transaction is aborted and the producer is initialized again
+	client.ended = map[string]bool{}
+	client.failProduce = errors.New("write error")
+	require.NoError(t, ks.Collect(ctx, &testx.MockRawTuple{Content: []byte(`{"a":5}`)}))
+	require.Error(t, ks.Collect(ctx, &testx.MockRawTuple{Content: []byte(`{"a":5}`)}))
+	require.Equal(t, map[string]bool{"ekuiper-rule1-kafka1-0": false}, client.ended)
+	require.EqualError(t, ks.PreCommit(ctx, 5), "transaction ekuiper-rule1-kafka1-0 is aborted: write to topic t partition 0 in transaction ekuiper-rule1-kafka1-0 error: write error")
+	client.failProduce = nil
+	require.NoError(t, ks.Collect(ctx, &testx.MockRawTuple{Content: []byte(`{"a":6}`)}))
+	require.NoError(t, ks.PreCommit(ctx, 6))
+	require.Equal(t, []string{"ekuiper-rule1-kafka1-0", "ekuiper-rule1-kafka1-1", "ekuiper-rule1-kafka1-0"}, client.inits)
+	require.NoError(t, ks.Close(ctx))
+
+	// commit fails with a non retriable error
+	client.failEnd = kafkago.ProducerFenced
+	require.Error(t, ks.Commit(ctx, 6))
+	require.Len(t, ks.pendingTxns, 0)
+	require.NotContains(t, ks.producers, "ekuiper-rule1-kafka1-0")
+}
+
+func TestKafkaSinkTxnDisabled(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule1", "kafka1")
+	client := newMockTxnClient()
+	ks := &KafkaSink{txnClient: client}
+	require.NoError(t, ks.Provision(ctx, map[string]any{
+		"topic":         "t",
+		"brokers":       "localhost:9092",
+		"transactional": true,
+	}))
+	// qos is not exactly once
+	require.NoError(t, ks.Connect(ctx, func(status string, message string) {}))
+	require.False(t, ks.txnMode)
+	require.NoError(t, ks.PreCommit(ctx, 1))
+	require.NoError(t, ks.Commit(ctx, 1))
+	require.Len(t, client.inits, 0)
+	require.NoError(t, ks.Close(ctx))
+
+	require.Error(t, ks.Provision(ctx, map[string]any{
+		"topic":               "t",
+		"brokers":             "localhost:9092",
+		"transactional":       true,
+		"transactionPoolSize": 0,
+	}))
+}
@@ -35,11 +35,26 @@ var supportedIncAggFunc = map[string]struct{}{
 	"last_value": {},
 }
 
+// supportedIncDistinctAggFunc are the incremental aggregate functions which support the DISTINCT modifier
+var supportedIncDistinctAggFunc = map[string]struct{}{
+	"count":   {},
+	"avg":     {},
+	"max":     {},
+	"min":     {},
+	"sum":     {},
+	"collect": {},
+}
+
 func IsSupportedIncAgg(name string) bool {
 	_, ok := supportedIncAggFunc[name]
 	return ok
 }
 
+func IsSupportedIncDistinctAgg(name string) bool {
+	_, ok := supportedIncDistinctAggFunc[name]
+	return ok
+}
+
 func registerIncAggFunc() {
 	builtins["inc_count"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
@@ -161,6 +176,53 @@ func registerIncAggFunc() {
 		val:   ValidateTwoNumberArg,
 		check: returnNilIfHasAnyNil,
 	}
+	for name := range supportedIncDistinctAggFunc {
+		registerIncDistinctAggFunc(name)
+	}
+}
+
+// registerIncDistinctAggFunc registers inc_xx_distinct which wraps inc_xx to only accumulate the values not seen before
+func registerIncDistinctAggFunc(name string) {
+	f := builtins["inc_"+name]
+	builtins[fmt.Sprintf("inc_%s_distinct", name)] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			return incrementalDistinct(ctx, args, f.exec)
+		},
+		val:   f.val,
+		check: f.check,
+	}
+}
+
+// incrementalDistinct feeds the value to the incremental function only if it is not seen before.
+// For a seen value, the last result is returned.
+func incrementalDistinct(ctx api.FunctionContext, args []interface{}, exec funcExe) (interface{}, bool) {
+	seenKey := fmt.Sprintf("%v_inc_distinct", ctx.GetFuncId())
+	resultKey := fmt.Sprintf("%v_inc_distinct_result", ctx.GetFuncId())
+	v, err := ctx.GetState(seenKey)
+	if err != nil {
+		return err, false
+	}
+	seen, _ := v.(map[string]interface{})
+	key := cast.ToKey(args[0])
+	if _, ok := seen[key]; ok {
+		r, err := ctx.GetState(resultKey)
+		if err != nil {
+			return err, false
+		}
+		return r, true
+	}
+	r, ok := exec(ctx, args)
+	if !ok {
+		return r, false
+	}
+	if seen == nil {
+		seen = make(map[string]interface{})
+		ctx.PutState(seenKey, seen)
+	}
+	seen[key] = true
+	ctx.PutState(resultKey, r)
+	return r, true
 }
 
 func incrementalLastValue(ctx api.FunctionContext, arg interface{}, ignoreNil bool) (interface{}, error) {
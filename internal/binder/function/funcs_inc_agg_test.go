@@ -108,6 +108,49 @@ func TestIncAggFunction(t *testing.T) {
 	}
 }
 
+func TestIncDistinctAggFunction(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	registerIncAggFunc()
+	testcases := []struct {
+		funcName string
+		args     [][]interface{}
+		outputs  []interface{}
+	}{
+		{
+			funcName: "inc_count_distinct",
+			args:     [][]interface{}{{1}, {1}, {2}},
+			outputs:  []interface{}{int64(1), int64(1), int64(2)},
+		},
+		{
+			funcName: "inc_sum_distinct",
+			args:     [][]interface{}{{3}, {3}, {1}},
+			outputs:  []interface{}{float64(3), float64(3), float64(4)},
+		},
+		{
+			funcName: "inc_collect_distinct",
+			args:     [][]interface{}{{"a"}, {"b"}, {"a"}},
+			outputs:  []interface{}{[]interface{}{"a"}, []interface{}{"a", "b"}, []interface{}{"a", "b"}},
+		},
+		{
+			funcName: "inc_collect_distinct",
+			args:     [][]interface{}{{1}, {"1"}, {1.0}, {"1"}},
+			outputs:  []interface{}{[]interface{}{1}, []interface{}{1, "1"}, []interface{}{1, "1"}, []interface{}{1, "1"}},
+		},
+	}
+	for index, tc := range testcases {
+		ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+		tempStore, _ := state.CreateStore(tc.funcName, def.AtMostOnce)
+		fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), index)
+		f, ok := builtins[tc.funcName]
+		require.True(t, ok, tc.funcName)
+		for i, args := range tc.args {
+			got, ok := f.exec(fctx, args)
+			require.True(t, ok, tc.funcName)
+			require.Equal(t, tc.outputs[i], got, tc.funcName)
+		}
+	}
+}
+
 func TestIncAggFunctionErr(t *testing.T) {
 	contextLogger := conf.Log.WithField("rule", "testExec")
 	registerIncAggFunc()
@@ -1,4 +1,4 @@
-// Copyright 2021-2024 EMQ Technologies Co., Ltd.
+// Copyright 2021-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -18,15 +18,24 @@ import (
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
 	"github.com/lf-edge/ekuiper/v2/internal/binder"
+	"github.com/lf-edge/ekuiper/v2/internal/io/coap"
 	"github.com/lf-edge/ekuiper/v2/internal/io/file"
 	"github.com/lf-edge/ekuiper/v2/internal/io/http"
 	"github.com/lf-edge/ekuiper/v2/internal/io/http/httpserver"
 	"github.com/lf-edge/ekuiper/v2/internal/io/memory"
+	"github.com/lf-edge/ekuiper/v2/internal/io/modbus"
 	"github.com/lf-edge/ekuiper/v2/internal/io/mqtt"
+	"github.com/lf-edge/ekuiper/v2/internal/io/nats"
 	"github.com/lf-edge/ekuiper/v2/internal/io/neuron"
 	"github.com/lf-edge/ekuiper/v2/internal/io/nexmark"
+	"github.com/lf-edge/ekuiper/v2/internal/io/opcua"
+	"github.com/lf-edge/ekuiper/v2/internal/io/otlp"
+	"github.com/lf-edge/ekuiper/v2/internal/io/prometheus"
+	"github.com/lf-edge/ekuiper/v2/internal/io/s3"
 	"github.com/lf-edge/ekuiper/v2/internal/io/simulator"
 	"github.com/lf-edge/ekuiper/v2/internal/io/sink"
+	"github.com/lf-edge/ekuiper/v2/internal/io/socket"
+	"github.com/lf-edge/ekuiper/v2/internal/io/syslog"
 	"github.com/lf-edge/ekuiper/v2/internal/io/websocket"
 	plugin2 "github.com/lf-edge/ekuiper/v2/internal/plugin"
 	"github.com/lf-edge/ekuiper/v2/pkg/modules"
@@ -40,6 +49,15 @@ func init() {
 	modules.RegisterSource("file", file.GetSource)
 	modules.RegisterSource("memory", func() api.Source { return memory.GetSource() })
 	modules.RegisterSource("neuron", neuron.GetSource)
+	modules.RegisterSource("nats", nats.GetSource)
+	modules.RegisterSource("modbus", modbus.GetSource)
+	modules.RegisterSource("opcua", opcua.GetSource)
+	modules.RegisterSource("opcuapull", opcua.GetPullSource)
+	modules.RegisterSource("coap", coap.GetSource)
+	modules.RegisterSource("syslog", syslog.GetSource)
+	modules.RegisterSource("socket", socket.GetSource)
+	modules.RegisterSource("prometheus", prometheus.GetSource)
+	modules.RegisterSource("otlp", otlp.GetSource)
 	modules.RegisterSource("websocket", func() api.Source { return websocket.GetSource() })
 	modules.RegisterSource("simulator", func() api.Source { return simulator.GetSource() })
 	modules.RegisterSource("nexmark", func() api.Source { return nexmark.GetSource() })
@@ -51,15 +69,23 @@ func init() {
 	modules.RegisterSink("nop", func() api.Sink { return &sink.NopSink{} })
 	modules.RegisterSink("memory", func() api.Sink { return memory.GetSink() })
 	modules.RegisterSink("neuron", neuron.GetSink)
+	modules.RegisterSink("nats", nats.GetSink)
+	modules.RegisterSink("coap", coap.GetSink)
+	modules.RegisterSink("socket", socket.GetSink)
+	modules.RegisterSink("prometheus", prometheus.GetSink)
+	modules.RegisterSink("s3", s3.GetSink)
 	modules.RegisterSink("file", file.GetSink)
 	modules.RegisterSink("websocket", func() api.Sink { return websocket.GetSink() })
 
 	modules.RegisterLookupSource("memory", memory.GetLookupSource)
 	modules.RegisterLookupSource("httppull", http.GetLookUpSource)
+	modules.RegisterLookupSource("modbus", modbus.GetLookupSource)
 	modules.RegisterLookupSource("simulator", func() api.Source { return &simulator.SimulatorLookupSource{} })
 
 	modules.RegisterConnection("mqtt", mqtt.CreateConnection)
 	modules.RegisterConnection("nng", nng.CreateConnection)
+	modules.RegisterConnection("nats", nats.CreateConnection)
+	modules.RegisterConnection("opcua", opcua.CreateConnection)
 	modules.RegisterConnection("httppush", httpserver.CreateConnection)
 	modules.RegisterConnection("websocket", httpserver.CreateWebsocketConnection)
 }
@@ -10,4 +10,6 @@ import (
 func init() {
 	modules.RegisterSchemaType(modules.PROTOBUF, &schema.PbType{}, ".proto")
 	modules.RegisterSchemaType(modules.CUSTOM, &schema.CustomType{}, ".so")
+	modules.RegisterSchemaType(modules.AVRO, &schema.AvroType{}, ".avsc")
+	modules.RegisterSchemaType(modules.JSON, &schema.JsonSchemaType{}, ".json")
 }
@@ -276,6 +276,11 @@ func ValidateRuleOption(option *def.RuleOption) error {
 		Log.Warnf("lateTol is negative, set to 1 second")
 		errs = errors.Join(errs, errors.New("invalidLateTol:lateTol must be greater than 0"))
 	}
+	if option.AllowedLateness < 0 {
+		option.AllowedLateness = 0
+		Log.Warnf("allowedLateness is negative, set to 0")
+		errs = errors.Join(errs, errors.New("invalidAllowedLateness:allowedLateness must be greater than 0"))
+	}
 	if option.RestartStrategy != nil {
 		if option.RestartStrategy.Attempts < 0 {
 			option.RestartStrategy.Attempts = 0
@@ -0,0 +1,194 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package avro
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/hamba/avro/v2"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
+)
+
+// magicByte is the first byte of the Confluent wire format, followed by the 4 bytes big endian schema id
+const (
+	magicByte  = 0
+	headerSize = 5
+)
+
+type Conf struct {
+	// SchemaRegistryUrl is the url of the Confluent compatible schema registry. If set, the payload is framed with the schema id.
+	SchemaRegistryUrl string `json:"schemaRegistryUrl"`
+	// SchemaRegistrySubject is the subject to register or fetch the writer schema when encoding
+	SchemaRegistrySubject  string `json:"schemaRegistrySubject"`
+	SchemaRegistryUsername string `json:"schemaRegistryUsername"`
+	SchemaRegistryPassword string `json:"schemaRegistryPassword"`
+}
+
+type Converter struct {
+	// schema is the local schema. It is the writer schema to encode and the reader schema to decode.
+	schema avro.Schema
+	// schemaText is the original schema definition to register which keeps the defaults
+	schemaText string
+	registry   *registryClient
+	subject    string
+
+	// the writer schema and its id to encode with the registry, resolved on the first encoding
+	encodeLock   sync.Mutex
+	encodeId     int
+	encodeSchema avro.Schema
+	// the resolved schemas to decode by the writer schema id
+	decodeSchemas sync.Map
+}
+
+// NewConverter creates the avro converter by the local schema file and the registry props.
+// At least one of them must be set.
+func NewConverter(schemaFile string, props map[string]any) (message.Converter, error) {
+	c := &Conf{}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return nil, fmt.Errorf("invalid avro converter props: %v", err)
+	}
+	if schemaFile == "" && c.SchemaRegistryUrl == "" {
+		return nil, fmt.Errorf("avro format requires a schemaId or a schemaRegistryUrl")
+	}
+	r := &Converter{subject: c.SchemaRegistrySubject}
+	if schemaFile != "" {
+		content, err := os.ReadFile(schemaFile)
+		if err != nil {
+			return nil, fmt.Errorf("read schema file %s failed: %s", schemaFile, err)
+		}
+		r.schemaText = string(content)
+		r.schema, err = avro.Parse(r.schemaText)
+		if err != nil {
+			return nil, fmt.Errorf("parse schema file %s failed: %s", schemaFile, err)
+		}
+	}
+	if c.SchemaRegistryUrl != "" {
+		r.registry = newRegistryClient(c)
+	}
+	return r, nil
+}
+
+func (c *Converter) Encode(ctx api.StreamContext, d any) (b []byte, err error) {
+	defer func() {
+		if err != nil {
+			err = errorx.NewWithCode(errorx.CovnerterErr, err.Error())
+		}
+	}()
+	m, ok := d.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unsupported type %v, must be a map", d)
+	}
+	if c.registry == nil {
+		return marshal(c.schema, m)
+	}
+	id, schema, err := c.getEncodeSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := marshal(schema, m)
+	if err != nil {
+		return nil, err
+	}
+	b = make([]byte, headerSize, headerSize+len(payload))
+	b[0] = magicByte
+	binary.BigEndian.PutUint32(b[1:headerSize], uint32(id))
+	return append(b, payload...), nil
+}
+
+// getEncodeSchema caches the resolved writer schema. It retries on the next encoding if the registry fails.
+func (c *Converter) getEncodeSchema(ctx api.StreamContext) (int, avro.Schema, error) {
+	c.encodeLock.Lock()
+	defer c.encodeLock.Unlock()
+	if c.encodeSchema == nil {
+		id, schema, err := c.resolveEncodeSchema(ctx)
+		if err != nil {
+			return 0, nil, err
+		}
+		c.encodeId, c.encodeSchema = id, schema
+	}
+	return c.encodeId, c.encodeSchema, nil
+}
+
+// resolveEncodeSchema registers the local schema to the subject, or uses the latest schema of the subject if no local schema
+func (c *Converter) resolveEncodeSchema(ctx api.StreamContext) (int, avro.Schema, error) {
+	if c.subject == "" {
+		return 0, nil, fmt.Errorf("schemaRegistrySubject is required to encode avro with the schema registry")
+	}
+	if c.schema != nil {
+		id, err := c.registry.register(ctx, c.subject, c.schemaText)
+		return id, c.schema, err
+	}
+	return c.registry.latest(ctx, c.subject)
+}
+
+func (c *Converter) Decode(ctx api.StreamContext, b []byte) (m any, err error) {
+	defer func() {
+		if err != nil {
+			err = errorx.NewWithCode(errorx.CovnerterErr, err.Error())
+		}
+	}()
+	schema := c.schema
+	if c.registry != nil {
+		if len(b) < headerSize || b[0] != magicByte {
+			return nil, fmt.Errorf("invalid avro payload, missing the schema registry header")
+		}
+		id := int(binary.BigEndian.Uint32(b[1:headerSize]))
+		schema, err = c.decodeSchema(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		b = b[headerSize:]
+	}
+	var result any
+	if err = avro.Unmarshal(schema, b, &result); err != nil {
+		return nil, err
+	}
+	return normalize(result), nil
+}
+
+// decodeSchema gets the writer schema by id and resolves it with the local reader schema if any
+func (c *Converter) decodeSchema(ctx api.StreamContext, id int) (avro.Schema, error) {
+	if s, ok := c.decodeSchemas.Load(id); ok {
+		return s.(avro.Schema), nil
+	}
+	writer, err := c.registry.schemaById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	schema := writer
+	if c.schema != nil && c.schema.Fingerprint() != writer.Fingerprint() {
+		schema, err = avro.NewSchemaCompatibility().Resolve(c.schema, writer)
+		if err != nil {
+			return nil, fmt.Errorf("cannot resolve writer schema %d with the reader schema: %v", id, err)
+		}
+	}
+	c.decodeSchemas.Store(id, schema)
+	return schema, nil
+}
+
+func marshal(schema avro.Schema, m map[string]any) ([]byte, error) {
+	v, err := coerce(schema, m)
+	if err != nil {
+		return nil, err
+	}
+	return avro.Marshal(schema, v)
+}
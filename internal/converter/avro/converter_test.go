@@ -0,0 +1,194 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+const schemaFile = "../../schema/test/order.avsc"
+
+func TestNewConverterErr(t *testing.T) {
+	_, err := NewConverter("", map[string]any{})
+	require.EqualError(t, err, "avro format requires a schemaId or a schemaRegistryUrl")
+	_, err = NewConverter("notexist.avsc", map[string]any{})
+	require.EqualError(t, err, "read schema file notexist.avsc failed: open notexist.avsc: no such file or directory")
+}
+
+func TestEncodeDecode(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "op1")
+	c, err := NewConverter(schemaFile, map[string]any{})
+	require.NoError(t, err)
+	// the numbers are float64 and arrays are []any like the decoded json
+	b, err := c.Encode(ctx, map[string]any{
+		"id":     float64(1),
+		"price":  10.5,
+		"ts":     int64(1700000000000),
+		"status": "NEW",
+		"tags":   []any{"a", "b"},
+		"attrs":  map[string]any{"x": float64(2)},
+		"item":   map[string]any{"sku": "s1", "qty": 3},
+	})
+	require.NoError(t, err)
+	m, err := c.Decode(ctx, b)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"id":     int64(1),
+		"name":   nil,
+		"price":  10.5,
+		"ts":     time.UnixMilli(1700000000000).UTC(),
+		"status": "NEW",
+		"tags":   []any{"a", "b"},
+		"attrs":  map[string]any{"x": int64(2)},
+		"item":   map[string]any{"sku": "s1", "qty": int64(3)},
+	}, m)
+
+	_, err = c.Encode(ctx, map[string]any{"id": "abc"})
+	require.EqualError(t, err, "field id: cannot convert string(abc) to int64")
+	_, err = c.Encode(ctx, []any{1})
+	require.EqualError(t, err, "unsupported type [1], must be a map")
+	_, err = c.Decode(ctx, []byte{0x02})
+	require.Error(t, err)
+}
+
+// mockRegistry is a stub of the Confluent schema registry which keeps the schemas in memory
+type mockRegistry struct {
+	sync.Mutex
+	schemas  map[int]string
+	subjects map[string]int
+}
+
+func (r *mockRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.Lock()
+	defer r.Unlock()
+	var (
+		id      int
+		subject string
+	)
+	switch {
+	case req.Method == http.MethodGet && matchPath(req.URL.Path, "/schemas/ids/%d", &id):
+		s, ok := r.schemas[id]
+		if !ok {
+			http.Error(w, `{"error_code":40403,"message":"Schema not found"}`, http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"schema": s})
+	case req.Method == http.MethodPost && matchPath(req.URL.Path, "/subjects/%s", &subject):
+		body := map[string]string{}
+		_ = json.NewDecoder(req.Body).Decode(&body)
+		id = len(r.schemas) + 1
+		r.schemas[id] = body["schema"]
+		r.subjects[subject[:len(subject)-len("/versions")]] = id
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": id})
+	case req.Method == http.MethodGet && matchPath(req.URL.Path, "/subjects/%s", &subject):
+		id, ok := r.subjects[subject[:len(subject)-len("/versions/latest")]]
+		if !ok {
+			http.Error(w, `{"error_code":40401,"message":"Subject not found"}`, http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": id, "schema": r.schemas[id]})
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func matchPath(path string, format string, v any) bool {
+	n, err := fmt.Sscanf(path, format, v)
+	return err == nil && n == 1
+}
+
+func TestSchemaRegistry(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "op1")
+	content, err := os.ReadFile(schemaFile)
+	require.NoError(t, err)
+	registry := &mockRegistry{
+		schemas: map[int]string{
+			// a writer schema with fewer fields
+			1: `{"type":"record","name":"Order","namespace":"example","fields":[{"name":"id","type":"long"},{"name":"name","type":"string"}]}`,
+		},
+		subjects: map[string]int{},
+	}
+	server := httptest.NewServer(registry)
+	defer server.Close()
+
+	// encode with the local schema registered to the subject
+	sinkConverter, err := NewConverter(schemaFile, map[string]any{
+		"schemaRegistryUrl":     server.URL,
+		"schemaRegistrySubject": "orders-value",
+	})
+	require.NoError(t, err)
+	data := map[string]any{
+		"id":     int64(2),
+		"name":   "order2",
+		"price":  1.5,
+		"ts":     int64(1700000000000),
+		"status": "DONE",
+		"tags":   []any{"t"},
+		"attrs":  map[string]any{},
+		"item":   map[string]any{"sku": "s2", "qty": int64(1)},
+	}
+	b, err := sinkConverter.Encode(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, 0, 0, 0, 2}, b[:5])
+	assert.JSONEq(t, string(content), registry.schemas[2])
+
+	// decode by the writer schema from the registry
+	sourceConverter, err := NewConverter("", map[string]any{"schemaRegistryUrl": server.URL})
+	require.NoError(t, err)
+	m, err := sourceConverter.Decode(ctx, b)
+	require.NoError(t, err)
+	data["ts"] = time.UnixMilli(1700000000000).UTC()
+	assert.Equal(t, data, m)
+
+	// encode with the latest schema of the subject without local schema
+	latestConverter, err := NewConverter("", map[string]any{
+		"schemaRegistryUrl":     server.URL,
+		"schemaRegistrySubject": "orders-value",
+	})
+	require.NoError(t, err)
+	b2, err := latestConverter.Encode(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, b, b2)
+
+	// decode the message of the old writer schema with the local reader schema
+	readerConverter, err := NewConverter(schemaFile, map[string]any{"schemaRegistryUrl": server.URL})
+	require.NoError(t, err)
+	_, err = readerConverter.Decode(ctx, []byte{0, 0, 0, 0, 1, 0x02, 0x02, 'a'})
+	require.EqualError(t, err, "cannot resolve writer schema 1 with the reader schema: reader field price is missing in writer schema and has no default")
+
+	_, err = sourceConverter.Decode(ctx, []byte{0, 0, 0, 0, 1, 0x02, 0x02, 'a'})
+	require.NoError(t, err)
+	_, err = sourceConverter.Decode(ctx, []byte{1, 2})
+	require.EqualError(t, err, "invalid avro payload, missing the schema registry header")
+	_, err = sourceConverter.Decode(ctx, []byte{0, 0, 0, 0, 9, 0x02})
+	require.ErrorContains(t, err, "failed with status 404")
+
+	noSubject, err := NewConverter(schemaFile, map[string]any{"schemaRegistryUrl": server.URL})
+	require.NoError(t, err)
+	_, err = noSubject.Encode(ctx, data)
+	require.EqualError(t, err, "schemaRegistrySubject is required to encode avro with the schema registry")
+}
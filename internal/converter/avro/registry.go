@@ -0,0 +1,131 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package avro
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/httpx"
+)
+
+// schemaCache caches the immutable schemas by registry url and id, which are shared by all converters
+var schemaCache sync.Map
+
+// registryClient is a client of the Confluent compatible schema registry REST API
+type registryClient struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+type registrySchema struct {
+	Id     int    `json:"id"`
+	Schema string `json:"schema"`
+}
+
+func newRegistryClient(c *Conf) *registryClient {
+	headers := map[string]string{"Accept": "application/vnd.schemaregistry.v1+json, application/json"}
+	if c.SchemaRegistryUsername != "" {
+		auth := base64.StdEncoding.EncodeToString([]byte(c.SchemaRegistryUsername + ":" + c.SchemaRegistryPassword))
+		headers["Authorization"] = "Basic " + auth
+	}
+	return &registryClient{
+		url:     strings.TrimSuffix(c.SchemaRegistryUrl, "/"),
+		headers: headers,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// schemaById gets the writer schema by the id in the message header
+func (r *registryClient) schemaById(ctx api.StreamContext, id int) (avro.Schema, error) {
+	key := fmt.Sprintf("%s#%d", r.url, id)
+	if s, ok := schemaCache.Load(key); ok {
+		return s.(avro.Schema), nil
+	}
+	rs := &registrySchema{}
+	if err := r.do(ctx, http.MethodGet, fmt.Sprintf("%s/schemas/ids/%d", r.url, id), nil, rs); err != nil {
+		return nil, err
+	}
+	s, err := avro.Parse(rs.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("parse schema %d from registry failed: %v", id, err)
+	}
+	schemaCache.Store(key, s)
+	return s, nil
+}
+
+// register registers the schema to the subject and returns its id. It returns the existing id if already registered.
+func (r *registryClient) register(ctx api.StreamContext, subject string, schema string) (int, error) {
+	body, err := json.Marshal(map[string]string{"schema": schema})
+	if err != nil {
+		return 0, err
+	}
+	rs := &registrySchema{}
+	if err := r.do(ctx, http.MethodPost, fmt.Sprintf("%s/subjects/%s/versions", r.url, url.PathEscape(subject)), body, rs); err != nil {
+		return 0, err
+	}
+	return rs.Id, nil
+}
+
+// latest gets the id and the schema of the latest version of the subject
+func (r *registryClient) latest(ctx api.StreamContext, subject string) (int, avro.Schema, error) {
+	rs := &registrySchema{}
+	if err := r.do(ctx, http.MethodGet, fmt.Sprintf("%s/subjects/%s/versions/latest", r.url, url.PathEscape(subject)), nil, rs); err != nil {
+		return 0, nil, err
+	}
+	s, err := avro.Parse(rs.Schema)
+	if err != nil {
+		return 0, nil, fmt.Errorf("parse schema of subject %s from registry failed: %v", subject, err)
+	}
+	return rs.Id, s, nil
+}
+
+func (r *registryClient) do(ctx api.StreamContext, method string, u string, body []byte, result any) error {
+	var (
+		resp *http.Response
+		err  error
+	)
+	if body == nil {
+		resp, err = httpx.Send(ctx.GetLogger(), r.client, "none", method, u, r.headers, nil)
+	} else {
+		resp, err = httpx.Send(ctx.GetLogger(), r.client, "json", method, u, r.headers, body)
+	}
+	if err != nil {
+		return fmt.Errorf("schema registry request %s failed: %v", u, err)
+	}
+	defer resp.Body.Close()
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read schema registry response failed: %v", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("schema registry request %s failed with status %d: %s", u, resp.StatusCode, string(content))
+	}
+	if err := json.Unmarshal(content, result); err != nil {
+		return fmt.Errorf("invalid schema registry response %s: %v", string(content), err)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package avro
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"time"
+
+	"github.com/hamba/avro/v2"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+// coerce converts the value to the go type required by the avro schema. The values in eKuiper are usually
+// float64 for numbers and []any for arrays, which are not accepted by the strict avro encoder.
+func coerce(schema avro.Schema, v any) (any, error) {
+	switch s := schema.(type) {
+	case *avro.RefSchema:
+		return coerce(s.Schema(), v)
+	case *avro.RecordSchema:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expect map for record %s but got %[2]T(%[2]v)", s.FullName(), v)
+		}
+		result := make(map[string]any, len(s.Fields()))
+		for _, f := range s.Fields() {
+			fv, ok := m[f.Name()]
+			if !ok && f.HasDefault() {
+				continue
+			}
+			cv, err := coerce(f.Type(), fv)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %v", f.Name(), err)
+			}
+			result[f.Name()] = cv
+		}
+		return result, nil
+	case *avro.ArraySchema:
+		if v == nil {
+			return nil, fmt.Errorf("expect array but got nil")
+		}
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return nil, fmt.Errorf("expect array but got %[1]T(%[1]v)", v)
+		}
+		result := make([]any, rv.Len())
+		for i := range result {
+			cv, err := coerce(s.Items(), rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			result[i] = cv
+		}
+		return result, nil
+	case *avro.MapSchema:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expect map but got %[1]T(%[1]v)", v)
+		}
+		result := make(map[string]any, len(m))
+		for k, mv := range m {
+			cv, err := coerce(s.Values(), mv)
+			if err != nil {
+				return nil, err
+			}
+			result[k] = cv
+		}
+		return result, nil
+	case *avro.UnionSchema:
+		if v == nil {
+			if s.Nullable() {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("nil is not allowed by union %s", s.String())
+		}
+		var lastErr error
+		for _, t := range s.Types() {
+			if t.Type() == avro.Null {
+				continue
+			}
+			cv, err := coerce(t, v)
+			if err == nil {
+				return cv, nil
+			}
+			lastErr = err
+		}
+		return nil, lastErr
+	case *avro.EnumSchema:
+		return cast.ToString(v, cast.STRICT)
+	case *avro.FixedSchema:
+		return cast.ToBytes(v, cast.CONVERT_SAMEKIND)
+	case *avro.PrimitiveSchema:
+		return coercePrimitive(s, v)
+	default:
+		return v, nil
+	}
+}
+
+func coercePrimitive(s *avro.PrimitiveSchema, v any) (any, error) {
+	if s.Logical() != nil {
+		// time.Time is accepted by the time logical types
+		if _, ok := v.(time.Time); ok {
+			return v, nil
+		}
+	}
+	switch s.Type() {
+	case avro.Null:
+		if v != nil {
+			return nil, fmt.Errorf("expect null but got %[1]T(%[1]v)", v)
+		}
+		return nil, nil
+	case avro.Boolean:
+		return cast.ToBool(v, cast.STRICT)
+	case avro.Int:
+		return cast.ToInt(v, cast.CONVERT_SAMEKIND)
+	case avro.Long:
+		return cast.ToInt64(v, cast.CONVERT_SAMEKIND)
+	case avro.Float:
+		return cast.ToFloat32(v, cast.CONVERT_SAMEKIND)
+	case avro.Double:
+		return cast.ToFloat64(v, cast.CONVERT_SAMEKIND)
+	case avro.String:
+		return cast.ToString(v, cast.STRICT)
+	case avro.Bytes:
+		return cast.ToBytes(v, cast.CONVERT_SAMEKIND)
+	default:
+		return v, nil
+	}
+}
+
+// normalize converts the decoded avro values to the eKuiper types
+func normalize(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, mv := range t {
+			t[k] = normalize(mv)
+		}
+		return t
+	case []any:
+		for i, av := range t {
+			t[i] = normalize(av)
+		}
+		return t
+	case int:
+		return int64(t)
+	case int32:
+		return int64(t)
+	case float32:
+		return float64(t)
+	case time.Duration:
+		return t.Milliseconds()
+	case *big.Rat:
+		f, _ := t.Float64()
+		return f
+	default:
+		rv := reflect.ValueOf(v)
+		// fixed is decoded as byte array
+		if rv.Kind() == reflect.Array && rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return b
+		}
+		return v
+	}
+}
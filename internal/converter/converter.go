@@ -1,4 +1,4 @@
-// Copyright 2022-2025 EMQ Technologies Co., Ltd.
+// Copyright 2022-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -21,9 +21,12 @@ import (
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
 	"github.com/lf-edge/ekuiper/v2/internal/converter/binary"
+	"github.com/lf-edge/ekuiper/v2/internal/converter/cbor"
 	"github.com/lf-edge/ekuiper/v2/internal/converter/delimited"
 	"github.com/lf-edge/ekuiper/v2/internal/converter/json"
+	"github.com/lf-edge/ekuiper/v2/internal/converter/msgpack"
 	"github.com/lf-edge/ekuiper/v2/internal/converter/urlencoded"
+	"github.com/lf-edge/ekuiper/v2/internal/converter/xml"
 	"github.com/lf-edge/ekuiper/v2/internal/schema"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
@@ -32,8 +35,19 @@ import (
 )
 
 func init() {
-	modules.RegisterConverter(message.FormatJson, func(_ api.StreamContext, _ string, schema map[string]*ast.JsonStreamField, props map[string]any) (message.Converter, error) {
-		return json.NewFastJsonConverter(schema, props), nil
+	modules.RegisterConverter(message.FormatJson, func(_ api.StreamContext, schemaId string, schemaFields map[string]*ast.JsonStreamField, props map[string]any) (message.Converter, error) {
+		c := json.NewFastJsonConverter(schemaFields, props)
+		// the JSON schema is optional to validate the payload
+		if schemaId != "" {
+			ffs, err := schema.GetSchemaFile(modules.JSON, strings.Split(schemaId, ".")[0])
+			if err != nil {
+				return nil, err
+			}
+			if err := c.SetJsonSchema(ffs.SchemaFile); err != nil {
+				return nil, err
+			}
+		}
+		return c, nil
 	})
 	modules.RegisterConverter(message.FormatBinary, func(_ api.StreamContext, _ string, _ map[string]*ast.JsonStreamField, props map[string]any) (message.Converter, error) {
 		return binary.GetConverter()
@@ -44,6 +58,15 @@ func init() {
 	modules.RegisterConverter(message.FormatUrlEncoded, func(_ api.StreamContext, _ string, _ map[string]*ast.JsonStreamField, props map[string]any) (message.Converter, error) {
 		return urlencoded.NewConverter(props)
 	})
+	modules.RegisterConverter(message.FormatCbor, func(_ api.StreamContext, _ string, schemaFields map[string]*ast.JsonStreamField, _ map[string]any) (message.Converter, error) {
+		return cbor.NewConverter(schemaFields), nil
+	})
+	modules.RegisterConverter(message.FormatMsgpack, func(_ api.StreamContext, _ string, schemaFields map[string]*ast.JsonStreamField, _ map[string]any) (message.Converter, error) {
+		return msgpack.NewConverter(schemaFields), nil
+	})
+	modules.RegisterConverter(message.FormatXML, func(_ api.StreamContext, _ string, schemaFields map[string]*ast.JsonStreamField, props map[string]any) (message.Converter, error) {
+		return xml.NewConverter(schemaFields, props)
+	})
 	modules.RegisterWriterConverter(message.FormatDelimited, func(ctx api.StreamContext, _ string, _ map[string]*ast.JsonStreamField, props map[string]any) (message.ConvertWriter, error) {
 		return delimited.NewCsvWriter(ctx, props)
 	})
@@ -1,4 +1,4 @@
-// Copyright 2022-2024 EMQ Technologies Co., Ltd.
+// Copyright 2022-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -21,6 +21,7 @@ import (
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
+	"github.com/lf-edge/ekuiper/v2/internal/converter/avro"
 	"github.com/lf-edge/ekuiper/v2/internal/converter/protobuf"
 	"github.com/lf-edge/ekuiper/v2/internal/schema"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
@@ -45,4 +46,16 @@ func init() {
 		}
 		return protobuf.NewConverter(ffs.SchemaFile, ffs.SoFile, schemaName)
 	})
+	modules.RegisterConverter(message.FormatAvro, func(_ api.StreamContext, schemaId string, _ map[string]*ast.JsonStreamField, props map[string]any) (message.Converter, error) {
+		// the schema is optional if the writer schema is resolved from the schema registry
+		schemaFile := ""
+		if schemaId != "" {
+			ffs, err := schema.GetSchemaFile(modules.AVRO, strings.Split(schemaId, ".")[0])
+			if err != nil {
+				return nil, err
+			}
+			schemaFile = ffs.SchemaFile
+		}
+		return avro.NewConverter(schemaFile, props)
+	})
 }
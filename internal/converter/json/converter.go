@@ -1,4 +1,4 @@
-// Copyright 2022-2025 EMQ Technologies Co., Ltd.
+// Copyright 2022-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -38,6 +38,8 @@ type FastJsonConverter struct {
 	isSlice bool
 	buffer  bytes.Buffer
 	isNew   bool
+	// validator validates the decoded payload by the JSON schema if set
+	validator *validator
 }
 
 func (f *FastJsonConverter) New(_ api.StreamContext) error {
@@ -68,6 +70,8 @@ func (f *FastJsonConverter) Flush(_ api.StreamContext) ([]byte, error) {
 type FastJsonConverterConf struct {
 	UseInt64        bool              `json:"useInt64ForWholeNumber"`
 	ColAliasMapping map[string]string `json:"colAliasMapping"`
+	// SchemaValidationAction is the action when the payload does not match the JSON schema, error or drop
+	SchemaValidationAction string `json:"schemaValidationAction"`
 }
 
 func NewFastJsonConverter(schema map[string]*ast.JsonStreamField, props map[string]any) *FastJsonConverter {
@@ -86,6 +90,16 @@ func (f *FastJsonConverter) setupProps(props map[string]any) {
 	cast.MapToStruct(props, &f.FastJsonConverterConf)
 }
 
+// SetJsonSchema sets the JSON schema file to validate the payload when decoding
+func (f *FastJsonConverter) SetJsonSchema(schemaFile string) error {
+	v, err := newValidator(schemaFile, f.SchemaValidationAction)
+	if err != nil {
+		return err
+	}
+	f.validator = v
+	return nil
+}
+
 func (f *FastJsonConverter) ResetSchema(schema map[string]*ast.JsonStreamField) {
 	f.Lock()
 	defer f.Unlock()
@@ -126,7 +140,23 @@ func (f *FastJsonConverter) Decode(ctx api.StreamContext, b []byte) (m any, err
 	}()
 	f.RLock()
 	defer f.RUnlock()
-	return f.decodeWithSchema(b, f.schema)
+	if f.validator == nil {
+		return f.decodeWithSchema(b, f.schema)
+	}
+	var p fastjson.Parser
+	v, err := p.ParseBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	valid, err := f.validator.validate(v)
+	if err != nil {
+		return nil, err
+	}
+	m, err = f.decodeValue(v, f.schema)
+	if err != nil {
+		return nil, err
+	}
+	return f.validator.filter(m, valid), nil
 }
 
 func (f *FastJsonConverter) DecodeField(_ api.StreamContext, b []byte, field string) (any, error) {
@@ -163,6 +193,10 @@ func (f *FastJsonConverter) decodeWithSchema(b []byte, schema map[string]*ast.Js
 	if err != nil {
 		return nil, err
 	}
+	return f.decodeValue(v, schema)
+}
+
+func (f *FastJsonConverter) decodeValue(v *fastjson.Value, schema map[string]*ast.JsonStreamField) (any, error) {
 	if f.isSlice {
 		return f.decodeToSlice(v, schema)
 	}
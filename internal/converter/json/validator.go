@@ -0,0 +1,134 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/valyala/fastjson"
+
+	"github.com/lf-edge/ekuiper/v2/internal/schema"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
+)
+
+const (
+	// ValidationError fails the decoding if the payload does not match the JSON schema
+	ValidationError = "error"
+	// ValidationDrop drops the payload, or the items of an array payload, which do not match the JSON schema
+	ValidationDrop = "drop"
+)
+
+type validator struct {
+	schema *jsonschema.Schema
+	drop   bool
+}
+
+func newValidator(schemaFile string, action string) (*validator, error) {
+	v := &validator{}
+	switch action {
+	case "", ValidationError:
+	case ValidationDrop:
+		v.drop = true
+	default:
+		return nil, fmt.Errorf("invalid schemaValidationAction %s, must be %s or %s", action, ValidationError, ValidationDrop)
+	}
+	s, err := schema.CompileJsonSchema(schemaFile)
+	if err != nil {
+		return nil, err
+	}
+	v.schema = s
+	return v, nil
+}
+
+// validate checks the parsed payload against the schema. Each item of an array payload is validated separately like the
+// decoder which decodes each item as a message. It returns whether each item is valid. In error mode, the first
+// validation error is returned.
+func (v *validator) validate(doc *fastjson.Value) ([]bool, error) {
+	items := []*fastjson.Value{doc}
+	if doc.Type() == fastjson.TypeArray {
+		items, _ = doc.Array()
+	}
+	valid := make([]bool, len(items))
+	for i, item := range items {
+		if err := v.schema.Validate(toJsonValue(item)); err != nil {
+			if !v.drop {
+				return nil, fmt.Errorf("payload does not match the JSON schema: %v", err)
+			}
+			continue
+		}
+		valid[i] = true
+	}
+	return valid, nil
+}
+
+// toJsonValue converts the parsed value to the form of the validator. The numbers are kept as json.Number
+// to validate without losing precision.
+func toJsonValue(v *fastjson.Value) any {
+	switch v.Type() {
+	case fastjson.TypeObject:
+		obj, _ := v.Object()
+		m := make(map[string]any, obj.Len())
+		obj.Visit(func(key []byte, vv *fastjson.Value) {
+			m[string(key)] = toJsonValue(vv)
+		})
+		return m
+	case fastjson.TypeArray:
+		arr, _ := v.Array()
+		l := make([]any, len(arr))
+		for i, vv := range arr {
+			l[i] = toJsonValue(vv)
+		}
+		return l
+	case fastjson.TypeString:
+		return string(v.GetStringBytes())
+	case fastjson.TypeNumber:
+		return json.Number(v.String())
+	case fastjson.TypeTrue:
+		return true
+	case fastjson.TypeFalse:
+		return false
+	default:
+		return nil
+	}
+}
+
+// filter removes the decoded items which are invalid. A dropped object payload results in an empty list.
+func (v *validator) filter(result any, valid []bool) any {
+	switch r := result.(type) {
+	case map[string]any:
+		if !valid[0] {
+			return []map[string]any{}
+		}
+	case model.SliceVal:
+		if !valid[0] {
+			return []model.SliceVal{}
+		}
+	case []map[string]any:
+		return filterValid(r, valid)
+	}
+	return result
+}
+
+func filterValid[T any](items []T, valid []bool) []T {
+	result := items[:0]
+	for i, item := range items {
+		if valid[i] {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,118 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package json
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
+)
+
+func TestJsonSchemaValidation(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "op1")
+	tests := []struct {
+		name    string
+		action  string
+		payload string
+		result  any
+		err     string
+	}{
+		{
+			name:    "valid object",
+			payload: `{"id":1,"temperature":20.5}`,
+			result:  map[string]any{"id": float64(1), "temperature": 20.5},
+		},
+		{
+			name:    "large integer",
+			payload: `{"id":9007199254740993,"temperature":20.5}`,
+			result:  map[string]any{"id": float64(9007199254740993), "temperature": 20.5},
+		},
+		{
+			name:    "fraction is not integer",
+			payload: `{"id":1.5,"temperature":20.5}`,
+			err:     "payload does not match the JSON schema",
+		},
+		{
+			name:    "invalid object",
+			payload: `{"id":1,"temperature":200}`,
+			err:     "payload does not match the JSON schema",
+		},
+		{
+			name:    "invalid item",
+			payload: `[{"id":1,"temperature":20},{"id":"a","temperature":20}]`,
+			err:     "payload does not match the JSON schema",
+		},
+		{
+			name:    "drop object",
+			action:  ValidationDrop,
+			payload: `{"id":1}`,
+			result:  []map[string]any{},
+		},
+		{
+			name:    "drop item",
+			action:  ValidationDrop,
+			payload: `[{"id":1,"temperature":20},{"id":"a","temperature":20},{"id":3,"temperature":30,"name":"c"}]`,
+			result: []map[string]any{
+				{"id": float64(1), "temperature": float64(20)},
+				{"id": float64(3), "temperature": float64(30), "name": "c"},
+			},
+		},
+		{
+			name:    "invalid json",
+			payload: `{"id":1`,
+			err:     "cannot parse JSON",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewFastJsonConverter(nil, map[string]any{"schemaValidationAction": tt.action})
+			require.NoError(t, c.SetJsonSchema("testdata/schema.json"))
+			r, err := c.Decode(ctx, []byte(tt.payload))
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.result, r)
+		})
+	}
+}
+
+func TestJsonSchemaValidationSlice(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "op1")
+	schema := map[string]*ast.JsonStreamField{
+		"id":          {Type: "bigint", HasIndex: true, Index: 0},
+		"temperature": {Type: "float", HasIndex: true, Index: 1},
+	}
+	c := NewFastJsonConverter(schema, map[string]any{"schemaValidationAction": ValidationDrop})
+	require.NoError(t, c.SetJsonSchema("testdata/schema.json"))
+	r, err := c.Decode(ctx, []byte(`{"id":1,"temperature":20}`))
+	require.NoError(t, err)
+	require.Equal(t, model.SliceVal{int64(1), float64(20)}, r)
+	r, err = c.Decode(ctx, []byte(`{"id":2}`))
+	require.NoError(t, err)
+	require.Equal(t, []model.SliceVal{}, r)
+}
+
+func TestJsonSchemaErr(t *testing.T) {
+	c := NewFastJsonConverter(nil, map[string]any{"schemaValidationAction": "ignore"})
+	require.EqualError(t, c.SetJsonSchema("testdata/schema.json"), "invalid schemaValidationAction ignore, must be error or drop")
+	c = NewFastJsonConverter(nil, nil)
+	require.ErrorContains(t, c.SetJsonSchema("testdata/notexist.json"), "read schema file testdata/notexist.json failed")
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgpack
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/ugorji/go/codec"
+
+	"github.com/lf-edge/ekuiper/v2/internal/converter/typed"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
+)
+
+// the handle is safe for concurrent use once configured
+var mh = newHandle()
+
+func newHandle() *codec.MsgpackHandle {
+	h := &codec.MsgpackHandle{}
+	// write the str8 and bin types of the new spec, so that bytes and strings are distinguished
+	h.WriteExt = true
+	return h
+}
+
+type Converter struct {
+	sync.RWMutex
+	schema map[string]*ast.JsonStreamField
+}
+
+func NewConverter(schema map[string]*ast.JsonStreamField) message.Converter {
+	return &Converter{schema: schema}
+}
+
+func (c *Converter) Encode(_ api.StreamContext, d any) (b []byte, err error) {
+	defer func() {
+		if err != nil {
+			err = errorx.NewWithCode(errorx.CovnerterErr, err.Error())
+		}
+	}()
+	c.RLock()
+	d = typed.FromSlice(d, c.schema)
+	c.RUnlock()
+	err = codec.NewEncoderBytes(&b, mh).Encode(d)
+	return b, err
+}
+
+func (c *Converter) Decode(_ api.StreamContext, b []byte) (any, error) {
+	var v any
+	// the maps are decoded as map[any]any to allow the keys which are not string
+	if err := codec.NewDecoderBytes(b, mh).Decode(&v); err != nil {
+		return nil, fmt.Errorf("fail to decode msgpack payload: %v", err)
+	}
+	c.RLock()
+	defer c.RUnlock()
+	return typed.Decode(v, c.schema)
+}
+
+func (c *Converter) ResetSchema(schema map[string]*ast.JsonStreamField) {
+	c.Lock()
+	defer c.Unlock()
+	c.schema = schema
+}
+
+var _ message.SchemaResetAbleConverter = &Converter{}
@@ -0,0 +1,149 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgpack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/ugorji/go/codec"
+
+	"github.com/lf-edge/ekuiper/v2/internal/topo/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	// the timestamp is decoded in UTC
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tt := []struct {
+		name string
+		m    any
+		nm   any
+	}{
+		{
+			name: "normal",
+			m: map[string]any{
+				"id":          1,
+				"temperature": float32(20.5),
+				"name":        "dev1",
+				"ok":          true,
+				"raw":         []byte{1, 2},
+				"ts":          ts,
+			},
+			nm: map[string]any{
+				"id":          int64(1),
+				"temperature": 20.5,
+				"name":        "dev1",
+				"ok":          true,
+				"raw":         []byte{1, 2},
+				"ts":          ts,
+			},
+		},
+		{
+			name: "nested",
+			m: []map[string]any{
+				{"a": []any{-1, 2.5}, "b": map[string]any{"c": nil}},
+				{"a": []any{}},
+			},
+			nm: []map[string]any{
+				{"a": []any{int64(-1), 2.5}, "b": map[string]any{"c": nil}},
+				{"a": []any{}},
+			},
+		},
+	}
+	ctx := context.Background()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewConverter(nil)
+			b, err := c.Encode(ctx, tc.m)
+			require.NoError(t, err)
+			r, err := c.Decode(ctx, b)
+			require.NoError(t, err)
+			require.Equal(t, tc.nm, r)
+		})
+	}
+}
+
+func TestDecodeWithSchema(t *testing.T) {
+	schema := map[string]*ast.JsonStreamField{
+		"id":   {Type: "bigint"},
+		"temp": {Type: "float"},
+		"name": {Type: "string"},
+		"raw":  {Type: "bytea"},
+		"ok":   {Type: "boolean"},
+		"loc": {Type: "struct", Properties: map[string]*ast.JsonStreamField{
+			"lat": {Type: "float"},
+		}},
+		"tags": {Type: "array", Items: &ast.JsonStreamField{Type: "string"}},
+	}
+	ctx := context.Background()
+	c := NewConverter(schema)
+	// the keys which are not string are converted to string
+	b, err := marshal(map[any]any{
+		1: "x", "id": uint8(1), "temp": 20, "name": []byte("ab"), "raw": []byte{1}, "ok": 1,
+		"loc": map[string]any{"lat": 1, "lng": 2}, "tags": []any{"a", 1}, "other": 1,
+	})
+	require.NoError(t, err)
+	r, err := c.Decode(ctx, b)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"id": int64(1), "temp": float64(20), "name": "ab", "raw": []byte{1}, "ok": true,
+		"loc": map[string]any{"lat": float64(1)}, "tags": []any{"a", "1"},
+	}, r)
+
+	b, err = marshal(map[string]any{"id": "abc"})
+	require.NoError(t, err)
+	_, err = c.Decode(ctx, b)
+	require.EqualError(t, err, "id has wrong type:string, expect:bigint")
+
+	// slice mode
+	c.(*Converter).ResetSchema(map[string]*ast.JsonStreamField{
+		"id":   {Type: "bigint", HasIndex: true, Index: 1},
+		"name": {Type: "string", HasIndex: true, Index: 0},
+	})
+	b, err = marshal(map[string]any{"id": 1, "name": "a", "other": 1})
+	require.NoError(t, err)
+	r, err = c.Decode(ctx, b)
+	require.NoError(t, err)
+	require.Equal(t, model.SliceVal{"a", int64(1)}, r)
+	b, err = c.Encode(ctx, r)
+	require.NoError(t, err)
+	r, err = NewConverter(nil).Decode(ctx, b)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"id": int64(1), "name": "a"}, r)
+}
+
+func TestDecodeErr(t *testing.T) {
+	ctx := context.Background()
+	c := NewConverter(nil)
+	_, err := c.Decode(ctx, []byte{0xc1})
+	require.Error(t, err)
+	b, err := marshal([]any{1})
+	require.NoError(t, err)
+	_, err = c.Decode(ctx, b)
+	require.EqualError(t, err, "only map[string]any inside a list is supported but got: 1")
+	b, err = marshal("a")
+	require.NoError(t, err)
+	_, err = c.Decode(ctx, b)
+	require.EqualError(t, err, "only map[string]any and []map[string]any is supported but got: a")
+}
+
+func marshal(v any) ([]byte, error) {
+	var b []byte
+	err := codec.NewEncoderBytes(&b, mh).Encode(v)
+	return b, err
+}
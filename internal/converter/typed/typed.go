@@ -0,0 +1,253 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package typed
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
+)
+
+// Normalize converts the values decoded by the self-describing binary formats such as CBOR and MessagePack to the
+// eKuiper types. The integers are converted to int64, the floats to float64 and the maps to map[string]any.
+func Normalize(v any) (any, error) {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, mv := range t {
+			nv, err := Normalize(mv)
+			if err != nil {
+				return nil, err
+			}
+			t[k] = nv
+		}
+		return t, nil
+	case map[any]any:
+		m := make(map[string]any, len(t))
+		for k, mv := range t {
+			key, err := cast.ToString(k, cast.CONVERT_ALL)
+			if err != nil {
+				return nil, fmt.Errorf("unsupported map key %[1]T(%[1]v)", k)
+			}
+			nv, err := Normalize(mv)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = nv
+		}
+		return m, nil
+	case []any:
+		for i, av := range t {
+			nv, err := Normalize(av)
+			if err != nil {
+				return nil, err
+			}
+			t[i] = nv
+		}
+		return t, nil
+	case int:
+		return int64(t), nil
+	case int8:
+		return int64(t), nil
+	case int16:
+		return int64(t), nil
+	case int32:
+		return int64(t), nil
+	case uint:
+		return uintToInt64(uint64(t))
+	case uint8:
+		return int64(t), nil
+	case uint16:
+		return int64(t), nil
+	case uint32:
+		return int64(t), nil
+	case uint64:
+		return uintToInt64(t)
+	case float32:
+		return float64(t), nil
+	default:
+		return v, nil
+	}
+}
+
+func uintToInt64(u uint64) (any, error) {
+	if u > math.MaxInt64 {
+		return nil, fmt.Errorf("integer %d overflows bigint", u)
+	}
+	return int64(u), nil
+}
+
+// Decode normalizes the decoded payload and applies the schema to each map. The payload must be a map or a list of
+// maps. In slice mode which is indicated by the schema index, only the map payload is supported.
+func Decode(v any, schema map[string]*ast.JsonStreamField) (any, error) {
+	nv, err := Normalize(v)
+	if err != nil {
+		return nil, err
+	}
+	isSlice := ast.CheckSchemaIndex(schema)
+	switch t := nv.(type) {
+	case map[string]any:
+		m, err := ApplySchema(t, schema)
+		if err != nil {
+			return nil, err
+		}
+		if isSlice {
+			return ToSlice(m, schema), nil
+		}
+		return m, nil
+	case []any:
+		if isSlice {
+			return nil, fmt.Errorf("do not support array yet in slice mode")
+		}
+		result := make([]map[string]any, 0, len(t))
+		for _, item := range t {
+			m, ok := item.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("only map[string]any inside a list is supported but got: %v", item)
+			}
+			m, err = ApplySchema(m, schema)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, m)
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("only map[string]any and []map[string]any is supported but got: %v", nv)
+	}
+}
+
+// ApplySchema keeps the fields defined in the schema and converts them to the defined types like the JSON converter.
+// The fields are kept unchanged if the schema is nil which means schemaless.
+func ApplySchema(m map[string]any, schema map[string]*ast.JsonStreamField) (map[string]any, error) {
+	return applySchema(m, schema, cast.CONVERT_SAMEKIND)
+}
+
+// ApplyTextSchema is like ApplySchema, but the numbers are also parsed from the strings. It is used by the text
+// formats like XML whose values are all strings.
+func ApplyTextSchema(m map[string]any, schema map[string]*ast.JsonStreamField) (map[string]any, error) {
+	return applySchema(m, schema, cast.CONVERT_ALL)
+}
+
+func applySchema(m map[string]any, schema map[string]*ast.JsonStreamField, sn cast.Strictness) (map[string]any, error) {
+	if schema == nil {
+		return m, nil
+	}
+	result := make(map[string]any, len(schema))
+	for k, v := range m {
+		field, ok := schema[k]
+		if !ok {
+			continue
+		}
+		cv, err := convertField(k, v, field, sn)
+		if err != nil {
+			return nil, err
+		}
+		result[k] = cv
+	}
+	return result, nil
+}
+
+func convertField(name string, v any, field *ast.JsonStreamField, sn cast.Strictness) (r any, err error) {
+	if v == nil || field == nil || field.Type == "" {
+		return v, nil
+	}
+	switch field.Type {
+	case "bigint":
+		r, err = cast.ToInt64(v, sn)
+	case "float":
+		r, err = cast.ToFloat64(v, sn)
+	case "string":
+		r, err = cast.ToString(v, cast.CONVERT_ALL)
+	case "bytea":
+		r, err = cast.ToByteA(v, cast.CONVERT_ALL)
+	case "boolean":
+		r, err = cast.ToBool(v, cast.CONVERT_ALL)
+	case "datetime":
+		switch v.(type) {
+		case time.Time, int64, float64, string:
+			r = v
+		default:
+			err = fmt.Errorf("unsupported datetime")
+		}
+	case "struct":
+		m, ok := v.(map[string]any)
+		if !ok {
+			err = fmt.Errorf("not a struct")
+			break
+		}
+		return applySchema(m, field.Properties, sn)
+	case "array":
+		a, ok := v.([]any)
+		if !ok {
+			err = fmt.Errorf("not an array")
+			break
+		}
+		items := make([]any, len(a))
+		for i, item := range a {
+			items[i], err = convertField(name, item, field.Items, sn)
+			if err != nil {
+				return nil, err
+			}
+		}
+		r = items
+	default:
+		r = v
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s has wrong type:%T, expect:%s", name, v, field.Type)
+	}
+	return r, nil
+}
+
+// ToSlice puts the fields to the index defined by the schema for the slice mode
+func ToSlice(m map[string]any, schema map[string]*ast.JsonStreamField) model.SliceVal {
+	result := make(model.SliceVal, len(schema))
+	for k, v := range m {
+		if field, ok := schema[k]; ok && field != nil && field.HasIndex {
+			result[field.Index] = v
+		}
+	}
+	return result
+}
+
+// FromSlice converts the slice values back to maps by the schema index so that they can be encoded
+func FromSlice(d any, schema map[string]*ast.JsonStreamField) any {
+	switch dt := d.(type) {
+	case model.SliceVal:
+		return sliceToMap(dt, schema)
+	case []model.SliceVal:
+		ms := make([]map[string]any, len(dt))
+		for i, dtt := range dt {
+			ms[i] = sliceToMap(dtt, schema)
+		}
+		return ms
+	default:
+		return d
+	}
+}
+
+func sliceToMap(s model.SliceVal, schema map[string]*ast.JsonStreamField) map[string]any {
+	m := make(map[string]any, len(schema))
+	for k, v := range schema {
+		if v != nil && v.HasIndex && v.Index < len(s) && s[v.Index] != nil {
+			m[k] = s[v.Index]
+		}
+	}
+	return m
+}
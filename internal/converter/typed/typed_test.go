@@ -0,0 +1,267 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package typed
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
+)
+
+func TestNormalize(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name string
+		v    any
+		r    any
+		err  string
+	}{
+		{name: "int", v: 1, r: int64(1)},
+		{name: "int8", v: int8(-8), r: int64(-8)},
+		{name: "int16", v: int16(-16), r: int64(-16)},
+		{name: "int32", v: int32(-32), r: int64(-32)},
+		{name: "int64", v: int64(-64), r: int64(-64)},
+		{name: "uint", v: uint(1), r: int64(1)},
+		{name: "uint8", v: uint8(8), r: int64(8)},
+		{name: "uint16", v: uint16(16), r: int64(16)},
+		{name: "uint32", v: uint32(32), r: int64(32)},
+		{name: "uint64", v: uint64(64), r: int64(64)},
+		{name: "uint64 max int", v: uint64(math.MaxInt64), r: int64(math.MaxInt64)},
+		{name: "uint64 overflow", v: uint64(math.MaxInt64) + 1, err: "integer 9223372036854775808 overflows bigint"},
+		{name: "uint overflow", v: uint(math.MaxUint64), err: "integer 18446744073709551615 overflows bigint"},
+		{name: "float32", v: float32(1.5), r: 1.5},
+		{name: "float64", v: 2.5, r: 2.5},
+		{name: "string", v: "a", r: "a"},
+		{name: "bytes", v: []byte{1}, r: []byte{1}},
+		{name: "bool", v: true, r: true},
+		{name: "time", v: ts, r: ts},
+		{name: "nil", v: nil, r: nil},
+		{
+			name: "string map",
+			v:    map[string]any{"a": uint8(1), "b": map[string]any{"c": float32(0.5)}},
+			r:    map[string]any{"a": int64(1), "b": map[string]any{"c": 0.5}},
+		},
+		{
+			name: "any map",
+			v:    map[any]any{1: "x", "a": int16(2), true: []any{uint32(3)}},
+			r:    map[string]any{"1": "x", "a": int64(2), "true": []any{int64(3)}},
+		},
+		{
+			name: "struct key",
+			v:    map[any]any{struct{}{}: 1},
+			r:    map[string]any{"{}": int64(1)},
+		},
+		{
+			name: "nested error",
+			v:    []any{map[string]any{"a": uint64(math.MaxUint64)}},
+			err:  "integer 18446744073709551615 overflows bigint",
+		},
+		{
+			name: "list",
+			v:    []any{int8(1), map[any]any{"a": float32(1)}, []any{uint16(2)}},
+			r:    []any{int64(1), map[string]any{"a": float64(1)}, []any{int64(2)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Normalize(tt.v)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.r, r)
+		})
+	}
+}
+
+func TestApplySchema(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	schema := map[string]*ast.JsonStreamField{
+		"i":  {Type: "bigint"},
+		"f":  {Type: "float"},
+		"s":  {Type: "string"},
+		"b":  {Type: "bytea"},
+		"ok": {Type: "boolean"},
+		"dt": {Type: "datetime"},
+		"st": {Type: "struct", Properties: map[string]*ast.JsonStreamField{
+			"x": {Type: "float"},
+		}},
+		"a":   {Type: "array", Items: &ast.JsonStreamField{Type: "bigint"}},
+		"any": {},
+		"nil": nil,
+	}
+	tests := []struct {
+		name string
+		m    map[string]any
+		text bool
+		r    map[string]any
+		err  string
+	}{
+		{
+			name: "same kind",
+			m: map[string]any{
+				"i": 1.0, "f": int64(2), "s": 3, "b": "YQ==", "ok": 1, "dt": ts,
+				"st": map[string]any{"x": int64(1), "y": 2}, "a": []any{1.0, int64(2)},
+				"any": "v", "nil": 1, "undefined": 1,
+			},
+			r: map[string]any{
+				"i": int64(1), "f": 2.0, "s": "3", "b": []byte("a"), "ok": true, "dt": ts,
+				"st": map[string]any{"x": 1.0}, "a": []any{int64(1), int64(2)},
+				"any": "v", "nil": 1,
+			},
+		},
+		{
+			name: "nil values",
+			m:    map[string]any{"i": nil, "st": nil, "a": []any{nil}},
+			r:    map[string]any{"i": nil, "st": nil, "a": []any{nil}},
+		},
+		{
+			name: "datetime types",
+			m:    map[string]any{"dt": int64(1)},
+			r:    map[string]any{"dt": int64(1)},
+		},
+		{
+			name: "datetime string",
+			m:    map[string]any{"dt": "2026-01-02"},
+			r:    map[string]any{"dt": "2026-01-02"},
+		},
+		{name: "string to bigint", m: map[string]any{"i": "1"}, err: "i has wrong type:string, expect:bigint"},
+		{name: "string to float", m: map[string]any{"f": "1.5"}, err: "f has wrong type:string, expect:float"},
+		{name: "invalid boolean", m: map[string]any{"ok": "x"}, err: "ok has wrong type:string, expect:boolean"},
+		{name: "invalid datetime", m: map[string]any{"dt": true}, err: "dt has wrong type:bool, expect:datetime"},
+		{name: "invalid struct", m: map[string]any{"st": 1}, err: "st has wrong type:int, expect:struct"},
+		{name: "invalid array", m: map[string]any{"a": 1}, err: "a has wrong type:int, expect:array"},
+		{name: "invalid array item", m: map[string]any{"a": []any{"x"}}, err: "a has wrong type:string, expect:bigint"},
+		{name: "invalid struct field", m: map[string]any{"st": map[string]any{"x": "y"}}, err: "x has wrong type:string, expect:float"},
+		{
+			name: "text",
+			text: true,
+			m:    map[string]any{"i": "1", "f": "1.5", "ok": "true", "st": map[string]any{"x": "2"}, "a": []any{"3"}},
+			r:    map[string]any{"i": int64(1), "f": 1.5, "ok": true, "st": map[string]any{"x": 2.0}, "a": []any{int64(3)}},
+		},
+		{name: "invalid text", text: true, m: map[string]any{"i": "x"}, err: "i has wrong type:string, expect:bigint"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				r   map[string]any
+				err error
+			)
+			if tt.text {
+				r, err = ApplyTextSchema(tt.m, schema)
+			} else {
+				r, err = ApplySchema(tt.m, schema)
+			}
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.r, r)
+		})
+	}
+	// schemaless
+	m := map[string]any{"a": 1}
+	r, err := ApplySchema(m, nil)
+	require.NoError(t, err)
+	require.Equal(t, m, r)
+}
+
+func TestDecode(t *testing.T) {
+	schema := map[string]*ast.JsonStreamField{
+		"id": {Type: "bigint"},
+	}
+	sliceSchema := map[string]*ast.JsonStreamField{
+		"id":   {Type: "bigint", HasIndex: true, Index: 1},
+		"name": {Type: "string", HasIndex: true, Index: 0},
+	}
+	tests := []struct {
+		name   string
+		v      any
+		schema map[string]*ast.JsonStreamField
+		r      any
+		err    string
+	}{
+		{name: "map", v: map[any]any{"id": uint8(1), "x": 1}, schema: schema, r: map[string]any{"id": int64(1)}},
+		{name: "schemaless", v: map[any]any{"id": uint8(1), "x": 1}, r: map[string]any{"id": int64(1), "x": int64(1)}},
+		{
+			name:   "list",
+			v:      []any{map[any]any{"id": 1.0}, map[string]any{"id": int64(2)}},
+			schema: schema,
+			r:      []map[string]any{{"id": int64(1)}, {"id": int64(2)}},
+		},
+		{name: "slice", v: map[string]any{"id": 1, "name": "a", "x": 1}, schema: sliceSchema, r: model.SliceVal{"a", int64(1)}},
+		{name: "slice list", v: []any{map[string]any{"id": 1}}, schema: sliceSchema, err: "do not support array yet in slice mode"},
+		{name: "list item", v: []any{1}, err: "only map[string]any inside a list is supported but got: 1"},
+		{name: "scalar", v: "a", err: "only map[string]any and []map[string]any is supported but got: a"},
+		{name: "wrong type", v: map[string]any{"id": "a"}, schema: schema, err: "id has wrong type:string, expect:bigint"},
+		{name: "wrong type in list", v: []any{map[string]any{"id": "a"}}, schema: schema, err: "id has wrong type:string, expect:bigint"},
+		{name: "normalize error", v: map[string]any{"id": uint64(math.MaxUint64)}, err: "integer 18446744073709551615 overflows bigint"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Decode(tt.v, tt.schema)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.r, r)
+		})
+	}
+}
+
+func TestFromSlice(t *testing.T) {
+	schema := map[string]*ast.JsonStreamField{
+		"id":    {Type: "bigint", HasIndex: true, Index: 1},
+		"name":  {Type: "string", HasIndex: true, Index: 0},
+		"out":   {Type: "string", HasIndex: true, Index: 5},
+		"noIdx": {Type: "string"},
+		"nil":   nil,
+	}
+	tests := []struct {
+		name string
+		d    any
+		r    any
+	}{
+		{name: "slice", d: model.SliceVal{"a", int64(1)}, r: map[string]any{"name": "a", "id": int64(1)}},
+		{name: "nil value", d: model.SliceVal{nil, int64(1)}, r: map[string]any{"id": int64(1)}},
+		{
+			name: "slices",
+			d:    []model.SliceVal{{"a", int64(1)}, {"b"}},
+			r:    []map[string]any{{"name": "a", "id": int64(1)}, {"name": "b"}},
+		},
+		{name: "map", d: map[string]any{"a": 1}, r: map[string]any{"a": 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.r, FromSlice(tt.d, schema))
+		})
+	}
+	require.Equal(t, model.SliceVal{"a", int64(1), nil, nil, nil, nil}, ToSlice(map[string]any{"id": int64(1), "name": "a", "noIdx": "x", "nil": 1}, map[string]*ast.JsonStreamField{
+		"id":    {Type: "bigint", HasIndex: true, Index: 1},
+		"name":  {Type: "string", HasIndex: true, Index: 0},
+		"noIdx": {Type: "string"},
+		"nil":   nil,
+		"a":     {},
+		"b":     {},
+	}))
+}
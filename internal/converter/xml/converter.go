@@ -0,0 +1,333 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xml
+
+import (
+	"bytes"
+	stdxml "encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/antchfx/xpath"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/converter/typed"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
+)
+
+type Conf struct {
+	// XmlRoot is the XPath to select the elements to decode. Each element is decoded as a tuple.
+	// Default to the document element.
+	XmlRoot string `json:"xmlRoot"`
+	// XmlArrayElements are the names of the elements which are always decoded as an array. The other elements are
+	// decoded as an array only if they are repeated.
+	XmlArrayElements []string `json:"xmlArrayElements"`
+	// XmlAttrPrefix is the prefix of the field names mapped from the attributes
+	XmlAttrPrefix string `json:"xmlAttrPrefix"`
+	// XmlTextKey is the field name of the text content for the elements which have attributes or children
+	XmlTextKey string `json:"xmlTextKey"`
+	// XmlRootElement is the name of the root element to encode
+	XmlRootElement string `json:"xmlRootElement"`
+	// XmlItemElement is the name of the element of each item to encode a list
+	XmlItemElement string `json:"xmlItemElement"`
+}
+
+type Converter struct {
+	sync.RWMutex
+	*Conf
+	root   *xpath.Expr
+	arrays map[string]struct{}
+	schema map[string]*ast.JsonStreamField
+}
+
+func NewConverter(schema map[string]*ast.JsonStreamField, props map[string]any) (*Converter, error) {
+	c := &Conf{
+		XmlAttrPrefix:  "@",
+		XmlTextKey:     "#text",
+		XmlRootElement: "root",
+		XmlItemElement: "item",
+	}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return nil, fmt.Errorf("invalid xml converter props: %v", err)
+	}
+	if c.XmlTextKey == "" {
+		return nil, fmt.Errorf("xmlTextKey must not be empty")
+	}
+	if c.XmlRootElement == "" || c.XmlItemElement == "" {
+		return nil, fmt.Errorf("xmlRootElement and xmlItemElement must not be empty")
+	}
+	for _, name := range []string{c.XmlRootElement, c.XmlItemElement} {
+		if !isName(name) {
+			return nil, fmt.Errorf("invalid xml element name %s", name)
+		}
+	}
+	r := &Converter{Conf: c, schema: schema, arrays: make(map[string]struct{}, len(c.XmlArrayElements))}
+	if c.XmlRoot != "" {
+		expr, err := xpath.Compile(c.XmlRoot)
+		if err != nil {
+			return nil, fmt.Errorf("invalid xmlRoot %s: %v", c.XmlRoot, err)
+		}
+		r.root = expr
+	}
+	for _, name := range c.XmlArrayElements {
+		r.arrays[name] = struct{}{}
+	}
+	return r, nil
+}
+
+// Decode decodes the selected elements to maps. It returns a map if only one element is selected.
+func (c *Converter) Decode(_ api.StreamContext, b []byte) (any, error) {
+	doc, err := xmlquery.Parse(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("fail to decode xml payload: %v", err)
+	}
+	var nodes []*xmlquery.Node
+	if c.root != nil {
+		nodes = xmlquery.QuerySelectorAll(doc, c.root)
+	} else {
+		for n := doc.FirstChild; n != nil; n = n.NextSibling {
+			if n.Type == xmlquery.ElementNode {
+				nodes = append(nodes, n)
+				break
+			}
+		}
+	}
+	c.RLock()
+	defer c.RUnlock()
+	result := make([]map[string]any, 0, len(nodes))
+	for _, n := range nodes {
+		if n.Type != xmlquery.ElementNode {
+			return nil, fmt.Errorf("xmlRoot %s must select elements", c.XmlRoot)
+		}
+		m, err := typed.ApplyTextSchema(c.decodeElement(n), c.schema)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, m)
+	}
+	isSlice := ast.CheckSchemaIndex(c.schema)
+	switch {
+	case len(result) == 1 && isSlice:
+		return typed.ToSlice(result[0], c.schema), nil
+	case len(result) == 1:
+		return result[0], nil
+	case isSlice:
+		return nil, fmt.Errorf("do not support array yet in slice mode")
+	default:
+		return result, nil
+	}
+}
+
+// decodeElement maps the attributes and the child elements of the element to fields
+func (c *Converter) decodeElement(n *xmlquery.Node) map[string]any {
+	m := make(map[string]any, len(n.Attr))
+	for _, attr := range n.Attr {
+		// skip the namespace declarations
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		m[c.XmlAttrPrefix+attr.Name.Local] = attr.Value
+	}
+	var text strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		switch child.Type {
+		case xmlquery.ElementNode:
+			v := c.decodeValue(child)
+			name := child.Data
+			if existed, ok := m[name]; ok {
+				if list, isList := existed.([]any); isList {
+					m[name] = append(list, v)
+				} else {
+					m[name] = []any{existed, v}
+				}
+			} else if _, isArray := c.arrays[name]; isArray {
+				m[name] = []any{v}
+			} else {
+				m[name] = v
+			}
+		case xmlquery.TextNode, xmlquery.CharDataNode:
+			text.WriteString(child.Data)
+		}
+	}
+	if t := strings.TrimSpace(text.String()); t != "" {
+		m[c.XmlTextKey] = t
+	}
+	return m
+}
+
+// decodeValue decodes the element without attributes and child elements as its text
+func (c *Converter) decodeValue(n *xmlquery.Node) any {
+	if len(n.Attr) == 0 {
+		simple := true
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == xmlquery.ElementNode {
+				simple = false
+				break
+			}
+		}
+		if simple {
+			return strings.TrimSpace(n.InnerText())
+		}
+	}
+	return c.decodeElement(n)
+}
+
+func (c *Converter) Encode(_ api.StreamContext, d any) (b []byte, err error) {
+	defer func() {
+		if err != nil {
+			err = errorx.NewWithCode(errorx.CovnerterErr, err.Error())
+		}
+	}()
+	c.RLock()
+	d = typed.FromSlice(d, c.schema)
+	c.RUnlock()
+	buf := &bytes.Buffer{}
+	switch dt := d.(type) {
+	case map[string]any:
+		if err := c.encodeElement(buf, c.XmlRootElement, dt); err != nil {
+			return nil, err
+		}
+	case []map[string]any:
+		buf.WriteString("<" + c.XmlRootElement + ">")
+		for _, m := range dt {
+			if err := c.encodeElement(buf, c.XmlItemElement, m); err != nil {
+				return nil, err
+			}
+		}
+		buf.WriteString("</" + c.XmlRootElement + ">")
+	default:
+		return nil, fmt.Errorf("unsupported type %v, must be a map or a list of maps", d)
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeElement writes the value as the element. The field names which are not valid xml names are rejected
+// instead of producing a malformed document.
+func (c *Converter) encodeElement(buf *bytes.Buffer, name string, v any) error {
+	switch vt := v.(type) {
+	case []any:
+		for _, item := range vt {
+			if err := c.encodeElement(buf, name, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []map[string]any:
+		for _, item := range vt {
+			if err := c.encodeElement(buf, name, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if !isName(name) {
+		return fmt.Errorf("field name %s is not a valid xml element name", name)
+	}
+	buf.WriteString("<" + name)
+	m, isMap := v.(map[string]any)
+	if !isMap {
+		buf.WriteString(">")
+		if v != nil {
+			s, _ := cast.ToString(v, cast.CONVERT_ALL)
+			escape(buf, s)
+		}
+		buf.WriteString("</" + name + ">")
+		return nil
+	}
+	keys := sortedKeys(m)
+	// write the attributes first
+	if c.XmlAttrPrefix != "" {
+		for _, k := range keys {
+			if strings.HasPrefix(k, c.XmlAttrPrefix) && m[k] != nil {
+				attr := strings.TrimPrefix(k, c.XmlAttrPrefix)
+				if !isName(attr) {
+					return fmt.Errorf("field name %s is not a valid xml attribute name", k)
+				}
+				s, _ := cast.ToString(m[k], cast.CONVERT_ALL)
+				buf.WriteString(" " + attr + `="`)
+				escape(buf, s)
+				buf.WriteString(`"`)
+			}
+		}
+	}
+	buf.WriteString(">")
+	for _, k := range keys {
+		switch {
+		case c.XmlAttrPrefix != "" && strings.HasPrefix(k, c.XmlAttrPrefix):
+		case k == c.XmlTextKey:
+			s, _ := cast.ToString(m[k], cast.CONVERT_ALL)
+			escape(buf, s)
+		default:
+			if err := c.encodeElement(buf, k, m[k]); err != nil {
+				return err
+			}
+		}
+	}
+	buf.WriteString("</" + name + ">")
+	return nil
+}
+
+func (c *Converter) ResetSchema(schema map[string]*ast.JsonStreamField) {
+	c.Lock()
+	defer c.Unlock()
+	c.schema = schema
+}
+
+// isName checks if the string is a valid xml name by the NameStartChar and NameChar productions of the spec
+func isName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if !isNameStartChar(r) && (i == 0 || !isNameChar(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+func isNameStartChar(r rune) bool {
+	return r == ':' || r == '_' || r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' ||
+		r >= 0xC0 && r <= 0xD6 || r >= 0xD8 && r <= 0xF6 || r >= 0xF8 && r <= 0x2FF ||
+		r >= 0x370 && r <= 0x37D || r >= 0x37F && r <= 0x1FFF || r >= 0x200C && r <= 0x200D ||
+		r >= 0x2070 && r <= 0x218F || r >= 0x2C00 && r <= 0x2FEF || r >= 0x3001 && r <= 0xD7FF ||
+		r >= 0xF900 && r <= 0xFDCF || r >= 0xFDF0 && r <= 0xFFFD || r >= 0x10000 && r <= 0xEFFFF
+}
+
+func isNameChar(r rune) bool {
+	return r == '-' || r == '.' || r >= '0' && r <= '9' || r == 0xB7 ||
+		r >= 0x300 && r <= 0x36F || r >= 0x203F && r <= 0x2040
+}
+
+func escape(buf *bytes.Buffer, s string) {
+	_ = stdxml.EscapeText(buf, []byte(s))
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var _ message.SchemaResetAbleConverter = &Converter{}
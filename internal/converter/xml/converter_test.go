@@ -0,0 +1,231 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/topo/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
+)
+
+const payload = `<?xml version="1.0" encoding="UTF-8"?>
+<response xmlns:s="urn:scada">
+  <status>ok</status>
+  <tags>
+    <tag name="t1" quality="good">20.5</tag>
+    <tag name="t2" quality="bad"><![CDATA[21]]></tag>
+  </tags>
+  <device id="d1">
+    <alarm>high</alarm>
+    <s:zone>A</s:zone>
+  </device>
+</response>`
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name   string
+		props  map[string]any
+		schema map[string]*ast.JsonStreamField
+		result any
+	}{
+		{
+			name: "document",
+			result: map[string]any{
+				"status": "ok",
+				"tags": map[string]any{
+					"tag": []any{
+						map[string]any{"@name": "t1", "@quality": "good", "#text": "20.5"},
+						map[string]any{"@name": "t2", "@quality": "bad", "#text": "21"},
+					},
+				},
+				"device": map[string]any{"@id": "d1", "alarm": "high", "zone": "A"},
+			},
+		},
+		{
+			name: "root selector",
+			props: map[string]any{
+				"xmlRoot":       "/response/tags/tag",
+				"xmlAttrPrefix": "",
+				"xmlTextKey":    "value",
+			},
+			result: []map[string]any{
+				{"name": "t1", "quality": "good", "value": "20.5"},
+				{"name": "t2", "quality": "bad", "value": "21"},
+			},
+		},
+		{
+			name: "root selector with predicate and schema",
+			props: map[string]any{
+				"xmlRoot":       "//tag[@quality='good']",
+				"xmlAttrPrefix": "",
+				"xmlTextKey":    "value",
+			},
+			schema: map[string]*ast.JsonStreamField{
+				"name":  {Type: "string"},
+				"value": {Type: "float"},
+			},
+			result: map[string]any{"name": "t1", "value": 20.5},
+		},
+		{
+			name: "array elements",
+			props: map[string]any{
+				"xmlRoot":          "/response/device",
+				"xmlArrayElements": []string{"alarm"},
+			},
+			result: map[string]any{"@id": "d1", "alarm": []any{"high"}, "zone": "A"},
+		},
+		{
+			name: "no match",
+			props: map[string]any{
+				"xmlRoot": "/response/none",
+			},
+			result: []map[string]any{},
+		},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewConverter(tt.schema, tt.props)
+			require.NoError(t, err)
+			r, err := c.Decode(ctx, []byte(payload))
+			require.NoError(t, err)
+			require.Equal(t, tt.result, r)
+		})
+	}
+}
+
+func TestDecodeSchema(t *testing.T) {
+	ctx := context.Background()
+	schema := map[string]*ast.JsonStreamField{
+		"id":     {Type: "bigint"},
+		"ok":     {Type: "boolean"},
+		"values": {Type: "array", Items: &ast.JsonStreamField{Type: "float"}},
+		"loc":    {Type: "struct", Properties: map[string]*ast.JsonStreamField{"lat": {Type: "float"}}},
+	}
+	c, err := NewConverter(schema, map[string]any{"xmlArrayElements": []string{"values"}})
+	require.NoError(t, err)
+	r, err := c.Decode(ctx, []byte(`<data><id>12</id><ok>true</ok><values>1.5</values><loc><lat>3</lat><lng>4</lng></loc><other>x</other></data>`))
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"id":     int64(12),
+		"ok":     true,
+		"values": []any{1.5},
+		"loc":    map[string]any{"lat": float64(3)},
+	}, r)
+
+	_, err = c.Decode(ctx, []byte(`<data><id>abc</id></data>`))
+	require.EqualError(t, err, "id has wrong type:string, expect:bigint")
+
+	c.ResetSchema(map[string]*ast.JsonStreamField{
+		"id":   {Type: "bigint", HasIndex: true, Index: 1},
+		"name": {Type: "string", HasIndex: true, Index: 0},
+	})
+	r, err = c.Decode(ctx, []byte(`<data><id>12</id><name>a</name></data>`))
+	require.NoError(t, err)
+	require.Equal(t, model.SliceVal{"a", int64(12)}, r)
+	b, err := c.Encode(ctx, r)
+	require.NoError(t, err)
+	require.Equal(t, `<root><id>12</id><name>a</name></root>`, string(b))
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name   string
+		props  map[string]any
+		data   any
+		result string
+		err    string
+	}{
+		{
+			name: "map",
+			data: map[string]any{
+				"id":     1,
+				"name":   "a<b",
+				"values": []any{1.5, 2},
+				"tag":    map[string]any{"@quality": "good", "#text": 20.5},
+				"empty":  nil,
+			},
+			result: `<root><empty></empty><id>1</id><name>a&lt;b</name><tag quality="good">20.5</tag><values>1.5</values><values>2</values></root>`,
+		},
+		{
+			name:  "list",
+			props: map[string]any{"xmlRootElement": "tags", "xmlItemElement": "tag", "xmlAttrPrefix": ""},
+			data: []map[string]any{
+				{"name": "t1"},
+				{"name": "t2"},
+			},
+			result: `<tags><tag><name>t1</name></tag><tag><name>t2</name></tag></tags>`,
+		},
+		{
+			name: "unsupported",
+			data: []any{1},
+			err:  "unsupported type [1], must be a map or a list of maps",
+		},
+		{
+			name:   "unicode name",
+			data:   map[string]any{"温度": 20, "a-b.c": 1},
+			result: `<root><a-b.c>1</a-b.c><温度>20</温度></root>`,
+		},
+		{
+			name: "invalid element name",
+			data: map[string]any{"a b": 1},
+			err:  "field name a b is not a valid xml element name",
+		},
+		{
+			name: "invalid element name start",
+			data: map[string]any{"tag": map[string]any{"1st": 1}},
+			err:  "field name 1st is not a valid xml element name",
+		},
+		{
+			name: "invalid element name in list",
+			data: []map[string]any{{"a<b": 1}},
+			err:  "field name a<b is not a valid xml element name",
+		},
+		{
+			name: "invalid attribute name",
+			data: map[string]any{"@a=b": 1},
+			err:  "field name @a=b is not a valid xml attribute name",
+		},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewConverter(nil, tt.props)
+			require.NoError(t, err)
+			b, err := c.Encode(ctx, tt.data)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.result, string(b))
+		})
+	}
+}
+
+func TestNewConverterErr(t *testing.T) {
+	_, err := NewConverter(nil, map[string]any{"xmlRoot": "/a["})
+	require.Error(t, err)
+	_, err = NewConverter(nil, map[string]any{"xmlTextKey": ""})
+	require.EqualError(t, err, "xmlTextKey must not be empty")
+	_, err = NewConverter(nil, map[string]any{"xmlRootElement": ""})
+	require.EqualError(t, err, "xmlRootElement and xmlItemElement must not be empty")
+	_, err = NewConverter(nil, map[string]any{"xmlItemElement": "my item"})
+	require.EqualError(t, err, "invalid xml element name my item")
+}
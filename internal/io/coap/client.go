@@ -0,0 +1,345 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+)
+
+const (
+	defaultPort = "5683"
+	// ackRandomFactor is the ACK_RANDOM_FACTOR of RFC 7252 section 4.8
+	ackRandomFactor = 1.5
+	// the count of the recently received message ids kept for deduplication
+	recentSize    = 32
+	maxPacketSize = 65535
+	// the back-off range of reading again after a read error
+	minReadBackoff = 10 * time.Millisecond
+	maxReadBackoff = time.Second
+)
+
+var errClosed = errors.New("coap client is closed")
+
+// parseServer returns the host:port address of the server url like coap://127.0.0.1:5683. The scheme and port are
+// optional.
+func parseServer(server string) (string, error) {
+	addr := strings.TrimPrefix(server, "coap://")
+	if addr == "" {
+		return "", fmt.Errorf("server is required")
+	}
+	if strings.Contains(addr, "://") {
+		return "", fmt.Errorf("invalid server %s, only coap:// is supported", server)
+	}
+	addr = strings.TrimSuffix(addr, "/")
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(strings.Trim(addr, "[]"), defaultPort)
+	}
+	return addr, nil
+}
+
+// client is a minimal CoAP client over UDP. It sends the confirmable requests with the exponential back-off
+// retransmission of RFC 7252 and receives the piggybacked or separate responses as well as the notifications of
+// the observed resources.
+type client struct {
+	addr          string
+	ackTimeout    time.Duration
+	maxRetransmit int
+	conn          *net.UDPConn
+
+	sync.Mutex
+	msgID uint16
+	// the waiters of the ACK or RST of the confirmable messages, keyed by message id
+	acks map[uint16]chan *message
+	// the waiters of the responses or notifications, keyed by token
+	exchanges map[string]chan *message
+	recent    []uint16
+	recentIdx int
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newClient(addr string, ackTimeout time.Duration, maxRetransmit int) (*client, error) {
+	raddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, errorx.NewIOErr(fmt.Sprintf("resolve coap server %s failed: %v", addr, err))
+	}
+	conn, err := net.DialUDP("udp", nil, raddr)
+	if err != nil {
+		return nil, errorx.NewIOErr(fmt.Sprintf("connect to coap server %s failed: %v", addr, err))
+	}
+	c := &client{
+		addr:          addr,
+		ackTimeout:    ackTimeout,
+		maxRetransmit: maxRetransmit,
+		conn:          conn,
+		msgID:         uint16(rand.Uint32()),
+		acks:          make(map[uint16]chan *message),
+		exchanges:     make(map[string]chan *message),
+		closed:        make(chan struct{}),
+	}
+	go c.readLoop()
+	return c, nil
+}
+
+func (c *client) close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		err = c.conn.Close()
+	})
+	return err
+}
+
+// maxTransmitWait is the MAX_TRANSMIT_WAIT of RFC 7252, the maximum time to wait for the response of a request
+func (c *client) maxTransmitWait() time.Duration {
+	return time.Duration(float64(c.ackTimeout) * float64(int(1)<<(c.maxRetransmit+1)-1) * ackRandomFactor)
+}
+
+func (c *client) readLoop() {
+	buf := make([]byte, maxPacketSize)
+	var backoff time.Duration
+	for {
+		n, err := c.conn.Read(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			// the icmp errors like port unreachable are reported by read, ignore them and let the request time out.
+			// Back off to avoid spinning if the error persists.
+			backoff = min(max(2*backoff, minReadBackoff), maxReadBackoff)
+			select {
+			case <-c.closed:
+				return
+			case <-time.After(backoff):
+				continue
+			}
+		}
+		backoff = 0
+		m, err := unmarshal(buf[:n])
+		if err != nil {
+			continue
+		}
+		c.handle(m)
+	}
+}
+
+func (c *client) handle(m *message) {
+	c.Lock()
+	defer c.Unlock()
+	switch m.typ {
+	case typeACK, typeRST:
+		if ch, ok := c.acks[m.messageID]; ok {
+			deliver(ch, m)
+		}
+	default:
+		ch, ok := c.exchanges[string(m.token)]
+		if !ok || m.code.isRequest() || m.code == codeEmpty {
+			// reject the unknown messages, the server will remove the observer if the token is not observed anymore
+			_ = c.write(&message{typ: typeRST, messageID: m.messageID})
+			return
+		}
+		if m.typ == typeCON {
+			_ = c.write(&message{typ: typeACK, messageID: m.messageID})
+		}
+		if c.isDuplicate(m.messageID) {
+			return
+		}
+		deliver(ch, m)
+	}
+}
+
+// isDuplicate checks whether the message is a retransmission of a recently received message
+func (c *client) isDuplicate(id uint16) bool {
+	for _, r := range c.recent {
+		if r == id {
+			return true
+		}
+	}
+	if len(c.recent) < recentSize {
+		c.recent = append(c.recent, id)
+	} else {
+		c.recent[c.recentIdx] = id
+		c.recentIdx = (c.recentIdx + 1) % recentSize
+	}
+	return false
+}
+
+// deliver sends the message without blocking the read loop. The message is dropped if the receiver is too slow.
+func deliver(ch chan *message, m *message) {
+	select {
+	case ch <- m:
+	default:
+	}
+}
+
+func (c *client) write(m *message) error {
+	data, err := m.marshal()
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write(data)
+	return err
+}
+
+// send sends the message with a new message id without waiting for any reply
+func (c *client) send(m *message) error {
+	c.Lock()
+	c.msgID++
+	m.messageID = c.msgID
+	c.Unlock()
+	return c.write(m)
+}
+
+func newToken() []byte {
+	token := make([]byte, 4)
+	_, _ = rand.Read(token)
+	return token
+}
+
+// do sends the request and returns the response. The non-confirmable request returns immediately without waiting
+// for the response.
+func (c *client) do(ctx context.Context, req *message) (*message, error) {
+	resp, _, err := c.exchange(ctx, req, false)
+	return resp, err
+}
+
+// observe sends the observe request and returns the first response. The following notifications are sent to the
+// returned channel until cancel is called with the token of the request.
+func (c *client) observe(ctx context.Context, req *message) (*message, <-chan *message, error) {
+	req.addUintOption(optObserve, 0)
+	return c.exchange(ctx, req, true)
+}
+
+func (c *client) cancel(token []byte) {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.exchanges, string(token))
+}
+
+func (c *client) exchange(ctx context.Context, req *message, keep bool) (*message, <-chan *message, error) {
+	req.token = newToken()
+	size := 1
+	if keep {
+		size = 16
+	}
+	respCh := make(chan *message, size)
+	c.Lock()
+	c.msgID++
+	req.messageID = c.msgID
+	c.exchanges[string(req.token)] = respCh
+	c.Unlock()
+	success := false
+	defer func() {
+		if !keep || !success {
+			c.cancel(req.token)
+		}
+	}()
+	if req.typ == typeNON {
+		if err := c.write(req); err != nil {
+			return nil, nil, errorx.NewIOErr(fmt.Sprintf("send to coap server %s failed: %v", c.addr, err))
+		}
+		return nil, nil, nil
+	}
+	ack, err := c.confirm(ctx, req, respCh)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ack.typ == typeRST {
+		return nil, nil, fmt.Errorf("request is reset by coap server %s", c.addr)
+	}
+	if ack.code == codeEmpty {
+		// the server acknowledged the request and will send the response separately
+		ack, err = c.wait(ctx, respCh)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	success = true
+	return ack, respCh, nil
+}
+
+// confirm sends the confirmable message with retransmission until the ACK or RST is received. The separate response
+// may arrive before the ACK if the ACK is lost, it is returned as the ACK with the response.
+func (c *client) confirm(ctx context.Context, m *message, respCh chan *message) (*message, error) {
+	ackCh := make(chan *message, 1)
+	c.Lock()
+	c.acks[m.messageID] = ackCh
+	c.Unlock()
+	defer func() {
+		c.Lock()
+		delete(c.acks, m.messageID)
+		c.Unlock()
+	}()
+	timeout := time.Duration(float64(c.ackTimeout) * (1 + rand.Float64()*(ackRandomFactor-1)))
+	for attempt := 0; ; attempt++ {
+		if err := c.write(m); err != nil {
+			return nil, errorx.NewIOErr(fmt.Sprintf("send to coap server %s failed: %v", c.addr, err))
+		}
+		timer := time.NewTimer(timeout)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-c.closed:
+			timer.Stop()
+			return nil, errClosed
+		case ack := <-ackCh:
+			timer.Stop()
+			return ack, nil
+		case resp := <-respCh:
+			timer.Stop()
+			return resp, nil
+		case <-timer.C:
+			if attempt >= c.maxRetransmit {
+				return nil, errorx.NewIOErr(fmt.Sprintf("coap server %s does not respond after %d retransmissions", c.addr, c.maxRetransmit))
+			}
+			timeout *= 2
+		}
+	}
+}
+
+func (c *client) wait(ctx context.Context, respCh chan *message) (*message, error) {
+	timer := time.NewTimer(c.maxTransmitWait())
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.closed:
+		return nil, errClosed
+	case resp := <-respCh:
+		return resp, nil
+	case <-timer.C:
+		return nil, errorx.NewIOErr(fmt.Sprintf("wait for the response from coap server %s timeout", c.addr))
+	}
+}
+
+// ping sends an empty confirmable message, the server replies with RST if it is alive
+func (c *client) ping(ctx context.Context) error {
+	c.Lock()
+	c.msgID++
+	m := &message{typ: typeCON, code: codeEmpty, messageID: c.msgID}
+	c.Unlock()
+	_, err := c.confirm(ctx, m, nil)
+	return err
+}
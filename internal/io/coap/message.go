@@ -0,0 +1,307 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coap
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// msgType is the message type defined in RFC 7252 section 3
+type msgType uint8
+
+const (
+	typeCON msgType = 0
+	typeNON msgType = 1
+	typeACK msgType = 2
+	typeRST msgType = 3
+)
+
+// code is the request method or response code in the c.dd format
+type code uint8
+
+func newCode(class, detail uint8) code {
+	return code(class<<5 | detail)
+}
+
+const (
+	codeEmpty code = 0
+	codeGET   code = 1
+	codePOST  code = 2
+	codePUT   code = 3
+)
+
+var (
+	codeChanged          = newCode(2, 4)
+	codeContent          = newCode(2, 5)
+	codeNotFound         = newCode(4, 4)
+	codeMethodNotAllowed = newCode(4, 5)
+)
+
+func (c code) class() uint8 {
+	return uint8(c) >> 5
+}
+
+func (c code) isRequest() bool {
+	return c.class() == 0 && c != codeEmpty
+}
+
+func (c code) String() string {
+	return fmt.Sprintf("%d.%02d", c.class(), uint8(c)&0x1f)
+}
+
+var methods = map[string]code{
+	"GET":  codeGET,
+	"POST": codePOST,
+	"PUT":  codePUT,
+}
+
+func methodName(c code) string {
+	for k, v := range methods {
+		if v == c {
+			return k
+		}
+	}
+	return c.String()
+}
+
+// The option numbers used by the connector
+const (
+	optObserve       uint16 = 6
+	optURIPath       uint16 = 11
+	optContentFormat uint16 = 12
+	optMaxAge        uint16 = 14
+	optURIQuery      uint16 = 15
+)
+
+type option struct {
+	num   uint16
+	value []byte
+}
+
+type message struct {
+	typ       msgType
+	code      code
+	messageID uint16
+	token     []byte
+	options   []option
+	payload   []byte
+}
+
+const (
+	coapVersion    = 1
+	payloadMarker  = 0xff
+	maxTokenLength = 8
+)
+
+var errInvalidMessage = errors.New("invalid coap message")
+
+func (m *message) addOption(num uint16, value []byte) {
+	m.options = append(m.options, option{num: num, value: value})
+}
+
+func (m *message) addUintOption(num uint16, v uint32) {
+	m.addOption(num, encodeUint(v))
+}
+
+func (m *message) option(num uint16) ([]byte, bool) {
+	for _, o := range m.options {
+		if o.num == num {
+			return o.value, true
+		}
+	}
+	return nil, false
+}
+
+func (m *message) uintOption(num uint16) (uint32, bool) {
+	v, ok := m.option(num)
+	if !ok || len(v) > 4 {
+		return 0, false
+	}
+	var r uint32
+	for _, b := range v {
+		r = r<<8 | uint32(b)
+	}
+	return r, true
+}
+
+// setPath sets the Uri-Path options from the path like /a/b and the Uri-Query options from the query like x=1&y=2
+func (m *message) setPath(path string) {
+	p, q, _ := strings.Cut(path, "?")
+	for _, seg := range strings.Split(strings.Trim(p, "/"), "/") {
+		if seg != "" {
+			m.addOption(optURIPath, []byte(seg))
+		}
+	}
+	if q != "" {
+		for _, seg := range strings.Split(q, "&") {
+			m.addOption(optURIQuery, []byte(seg))
+		}
+	}
+}
+
+// path returns the request path with a leading slash built from the Uri-Path options
+func (m *message) path() string {
+	var sb strings.Builder
+	for _, o := range m.options {
+		if o.num == optURIPath {
+			sb.WriteByte('/')
+			sb.Write(o.value)
+		}
+	}
+	if sb.Len() == 0 {
+		return "/"
+	}
+	return sb.String()
+}
+
+func encodeUint(v uint32) []byte {
+	switch {
+	case v == 0:
+		return nil
+	case v < 1<<8:
+		return []byte{byte(v)}
+	case v < 1<<16:
+		return []byte{byte(v >> 8), byte(v)}
+	case v < 1<<24:
+		return []byte{byte(v >> 16), byte(v >> 8), byte(v)}
+	default:
+		return []byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)}
+	}
+}
+
+// splitOptionValue returns the 4 bits nibble and the extended bytes of the option delta or length
+func splitOptionValue(v int) (byte, []byte) {
+	switch {
+	case v < 13:
+		return byte(v), nil
+	case v < 269:
+		return 13, []byte{byte(v - 13)}
+	default:
+		ext := make([]byte, 2)
+		binary.BigEndian.PutUint16(ext, uint16(v-269))
+		return 14, ext
+	}
+}
+
+func (m *message) marshal() ([]byte, error) {
+	if len(m.token) > maxTokenLength {
+		return nil, fmt.Errorf("token length %d exceeds %d", len(m.token), maxTokenLength)
+	}
+	buf := make([]byte, 4, 4+len(m.token)+len(m.payload)+16)
+	buf[0] = coapVersion<<6 | byte(m.typ)<<4 | byte(len(m.token))
+	buf[1] = byte(m.code)
+	binary.BigEndian.PutUint16(buf[2:], m.messageID)
+	buf = append(buf, m.token...)
+	opts := make([]option, len(m.options))
+	copy(opts, m.options)
+	// the options must be sorted by number while keeping the order of the repeated options
+	sort.SliceStable(opts, func(i, j int) bool {
+		return opts[i].num < opts[j].num
+	})
+	var prev uint16
+	for _, o := range opts {
+		if len(o.value) > 65535+269 {
+			return nil, fmt.Errorf("option %d is too long", o.num)
+		}
+		dn, dext := splitOptionValue(int(o.num - prev))
+		ln, lext := splitOptionValue(len(o.value))
+		buf = append(buf, dn<<4|ln)
+		buf = append(buf, dext...)
+		buf = append(buf, lext...)
+		buf = append(buf, o.value...)
+		prev = o.num
+	}
+	if len(m.payload) > 0 {
+		buf = append(buf, payloadMarker)
+		buf = append(buf, m.payload...)
+	}
+	return buf, nil
+}
+
+// readOptionValue reads the option delta or length from the nibble and the extended bytes
+func readOptionValue(nibble byte, data []byte) (int, []byte, error) {
+	switch nibble {
+	case 13:
+		if len(data) < 1 {
+			return 0, nil, errInvalidMessage
+		}
+		return int(data[0]) + 13, data[1:], nil
+	case 14:
+		if len(data) < 2 {
+			return 0, nil, errInvalidMessage
+		}
+		return int(binary.BigEndian.Uint16(data)) + 269, data[2:], nil
+	case 15:
+		return 0, nil, errInvalidMessage
+	default:
+		return int(nibble), data, nil
+	}
+}
+
+func unmarshal(data []byte) (*message, error) {
+	if len(data) < 4 || data[0]>>6 != coapVersion {
+		return nil, errInvalidMessage
+	}
+	tkl := int(data[0] & 0x0f)
+	if tkl > maxTokenLength || len(data) < 4+tkl {
+		return nil, errInvalidMessage
+	}
+	m := &message{
+		typ:       msgType(data[0] >> 4 & 0x03),
+		code:      code(data[1]),
+		messageID: binary.BigEndian.Uint16(data[2:]),
+	}
+	if tkl > 0 {
+		m.token = append([]byte(nil), data[4:4+tkl]...)
+	}
+	data = data[4+tkl:]
+	var num int
+	for len(data) > 0 {
+		if data[0] == payloadMarker {
+			if len(data) == 1 {
+				return nil, errInvalidMessage
+			}
+			m.payload = append([]byte(nil), data[1:]...)
+			break
+		}
+		b := data[0]
+		var (
+			delta, length int
+			err           error
+		)
+		delta, data, err = readOptionValue(b>>4, data[1:])
+		if err != nil {
+			return nil, err
+		}
+		length, data, err = readOptionValue(b&0x0f, data)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) < length {
+			return nil, errInvalidMessage
+		}
+		num += delta
+		if num > 65535 {
+			return nil, errInvalidMessage
+		}
+		m.options = append(m.options, option{num: uint16(num), value: append([]byte(nil), data[:length]...)})
+		data = data[length:]
+	}
+	return m, nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageCodec(t *testing.T) {
+	m := &message{
+		typ:       typeCON,
+		code:      codePOST,
+		messageID: 0x1234,
+		token:     []byte{1, 2, 3, 4},
+		payload:   []byte(`{"a":1}`),
+	}
+	m.setPath("/sensors/temp?unit=c&id=1")
+	m.addUintOption(optContentFormat, 50)
+	// options with the extended delta and length
+	m.addOption(2048, []byte(strings.Repeat("x", 300)))
+	data, err := m.marshal()
+	require.NoError(t, err)
+	// header of version 1, CON, token length 4 and code 0.02
+	require.Equal(t, []byte{0x44, 0x02, 0x12, 0x34, 1, 2, 3, 4}, data[:8])
+	// the first option is Uri-Path sensors with delta 11 and length 7
+	require.Equal(t, byte(0xb7), data[8])
+
+	r, err := unmarshal(data)
+	require.NoError(t, err)
+	require.Equal(t, typeCON, r.typ)
+	require.Equal(t, codePOST, r.code)
+	require.Equal(t, uint16(0x1234), r.messageID)
+	require.Equal(t, m.token, r.token)
+	require.Equal(t, m.payload, r.payload)
+	require.Equal(t, "/sensors/temp", r.path())
+	cf, ok := r.uintOption(optContentFormat)
+	require.True(t, ok)
+	require.Equal(t, uint32(50), cf)
+	v, ok := r.option(2048)
+	require.True(t, ok)
+	require.Len(t, v, 300)
+	var queries []string
+	for _, o := range r.options {
+		if o.num == optURIQuery {
+			queries = append(queries, string(o.value))
+		}
+	}
+	require.Equal(t, []string{"unit=c", "id=1"}, queries)
+
+	// empty message
+	data, err = (&message{typ: typeRST, messageID: 7}).marshal()
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x70, 0, 0, 7}, data)
+	r, err = unmarshal(data)
+	require.NoError(t, err)
+	require.Equal(t, typeRST, r.typ)
+	require.Equal(t, codeEmpty, r.code)
+	require.Equal(t, "/", r.path())
+}
+
+func TestUnmarshalErr(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "short", data: []byte{0x40, 0x01}},
+		{name: "version", data: []byte{0x80, 0x01, 0, 1}},
+		{name: "token length", data: []byte{0x49, 0x01, 0, 1}},
+		{name: "truncated token", data: []byte{0x44, 0x01, 0, 1, 1}},
+		{name: "empty payload", data: []byte{0x40, 0x01, 0, 1, 0xff}},
+		{name: "truncated option", data: []byte{0x40, 0x01, 0, 1, 0xb5, 'a'}},
+		{name: "reserved delta", data: []byte{0x40, 0x01, 0, 1, 0xf1, 'a'}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := unmarshal(tt.data)
+			require.Equal(t, errInvalidMessage, err)
+		})
+	}
+}
+
+func TestParseServer(t *testing.T) {
+	tests := []struct {
+		server string
+		addr   string
+		err    string
+	}{
+		{server: "coap://127.0.0.1:5684", addr: "127.0.0.1:5684"},
+		{server: "coap://127.0.0.1", addr: "127.0.0.1:5683"},
+		{server: "localhost:1234/", addr: "localhost:1234"},
+		{server: "coap://[::1]", addr: "[::1]:5683"},
+		{server: "", err: "server is required"},
+		{server: "coaps://127.0.0.1", err: "invalid server coaps://127.0.0.1, only coap:// is supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.server, func(t *testing.T) {
+			addr, err := parseServer(tt.server)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.addr, addr)
+			}
+		})
+	}
+}
+
+func TestIsFresher(t *testing.T) {
+	t1 := timeAt(0)
+	require.True(t, isFresher(1, t1, 2, timeAt(1)))
+	require.False(t, isFresher(2, t1, 1, timeAt(1)))
+	// wrap around
+	require.True(t, isFresher(1<<24-1, t1, 0, timeAt(1)))
+	// too old to compare
+	require.True(t, isFresher(2, t1, 1, timeAt(129)))
+}
@@ -0,0 +1,244 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coap
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+// exchangeLifetime is the EXCHANGE_LIFETIME of RFC 7252 with the default transmission parameters. The responses of
+// the confirmable requests are kept in this period to reply the retransmitted requests.
+const exchangeLifetime = 247 * time.Second
+
+// request is a POST or PUT request received by the server
+type request struct {
+	path          string
+	method        string
+	remoteAddr    string
+	payload       []byte
+	contentFormat int64
+	hasFormat     bool
+	rcvTime       time.Time
+}
+
+// handler consumes the request. It must not block the server.
+type handler func(req *request)
+
+type cachedResponse struct {
+	data    []byte
+	expires time.Time
+}
+
+// server is a CoAP server listening on a UDP address. It is shared by the sources with the same listen address and
+// dispatches the requests to the sources by path and method. It is closed once no route is registered.
+type server struct {
+	conn *net.UDPConn
+
+	sync.RWMutex
+	// path -> method -> source id -> handler
+	routes map[string]map[string]map[string]handler
+	msgID  uint16
+	// the responses of the recently received confirmable requests, keyed by the remote address and message id
+	responses map[string]*cachedResponse
+	lastPurge time.Time
+}
+
+var (
+	serversLock sync.Mutex
+	servers     = make(map[string]*server)
+)
+
+// registerRoute registers the handler of the path and method to the server of the listen address. The server is
+// started if it is not running yet.
+func registerRoute(addr, path, method, id string, h handler) error {
+	serversLock.Lock()
+	defer serversLock.Unlock()
+	s, ok := servers[addr]
+	if !ok {
+		laddr, err := net.ResolveUDPAddr("udp", addr)
+		if err != nil {
+			return fmt.Errorf("invalid listenAddr %s: %v", addr, err)
+		}
+		conn, err := net.ListenUDP("udp", laddr)
+		if err != nil {
+			return fmt.Errorf("listen on %s failed: %v", addr, err)
+		}
+		s = &server{
+			conn:      conn,
+			routes:    make(map[string]map[string]map[string]handler),
+			responses: make(map[string]*cachedResponse),
+		}
+		servers[addr] = s
+		go s.serve()
+		conf.Log.Infof("coap server listens on %s", conn.LocalAddr())
+	}
+	s.Lock()
+	defer s.Unlock()
+	if s.routes[path] == nil {
+		s.routes[path] = make(map[string]map[string]handler)
+	}
+	if s.routes[path][method] == nil {
+		s.routes[path][method] = make(map[string]handler)
+	}
+	s.routes[path][method][id] = h
+	return nil
+}
+
+// unregisterRoute removes the handler and stops the server if there is no route anymore
+func unregisterRoute(addr, path, method, id string) {
+	serversLock.Lock()
+	defer serversLock.Unlock()
+	s, ok := servers[addr]
+	if !ok {
+		return
+	}
+	s.Lock()
+	delete(s.routes[path][method], id)
+	if len(s.routes[path][method]) == 0 {
+		delete(s.routes[path], method)
+	}
+	if len(s.routes[path]) == 0 {
+		delete(s.routes, path)
+	}
+	empty := len(s.routes) == 0
+	s.Unlock()
+	if empty {
+		delete(servers, addr)
+		_ = s.conn.Close()
+		conf.Log.Infof("coap server on %s is closed", addr)
+	}
+}
+
+func (s *server) serve() {
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, from, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				continue
+			}
+			// the connection is closed
+			return
+		}
+		m, err := unmarshal(buf[:n])
+		if err != nil {
+			conf.Log.Debugf("drop invalid coap message from %s", from)
+			continue
+		}
+		s.handle(m, from)
+	}
+}
+
+func (s *server) handle(m *message, from *net.UDPAddr) {
+	if m.typ == typeACK || m.typ == typeRST {
+		return
+	}
+	if !m.code.isRequest() {
+		// reply RST to the ping(empty CON) and the unexpected responses
+		if m.typ == typeCON {
+			s.write(&message{typ: typeRST, messageID: m.messageID}, from)
+		}
+		return
+	}
+	key := fmt.Sprintf("%s#%d", from, m.messageID)
+	if m.typ == typeCON {
+		s.RLock()
+		cached, ok := s.responses[key]
+		s.RUnlock()
+		if ok && timex.GetNow().Before(cached.expires) {
+			// the retransmission of a processed request, reply the same response without processing it again
+			_, _ = s.conn.WriteToUDP(cached.data, from)
+			return
+		}
+	}
+	resp := &message{code: s.dispatch(m, from), token: m.token}
+	if m.typ == typeCON {
+		resp.typ = typeACK
+		resp.messageID = m.messageID
+	} else {
+		s.Lock()
+		s.msgID++
+		resp.typ = typeNON
+		resp.messageID = s.msgID
+		s.Unlock()
+	}
+	data := s.write(resp, from)
+	if m.typ == typeCON && data != nil {
+		s.cache(key, data)
+	}
+}
+
+// dispatch sends the request to all the handlers of the path and method and returns the response code
+func (s *server) dispatch(m *message, from *net.UDPAddr) code {
+	path := m.path()
+	method := methodName(m.code)
+	s.RLock()
+	defer s.RUnlock()
+	methods, ok := s.routes[path]
+	if !ok {
+		return codeNotFound
+	}
+	handlers, ok := methods[method]
+	if !ok {
+		return codeMethodNotAllowed
+	}
+	req := &request{
+		path:       path,
+		method:     method,
+		remoteAddr: from.String(),
+		payload:    m.payload,
+		rcvTime:    timex.GetNow(),
+	}
+	if cf, ok := m.uintOption(optContentFormat); ok {
+		req.contentFormat = int64(cf)
+		req.hasFormat = true
+	}
+	for _, h := range handlers {
+		h(req)
+	}
+	return codeChanged
+}
+
+func (s *server) write(m *message, to *net.UDPAddr) []byte {
+	data, err := m.marshal()
+	if err != nil {
+		return nil
+	}
+	if _, err = s.conn.WriteToUDP(data, to); err != nil {
+		conf.Log.Debugf("reply to coap client %s failed: %v", to, err)
+	}
+	return data
+}
+
+func (s *server) cache(key string, data []byte) {
+	s.Lock()
+	defer s.Unlock()
+	now := timex.GetNow()
+	s.responses[key] = &cachedResponse{data: data, expires: now.Add(exchangeLifetime)}
+	if now.Sub(s.lastPurge) > time.Minute {
+		for k, v := range s.responses {
+			if now.After(v.expires) {
+				delete(s.responses, k)
+			}
+		}
+		s.lastPurge = now
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coap
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func timeAt(sec int) time.Time {
+	return time.Unix(1700000000+int64(sec), 0)
+}
+
+// freeUDPAddr returns a local udp address which is not in use
+func freeUDPAddr(t *testing.T) string {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	addr := conn.LocalAddr().String()
+	require.NoError(t, conn.Close())
+	return addr
+}
+
+// observeServer is an in-process CoAP server for testing. It serves an observable resource and can drop the first
+// requests or reply the separate responses to test the retransmission.
+type observeServer struct {
+	conn *net.UDPConn
+	path string
+
+	sync.Mutex
+	value     []byte
+	seq       uint32
+	msgID     uint16
+	observers map[string]*observer
+	// the count of the requests to drop
+	drop int
+	// reply the empty ACK and send the response separately
+	separate bool
+	// the received requests except the dropped ones
+	requests []*message
+}
+
+type observer struct {
+	addr  *net.UDPAddr
+	token []byte
+}
+
+func newObserveServer(t *testing.T, path string) *observeServer {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	s := &observeServer{
+		conn:      conn,
+		path:      path,
+		value:     []byte(`{"temperature":20}`),
+		observers: make(map[string]*observer),
+	}
+	go s.serve()
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return s
+}
+
+func (s *observeServer) addr() string {
+	return s.conn.LocalAddr().String()
+}
+
+func (s *observeServer) serve() {
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, from, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		m, err := unmarshal(buf[:n])
+		if err != nil {
+			continue
+		}
+		s.handle(m, from)
+	}
+}
+
+func (s *observeServer) handle(m *message, from *net.UDPAddr) {
+	s.Lock()
+	defer s.Unlock()
+	switch {
+	case m.typ == typeRST:
+		// the client is not interested in the notifications anymore
+		for k, o := range s.observers {
+			if o.addr.String() == from.String() {
+				delete(s.observers, k)
+			}
+		}
+		return
+	case m.typ == typeACK:
+		return
+	case m.code == codeEmpty:
+		s.send(&message{typ: typeRST, messageID: m.messageID}, from)
+		return
+	}
+	if s.drop > 0 {
+		s.drop--
+		return
+	}
+	s.requests = append(s.requests, m)
+	resp := &message{token: m.token}
+	switch {
+	case m.path() != s.path:
+		resp.code = codeNotFound
+	case m.code == codeGET:
+		resp.code = codeContent
+		resp.payload = s.value
+		if v, ok := m.uintOption(optObserve); ok && v == 0 {
+			s.observers[string(m.token)] = &observer{addr: from, token: m.token}
+			resp.addUintOption(optObserve, s.seq)
+		} else if ok && v == 1 {
+			delete(s.observers, string(m.token))
+		}
+		resp.addUintOption(optContentFormat, 50)
+	case m.code == codePOST:
+		resp.code = codeChanged
+		s.value = m.payload
+	default:
+		resp.code = codeMethodNotAllowed
+	}
+	if m.typ == typeNON {
+		return
+	}
+	if s.separate {
+		s.send(&message{typ: typeACK, messageID: m.messageID}, from)
+		s.msgID++
+		resp.typ = typeCON
+		resp.messageID = s.msgID
+	} else {
+		resp.typ = typeACK
+		resp.messageID = m.messageID
+	}
+	s.send(resp, from)
+}
+
+func (s *observeServer) send(m *message, to *net.UDPAddr) {
+	data, _ := m.marshal()
+	_, _ = s.conn.WriteToUDP(data, to)
+}
+
+// notify changes the value and sends the notification to all observers
+func (s *observeServer) notify(value string, maxAge uint32) {
+	s.Lock()
+	defer s.Unlock()
+	s.value = []byte(value)
+	s.seq++
+	for _, o := range s.observers {
+		s.msgID++
+		n := &message{typ: typeCON, code: codeContent, messageID: s.msgID, token: o.token, payload: s.value}
+		n.addUintOption(optObserve, s.seq)
+		n.addUintOption(optContentFormat, 50)
+		if maxAge > 0 {
+			n.addUintOption(optMaxAge, maxAge)
+		}
+		s.send(n, o.addr)
+	}
+}
+
+func (s *observeServer) observerCount() int {
+	s.Lock()
+	defer s.Unlock()
+	return len(s.observers)
+}
+
+func (s *observeServer) requestCount() int {
+	s.Lock()
+	defer s.Unlock()
+	return len(s.requests)
+}
+
+func (s *observeServer) lastRequest() *message {
+	s.Lock()
+	defer s.Unlock()
+	if len(s.requests) == 0 {
+		return nil
+	}
+	return s.requests[len(s.requests)-1]
+}
@@ -0,0 +1,174 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coap
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+// contentFormats maps the formats to the CoAP Content-Format registry values
+var contentFormats = map[string]int{
+	"delimited": 0,
+	"xml":       41,
+	"binary":    42,
+	"json":      50,
+	"cbor":      60,
+}
+
+type SinkConf struct {
+	// Server is the remote server url like coap://127.0.0.1:5683
+	Server string `json:"server"`
+	Path   string `json:"path"`
+	// Method is the request method, POST or PUT
+	Method string `json:"method"`
+	// Confirmable sends the CON request which is retransmitted until acknowledged, otherwise NON request is sent
+	Confirmable bool `json:"confirmable"`
+	// ContentFormat is the Content-Format option of the request. The negative value means to derive it from the format.
+	ContentFormat int               `json:"contentFormat"`
+	Format        string            `json:"format"`
+	AckTimeout    cast.DurationConf `json:"ackTimeout"`
+	MaxRetransmit int               `json:"maxRetransmit"`
+}
+
+// Sink sends each payload as a CoAP request to the remote server
+type Sink struct {
+	cfg  *SinkConf
+	addr string
+	cli  *client
+}
+
+func (s *Sink) Provision(_ api.StreamContext, props map[string]any) error {
+	cfg := &SinkConf{
+		Method:        "POST",
+		Confirmable:   true,
+		ContentFormat: -1,
+		Format:        "json",
+		AckTimeout:    cast.DurationConf(2 * time.Second),
+		MaxRetransmit: 4,
+	}
+	err := cast.MapToStruct(props, cfg)
+	if err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	s.addr, err = validateClientConf(cfg.Server, cfg.AckTimeout, cfg.MaxRetransmit)
+	if err != nil {
+		return err
+	}
+	if cfg.Path == "" {
+		return fmt.Errorf("path is required")
+	}
+	cfg.Method = strings.ToUpper(cfg.Method)
+	if cfg.Method != "POST" && cfg.Method != "PUT" {
+		return fmt.Errorf("method %s is not supported, must be POST or PUT", cfg.Method)
+	}
+	if cfg.ContentFormat < 0 {
+		cf, ok := contentFormats[strings.ToLower(cfg.Format)]
+		if !ok {
+			// no Content-Format option for the unknown formats
+			cf = -1
+		}
+		cfg.ContentFormat = cf
+	} else if cfg.ContentFormat > 65535 {
+		return fmt.Errorf("invalid contentFormat %d, must be in range 0-65535", cfg.ContentFormat)
+	}
+	s.cfg = cfg
+	return nil
+}
+
+func (s *Sink) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	ctx.GetLogger().Infof("Connecting to coap server %s", s.addr)
+	cli, err := newClient(s.addr, time.Duration(s.cfg.AckTimeout), s.cfg.MaxRetransmit)
+	if err != nil {
+		sch(api.ConnectionDisconnected, err.Error())
+		return err
+	}
+	s.cli = cli
+	sch(api.ConnectionConnected, "")
+	return nil
+}
+
+func (s *Sink) Collect(ctx api.StreamContext, item api.RawTuple) error {
+	path := s.cfg.Path
+	// If path supports dynamic props(template), planner will guarantee the result has the parsed dynamic props
+	if dp, ok := item.(api.HasDynamicProps); ok {
+		temp, transformed := dp.DynamicProps(path)
+		if transformed {
+			path = temp
+		}
+	}
+	req := &message{typ: typeNON, code: methods[s.cfg.Method], payload: item.Raw()}
+	if s.cfg.Confirmable {
+		req.typ = typeCON
+	}
+	req.setPath(path)
+	if s.cfg.ContentFormat >= 0 {
+		req.addUintOption(optContentFormat, uint32(s.cfg.ContentFormat))
+	}
+	ctx.GetLogger().Debugf("send coap %s request to %s", s.cfg.Method, path)
+	resp, err := s.cli.do(ctx, req)
+	if err != nil {
+		return err
+	}
+	if resp != nil && resp.code.class() != 2 {
+		return fmt.Errorf("coap %s request to %s failed with response code %s: %s", s.cfg.Method, path, resp.code, resp.payload)
+	}
+	return nil
+}
+
+func (s *Sink) Close(ctx api.StreamContext) error {
+	ctx.GetLogger().Infof("Closing coap sink")
+	if s.cli != nil {
+		return s.cli.close()
+	}
+	return nil
+}
+
+// Ping checks the remote server by the CoAP ping
+func (s *Sink) Ping(ctx api.StreamContext, props map[string]any) error {
+	cfg := &SinkConf{
+		AckTimeout:    cast.DurationConf(2 * time.Second),
+		MaxRetransmit: 4,
+	}
+	err := cast.MapToStruct(props, cfg)
+	if err != nil {
+		return err
+	}
+	addr, err := validateClientConf(cfg.Server, cfg.AckTimeout, cfg.MaxRetransmit)
+	if err != nil {
+		return err
+	}
+	cli, err := newClient(addr, time.Duration(cfg.AckTimeout), cfg.MaxRetransmit)
+	if err != nil {
+		return err
+	}
+	defer cli.close()
+	return cli.ping(ctx)
+}
+
+func GetSink() api.Sink {
+	return &Sink{}
+}
+
+var (
+	_ api.BytesCollector = &Sink{}
+	_ util.PingableConn  = &Sink{}
+)
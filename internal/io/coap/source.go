@@ -0,0 +1,355 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coap
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/infra"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+const (
+	modeServer  = "server"
+	modeObserve = "observe"
+	// defaultMaxAge is the default Max-Age of the notifications defined in RFC 7252
+	defaultMaxAge = 60 * time.Second
+)
+
+// observeRetryInterval is the interval to register the observation again after it fails
+var observeRetryInterval = 5 * time.Second
+
+type SourceConf struct {
+	// Mode is server to accept the requests or observe to observe a remote resource
+	Mode string `json:"mode"`
+	// Path is the resource path to accept the requests in server mode or to observe in observe mode
+	Path string `json:"datasource"`
+	// ListenAddr is the udp address to listen in server mode
+	ListenAddr string `json:"listenAddr"`
+	// Method is the request method accepted in server mode, POST or PUT
+	Method       string `json:"method"`
+	BufferLength int    `json:"bufferLength"`
+	// Server is the remote server url in observe mode like coap://127.0.0.1:5683
+	Server        string            `json:"server"`
+	AckTimeout    cast.DurationConf `json:"ackTimeout"`
+	MaxRetransmit int               `json:"maxRetransmit"`
+}
+
+// Source receives the payloads from the CoAP clients as a server or from the notifications of an observed remote
+// resource. The payloads are decoded by the configured format.
+type Source struct {
+	cfg  *SourceConf
+	addr string
+	id   string
+	sch  api.StatusChangeHandler
+	// server mode
+	ch chan *request
+	// observe mode
+	cli           *client
+	retryInterval time.Duration
+	// the token of the current observation, used to deregister on close
+	lock  sync.Mutex
+	token []byte
+}
+
+func validateClientConf(server string, ackTimeout cast.DurationConf, maxRetransmit int) (string, error) {
+	addr, err := parseServer(server)
+	if err != nil {
+		return "", err
+	}
+	if ackTimeout <= 0 {
+		return "", fmt.Errorf("invalid ackTimeout %v, must be positive", time.Duration(ackTimeout))
+	}
+	if maxRetransmit < 0 || maxRetransmit > 10 {
+		return "", fmt.Errorf("invalid maxRetransmit %d, must be in range 0-10", maxRetransmit)
+	}
+	return addr, nil
+}
+
+func (s *Source) Provision(_ api.StreamContext, props map[string]any) error {
+	cfg := &SourceConf{
+		Mode:          modeServer,
+		ListenAddr:    ":5683",
+		Method:        "POST",
+		BufferLength:  1024,
+		AckTimeout:    cast.DurationConf(2 * time.Second),
+		MaxRetransmit: 4,
+	}
+	err := cast.MapToStruct(props, cfg)
+	if err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if !strings.HasPrefix(cfg.Path, "/") {
+		return fmt.Errorf("datasource %s must be a path starting with /", cfg.Path)
+	}
+	switch cfg.Mode {
+	case modeServer:
+		cfg.Method = strings.ToUpper(cfg.Method)
+		if cfg.Method != "POST" && cfg.Method != "PUT" {
+			return fmt.Errorf("method %s is not supported, must be POST or PUT", cfg.Method)
+		}
+		if strings.Contains(cfg.Path, "?") {
+			return fmt.Errorf("datasource %s must not contain the query in server mode", cfg.Path)
+		}
+		if cfg.ListenAddr == "" {
+			return fmt.Errorf("listenAddr is required in server mode")
+		}
+		if cfg.BufferLength <= 0 {
+			return fmt.Errorf("invalid bufferLength %d, must be positive", cfg.BufferLength)
+		}
+		// normalize the path so that it matches the path built from the request options
+		cfg.Path = "/" + strings.Trim(cfg.Path, "/")
+	case modeObserve:
+		s.addr, err = validateClientConf(cfg.Server, cfg.AckTimeout, cfg.MaxRetransmit)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid mode %s, must be server or observe", cfg.Mode)
+	}
+	s.cfg = cfg
+	return nil
+}
+
+func (s *Source) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	s.sch = sch
+	s.id = fmt.Sprintf("%s_%s_%v", ctx.GetRuleId(), ctx.GetOpId(), ctx.GetInstanceId())
+	if s.cfg.Mode == modeServer {
+		ctx.GetLogger().Infof("Registering coap path %s %s on %s", s.cfg.Method, s.cfg.Path, s.cfg.ListenAddr)
+		s.ch = make(chan *request, s.cfg.BufferLength)
+		err := registerRoute(s.cfg.ListenAddr, s.cfg.Path, s.cfg.Method, s.id, func(req *request) {
+			select {
+			case s.ch <- req:
+			default:
+				ctx.GetLogger().Warnf("coap source buffer is full, drop the request from %s", req.remoteAddr)
+			}
+		})
+		if err != nil {
+			sch(api.ConnectionDisconnected, err.Error())
+			return err
+		}
+		sch(api.ConnectionConnected, "")
+		return nil
+	}
+	ctx.GetLogger().Infof("Connecting to coap server %s", s.addr)
+	cli, err := newClient(s.addr, time.Duration(s.cfg.AckTimeout), s.cfg.MaxRetransmit)
+	if err != nil {
+		sch(api.ConnectionDisconnected, err.Error())
+		return err
+	}
+	s.cli = cli
+	s.retryInterval = observeRetryInterval
+	return nil
+}
+
+func (s *Source) Subscribe(ctx api.StreamContext, ingest api.BytesIngest, ingestError api.ErrorIngest) error {
+	if s.cfg.Mode == modeServer {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case req := <-s.ch:
+					meta := map[string]any{
+						"path":       req.path,
+						"method":     req.method,
+						"remoteAddr": req.remoteAddr,
+					}
+					if req.hasFormat {
+						meta["contentFormat"] = req.contentFormat
+					}
+					e := infra.SafeRun(func() error {
+						ingest(ctx, req.payload, meta, req.rcvTime)
+						return nil
+					})
+					if e != nil {
+						ingestError(ctx, e)
+					}
+				}
+			}
+		}()
+		return nil
+	}
+	go s.observeLoop(ctx, ingest)
+	return nil
+}
+
+// observeLoop keeps the observation alive. The observation is registered again if it fails or no notification is
+// received in the Max-Age of the last notification.
+func (s *Source) observeLoop(ctx api.StreamContext, ingest api.BytesIngest) {
+	for {
+		err := s.observe(ctx, ingest)
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		if err == nil {
+			continue
+		}
+		ctx.GetLogger().Warnf("observe coap resource %s failed: %v", s.cfg.Path, err)
+		s.sch(api.ConnectionDisconnected, err.Error())
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.retryInterval):
+		}
+	}
+}
+
+// observe registers the observation and ingests the notifications until the observation ends
+func (s *Source) observe(ctx api.StreamContext, ingest api.BytesIngest) error {
+	req := &message{typ: typeCON, code: codeGET}
+	req.setPath(s.cfg.Path)
+	resp, notifications, err := s.cli.observe(ctx, req)
+	if err != nil {
+		return err
+	}
+	s.setToken(req.token)
+	defer func() {
+		s.setToken(nil)
+		s.cli.cancel(req.token)
+	}()
+	if resp.code != codeContent {
+		return fmt.Errorf("observe %s got response code %s", s.cfg.Path, resp.code)
+	}
+	seq, ok := resp.uintOption(optObserve)
+	if !ok {
+		return fmt.Errorf("resource %s is not observable", s.cfg.Path)
+	}
+	s.sch(api.ConnectionConnected, "")
+	ctx.GetLogger().Infof("coap resource %s is observed", s.cfg.Path)
+	s.ingest(ctx, resp, seq, ingest)
+	lastTime := time.Now()
+	timer := time.NewTimer(s.maxAge(resp))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+			ctx.GetLogger().Infof("no notification of coap resource %s in max age, register again", s.cfg.Path)
+			return nil
+		case n := <-notifications:
+			if n.code.class() != 2 {
+				return fmt.Errorf("observation of %s is cancelled with response code %s", s.cfg.Path, n.code)
+			}
+			v, ok := n.uintOption(optObserve)
+			if !ok {
+				// the response without observe option ends the observation
+				s.ingest(ctx, n, seq, ingest)
+				return nil
+			}
+			now := time.Now()
+			if !isFresher(seq, lastTime, v, now) {
+				ctx.GetLogger().Debugf("drop the reordered notification %d of %s", v, s.cfg.Path)
+				continue
+			}
+			seq, lastTime = v, now
+			s.ingest(ctx, n, seq, ingest)
+			timer.Reset(s.maxAge(n))
+		}
+	}
+}
+
+// isFresher checks whether the notification v2 received at t2 is newer than v1 received at t1 by the rule of RFC 7641
+// section 3.4
+func isFresher(v1 uint32, t1 time.Time, v2 uint32, t2 time.Time) bool {
+	return (v1 < v2 && v2-v1 < 1<<23) || (v1 > v2 && v1-v2 > 1<<23) || t2.After(t1.Add(128*time.Second))
+}
+
+// maxAge returns the time to wait for the next notification before registering again
+func (s *Source) maxAge(m *message) time.Duration {
+	age := defaultMaxAge
+	if v, ok := m.uintOption(optMaxAge); ok {
+		age = time.Duration(v) * time.Second
+	}
+	return age + time.Duration(s.cfg.AckTimeout)
+}
+
+func (s *Source) ingest(ctx api.StreamContext, m *message, seq uint32, ingest api.BytesIngest) {
+	meta := map[string]any{
+		"path":    s.cfg.Path,
+		"observe": int64(seq),
+	}
+	if cf, ok := m.uintOption(optContentFormat); ok {
+		meta["contentFormat"] = int64(cf)
+	}
+	ingest(ctx, m.payload, meta, timex.GetNow())
+}
+
+func (s *Source) setToken(token []byte) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.token = token
+}
+
+func (s *Source) Close(ctx api.StreamContext) error {
+	ctx.GetLogger().Infof("Closing coap source")
+	if s.cfg.Mode == modeServer {
+		if s.id != "" {
+			unregisterRoute(s.cfg.ListenAddr, s.cfg.Path, s.cfg.Method, s.id)
+		}
+		return nil
+	}
+	if s.cli == nil {
+		return nil
+	}
+	s.lock.Lock()
+	token := s.token
+	s.lock.Unlock()
+	if token != nil {
+		// deregister the observation so that the server stops sending the notifications
+		req := &message{typ: typeNON, code: codeGET, token: token}
+		req.setPath(s.cfg.Path)
+		req.addUintOption(optObserve, 1)
+		if err := s.cli.send(req); err != nil {
+			ctx.GetLogger().Warnf("deregister the observation of %s failed: %v", s.cfg.Path, err)
+		}
+	}
+	return s.cli.close()
+}
+
+// Ping checks the remote server by the CoAP ping in observe mode
+func (s *Source) Ping(ctx api.StreamContext, props map[string]any) error {
+	if err := s.Provision(ctx, props); err != nil {
+		return err
+	}
+	if s.cfg.Mode != modeObserve {
+		return nil
+	}
+	cli, err := newClient(s.addr, time.Duration(s.cfg.AckTimeout), s.cfg.MaxRetransmit)
+	if err != nil {
+		return err
+	}
+	defer cli.close()
+	return cli.ping(ctx)
+}
+
+func GetSource() api.Source {
+	return &Source{}
+}
+
+var (
+	_ api.BytesSource   = &Source{}
+	_ util.PingableConn = &Source{}
+)
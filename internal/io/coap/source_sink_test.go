@@ -0,0 +1,304 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coap
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+type received struct {
+	payload []byte
+	meta    map[string]any
+}
+
+// startSource provisions, connects and subscribes the source. The received payloads are sent to the returned channel.
+func startSource(t *testing.T, ctx api.StreamContext, props map[string]any, sch api.StatusChangeHandler) (*Source, chan received) {
+	s := GetSource().(*Source)
+	require.NoError(t, s.Provision(ctx, props))
+	if sch == nil {
+		sch = func(string, string) {}
+	}
+	require.NoError(t, s.Connect(ctx, sch))
+	ch := make(chan received, 10)
+	require.NoError(t, s.Subscribe(ctx, func(_ api.StreamContext, payload []byte, meta map[string]any, _ time.Time) {
+		ch <- received{payload: payload, meta: meta}
+	}, func(_ api.StreamContext, err error) {
+		require.NoError(t, err)
+	}))
+	return s, ch
+}
+
+func expectReceived(t *testing.T, ch chan received) received {
+	select {
+	case r := <-ch:
+		return r
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "timeout")
+		return received{}
+	}
+}
+
+func send(t *testing.T, ctx api.StreamContext, props map[string]any, data string) error {
+	s := GetSink().(*Sink)
+	require.NoError(t, s.Provision(ctx, props))
+	require.NoError(t, s.Connect(ctx, func(string, string) {}))
+	defer s.Close(ctx)
+	return s.Collect(ctx, &xsql.RawTuple{Rawdata: []byte(data)})
+}
+
+func TestServerSource(t *testing.T) {
+	ctx, cancel := mockContext.NewMockContext("rule1", "op1").WithCancel()
+	defer cancel()
+	addr := freeUDPAddr(t)
+	s1, ch1 := startSource(t, ctx, map[string]any{
+		"listenAddr": addr,
+		"datasource": "/sensors/temp/",
+	}, nil)
+	s2, ch2 := startSource(t, mockContext.NewMockContext("rule2", "op1"), map[string]any{
+		"listenAddr": addr,
+		"datasource": "/sensors/humidity",
+		"method":     "put",
+	}, nil)
+
+	require.NoError(t, send(t, ctx, map[string]any{"server": "coap://" + addr, "path": "/sensors/temp"}, `{"temperature":22}`))
+	r := expectReceived(t, ch1)
+	require.Equal(t, `{"temperature":22}`, string(r.payload))
+	require.Equal(t, "/sensors/temp", r.meta["path"])
+	require.Equal(t, "POST", r.meta["method"])
+	require.Equal(t, int64(50), r.meta["contentFormat"])
+	require.NotEmpty(t, r.meta["remoteAddr"])
+
+	require.NoError(t, send(t, ctx, map[string]any{"server": addr, "path": "sensors/humidity", "method": "PUT", "format": "delimited", "confirmable": false}, "60"))
+	r = expectReceived(t, ch2)
+	require.Equal(t, "60", string(r.payload))
+	require.Equal(t, "PUT", r.meta["method"])
+	require.Equal(t, int64(0), r.meta["contentFormat"])
+
+	err := send(t, ctx, map[string]any{"server": addr, "path": "/sensors/temp", "method": "PUT"}, "{}")
+	require.EqualError(t, err, "coap PUT request to /sensors/temp failed with response code 4.05: ")
+	err = send(t, ctx, map[string]any{"server": addr, "path": "/unknown"}, "{}")
+	require.EqualError(t, err, "coap POST request to /unknown failed with response code 4.04: ")
+
+	// the server is closed after all sources are closed
+	require.NoError(t, s2.Close(ctx))
+	require.NoError(t, send(t, ctx, map[string]any{"server": addr, "path": "/sensors/temp"}, `{"temperature":23}`))
+	expectReceived(t, ch1)
+	require.NoError(t, s1.Close(ctx))
+	serversLock.Lock()
+	require.Empty(t, servers)
+	serversLock.Unlock()
+}
+
+func TestServerDuplicate(t *testing.T) {
+	ctx, cancel := mockContext.NewMockContext("rule1", "op1").WithCancel()
+	defer cancel()
+	addr := freeUDPAddr(t)
+	s, ch := startSource(t, ctx, map[string]any{"listenAddr": addr, "datasource": "/data"}, nil)
+	defer s.Close(ctx)
+	cli, err := newClient(addr, time.Second, 0)
+	require.NoError(t, err)
+	defer cli.close()
+	m := &message{typ: typeCON, code: codePOST, messageID: 100, token: []byte{1}, payload: []byte("a")}
+	m.setPath("/data")
+	// the retransmitted request is acknowledged but only ingested once
+	for i := 0; i < 2; i++ {
+		require.NoError(t, cli.write(m))
+	}
+	expectReceived(t, ch)
+	select {
+	case <-ch:
+		require.Fail(t, "duplicated request is ingested")
+	case <-time.After(200 * time.Millisecond):
+	}
+	// the request is processed again after the exchange lifetime
+	timex.Add(exchangeLifetime + time.Second)
+	require.NoError(t, cli.write(m))
+	expectReceived(t, ch)
+	// ping is replied with RST
+	require.NoError(t, cli.ping(ctx))
+}
+
+func TestObserve(t *testing.T) {
+	ctx, cancel := mockContext.NewMockContext("rule1", "op1").WithCancel()
+	defer cancel()
+	srv := newObserveServer(t, "/sensors/temp")
+	var (
+		lock     sync.Mutex
+		statuses []string
+	)
+	s, ch := startSource(t, ctx, map[string]any{
+		"mode":       "observe",
+		"server":     "coap://" + srv.addr(),
+		"datasource": "/sensors/temp",
+	}, func(status string, _ string) {
+		lock.Lock()
+		defer lock.Unlock()
+		statuses = append(statuses, status)
+	})
+	r := expectReceived(t, ch)
+	require.Equal(t, `{"temperature":20}`, string(r.payload))
+	require.Equal(t, map[string]any{"path": "/sensors/temp", "observe": int64(0), "contentFormat": int64(50)}, r.meta)
+	for i, v := range []string{`{"temperature":21}`, `{"temperature":22}`} {
+		srv.notify(v, 0)
+		r = expectReceived(t, ch)
+		require.Equal(t, v, string(r.payload))
+		require.Equal(t, int64(i+1), r.meta["observe"])
+	}
+	lock.Lock()
+	require.Equal(t, []string{api.ConnectionConnected}, statuses)
+	lock.Unlock()
+
+	// the observer is deregistered after the source is closed
+	require.Equal(t, 1, srv.observerCount())
+	require.NoError(t, s.Close(ctx))
+	require.Eventually(t, func() bool {
+		return srv.observerCount() == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestObserveRegisterAgain(t *testing.T) {
+	old := observeRetryInterval
+	observeRetryInterval = 50 * time.Millisecond
+	defer func() {
+		observeRetryInterval = old
+	}()
+	ctx, cancel := mockContext.NewMockContext("rule1", "op1").WithCancel()
+	defer cancel()
+	srv := newObserveServer(t, "/sensors/temp")
+	props := map[string]any{
+		"mode":       "observe",
+		"server":     srv.addr(),
+		"datasource": "/sensors/temp",
+		"ackTimeout": "50ms",
+	}
+	s, ch := startSource(t, ctx, props, nil)
+	defer s.Close(ctx)
+	expectReceived(t, ch)
+	// register again once the max age expires without notification
+	srv.notify(`{"temperature":21}`, 1)
+	expectReceived(t, ch)
+	r := expectReceived(t, ch)
+	require.Equal(t, `{"temperature":21}`, string(r.payload))
+	require.Equal(t, 2, srv.requestCount())
+
+	// the failed observation reports the status and retries
+	statusCh := make(chan string, 10)
+	props["datasource"] = "/unknown"
+	s2, _ := startSource(t, ctx, props, func(status string, msg string) {
+		statusCh <- status + ":" + msg
+	})
+	defer s2.Close(ctx)
+	for i := 0; i < 2; i++ {
+		select {
+		case st := <-statusCh:
+			require.Equal(t, api.ConnectionDisconnected+":observe /unknown got response code 4.04", st)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "timeout")
+		}
+	}
+}
+
+func TestSinkRetransmit(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	srv := newObserveServer(t, "/sensors/temp")
+	props := map[string]any{
+		"server":     srv.addr(),
+		"path":       "/sensors/temp",
+		"ackTimeout": "50ms",
+	}
+	// the lost requests are retransmitted
+	srv.Lock()
+	srv.drop = 2
+	srv.Unlock()
+	require.NoError(t, send(t, ctx, props, `{"temperature":30}`))
+	require.Equal(t, 1, srv.requestCount())
+	require.Equal(t, `{"temperature":30}`, string(srv.lastRequest().payload))
+	cf, _ := srv.lastRequest().uintOption(optContentFormat)
+	require.Equal(t, uint32(50), cf)
+
+	// separate response
+	srv.Lock()
+	srv.separate = true
+	srv.Unlock()
+	require.NoError(t, send(t, ctx, props, `{"temperature":31}`))
+	require.Equal(t, 2, srv.requestCount())
+
+	// no response after all the retransmissions
+	props["server"] = freeUDPAddr(t)
+	props["maxRetransmit"] = 1
+	err := send(t, ctx, props, `{"temperature":32}`)
+	require.Error(t, err)
+	require.True(t, errorx.IsIOError(err))
+	require.Contains(t, err.Error(), "does not respond after 1 retransmissions")
+}
+
+func TestPing(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	srv := newObserveServer(t, "/sensors/temp")
+	require.NoError(t, GetSink().(*Sink).Ping(ctx, map[string]any{"server": srv.addr(), "path": "/a"}))
+	require.NoError(t, GetSource().(*Source).Ping(ctx, map[string]any{"mode": "observe", "server": srv.addr(), "datasource": "/a"}))
+	err := GetSink().(*Sink).Ping(ctx, map[string]any{"server": freeUDPAddr(t), "ackTimeout": "20ms", "maxRetransmit": 0})
+	require.Error(t, err)
+	// server mode does not ping
+	require.NoError(t, GetSource().(*Source).Ping(ctx, map[string]any{"datasource": "/a"}))
+}
+
+func TestProvisionErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	sourceTests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{name: "no path", props: map[string]any{}, err: "datasource  must be a path starting with /"},
+		{name: "invalid mode", props: map[string]any{"datasource": "/a", "mode": "client"}, err: "invalid mode client, must be server or observe"},
+		{name: "invalid method", props: map[string]any{"datasource": "/a", "method": "GET"}, err: "method GET is not supported, must be POST or PUT"},
+		{name: "query", props: map[string]any{"datasource": "/a?b=1"}, err: "datasource /a?b=1 must not contain the query in server mode"},
+		{name: "invalid buffer", props: map[string]any{"datasource": "/a", "bufferLength": 0}, err: "invalid bufferLength 0, must be positive"},
+		{name: "no server", props: map[string]any{"datasource": "/a", "mode": "observe"}, err: "server is required"},
+		{name: "invalid ack timeout", props: map[string]any{"datasource": "/a", "mode": "observe", "server": "127.0.0.1", "ackTimeout": "0s"}, err: "invalid ackTimeout 0s, must be positive"},
+		{name: "invalid max retransmit", props: map[string]any{"datasource": "/a", "mode": "observe", "server": "127.0.0.1", "maxRetransmit": 11}, err: "invalid maxRetransmit 11, must be in range 0-10"},
+	}
+	for _, tt := range sourceTests {
+		t.Run("source "+tt.name, func(t *testing.T) {
+			require.EqualError(t, GetSource().Provision(ctx, tt.props), tt.err)
+		})
+	}
+	sinkTests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{name: "no server", props: map[string]any{"path": "/a"}, err: "server is required"},
+		{name: "no path", props: map[string]any{"server": "127.0.0.1"}, err: "path is required"},
+		{name: "invalid method", props: map[string]any{"server": "127.0.0.1", "path": "/a", "method": "DELETE"}, err: "method DELETE is not supported, must be POST or PUT"},
+		{name: "invalid content format", props: map[string]any{"server": "127.0.0.1", "path": "/a", "contentFormat": 70000}, err: "invalid contentFormat 70000, must be in range 0-65535"},
+	}
+	for _, tt := range sinkTests {
+		t.Run("sink "+tt.name, func(t *testing.T) {
+			require.EqualError(t, GetSink().Provision(ctx, tt.props), tt.err)
+		})
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright 2023 EMQ Technologies Co., Ltd.
+// Copyright 2023-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -21,6 +21,7 @@ const (
 	CSV_TYPE     FileType = "csv"
 	LINES_TYPE   FileType = "lines"
 	PARQUET_TYPE FileType = "parquet"
+	AVRO_TYPE    FileType = "avro"
 )
 
 const (
@@ -33,6 +34,7 @@ var fileTypes = map[FileType]struct{}{
 	CSV_TYPE:     {},
 	LINES_TYPE:   {},
 	PARQUET_TYPE: {},
+	AVRO_TYPE:    {},
 }
 
 var compressionTypes = map[string]struct{}{
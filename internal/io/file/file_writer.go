@@ -1,4 +1,4 @@
-// Copyright 2023-2025 EMQ Technologies Co., Ltd.
+// Copyright 2023-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -16,6 +16,7 @@ package file
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -28,11 +29,12 @@ import (
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/io/file/writer"
 	"github.com/lf-edge/ekuiper/v2/modules/encryptor"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
 	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
 
 type fileWriter struct {
-	File       *os.File
+	File       StorageFile
 	Writer     io.Writer
 	Hook       writerHooks
 	Start      time.Time
@@ -48,24 +50,16 @@ func (m *fileSink) createFileWriter(ctx api.StreamContext, fn string, ft FileTyp
 	ctx.GetLogger().Infof("Create new file writer for %s", fn)
 	fws := &fileWriter{Start: timex.GetNow()}
 	var (
-		f   *os.File
+		f   StorageFile
 		err error
 	)
-	Dir := filepath.Dir(fn)
-	if _, err = os.Stat(Dir); os.IsNotExist(err) {
-		if err := os.Mkdir(Dir, 0o777); err != nil {
-			return nil, fmt.Errorf("fail to create file %s: %v", fn, err)
-		}
+	if m.storage != nil {
+		f, err = m.storage.Create(ctx, fn)
+	} else {
+		f, err = createLocalFile(fn)
 	}
-
-	if _, err = os.Stat(fn); os.IsNotExist(err) {
-		if _, err := os.Create(fn); err != nil {
-			return nil, fmt.Errorf("fail to create file %s: %v", fn, err)
-		}
-	}
-	f, err = os.OpenFile(fn, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, os.ModeAppend)
 	if err != nil {
-		return nil, fmt.Errorf("fail to open file sink for %s: %v", fn, err)
+		return nil, err
 	}
 	defer func() {
 		if ge != nil {
@@ -73,6 +67,7 @@ func (m *fileSink) createFileWriter(ctx api.StreamContext, fn string, ft FileTyp
 		}
 	}()
 	fws.File = f
+	var sw modules.FileStreamWriter
 	switch ft {
 	case JSON_TYPE:
 		fws.Hook = jsonHooks
@@ -80,6 +75,14 @@ func (m *fileSink) createFileWriter(ctx api.StreamContext, fn string, ft FileTyp
 		fws.Hook = &csvWriterHooks{header: []byte(headers)}
 	case LINES_TYPE:
 		fws.Hook = linesHooks
+	case PARQUET_TYPE, AVRO_TYPE:
+		fws.Hook = rowsHooks
+		sw, _ = modules.GetFileStreamWriter(ctx, string(ft))
+		if err := sw.Provision(ctx, m.props); err != nil {
+			return nil, err
+		}
+		// the compression is done by the stream writer inside the file layout
+		compressAlgorithm = ""
 	}
 
 	fws.fileBuffer = writer.NewBufioWrapWriter(bufio.NewWriter(f))
@@ -88,6 +91,12 @@ func (m *fileSink) createFileWriter(ctx api.StreamContext, fn string, ft FileTyp
 	if err != nil {
 		return nil, err
 	}
+	if sw != nil {
+		if err := sw.Bind(ctx, fws.Writer, m.schema); err != nil {
+			return nil, err
+		}
+		fws.Writer = &rowWriter{ctx: ctx, w: sw, out: fws.Writer}
+	}
 	header := fws.Hook.Header()
 	_, err = fws.Writer.Write(header)
 	if err != nil {
@@ -99,6 +108,26 @@ func (m *fileSink) createFileWriter(ctx api.StreamContext, fn string, ft FileTyp
 	return fws, nil
 }
 
+func createLocalFile(fn string) (*os.File, error) {
+	Dir := filepath.Dir(fn)
+	if _, err := os.Stat(Dir); os.IsNotExist(err) {
+		if err := os.Mkdir(Dir, 0o777); err != nil {
+			return nil, fmt.Errorf("fail to create file %s: %v", fn, err)
+		}
+	}
+
+	if _, err := os.Stat(fn); os.IsNotExist(err) {
+		if _, err := os.Create(fn); err != nil {
+			return nil, fmt.Errorf("fail to create file %s: %v", fn, err)
+		}
+	}
+	f, err := os.OpenFile(fn, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, os.ModeAppend)
+	if err != nil {
+		return nil, fmt.Errorf("fail to open file sink for %s: %v", fn, err)
+	}
+	return f, nil
+}
+
 func (m *fileSink) CreateWriter(_ api.StreamContext, currWriter io.Writer, compression string, encryption string) (io.Writer, error) {
 	var err error
 	if encryption != "" {
@@ -116,33 +145,36 @@ func (m *fileSink) CreateWriter(_ api.StreamContext, currWriter io.Writer, compr
 	return currWriter, nil
 }
 
+// Close writes the footer and closes the writers and the file. The errors are all returned so that the incomplete file
+// is not passed to the rolling hook.
 func (fw *fileWriter) Close(ctx api.StreamContext) error {
-	var err error
-	if fw.File != nil {
-		ctx.GetLogger().Debugf("File sync before close")
-		_, e := fw.Writer.Write(fw.Hook.Footer())
-		if e != nil {
-			ctx.GetLogger().Errorf("file sink fails to write footer with error %s.", e)
-		}
-
-		// Close the compressor and encryptor firstly
-		if w, ok := fw.Writer.(io.Closer); ok {
-			e := w.Close()
-			if e != nil {
-				ctx.GetLogger().Errorf("file sink fails to close compress/encrypt writer with error %s.", err)
-			}
-		}
-		err = fw.fileBuffer.Flush()
-		if err != nil {
-			ctx.GetLogger().Errorf("file sink fails to flush with error %s.", err)
-		}
-
-		err = fw.File.Sync()
-		if err != nil {
-			ctx.GetLogger().Errorf("file sink fails to sync with error %s.", err)
+	if fw.File == nil {
+		return nil
+	}
+	var errs []error
+	ctx.GetLogger().Debugf("File sync before close")
+	if _, e := fw.Writer.Write(fw.Hook.Footer()); e != nil {
+		ctx.GetLogger().Errorf("file sink fails to write footer with error %s.", e)
+		errs = append(errs, fmt.Errorf("fail to write footer: %v", e))
+	}
+	// Close the stream writer, compressor and encryptor firstly
+	if w, ok := fw.Writer.(io.Closer); ok {
+		if e := w.Close(); e != nil {
+			ctx.GetLogger().Errorf("file sink fails to close compress/encrypt writer with error %s.", e)
+			errs = append(errs, fmt.Errorf("fail to close writer: %v", e))
 		}
-		ctx.GetLogger().Infof("Close file %s", fw.File.Name())
-		return fw.File.Close()
 	}
-	return nil
+	if e := fw.fileBuffer.Flush(); e != nil {
+		ctx.GetLogger().Errorf("file sink fails to flush with error %s.", e)
+		errs = append(errs, fmt.Errorf("fail to flush: %v", e))
+	}
+	if e := fw.File.Sync(); e != nil {
+		ctx.GetLogger().Errorf("file sink fails to sync with error %s.", e)
+		errs = append(errs, fmt.Errorf("fail to sync: %v", e))
+	}
+	ctx.GetLogger().Infof("Close file %s", fw.File.Name())
+	if e := fw.File.Close(); e != nil {
+		errs = append(errs, e)
+	}
+	return errors.Join(errs...)
 }
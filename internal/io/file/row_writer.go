@@ -0,0 +1,88 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+)
+
+// rowWriter adapts the FileStreamWriter to the writer of the file sink. Unlike a normal writer, each Write must be
+// a whole JSON encoded item which is a row or a list of rows. Closing it finalizes the file layout and then closes
+// the underlying compress/encrypt writer if any.
+type rowWriter struct {
+	ctx api.StreamContext
+	w   modules.FileStreamWriter
+	out io.Writer
+}
+
+func (r *rowWriter) Write(p []byte) (int, error) {
+	// the separators of the hooks are empty
+	if len(p) == 0 {
+		return 0, nil
+	}
+	rows, err := decodeRows(p)
+	if err != nil {
+		return 0, err
+	}
+	for _, row := range rows {
+		if err := r.w.Write(r.ctx, row); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (r *rowWriter) Close() error {
+	err := r.w.Close(r.ctx)
+	if c, ok := r.out.(io.Closer); ok {
+		err = errors.Join(err, c.Close())
+	}
+	return err
+}
+
+// decodeRows decodes the JSON object or array. The numbers are kept as json.Number because a whole float like 2.0 is
+// encoded as 2, so they are only converted when the column type is known.
+func decodeRows(p []byte) ([]map[string]any, error) {
+	d := json.NewDecoder(bytes.NewReader(p))
+	d.UseNumber()
+	var v any
+	if err := d.Decode(&v); err != nil {
+		return nil, fmt.Errorf("fail to decode the rows, the format must be json: %v", err)
+	}
+	switch vt := v.(type) {
+	case map[string]any:
+		return []map[string]any{vt}, nil
+	case []any:
+		rows := make([]map[string]any, 0, len(vt))
+		for _, item := range vt {
+			m, ok := item.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("only map[string]any inside a list is supported but got: %v", item)
+			}
+			rows = append(rows, m)
+		}
+		return rows, nil
+	default:
+		return nil, fmt.Errorf("only map[string]any and []map[string]any is supported but got: %v", v)
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright 2021-2024 EMQ Technologies Co., Ltd.
+// Copyright 2021-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -19,13 +19,16 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"math"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/infra"
 	"github.com/lf-edge/ekuiper/v2/pkg/message"
@@ -58,7 +61,12 @@ type fileSink struct {
 	mux      sync.Mutex
 	fws      map[string]*fileWriter
 	rollHook modules.RollHook
-	headers  string
+	// storage creates the files, it is nil for the local file system
+	storage Storage
+	headers string
+	// props and schema are used to create the stream writer for the file types like parquet
+	props  map[string]any
+	schema *modules.FileSchema
 }
 
 func (m *fileSink) Provision(ctx api.StreamContext, props map[string]interface{}) error {
@@ -68,6 +76,9 @@ func (m *fileSink) Provision(ctx api.StreamContext, props map[string]interface{}
 		FileType:      LINES_TYPE,
 		CheckInterval: cast.DurationConf(5 * time.Minute),
 	}
+	if m.storage != nil {
+		c.RollingNamePattern = "suffix"
+	}
 	if err := cast.MapToStruct(props, c); err != nil {
 		return err
 	}
@@ -94,8 +105,22 @@ func (m *fileSink) Provision(ctx api.StreamContext, props map[string]interface{}
 	if c.Path == "" {
 		return fmt.Errorf("path must be set")
 	}
-	if c.FileType != JSON_TYPE && c.FileType != CSV_TYPE && c.FileType != LINES_TYPE {
-		return fmt.Errorf("fileType must be one of json, csv or lines")
+	switch c.FileType {
+	case JSON_TYPE, CSV_TYPE, LINES_TYPE:
+	case PARQUET_TYPE, AVRO_TYPE:
+		if c.Format != "" && c.Format != message.FormatJson {
+			return fmt.Errorf("format must be json when fileType is %s", c.FileType)
+		}
+		// validate the props of the stream writer which is created for each file
+		w, ok := modules.GetFileStreamWriter(ctx, string(c.FileType))
+		if !ok {
+			return fmt.Errorf("fileType %s is not supported in this build", c.FileType)
+		}
+		if err := w.Provision(ctx, props); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("fileType must be one of json, csv, lines, parquet or avro")
 	}
 	if c.FileType == CSV_TYPE {
 		if c.Format != message.FormatDelimited {
@@ -121,11 +146,50 @@ func (m *fileSink) Provision(ctx api.StreamContext, props map[string]interface{}
 		}
 		m.rollHook = h
 	}
+	if m.storage != nil {
+		if err := m.storage.Provision(ctx, props); err != nil {
+			return err
+		}
+	}
 	m.c = c
+	m.props = props
+	m.schema = &modules.FileSchema{}
 	m.fws = make(map[string]*fileWriter)
 	return nil
 }
 
+// SetSchema sets the columns of the stream writer by the rule output schema. The columns are ordered by the field
+// index and typed by the field type. If the fields property is set, use it instead.
+func (m *fileSink) SetSchema(schema map[string]*ast.JsonStreamField) {
+	var names []string
+	if len(m.c.Fields) > 0 {
+		names = m.c.Fields
+	} else {
+		names = make([]string, 0, len(schema))
+		for k := range schema {
+			names = append(names, k)
+		}
+		index := func(k string) int {
+			if f := schema[k]; f != nil && f.HasIndex {
+				return f.Index
+			}
+			return math.MaxInt
+		}
+		sort.Strings(names)
+		sort.SliceStable(names, func(i, j int) bool {
+			return index(names[i]) < index(names[j])
+		})
+	}
+	columns := make([]modules.FileColumn, len(names))
+	for i, n := range names {
+		columns[i] = modules.FileColumn{Name: n}
+		if f := schema[n]; f != nil {
+			columns[i].Type = f.Type
+		}
+	}
+	m.schema = &modules.FileSchema{Columns: columns}
+}
+
 func (m *fileSink) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
 	ctx.GetLogger().Debug("Opening file sink")
 	// Check if the files have opened longer than the rolling interval, if so close it and create a new one
@@ -229,6 +293,12 @@ func (m *fileSink) Close(ctx api.StreamContext) error {
 			errs = append(errs, e)
 		}
 	}
+	if m.storage != nil {
+		e := m.storage.Close(ctx)
+		if e != nil {
+			errs = append(errs, e)
+		}
+	}
 	return errors.Join(errs...)
 }
 
@@ -236,6 +306,9 @@ func (m *fileSink) roll(ctx api.StreamContext, k string, v *fileWriter) error {
 	ctx.GetLogger().Infof("rolling file %s", k)
 	err := v.Close(ctx)
 	if err != nil {
+		// the file cannot be written anymore, the next item will be written to a new file
+		delete(m.fws, k)
+		v.Written = false
 		return err
 	}
 	if m.rollHook != nil {
@@ -307,6 +380,7 @@ func GetSink() api.Sink {
 }
 
 var (
-	_ api.BytesCollector = &fileSink{}
-	_ model.StreamWriter = &fileSink{}
+	_ api.BytesCollector     = &fileSink{}
+	_ model.StreamWriter     = &fileSink{}
+	_ modules.SchemaConsumer = &fileSink{}
 )
@@ -0,0 +1,193 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hamba/avro/v2/ocf"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/io/file/writer"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+)
+
+// readRollHook reads the file when it is rolled to make sure the file is complete
+type readRollHook struct {
+	read   func(filePath string) ([]map[string]any, error)
+	result [][]map[string]any
+}
+
+func (h *readRollHook) Provision(_ api.StreamContext, _ map[string]any) error {
+	return nil
+}
+
+func (h *readRollHook) RollDone(_ api.StreamContext, filePath string) error {
+	rows, err := h.read(filePath)
+	if err != nil {
+		return err
+	}
+	h.result = append(h.result, rows)
+	return nil
+}
+
+func (h *readRollHook) Close(_ api.StreamContext) error {
+	return nil
+}
+
+func readAvroFile(filePath string) ([]map[string]any, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	dec, err := ocf.NewDecoder(f)
+	if err != nil {
+		return nil, err
+	}
+	var rows []map[string]any
+	for dec.HasNext() {
+		var m map[string]any
+		if err := dec.Decode(&m); err != nil {
+			return nil, err
+		}
+		rows = append(rows, m)
+	}
+	return rows, dec.Error()
+}
+
+func TestAvroSinkRolling(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule", "testAvro")
+	hook := &readRollHook{read: readAvroFile}
+	modules.RegisterFileRollHook("read", func() modules.RollHook {
+		return hook
+	})
+	dir := t.TempDir()
+	sink := &fileSink{}
+	err := sink.Provision(ctx, map[string]any{
+		"path":               filepath.Join(dir, "test.avro"),
+		"fileType":           AVRO_TYPE,
+		"format":             "json",
+		"rollingCount":       3,
+		"rollingNamePattern": "none",
+		"rollingHook":        "read",
+		"rowGroupSize":       2,
+		"compression":        "zstd",
+	})
+	require.NoError(t, err)
+	sink.SetSchema(map[string]*ast.JsonStreamField{
+		"name": {HasIndex: true, Index: 1},
+		"id":   {Type: "bigint", HasIndex: true, Index: 0},
+	})
+	require.Equal(t, []modules.FileColumn{{Name: "id", Type: "bigint"}, {Name: "name"}}, sink.schema.Columns)
+	require.NoError(t, sink.Connect(ctx, func(status string, message string) {}))
+	items := []string{
+		`{"id":1,"name":"a","temperature":20.5}`,
+		`{"id":2,"name":null,"temperature":21}`,
+		`[{"id":3,"name":"c","temperature":22}]`,
+		`{"id":4,"name":"d","temperature":23.5,"tags":["x"]}`,
+		`{"id":5,"name":"e","temperature":24}`,
+	}
+	for _, item := range items {
+		require.NoError(t, sink.Collect(ctx, &xsql.RawTuple{Rawdata: []byte(item)}))
+	}
+	// the column types are fixed by the first row group
+	err = sink.Collect(ctx, &xsql.RawTuple{Rawdata: []byte(`{"id":"abc"}`)})
+	require.EqualError(t, err, "column id: cannot convert string(abc) to int64")
+	require.NoError(t, sink.Close(ctx))
+	require.Equal(t, [][]map[string]any{
+		{
+			{"id": int64(1), "name": "a", "temperature": 20.5},
+			{"id": int64(2), "name": nil, "temperature": float64(21)},
+			{"id": int64(3), "name": "c", "temperature": float64(22)},
+		},
+		{
+			// the columns are fixed by the first file, so the new key is not saved
+			{"id": int64(4), "name": "d", "temperature": 23.5},
+			{"id": int64(5), "name": "e", "temperature": float64(24)},
+		},
+	}, hook.result)
+}
+
+// closeErrWriter fails to write the footer when closing
+type closeErrWriter struct {
+	*writer.AvroWriter
+}
+
+func (w *closeErrWriter) Close(_ api.StreamContext) error {
+	return errors.New("footer failed")
+}
+
+func TestAvroSinkCloseErr(t *testing.T) {
+	modules.RegisterFileStreamWriter("avro", func(ctx api.StreamContext) modules.FileStreamWriter {
+		return &closeErrWriter{AvroWriter: &writer.AvroWriter{}}
+	})
+	defer modules.RegisterFileStreamWriter("avro", func(ctx api.StreamContext) modules.FileStreamWriter {
+		return &writer.AvroWriter{}
+	})
+	ctx := mockContext.NewMockContext("rule", "testAvroCloseErr")
+	hook := &readRollHook{read: readAvroFile}
+	modules.RegisterFileRollHook("readCloseErr", func() modules.RollHook {
+		return hook
+	})
+	sink := &fileSink{}
+	err := sink.Provision(ctx, map[string]any{
+		"path":               filepath.Join(t.TempDir(), "test.avro"),
+		"fileType":           AVRO_TYPE,
+		"format":             "json",
+		"rollingCount":       1,
+		"rollingNamePattern": "none",
+		"rollingHook":        "readCloseErr",
+	})
+	require.NoError(t, err)
+	require.NoError(t, sink.Connect(ctx, func(status string, message string) {}))
+	err = sink.Collect(ctx, &xsql.RawTuple{Rawdata: []byte(`{"id":1,"name":"a"}`)})
+	require.EqualError(t, err, "fail to close writer: footer failed")
+	// the incomplete file is not passed to the rolling hook
+	require.Empty(t, hook.result)
+	require.NoError(t, sink.Close(ctx))
+}
+
+func TestColumnarProvisionErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule", "testAvro")
+	tests := []struct {
+		props map[string]any
+		err   string
+	}{
+		{
+			props: map[string]any{"fileType": "avro", "format": "delimited"},
+			err:   "format must be json when fileType is avro",
+		},
+		{
+			props: map[string]any{"fileType": "avro", "rowGroupSize": -1},
+			err:   "rowGroupSize must be positive",
+		},
+		{
+			props: map[string]any{"fileType": "orc"},
+			err:   "fileType must be one of json, csv, lines, parquet or avro",
+		},
+	}
+	for _, tt := range tests {
+		sink := &fileSink{}
+		require.EqualError(t, sink.Provision(ctx, tt.props), tt.err)
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build parquet || full
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+)
+
+func readParquetFile(filePath string) ([]map[string]any, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	pf, err := parquet.OpenFile(f, info.Size())
+	if err != nil {
+		return nil, err
+	}
+	var result []map[string]any
+	for _, group := range pf.RowGroups() {
+		rows := make([]parquet.Row, group.NumRows())
+		r := group.Rows()
+		n, _ := r.ReadRows(rows)
+		_ = r.Close()
+		for _, row := range rows[:n] {
+			m := make(map[string]any)
+			if err := pf.Schema().Reconstruct(&m, row); err != nil {
+				return nil, err
+			}
+			result = append(result, m)
+		}
+	}
+	return result, nil
+}
+
+func TestParquetSinkRolling(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule", "testParquet")
+	hook := &readRollHook{read: readParquetFile}
+	modules.RegisterFileRollHook("readParquet", func() modules.RollHook {
+		return hook
+	})
+	dir := t.TempDir()
+	sink := &fileSink{}
+	err := sink.Provision(ctx, map[string]any{
+		"path":               filepath.Join(dir, "test.parquet"),
+		"fileType":           PARQUET_TYPE,
+		"rollingCount":       3,
+		"rollingNamePattern": "none",
+		"rollingHook":        "readParquet",
+		"rowGroupSize":       2,
+		"compression":        "gzip",
+		"fields":             []string{"id", "name"},
+	})
+	require.NoError(t, err)
+	sink.SetSchema(nil)
+	require.Equal(t, []modules.FileColumn{{Name: "id"}, {Name: "name"}}, sink.schema.Columns)
+	require.NoError(t, sink.Connect(ctx, func(status string, message string) {}))
+	items := []string{
+		`{"id":1,"name":"a","ok":true}`,
+		`{"id":2,"name":"b","ok":false}`,
+		`{"id":3,"name":"c"}`,
+		`{"id":4,"name":"d"}`,
+	}
+	for _, item := range items {
+		require.NoError(t, sink.Collect(ctx, &xsql.RawTuple{Rawdata: []byte(item)}))
+	}
+	require.NoError(t, sink.Close(ctx))
+	require.Len(t, hook.result, 2)
+	require.Len(t, hook.result[0], 3)
+	require.Equal(t, float64(3), hook.result[0][2]["id"])
+	require.Equal(t, "c", hook.result[0][2]["name"])
+	require.Equal(t, true, hook.result[0][0]["ok"])
+	require.Len(t, hook.result[1], 1)
+	require.Equal(t, float64(4), hook.result[1][0]["id"])
+}
+
+// TestParquetSinkSchemaTypes verifies the column types are decided by the rule output schema instead of the values
+// of the first row group, and the columns are kept the same for all rolled files.
+func TestParquetSinkSchemaTypes(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule", "testParquetTypes")
+	hook := &readRollHook{read: readParquetFile}
+	modules.RegisterFileRollHook("readParquetTypes", func() modules.RollHook {
+		return hook
+	})
+	dir := t.TempDir()
+	sink := &fileSink{}
+	require.NoError(t, sink.Provision(ctx, map[string]any{
+		"path":               filepath.Join(dir, "test.parquet"),
+		"fileType":           PARQUET_TYPE,
+		"rollingCount":       3,
+		"rollingNamePattern": "none",
+		"rollingHook":        "readParquetTypes",
+		"rowGroupSize":       2,
+	}))
+	sink.SetSchema(map[string]*ast.JsonStreamField{
+		"id":   {Type: "bigint", HasIndex: true, Index: 0},
+		"temp": {Type: "float", HasIndex: true, Index: 1},
+		"tag":  {HasIndex: true, Index: 2},
+	})
+	require.Equal(t, []modules.FileColumn{{Name: "id", Type: "bigint"}, {Name: "temp", Type: "float"}, {Name: "tag"}}, sink.schema.Columns)
+	require.NoError(t, sink.Connect(ctx, func(status string, message string) {}))
+	items := []string{
+		// the first row group only has whole numbers
+		`{"id":1,"temp":25,"tag":"a","extra":1}`,
+		`{"id":2,"temp":26,"tag":"b"}`,
+		`{"id":3,"temp":25.5}`,
+		// the next file has no tag and extra values but keeps the columns
+		`{"id":4,"temp":27.5}`,
+	}
+	for _, item := range items {
+		require.NoError(t, sink.Collect(ctx, &xsql.RawTuple{Rawdata: []byte(item)}))
+	}
+	require.NoError(t, sink.Close(ctx))
+	require.Equal(t, [][]map[string]any{
+		{
+			{"id": int64(1), "temp": float64(25), "tag": "a", "extra": float64(1)},
+			{"id": int64(2), "temp": float64(26), "tag": "b", "extra": nil},
+			{"id": int64(3), "temp": 25.5, "tag": nil, "extra": nil},
+		},
+		{
+			{"id": int64(4), "temp": 27.5, "tag": nil, "extra": nil},
+		},
+	}, hook.result)
+	require.Equal(t, &modules.FileSchema{
+		Columns: []modules.FileColumn{{Name: "id", Type: "bigint"}, {Name: "temp", Type: "float"}, {Name: "tag", Type: "string"}, {Name: "extra", Type: "float"}},
+		Fixed:   true,
+	}, sink.schema)
+}
+
+// TestParquetSinkWholeFloats verifies the numbers without a type are saved as float, so the later files accept the
+// fractional values even if the first file only has whole numbers.
+func TestParquetSinkWholeFloats(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule", "testParquetFloats")
+	hook := &readRollHook{read: readParquetFile}
+	modules.RegisterFileRollHook("readParquetFloats", func() modules.RollHook {
+		return hook
+	})
+	dir := t.TempDir()
+	sink := &fileSink{}
+	require.NoError(t, sink.Provision(ctx, map[string]any{
+		"path":               filepath.Join(dir, "test.parquet"),
+		"fileType":           PARQUET_TYPE,
+		"rollingCount":       2,
+		"rollingNamePattern": "none",
+		"rollingHook":        "readParquetFloats",
+	}))
+	sink.SetSchema(nil)
+	require.NoError(t, sink.Connect(ctx, func(status string, message string) {}))
+	// the float 2.0 is encoded as 2 in JSON
+	items := []string{
+		`{"temp":2}`,
+		`{"temp":3}`,
+		`{"temp":2.5}`,
+		`{"temp":4}`,
+	}
+	for _, item := range items {
+		require.NoError(t, sink.Collect(ctx, &xsql.RawTuple{Rawdata: []byte(item)}))
+	}
+	require.NoError(t, sink.Close(ctx))
+	require.Equal(t, [][]map[string]any{
+		{{"temp": float64(2)}, {"temp": float64(3)}},
+		{{"temp": 2.5}, {"temp": float64(4)}},
+	}, hook.result)
+	require.Equal(t, []modules.FileColumn{{Name: "temp", Type: "float"}}, sink.schema.Columns)
+}
@@ -0,0 +1,44 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"io"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+)
+
+// Storage is where the file sink creates the files instead of the local file system, such as the object storages.
+// The rolling, formatting, compression and encryption of the files are all done by the file sink.
+type Storage interface {
+	// Provision validates the storage specific props
+	Provision(ctx api.StreamContext, props map[string]any) error
+	// Create creates the file of the name to write sequentially
+	Create(ctx api.StreamContext, name string) (StorageFile, error)
+	api.Closable
+}
+
+// StorageFile is the file written by the file sink. The file is finished once it is closed.
+type StorageFile interface {
+	io.WriteCloser
+	Name() string
+	Sync() error
+}
+
+// NewSink creates the file sink which writes the files to the storage. The rolled files are named with the timestamp
+// suffix by default to archive each of them as a separate object.
+func NewSink(storage Storage) api.Sink {
+	return &fileSink{storage: storage}
+}
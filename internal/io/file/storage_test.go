@@ -0,0 +1,128 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+type memStorage struct {
+	files    map[string]string
+	failNext bool
+	closed   bool
+}
+
+func (s *memStorage) Provision(_ api.StreamContext, props map[string]any) error {
+	if props["bucket"] == nil {
+		return errors.New("bucket is required")
+	}
+	s.files = map[string]string{}
+	return nil
+}
+
+func (s *memStorage) Create(_ api.StreamContext, name string) (StorageFile, error) {
+	return &memFile{s: s, name: name}, nil
+}
+
+func (s *memStorage) Close(_ api.StreamContext) error {
+	s.closed = true
+	return nil
+}
+
+type memFile struct {
+	s    *memStorage
+	name string
+	buf  bytes.Buffer
+}
+
+func (f *memFile) Write(p []byte) (int, error) {
+	return f.buf.Write(p)
+}
+
+func (f *memFile) Name() string {
+	return f.name
+}
+
+func (f *memFile) Sync() error {
+	return nil
+}
+
+func (f *memFile) Close() error {
+	if f.s.failNext {
+		f.s.failNext = false
+		return errors.New("upload failed")
+	}
+	f.s.files[f.name] = f.buf.String()
+	return nil
+}
+
+func TestStorageSink(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	storage := &memStorage{}
+	sink := NewSink(storage)
+	require.Error(t, sink.Provision(ctx, map[string]any{"path": "data/test.json"}))
+	require.NoError(t, sink.Provision(ctx, map[string]any{"path": "data/test.json", "bucket": "b", "rollingCount": 2}))
+	require.NoError(t, sink.Connect(ctx, func(string, string) {}))
+	collect := func(data string) error {
+		return sink.(api.BytesCollector).Collect(ctx, &xsql.RawTuple{Rawdata: []byte(data)})
+	}
+	timex.Set(1000)
+	require.NoError(t, collect("a"))
+	require.NoError(t, collect("b"))
+	// the file is rolled with the timestamp suffix by default
+	require.Equal(t, []string{"a\nb"}, storageFiles(t, storage))
+
+	// the failed file is dropped and the next data is written to a new file
+	timex.Set(2000)
+	storage.failNext = true
+	require.NoError(t, collect("c"))
+	require.Error(t, collect("d"))
+	timex.Set(3000)
+	require.NoError(t, collect("e"))
+	require.NoError(t, sink.Close(ctx))
+	require.Equal(t, []string{"a\nb", "e"}, storageFiles(t, storage))
+	require.True(t, storage.closed)
+}
+
+// storageFiles returns the file contents ordered by the name. The names must have the timestamp suffix.
+func storageFiles(t *testing.T, s *memStorage) []string {
+	names := make([]string, 0, len(s.files))
+	for name := range s.files {
+		require.Regexp(t, `^data/test-\d+\.json$`, name)
+		names = append(names, name)
+	}
+	// the longer timestamp is later
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) < len(names[j])
+		}
+		return names[i] < names[j]
+	})
+	result := make([]string, len(names))
+	for i, name := range names {
+		result[i] = s.files[name]
+	}
+	return result
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"errors"
+	"io"
+
+	"github.com/hamba/avro/v2"
+	"github.com/hamba/avro/v2/ocf"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+)
+
+func init() {
+	modules.RegisterFileStreamWriter("avro", func(ctx api.StreamContext) modules.FileStreamWriter {
+		return &AvroWriter{}
+	})
+}
+
+// AvroWriter writes the Avro object container file. Each buffered group of rows is written as a block.
+// All fields are nullable.
+type AvroWriter struct {
+	c      *columnarConf
+	out    io.Writer
+	buffer *rowBuffer
+	enc    *ocf.Encoder
+}
+
+func (aw *AvroWriter) Provision(_ api.StreamContext, props map[string]any) (err error) {
+	aw.c, err = parseColumnarConf(props)
+	return err
+}
+
+func (aw *AvroWriter) Bind(_ api.StreamContext, fileStream io.Writer, schema *modules.FileSchema) error {
+	aw.out = fileStream
+	aw.buffer = newRowBuffer(schema, aw.c.RowGroupSize)
+	return nil
+}
+
+func (aw *AvroWriter) Write(_ api.StreamContext, row map[string]any) error {
+	full, err := aw.buffer.add(row)
+	if err != nil {
+		return err
+	}
+	if full {
+		return aw.buffer.flush(aw.writeBlock)
+	}
+	return nil
+}
+
+func (aw *AvroWriter) writeBlock(columns []*column, rows []map[string]any) error {
+	if aw.enc == nil {
+		s, err := aw.schema(columns)
+		if err != nil {
+			return err
+		}
+		opts := []ocf.EncoderFunc{ocf.WithBlockLength(aw.c.RowGroupSize)}
+		switch aw.c.Compression {
+		case gzipCompression:
+			opts = append(opts, ocf.WithCodec(ocf.Deflate))
+		case zstdCompression:
+			opts = append(opts, ocf.WithCodec(ocf.ZStandard))
+		}
+		aw.enc, err = ocf.NewEncoderWithSchema(s, aw.out, opts...)
+		if err != nil {
+			return err
+		}
+	}
+	for _, row := range rows {
+		if err := aw.enc.Encode(row); err != nil {
+			return err
+		}
+	}
+	return aw.enc.Flush()
+}
+
+func (aw *AvroWriter) schema(columns []*column) (avro.Schema, error) {
+	fields := make([]*avro.Field, len(columns))
+	for i, c := range columns {
+		var t avro.Type
+		switch c.t {
+		case int64Column:
+			t = avro.Long
+		case float64Column:
+			t = avro.Double
+		case boolColumn:
+			t = avro.Boolean
+		default:
+			t = avro.String
+		}
+		u, err := avro.NewUnionSchema([]avro.Schema{avro.NewNullSchema(), avro.NewPrimitiveSchema(t, nil)})
+		if err != nil {
+			return nil, err
+		}
+		f, err := avro.NewField(c.name, u, avro.WithDefault(nil))
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = f
+	}
+	return avro.NewRecordSchema("row", "", fields)
+}
+
+// Close writes the remaining rows. A file without any row is left empty.
+func (aw *AvroWriter) Close(_ api.StreamContext) error {
+	err := aw.buffer.flush(aw.writeBlock)
+	if aw.enc != nil {
+		err = errors.Join(err, aw.enc.Close())
+	}
+	return err
+}
+
+var _ modules.FileStreamWriter = &AvroWriter{}
@@ -0,0 +1,282 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+)
+
+const (
+	defaultRowGroupSize = 10000
+	gzipCompression     = "gzip"
+	zstdCompression     = "zstd"
+)
+
+type columnType int
+
+const (
+	unknownColumn columnType = iota
+	int64Column
+	float64Column
+	boolColumn
+	// stringColumn also holds the nested values as JSON string
+	stringColumn
+)
+
+type column struct {
+	name string
+	t    columnType
+}
+
+type columnarConf struct {
+	RowGroupSize int    `json:"rowGroupSize"`
+	Compression  string `json:"compression"`
+}
+
+func parseColumnarConf(props map[string]any) (*columnarConf, error) {
+	c := &columnarConf{RowGroupSize: defaultRowGroupSize}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return nil, err
+	}
+	if c.RowGroupSize <= 0 {
+		return nil, fmt.Errorf("rowGroupSize must be positive")
+	}
+	switch c.Compression {
+	case "", gzipCompression, zstdCompression:
+	default:
+		return nil, fmt.Errorf("compression must be one of gzip, zstd")
+	}
+	return c, nil
+}
+
+// rowBuffer buffers the rows of a row group in memory. The columns are fixed by the first row group of the sink and
+// saved in the shared schema, so the following files use the same columns.
+type rowBuffer struct {
+	schema  *modules.FileSchema
+	columns []*column
+	rows    []map[string]any
+	size    int
+}
+
+func newRowBuffer(schema *modules.FileSchema, size int) *rowBuffer {
+	if schema == nil {
+		schema = &modules.FileSchema{}
+	}
+	b := &rowBuffer{schema: schema, size: size, rows: make([]map[string]any, 0, size)}
+	if schema.Fixed {
+		b.columns = make([]*column, len(schema.Columns))
+		for i, c := range schema.Columns {
+			b.columns[i] = &column{name: c.Name, t: fieldColumnType(c.Type)}
+		}
+	}
+	return b
+}
+
+// add buffers the row and returns true if the row group is full. After the columns are fixed, the row is converted
+// immediately so that only the invalid row is rejected.
+func (b *rowBuffer) add(row map[string]any) (bool, error) {
+	if b.columns != nil {
+		r, err := b.convert(row)
+		if err != nil {
+			return false, err
+		}
+		row = r
+	}
+	b.rows = append(b.rows, row)
+	return len(b.rows) >= b.size, nil
+}
+
+// flush writes the buffered rows by the write function. The columns are resolved if this is the first row group.
+func (b *rowBuffer) flush(write func(columns []*column, rows []map[string]any) error) error {
+	if len(b.rows) == 0 {
+		return nil
+	}
+	if b.columns == nil {
+		b.columns = inferColumns(b.schema.Columns, b.rows)
+		b.schema.Columns = make([]modules.FileColumn, len(b.columns))
+		for i, c := range b.columns {
+			b.schema.Columns[i] = modules.FileColumn{Name: c.name, Type: c.t.fieldType()}
+		}
+		b.schema.Fixed = true
+		for i, row := range b.rows {
+			r, err := b.convert(row)
+			if err != nil {
+				return err
+			}
+			b.rows[i] = r
+		}
+	}
+	err := write(b.columns, b.rows)
+	b.rows = make([]map[string]any, 0, b.size)
+	return err
+}
+
+func (b *rowBuffer) convert(row map[string]any) (map[string]any, error) {
+	r := make(map[string]any, len(b.columns))
+	for _, c := range b.columns {
+		v, err := c.convert(row[c.name])
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %v", c.name, err)
+		}
+		r[c.name] = v
+	}
+	return r, nil
+}
+
+// inferColumns uses the declared columns in order and appends the other keys of the rows in alphabetical order, which
+// are selected by wildcard. The type of each column is the declared type, or inferred by its values if not declared.
+func inferColumns(declared []modules.FileColumn, rows []map[string]any) []*column {
+	columns := make([]*column, 0, len(declared))
+	existed := make(map[string]struct{}, len(declared))
+	for _, c := range declared {
+		columns = append(columns, &column{name: c.Name, t: fieldColumnType(c.Type)})
+		existed[c.Name] = struct{}{}
+	}
+	var others []string
+	for _, row := range rows {
+		for k := range row {
+			if _, ok := existed[k]; !ok {
+				others = append(others, k)
+				existed[k] = struct{}{}
+			}
+		}
+	}
+	sort.Strings(others)
+	for _, n := range others {
+		columns = append(columns, &column{name: n})
+	}
+	for _, c := range columns {
+		if c.t != unknownColumn {
+			continue
+		}
+		for _, row := range rows {
+			c.t = mergeColumnType(c.t, valueType(row[c.name]))
+		}
+		// all values are null, just save as string
+		if c.t == unknownColumn {
+			c.t = stringColumn
+		}
+	}
+	return columns
+}
+
+// fieldColumnType maps the stream field type to the column type. The other types like datetime are saved as string.
+func fieldColumnType(t string) columnType {
+	switch t {
+	case "":
+		return unknownColumn
+	case "bigint":
+		return int64Column
+	case "float":
+		return float64Column
+	case "boolean":
+		return boolColumn
+	default:
+		return stringColumn
+	}
+}
+
+func (t columnType) fieldType() string {
+	switch t {
+	case int64Column:
+		return "bigint"
+	case float64Column:
+		return "float"
+	case boolColumn:
+		return "boolean"
+	default:
+		return "string"
+	}
+}
+
+func valueType(v any) columnType {
+	switch v.(type) {
+	case nil:
+		return unknownColumn
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return int64Column
+	// The JSON numbers without a declared type are always float since the whole floats cannot be distinguished
+	case float32, float64, json.Number:
+		return float64Column
+	case bool:
+		return boolColumn
+	default:
+		return stringColumn
+	}
+}
+
+func mergeColumnType(t1, t2 columnType) columnType {
+	switch {
+	case t1 == unknownColumn:
+		return t2
+	case t2 == unknownColumn || t1 == t2:
+		return t1
+	case (t1 == int64Column && t2 == float64Column) || (t1 == float64Column && t2 == int64Column):
+		return float64Column
+	default:
+		return stringColumn
+	}
+}
+
+func (c *column) convert(v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if n, ok := v.(json.Number); ok {
+		return c.convertNumber(n)
+	}
+	switch c.t {
+	case int64Column:
+		return cast.ToInt64(v, cast.STRICT)
+	case float64Column:
+		return cast.ToFloat64(v, cast.CONVERT_SAMEKIND)
+	case boolColumn:
+		return cast.ToBool(v, cast.STRICT)
+	default:
+		switch vt := v.(type) {
+		case map[string]any, []any, []map[string]any:
+			b, err := json.Marshal(vt)
+			if err != nil {
+				return nil, err
+			}
+			return string(b), nil
+		default:
+			return cast.ToString(v, cast.CONVERT_ALL)
+		}
+	}
+}
+
+// convertNumber narrows the JSON number to the column type
+func (c *column) convertNumber(n json.Number) (any, error) {
+	switch c.t {
+	case int64Column:
+		i, err := n.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert number(%s) to int64", n)
+		}
+		return i, nil
+	case float64Column:
+		return n.Float64()
+	case boolColumn:
+		return nil, fmt.Errorf("cannot convert number(%s) to bool", n)
+	default:
+		return n.String(), nil
+	}
+}
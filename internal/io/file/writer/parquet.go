@@ -0,0 +1,116 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build parquet || full
+
+package writer
+
+import (
+	"errors"
+	"io"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress/gzip"
+	"github.com/parquet-go/parquet-go/compress/zstd"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+)
+
+func init() {
+	modules.RegisterFileStreamWriter("parquet", func(ctx api.StreamContext) modules.FileStreamWriter {
+		return &ParquetWriter{}
+	})
+}
+
+// ParquetWriter writes a row group for each buffered group of rows. All columns are optional.
+type ParquetWriter struct {
+	c      *columnarConf
+	out    io.Writer
+	buffer *rowBuffer
+	w      *parquet.GenericWriter[map[string]any]
+}
+
+func (pw *ParquetWriter) Provision(_ api.StreamContext, props map[string]any) (err error) {
+	pw.c, err = parseColumnarConf(props)
+	return err
+}
+
+func (pw *ParquetWriter) Bind(_ api.StreamContext, fileStream io.Writer, schema *modules.FileSchema) error {
+	pw.out = fileStream
+	pw.buffer = newRowBuffer(schema, pw.c.RowGroupSize)
+	return nil
+}
+
+func (pw *ParquetWriter) Write(_ api.StreamContext, row map[string]any) error {
+	full, err := pw.buffer.add(row)
+	if err != nil {
+		return err
+	}
+	if full {
+		return pw.buffer.flush(pw.writeRowGroup)
+	}
+	return nil
+}
+
+func (pw *ParquetWriter) writeRowGroup(columns []*column, rows []map[string]any) error {
+	if pw.w == nil {
+		pw.w = parquet.NewGenericWriter[map[string]any](pw.out, append(pw.options(), pw.schema(columns))...)
+	}
+	if _, err := pw.w.Write(rows); err != nil {
+		return err
+	}
+	return pw.w.Flush()
+}
+
+func (pw *ParquetWriter) schema(columns []*column) *parquet.Schema {
+	g := make(parquet.Group, len(columns))
+	for _, c := range columns {
+		var n parquet.Node
+		switch c.t {
+		case int64Column:
+			n = parquet.Int(64)
+		case float64Column:
+			n = parquet.Leaf(parquet.DoubleType)
+		case boolColumn:
+			n = parquet.Leaf(parquet.BooleanType)
+		default:
+			n = parquet.String()
+		}
+		g[c.name] = parquet.Optional(n)
+	}
+	return parquet.NewSchema("row", g)
+}
+
+func (pw *ParquetWriter) options() []parquet.WriterOption {
+	switch pw.c.Compression {
+	case gzipCompression:
+		return []parquet.WriterOption{parquet.Compression(&gzip.Codec{})}
+	case zstdCompression:
+		return []parquet.WriterOption{parquet.Compression(&zstd.Codec{})}
+	default:
+		return nil
+	}
+}
+
+// Close writes the remaining rows and the footer. A file without any row is left empty.
+func (pw *ParquetWriter) Close(_ api.StreamContext) error {
+	err := pw.buffer.flush(pw.writeRowGroup)
+	if pw.w != nil {
+		err = errors.Join(err, pw.w.Close())
+	}
+	return err
+}
+
+var _ modules.FileStreamWriter = &ParquetWriter{}
@@ -1,4 +1,4 @@
-// Copyright 2023 EMQ Technologies Co., Ltd.
+// Copyright 2023-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -76,3 +76,20 @@ func (c *csvWriterHooks) Footer() []byte {
 func (c *csvWriterHooks) SetHeader(header string) {
 	c.header = []byte(header)
 }
+
+// rowsWriterHooks has no separators because the rows are written by the FileStreamWriter in its own layout
+type rowsWriterHooks struct{}
+
+func (r *rowsWriterHooks) Header() []byte {
+	return nil
+}
+
+func (r *rowsWriterHooks) Line() []byte {
+	return nil
+}
+
+func (r *rowsWriterHooks) Footer() []byte {
+	return nil
+}
+
+var rowsHooks = &rowsWriterHooks{}
@@ -1,4 +1,4 @@
-// Copyright 2023-2025 EMQ Technologies Co., Ltd.
+// Copyright 2023-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -30,6 +30,7 @@ import (
 
 	"github.com/lf-edge/ekuiper/v2/internal/compressor"
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/converter/xml"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/httpx"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/cert"
@@ -44,6 +45,7 @@ type ClientConf struct {
 	config       *RawConf
 	client       *http.Client
 	decompressor message.Decompressor // decompressor used to payload decompression when specifies compressAlgorithm
+	decoder      message.Converter    // decoder used to decode the response body when the format is not json
 
 	// auth related, all handled inside client sync
 	// In Conn, try to auth
@@ -210,6 +212,12 @@ func (cc *ClientConf) InitConf(ctx api.StreamContext, device string, props map[s
 		Timeout:   time.Duration(c.Timeout),
 	}
 	cc.config = c
+	if strings.EqualFold(c.Format, message.FormatXML) {
+		cc.decoder, err = xml.NewConverter(nil, props)
+		if err != nil {
+			return err
+		}
+	}
 	// that means payload need compression and decompression, so we need initialize compressor and decompressor
 	if c.Compression != "" {
 		cc.decompressor, err = compressor.GetDecompressor(c.Compression)
@@ -257,7 +265,7 @@ func (cc *ClientConf) auth(ctx api.StreamContext) error {
 		return err
 	}
 	defer resp.Body.Close()
-	tokens, _, err := cc.parseResponse(ctx, resp, "", true, true)
+	tokens, err := parseTokenResponse(resp)
 	if err != nil {
 		return err
 	}
@@ -283,7 +291,7 @@ func (cc *ClientConf) refresh(ctx api.StreamContext) error {
 			return fmt.Errorf("fail to get refresh token: %v", err)
 		}
 		defer resp.Body.Close()
-		nt, _, err := cc.parseResponse(ctx, resp, "", true, true)
+		nt, err := parseTokenResponse(resp)
 		if err != nil {
 			return fmt.Errorf("Cannot parse refresh token response to json: %v", err)
 		}
@@ -373,7 +381,7 @@ func (cc *ClientConf) parseResponse(ctx api.StreamContext, resp *http.Response,
 					return nil, "", fmt.Errorf("try to decompress payload failed, %w", err)
 				}
 			}
-			m, e := decode(c)
+			m, e := cc.decode(ctx, c)
 			if e != nil {
 				return nil, "", fmt.Errorf("%s: decode fail for %v", BODY_ERR, e)
 			}
@@ -386,7 +394,7 @@ func (cc *ClientConf) parseResponse(ctx api.StreamContext, resp *http.Response,
 				return nil, "", fmt.Errorf("try to decompress payload failed, %w", err)
 			}
 		}
-		payloads, err := decode(c)
+		payloads, err := cc.decode(ctx, c)
 		if err != nil {
 			return nil, "", fmt.Errorf("%s: decode fail for %v", BODY_ERR, err)
 		}
@@ -414,6 +422,40 @@ func (cc *ClientConf) parseHeaders(ctx api.StreamContext, data map[string]interf
 	return parseHeaders(ctx, cc.config.Headers, data)
 }
 
+// parseTokenResponse parses the oAuth token response which is always json
+func parseTokenResponse(resp *http.Response) ([]map[string]interface{}, error) {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%s: %d", CODE_ERR, resp.StatusCode)
+	}
+	c, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", BODY_ERR, err)
+	}
+	tokens, err := decode(c)
+	if err != nil {
+		return nil, fmt.Errorf("%s: decode fail for %v", BODY_ERR, err)
+	}
+	return tokens, nil
+}
+
+// decode decodes the response body by the format. The default format is json.
+func (cc *ClientConf) decode(ctx api.StreamContext, data []byte) ([]map[string]interface{}, error) {
+	if cc.decoder == nil {
+		return decode(data)
+	}
+	r, err := cc.decoder.Decode(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	switch rt := r.(type) {
+	case map[string]interface{}:
+		return []map[string]interface{}{rt}, nil
+	case []map[string]interface{}:
+		return rt, nil
+	}
+	return nil, fmt.Errorf("only map[string]interface{} and []map[string]interface{} is supported")
+}
+
 func decode(data []byte) ([]map[string]interface{}, error) {
 	var r1 interface{}
 	err := json.Unmarshal(data, &r1)
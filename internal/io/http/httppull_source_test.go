@@ -297,3 +297,32 @@ func TestSourceIncremental(t *testing.T) {
 	}, func(ctx api.StreamContext, err error) {})
 	require.Nil(t, <-dataCh)
 }
+
+func TestHttpPullXml(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = w.Write([]byte(`<data><tag name="t1">20.5</tag><tag name="t2">21</tag></data>`))
+	}))
+	defer server.Close()
+	ctx := mockContext.NewMockContext("1", "2")
+	source := &HttpPullSource{}
+	require.NoError(t, source.Provision(ctx, map[string]any{
+		"url":           server.URL,
+		"method":        "get",
+		"format":        "xml",
+		"xmlRoot":       "//tag",
+		"xmlAttrPrefix": "",
+	}))
+	require.NoError(t, source.Connect(ctx, func(status string, message string) {}))
+	dataCh := make(chan any, 1)
+	source.Pull(ctx, time.Now(), func(ctx api.StreamContext, data any, meta map[string]any, ts time.Time) {
+		dataCh <- data
+	}, func(ctx api.StreamContext, err error) {
+		dataCh <- err
+	})
+	require.Equal(t, []map[string]any{
+		{"name": "t1", "#text": "20.5"},
+		{"name": "t2", "#text": "21"},
+	}, <-dataCh)
+	require.NoError(t, source.Close(ctx))
+}
@@ -1,4 +1,4 @@
-// Copyright 2024-2025 EMQ Technologies Co., Ltd.
+// Copyright 2024-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -23,6 +23,7 @@ import (
 	"github.com/pingcap/failpoint"
 
 	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
 )
 
 type RestSink struct {
@@ -45,6 +46,10 @@ func (r *RestSink) Provision(ctx api.StreamContext, configs map[string]any) erro
 	if r.ClientConf.config.Format == "" {
 		r.ClientConf.config.Format = "json"
 	}
+	// send xml content type by default for xml format
+	if _, ok := configs["bodyType"]; !ok && strings.EqualFold(r.ClientConf.config.Format, message.FormatXML) && r.ClientConf.config.BodyType == "json" {
+		r.ClientConf.config.BodyType = "xml"
+	}
 	if rf, ok := bodyTypeFormat[r.ClientConf.config.BodyType]; ok && r.ClientConf.config.Format != rf {
 		return fmt.Errorf("format must be %s if bodyType is %s", rf, r.ClientConf.config.BodyType)
 	}
@@ -430,3 +430,22 @@ func TestRestSinkAuth(t *testing.T) {
 		})
 	}
 }
+
+func TestRestSinkXmlBodyType(t *testing.T) {
+	ctx := mockContext.NewMockContext("testXml", "op")
+	s := &RestSink{}
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"url":    "http://localhost/test",
+		"method": "post",
+		"format": "xml",
+	}))
+	require.Equal(t, "xml", s.config.BodyType)
+	s = &RestSink{}
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"url":      "http://localhost/test",
+		"method":   "post",
+		"format":   "xml",
+		"bodyType": "text",
+	}))
+	require.Equal(t, "text", s.config.BodyType)
+}
@@ -0,0 +1,197 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+)
+
+// Modbus read function codes
+const (
+	fcReadCoils            byte = 0x01
+	fcReadDiscreteInputs   byte = 0x02
+	fcReadHoldingRegisters byte = 0x03
+	fcReadInputRegisters   byte = 0x04
+)
+
+// The maximum quantity of a single read request defined by the protocol
+const (
+	maxReadBits      = 2000
+	maxReadRegisters = 125
+)
+
+const (
+	mbapHeaderLen = 7
+	maxAduLen     = 260
+)
+
+var exceptionMessages = map[byte]string{
+	0x01: "illegal function",
+	0x02: "illegal data address",
+	0x03: "illegal data value",
+	0x04: "server device failure",
+	0x05: "acknowledge",
+	0x06: "server device busy",
+	0x08: "memory parity error",
+	0x0A: "gateway path unavailable",
+	0x0B: "gateway target device failed to respond",
+}
+
+// client is a minimal Modbus TCP client which only supports the read functions. The requests are sent one by one
+// on a single connection. The connection is dialed lazily and is reset on any IO error so that the next request
+// reconnects.
+type client struct {
+	addr    string
+	timeout time.Duration
+
+	sync.Mutex
+	conn net.Conn
+	tid  uint16
+}
+
+func newClient(addr string, timeout time.Duration) *client {
+	return &client{addr: addr, timeout: timeout}
+}
+
+func (c *client) connect() error {
+	c.Lock()
+	defer c.Unlock()
+	return c.dial()
+}
+
+func (c *client) dial() error {
+	if c.conn != nil {
+		return nil
+	}
+	conn, err := net.DialTimeout("tcp", c.addr, c.timeout)
+	if err != nil {
+		return errorx.NewIOErr(fmt.Sprintf("connect to modbus server %s failed: %v", c.addr, err))
+	}
+	c.conn = conn
+	return nil
+}
+
+func (c *client) close() error {
+	c.Lock()
+	defer c.Unlock()
+	return c.reset()
+}
+
+func (c *client) reset() error {
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+// readBits reads the coils or discrete inputs
+func (c *client) readBits(unitId byte, fc byte, address, quantity uint16) ([]bool, error) {
+	data, err := c.read(unitId, fc, address, quantity, int(quantity+7)/8)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]bool, quantity)
+	for i := range result {
+		result[i] = data[i/8]&(1<<(i%8)) != 0
+	}
+	return result, nil
+}
+
+// readRegisters reads the holding or input registers and returns the raw bytes in the wire order
+func (c *client) readRegisters(unitId byte, fc byte, address, quantity uint16) ([]byte, error) {
+	return c.read(unitId, fc, address, quantity, int(quantity)*2)
+}
+
+func (c *client) read(unitId byte, fc byte, address, quantity uint16, byteCount int) ([]byte, error) {
+	c.Lock()
+	defer c.Unlock()
+	if err := c.dial(); err != nil {
+		return nil, err
+	}
+	c.tid++
+	req := make([]byte, mbapHeaderLen+5)
+	binary.BigEndian.PutUint16(req[0:], c.tid)
+	// protocol identifier is always 0 for modbus
+	binary.BigEndian.PutUint16(req[2:], 0)
+	binary.BigEndian.PutUint16(req[4:], 6)
+	req[6] = unitId
+	req[7] = fc
+	binary.BigEndian.PutUint16(req[8:], address)
+	binary.BigEndian.PutUint16(req[10:], quantity)
+
+	pdu, err := c.transact(req)
+	if err != nil {
+		_ = c.reset()
+		return nil, errorx.NewIOErr(fmt.Sprintf("read from modbus server %s failed: %v", c.addr, err))
+	}
+	if pdu[0] == fc|0x80 {
+		if len(pdu) < 2 {
+			return nil, fmt.Errorf("invalid modbus exception response")
+		}
+		msg, ok := exceptionMessages[pdu[1]]
+		if !ok {
+			msg = "unknown exception"
+		}
+		return nil, fmt.Errorf("modbus exception %d: %s", pdu[1], msg)
+	}
+	if pdu[0] != fc {
+		_ = c.reset()
+		return nil, fmt.Errorf("invalid modbus response function code %d, expect %d", pdu[0], fc)
+	}
+	if len(pdu) < 2 || int(pdu[1]) != byteCount || len(pdu)-2 != byteCount {
+		_ = c.reset()
+		return nil, fmt.Errorf("invalid modbus response length, expect %d bytes", byteCount)
+	}
+	return pdu[2:], nil
+}
+
+// transact sends the request and reads the response pdu of the same transaction
+func (c *client) transact(req []byte) ([]byte, error) {
+	if err := c.conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+		return nil, err
+	}
+	if _, err := c.conn.Write(req); err != nil {
+		return nil, err
+	}
+	header := make([]byte, mbapHeaderLen)
+	if _, err := io.ReadFull(c.conn, header); err != nil {
+		return nil, err
+	}
+	length := int(binary.BigEndian.Uint16(header[4:]))
+	// length includes the unit id and at least one byte function code
+	if length < 2 || length+6 > maxAduLen {
+		return nil, fmt.Errorf("invalid response length %d", length)
+	}
+	pdu := make([]byte, length-1)
+	if _, err := io.ReadFull(c.conn, pdu); err != nil {
+		return nil, err
+	}
+	if tid := binary.BigEndian.Uint16(header[0:]); tid != c.tid {
+		return nil, fmt.Errorf("transaction id mismatch, got %d, expect %d", tid, c.tid)
+	}
+	if header[6] != req[6] {
+		return nil, fmt.Errorf("unit id mismatch, got %d, expect %d", header[6], req[6])
+	}
+	return pdu, nil
+}
@@ -0,0 +1,236 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Register defines a field of the output tuple which is read from the modbus data area
+type Register struct {
+	Name string `json:"name"`
+	// Area is the modbus data area, could be coil, discreteInput, holdingRegister or inputRegister
+	Area    string `json:"area"`
+	Address uint16 `json:"address"`
+	// Type is the data type. The bit areas only support bool.
+	Type string `json:"type"`
+	// ByteOrder is the byte order inside a register, could be big or little
+	ByteOrder string `json:"byteOrder"`
+	// WordOrder is the register order of the multi-register types, could be big or little
+	WordOrder string `json:"wordOrder"`
+	// Scale is multiplied to the numeric value. The result is float if the scale is set.
+	Scale float64 `json:"scale"`
+
+	fc    byte
+	count uint16
+}
+
+var areas = map[string]byte{
+	"coil":            fcReadCoils,
+	"discreteInput":   fcReadDiscreteInputs,
+	"holdingRegister": fcReadHoldingRegisters,
+	"inputRegister":   fcReadInputRegisters,
+}
+
+// register count of each data type
+var types = map[string]uint16{
+	"bool":    1,
+	"int16":   1,
+	"uint16":  1,
+	"int32":   2,
+	"uint32":  2,
+	"float32": 2,
+	"int64":   4,
+	"uint64":  4,
+	"float64": 4,
+}
+
+func (r *Register) validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("register name is required")
+	}
+	fc, ok := areas[r.Area]
+	if !ok {
+		return fmt.Errorf("register %s has invalid area %s, must be coil, discreteInput, holdingRegister or inputRegister", r.Name, r.Area)
+	}
+	r.fc = fc
+	isBit := fc == fcReadCoils || fc == fcReadDiscreteInputs
+	if r.Type == "" {
+		if isBit {
+			r.Type = "bool"
+		} else {
+			r.Type = "uint16"
+		}
+	}
+	count, ok := types[r.Type]
+	if !ok {
+		return fmt.Errorf("register %s has invalid type %s", r.Name, r.Type)
+	}
+	if isBit != (r.Type == "bool") {
+		return fmt.Errorf("register %s of area %s doesn't support type %s", r.Name, r.Area, r.Type)
+	}
+	r.count = count
+	if int(r.Address)+int(count) > math.MaxUint16+1 {
+		return fmt.Errorf("register %s address %d is out of range", r.Name, r.Address)
+	}
+	if r.ByteOrder == "" {
+		r.ByteOrder = "big"
+	}
+	if r.ByteOrder != "big" && r.ByteOrder != "little" {
+		return fmt.Errorf("register %s has invalid byteOrder %s, must be big or little", r.Name, r.ByteOrder)
+	}
+	if r.WordOrder == "" {
+		r.WordOrder = "big"
+	}
+	if r.WordOrder != "big" && r.WordOrder != "little" {
+		return fmt.Errorf("register %s has invalid wordOrder %s, must be big or little", r.Name, r.WordOrder)
+	}
+	return nil
+}
+
+// decode converts the raw register bytes in the wire order to the typed value
+func (r *Register) decode(raw []byte) any {
+	b := make([]byte, len(raw))
+	words := len(raw) / 2
+	for i := 0; i < words; i++ {
+		j := i
+		if r.WordOrder == "little" {
+			j = words - 1 - i
+		}
+		if r.ByteOrder == "little" {
+			b[2*j], b[2*j+1] = raw[2*i+1], raw[2*i]
+		} else {
+			b[2*j], b[2*j+1] = raw[2*i], raw[2*i+1]
+		}
+	}
+	var v any
+	switch r.Type {
+	case "int16":
+		v = int64(int16(binary.BigEndian.Uint16(b)))
+	case "uint16":
+		v = int64(binary.BigEndian.Uint16(b))
+	case "int32":
+		v = int64(int32(binary.BigEndian.Uint32(b)))
+	case "uint32":
+		v = int64(binary.BigEndian.Uint32(b))
+	case "int64":
+		v = int64(binary.BigEndian.Uint64(b))
+	case "uint64":
+		v = binary.BigEndian.Uint64(b)
+	case "float32":
+		// format with 32 bits precision to avoid the noise digits like 3.140000104904175
+		f := math.Float32frombits(binary.BigEndian.Uint32(b))
+		v, _ = strconv.ParseFloat(strconv.FormatFloat(float64(f), 'g', -1, 32), 64)
+	case "float64":
+		v = math.Float64frombits(binary.BigEndian.Uint64(b))
+	}
+	if r.Scale == 0 || r.Scale == 1 {
+		return v
+	}
+	switch n := v.(type) {
+	case int64:
+		return float64(n) * r.Scale
+	case uint64:
+		return float64(n) * r.Scale
+	case float64:
+		return n * r.Scale
+	}
+	return v
+}
+
+// readBlock is a read request which covers the contiguous registers of the same area
+type readBlock struct {
+	fc        byte
+	address   uint16
+	quantity  uint16
+	registers []*Register
+}
+
+// planReads validates the register map and merges the contiguous registers into the minimal read requests
+func planReads(registers []*Register) ([]*readBlock, error) {
+	if len(registers) == 0 {
+		return nil, fmt.Errorf("registers is required")
+	}
+	names := make(map[string]struct{}, len(registers))
+	for _, r := range registers {
+		if err := r.validate(); err != nil {
+			return nil, err
+		}
+		if _, ok := names[r.Name]; ok {
+			return nil, fmt.Errorf("duplicate register name %s", r.Name)
+		}
+		names[r.Name] = struct{}{}
+	}
+	sorted := make([]*Register, len(registers))
+	copy(sorted, registers)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].fc != sorted[j].fc {
+			return sorted[i].fc < sorted[j].fc
+		}
+		return sorted[i].Address < sorted[j].Address
+	})
+	var (
+		blocks []*readBlock
+		cur    *readBlock
+	)
+	for _, r := range sorted {
+		limit := maxReadRegisters
+		if r.fc == fcReadCoils || r.fc == fcReadDiscreteInputs {
+			limit = maxReadBits
+		}
+		end := int(r.Address) + int(r.count)
+		if cur != nil && cur.fc == r.fc && int(r.Address) <= int(cur.address)+int(cur.quantity) && end-int(cur.address) <= limit {
+			if q := uint16(end - int(cur.address)); q > cur.quantity {
+				cur.quantity = q
+			}
+			cur.registers = append(cur.registers, r)
+			continue
+		}
+		cur = &readBlock{fc: r.fc, address: r.Address, quantity: r.count, registers: []*Register{r}}
+		blocks = append(blocks, cur)
+	}
+	return blocks, nil
+}
+
+// readAll reads all the blocks from the unit and returns the tuple of the register values
+func readAll(cli *client, unitId byte, blocks []*readBlock) (map[string]any, error) {
+	result := make(map[string]any)
+	for _, b := range blocks {
+		switch b.fc {
+		case fcReadCoils, fcReadDiscreteInputs:
+			bits, err := cli.readBits(unitId, b.fc, b.address, b.quantity)
+			if err != nil {
+				return nil, err
+			}
+			for _, r := range b.registers {
+				result[r.Name] = bits[r.Address-b.address]
+			}
+		default:
+			raw, err := cli.readRegisters(unitId, b.fc, b.address, b.quantity)
+			if err != nil {
+				return nil, err
+			}
+			for _, r := range b.registers {
+				offset := int(r.Address-b.address) * 2
+				result[r.Name] = r.decode(raw[offset : offset+int(r.count)*2])
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,235 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		reg  *Register
+		raw  []byte
+		exp  any
+	}{
+		{
+			name: "int16",
+			reg:  &Register{Type: "int16"},
+			raw:  []byte{0xFF, 0xFE},
+			exp:  int64(-2),
+		},
+		{
+			name: "uint16",
+			reg:  &Register{Type: "uint16"},
+			raw:  []byte{0xFF, 0xFE},
+			exp:  int64(65534),
+		},
+		{
+			name: "uint16 little byte order",
+			reg:  &Register{Type: "uint16", ByteOrder: "little"},
+			raw:  []byte{0x01, 0x02},
+			exp:  int64(0x0201),
+		},
+		{
+			name: "int16 scale",
+			reg:  &Register{Type: "int16", Scale: 0.5},
+			raw:  []byte{0xFF, 0xFB},
+			exp:  -2.5,
+		},
+		{
+			name: "int32",
+			reg:  &Register{Type: "int32"},
+			raw:  []byte{0xFF, 0xFF, 0xFF, 0x9C},
+			exp:  int64(-100),
+		},
+		{
+			name: "uint32 little word order",
+			reg:  &Register{Type: "uint32", WordOrder: "little"},
+			raw:  []byte{0x00, 0x02, 0x00, 0x01},
+			exp:  int64(0x00010002),
+		},
+		{
+			name: "float32 ABCD",
+			reg:  &Register{Type: "float32", ByteOrder: "big", WordOrder: "big"},
+			raw:  []byte{0x40, 0x48, 0xF5, 0xC3},
+			exp:  3.14,
+		},
+		{
+			name: "float32 CDAB",
+			reg:  &Register{Type: "float32", ByteOrder: "big", WordOrder: "little"},
+			raw:  []byte{0xF5, 0xC3, 0x40, 0x48},
+			exp:  3.14,
+		},
+		{
+			name: "float32 BADC",
+			reg:  &Register{Type: "float32", ByteOrder: "little", WordOrder: "big"},
+			raw:  []byte{0x48, 0x40, 0xC3, 0xF5},
+			exp:  3.14,
+		},
+		{
+			name: "float32 DCBA",
+			reg:  &Register{Type: "float32", ByteOrder: "little", WordOrder: "little"},
+			raw:  []byte{0xC3, 0xF5, 0x48, 0x40},
+			exp:  3.14,
+		},
+		{
+			name: "int64",
+			reg:  &Register{Type: "int64"},
+			raw:  []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF},
+			exp:  int64(-1),
+		},
+		{
+			name: "uint64",
+			reg:  &Register{Type: "uint64"},
+			raw:  []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF},
+			exp:  uint64(18446744073709551615),
+		},
+		{
+			name: "float64",
+			reg:  &Register{Type: "float64", Scale: 2},
+			raw:  []byte{0x40, 0x09, 0x21, 0xFB, 0x54, 0x44, 0x2D, 0x18},
+			exp:  6.283185307179586,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.reg.ByteOrder == "" {
+				tt.reg.ByteOrder = "big"
+			}
+			if tt.reg.WordOrder == "" {
+				tt.reg.WordOrder = "big"
+			}
+			require.Equal(t, tt.exp, tt.reg.decode(tt.raw))
+		})
+	}
+}
+
+func TestPlanReads(t *testing.T) {
+	registers := []*Register{
+		{Name: "temperature", Area: "holdingRegister", Address: 2, Type: "float32"},
+		{Name: "pressure", Area: "holdingRegister", Address: 0, Type: "int16"},
+		{Name: "humidity", Area: "holdingRegister", Address: 1},
+		// gap after the temperature
+		{Name: "counter", Area: "holdingRegister", Address: 10, Type: "uint32"},
+		// overlapped with the counter
+		{Name: "counterHigh", Area: "holdingRegister", Address: 10},
+		{Name: "voltage", Area: "inputRegister", Address: 2},
+		{Name: "running", Area: "coil", Address: 5},
+		{Name: "alarm", Area: "coil", Address: 6},
+	}
+	blocks, err := planReads(registers)
+	require.NoError(t, err)
+	require.Len(t, blocks, 4)
+	exp := []struct {
+		fc       byte
+		address  uint16
+		quantity uint16
+		names    []string
+	}{
+		{fc: fcReadCoils, address: 5, quantity: 2, names: []string{"running", "alarm"}},
+		{fc: fcReadHoldingRegisters, address: 0, quantity: 4, names: []string{"pressure", "humidity", "temperature"}},
+		{fc: fcReadHoldingRegisters, address: 10, quantity: 2, names: []string{"counter", "counterHigh"}},
+		{fc: fcReadInputRegisters, address: 2, quantity: 1, names: []string{"voltage"}},
+	}
+	for i, b := range blocks {
+		require.Equal(t, exp[i].fc, b.fc)
+		require.Equal(t, exp[i].address, b.address)
+		require.Equal(t, exp[i].quantity, b.quantity)
+		names := make([]string, 0, len(b.registers))
+		for _, r := range b.registers {
+			names = append(names, r.Name)
+		}
+		require.Equal(t, exp[i].names, names)
+	}
+
+	// split by the max quantity of a request
+	registers = make([]*Register, 0, 130)
+	for i := 0; i < 130; i++ {
+		registers = append(registers, &Register{Name: string(rune('a'+i%26)) + string(rune('a'+i/26)), Area: "inputRegister", Address: uint16(i)})
+	}
+	blocks, err = planReads(registers)
+	require.NoError(t, err)
+	require.Len(t, blocks, 2)
+	require.Equal(t, uint16(125), blocks[0].quantity)
+	require.Equal(t, uint16(125), blocks[1].address)
+	require.Equal(t, uint16(5), blocks[1].quantity)
+}
+
+func TestPlanReadsErr(t *testing.T) {
+	tests := []struct {
+		name string
+		regs []*Register
+		err  string
+	}{
+		{
+			name: "empty",
+			err:  "registers is required",
+		},
+		{
+			name: "no name",
+			regs: []*Register{{Area: "coil"}},
+			err:  "register name is required",
+		},
+		{
+			name: "invalid area",
+			regs: []*Register{{Name: "a", Area: "holding"}},
+			err:  "register a has invalid area holding, must be coil, discreteInput, holdingRegister or inputRegister",
+		},
+		{
+			name: "invalid type",
+			regs: []*Register{{Name: "a", Area: "holdingRegister", Type: "string"}},
+			err:  "register a has invalid type string",
+		},
+		{
+			name: "bool register",
+			regs: []*Register{{Name: "a", Area: "holdingRegister", Type: "bool"}},
+			err:  "register a of area holdingRegister doesn't support type bool",
+		},
+		{
+			name: "int coil",
+			regs: []*Register{{Name: "a", Area: "coil", Type: "int16"}},
+			err:  "register a of area coil doesn't support type int16",
+		},
+		{
+			name: "out of range",
+			regs: []*Register{{Name: "a", Area: "inputRegister", Address: 65535, Type: "uint32"}},
+			err:  "register a address 65535 is out of range",
+		},
+		{
+			name: "invalid byte order",
+			regs: []*Register{{Name: "a", Area: "inputRegister", ByteOrder: "ab"}},
+			err:  "register a has invalid byteOrder ab, must be big or little",
+		},
+		{
+			name: "invalid word order",
+			regs: []*Register{{Name: "a", Area: "inputRegister", WordOrder: "ab"}},
+			err:  "register a has invalid wordOrder ab, must be big or little",
+		},
+		{
+			name: "duplicate",
+			regs: []*Register{{Name: "a", Area: "inputRegister"}, {Name: "a", Area: "coil"}},
+			err:  "duplicate register name a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := planReads(tt.regs)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
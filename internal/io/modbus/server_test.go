@@ -0,0 +1,178 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const simAreaSize = 200
+
+type simUnit struct {
+	coils    []bool
+	discrete []bool
+	holding  []uint16
+	input    []uint16
+}
+
+// simulator is an in-process modbus tcp server for testing which serves the read functions
+type simulator struct {
+	ln net.Listener
+	sync.Mutex
+	units map[byte]*simUnit
+	conns map[net.Conn]struct{}
+	// the count of the received requests
+	requests int
+}
+
+func newSimulator(t *testing.T, unitIds ...byte) *simulator {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &simulator{
+		ln:    ln,
+		units: make(map[byte]*simUnit),
+		conns: make(map[net.Conn]struct{}),
+	}
+	for _, id := range unitIds {
+		s.units[id] = &simUnit{
+			coils:    make([]bool, simAreaSize),
+			discrete: make([]bool, simAreaSize),
+			holding:  make([]uint16, simAreaSize),
+			input:    make([]uint16, simAreaSize),
+		}
+	}
+	go s.serve()
+	t.Cleanup(s.close)
+	return s
+}
+
+func (s *simulator) addr() string {
+	return s.ln.Addr().String()
+}
+
+func (s *simulator) unit(id byte) *simUnit {
+	s.Lock()
+	defer s.Unlock()
+	return s.units[id]
+}
+
+func (s *simulator) requestCount() int {
+	s.Lock()
+	defer s.Unlock()
+	return s.requests
+}
+
+// dropConns closes all the accepted connections to simulate the network failure
+func (s *simulator) dropConns() {
+	s.Lock()
+	defer s.Unlock()
+	for c := range s.conns {
+		_ = c.Close()
+	}
+}
+
+func (s *simulator) close() {
+	_ = s.ln.Close()
+	s.dropConns()
+}
+
+func (s *simulator) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.Lock()
+		s.conns[conn] = struct{}{}
+		s.Unlock()
+		go s.handle(conn)
+	}
+}
+
+func (s *simulator) handle(conn net.Conn) {
+	defer func() {
+		s.Lock()
+		delete(s.conns, conn)
+		s.Unlock()
+		_ = conn.Close()
+	}()
+	header := make([]byte, mbapHeaderLen)
+	for {
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		pdu := make([]byte, binary.BigEndian.Uint16(header[4:])-1)
+		if _, err := io.ReadFull(conn, pdu); err != nil {
+			return
+		}
+		resp := s.process(header[6], pdu)
+		adu := make([]byte, mbapHeaderLen, mbapHeaderLen+len(resp))
+		copy(adu, header[:4])
+		binary.BigEndian.PutUint16(adu[4:], uint16(len(resp)+1))
+		adu[6] = header[6]
+		if _, err := conn.Write(append(adu, resp...)); err != nil {
+			return
+		}
+	}
+}
+
+func (s *simulator) process(unitId byte, pdu []byte) []byte {
+	s.Lock()
+	defer s.Unlock()
+	s.requests++
+	fc := pdu[0]
+	u, ok := s.units[unitId]
+	if !ok {
+		return []byte{fc | 0x80, 0x0B}
+	}
+	if fc < fcReadCoils || fc > fcReadInputRegisters || len(pdu) != 5 {
+		return []byte{fc | 0x80, 0x01}
+	}
+	address := int(binary.BigEndian.Uint16(pdu[1:]))
+	quantity := int(binary.BigEndian.Uint16(pdu[3:]))
+	if address+quantity > simAreaSize {
+		return []byte{fc | 0x80, 0x02}
+	}
+	switch fc {
+	case fcReadCoils, fcReadDiscreteInputs:
+		bits := u.coils
+		if fc == fcReadDiscreteInputs {
+			bits = u.discrete
+		}
+		data := make([]byte, (quantity+7)/8)
+		for i := 0; i < quantity; i++ {
+			if bits[address+i] {
+				data[i/8] |= 1 << (i % 8)
+			}
+		}
+		return append([]byte{fc, byte(len(data))}, data...)
+	default:
+		regs := u.holding
+		if fc == fcReadInputRegisters {
+			regs = u.input
+		}
+		data := make([]byte, quantity*2)
+		for i := 0; i < quantity; i++ {
+			binary.BigEndian.PutUint16(data[2*i:], regs[address+i])
+		}
+		return append([]byte{fc, byte(len(data))}, data...)
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+)
+
+type conf struct {
+	// Server is the address of the modbus tcp server like 127.0.0.1:502, the tcp:// prefix is optional
+	Server string `json:"server"`
+	// UnitId is the unit identifier(slave id) to read from
+	UnitId    int               `json:"unitId"`
+	Timeout   cast.DurationConf `json:"timeout"`
+	Registers []*Register       `json:"registers"`
+}
+
+// reader is the common part of the modbus source and lookup source
+type reader struct {
+	cfg    *conf
+	blocks []*readBlock
+	cli    *client
+	sch    api.StatusChangeHandler
+	// whether the last read succeeded, used to report the status change once
+	connected bool
+}
+
+func (m *reader) Provision(_ api.StreamContext, props map[string]any) error {
+	cfg := &conf{
+		UnitId:  1,
+		Timeout: cast.DurationConf(time.Second),
+	}
+	err := cast.MapToStruct(props, cfg)
+	if err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	cfg.Server = strings.TrimPrefix(cfg.Server, "tcp://")
+	if cfg.Server == "" {
+		return fmt.Errorf("server is required")
+	}
+	if cfg.UnitId < 0 || cfg.UnitId > 255 {
+		return fmt.Errorf("invalid unitId %d, must be in range 0-255", cfg.UnitId)
+	}
+	if cfg.Timeout <= 0 {
+		return fmt.Errorf("invalid timeout %v, must be positive", time.Duration(cfg.Timeout))
+	}
+	m.blocks, err = planReads(cfg.Registers)
+	if err != nil {
+		return err
+	}
+	m.cfg = cfg
+	return nil
+}
+
+func (m *reader) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	ctx.GetLogger().Infof("Connecting to modbus server %s", m.cfg.Server)
+	m.sch = sch
+	m.cli = newClient(m.cfg.Server, time.Duration(m.cfg.Timeout))
+	err := m.cli.connect()
+	if err != nil {
+		sch(api.ConnectionDisconnected, err.Error())
+		return err
+	}
+	m.connected = true
+	sch(api.ConnectionConnected, "")
+	return nil
+}
+
+func (m *reader) Ping(ctx api.StreamContext, props map[string]any) error {
+	cfg := &conf{Timeout: cast.DurationConf(time.Second)}
+	err := cast.MapToStruct(props, cfg)
+	if err != nil {
+		return err
+	}
+	cli := newClient(strings.TrimPrefix(cfg.Server, "tcp://"), time.Duration(cfg.Timeout))
+	defer cli.close()
+	return cli.connect()
+}
+
+// read reads all the registers from the unit. The client reconnects in the next read if the connection is broken.
+func (m *reader) read(ctx api.StreamContext, unitId byte) (map[string]any, error) {
+	result, err := readAll(m.cli, unitId, m.blocks)
+	if err != nil {
+		if m.connected && errorx.IsIOError(err) {
+			m.connected = false
+			m.sch(api.ConnectionDisconnected, err.Error())
+		}
+		return nil, err
+	}
+	if !m.connected {
+		m.connected = true
+		m.sch(api.ConnectionConnected, "")
+		ctx.GetLogger().Infof("modbus server %s reconnected", m.cfg.Server)
+	}
+	return result, nil
+}
+
+func (m *reader) Close(ctx api.StreamContext) error {
+	ctx.GetLogger().Infof("Closing modbus source to %s", m.cfg.Server)
+	if m.cli != nil {
+		return m.cli.close()
+	}
+	return nil
+}
+
+// Source polls the registers of the configured unit in each interval and produces one tuple per poll
+type Source struct {
+	reader
+}
+
+func (s *Source) Pull(ctx api.StreamContext, trigger time.Time, ingest api.TupleIngest, ingestError api.ErrorIngest) {
+	result, err := s.read(ctx, byte(s.cfg.UnitId))
+	if err != nil {
+		ingestError(ctx, err)
+		return
+	}
+	ingest(ctx, result, map[string]any{"unitId": s.cfg.UnitId}, trigger)
+}
+
+// LookupSource reads the registers on demand. The lookup key must be unitId, so that a rule can read the same
+// register map from different units.
+type LookupSource struct {
+	reader
+}
+
+func (s *LookupSource) Lookup(ctx api.StreamContext, _ []string, keys []string, values []any) ([]map[string]any, error) {
+	if len(keys) != 1 || keys[0] != "unitId" {
+		return nil, fmt.Errorf("modbus lookup only supports the key unitId, but got %v", keys)
+	}
+	unitId, err := cast.ToInt(values[0], cast.CONVERT_SAMEKIND)
+	if err != nil || unitId < 0 || unitId > 255 {
+		return nil, fmt.Errorf("invalid unitId %v", values[0])
+	}
+	ctx.GetLogger().Debugf("Lookup modbus unit %d", unitId)
+	result, err := s.read(ctx, byte(unitId))
+	if err != nil {
+		return nil, err
+	}
+	result["unitId"] = int64(unitId)
+	return []map[string]any{result}, nil
+}
+
+func GetSource() api.Source {
+	return &Source{}
+}
+
+func GetLookupSource() api.Source {
+	return &LookupSource{}
+}
+
+var (
+	_ api.PullTupleSource = &Source{}
+	_ api.LookupSource    = &LookupSource{}
+	_ util.PingableConn   = &Source{}
+	_ util.PingableConn   = &LookupSource{}
+)
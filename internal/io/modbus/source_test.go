@@ -0,0 +1,205 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/require"
+
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+var testRegisters = []map[string]any{
+	{"name": "running", "area": "coil", "address": 0},
+	{"name": "alarm", "area": "discreteInput", "address": 3},
+	{"name": "temperature", "area": "holdingRegister", "address": 0, "type": "float32"},
+	{"name": "pressure", "area": "holdingRegister", "address": 2, "type": "int16", "scale": 0.1},
+	{"name": "counter", "area": "inputRegister", "address": 10, "type": "uint32", "wordOrder": "little"},
+}
+
+func setValues(s *simulator, unitId byte, temperature [2]uint16, pressure uint16) {
+	u := s.unit(unitId)
+	u.coils[0] = true
+	u.discrete[3] = true
+	u.holding[0], u.holding[1] = temperature[0], temperature[1]
+	u.holding[2] = pressure
+	u.input[10], u.input[11] = 0x0002, 0x0001
+}
+
+type pullResult struct {
+	data any
+	meta map[string]any
+	err  error
+}
+
+func pull(ctx api.StreamContext, s *Source) pullResult {
+	var r pullResult
+	s.Pull(ctx, time.Now(), func(_ api.StreamContext, data any, meta map[string]any, _ time.Time) {
+		r.data = data
+		r.meta = meta
+	}, func(_ api.StreamContext, err error) {
+		r.err = err
+	})
+	return r
+}
+
+func TestPull(t *testing.T) {
+	sim := newSimulator(t, 1)
+	// 22.5 and -12.3 bar
+	setValues(sim, 1, [2]uint16{0x41B4, 0x0000}, 0xFF85)
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	s := GetSource().(*Source)
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"server":    "tcp://" + sim.addr(),
+		"registers": testRegisters,
+	}))
+	var statuses []string
+	require.NoError(t, s.Connect(ctx, func(status string, _ string) {
+		statuses = append(statuses, status)
+	}))
+	r := pull(ctx, s)
+	require.NoError(t, r.err)
+	require.Equal(t, map[string]any{
+		"running":     true,
+		"alarm":       true,
+		"temperature": 22.5,
+		"pressure":    -12.3,
+		"counter":     int64(0x00010002),
+	}, r.data)
+	require.Equal(t, map[string]any{"unitId": 1}, r.meta)
+	// coil, discrete input, holding registers and input registers are read in 4 requests
+	require.Equal(t, 4, sim.requestCount())
+
+	// the connection is broken, report the error and reconnect in the next pull
+	sim.dropConns()
+	r = pull(ctx, s)
+	require.Error(t, r.err)
+	r = pull(ctx, s)
+	require.NoError(t, r.err)
+	require.Equal(t, 22.5, r.data.(map[string]any)["temperature"])
+	require.Equal(t, []string{api.ConnectionConnected, api.ConnectionDisconnected, api.ConnectionConnected}, statuses)
+	require.NoError(t, s.Close(ctx))
+}
+
+func TestPullErr(t *testing.T) {
+	sim := newSimulator(t, 1)
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	s := GetSource().(*Source)
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"server": sim.addr(),
+		"unitId": 2,
+		"registers": []map[string]any{
+			{"name": "a", "area": "inputRegister", "address": 0},
+		},
+	}))
+	require.NoError(t, s.Connect(ctx, func(string, string) {}))
+	r := pull(ctx, s)
+	require.EqualError(t, r.err, "modbus exception 11: gateway target device failed to respond")
+
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"server": sim.addr(),
+		"registers": []map[string]any{
+			{"name": "a", "area": "inputRegister", "address": simAreaSize},
+		},
+	}))
+	r = pull(ctx, s)
+	require.EqualError(t, r.err, "modbus exception 2: illegal data address")
+	require.NoError(t, s.Close(ctx))
+
+	// connect failure
+	addr := sim.addr()
+	sim.close()
+	s = GetSource().(*Source)
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"server":    addr,
+		"registers": testRegisters,
+	}))
+	require.Error(t, s.Connect(ctx, func(string, string) {}))
+	require.Error(t, s.Ping(ctx, map[string]any{"server": addr}))
+}
+
+func TestLookup(t *testing.T) {
+	sim := newSimulator(t, 1, 2)
+	setValues(sim, 1, [2]uint16{0x41B4, 0x0000}, 10)
+	// 3.14 with CDAB order
+	setValues(sim, 2, [2]uint16{0x4048, 0xF5C3}, 20)
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	s := GetLookupSource().(*LookupSource)
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"server":    sim.addr(),
+		"registers": testRegisters,
+	}))
+	require.NoError(t, s.Ping(ctx, map[string]any{"server": sim.addr()}))
+	require.NoError(t, s.Connect(ctx, func(string, string) {}))
+	result, err := s.Lookup(ctx, nil, []string{"unitId"}, []any{int64(2)})
+	require.NoError(t, err)
+	require.Equal(t, []map[string]any{{
+		"unitId":      int64(2),
+		"running":     true,
+		"alarm":       true,
+		"temperature": 3.14,
+		"pressure":    float64(2),
+		"counter":     int64(0x00010002),
+	}}, result)
+	result, err = s.Lookup(ctx, nil, []string{"unitId"}, []any{float64(1)})
+	require.NoError(t, err)
+	require.Equal(t, 22.5, result[0]["temperature"])
+
+	_, err = s.Lookup(ctx, nil, []string{"id"}, []any{1})
+	require.EqualError(t, err, "modbus lookup only supports the key unitId, but got [id]")
+	_, err = s.Lookup(ctx, nil, []string{"unitId"}, []any{300})
+	require.EqualError(t, err, "invalid unitId 300")
+	_, err = s.Lookup(ctx, nil, []string{"unitId"}, []any{3})
+	require.EqualError(t, err, "modbus exception 11: gateway target device failed to respond")
+	require.NoError(t, s.Close(ctx))
+}
+
+func TestProvisionErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "no server",
+			props: map[string]any{"registers": testRegisters},
+			err:   "server is required",
+		},
+		{
+			name:  "invalid unit id",
+			props: map[string]any{"server": "127.0.0.1:502", "unitId": 256, "registers": testRegisters},
+			err:   "invalid unitId 256, must be in range 0-255",
+		},
+		{
+			name:  "invalid timeout",
+			props: map[string]any{"server": "127.0.0.1:502", "timeout": "-1s", "registers": testRegisters},
+			err:   "invalid timeout -1s, must be positive",
+		},
+		{
+			name:  "no registers",
+			props: map[string]any{"server": "127.0.0.1:502"},
+			err:   "registers is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, GetSource().Provision(ctx, tt.props), tt.err)
+		})
+	}
+}
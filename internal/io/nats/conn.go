@@ -0,0 +1,234 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cert"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+)
+
+type ConnectionConfig struct {
+	// Server is the nats server url. Multiple urls can be separated by comma for a cluster
+	Server   string `json:"server"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Token    string `json:"token"`
+	// CredsFile is the path of the user credentials file for the decentralized authentication
+	CredsFile string `json:"credsFile"`
+	// ConnectTimeout is the timeout to dial the server
+	ConnectTimeout cast.DurationConf `json:"connectTimeout"`
+}
+
+type Connection struct {
+	nc        *nats.Conn
+	js        jetstream.JetStream
+	id        string
+	server    string
+	opts      []nats.Option
+	connected atomic.Bool
+	status    atomic.Value
+	scHandler api.StatusChangeHandler
+}
+
+func CreateConnection(_ api.StreamContext) modules.Connection {
+	conn := &Connection{}
+	conn.status.Store(modules.ConnectionStatus{Status: api.ConnectionConnecting})
+	return conn
+}
+
+func ValidateConfig(ctx api.StreamContext, props map[string]any) (*ConnectionConfig, []nats.Option, error) {
+	c := &ConnectionConfig{
+		ConnectTimeout: cast.DurationConf(5 * time.Second),
+	}
+	err := cast.MapToStruct(props, c)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if c.Server == "" {
+		return nil, nil, fmt.Errorf("server is required")
+	}
+	if c.ConnectTimeout <= 0 {
+		return nil, nil, fmt.Errorf("invalid connectTimeout %v, must be positive", time.Duration(c.ConnectTimeout))
+	}
+	opts := []nats.Option{
+		nats.Timeout(time.Duration(c.ConnectTimeout)),
+		// reconnect forever, the status is reported by the handlers
+		nats.MaxReconnects(-1),
+		nats.RetryOnFailedConnect(false),
+	}
+	switch {
+	case c.CredsFile != "":
+		opts = append(opts, nats.UserCredentials(c.CredsFile))
+	case c.Token != "":
+		opts = append(opts, nats.Token(c.Token))
+	case c.Username != "":
+		opts = append(opts, nats.UserInfo(c.Username, c.Password))
+	}
+	tlsConf, err := cert.GenTLSConfig(ctx, props)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tlsConf != nil {
+		opts = append(opts, nats.Secure(tlsConf))
+	}
+	return c, opts, nil
+}
+
+func (conn *Connection) Provision(ctx api.StreamContext, conId string, props map[string]any) error {
+	c, opts, err := ValidateConfig(ctx, props)
+	if err != nil {
+		return err
+	}
+	conn.opts = append(opts,
+		nats.Name("ekuiper-"+conId),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			conn.onDisconnect(ctx, err)
+		}),
+		nats.ReconnectHandler(func(_ *nats.Conn) {
+			conn.onConnect(ctx)
+		}),
+	)
+	conn.server = c.Server
+	conn.id = conId
+	conn.status.Store(modules.ConnectionStatus{Status: api.ConnectionConnecting})
+	return nil
+}
+
+func (conn *Connection) GetId(_ api.StreamContext) string {
+	return conn.id
+}
+
+func (conn *Connection) Dial(ctx api.StreamContext) error {
+	nc, err := nats.Connect(conn.server, conn.opts...)
+	if err != nil {
+		return errorx.NewIOErr(fmt.Sprintf("found error when connecting for %s: %s", conn.server, err))
+	}
+	js, err := jetstream.New(nc)
+	if err != nil {
+		nc.Close()
+		return err
+	}
+	conn.nc = nc
+	conn.js = js
+	conn.onConnect(ctx)
+	ctx.GetLogger().Infof("new nats client created")
+	return nil
+}
+
+func (conn *Connection) Status(_ api.StreamContext) modules.ConnectionStatus {
+	return conn.status.Load().(modules.ConnectionStatus)
+}
+
+func (conn *Connection) SetStatusChangeHandler(ctx api.StreamContext, sch api.StatusChangeHandler) {
+	st := conn.status.Load().(modules.ConnectionStatus)
+	sch(st.Status, st.ErrMsg)
+	conn.scHandler = sch
+	ctx.GetLogger().Infof("trigger status change handler")
+}
+
+func (conn *Connection) onConnect(ctx api.StreamContext) {
+	conn.connected.Store(true)
+	conn.status.Store(modules.ConnectionStatus{Status: api.ConnectionConnected})
+	if conn.scHandler != nil {
+		conn.scHandler(api.ConnectionConnected, "")
+	}
+	ctx.GetLogger().Infof("The connection to nats server is established")
+}
+
+func (conn *Connection) onDisconnect(ctx api.StreamContext, err error) {
+	conn.connected.Store(false)
+	msg := "disconnected"
+	if err != nil {
+		msg = err.Error()
+	}
+	conn.status.Store(modules.ConnectionStatus{Status: api.ConnectionDisconnected, ErrMsg: msg})
+	if conn.scHandler != nil {
+		conn.scHandler(api.ConnectionDisconnected, msg)
+	}
+	ctx.GetLogger().Infof("nats connection lost: %s", msg)
+}
+
+func (conn *Connection) Ping(ctx api.StreamContext) error {
+	if conn.nc == nil {
+		return conn.Dial(ctx)
+	}
+	if !conn.nc.IsConnected() {
+		return fmt.Errorf("nats client is not connected")
+	}
+	return conn.nc.FlushTimeout(5 * time.Second)
+}
+
+func (conn *Connection) Close(_ api.StreamContext) error {
+	if conn == nil || conn.nc == nil {
+		return nil
+	}
+	conn.nc.Close()
+	return nil
+}
+
+// NATS features
+
+// Publish publishes the message to the core nats subject. It returns after the message is flushed to the server.
+func (conn *Connection) Publish(_ api.StreamContext, msg *nats.Msg) error {
+	// Need to return error immediately so that we can enable cache immediately
+	if conn == nil || !conn.connected.Load() {
+		return errorx.NewIOErr("nats client is not connected")
+	}
+	err := conn.nc.PublishMsg(msg)
+	if err == nil {
+		err = conn.nc.Flush()
+	}
+	if err != nil {
+		return errorx.NewIOErr(fmt.Sprintf("publish to nats server failed: %s", err))
+	}
+	return nil
+}
+
+// PublishStream publishes the message to the JetStream and waits for the acknowledgement of the stream
+func (conn *Connection) PublishStream(ctx api.StreamContext, msg *nats.Msg, opts ...jetstream.PublishOpt) (*jetstream.PubAck, error) {
+	if conn == nil || !conn.connected.Load() {
+		return nil, errorx.NewIOErr("nats client is not connected")
+	}
+	ack, err := conn.js.PublishMsg(ctx, msg, opts...)
+	if err != nil {
+		return nil, errorx.NewIOErr(fmt.Sprintf("publish to nats stream failed: %s", err))
+	}
+	return ack, nil
+}
+
+// Subscribe subscribes to the core nats subject. If queue is set, the subscribers of the same queue group share the messages.
+func (conn *Connection) Subscribe(subject, queue string, handler nats.MsgHandler) (*nats.Subscription, error) {
+	if queue != "" {
+		return conn.nc.QueueSubscribe(subject, queue, handler)
+	}
+	return conn.nc.Subscribe(subject, handler)
+}
+
+// Consumer creates or updates the JetStream consumer
+func (conn *Connection) Consumer(ctx api.StreamContext, stream string, cfg jetstream.ConsumerConfig) (jetstream.Consumer, error) {
+	return conn.js.CreateOrUpdateConsumer(ctx, stream, cfg)
+}
+
+var _ modules.StatefulDialer = &Connection{}
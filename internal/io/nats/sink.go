@@ -0,0 +1,163 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node/tracenode"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+)
+
+type SinkConf struct {
+	Subject string `json:"subject"`
+	// JetStream publishes the message to the JetStream and waits for the acknowledgement
+	JetStream bool `json:"jetstream"`
+	// Stream is the expected stream name of the subject for JetStream publishing
+	Stream  string            `json:"stream"`
+	Headers map[string]string `json:"headers"`
+}
+
+type Sink struct {
+	id    string
+	cw    *connection.ConnWrapper
+	cfg   *SinkConf
+	props map[string]any
+	cli   *Connection
+}
+
+func (s *Sink) Provision(ctx api.StreamContext, props map[string]any) error {
+	cfg := &SinkConf{}
+	err := cast.MapToStruct(props, cfg)
+	if err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if cfg.Subject == "" {
+		return fmt.Errorf("nats sink is missing property subject")
+	}
+	if strings.ContainsAny(cfg.Subject, "*>") {
+		return fmt.Errorf("nats sink subject shouldn't contain wildcard * or >")
+	}
+	if cfg.Stream != "" && !cfg.JetStream {
+		return fmt.Errorf("stream is only supported when jetstream is true")
+	}
+	_, _, err = ValidateConfig(ctx, props)
+	if err != nil {
+		return err
+	}
+	s.cfg = cfg
+	s.props = props
+	return nil
+}
+
+func (s *Sink) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	ctx.GetLogger().Infof("Connecting to nats server")
+	var err error
+	s.id = fmt.Sprintf("%s-%s-%s-nats-sink", ctx.GetRuleId(), ctx.GetOpId(), s.cfg.Subject)
+	s.cw, err = connection.FetchConnection(ctx, s.id, "nats", s.props, sch)
+	if err != nil {
+		return err
+	}
+	conn, err := s.cw.Wait(ctx)
+	if conn == nil {
+		return fmt.Errorf("nats client not ready: %v", err)
+	}
+	c, ok := conn.(*Connection)
+	if !ok {
+		return fmt.Errorf("connection %s should be nats connection", s.cw.ID)
+	}
+	s.cli = c
+	return err
+}
+
+func (s *Sink) Collect(ctx api.StreamContext, item api.RawTuple) error {
+	subject := s.cfg.Subject
+	headers := s.cfg.Headers
+	// If subject supports dynamic props(template), planner will guarantee the result has the parsed dynamic props
+	if dp, ok := item.(api.HasDynamicProps); ok {
+		temp, transformed := dp.DynamicProps(subject)
+		if transformed {
+			subject = temp
+		}
+		newHeaders := make(map[string]string, len(headers))
+		for k, v := range headers {
+			nv, ok := dp.DynamicProps(v)
+			if ok {
+				newHeaders[k] = nv
+			} else {
+				newHeaders[k] = v
+			}
+		}
+		headers = newHeaders
+	}
+	msg := nats.NewMsg(subject)
+	msg.Data = item.Raw()
+	for k, v := range headers {
+		msg.Header.Set(k, v)
+	}
+	traced, _, span := tracenode.TraceInput(ctx, item, fmt.Sprintf("%s_emit", ctx.GetOpId()))
+	if traced {
+		defer span.End()
+		msg.Header.Set("traceparent", tracenode.BuildTraceParentId(span.SpanContext().TraceID(), span.SpanContext().SpanID()))
+	}
+	ctx.GetLogger().Debugf("publishing to subject %s", subject)
+	if !s.cfg.JetStream {
+		return s.cli.Publish(ctx, msg)
+	}
+	var opts []jetstream.PublishOpt
+	if s.cfg.Stream != "" {
+		opts = append(opts, jetstream.WithExpectStream(s.cfg.Stream))
+	}
+	ack, err := s.cli.PublishStream(ctx, msg, opts...)
+	if err != nil {
+		return err
+	}
+	ctx.GetLogger().Debugf("published to stream %s with sequence %d", ack.Stream, ack.Sequence)
+	return nil
+}
+
+func (s *Sink) Close(ctx api.StreamContext) error {
+	ctx.GetLogger().Infof("Closing nats sink connector, id:%v", s.id)
+	if s.cw != nil {
+		return connection.DetachConnection(ctx, s.cw.ID)
+	}
+	return nil
+}
+
+func (s *Sink) Ping(ctx api.StreamContext, props map[string]any) error {
+	cli := &Connection{}
+	err := cli.Provision(ctx, "test", props)
+	if err != nil {
+		return err
+	}
+	defer cli.Close(ctx)
+	return cli.Ping(ctx)
+}
+
+func GetSink() api.Sink {
+	return &Sink{}
+}
+
+var (
+	_ api.BytesCollector = &Sink{}
+	_ util.PingableConn  = &Sink{}
+)
@@ -0,0 +1,333 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+type SourceConf struct {
+	Subject string `json:"datasource"`
+	// Queue is the queue group of the core nats subscription
+	Queue string `json:"queue"`
+	// Stream is the JetStream stream name. If it is set, the source consumes from the JetStream consumer.
+	Stream string `json:"stream"`
+	// Durable is the durable consumer name. The consumer is ephemeral if it is not set.
+	Durable string `json:"durable"`
+	// DeliverPolicy is the start position of the new consumer, could be all, last or new
+	DeliverPolicy string            `json:"deliverPolicy"`
+	AckWait       cast.DurationConf `json:"ackWait"`
+	MaxAckPending int               `json:"maxAckPending"`
+}
+
+// Source subscribes to the nats subject or consumes from the JetStream consumer.
+// For JetStream, the messages are acknowledged once ingested by default. If the rule enables checkpoint, the messages
+// are acknowledged only after the checkpoint completes so that the unacknowledged messages are redelivered after failure.
+type Source struct {
+	cfg   *SourceConf
+	props map[string]any
+
+	cli   *Connection
+	conId string
+	sub   *nats.Subscription
+	cc    jetstream.ConsumeContext
+
+	ackOnCheckpoint bool
+	// lock for the pending messages and the offset
+	sync.Mutex
+	pending []jetstream.Msg
+	// the stream sequence of the last ingested message
+	offset uint64
+	// the stream sequence of the last completed checkpoint
+	committed uint64
+}
+
+var deliverPolicies = map[string]jetstream.DeliverPolicy{
+	"all":  jetstream.DeliverAllPolicy,
+	"last": jetstream.DeliverLastPolicy,
+	"new":  jetstream.DeliverNewPolicy,
+}
+
+func (s *Source) Provision(ctx api.StreamContext, props map[string]any) error {
+	cfg := &SourceConf{
+		DeliverPolicy: "all",
+	}
+	err := cast.MapToStruct(props, cfg)
+	if err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if cfg.Subject == "" {
+		return fmt.Errorf("subject is required")
+	}
+	if cfg.Stream == "" {
+		if cfg.Durable != "" {
+			return fmt.Errorf("durable is only supported for JetStream, please set stream")
+		}
+	} else {
+		if cfg.Queue != "" {
+			return fmt.Errorf("queue is only supported for core nats, use the same durable consumer instead for JetStream")
+		}
+		if _, ok := deliverPolicies[cfg.DeliverPolicy]; !ok {
+			return fmt.Errorf("invalid deliverPolicy %s, must be all, last or new", cfg.DeliverPolicy)
+		}
+		if cfg.AckWait < 0 {
+			return fmt.Errorf("invalid ackWait %v, must be positive", time.Duration(cfg.AckWait))
+		}
+	}
+	_, _, err = ValidateConfig(ctx, props)
+	if err != nil {
+		return err
+	}
+	s.cfg = cfg
+	s.props = props
+	return nil
+}
+
+func (s *Source) Ping(ctx api.StreamContext, props map[string]any) error {
+	cli := &Connection{}
+	err := cli.Provision(ctx, "test", props)
+	if err != nil {
+		return err
+	}
+	defer cli.Close(ctx)
+	return cli.Ping(ctx)
+}
+
+func (s *Source) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	ctx.GetLogger().Infof("Connecting to nats server")
+	id := fmt.Sprintf("%s-%s-%s-nats-source", ctx.GetRuleId(), ctx.GetOpId(), s.cfg.Subject)
+	cw, err := connection.FetchConnection(ctx, id, "nats", s.props, sch)
+	if err != nil {
+		return err
+	}
+	s.conId = cw.ID
+	conn, err := cw.Wait(ctx)
+	if conn == nil {
+		return fmt.Errorf("nats client not ready: %v", err)
+	}
+	c, ok := conn.(*Connection)
+	if !ok {
+		return fmt.Errorf("connection %s should be nats connection", s.conId)
+	}
+	s.cli = c
+	return err
+}
+
+func (s *Source) Subscribe(ctx api.StreamContext, ingest api.BytesIngest, ingestError api.ErrorIngest) error {
+	if s.cfg.Stream == "" {
+		sub, err := s.cli.Subscribe(s.cfg.Subject, s.cfg.Queue, func(msg *nats.Msg) {
+			ingest(ctx, msg.Data, s.meta(msg), timex.GetNow())
+		})
+		if err != nil {
+			return fmt.Errorf("subscribe to subject %s failed: %v", s.cfg.Subject, err)
+		}
+		s.sub = sub
+		return nil
+	}
+	consumer, err := s.cli.Consumer(ctx, s.cfg.Stream, jetstream.ConsumerConfig{
+		Durable:       s.cfg.Durable,
+		FilterSubject: s.cfg.Subject,
+		DeliverPolicy: deliverPolicies[s.cfg.DeliverPolicy],
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       time.Duration(s.cfg.AckWait),
+		MaxAckPending: s.cfg.MaxAckPending,
+	})
+	if err != nil {
+		return fmt.Errorf("create consumer for stream %s failed: %v", s.cfg.Stream, err)
+	}
+	s.cc, err = consumer.Consume(func(msg jetstream.Msg) {
+		s.onStreamMessage(ctx, msg, ingest, ingestError)
+	}, jetstream.ConsumeErrHandler(func(_ jetstream.ConsumeContext, err error) {
+		ctx.GetLogger().Warnf("consume stream %s error: %v", s.cfg.Stream, err)
+	}))
+	if err != nil {
+		return fmt.Errorf("consume stream %s failed: %v", s.cfg.Stream, err)
+	}
+	return nil
+}
+
+func (s *Source) onStreamMessage(ctx api.StreamContext, msg jetstream.Msg, ingest api.BytesIngest, ingestError api.ErrorIngest) {
+	rcvTime := timex.GetNow()
+	md, err := msg.Metadata()
+	if err != nil {
+		ingestError(ctx, err)
+		return
+	}
+	s.Lock()
+	// the message is redelivered and is already ingested
+	if md.Sequence.Stream <= s.offset {
+		if md.Sequence.Stream <= s.committed || !s.ackOnCheckpoint {
+			// only the acknowledgement is lost because the message is covered by a completed checkpoint or the
+			// checkpoint is disabled
+			s.Unlock()
+			_ = msg.Ack()
+			return
+		}
+		// the checkpoint is not completed yet, keep the message pending and acknowledge it by the checkpoint
+		s.replacePending(msg, md.Sequence.Stream)
+		s.Unlock()
+		if err := msg.InProgress(); err != nil {
+			ctx.GetLogger().Warnf("mark message %d in progress failed: %v", md.Sequence.Stream, err)
+		}
+		return
+	}
+	s.offset = md.Sequence.Stream
+	if s.ackOnCheckpoint {
+		s.pending = append(s.pending, msg)
+	}
+	s.Unlock()
+	meta := map[string]any{
+		"subject":  msg.Subject(),
+		"stream":   md.Stream,
+		"sequence": md.Sequence.Stream,
+	}
+	if tid := msg.Headers().Get("traceparent"); tid != "" {
+		meta["traceId"] = tid
+	}
+	ingest(ctx, msg.Data(), meta, rcvTime)
+	if !s.ackOnCheckpoint {
+		if err := msg.Ack(); err != nil {
+			ctx.GetLogger().Warnf("ack message %d failed: %v", md.Sequence.Stream, err)
+		}
+	}
+}
+
+// replacePending replaces the pending message of the sequence by its redelivery so that the latest delivery is
+// acknowledged. It must be called with the lock.
+func (s *Source) replacePending(msg jetstream.Msg, seq uint64) {
+	for i, m := range s.pending {
+		if md, err := m.Metadata(); err == nil && md.Sequence.Stream == seq {
+			s.pending[i] = msg
+			return
+		}
+	}
+}
+
+func (s *Source) meta(msg *nats.Msg) map[string]any {
+	meta := map[string]any{
+		"subject": msg.Subject,
+	}
+	if tid := msg.Header.Get("traceparent"); tid != "" {
+		meta["traceId"] = tid
+	}
+	return meta
+}
+
+// EnableCheckpointCommit acknowledges the JetStream messages after the checkpoint completes. The messages must not be
+// redelivered while waiting for the checkpoint, so the ackWait must be longer than the checkpoint interval. If not
+// set, the ackWait is twice the interval and the pending messages are not limited.
+func (s *Source) EnableCheckpointCommit(checkpointInterval time.Duration) error {
+	if s.cfg.Stream == "" {
+		return nil
+	}
+	if s.cfg.AckWait == 0 {
+		s.cfg.AckWait = cast.DurationConf(2 * checkpointInterval)
+	} else if time.Duration(s.cfg.AckWait) <= checkpointInterval {
+		return fmt.Errorf("ackWait %v must be longer than the checkpointInterval %v of the rule", time.Duration(s.cfg.AckWait), checkpointInterval)
+	}
+	if s.cfg.MaxAckPending == 0 {
+		s.cfg.MaxAckPending = -1
+	}
+	s.ackOnCheckpoint = true
+	return nil
+}
+
+// Commit acknowledges all the pending messages whose stream sequence is not larger than the offset
+func (s *Source) Commit(ctx api.StreamContext, offset any) error {
+	seq, err := cast.ToUint64(offset, cast.CONVERT_SAMEKIND)
+	if err != nil {
+		return fmt.Errorf("invalid offset %v: %v", offset, err)
+	}
+	s.Lock()
+	i := 0
+	for ; i < len(s.pending); i++ {
+		md, err := s.pending[i].Metadata()
+		if err != nil || md.Sequence.Stream > seq {
+			break
+		}
+	}
+	acks := s.pending[:i]
+	s.pending = s.pending[i:]
+	s.committed = max(s.committed, seq)
+	s.Unlock()
+	for _, msg := range acks {
+		if err := msg.Ack(); err != nil {
+			return err
+		}
+	}
+	ctx.GetLogger().Debugf("acknowledged %d messages to stream sequence %d", len(acks), seq)
+	return nil
+}
+
+func (s *Source) GetOffset() (any, error) {
+	s.Lock()
+	defer s.Unlock()
+	return s.offset, nil
+}
+
+// Rewind restores the offset. The unacknowledged messages are redelivered by the server so that only the duplicate
+// messages are skipped.
+func (s *Source) Rewind(offset any) error {
+	seq, err := cast.ToUint64(offset, cast.CONVERT_SAMEKIND)
+	if err != nil {
+		return fmt.Errorf("%v can't be set as offset", offset)
+	}
+	s.Lock()
+	// the restored checkpoint is completed
+	s.offset = seq
+	s.committed = seq
+	s.Unlock()
+	return nil
+}
+
+func (s *Source) ResetOffset(_ map[string]any) error {
+	return fmt.Errorf("nats source does not support reset offset")
+}
+
+func (s *Source) Close(ctx api.StreamContext) error {
+	ctx.GetLogger().Infof("Closing nats source connector to subject %s.", s.cfg.Subject)
+	if s.sub != nil {
+		if err := s.sub.Unsubscribe(); err != nil {
+			ctx.GetLogger().Warnf("unsubscribe subject %s: %v", s.cfg.Subject, err)
+		}
+	}
+	if s.cc != nil {
+		s.cc.Stop()
+	}
+	return connection.DetachConnection(ctx, s.conId)
+}
+
+func GetSource() api.Source {
+	return &Source{}
+}
+
+var (
+	_ api.BytesSource             = &Source{}
+	_ api.Rewindable              = &Source{}
+	_ modules.CheckpointCommitter = &Source{}
+	_ util.PingableConn           = &Source{}
+)
@@ -0,0 +1,247 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/nats-io/nats-server/v2/server"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/topotest/mockclock"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+	"github.com/lf-edge/ekuiper/v2/pkg/mock"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+)
+
+func runServer(t *testing.T) *server.Server {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	opts.JetStream = true
+	opts.StoreDir = t.TempDir()
+	s := natsserver.RunServer(&opts)
+	t.Cleanup(s.Shutdown)
+	return s
+}
+
+func setup(t *testing.T) {
+	dataDir, err := conf.GetDataLoc()
+	require.NoError(t, err)
+	require.NoError(t, store.SetupDefault(dataDir))
+	require.NoError(t, connection.InitConnectionManager4Test())
+	modules.RegisterConnection("nats", CreateConnection)
+}
+
+func TestSourceSink(t *testing.T) {
+	s := runServer(t)
+	setup(t)
+	mc := mockclock.GetMockClock()
+	data := [][]byte{
+		[]byte(`{"temperature":22}`),
+		[]byte(`{"temperature":25}`),
+	}
+	result := []api.MessageTuple{
+		model.NewDefaultRawTuple(data[0], map[string]any{"subject": "sensors.a"}, mc.Now()),
+		model.NewDefaultRawTuple(data[1], map[string]any{"subject": "sensors.a"}, mc.Now()),
+	}
+	sinkDone := make(chan struct{})
+	mock.TestSourceConnector(t, GetSource(), map[string]any{
+		"server":     s.ClientURL(),
+		"datasource": "sensors.*",
+	}, result, func() {
+		defer close(sinkDone)
+		// wait for the subscription
+		time.Sleep(100 * time.Millisecond)
+		err := mock.RunBytesSinkCollect(GetSink().(api.BytesCollector), data, map[string]any{
+			"server":  s.ClientURL(),
+			"subject": "sensors.a",
+		})
+		assert.NoError(t, err)
+	})
+	<-sinkDone
+}
+
+func TestJetStream(t *testing.T) {
+	s := runServer(t)
+	setup(t)
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	nc, err := nats.Connect(s.ClientURL())
+	require.NoError(t, err)
+	defer nc.Close()
+	js, err := jetstream.New(nc)
+	require.NoError(t, err)
+	_, err = js.CreateStream(ctx, jetstream.StreamConfig{Name: "SENSORS", Subjects: []string{"sensors.>"}})
+	require.NoError(t, err)
+
+	// publish with acknowledgement
+	data := [][]byte{
+		[]byte(`{"temperature":22}`),
+		[]byte(`{"temperature":25}`),
+		[]byte(`{"temperature":28}`),
+	}
+	err = mock.RunBytesSinkCollect(GetSink().(api.BytesCollector), data, map[string]any{
+		"server":    s.ClientURL(),
+		"subject":   "sensors.a",
+		"jetstream": true,
+		"stream":    "SENSORS",
+	})
+	require.NoError(t, err)
+	// wrong expected stream is rejected by the server
+	err = mock.RunBytesSinkCollect(GetSink().(api.BytesCollector), data[:1], map[string]any{
+		"server":    s.ClientURL(),
+		"subject":   "sensors.a",
+		"jetstream": true,
+		"stream":    "OTHER",
+	})
+	require.Error(t, err)
+
+	// the ackWait is derived from the checkpoint interval if not set
+	src := GetSource().(*Source)
+	require.NoError(t, src.Provision(ctx, map[string]any{
+		"server":     s.ClientURL(),
+		"datasource": "sensors.>",
+		"stream":     "SENSORS",
+	}))
+	require.NoError(t, src.EnableCheckpointCommit(time.Minute))
+	require.Equal(t, cast.DurationConf(2*time.Minute), src.cfg.AckWait)
+	require.Equal(t, -1, src.cfg.MaxAckPending)
+
+	// consume with ack on checkpoint
+	src = GetSource().(*Source)
+	require.NoError(t, src.Provision(ctx, map[string]any{
+		"server":     s.ClientURL(),
+		"datasource": "sensors.>",
+		"stream":     "SENSORS",
+		"durable":    "ekuiper",
+		"ackWait":    "300ms",
+	}))
+	require.NoError(t, src.Connect(ctx, func(string, string) {}))
+	require.EqualError(t, src.EnableCheckpointCommit(time.Second), "ackWait 300ms must be longer than the checkpointInterval 1s of the rule")
+	require.NoError(t, src.EnableCheckpointCommit(100*time.Millisecond))
+	received := make(chan map[string]any, 10)
+	require.NoError(t, src.Subscribe(ctx, func(_ api.StreamContext, _ []byte, meta map[string]any, _ time.Time) {
+		received <- meta
+	}, func(_ api.StreamContext, err error) {
+		assert.NoError(t, err)
+	}))
+	for i := 1; i <= len(data); i++ {
+		select {
+		case meta := <-received:
+			assert.Equal(t, map[string]any{"subject": "sensors.a", "stream": "SENSORS", "sequence": uint64(i)}, meta)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "timeout")
+		}
+	}
+	offset, err := src.GetOffset()
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), offset)
+
+	consumer, err := js.Consumer(ctx, "SENSORS", "ekuiper")
+	require.NoError(t, err)
+	assertAckPending := func(expected int) {
+		assert.Eventually(t, func() bool {
+			info, err := consumer.Info(ctx)
+			return err == nil && info.NumAckPending == expected
+		}, 5*time.Second, 50*time.Millisecond)
+	}
+	assertAckPending(3)
+	// the messages redelivered before the checkpoint completes are neither ingested again nor acknowledged
+	assert.Eventually(t, func() bool {
+		info, err := consumer.Info(ctx)
+		return err == nil && info.NumRedelivered > 0
+	}, 5*time.Second, 50*time.Millisecond)
+	assertAckPending(3)
+	require.Empty(t, received)
+	require.NoError(t, src.Commit(ctx, uint64(2)))
+	assertAckPending(1)
+	require.NoError(t, src.Commit(ctx, uint64(3)))
+	assertAckPending(0)
+	require.NoError(t, src.Close(ctx))
+}
+
+func TestProvisionErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+		sink  bool
+	}{
+		{
+			name:  "no server",
+			props: map[string]any{"datasource": "a"},
+			err:   "server is required",
+		},
+		{
+			name:  "no subject",
+			props: map[string]any{"server": "nats://127.0.0.1:4222"},
+			err:   "subject is required",
+		},
+		{
+			name:  "durable without stream",
+			props: map[string]any{"server": "nats://127.0.0.1:4222", "datasource": "a", "durable": "d"},
+			err:   "durable is only supported for JetStream, please set stream",
+		},
+		{
+			name:  "invalid deliver policy",
+			props: map[string]any{"server": "nats://127.0.0.1:4222", "datasource": "a", "stream": "s", "deliverPolicy": "first"},
+			err:   "invalid deliverPolicy first, must be all, last or new",
+		},
+		{
+			name:  "invalid ack wait",
+			props: map[string]any{"server": "nats://127.0.0.1:4222", "datasource": "a", "stream": "s", "ackWait": "-1s"},
+			err:   "invalid ackWait -1s, must be positive",
+		},
+		{
+			name:  "invalid connect timeout",
+			props: map[string]any{"server": "nats://127.0.0.1:4222", "datasource": "a", "connectTimeout": "-1s"},
+			err:   "invalid connectTimeout -1s, must be positive",
+		},
+		{
+			name:  "sink wildcard",
+			props: map[string]any{"server": "nats://127.0.0.1:4222", "subject": "a.*"},
+			err:   "nats sink subject shouldn't contain wildcard * or >",
+			sink:  true,
+		},
+		{
+			name:  "sink stream without jetstream",
+			props: map[string]any{"server": "nats://127.0.0.1:4222", "subject": "a", "stream": "s"},
+			err:   "stream is only supported when jetstream is true",
+			sink:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.sink {
+				err = GetSink().Provision(ctx, tt.props)
+			} else {
+				err = GetSource().Provision(ctx, tt.props)
+			}
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,310 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opcua
+
+import (
+	"context"
+	"crypto/rsa"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gopcua/opcua"
+	"github.com/gopcua/opcua/id"
+	"github.com/gopcua/opcua/ua"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cert"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+)
+
+type ConnectionConfig struct {
+	// Endpoint is the server endpoint url such as opc.tcp://127.0.0.1:4840
+	Endpoint string `json:"endpoint"`
+	// SecurityPolicy is the short name of the security policy such as None or Basic256Sha256
+	SecurityPolicy string `json:"securityPolicy"`
+	// SecurityMode could be None, Sign or SignAndEncrypt. It defaults to SignAndEncrypt if the policy is not None.
+	SecurityMode   string            `json:"securityMode"`
+	Username       string            `json:"username"`
+	Password       string            `json:"password"`
+	ConnectTimeout cast.DurationConf `json:"connectTimeout"`
+	RequestTimeout cast.DurationConf `json:"requestTimeout"`
+}
+
+type Connection struct {
+	cli       *opcua.Client
+	id        string
+	cfg       *ConnectionConfig
+	opts      []opcua.Option
+	status    atomic.Value
+	scHandler atomic.Value
+	stateCh   chan opcua.ConnState
+	done      chan struct{}
+}
+
+func CreateConnection(_ api.StreamContext) modules.Connection {
+	conn := &Connection{}
+	conn.status.Store(modules.ConnectionStatus{Status: api.ConnectionConnecting})
+	return conn
+}
+
+func ValidateConfig(ctx api.StreamContext, props map[string]any) (*ConnectionConfig, []opcua.Option, error) {
+	c := &ConnectionConfig{
+		SecurityPolicy: "None",
+		ConnectTimeout: cast.DurationConf(5 * time.Second),
+		RequestTimeout: cast.DurationConf(10 * time.Second),
+	}
+	err := cast.MapToStruct(props, c)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if c.Endpoint == "" {
+		return nil, nil, fmt.Errorf("endpoint is required")
+	}
+	if !strings.HasPrefix(c.Endpoint, "opc.tcp://") {
+		return nil, nil, fmt.Errorf("invalid endpoint %s, must start with opc.tcp://", c.Endpoint)
+	}
+	if _, ok := ua.SecurityPolicyURIs[c.SecurityPolicy]; !ok {
+		return nil, nil, fmt.Errorf("invalid securityPolicy %s, must be None, Basic128Rsa15, Basic256, Basic256Sha256, Aes128Sha256RsaOaep or Aes256Sha256RsaPss", c.SecurityPolicy)
+	}
+	if c.SecurityMode == "" {
+		if c.SecurityPolicy == "None" {
+			c.SecurityMode = "None"
+		} else {
+			c.SecurityMode = "SignAndEncrypt"
+		}
+	}
+	switch c.SecurityMode {
+	case "None":
+		if c.SecurityPolicy != "None" {
+			return nil, nil, fmt.Errorf("securityMode None is only supported by securityPolicy None")
+		}
+	case "Sign", "SignAndEncrypt":
+		if c.SecurityPolicy == "None" {
+			return nil, nil, fmt.Errorf("securityMode %s is not supported by securityPolicy None", c.SecurityMode)
+		}
+	default:
+		return nil, nil, fmt.Errorf("invalid securityMode %s, must be None, Sign or SignAndEncrypt", c.SecurityMode)
+	}
+	if c.ConnectTimeout <= 0 {
+		return nil, nil, fmt.Errorf("invalid connectTimeout %v, must be positive", time.Duration(c.ConnectTimeout))
+	}
+	if c.RequestTimeout <= 0 {
+		return nil, nil, fmt.Errorf("invalid requestTimeout %v, must be positive", time.Duration(c.RequestTimeout))
+	}
+	opts := []opcua.Option{
+		opcua.SecurityPolicy(c.SecurityPolicy),
+		opcua.SecurityModeString(c.SecurityMode),
+		opcua.DialTimeout(time.Duration(c.ConnectTimeout)),
+		opcua.RequestTimeout(time.Duration(c.RequestTimeout)),
+		opcua.AutoReconnect(true),
+	}
+	if c.Username != "" {
+		opts = append(opts, opcua.AuthUsername(c.Username, c.Password))
+	} else {
+		opts = append(opts, opcua.AuthAnonymous())
+	}
+	// The client certificate signs and encrypts the secure channel
+	tlsConf, err := cert.GenTLSConfig(ctx, props)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tlsConf != nil && len(tlsConf.Certificates) > 0 {
+		crt := tlsConf.Certificates[0]
+		key, ok := crt.PrivateKey.(*rsa.PrivateKey)
+		if !ok {
+			return nil, nil, fmt.Errorf("the private key of the client certificate must be a RSA key")
+		}
+		opts = append(opts, opcua.Certificate(crt.Certificate[0]), opcua.PrivateKey(key))
+	} else if c.SecurityPolicy != "None" {
+		return nil, nil, fmt.Errorf("securityPolicy %s requires the client certificate, please set certificationPath and privateKeyPath", c.SecurityPolicy)
+	}
+	return c, opts, nil
+}
+
+func (conn *Connection) Provision(ctx api.StreamContext, conId string, props map[string]any) error {
+	c, opts, err := ValidateConfig(ctx, props)
+	if err != nil {
+		return err
+	}
+	conn.cfg = c
+	conn.opts = opts
+	conn.id = conId
+	conn.status.Store(modules.ConnectionStatus{Status: api.ConnectionConnecting})
+	return nil
+}
+
+func (conn *Connection) GetId(_ api.StreamContext) string {
+	return conn.id
+}
+
+// Dial selects the server endpoint which matches the security settings and then creates the session.
+// The client reconnects and recreates the subscriptions automatically after the connection is lost.
+func (conn *Connection) Dial(ctx api.StreamContext) error {
+	dctx, cancel := context.WithTimeout(ctx, time.Duration(conn.cfg.ConnectTimeout))
+	defer cancel()
+	endpoints, err := opcua.GetEndpoints(dctx, conn.cfg.Endpoint)
+	if err != nil {
+		return errorx.NewIOErr(fmt.Sprintf("found error when getting endpoints of %s: %s", conn.cfg.Endpoint, err))
+	}
+	ep, err := opcua.SelectEndpoint(endpoints, conn.cfg.SecurityPolicy, ua.MessageSecurityModeFromString(conn.cfg.SecurityMode))
+	if err != nil {
+		return err
+	}
+	authType := ua.UserTokenTypeAnonymous
+	if conn.cfg.Username != "" {
+		authType = ua.UserTokenTypeUserName
+	}
+	conn.stateCh = make(chan opcua.ConnState, 8)
+	conn.done = make(chan struct{})
+	opts := append([]opcua.Option{}, conn.opts...)
+	opts = append(opts, opcua.SecurityFromEndpoint(ep, authType), opcua.StateChangedCh(conn.stateCh))
+	cli, err := opcua.NewClient(conn.cfg.Endpoint, opts...)
+	if err != nil {
+		return err
+	}
+	if err := cli.Connect(dctx); err != nil {
+		return errorx.NewIOErr(fmt.Sprintf("found error when connecting for %s: %s", conn.cfg.Endpoint, err))
+	}
+	conn.cli = cli
+	conn.setStatus(ctx, api.ConnectionConnected, "")
+	go conn.watchState(ctx)
+	ctx.GetLogger().Infof("new opcua client created")
+	return nil
+}
+
+// watchState reports the state changes of the client after connected. The client blocks when changing state, so the
+// channel must be drained until the client is closed.
+func (conn *Connection) watchState(ctx api.StreamContext) {
+	for {
+		select {
+		case st := <-conn.stateCh:
+			switch st {
+			case opcua.Connected:
+				conn.setStatus(ctx, api.ConnectionConnected, "")
+			case opcua.Disconnected, opcua.Reconnecting:
+				conn.setStatus(ctx, api.ConnectionDisconnected, "opcua connection lost, reconnecting")
+			case opcua.Closed:
+				conn.setStatus(ctx, api.ConnectionDisconnected, "opcua connection closed")
+			}
+		case <-conn.done:
+			return
+		}
+	}
+}
+
+func (conn *Connection) setStatus(ctx api.StreamContext, status, msg string) {
+	conn.status.Store(modules.ConnectionStatus{Status: status, ErrMsg: msg})
+	if sch, ok := conn.scHandler.Load().(api.StatusChangeHandler); ok {
+		sch(status, msg)
+	}
+	ctx.GetLogger().Infof("opcua connection status changed to %s %s", status, msg)
+}
+
+func (conn *Connection) Status(_ api.StreamContext) modules.ConnectionStatus {
+	return conn.status.Load().(modules.ConnectionStatus)
+}
+
+func (conn *Connection) SetStatusChangeHandler(ctx api.StreamContext, sch api.StatusChangeHandler) {
+	st := conn.status.Load().(modules.ConnectionStatus)
+	sch(st.Status, st.ErrMsg)
+	conn.scHandler.Store(sch)
+	ctx.GetLogger().Infof("trigger status change handler")
+}
+
+func (conn *Connection) Ping(ctx api.StreamContext) error {
+	if conn.cli == nil {
+		return conn.Dial(ctx)
+	}
+	if conn.cli.State() != opcua.Connected {
+		return fmt.Errorf("opcua client is not connected")
+	}
+	_, err := conn.Read(ctx, []*ua.NodeID{ua.NewNumericNodeID(0, id.Server_ServerStatus_State)})
+	return err
+}
+
+func (conn *Connection) Close(ctx api.StreamContext) error {
+	if conn == nil || conn.cli == nil {
+		return nil
+	}
+	err := conn.cli.Close(ctx)
+	close(conn.done)
+	return err
+}
+
+// OPC UA features
+
+// Read reads the value attribute of the nodes in a single request
+func (conn *Connection) Read(ctx api.StreamContext, nodeIds []*ua.NodeID) ([]*ua.DataValue, error) {
+	if conn == nil || conn.cli == nil || conn.cli.State() != opcua.Connected {
+		return nil, errorx.NewIOErr("opcua client is not connected")
+	}
+	req := &ua.ReadRequest{
+		TimestampsToReturn: ua.TimestampsToReturnBoth,
+		NodesToRead:        make([]*ua.ReadValueID, len(nodeIds)),
+	}
+	for i, nid := range nodeIds {
+		req.NodesToRead[i] = &ua.ReadValueID{NodeID: nid, AttributeID: ua.AttributeIDValue}
+	}
+	resp, err := conn.cli.Read(ctx, req)
+	if err != nil {
+		return nil, errorx.NewIOErr(fmt.Sprintf("read from opcua server failed: %s", err))
+	}
+	if len(resp.Results) != len(nodeIds) {
+		return nil, fmt.Errorf("read %d nodes but got %d results", len(nodeIds), len(resp.Results))
+	}
+	return resp.Results, nil
+}
+
+// Subscribe creates a subscription and monitors the value attribute of the nodes. The client handle of each monitored
+// item is the index of the node.
+func (conn *Connection) Subscribe(ctx api.StreamContext, params *opcua.SubscriptionParameters, nodeIds []*ua.NodeID, samplingInterval time.Duration, notifyCh chan<- *opcua.PublishNotificationData) (*opcua.Subscription, error) {
+	sub, err := conn.cli.Subscribe(ctx, params, notifyCh)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*ua.MonitoredItemCreateRequest, len(nodeIds))
+	for i, nid := range nodeIds {
+		items[i] = opcua.NewMonitoredItemCreateRequestWithDefaults(nid, ua.AttributeIDValue, uint32(i))
+		items[i].RequestedParameters.SamplingInterval = float64(samplingInterval.Milliseconds())
+	}
+	resp, err := sub.Monitor(ctx, ua.TimestampsToReturnBoth, items...)
+	if err == nil {
+		for i, r := range resp.Results {
+			if r.StatusCode != ua.StatusOK {
+				err = fmt.Errorf("monitor node %s failed: %s", nodeIds[i], r.StatusCode)
+				break
+			}
+		}
+	}
+	if err != nil {
+		_ = sub.Cancel(ctx)
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Browse returns the forward hierarchical references of the node
+func (conn *Connection) Browse(ctx api.StreamContext, nodeId *ua.NodeID) ([]*ua.ReferenceDescription, error) {
+	if conn == nil || conn.cli == nil {
+		return nil, errorx.NewIOErr("opcua client is not connected")
+	}
+	return conn.cli.Node(nodeId).References(ctx, id.HierarchicalReferences, ua.BrowseDirectionForward, ua.NodeClassAll, true)
+}
+
+var _ modules.StatefulDialer = &Connection{}
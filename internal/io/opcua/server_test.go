@@ -0,0 +1,110 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opcua
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/id"
+	"github.com/gopcua/opcua/server"
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/require"
+)
+
+// testServer is an in-process OPC UA server with the variables Temperature and Running under the Plant object
+type testServer struct {
+	srv         *server.Server
+	endpoint    string
+	temperature atomic.Value
+	tempId      *ua.NodeID
+}
+
+func newTestServer(t *testing.T) *testServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	require.NoError(t, l.Close())
+
+	s := server.New(
+		server.EnableSecurity("None", ua.MessageSecurityModeNone),
+		server.EnableAuthMode(ua.UserTokenTypeAnonymous),
+		server.EndPoint("127.0.0.1", port),
+	)
+	ts := &testServer{srv: s, endpoint: fmt.Sprintf("opc.tcp://127.0.0.1:%d", port)}
+	ts.temperature.Store(22.5)
+
+	root, err := s.Namespace(0)
+	require.NoError(t, err)
+	ns := server.NewNodeNameSpace(s, "Plant")
+	s.AddNamespace(ns)
+	root.Objects().AddRef(ns.Objects(), id.HasComponent, true)
+	temp := ns.AddNewVariableStringNode("Temperature", func() *ua.DataValue {
+		return server.DataValueFromValue(ts.temperature.Load())
+	})
+	ns.Objects().AddRef(temp, id.HasComponent, true)
+	ts.tempId = temp.ID()
+	running := ns.AddNewVariableStringNode("Running", true)
+	ns.Objects().AddRef(running, id.HasComponent, true)
+	counter := ns.AddNewVariableStringNode("Counter", uint16(7))
+	ns.Objects().AddRef(counter, id.HasComponent, true)
+
+	require.NoError(t, s.Start(context.Background()))
+	t.Cleanup(func() {
+		_ = s.Close()
+	})
+	return ts
+}
+
+// setTemperature changes the value and notifies the monitored items
+func (ts *testServer) setTemperature(v float64) {
+	ts.temperature.Store(v)
+	ts.srv.ChangeNotification(ts.tempId)
+}
+
+// genRawCert generates a self-signed client certificate in the raw format of the tls properties
+func genRawCert(t *testing.T) (string, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	uri, err := url.Parse("urn:ekuiper:test:client")
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: "ekuiper test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment | x509.KeyUsageDataEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		URIs:                  []*url.URL{uri},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	crtPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return base64.StdEncoding.EncodeToString(crtPem), base64.StdEncoding.EncodeToString(keyPem)
+}
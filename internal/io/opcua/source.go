@@ -0,0 +1,334 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opcua
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gopcua/opcua"
+	"github.com/gopcua/opcua/id"
+	"github.com/gopcua/opcua/ua"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+type NodeConf struct {
+	// NodeId is the node id in the string format such as ns=2;s=Temperature
+	NodeId string `json:"nodeId"`
+	// Name is the field name in the output tuple. It defaults to the node id.
+	Name string `json:"name"`
+}
+
+type SourceConf struct {
+	Nodes []*NodeConf `json:"nodes"`
+	// PublishingInterval is the interval the server publishes the notifications of the subscription
+	PublishingInterval cast.DurationConf `json:"publishingInterval"`
+	// SamplingInterval is the interval the server samples the monitored nodes
+	SamplingInterval cast.DurationConf `json:"samplingInterval"`
+}
+
+// reader is the common part of the subscription source and the pull source
+type reader struct {
+	cfg   *SourceConf
+	props map[string]any
+	ids   []*ua.NodeID
+	names []string
+
+	cli   *Connection
+	conId string
+}
+
+func (r *reader) Provision(ctx api.StreamContext, props map[string]any) error {
+	cfg := &SourceConf{
+		PublishingInterval: cast.DurationConf(time.Second),
+		SamplingInterval:   cast.DurationConf(time.Second),
+	}
+	err := cast.MapToStruct(props, cfg)
+	if err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if len(cfg.Nodes) == 0 {
+		return fmt.Errorf("nodes is required")
+	}
+	if cfg.PublishingInterval <= 0 {
+		return fmt.Errorf("invalid publishingInterval %v, must be positive", time.Duration(cfg.PublishingInterval))
+	}
+	if cfg.SamplingInterval < 0 {
+		return fmt.Errorf("invalid samplingInterval %v, must not be negative", time.Duration(cfg.SamplingInterval))
+	}
+	ids := make([]*ua.NodeID, len(cfg.Nodes))
+	names := make([]string, len(cfg.Nodes))
+	exists := make(map[string]struct{}, len(cfg.Nodes))
+	for i, n := range cfg.Nodes {
+		ids[i], err = ua.ParseNodeID(n.NodeId)
+		if err != nil {
+			return fmt.Errorf("invalid nodeId %s: %v", n.NodeId, err)
+		}
+		names[i] = n.Name
+		if names[i] == "" {
+			names[i] = n.NodeId
+		}
+		if _, ok := exists[names[i]]; ok {
+			return fmt.Errorf("duplicate node name %s", names[i])
+		}
+		exists[names[i]] = struct{}{}
+	}
+	_, _, err = ValidateConfig(ctx, props)
+	if err != nil {
+		return err
+	}
+	r.cfg = cfg
+	r.props = props
+	r.ids = ids
+	r.names = names
+	return nil
+}
+
+func (r *reader) Ping(ctx api.StreamContext, props map[string]any) error {
+	cli := &Connection{}
+	err := cli.Provision(ctx, "test", props)
+	if err != nil {
+		return err
+	}
+	defer cli.Close(ctx)
+	return cli.Ping(ctx)
+}
+
+// BrowseNode is a node referenced by the browsed node
+type BrowseNode struct {
+	NodeId      string `json:"nodeId"`
+	BrowseName  string `json:"browseName"`
+	DisplayName string `json:"displayName"`
+	NodeClass   string `json:"nodeClass"`
+}
+
+// Browse returns the children of the node set by the nodeId property. It browses the Objects folder by default.
+func (r *reader) Browse(ctx api.StreamContext, props map[string]any) (any, error) {
+	nid := ua.NewNumericNodeID(0, id.ObjectsFolder)
+	if v, ok := props["nodeId"]; ok {
+		s, err := cast.ToString(v, cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return nil, fmt.Errorf("invalid nodeId %v", v)
+		}
+		if s != "" {
+			nid, err = ua.ParseNodeID(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid nodeId %s: %v", s, err)
+			}
+		}
+	}
+	cli := &Connection{}
+	err := cli.Provision(ctx, "browse", props)
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close(ctx)
+	err = cli.Dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := cli.Browse(ctx, nid)
+	if err != nil {
+		return nil, fmt.Errorf("browse node %s failed: %v", nid, err)
+	}
+	result := make([]*BrowseNode, 0, len(refs))
+	for _, ref := range refs {
+		n := &BrowseNode{
+			NodeId:    ref.NodeID.NodeID.String(),
+			NodeClass: nodeClassName(ref.NodeClass),
+		}
+		if ref.BrowseName != nil {
+			n.BrowseName = ref.BrowseName.Name
+		}
+		if ref.DisplayName != nil {
+			n.DisplayName = ref.DisplayName.Text
+		}
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+func (r *reader) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	ctx.GetLogger().Infof("Connecting to opcua server")
+	refId := fmt.Sprintf("%s-%s-opcua-source", ctx.GetRuleId(), ctx.GetOpId())
+	cw, err := connection.FetchConnection(ctx, refId, "opcua", r.props, sch)
+	if err != nil {
+		return err
+	}
+	r.conId = cw.ID
+	conn, err := cw.Wait(ctx)
+	if conn == nil {
+		return fmt.Errorf("opcua client not ready: %v", err)
+	}
+	c, ok := conn.(*Connection)
+	if !ok {
+		return fmt.Errorf("connection %s should be opcua connection", r.conId)
+	}
+	r.cli = c
+	return err
+}
+
+// collect converts the data values to a tuple. The values with bad status are skipped.
+func (r *reader) collect(ctx api.StreamContext, result map[string]any, timestamps map[string]any, index int, dv *ua.DataValue) error {
+	if index < 0 || index >= len(r.names) {
+		return fmt.Errorf("unknown monitored item %d", index)
+	}
+	name := r.names[index]
+	if dv == nil || dv.Status != ua.StatusOK {
+		status := ua.StatusBad
+		if dv != nil {
+			status = dv.Status
+		}
+		ctx.GetLogger().Warnf("read node %s got bad status %s", r.ids[index], status)
+		return fmt.Errorf("node %s has bad status %s", r.ids[index], status)
+	}
+	if dv.Value == nil {
+		result[name] = nil
+	} else {
+		result[name] = convertValue(dv.Value.Value())
+	}
+	if !dv.SourceTimestamp.IsZero() {
+		timestamps[name] = dv.SourceTimestamp
+	}
+	return nil
+}
+
+func (r *reader) Close(ctx api.StreamContext) error {
+	ctx.GetLogger().Infof("Closing opcua source")
+	if r.conId != "" {
+		return connection.DetachConnection(ctx, r.conId)
+	}
+	return nil
+}
+
+// Source subscribes to the value changes of the nodes by the monitored items of a server side subscription. Each
+// publish notification produces one tuple which contains the changed values.
+type Source struct {
+	reader
+	sub *opcua.Subscription
+}
+
+func (s *Source) Subscribe(ctx api.StreamContext, ingest api.TupleIngest, ingestError api.ErrorIngest) error {
+	notifyCh := make(chan *opcua.PublishNotificationData, 16)
+	sub, err := s.cli.Subscribe(ctx, &opcua.SubscriptionParameters{
+		Interval: time.Duration(s.cfg.PublishingInterval),
+	}, s.ids, time.Duration(s.cfg.SamplingInterval), notifyCh)
+	if err != nil {
+		return fmt.Errorf("subscribe to opcua nodes failed: %v", err)
+	}
+	s.sub = sub
+	ctx.GetLogger().Infof("opcua subscription %d created", sub.SubscriptionID)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case n := <-notifyCh:
+				s.onNotification(ctx, n, ingest, ingestError)
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *Source) onNotification(ctx api.StreamContext, n *opcua.PublishNotificationData, ingest api.TupleIngest, ingestError api.ErrorIngest) {
+	if n.Error != nil {
+		ingestError(ctx, n.Error)
+		return
+	}
+	switch v := n.Value.(type) {
+	case *ua.DataChangeNotification:
+		rcvTime := timex.GetNow()
+		result := make(map[string]any, len(v.MonitoredItems))
+		timestamps := make(map[string]any, len(v.MonitoredItems))
+		var firstErr error
+		for _, item := range v.MonitoredItems {
+			err := s.collect(ctx, result, timestamps, int(item.ClientHandle), item.Value)
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		if len(result) == 0 {
+			if firstErr != nil {
+				ingestError(ctx, firstErr)
+			}
+			return
+		}
+		ingest(ctx, result, map[string]any{"subscriptionId": n.SubscriptionID, "sourceTimestamps": timestamps}, rcvTime)
+	case *ua.StatusChangeNotification:
+		ctx.GetLogger().Infof("opcua subscription %d status changed to %s", n.SubscriptionID, v.Status)
+	default:
+		ctx.GetLogger().Debugf("ignore opcua notification %T", n.Value)
+	}
+}
+
+func (s *Source) Close(ctx api.StreamContext) error {
+	if s.sub != nil {
+		if err := s.sub.Cancel(ctx); err != nil {
+			ctx.GetLogger().Warnf("cancel opcua subscription failed: %v", err)
+		}
+	}
+	return s.reader.Close(ctx)
+}
+
+// PullSource reads the values of the nodes in a single request in each interval and produces one tuple per read
+type PullSource struct {
+	reader
+}
+
+func (s *PullSource) Pull(ctx api.StreamContext, trigger time.Time, ingest api.TupleIngest, ingestError api.ErrorIngest) {
+	dvs, err := s.cli.Read(ctx, s.ids)
+	if err != nil {
+		ingestError(ctx, err)
+		return
+	}
+	result := make(map[string]any, len(dvs))
+	timestamps := make(map[string]any, len(dvs))
+	var firstErr error
+	for i, dv := range dvs {
+		err = s.collect(ctx, result, timestamps, i, dv)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	// report the error only if none of the nodes is read successfully
+	if len(result) == 0 {
+		ingestError(ctx, firstErr)
+		return
+	}
+	ingest(ctx, result, map[string]any{"sourceTimestamps": timestamps}, trigger)
+}
+
+func GetSource() api.Source {
+	return &Source{}
+}
+
+func GetPullSource() api.Source {
+	return &PullSource{}
+}
+
+var (
+	_ api.TupleSource     = &Source{}
+	_ api.PullTupleSource = &PullSource{}
+	_ util.PingableConn   = &Source{}
+	_ util.PingableConn   = &PullSource{}
+	_ util.Browsable      = &Source{}
+	_ util.Browsable      = &PullSource{}
+)
@@ -0,0 +1,265 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opcua
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+)
+
+func setup(t *testing.T) {
+	dataDir, err := conf.GetDataLoc()
+	require.NoError(t, err)
+	require.NoError(t, store.SetupDefault(dataDir))
+	require.NoError(t, connection.InitConnectionManager4Test())
+	modules.RegisterConnection("opcua", CreateConnection)
+}
+
+var testNodes = []map[string]any{
+	{"nodeId": "ns=1;s=Temperature", "name": "temperature"},
+	{"nodeId": "ns=1;s=Running", "name": "running"},
+	{"nodeId": "ns=1;s=Counter"},
+}
+
+type pullResult struct {
+	data any
+	meta map[string]any
+	err  error
+}
+
+func pull(ctx api.StreamContext, s *PullSource) pullResult {
+	var r pullResult
+	s.Pull(ctx, time.Now(), func(_ api.StreamContext, data any, meta map[string]any, _ time.Time) {
+		r.data = data
+		r.meta = meta
+	}, func(_ api.StreamContext, err error) {
+		r.err = err
+	})
+	return r
+}
+
+func TestPull(t *testing.T) {
+	ts := newTestServer(t)
+	setup(t)
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	s := GetPullSource().(*PullSource)
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"endpoint": ts.endpoint,
+		"nodes":    testNodes,
+	}))
+	require.NoError(t, s.Connect(ctx, func(string, string) {}))
+	r := pull(ctx, s)
+	require.NoError(t, r.err)
+	require.Equal(t, map[string]any{
+		"temperature":    22.5,
+		"running":        true,
+		"ns=1;s=Counter": int64(7),
+	}, r.data)
+	require.Contains(t, r.meta, "sourceTimestamps")
+
+	ts.setTemperature(25)
+	r = pull(ctx, s)
+	require.NoError(t, r.err)
+	require.Equal(t, 25.0, r.data.(map[string]any)["temperature"])
+	require.NoError(t, s.Close(ctx))
+
+	// all nodes are unknown
+	s = GetPullSource().(*PullSource)
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"endpoint": ts.endpoint,
+		"nodes":    []map[string]any{{"nodeId": "ns=1;s=NotExist"}},
+	}))
+	require.NoError(t, s.Connect(ctx, func(string, string) {}))
+	r = pull(ctx, s)
+	require.ErrorContains(t, r.err, "StatusBadNodeIDUnknown")
+	require.NoError(t, s.Close(ctx))
+}
+
+func TestSubscribe(t *testing.T) {
+	ts := newTestServer(t)
+	setup(t)
+	ctx, cancel := mockContext.NewMockContext("rule1", "op1").WithCancel()
+	defer cancel()
+	s := GetSource().(*Source)
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"endpoint":           ts.endpoint,
+		"nodes":              testNodes,
+		"publishingInterval": "100ms",
+		"samplingInterval":   "50ms",
+	}))
+	var statuses []string
+	require.NoError(t, s.Connect(ctx, func(status string, _ string) {
+		statuses = append(statuses, status)
+	}))
+	received := make(chan map[string]any, 10)
+	require.NoError(t, s.Subscribe(ctx, func(_ api.StreamContext, data any, meta map[string]any, _ time.Time) {
+		assert.Contains(t, meta, "subscriptionId")
+		received <- data.(map[string]any)
+	}, func(_ api.StreamContext, err error) {
+		assert.NoError(t, err)
+	}))
+	expect := func(exp map[string]any) {
+		select {
+		case data := <-received:
+			require.Equal(t, exp, data)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "timeout")
+		}
+	}
+	// the server sends the initial values once the monitored items are created
+	expect(map[string]any{
+		"temperature":    22.5,
+		"running":        true,
+		"ns=1;s=Counter": int64(7),
+	})
+	// only the changed values are sent afterward
+	for _, v := range []float64{30, 31.5} {
+		ts.setTemperature(v)
+		expect(map[string]any{"temperature": v})
+	}
+	require.Contains(t, statuses, api.ConnectionConnected)
+	require.NoError(t, s.Close(ctx))
+}
+
+func TestSecurity(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	crt, key := genRawCert(t)
+	props := map[string]any{
+		"endpoint":         ts.endpoint,
+		"securityPolicy":   "Basic256Sha256",
+		"certificationRaw": crt,
+		"privateKeyRaw":    key,
+	}
+	c, _, err := ValidateConfig(ctx, props)
+	require.NoError(t, err)
+	require.Equal(t, "SignAndEncrypt", c.SecurityMode)
+	// the test server only supports the None policy
+	s := GetPullSource().(*PullSource)
+	require.EqualError(t, s.Ping(ctx, props), "opcua: no matching endpoint found for policy http://opcfoundation.org/UA/SecurityPolicy#Basic256Sha256 and mode MessageSecurityModeSignAndEncrypt")
+	props["securityPolicy"] = "None"
+	require.NoError(t, s.Ping(ctx, props))
+
+	// the certificate must be valid
+	props["securityPolicy"] = "Basic256Sha256"
+	props["privateKeyRaw"] = "invalid"
+	_, _, err = ValidateConfig(ctx, props)
+	require.Error(t, err)
+}
+
+func TestBrowse(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	s := GetSource().(*Source)
+	result, err := s.Browse(ctx, map[string]any{"endpoint": ts.endpoint})
+	require.NoError(t, err)
+	require.Contains(t, result, &BrowseNode{NodeId: "ns=1;i=85", BrowseName: "Plant", DisplayName: "Plant", NodeClass: "Object"})
+	require.Contains(t, result, &BrowseNode{NodeId: "i=2253", BrowseName: "Server", DisplayName: "Server", NodeClass: "Object"})
+
+	result, err = s.Browse(ctx, map[string]any{"endpoint": ts.endpoint, "nodeId": "ns=1;i=85"})
+	require.NoError(t, err)
+	require.Equal(t, []*BrowseNode{
+		{NodeId: "ns=1;s=Temperature", BrowseName: "Temperature", DisplayName: "Temperature", NodeClass: "Variable"},
+		{NodeId: "ns=1;s=Running", BrowseName: "Running", DisplayName: "Running", NodeClass: "Variable"},
+		{NodeId: "ns=1;s=Counter", BrowseName: "Counter", DisplayName: "Counter", NodeClass: "Variable"},
+	}, result)
+
+	_, err = s.Browse(ctx, map[string]any{"endpoint": ts.endpoint, "nodeId": "i=abc"})
+	require.Error(t, err)
+	addr := ts.endpoint
+	require.NoError(t, ts.srv.Close())
+	_, err = s.Browse(ctx, map[string]any{"endpoint": addr})
+	require.Error(t, err)
+}
+
+func TestProvisionErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "no nodes",
+			props: map[string]any{"endpoint": "opc.tcp://127.0.0.1:4840"},
+			err:   "nodes is required",
+		},
+		{
+			name:  "invalid node id",
+			props: map[string]any{"endpoint": "opc.tcp://127.0.0.1:4840", "nodes": []map[string]any{{"nodeId": "i=abc"}}},
+			err:   "invalid nodeId i=abc: opcua: invalid numeric id: i=abc",
+		},
+		{
+			name:  "duplicate name",
+			props: map[string]any{"endpoint": "opc.tcp://127.0.0.1:4840", "nodes": []map[string]any{{"nodeId": "i=1", "name": "a"}, {"nodeId": "i=2", "name": "a"}}},
+			err:   "duplicate node name a",
+		},
+		{
+			name:  "invalid publishing interval",
+			props: map[string]any{"endpoint": "opc.tcp://127.0.0.1:4840", "nodes": testNodes, "publishingInterval": "0s"},
+			err:   "invalid publishingInterval 0s, must be positive",
+		},
+		{
+			name:  "no endpoint",
+			props: map[string]any{"nodes": testNodes},
+			err:   "endpoint is required",
+		},
+		{
+			name:  "invalid endpoint",
+			props: map[string]any{"endpoint": "tcp://127.0.0.1:4840", "nodes": testNodes},
+			err:   "invalid endpoint tcp://127.0.0.1:4840, must start with opc.tcp://",
+		},
+		{
+			name:  "invalid security policy",
+			props: map[string]any{"endpoint": "opc.tcp://127.0.0.1:4840", "nodes": testNodes, "securityPolicy": "Basic512"},
+			err:   "invalid securityPolicy Basic512, must be None, Basic128Rsa15, Basic256, Basic256Sha256, Aes128Sha256RsaOaep or Aes256Sha256RsaPss",
+		},
+		{
+			name:  "invalid security mode",
+			props: map[string]any{"endpoint": "opc.tcp://127.0.0.1:4840", "nodes": testNodes, "securityMode": "Encrypt"},
+			err:   "invalid securityMode Encrypt, must be None, Sign or SignAndEncrypt",
+		},
+		{
+			name:  "security mode without policy",
+			props: map[string]any{"endpoint": "opc.tcp://127.0.0.1:4840", "nodes": testNodes, "securityMode": "Sign"},
+			err:   "securityMode Sign is not supported by securityPolicy None",
+		},
+		{
+			name:  "no certificate",
+			props: map[string]any{"endpoint": "opc.tcp://127.0.0.1:4840", "nodes": testNodes, "securityPolicy": "Basic256Sha256"},
+			err:   "securityPolicy Basic256Sha256 requires the client certificate, please set certificationPath and privateKeyPath",
+		},
+		{
+			name:  "invalid request timeout",
+			props: map[string]any{"endpoint": "opc.tcp://127.0.0.1:4840", "nodes": testNodes, "requestTimeout": "-1s"},
+			err:   "invalid requestTimeout -1s, must be positive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, GetSource().Provision(ctx, tt.props), tt.err)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opcua
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gopcua/opcua/ua"
+)
+
+// convertValue converts the variant value to the types of eKuiper. The integers are converted to int64 except uint64,
+// the structures such as LocalizedText and NodeID are converted to string and the arrays are converted to []any.
+func convertValue(v any) any {
+	switch vt := v.(type) {
+	case nil, bool, string, int64, uint64, float64, []byte:
+		return v
+	case int8:
+		return int64(vt)
+	case int16:
+		return int64(vt)
+	case int32:
+		return int64(vt)
+	case uint8:
+		return int64(vt)
+	case uint16:
+		return int64(vt)
+	case uint32:
+		return int64(vt)
+	case float32:
+		// format with 32 bits precision to avoid the noise digits like 3.140000104904175
+		f, _ := strconv.ParseFloat(strconv.FormatFloat(float64(vt), 'g', -1, 32), 64)
+		return f
+	case *ua.LocalizedText:
+		return vt.Text
+	case *ua.QualifiedName:
+		return vt.Name
+	case *ua.NodeID:
+		return vt.String()
+	case *ua.ExpandedNodeID:
+		return vt.NodeID.String()
+	case *ua.GUID:
+		return vt.String()
+	case ua.StatusCode:
+		return int64(vt)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice {
+		result := make([]any, rv.Len())
+		for i := range result {
+			result[i] = convertValue(rv.Index(i).Interface())
+		}
+		return result
+	}
+	return v
+}
+
+func nodeClassName(nc ua.NodeClass) string {
+	return strings.TrimPrefix(nc.String(), "NodeClass")
+}
@@ -0,0 +1,316 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"encoding/hex"
+	"strings"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// group is the flattened rows of a scope which share the same resource and scope as the metadata
+type group struct {
+	meta map[string]any
+	rows []map[string]any
+}
+
+type scopeInfo struct {
+	resource  map[string]any
+	scope     map[string]any
+	schemaUrl string
+}
+
+func newScopeInfo(res *resourcepb.Resource, resSchemaUrl string, scope *commonpb.InstrumentationScope, scopeSchemaUrl string) *scopeInfo {
+	si := &scopeInfo{
+		resource: attributesToMap(res.GetAttributes()),
+		scope: map[string]any{
+			"name":       scope.GetName(),
+			"version":    scope.GetVersion(),
+			"attributes": attributesToMap(scope.GetAttributes()),
+		},
+		schemaUrl: scopeSchemaUrl,
+	}
+	if si.schemaUrl == "" {
+		si.schemaUrl = resSchemaUrl
+	}
+	return si
+}
+
+func (si *scopeInfo) group(signal string, rows []map[string]any, resourceAsFields bool) group {
+	if resourceAsFields {
+		for _, row := range rows {
+			row["resource"] = si.resource
+			row["scope"] = si.scope
+		}
+	}
+	return group{
+		meta: map[string]any{
+			"signal":    signal,
+			"resource":  si.resource,
+			"scope":     si.scope,
+			"schemaUrl": si.schemaUrl,
+		},
+		rows: rows,
+	}
+}
+
+func flattenMetrics(req *colmetricspb.ExportMetricsServiceRequest, resourceAsFields bool) []group {
+	var result []group
+	for _, rm := range req.GetResourceMetrics() {
+		for _, sm := range rm.GetScopeMetrics() {
+			si := newScopeInfo(rm.GetResource(), rm.GetSchemaUrl(), sm.GetScope(), sm.GetSchemaUrl())
+			var rows []map[string]any
+			for _, m := range sm.GetMetrics() {
+				rows = append(rows, flattenMetric(m)...)
+			}
+			if len(rows) > 0 {
+				result = append(result, si.group(SignalMetrics, rows, resourceAsFields))
+			}
+		}
+	}
+	return result
+}
+
+// flattenMetric converts each data point of the metric to a row
+func flattenMetric(m *metricspb.Metric) []map[string]any {
+	var rows []map[string]any
+	newRow := func(typ string, attrs []*commonpb.KeyValue, start, ts uint64) map[string]any {
+		row := map[string]any{
+			"name":              m.GetName(),
+			"description":       m.GetDescription(),
+			"unit":              m.GetUnit(),
+			"type":              typ,
+			"attributes":        attributesToMap(attrs),
+			"startTimeUnixNano": int64(start),
+			"timeUnixNano":      int64(ts),
+		}
+		rows = append(rows, row)
+		return row
+	}
+	switch data := m.GetData().(type) {
+	case *metricspb.Metric_Gauge:
+		for _, dp := range data.Gauge.GetDataPoints() {
+			row := newRow("gauge", dp.GetAttributes(), dp.GetStartTimeUnixNano(), dp.GetTimeUnixNano())
+			row["value"] = numberValue(dp)
+		}
+	case *metricspb.Metric_Sum:
+		for _, dp := range data.Sum.GetDataPoints() {
+			row := newRow("sum", dp.GetAttributes(), dp.GetStartTimeUnixNano(), dp.GetTimeUnixNano())
+			row["value"] = numberValue(dp)
+			row["isMonotonic"] = data.Sum.GetIsMonotonic()
+			row["aggregationTemporality"] = temporality(data.Sum.GetAggregationTemporality())
+		}
+	case *metricspb.Metric_Histogram:
+		for _, dp := range data.Histogram.GetDataPoints() {
+			row := newRow("histogram", dp.GetAttributes(), dp.GetStartTimeUnixNano(), dp.GetTimeUnixNano())
+			row["count"] = int64(dp.GetCount())
+			row["sum"] = dp.GetSum()
+			if dp.Min != nil {
+				row["min"] = dp.GetMin()
+			}
+			if dp.Max != nil {
+				row["max"] = dp.GetMax()
+			}
+			row["bucketCounts"] = uintsToList(dp.GetBucketCounts())
+			bounds := make([]any, 0, len(dp.GetExplicitBounds()))
+			for _, b := range dp.GetExplicitBounds() {
+				bounds = append(bounds, b)
+			}
+			row["explicitBounds"] = bounds
+			row["aggregationTemporality"] = temporality(data.Histogram.GetAggregationTemporality())
+		}
+	case *metricspb.Metric_ExponentialHistogram:
+		for _, dp := range data.ExponentialHistogram.GetDataPoints() {
+			row := newRow("exponentialHistogram", dp.GetAttributes(), dp.GetStartTimeUnixNano(), dp.GetTimeUnixNano())
+			row["count"] = int64(dp.GetCount())
+			row["sum"] = dp.GetSum()
+			if dp.Min != nil {
+				row["min"] = dp.GetMin()
+			}
+			if dp.Max != nil {
+				row["max"] = dp.GetMax()
+			}
+			row["scale"] = int64(dp.GetScale())
+			row["zeroCount"] = int64(dp.GetZeroCount())
+			row["positive"] = map[string]any{
+				"offset":       int64(dp.GetPositive().GetOffset()),
+				"bucketCounts": uintsToList(dp.GetPositive().GetBucketCounts()),
+			}
+			row["negative"] = map[string]any{
+				"offset":       int64(dp.GetNegative().GetOffset()),
+				"bucketCounts": uintsToList(dp.GetNegative().GetBucketCounts()),
+			}
+			row["aggregationTemporality"] = temporality(data.ExponentialHistogram.GetAggregationTemporality())
+		}
+	case *metricspb.Metric_Summary:
+		for _, dp := range data.Summary.GetDataPoints() {
+			row := newRow("summary", dp.GetAttributes(), dp.GetStartTimeUnixNano(), dp.GetTimeUnixNano())
+			row["count"] = int64(dp.GetCount())
+			row["sum"] = dp.GetSum()
+			quantiles := make([]any, 0, len(dp.GetQuantileValues()))
+			for _, q := range dp.GetQuantileValues() {
+				quantiles = append(quantiles, map[string]any{"quantile": q.GetQuantile(), "value": q.GetValue()})
+			}
+			row["quantileValues"] = quantiles
+		}
+	}
+	return rows
+}
+
+func numberValue(dp *metricspb.NumberDataPoint) any {
+	if v, ok := dp.GetValue().(*metricspb.NumberDataPoint_AsInt); ok {
+		return v.AsInt
+	}
+	return dp.GetAsDouble()
+}
+
+func temporality(t metricspb.AggregationTemporality) string {
+	return enumName(t.String(), "AGGREGATION_TEMPORALITY_")
+}
+
+func flattenLogs(req *collogspb.ExportLogsServiceRequest, resourceAsFields bool) []group {
+	var result []group
+	for _, rl := range req.GetResourceLogs() {
+		for _, sl := range rl.GetScopeLogs() {
+			si := newScopeInfo(rl.GetResource(), rl.GetSchemaUrl(), sl.GetScope(), sl.GetSchemaUrl())
+			rows := make([]map[string]any, 0, len(sl.GetLogRecords()))
+			for _, lr := range sl.GetLogRecords() {
+				rows = append(rows, map[string]any{
+					"timeUnixNano":         int64(lr.GetTimeUnixNano()),
+					"observedTimeUnixNano": int64(lr.GetObservedTimeUnixNano()),
+					"severityNumber":       int64(lr.GetSeverityNumber()),
+					"severityText":         lr.GetSeverityText(),
+					"body":                 anyValue(lr.GetBody()),
+					"attributes":           attributesToMap(lr.GetAttributes()),
+					"traceId":              hex.EncodeToString(lr.GetTraceId()),
+					"spanId":               hex.EncodeToString(lr.GetSpanId()),
+					"flags":                int64(lr.GetFlags()),
+				})
+			}
+			if len(rows) > 0 {
+				result = append(result, si.group(SignalLogs, rows, resourceAsFields))
+			}
+		}
+	}
+	return result
+}
+
+func flattenTraces(req *coltracepb.ExportTraceServiceRequest, resourceAsFields bool) []group {
+	var result []group
+	for _, rs := range req.GetResourceSpans() {
+		for _, ss := range rs.GetScopeSpans() {
+			si := newScopeInfo(rs.GetResource(), rs.GetSchemaUrl(), ss.GetScope(), ss.GetSchemaUrl())
+			rows := make([]map[string]any, 0, len(ss.GetSpans()))
+			for _, span := range ss.GetSpans() {
+				rows = append(rows, flattenSpan(span))
+			}
+			if len(rows) > 0 {
+				result = append(result, si.group(SignalTraces, rows, resourceAsFields))
+			}
+		}
+	}
+	return result
+}
+
+func flattenSpan(span *tracepb.Span) map[string]any {
+	events := make([]any, 0, len(span.GetEvents()))
+	for _, e := range span.GetEvents() {
+		events = append(events, map[string]any{
+			"name":         e.GetName(),
+			"timeUnixNano": int64(e.GetTimeUnixNano()),
+			"attributes":   attributesToMap(e.GetAttributes()),
+		})
+	}
+	links := make([]any, 0, len(span.GetLinks()))
+	for _, l := range span.GetLinks() {
+		links = append(links, map[string]any{
+			"traceId":    hex.EncodeToString(l.GetTraceId()),
+			"spanId":     hex.EncodeToString(l.GetSpanId()),
+			"traceState": l.GetTraceState(),
+			"attributes": attributesToMap(l.GetAttributes()),
+		})
+	}
+	return map[string]any{
+		"traceId":           hex.EncodeToString(span.GetTraceId()),
+		"spanId":            hex.EncodeToString(span.GetSpanId()),
+		"parentSpanId":      hex.EncodeToString(span.GetParentSpanId()),
+		"traceState":        span.GetTraceState(),
+		"name":              span.GetName(),
+		"kind":              enumName(span.GetKind().String(), "SPAN_KIND_"),
+		"startTimeUnixNano": int64(span.GetStartTimeUnixNano()),
+		"endTimeUnixNano":   int64(span.GetEndTimeUnixNano()),
+		"durationNano":      int64(span.GetEndTimeUnixNano()) - int64(span.GetStartTimeUnixNano()),
+		"attributes":        attributesToMap(span.GetAttributes()),
+		"status": map[string]any{
+			"code":    enumName(span.GetStatus().GetCode().String(), "STATUS_CODE_"),
+			"message": span.GetStatus().GetMessage(),
+		},
+		"events": events,
+		"links":  links,
+	}
+}
+
+// enumName converts the enum name like SPAN_KIND_SERVER to server
+func enumName(name, prefix string) string {
+	return strings.ToLower(strings.TrimPrefix(name, prefix))
+}
+
+func attributesToMap(attrs []*commonpb.KeyValue) map[string]any {
+	result := make(map[string]any, len(attrs))
+	for _, kv := range attrs {
+		result[kv.GetKey()] = anyValue(kv.GetValue())
+	}
+	return result
+}
+
+func anyValue(v *commonpb.AnyValue) any {
+	switch val := v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return val.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return val.BoolValue
+	case *commonpb.AnyValue_IntValue:
+		return val.IntValue
+	case *commonpb.AnyValue_DoubleValue:
+		return val.DoubleValue
+	case *commonpb.AnyValue_BytesValue:
+		return val.BytesValue
+	case *commonpb.AnyValue_ArrayValue:
+		result := make([]any, 0, len(val.ArrayValue.GetValues()))
+		for _, e := range val.ArrayValue.GetValues() {
+			result = append(result, anyValue(e))
+		}
+		return result
+	case *commonpb.AnyValue_KvlistValue:
+		return attributesToMap(val.KvlistValue.GetValues())
+	default:
+		return nil
+	}
+}
+
+func uintsToList(values []uint64) []any {
+	result := make([]any, 0, len(values))
+	for _, v := range values {
+		result = append(result, int64(v))
+	}
+	return result
+}
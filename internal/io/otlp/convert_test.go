@@ -0,0 +1,215 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+func strAttr(k, v string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: k, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}}
+}
+
+var (
+	testResource = &resourcepb.Resource{Attributes: []*commonpb.KeyValue{strAttr("service.name", "checkout")}}
+	testScope    = &commonpb.InstrumentationScope{Name: "lib", Version: "1.0"}
+	expResource  = map[string]any{"service.name": "checkout"}
+	expScope     = map[string]any{"name": "lib", "version": "1.0", "attributes": map[string]any{}}
+)
+
+func testMetricsRequest() *colmetricspb.ExportMetricsServiceRequest {
+	return &colmetricspb.ExportMetricsServiceRequest{ResourceMetrics: []*metricspb.ResourceMetrics{{
+		Resource:  testResource,
+		SchemaUrl: "https://opentelemetry.io/schemas/1.21.0",
+		ScopeMetrics: []*metricspb.ScopeMetrics{{
+			Scope: testScope,
+			Metrics: []*metricspb.Metric{
+				{
+					Name: "temperature", Unit: "Cel",
+					Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: []*metricspb.NumberDataPoint{
+						{Attributes: []*commonpb.KeyValue{strAttr("room", "a")}, TimeUnixNano: 1000, Value: &metricspb.NumberDataPoint_AsDouble{AsDouble: 20.5}},
+						{Attributes: []*commonpb.KeyValue{strAttr("room", "b")}, TimeUnixNano: 1000, Value: &metricspb.NumberDataPoint_AsDouble{AsDouble: 21}},
+					}}},
+				},
+				{
+					Name: "requests",
+					Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+						IsMonotonic:            true,
+						AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+						DataPoints:             []*metricspb.NumberDataPoint{{StartTimeUnixNano: 500, TimeUnixNano: 1000, Value: &metricspb.NumberDataPoint_AsInt{AsInt: 42}}},
+					}},
+				},
+				{
+					Name: "latency",
+					Data: &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{
+						AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA,
+						DataPoints: []*metricspb.HistogramDataPoint{{
+							TimeUnixNano: 1000, Count: 3, Sum: proto64(0.6), Max: proto64(0.3),
+							BucketCounts: []uint64{1, 2}, ExplicitBounds: []float64{0.25},
+						}},
+					}},
+				},
+				{
+					Name: "size",
+					Data: &metricspb.Metric_Summary{Summary: &metricspb.Summary{DataPoints: []*metricspb.SummaryDataPoint{{
+						TimeUnixNano: 1000, Count: 2, Sum: 10,
+						QuantileValues: []*metricspb.SummaryDataPoint_ValueAtQuantile{{Quantile: 0.5, Value: 4}},
+					}}}},
+				},
+			},
+		}},
+	}}}
+}
+
+func proto64(f float64) *float64 {
+	return &f
+}
+
+func TestFlattenMetrics(t *testing.T) {
+	groups := flattenMetrics(testMetricsRequest(), false)
+	require.Len(t, groups, 1)
+	require.Equal(t, map[string]any{
+		"signal":    SignalMetrics,
+		"resource":  expResource,
+		"scope":     expScope,
+		"schemaUrl": "https://opentelemetry.io/schemas/1.21.0",
+	}, groups[0].meta)
+	require.Equal(t, []map[string]any{
+		{
+			"name": "temperature", "description": "", "unit": "Cel", "type": "gauge",
+			"attributes": map[string]any{"room": "a"}, "startTimeUnixNano": int64(0), "timeUnixNano": int64(1000),
+			"value": 20.5,
+		},
+		{
+			"name": "temperature", "description": "", "unit": "Cel", "type": "gauge",
+			"attributes": map[string]any{"room": "b"}, "startTimeUnixNano": int64(0), "timeUnixNano": int64(1000),
+			"value": float64(21),
+		},
+		{
+			"name": "requests", "description": "", "unit": "", "type": "sum",
+			"attributes": map[string]any{}, "startTimeUnixNano": int64(500), "timeUnixNano": int64(1000),
+			"value": int64(42), "isMonotonic": true, "aggregationTemporality": "cumulative",
+		},
+		{
+			"name": "latency", "description": "", "unit": "", "type": "histogram",
+			"attributes": map[string]any{}, "startTimeUnixNano": int64(0), "timeUnixNano": int64(1000),
+			"count": int64(3), "sum": 0.6, "max": 0.3, "bucketCounts": []any{int64(1), int64(2)},
+			"explicitBounds": []any{0.25}, "aggregationTemporality": "delta",
+		},
+		{
+			"name": "size", "description": "", "unit": "", "type": "summary",
+			"attributes": map[string]any{}, "startTimeUnixNano": int64(0), "timeUnixNano": int64(1000),
+			"count": int64(2), "sum": float64(10), "quantileValues": []any{map[string]any{"quantile": 0.5, "value": float64(4)}},
+		},
+	}, groups[0].rows)
+
+	groups = flattenMetrics(testMetricsRequest(), true)
+	for _, row := range groups[0].rows {
+		require.Equal(t, expResource, row["resource"])
+		require.Equal(t, expScope, row["scope"])
+	}
+}
+
+func TestFlattenLogs(t *testing.T) {
+	req := &collogspb.ExportLogsServiceRequest{ResourceLogs: []*logspb.ResourceLogs{{
+		Resource: testResource,
+		ScopeLogs: []*logspb.ScopeLogs{
+			{
+				Scope:     testScope,
+				SchemaUrl: "scope-schema",
+				LogRecords: []*logspb.LogRecord{{
+					TimeUnixNano:   1000,
+					SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_ERROR,
+					SeverityText:   "ERROR",
+					Body: &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: &commonpb.KeyValueList{Values: []*commonpb.KeyValue{
+						strAttr("msg", "failed"),
+						{Key: "codes", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{Values: []*commonpb.AnyValue{
+							{Value: &commonpb.AnyValue_IntValue{IntValue: 1}},
+							{Value: &commonpb.AnyValue_BoolValue{BoolValue: true}},
+						}}}}},
+					}}}},
+					Attributes: []*commonpb.KeyValue{strAttr("user", "u1")},
+					TraceId:    []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+					SpanId:     []byte{1, 2, 3, 4, 5, 6, 7, 8},
+				}},
+			},
+			// the empty scope is skipped
+			{Scope: testScope},
+		},
+	}}}
+	groups := flattenLogs(req, true)
+	require.Len(t, groups, 1)
+	require.Equal(t, "scope-schema", groups[0].meta["schemaUrl"])
+	require.Equal(t, []map[string]any{{
+		"timeUnixNano":         int64(1000),
+		"observedTimeUnixNano": int64(0),
+		"severityNumber":       int64(17),
+		"severityText":         "ERROR",
+		"body":                 map[string]any{"msg": "failed", "codes": []any{int64(1), true}},
+		"attributes":           map[string]any{"user": "u1"},
+		"traceId":              "0102030405060708090a0b0c0d0e0f10",
+		"spanId":               "0102030405060708",
+		"flags":                int64(0),
+		"resource":             expResource,
+		"scope":                expScope,
+	}}, groups[0].rows)
+}
+
+func TestFlattenTraces(t *testing.T) {
+	req := &coltracepb.ExportTraceServiceRequest{ResourceSpans: []*tracepb.ResourceSpans{{
+		Resource: testResource,
+		ScopeSpans: []*tracepb.ScopeSpans{{
+			Scope: testScope,
+			Spans: []*tracepb.Span{{
+				TraceId:           []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+				SpanId:            []byte{1, 2, 3, 4, 5, 6, 7, 8},
+				Name:              "GET /cart",
+				Kind:              tracepb.Span_SPAN_KIND_SERVER,
+				StartTimeUnixNano: 1000,
+				EndTimeUnixNano:   3500,
+				Attributes:        []*commonpb.KeyValue{strAttr("http.method", "GET")},
+				Events:            []*tracepb.Span_Event{{Name: "retry", TimeUnixNano: 2000}},
+				Status:            &tracepb.Status{Code: tracepb.Status_STATUS_CODE_ERROR, Message: "timeout"},
+			}},
+		}},
+	}}}
+	groups := flattenTraces(req, false)
+	require.Len(t, groups, 1)
+	require.Equal(t, []map[string]any{{
+		"traceId":           "0102030405060708090a0b0c0d0e0f10",
+		"spanId":            "0102030405060708",
+		"parentSpanId":      "",
+		"traceState":        "",
+		"name":              "GET /cart",
+		"kind":              "server",
+		"startTimeUnixNano": int64(1000),
+		"endTimeUnixNano":   int64(3500),
+		"durationNano":      int64(2500),
+		"attributes":        map[string]any{"http.method": "GET"},
+		"status":            map[string]any{"code": "error", "message": "timeout"},
+		"events":            []any{map[string]any{"name": "retry", "timeUnixNano": int64(2000), "attributes": map[string]any{}}},
+		"links":             []any{},
+	}}, groups[0].rows)
+}
@@ -0,0 +1,326 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	SignalMetrics = "metrics"
+	SignalLogs    = "logs"
+	SignalTraces  = "traces"
+
+	protocolGrpc = "grpc"
+	protocolHttp = "http"
+
+	contentTypeProtobuf = "application/x-protobuf"
+	contentTypeJson     = "application/json"
+	// maxBodySize is the max size of the decompressed request body of OTLP/HTTP
+	maxBodySize = 32 << 20
+)
+
+// consumer receives the export requests of the subscribed signal
+type consumer interface {
+	consume(req proto.Message)
+}
+
+// receiver serves the OTLP/gRPC or OTLP/HTTP endpoint on one address. The streams listening on the same address share
+// one receiver and each export request is dispatched to all the consumers of its signal.
+type receiver struct {
+	key        string
+	refs       int
+	grpcServer *grpc.Server
+	httpServer *http.Server
+	lis        net.Listener
+
+	mu        sync.RWMutex
+	consumers map[string]map[consumer]struct{}
+}
+
+var (
+	receiversLock sync.Mutex
+	receivers     = map[string]*receiver{}
+)
+
+// acquireReceiver returns the running receiver of the protocol and address or starts a new one
+func acquireReceiver(protocol, addr string) (*receiver, error) {
+	key := protocol + "://" + addr
+	receiversLock.Lock()
+	defer receiversLock.Unlock()
+	if r, ok := receivers[key]; ok {
+		r.refs++
+		return r, nil
+	}
+	r := &receiver{
+		key:       key,
+		refs:      1,
+		consumers: map[string]map[consumer]struct{}{},
+	}
+	if err := r.start(protocol, addr); err != nil {
+		return nil, err
+	}
+	receivers[key] = r
+	return r, nil
+}
+
+func releaseReceiver(r *receiver) {
+	receiversLock.Lock()
+	defer receiversLock.Unlock()
+	r.refs--
+	if r.refs <= 0 {
+		delete(receivers, r.key)
+		r.stop()
+	}
+}
+
+func (r *receiver) start(protocol, addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listen otlp %s on %s failed: %v", protocol, addr, err)
+	}
+	r.lis = lis
+	switch protocol {
+	case protocolGrpc:
+		r.grpcServer = grpc.NewServer()
+		colmetricspb.RegisterMetricsServiceServer(r.grpcServer, &metricsService{r: r})
+		collogspb.RegisterLogsServiceServer(r.grpcServer, &logsService{r: r})
+		coltracepb.RegisterTraceServiceServer(r.grpcServer, &traceService{r: r})
+		go func(s *grpc.Server, lis net.Listener) {
+			_ = s.Serve(lis)
+		}(r.grpcServer, lis)
+	case protocolHttp:
+		mux := http.NewServeMux()
+		mux.HandleFunc("/v1/metrics", r.handle(SignalMetrics, func() proto.Message { return &colmetricspb.ExportMetricsServiceRequest{} }, &colmetricspb.ExportMetricsServiceResponse{}))
+		mux.HandleFunc("/v1/logs", r.handle(SignalLogs, func() proto.Message { return &collogspb.ExportLogsServiceRequest{} }, &collogspb.ExportLogsServiceResponse{}))
+		mux.HandleFunc("/v1/traces", r.handle(SignalTraces, func() proto.Message { return &coltracepb.ExportTraceServiceRequest{} }, &coltracepb.ExportTraceServiceResponse{}))
+		r.httpServer = &http.Server{
+			Handler:      mux,
+			ReadTimeout:  time.Minute,
+			WriteTimeout: time.Minute,
+			IdleTimeout:  time.Minute,
+		}
+		go func(s *http.Server, lis net.Listener) {
+			_ = s.Serve(lis)
+		}(r.httpServer, lis)
+	}
+	return nil
+}
+
+func (r *receiver) stop() {
+	switch {
+	case r.grpcServer != nil:
+		r.grpcServer.Stop()
+	case r.httpServer != nil:
+		_ = r.httpServer.Close()
+	case r.lis != nil:
+		_ = r.lis.Close()
+	}
+}
+
+func (r *receiver) subscribe(signal string, c consumer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cs, ok := r.consumers[signal]
+	if !ok {
+		cs = map[consumer]struct{}{}
+		r.consumers[signal] = cs
+	}
+	cs[c] = struct{}{}
+}
+
+func (r *receiver) unsubscribe(signal string, c consumer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.consumers[signal], c)
+}
+
+func (r *receiver) dispatch(signal string, req proto.Message) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for c := range r.consumers[signal] {
+		c.consume(req)
+	}
+}
+
+// handle serves the OTLP/HTTP request of the signal. Both the binary protobuf and the JSON encoding are supported and
+// the response is encoded in the same way as the request.
+func (r *receiver) handle(signal string, newReq func() proto.Message, resp proto.Message) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		contentType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if contentType != contentTypeProtobuf && contentType != contentTypeJson {
+			http.Error(w, fmt.Sprintf("unsupported content type %s", contentType), http.StatusUnsupportedMediaType)
+			return
+		}
+		body, err := readBody(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		msg := newReq()
+		if contentType == contentTypeProtobuf {
+			err = proto.Unmarshal(body, msg)
+		} else {
+			err = unmarshalJson(body, msg)
+		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("decode %s request failed: %v", signal, err), http.StatusBadRequest)
+			return
+		}
+		r.dispatch(signal, msg)
+		var out []byte
+		if contentType == contentTypeProtobuf {
+			out, err = proto.Marshal(resp)
+		} else {
+			out, err = protojson.Marshal(resp)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(out)
+	}
+}
+
+func readBody(req *http.Request) ([]byte, error) {
+	var reader io.Reader = req.Body
+	switch req.Header.Get("Content-Encoding") {
+	case "", "identity":
+	case "gzip":
+		gr, err := gzip.NewReader(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("read gzip body failed: %v", err)
+		}
+		defer gr.Close()
+		reader = gr
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %s", req.Header.Get("Content-Encoding"))
+	}
+	body, err := io.ReadAll(io.LimitReader(reader, maxBodySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read body failed: %v", err)
+	}
+	if len(body) > maxBodySize {
+		return nil, errors.New("request body too large")
+	}
+	return body, nil
+}
+
+// idFields are the ids encoded as hex strings in OTLP/JSON instead of base64 by the protobuf JSON mapping
+var idFields = map[string]struct{}{
+	"traceId":      {},
+	"spanId":       {},
+	"parentSpanId": {},
+}
+
+// unmarshalJson decodes the OTLP/JSON which encodes the trace and span ids as hex
+func unmarshalJson(body []byte, msg proto.Message) error {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return err
+	}
+	if err := convertIds(v); err != nil {
+		return err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(b, msg)
+}
+
+func convertIds(v any) error {
+	switch vt := v.(type) {
+	case map[string]any:
+		for k, e := range vt {
+			if s, ok := e.(string); ok {
+				if _, isId := idFields[k]; isId {
+					b, err := hex.DecodeString(s)
+					if err != nil {
+						return fmt.Errorf("invalid %s %s: %v", k, s, err)
+					}
+					vt[k] = base64.StdEncoding.EncodeToString(b)
+				}
+				continue
+			}
+			if err := convertIds(e); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, e := range vt {
+			if err := convertIds(e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type metricsService struct {
+	colmetricspb.UnimplementedMetricsServiceServer
+	r *receiver
+}
+
+func (s *metricsService) Export(_ context.Context, req *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	s.r.dispatch(SignalMetrics, req)
+	return &colmetricspb.ExportMetricsServiceResponse{}, nil
+}
+
+type logsService struct {
+	collogspb.UnimplementedLogsServiceServer
+	r *receiver
+}
+
+func (s *logsService) Export(_ context.Context, req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	s.r.dispatch(SignalLogs, req)
+	return &collogspb.ExportLogsServiceResponse{}, nil
+}
+
+type traceService struct {
+	coltracepb.UnimplementedTraceServiceServer
+	r *receiver
+}
+
+func (s *traceService) Export(_ context.Context, req *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	s.r.dispatch(SignalTraces, req)
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
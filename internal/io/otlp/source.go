@@ -0,0 +1,141 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/infra"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+type SourceConf struct {
+	// Datasource is the signal to receive: metrics, logs or traces
+	Datasource string `json:"datasource"`
+	// GrpcAddr and HttpAddr are the addresses to listen, empty to disable the protocol
+	GrpcAddr string `json:"grpcAddr"`
+	HttpAddr string `json:"httpAddr"`
+	// ResourceAsFields adds the resource attributes and the scope as the fields of each tuple
+	ResourceAsFields bool `json:"resourceAsFields"`
+}
+
+// Source receives the OTLP export requests of one signal and ingests one tuple per data point, log record or span.
+// The resource and scope are always available in the metadata.
+type Source struct {
+	cfg         *SourceConf
+	receivers   []*receiver
+	ctx         api.StreamContext
+	ingest      api.TupleIngest
+	ingestError api.ErrorIngest
+}
+
+func (s *Source) Provision(_ api.StreamContext, props map[string]any) error {
+	cfg := &SourceConf{
+		GrpcAddr:         ":4317",
+		HttpAddr:         ":4318",
+		ResourceAsFields: true,
+	}
+	err := cast.MapToStruct(props, cfg)
+	if err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	switch cfg.Datasource {
+	case SignalMetrics, SignalLogs, SignalTraces:
+	default:
+		return fmt.Errorf("invalid datasource %s, must be one of %s, %s or %s", cfg.Datasource, SignalMetrics, SignalLogs, SignalTraces)
+	}
+	if cfg.GrpcAddr == "" && cfg.HttpAddr == "" {
+		return fmt.Errorf("at least one of grpcAddr and httpAddr is required")
+	}
+	s.cfg = cfg
+	return nil
+}
+
+func (s *Source) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	ctx.GetLogger().Infof("Receiving otlp %s by grpc %s and http %s", s.cfg.Datasource, s.cfg.GrpcAddr, s.cfg.HttpAddr)
+	for _, l := range []struct{ protocol, addr string }{{protocolGrpc, s.cfg.GrpcAddr}, {protocolHttp, s.cfg.HttpAddr}} {
+		if l.addr == "" {
+			continue
+		}
+		r, err := acquireReceiver(l.protocol, l.addr)
+		if err != nil {
+			s.release()
+			sch(api.ConnectionDisconnected, err.Error())
+			return err
+		}
+		s.receivers = append(s.receivers, r)
+	}
+	sch(api.ConnectionConnected, "")
+	return nil
+}
+
+func (s *Source) Subscribe(ctx api.StreamContext, ingest api.TupleIngest, ingestError api.ErrorIngest) error {
+	s.ctx = ctx
+	s.ingest = ingest
+	s.ingestError = ingestError
+	for _, r := range s.receivers {
+		r.subscribe(s.cfg.Datasource, s)
+	}
+	return nil
+}
+
+func (s *Source) consume(req proto.Message) {
+	var groups []group
+	switch r := req.(type) {
+	case *colmetricspb.ExportMetricsServiceRequest:
+		groups = flattenMetrics(r, s.cfg.ResourceAsFields)
+	case *collogspb.ExportLogsServiceRequest:
+		groups = flattenLogs(r, s.cfg.ResourceAsFields)
+	case *coltracepb.ExportTraceServiceRequest:
+		groups = flattenTraces(r, s.cfg.ResourceAsFields)
+	}
+	for _, g := range groups {
+		e := infra.SafeRun(func() error {
+			s.ingest(s.ctx, g.rows, g.meta, timex.GetNow())
+			return nil
+		})
+		if e != nil {
+			s.ingestError(s.ctx, e)
+		}
+	}
+}
+
+func (s *Source) Close(ctx api.StreamContext) error {
+	ctx.GetLogger().Infof("Closing otlp source")
+	s.release()
+	return nil
+}
+
+// release unsubscribes and releases all the receivers of the source
+func (s *Source) release() {
+	for _, r := range s.receivers {
+		r.unsubscribe(s.cfg.Datasource, s)
+		releaseReceiver(r)
+	}
+	s.receivers = nil
+}
+
+func GetSource() api.Source {
+	return &Source{}
+}
+
+var _ api.TupleSource = &Source{}
@@ -0,0 +1,238 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/require"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+type received struct {
+	rows []map[string]any
+	meta map[string]any
+}
+
+// startSource starts the source on random ports, the sources with the same props share the receiver
+func startSource(t *testing.T, ctx api.StreamContext, props map[string]any) (*Source, chan received) {
+	s := GetSource().(*Source)
+	require.NoError(t, s.Provision(ctx, props))
+	require.NoError(t, s.Connect(ctx, func(string, string) {}))
+	ch := make(chan received, 10)
+	require.NoError(t, s.Subscribe(ctx, func(_ api.StreamContext, data any, meta map[string]any, _ time.Time) {
+		ch <- received{rows: data.([]map[string]any), meta: meta}
+	}, func(_ api.StreamContext, err error) {
+		require.NoError(t, err)
+	}))
+	return s, ch
+}
+
+func expectReceived(t *testing.T, ch chan received) received {
+	select {
+	case r := <-ch:
+		return r
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "timeout")
+		return received{}
+	}
+}
+
+func TestProvision(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	for _, props := range []map[string]any{
+		{},
+		{"datasource": "profiles"},
+		{"datasource": "logs", "grpcAddr": "", "httpAddr": ""},
+	} {
+		require.Error(t, GetSource().Provision(ctx, props), props)
+	}
+	s := GetSource().(*Source)
+	require.NoError(t, s.Provision(ctx, map[string]any{"datasource": "traces"}))
+	require.Equal(t, &SourceConf{Datasource: "traces", GrpcAddr: ":4317", HttpAddr: ":4318", ResourceAsFields: true}, s.cfg)
+}
+
+func TestGrpc(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	props := map[string]any{"datasource": "metrics", "grpcAddr": "127.0.0.1:0", "httpAddr": ""}
+	s1, ch1 := startSource(t, ctx, props)
+	// the second stream of the same signal shares the receiver
+	s2, ch2 := startSource(t, ctx, map[string]any{"datasource": "metrics", "grpcAddr": "127.0.0.1:0", "httpAddr": "", "resourceAsFields": false})
+	require.Same(t, s1.receivers[0], s2.receivers[0])
+	addr := s1.receivers[0].lis.Addr().String()
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = colmetricspb.NewMetricsServiceClient(conn).Export(context.Background(), testMetricsRequest(), grpc.UseCompressor("gzip"))
+	require.NoError(t, err)
+	r := expectReceived(t, ch1)
+	require.Len(t, r.rows, 5)
+	require.Equal(t, "temperature", r.rows[0]["name"])
+	require.Equal(t, expResource, r.rows[0]["resource"])
+	require.Equal(t, expResource, r.meta["resource"])
+	r = expectReceived(t, ch2)
+	require.Len(t, r.rows, 5)
+	require.NotContains(t, r.rows[0], "resource")
+
+	// the logs service is served but no stream subscribes to it
+	_, err = collogspb.NewLogsServiceClient(conn).Export(context.Background(), &collogspb.ExportLogsServiceRequest{})
+	require.NoError(t, err)
+
+	require.NoError(t, s2.Close(ctx))
+	require.NoError(t, s1.Close(ctx))
+	// the receiver is stopped after all the sources are closed
+	require.Empty(t, receivers)
+	_, err = colmetricspb.NewMetricsServiceClient(conn).Export(context.Background(), testMetricsRequest())
+	require.Error(t, err)
+}
+
+func TestHttp(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	props := map[string]any{"grpcAddr": "", "httpAddr": "127.0.0.1:0"}
+	props["datasource"] = "logs"
+	logSrc, logCh := startSource(t, ctx, props)
+	defer logSrc.Close(ctx)
+	traceSrc, traceCh := startSource(t, ctx, map[string]any{"datasource": "traces", "grpcAddr": "", "httpAddr": "127.0.0.1:0"})
+	defer traceSrc.Close(ctx)
+	url := "http://" + logSrc.receivers[0].lis.Addr().String()
+
+	post := func(path, contentType, encoding string, body []byte) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, url+path, bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Content-Encoding", encoding)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	// protobuf with gzip
+	body, err := proto.Marshal(&collogspb.ExportLogsServiceRequest{ResourceLogs: []*logspb.ResourceLogs{{
+		Resource:  testResource,
+		ScopeLogs: []*logspb.ScopeLogs{{Scope: testScope, LogRecords: []*logspb.LogRecord{{SeverityText: "INFO"}}}},
+	}}})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, _ = gw.Write(body)
+	require.NoError(t, gw.Close())
+	resp := post("/v1/logs", "application/x-protobuf", "gzip", buf.Bytes())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/x-protobuf", resp.Header.Get("Content-Type"))
+	r := expectReceived(t, logCh)
+	require.Equal(t, "INFO", r.rows[0]["severityText"])
+	require.Equal(t, SignalLogs, r.meta["signal"])
+
+	// json with the hex ids
+	resp = post("/v1/traces", "application/json; charset=utf-8", "", []byte(`{"resourceSpans":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"checkout"}}]},
+"scopeSpans":[{"spans":[{"traceId":"5b8efff798038103d269b633813fc60c","spanId":"eee19b7ec3c1b174","name":"GET","kind":2,
+"startTimeUnixNano":"1000","endTimeUnixNano":"2000","attributes":[{"key":"status","value":{"intValue":"200"}}],"status":{}}]}]}]}`))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	r = expectReceived(t, traceCh)
+	require.Equal(t, "5b8efff798038103d269b633813fc60c", r.rows[0]["traceId"])
+	require.Equal(t, "eee19b7ec3c1b174", r.rows[0]["spanId"])
+	require.Equal(t, "server", r.rows[0]["kind"])
+	require.Equal(t, int64(1000), r.rows[0]["durationNano"])
+	require.Equal(t, map[string]any{"status": int64(200)}, r.rows[0]["attributes"])
+	require.Equal(t, expResource, r.rows[0]["resource"])
+
+	// metrics are accepted without subscribers
+	resp = post("/v1/metrics", "application/json", "", []byte(`{}`))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.Equal(t, http.StatusUnsupportedMediaType, post("/v1/logs", "text/plain", "", []byte("a")).StatusCode)
+	require.Equal(t, http.StatusBadRequest, post("/v1/logs", "application/x-protobuf", "", []byte("invalid")).StatusCode)
+	require.Equal(t, http.StatusBadRequest, post("/v1/traces", "application/json", "", []byte(`{"resourceSpans":[{"scopeSpans":[{"spans":[{"traceId":"xyz"}]}]}]}`)).StatusCode)
+	require.Equal(t, http.StatusBadRequest, post("/v1/logs", "application/json", "br", []byte(`{}`)).StatusCode)
+	require.Equal(t, http.StatusNotFound, post("/v1/profiles", "application/json", "", []byte(`{}`)).StatusCode)
+	getResp, err := http.Get(url + "/v1/logs")
+	require.NoError(t, err)
+	getResp.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, getResp.StatusCode)
+}
+
+func TestConnectError(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	s1, _ := startSource(t, ctx, map[string]any{"datasource": "logs", "grpcAddr": "127.0.0.1:0", "httpAddr": ""})
+	defer s1.Close(ctx)
+	// the address is in use by the grpc receiver
+	s2 := GetSource().(*Source)
+	require.NoError(t, s2.Provision(ctx, map[string]any{"datasource": "logs", "grpcAddr": "127.0.0.1:0", "httpAddr": s1.receivers[0].lis.Addr().String()}))
+	var status string
+	err := s2.Connect(ctx, func(s string, _ string) { status = s })
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "listen otlp http"))
+	require.Equal(t, api.ConnectionDisconnected, status)
+	require.NoError(t, s2.Close(ctx))
+	// the grpc receiver acquired before the failure is released
+	receiversLock.Lock()
+	require.Len(t, receivers, 1)
+	receiversLock.Unlock()
+}
+
+func TestShareAddr(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	s1, ch1 := startSource(t, ctx, map[string]any{"datasource": "logs", "grpcAddr": "127.0.0.1:0", "httpAddr": "127.0.0.1:0"})
+	// only the grpc address is the same, the grpc receiver is shared and a new http receiver is started
+	s2, ch2 := startSource(t, ctx, map[string]any{"datasource": "logs", "grpcAddr": "127.0.0.1:0", "httpAddr": ""})
+	require.Len(t, s2.receivers, 1)
+	require.Same(t, s1.receivers[0], s2.receivers[0])
+	require.NotSame(t, s1.receivers[0], s1.receivers[1])
+
+	conn, err := grpc.NewClient(s1.receivers[0].lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = collogspb.NewLogsServiceClient(conn).Export(context.Background(), &collogspb.ExportLogsServiceRequest{ResourceLogs: []*logspb.ResourceLogs{{
+		ScopeLogs: []*logspb.ScopeLogs{{LogRecords: []*logspb.LogRecord{{SeverityText: "WARN"}}}},
+	}}})
+	require.NoError(t, err)
+	require.Equal(t, "WARN", expectReceived(t, ch1).rows[0]["severityText"])
+	require.Equal(t, "WARN", expectReceived(t, ch2).rows[0]["severityText"])
+
+	// the requests of the http receiver are only dispatched to its own sources
+	resp, err := http.Post("http://"+s1.receivers[1].lis.Addr().String()+"/v1/logs", "application/json", strings.NewReader(`{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"severityText":"INFO"}]}]}]}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "INFO", expectReceived(t, ch1).rows[0]["severityText"])
+	select {
+	case <-ch2:
+		require.Fail(t, "the http request is dispatched to the grpc only source")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	require.NoError(t, s1.Close(ctx))
+	require.NoError(t, s2.Close(ctx))
+	receiversLock.Lock()
+	require.Empty(t, receivers)
+	receiversLock.Unlock()
+}
@@ -0,0 +1,60 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/httpx"
+	"github.com/lf-edge/ekuiper/v2/pkg/cert"
+)
+
+// maxErrorBody is the max bytes of the response body to show in the error
+const maxErrorBody = 512
+
+func newClient(ctx api.StreamContext, props map[string]any, timeout time.Duration) (*http.Client, error) {
+	tlsConf, err := cert.GenTLSConfig(ctx, props)
+	if err != nil {
+		return nil, fmt.Errorf("error configuring tls: %v", err)
+	}
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.TLSClientConfig = tlsConf
+	tr.DialContext = httpx.GetSSRFDialContext(timeout)
+	return &http.Client{
+		Transport: tr,
+		Timeout:   timeout,
+	}, nil
+}
+
+func validateUrl(u string) error {
+	if u == "" {
+		return fmt.Errorf("url is required")
+	}
+	if err := httpx.IsHttpUrl(u); err != nil {
+		return fmt.Errorf("invalid url %s: %v", u, err)
+	}
+	return nil
+}
+
+// statusError builds the error of the unexpected response status with the beginning of the body
+func statusError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return fmt.Errorf("unexpected response status %s: %s", resp.Status, string(body))
+}
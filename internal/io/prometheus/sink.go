@@ -0,0 +1,238 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+type SinkConf struct {
+	// Url is the remote write endpoint like http://127.0.0.1:9090/api/v1/write
+	Url     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Timeout cast.DurationConf `json:"timeout"`
+	// MetricName is the name of all the metrics, or MetricNameField is the field to read the name from
+	MetricName      string `json:"metricName"`
+	MetricNameField string `json:"metricNameField"`
+	ValueField      string `json:"valueField"`
+	// LabelFields are the fields written as labels, Labels are the constant labels
+	LabelFields []string          `json:"labelFields"`
+	Labels      map[string]string `json:"labels"`
+	// TimestampField is the field of the sample timestamp in milliseconds, use the current time if not set
+	TimestampField string `json:"timestampField"`
+}
+
+// Sink writes each tuple as a sample by the Prometheus remote write protocol. The batches of the sink are written in
+// one request with the samples grouped by series.
+type Sink struct {
+	cfg    *SinkConf
+	client *http.Client
+}
+
+func (s *Sink) Provision(ctx api.StreamContext, props map[string]any) error {
+	cfg := &SinkConf{
+		ValueField: "value",
+		Timeout:    cast.DurationConf(5 * time.Second),
+	}
+	err := cast.MapToStruct(props, cfg)
+	if err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if err := validateUrl(cfg.Url); err != nil {
+		return err
+	}
+	if cfg.Timeout <= 0 {
+		return fmt.Errorf("invalid timeout %v, must be positive", time.Duration(cfg.Timeout))
+	}
+	switch {
+	case cfg.MetricName == "" && cfg.MetricNameField == "":
+		return fmt.Errorf("either metricName or metricNameField is required")
+	case cfg.MetricName != "" && cfg.MetricNameField != "":
+		return fmt.Errorf("metricName and metricNameField cannot be set at the same time")
+	case cfg.MetricName != "" && !metricNameRe.MatchString(cfg.MetricName):
+		return fmt.Errorf("invalid metricName %s", cfg.MetricName)
+	}
+	if cfg.ValueField == "" {
+		return fmt.Errorf("valueField is required")
+	}
+	for _, name := range cfg.LabelFields {
+		if err := validateLabelName(name); err != nil {
+			return err
+		}
+	}
+	for name := range cfg.Labels {
+		if err := validateLabelName(name); err != nil {
+			return err
+		}
+	}
+	s.client, err = newClient(ctx, props, time.Duration(cfg.Timeout))
+	if err != nil {
+		return err
+	}
+	s.cfg = cfg
+	return nil
+}
+
+func validateLabelName(name string) error {
+	if !labelNameRe.MatchString(name) || strings.HasPrefix(name, "__") {
+		return fmt.Errorf("invalid label name %s", name)
+	}
+	return nil
+}
+
+func (s *Sink) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	ctx.GetLogger().Infof("Prometheus sink writes to %s", s.cfg.Url)
+	sch(api.ConnectionConnected, "")
+	return nil
+}
+
+func (s *Sink) Collect(ctx api.StreamContext, item api.MessageTuple) error {
+	return s.collect(ctx, []map[string]any{item.ToMap()})
+}
+
+func (s *Sink) CollectList(ctx api.StreamContext, items api.MessageTupleList) error {
+	return s.collect(ctx, items.ToMaps())
+}
+
+func (s *Sink) collect(ctx api.StreamContext, data []map[string]any) error {
+	b := newSeriesBuilder()
+	for _, m := range data {
+		labels, smp, err := s.toSample(m)
+		if err != nil {
+			// the invalid data will never succeed, so it is dropped instead of failing the whole batch
+			ctx.GetLogger().Warnf("prometheus sink drops data %v: %v", m, err)
+			continue
+		}
+		b.add(labels, smp)
+	}
+	if len(b.series) == 0 {
+		return fmt.Errorf("no valid sample to write")
+	}
+	return s.write(ctx, encodeWriteRequest(b.series))
+}
+
+// toSample maps the fields to the labels including the metric name and the sample
+func (s *Sink) toSample(m map[string]any) ([]label, sample, error) {
+	var smp sample
+	name := s.cfg.MetricName
+	if s.cfg.MetricNameField != "" {
+		v, ok := m[s.cfg.MetricNameField]
+		if !ok {
+			return nil, smp, fmt.Errorf("metric name field %s not found", s.cfg.MetricNameField)
+		}
+		name = cast.ToStringAlways(v)
+		if !metricNameRe.MatchString(name) {
+			return nil, smp, fmt.Errorf("invalid metric name %s", name)
+		}
+	}
+	v, ok := m[s.cfg.ValueField]
+	if !ok {
+		return nil, smp, fmt.Errorf("value field %s not found", s.cfg.ValueField)
+	}
+	switch vt := v.(type) {
+	case bool:
+		if vt {
+			smp.value = 1
+		}
+	default:
+		fv, err := cast.ToFloat64(v, cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return nil, smp, fmt.Errorf("value field %s is not a number: %v", s.cfg.ValueField, v)
+		}
+		smp.value = fv
+	}
+	if s.cfg.TimestampField != "" {
+		tv, ok := m[s.cfg.TimestampField]
+		if !ok {
+			return nil, smp, fmt.Errorf("timestamp field %s not found", s.cfg.TimestampField)
+		}
+		if t, ok := tv.(time.Time); ok {
+			smp.timestamp = t.UnixMilli()
+		} else {
+			ts, err := cast.ToInt64(tv, cast.CONVERT_SAMEKIND)
+			if err != nil {
+				return nil, smp, fmt.Errorf("timestamp field %s is not a timestamp: %v", s.cfg.TimestampField, tv)
+			}
+			smp.timestamp = ts
+		}
+	} else {
+		smp.timestamp = timex.GetNowInMilli()
+	}
+	labels := make([]label, 0, len(s.cfg.Labels)+len(s.cfg.LabelFields)+1)
+	labels = append(labels, label{name: metricNameLabel, value: name})
+	for k, lv := range s.cfg.Labels {
+		labels = append(labels, label{name: k, value: lv})
+	}
+	for _, k := range s.cfg.LabelFields {
+		// empty label is the same as no label in Prometheus
+		if lv, ok := m[k]; ok && lv != nil {
+			if str := cast.ToStringAlways(lv); str != "" {
+				labels = append(labels, label{name: k, value: str})
+			}
+		}
+	}
+	return labels, smp, nil
+}
+
+func (s *Sink) write(ctx api.StreamContext, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range s.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return errorx.NewIOErr(fmt.Sprintf("prometheus remote write to %s failed: %v", s.cfg.Url, err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	err = statusError(resp)
+	// retry the server errors and the rate limit, other client errors will not succeed in retry
+	if resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests {
+		return errorx.NewIOErr(fmt.Sprintf("prometheus remote write to %s failed: %v", s.cfg.Url, err))
+	}
+	return fmt.Errorf("prometheus remote write to %s failed: %v", s.cfg.Url, err)
+}
+
+func (s *Sink) Close(ctx api.StreamContext) error {
+	ctx.GetLogger().Infof("Closing prometheus sink")
+	if s.client != nil {
+		s.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+func GetSink() api.Sink {
+	return &Sink{}
+}
+
+var _ api.TupleCollector = &Sink{}
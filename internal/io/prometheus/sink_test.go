@@ -0,0 +1,204 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+func init() {
+	conf.InitConf()
+	conf.Config.Basic.EnablePrivateNet = true
+}
+
+// decodeWriteRequest decodes the write request to the lines like `name{k="v"} value timestamp`
+func decodeWriteRequest(t *testing.T, body []byte) []string {
+	b, err := snappy.Decode(nil, body)
+	require.NoError(t, err)
+	var lines []string
+	fields := func(b []byte, f func(num protowire.Number, typ protowire.Type, b []byte) int) {
+		for len(b) > 0 {
+			num, typ, n := protowire.ConsumeTag(b)
+			require.True(t, n > 0)
+			b = b[n:]
+			n = f(num, typ, b)
+			require.True(t, n > 0)
+			b = b[n:]
+		}
+	}
+	fields(b, func(_ protowire.Number, _ protowire.Type, b []byte) int {
+		ts, n := protowire.ConsumeBytes(b)
+		var name string
+		var labels, samples []string
+		fields(ts, func(num protowire.Number, _ protowire.Type, b []byte) int {
+			v, n := protowire.ConsumeBytes(b)
+			var strs []string
+			var value float64
+			var timestamp int64
+			fields(v, func(_ protowire.Number, typ protowire.Type, b []byte) int {
+				switch typ {
+				case protowire.BytesType:
+					s, n := protowire.ConsumeString(b)
+					strs = append(strs, s)
+					return n
+				case protowire.Fixed64Type:
+					f, n := protowire.ConsumeFixed64(b)
+					value = math.Float64frombits(f)
+					return n
+				default:
+					i, n := protowire.ConsumeVarint(b)
+					timestamp = int64(i)
+					return n
+				}
+			})
+			if num == 1 {
+				if strs[0] == metricNameLabel {
+					name = strs[1]
+				} else {
+					labels = append(labels, fmt.Sprintf("%s=%q", strs[0], strs[1]))
+				}
+			} else {
+				samples = append(samples, fmt.Sprintf("%v %d", value, timestamp))
+			}
+			return n
+		})
+		for _, s := range samples {
+			lines = append(lines, fmt.Sprintf("%s{%s} %s", name, strings.Join(labels, ","), s))
+		}
+		return n
+	})
+	return lines
+}
+
+func TestSinkProvision(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	for _, props := range []map[string]any{
+		{},
+		{"url": "localhost:9090"},
+		{"url": "http://localhost:9090/api/v1/write"},
+		{"url": "http://localhost:9090/api/v1/write", "metricName": "a-b"},
+		{"url": "http://localhost:9090/api/v1/write", "metricName": "a", "metricNameField": "name"},
+		{"url": "http://localhost:9090/api/v1/write", "metricName": "a", "labelFields": []any{"__a"}},
+		{"url": "http://localhost:9090/api/v1/write", "metricName": "a", "labels": map[string]any{"a.b": "c"}},
+		{"url": "http://localhost:9090/api/v1/write", "metricName": "a", "valueField": ""},
+		{"url": "http://localhost:9090/api/v1/write", "metricName": "a", "timeout": "-1s"},
+	} {
+		require.Error(t, GetSink().Provision(ctx, props), props)
+	}
+}
+
+func TestSinkCollect(t *testing.T) {
+	var (
+		status  = http.StatusNoContent
+		bodies  [][]byte
+		headers http.Header
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, b)
+		headers = r.Header
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("bad data"))
+	}))
+	defer server.Close()
+
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	s := GetSink().(*Sink)
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"url":             server.URL,
+		"headers":         map[string]any{"Authorization": "Bearer token"},
+		"metricNameField": "name",
+		"labelFields":     []any{"device", "zone"},
+		"labels":          map[string]any{"source": "ekuiper"},
+		"timestampField":  "ts",
+	}))
+	require.NoError(t, s.Connect(ctx, func(string, string) {}))
+	require.NoError(t, s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"name": "temperature", "device": "d1", "value": 20.5, "ts": int64(1000)}}))
+	require.Equal(t, []string{`temperature{device="d1",source="ekuiper"} 20.5 1000`}, decodeWriteRequest(t, bodies[0]))
+	require.Equal(t, "snappy", headers.Get("Content-Encoding"))
+	require.Equal(t, "application/x-protobuf", headers.Get("Content-Type"))
+	require.Equal(t, "0.1.0", headers.Get("X-Prometheus-Remote-Write-Version"))
+	require.Equal(t, "Bearer token", headers.Get("Authorization"))
+
+	// the samples are grouped by series and sorted by time, invalid data is dropped
+	list := &xsql.WindowTuples{}
+	for _, m := range []map[string]any{
+		{"name": "temperature", "device": "d1", "zone": 1, "value": 21, "ts": int64(3000)},
+		{"name": "running", "device": "d1", "value": true, "ts": int64(1000)},
+		{"name": "temperature", "device": "d1", "zone": 1, "value": 20, "ts": int64(2000)},
+		{"name": "temperature", "device": "d2", "value": 22, "ts": time.UnixMilli(4000)},
+		{"name": "bad name", "value": 1, "ts": int64(1000)},
+		{"name": "temperature", "value": "abc", "ts": int64(1000)},
+		{"name": "temperature", "value": 1},
+	} {
+		list.Content = append(list.Content, &xsql.Tuple{Message: m})
+	}
+	require.NoError(t, s.CollectList(ctx, list))
+	lines := decodeWriteRequest(t, bodies[1])
+	require.Equal(t, []string{
+		`temperature{device="d1",source="ekuiper",zone="1"} 20 2000`,
+		`temperature{device="d1",source="ekuiper",zone="1"} 21 3000`,
+		`running{device="d1",source="ekuiper"} 1 1000`,
+		`temperature{device="d2",source="ekuiper"} 22 4000`,
+	}, lines)
+	require.Error(t, s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"name": "temperature"}}))
+	require.Len(t, bodies, 2)
+
+	// server errors are retried by the cache while bad requests are not
+	status = http.StatusServiceUnavailable
+	err := s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"name": "temperature", "value": 1, "ts": 1}})
+	require.True(t, errorx.IsIOError(err))
+	status = http.StatusBadRequest
+	err = s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"name": "temperature", "value": 1, "ts": 1}})
+	require.Error(t, err)
+	require.False(t, errorx.IsIOError(err))
+	require.Contains(t, err.Error(), "bad data")
+	require.NoError(t, s.Close(ctx))
+}
+
+func TestSinkDefaultTimestamp(t *testing.T) {
+	var lines []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		lines = decodeWriteRequest(t, b)
+	}))
+	defer server.Close()
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	s := GetSink().(*Sink)
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"url":        server.URL,
+		"metricName": "cpu_usage",
+		"valueField": "usage",
+	}))
+	require.NoError(t, s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"usage": 0.5}}))
+	require.Equal(t, []string{fmt.Sprintf("cpu_usage{} 0.5 %d", timex.GetNowInMilli())}, lines)
+}
@@ -0,0 +1,221 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+)
+
+const acceptHeader = "text/plain;version=0.0.4;q=1,*/*;q=0.1"
+
+type SourceConf struct {
+	// Url is the server address, the datasource is appended as the path like /metrics
+	Url        string            `json:"url"`
+	Datasource string            `json:"datasource"`
+	Headers    map[string]string `json:"headers"`
+	Timeout    cast.DurationConf `json:"timeout"`
+	// Metrics are the metric family names to keep, keep all if empty
+	Metrics []string `json:"metrics"`
+}
+
+// Source scrapes the text exposition endpoint in each interval and produces one tuple per sample
+type Source struct {
+	cfg       *SourceConf
+	endpoint  string
+	metrics   map[string]struct{}
+	client    *http.Client
+	sch       api.StatusChangeHandler
+	connected bool
+}
+
+func (s *Source) Provision(ctx api.StreamContext, props map[string]any) error {
+	cfg := &SourceConf{
+		Timeout: cast.DurationConf(5 * time.Second),
+	}
+	err := cast.MapToStruct(props, cfg)
+	if err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if cfg.Timeout <= 0 {
+		return fmt.Errorf("invalid timeout %v, must be positive", time.Duration(cfg.Timeout))
+	}
+	s.endpoint = cfg.Url
+	if cfg.Datasource != "" && cfg.Datasource != "/" {
+		s.endpoint = strings.TrimSuffix(cfg.Url, "/") + "/" + strings.TrimPrefix(cfg.Datasource, "/")
+	}
+	if err := validateUrl(s.endpoint); err != nil {
+		return err
+	}
+	if len(cfg.Metrics) > 0 {
+		s.metrics = make(map[string]struct{}, len(cfg.Metrics))
+		for _, m := range cfg.Metrics {
+			s.metrics[m] = struct{}{}
+		}
+	}
+	s.client, err = newClient(ctx, props, time.Duration(cfg.Timeout))
+	if err != nil {
+		return err
+	}
+	s.cfg = cfg
+	return nil
+}
+
+func (s *Source) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	ctx.GetLogger().Infof("Prometheus source scrapes %s", s.endpoint)
+	s.sch = sch
+	s.connected = true
+	sch(api.ConnectionConnected, "")
+	return nil
+}
+
+func (s *Source) Pull(ctx api.StreamContext, trigger time.Time, ingest api.TupleIngest, ingestError api.ErrorIngest) {
+	families, err := s.scrape(ctx)
+	if err != nil {
+		if s.connected && errorx.IsIOError(err) {
+			s.connected = false
+			s.sch(api.ConnectionDisconnected, err.Error())
+		}
+		ingestError(ctx, err)
+		return
+	}
+	if !s.connected {
+		s.connected = true
+		s.sch(api.ConnectionConnected, "")
+	}
+	result := toSamples(families, s.metrics, trigger.UnixMilli())
+	if len(result) > 0 {
+		ingest(ctx, result, map[string]any{"url": s.endpoint}, trigger)
+	}
+}
+
+func (s *Source) scrape(ctx api.StreamContext) (map[string]*dto.MetricFamily, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range s.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Accept", acceptHeader)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, errorx.NewIOErr(fmt.Sprintf("scrape %s failed: %v", s.endpoint, err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errorx.NewIOErr(fmt.Sprintf("scrape %s failed: %v", s.endpoint, statusError(resp)))
+	}
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("parse metrics of %s failed: %v", s.endpoint, err)
+	}
+	return families, nil
+}
+
+func (s *Source) Close(ctx api.StreamContext) error {
+	ctx.GetLogger().Infof("Closing prometheus source")
+	if s.client != nil {
+		s.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+// toSamples flattens the metric families into samples like the exposition lines. The histograms and summaries are
+// expanded into the _bucket, _sum and _count samples. The families are sorted by name to keep a stable order.
+func toSamples(families map[string]*dto.MetricFamily, filter map[string]struct{}, now int64) []map[string]any {
+	names := make([]string, 0, len(families))
+	for name := range families {
+		if filter != nil {
+			if _, ok := filter[name]; !ok {
+				continue
+			}
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var result []map[string]any
+	for _, name := range names {
+		mf := families[name]
+		typ := strings.ToLower(mf.GetType().String())
+		for _, m := range mf.GetMetric() {
+			ts := now
+			if m.TimestampMs != nil {
+				ts = m.GetTimestampMs()
+			}
+			add := func(name string, value float64, extra ...string) {
+				labels := make(map[string]any, len(m.GetLabel())+len(extra)/2)
+				for _, lp := range m.GetLabel() {
+					labels[lp.GetName()] = lp.GetValue()
+				}
+				for i := 0; i+1 < len(extra); i += 2 {
+					labels[extra[i]] = extra[i+1]
+				}
+				result = append(result, map[string]any{
+					"name":      name,
+					"type":      typ,
+					"labels":    labels,
+					"value":     value,
+					"timestamp": ts,
+				})
+			}
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				add(name, m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				add(name, m.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				add(name, m.GetUntyped().GetValue())
+			case dto.MetricType_SUMMARY:
+				sm := m.GetSummary()
+				for _, q := range sm.GetQuantile() {
+					add(name, q.GetValue(), "quantile", formatFloat(q.GetQuantile()))
+				}
+				add(name+"_sum", sm.GetSampleSum())
+				add(name+"_count", float64(sm.GetSampleCount()))
+			case dto.MetricType_HISTOGRAM:
+				h := m.GetHistogram()
+				for _, b := range h.GetBucket() {
+					add(name+"_bucket", float64(b.GetCumulativeCount()), "le", formatFloat(b.GetUpperBound()))
+				}
+				add(name+"_sum", h.GetSampleSum())
+				add(name+"_count", float64(h.GetSampleCount()))
+			}
+		}
+	}
+	return result
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func GetSource() api.Source {
+	return &Source{}
+}
+
+var _ api.PullTupleSource = &Source{}
@@ -0,0 +1,154 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+const exposition = `# HELP http_requests_total The total number of requests.
+# TYPE http_requests_total counter
+http_requests_total{method="post",code="200"} 1027 1395066363000
+http_requests_total{method="post",code="400"} 3 1395066363000
+# TYPE temperature gauge
+temperature 21.5
+# HELP rpc_duration_seconds A summary of the RPC duration in seconds.
+# TYPE rpc_duration_seconds summary
+rpc_duration_seconds{quantile="0.5"} 4773
+rpc_duration_seconds_sum 1.7560473e+07
+rpc_duration_seconds_count 2693
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{le="0.1"} 33444
+request_duration_seconds_bucket{le="+Inf"} 144320
+request_duration_seconds_sum 53423
+request_duration_seconds_count 144320
+`
+
+func TestSourceProvision(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	for _, props := range []map[string]any{
+		{},
+		{"url": "localhost:9100"},
+		{"url": "http://localhost:9100", "timeout": "0s"},
+	} {
+		require.Error(t, GetSource().Provision(ctx, props), props)
+	}
+	s := GetSource().(*Source)
+	require.NoError(t, s.Provision(ctx, map[string]any{"url": "http://localhost:9100/", "datasource": "/metrics"}))
+	require.Equal(t, "http://localhost:9100/metrics", s.endpoint)
+	require.NoError(t, s.Provision(ctx, map[string]any{"url": "http://localhost:9100/metrics", "datasource": "/"}))
+	require.Equal(t, "http://localhost:9100/metrics", s.endpoint)
+}
+
+func TestSourcePull(t *testing.T) {
+	status := http.StatusOK
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		if r.URL.Path == "/invalid" {
+			_, _ = w.Write([]byte("temperature{ 21.5\n"))
+			return
+		}
+		if r.URL.Path != "/metrics" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(exposition))
+	}))
+	defer server.Close()
+
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	s := GetSource().(*Source)
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"url":        server.URL,
+		"datasource": "/metrics",
+		"headers":    map[string]any{"Authorization": "Bearer token"},
+	}))
+	var statuses []string
+	require.NoError(t, s.Connect(ctx, func(status string, _ string) {
+		statuses = append(statuses, status)
+	}))
+	var (
+		result []map[string]any
+		meta   map[string]any
+		errs   []error
+	)
+	ingest := func(_ api.StreamContext, data any, m map[string]any, _ time.Time) {
+		result = data.([]map[string]any)
+		meta = m
+	}
+	ingestErr := func(_ api.StreamContext, err error) {
+		errs = append(errs, err)
+	}
+	now := time.UnixMilli(1700000000000)
+	s.Pull(ctx, now, ingest, ingestErr)
+	require.Empty(t, errs)
+	require.Equal(t, "Bearer token", header.Get("Authorization"))
+	require.Equal(t, map[string]any{"url": server.URL + "/metrics"}, meta)
+	sample := func(name, typ string, labels map[string]any, value float64, ts int64) map[string]any {
+		return map[string]any{"name": name, "type": typ, "labels": labels, "value": value, "timestamp": ts}
+	}
+	require.Equal(t, []map[string]any{
+		sample("http_requests_total", "counter", map[string]any{"method": "post", "code": "200"}, 1027, 1395066363000),
+		sample("http_requests_total", "counter", map[string]any{"method": "post", "code": "400"}, 3, 1395066363000),
+		sample("request_duration_seconds_bucket", "histogram", map[string]any{"le": "0.1"}, 33444, now.UnixMilli()),
+		sample("request_duration_seconds_bucket", "histogram", map[string]any{"le": "+Inf"}, 144320, now.UnixMilli()),
+		sample("request_duration_seconds_sum", "histogram", map[string]any{}, 53423, now.UnixMilli()),
+		sample("request_duration_seconds_count", "histogram", map[string]any{}, 144320, now.UnixMilli()),
+		sample("rpc_duration_seconds", "summary", map[string]any{"quantile": "0.5"}, 4773, now.UnixMilli()),
+		sample("rpc_duration_seconds_sum", "summary", map[string]any{}, 1.7560473e+07, now.UnixMilli()),
+		sample("rpc_duration_seconds_count", "summary", map[string]any{}, 2693, now.UnixMilli()),
+		sample("temperature", "gauge", map[string]any{}, 21.5, now.UnixMilli()),
+	}, result)
+
+	// the scrape failure changes the status until recovered
+	status = http.StatusInternalServerError
+	s.Pull(ctx, now, ingest, ingestErr)
+	require.Len(t, errs, 1)
+	require.True(t, errorx.IsIOError(errs[0]))
+	status = http.StatusOK
+	s.Pull(ctx, now, ingest, ingestErr)
+	require.Equal(t, []string{api.ConnectionConnected, api.ConnectionDisconnected, api.ConnectionConnected}, statuses)
+	require.NoError(t, s.Close(ctx))
+
+	// filter the metrics
+	s = GetSource().(*Source)
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"url":        server.URL,
+		"datasource": "/metrics",
+		"metrics":    []any{"temperature"},
+	}))
+	require.NoError(t, s.Connect(ctx, func(string, string) {}))
+	s.Pull(ctx, now, ingest, ingestErr)
+	require.Equal(t, []map[string]any{sample("temperature", "gauge", map[string]any{}, 21.5, now.UnixMilli())}, result)
+
+	// the invalid exposition is not an io error
+	require.NoError(t, s.Provision(ctx, map[string]any{"url": server.URL, "datasource": "/invalid"}))
+	require.NoError(t, s.Connect(ctx, func(string, string) {}))
+	errs = nil
+	s.Pull(ctx, now, ingest, ingestErr)
+	require.Len(t, errs, 1)
+	require.False(t, errorx.IsIOError(errs[0]))
+}
@@ -0,0 +1,179 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+)
+
+// maxResponseBody is the max bytes of the response body to read, the responses of the used APIs are small
+const maxResponseBody = 1 << 20
+
+// client calls the S3 REST API of the object uploading. The requests are retried for the network errors, the server
+// errors and the throttling.
+type client struct {
+	http          *http.Client
+	endpoint      *url.URL
+	bucket        string
+	usePathStyle  bool
+	region        string
+	signer        *v4.Signer
+	cred          aws.Credentials
+	retryCount    int
+	retryInterval time.Duration
+}
+
+type s3Error struct {
+	XMLName xml.Name `xml:"Error"`
+	Code    string   `xml:"Code"`
+	Message string   `xml:"Message"`
+}
+
+type initiateMultipartUploadResult struct {
+	UploadId string `xml:"UploadId"`
+}
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []completedPart `xml:"Part"`
+}
+
+func (c *client) putObject(ctx api.StreamContext, key string, body []byte) error {
+	_, _, err := c.do(ctx, http.MethodPut, key, nil, body)
+	return err
+}
+
+func (c *client) createMultipartUpload(ctx api.StreamContext, key string) (string, error) {
+	resp, _, err := c.do(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil)
+	if err != nil {
+		return "", err
+	}
+	result := &initiateMultipartUploadResult{}
+	if err := xml.Unmarshal(resp, result); err != nil || result.UploadId == "" {
+		return "", fmt.Errorf("invalid create multipart upload response of %s: %s", key, string(resp))
+	}
+	return result.UploadId, nil
+}
+
+func (c *client) uploadPart(ctx api.StreamContext, key, uploadId string, partNumber int, body []byte) (string, error) {
+	_, header, err := c.do(ctx, http.MethodPut, key, url.Values{"partNumber": {strconv.Itoa(partNumber)}, "uploadId": {uploadId}}, body)
+	if err != nil {
+		return "", err
+	}
+	return header.Get("ETag"), nil
+}
+
+func (c *client) completeMultipartUpload(ctx api.StreamContext, key, uploadId string, parts []completedPart) error {
+	body, err := xml.Marshal(&completeMultipartUpload{Parts: parts})
+	if err != nil {
+		return err
+	}
+	_, _, err = c.do(ctx, http.MethodPost, key, url.Values{"uploadId": {uploadId}}, body)
+	return err
+}
+
+func (c *client) abortMultipartUpload(ctx api.StreamContext, key, uploadId string) error {
+	_, _, err := c.do(ctx, http.MethodDelete, key, url.Values{"uploadId": {uploadId}}, nil)
+	return err
+}
+
+// do sends the request with retries and returns the response body and headers
+func (c *client) do(ctx api.StreamContext, method, key string, query url.Values, body []byte) ([]byte, http.Header, error) {
+	var lastErr error
+	for i := 0; i <= c.retryCount; i++ {
+		if i > 0 {
+			ctx.GetLogger().Warnf("retry s3 %s %s in %v: %v", method, key, c.retryInterval, lastErr)
+			time.Sleep(c.retryInterval)
+		}
+		resp, header, retry, err := c.send(method, key, query, body)
+		if err == nil {
+			return resp, header, nil
+		}
+		if !retry {
+			return nil, nil, err
+		}
+		lastErr = err
+	}
+	return nil, nil, errorx.NewIOErr(lastErr.Error())
+}
+
+// send sends the request once and returns whether the error can be retried. The requests are not bound to the rule
+// context because the objects are completed when closing the sink after the context is done.
+func (c *client) send(method, key string, query url.Values, body []byte) ([]byte, http.Header, bool, error) {
+	req, err := http.NewRequestWithContext(context.Background(), method, c.objectUrl(key, query), bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, false, err
+	}
+	req.ContentLength = int64(len(body))
+	if err := c.sign(req, hashHex(body), time.Now()); err != nil {
+		return nil, nil, false, fmt.Errorf("s3 %s %s failed to sign: %v", method, key, err)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, nil, true, fmt.Errorf("s3 %s %s failed: %v", method, key, err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if err != nil {
+		return nil, nil, true, fmt.Errorf("s3 %s %s failed to read response: %v", method, key, err)
+	}
+	// the complete multipart upload may respond the error with the status 200
+	e := &s3Error{}
+	if resp.StatusCode/100 == 2 && (!bytes.Contains(respBody, []byte("<Error>")) || xml.Unmarshal(respBody, e) != nil) {
+		return respBody, resp.Header, false, nil
+	}
+	if e.Code == "" {
+		_ = xml.Unmarshal(respBody, e)
+	}
+	retry := resp.StatusCode/100 == 5 || resp.StatusCode/100 == 2 || resp.StatusCode == http.StatusTooManyRequests
+	return nil, nil, retry, fmt.Errorf("s3 %s %s failed with status %s: %s %s", method, key, resp.Status, e.Code, e.Message)
+}
+
+// objectUrl builds the url of the object by the path style like http://endpoint/bucket/key or the virtual hosted style
+// like https://bucket.endpoint/key
+func (c *client) objectUrl(key string, query url.Values) string {
+	u := *c.endpoint
+	path := strings.TrimSuffix(u.Path, "/") + "/" + uriEncode(key, false)
+	if c.usePathStyle {
+		path = strings.TrimSuffix(u.Path, "/") + "/" + c.bucket + "/" + uriEncode(key, false)
+	} else {
+		u.Host = c.bucket + "." + u.Host
+	}
+	result := u.Scheme + "://" + u.Host + path
+	if len(query) > 0 {
+		result += "?" + query.Encode()
+	}
+	return result
+}
@@ -0,0 +1,144 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/io/file"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/httpx"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cert"
+)
+
+// minPartSize is the min size of the parts except the last one required by S3
+const minPartSize = 5 << 20
+
+type storageConf struct {
+	// Endpoint is the server address like http://127.0.0.1:9000, the AWS endpoint of the region is used if not set
+	Endpoint        string `json:"endpoint"`
+	Region          string `json:"region"`
+	Bucket          string `json:"bucket"`
+	AccessKeyId     string `json:"accessKeyId"`
+	SecretAccessKey string `json:"secretAccessKey"`
+	SessionToken    string `json:"sessionToken"`
+	// UsePathStyle puts the bucket in the path instead of the host, which is usually required by MinIO
+	UsePathStyle bool              `json:"usePathStyle"`
+	PartSize     int64             `json:"partSize"`
+	Timeout      cast.DurationConf `json:"timeout"`
+	// RetryCount and RetryInterval are for each failed request such as uploading a part
+	RetryCount    int               `json:"retryCount"`
+	RetryInterval cast.DurationConf `json:"retryInterval"`
+}
+
+// storage writes the files of the file sink as the objects of the bucket. The file path is the object key.
+type storage struct {
+	cfg    *storageConf
+	client *client
+}
+
+func (s *storage) Provision(ctx api.StreamContext, props map[string]any) error {
+	cfg := &storageConf{
+		Region:        "us-east-1",
+		PartSize:      minPartSize,
+		Timeout:       cast.DurationConf(time.Minute),
+		RetryCount:    3,
+		RetryInterval: cast.DurationConf(time.Second),
+	}
+	err := cast.MapToStruct(props, cfg)
+	if err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if cfg.Bucket == "" {
+		return fmt.Errorf("bucket is required")
+	}
+	if cfg.AccessKeyId == "" || cfg.SecretAccessKey == "" {
+		return fmt.Errorf("accessKeyId and secretAccessKey are required")
+	}
+	if cfg.Region == "" {
+		return fmt.Errorf("region is required")
+	}
+	if cfg.PartSize < minPartSize {
+		return fmt.Errorf("partSize must be at least %d", minPartSize)
+	}
+	if cfg.Timeout <= 0 {
+		return fmt.Errorf("invalid timeout %v, must be positive", time.Duration(cfg.Timeout))
+	}
+	if cfg.RetryCount < 0 || cfg.RetryInterval < 0 {
+		return fmt.Errorf("retryCount and retryInterval must not be negative")
+	}
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+	}
+	if err := httpx.IsHttpUrl(endpoint); err != nil {
+		return fmt.Errorf("invalid endpoint %s: %v", endpoint, err)
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint %s: %v", endpoint, err)
+	}
+	tlsConf, err := cert.GenTLSConfig(ctx, props)
+	if err != nil {
+		return fmt.Errorf("error configuring tls: %v", err)
+	}
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.TLSClientConfig = tlsConf
+	tr.DialContext = httpx.GetSSRFDialContext(time.Duration(cfg.Timeout))
+	s.client = &client{
+		http:          &http.Client{Transport: tr, Timeout: time.Duration(cfg.Timeout)},
+		endpoint:      u,
+		bucket:        cfg.Bucket,
+		usePathStyle:  cfg.UsePathStyle,
+		region:        cfg.Region,
+		signer:        newSigner(),
+		cred:          aws.Credentials{AccessKeyID: cfg.AccessKeyId, SecretAccessKey: cfg.SecretAccessKey, SessionToken: cfg.SessionToken},
+		retryCount:    cfg.RetryCount,
+		retryInterval: time.Duration(cfg.RetryInterval),
+	}
+	s.cfg = cfg
+	return nil
+}
+
+func (s *storage) Create(ctx api.StreamContext, name string) (file.StorageFile, error) {
+	key := strings.TrimPrefix(name, "/")
+	if key == "" {
+		return nil, fmt.Errorf("object key is empty")
+	}
+	ctx.GetLogger().Infof("Create s3 object %s in bucket %s", key, s.cfg.Bucket)
+	return &upload{ctx: ctx, client: s.client, key: key, partSize: int(s.cfg.PartSize)}, nil
+}
+
+func (s *storage) Close(ctx api.StreamContext) error {
+	ctx.GetLogger().Infof("Closing s3 storage")
+	if s.client != nil {
+		s.client.http.CloseIdleConnections()
+	}
+	return nil
+}
+
+// GetSink returns the file sink which writes the rolled files to the S3 compatible object storage
+func GetSink() api.Sink {
+	return file.NewSink(&storage{})
+}
+
+var _ file.Storage = &storage{}
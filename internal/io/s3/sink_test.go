@@ -0,0 +1,280 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+func init() {
+	conf.InitConf()
+	conf.Config.Basic.EnablePrivateNet = true
+}
+
+// stubS3 is an in-memory S3 server of the path style which supports the object and multipart upload APIs
+type stubS3 struct {
+	sync.Mutex
+	objects map[string][]byte
+	uploads map[string]map[int][]byte
+	nextId  int
+	// failParts is the number of the next part uploads to fail
+	failParts int
+	aborted   int
+	parts     int
+	denied    int
+}
+
+func newStubS3() *stubS3 {
+	return &stubS3{objects: map[string][]byte{}, uploads: map[string]map[int][]byte{}}
+}
+
+func (s *stubS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") {
+		s.denied++
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>"))
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	if r.Header.Get(amzHashHeader) != hashHex(body) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("<Error><Code>XAmzContentSHA256Mismatch</Code></Error>"))
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	query := r.URL.Query()
+	uploadId := query.Get("uploadId")
+	switch {
+	case r.Method == http.MethodPut && uploadId == "":
+		s.objects[key] = body
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		s.nextId++
+		id := fmt.Sprintf("upload%d", s.nextId)
+		s.uploads[id] = map[int][]byte{}
+		_, _ = fmt.Fprintf(w, "<InitiateMultipartUploadResult><Key>%s</Key><UploadId>%s</UploadId></InitiateMultipartUploadResult>", key, id)
+	case r.Method == http.MethodPut:
+		if s.failParts > 0 {
+			s.failParts--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("<Error><Code>SlowDown</Code><Message>Please reduce your request rate.</Message></Error>"))
+			return
+		}
+		n, _ := strconv.Atoi(query.Get("partNumber"))
+		s.uploads[uploadId][n] = body
+		s.parts++
+		w.Header().Set("ETag", fmt.Sprintf(`"etag%d"`, n))
+	case r.Method == http.MethodPost:
+		req := &completeMultipartUpload{}
+		_ = xml.Unmarshal(body, req)
+		var data []byte
+		for i, p := range req.Parts {
+			if p.PartNumber != i+1 || p.ETag != fmt.Sprintf(`"etag%d"`, i+1) {
+				_, _ = w.Write([]byte("<Error><Code>InvalidPart</Code></Error>"))
+				return
+			}
+			data = append(data, s.uploads[uploadId][p.PartNumber]...)
+		}
+		delete(s.uploads, uploadId)
+		s.objects[key] = data
+		_, _ = fmt.Fprintf(w, "<CompleteMultipartUploadResult><Key>%s</Key></CompleteMultipartUploadResult>", key)
+	case r.Method == http.MethodDelete:
+		delete(s.uploads, uploadId)
+		s.aborted++
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *stubS3) keys() []string {
+	s.Lock()
+	defer s.Unlock()
+	keys := make([]string, 0, len(s.objects))
+	for k := range s.objects {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func stubProps(url string) map[string]any {
+	return map[string]any{
+		"endpoint":        url,
+		"bucket":          "archive",
+		"accessKeyId":     "key",
+		"secretAccessKey": "secret",
+		"usePathStyle":    true,
+		"retryInterval":   "10ms",
+	}
+}
+
+func TestProvision(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	for _, props := range []map[string]any{
+		{},
+		{"bucket": "archive"},
+		{"bucket": "archive", "accessKeyId": "key", "secretAccessKey": "secret", "region": ""},
+		{"bucket": "archive", "accessKeyId": "key", "secretAccessKey": "secret", "partSize": 1024},
+		{"bucket": "archive", "accessKeyId": "key", "secretAccessKey": "secret", "retryCount": -1},
+		{"bucket": "archive", "accessKeyId": "key", "secretAccessKey": "secret", "endpoint": "ftp://127.0.0.1"},
+	} {
+		require.Error(t, (&storage{}).Provision(ctx, props), props)
+	}
+	s := &storage{}
+	require.NoError(t, s.Provision(ctx, map[string]any{"bucket": "archive", "accessKeyId": "key", "secretAccessKey": "secret", "region": "eu-west-1"}))
+	require.Equal(t, "https://archive.s3.eu-west-1.amazonaws.com/dt%3D1/a.json?partNumber=1&uploadId=x", s.client.objectUrl("dt=1/a.json", map[string][]string{"uploadId": {"x"}, "partNumber": {"1"}}))
+	// the file sink props are validated too
+	require.Error(t, GetSink().Provision(ctx, map[string]any{"bucket": "archive", "accessKeyId": "key", "secretAccessKey": "secret", "rollingCount": -1}))
+}
+
+func TestUpload(t *testing.T) {
+	stub := newStubS3()
+	server := httptest.NewServer(stub)
+	defer server.Close()
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	s := &storage{}
+	require.NoError(t, s.Provision(ctx, stubProps(server.URL)))
+
+	create := func(name string) *upload {
+		f, err := s.Create(ctx, name)
+		require.NoError(t, err)
+		u := f.(*upload)
+		// use a small part size to test the multipart upload
+		u.partSize = 4
+		return u
+	}
+
+	// the small object is uploaded by one request
+	u := create("/small.txt")
+	require.Equal(t, "small.txt", u.Name())
+	_, err := u.Write([]byte("abc"))
+	require.NoError(t, err)
+	require.NoError(t, u.Close())
+	require.Equal(t, []byte("abc"), stub.objects["archive/small.txt"])
+
+	// the parts are uploaded when written and the failed parts are retried
+	stub.failParts = 2
+	u = create("dt=2026-10-18/big.txt")
+	for _, p := range []string{"abcde", "fgh", "ijklm"} {
+		_, err = u.Write([]byte(p))
+		require.NoError(t, err)
+	}
+	require.Equal(t, 3, stub.parts)
+	require.NoError(t, u.Close())
+	require.Equal(t, 4, stub.parts)
+	require.Equal(t, []byte("abcdefghijklm"), stub.objects["archive/dt=2026-10-18/big.txt"])
+	require.Empty(t, stub.uploads)
+	// closing twice is a no-op
+	require.NoError(t, u.Close())
+
+	// the upload is aborted if the part still fails after the retries
+	stub.failParts = 10
+	u = create("failed.txt")
+	_, err = u.Write([]byte("abcdefgh"))
+	require.Error(t, err)
+	require.True(t, errorx.IsIOError(err))
+	_, err = u.Write([]byte("a"))
+	require.Error(t, err)
+	require.Error(t, u.Close())
+	require.Equal(t, 1, stub.aborted)
+	require.Empty(t, stub.uploads)
+	require.NotContains(t, stub.objects, "archive/failed.txt")
+	stub.failParts = 0
+
+	// nothing is uploaded if nothing is written
+	u = create("empty.txt")
+	require.NoError(t, u.Close())
+	require.NotContains(t, stub.objects, "archive/empty.txt")
+
+	// the client errors are not retried
+	props := stubProps(server.URL)
+	props["accessKeyId"] = "other"
+	s2 := &storage{}
+	require.NoError(t, s2.Provision(ctx, props))
+	f, err := s2.Create(ctx, "denied.txt")
+	require.NoError(t, err)
+	_, _ = f.Write([]byte("abc"))
+	err = f.Close()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "AccessDenied")
+	require.Equal(t, 1, stub.denied)
+	require.NoError(t, s.Close(ctx))
+}
+
+func TestSinkCollect(t *testing.T) {
+	stub := newStubS3()
+	server := httptest.NewServer(stub)
+	defer server.Close()
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	props := stubProps(server.URL)
+	props["path"] = "dt={{.date}}/device={{.id}}/data.json"
+	props["rollingCount"] = 2
+	props["compression"] = "gzip"
+	s := GetSink()
+	require.NoError(t, s.Provision(ctx, props))
+	require.NoError(t, s.Connect(ctx, func(string, string) {}))
+	collect := func(date, id, data string) {
+		require.NoError(t, s.(api.BytesCollector).Collect(ctx, &xsql.RawTuple{
+			Rawdata: []byte(data),
+			Props:   map[string]string{props["path"].(string): fmt.Sprintf("dt=%s/device=%s/data.json", date, id)},
+		}))
+	}
+	timex.Set(1000)
+	collect("2026-10-18", "d1", `{"a":1}`)
+	collect("2026-10-18", "d2", `{"a":2}`)
+	// the object is rolled by the count
+	collect("2026-10-18", "d1", `{"a":3}`)
+	timex.Set(2000)
+	collect("2026-10-18", "d1", `{"a":4}`)
+	require.Equal(t, []string{"archive/dt=2026-10-18/device=d1/data-1000.json"}, stub.keys())
+	// the other objects are completed when closing
+	require.NoError(t, s.Close(ctx))
+	require.Equal(t, []string{
+		"archive/dt=2026-10-18/device=d1/data-1000.json",
+		"archive/dt=2026-10-18/device=d1/data-2000.json",
+		"archive/dt=2026-10-18/device=d2/data-1000.json",
+	}, stub.keys())
+	require.Equal(t, "{\"a\":1}\n{\"a\":3}", readGzip(t, stub.objects["archive/dt=2026-10-18/device=d1/data-1000.json"]))
+	require.Equal(t, `{"a":4}`, readGzip(t, stub.objects["archive/dt=2026-10-18/device=d1/data-2000.json"]))
+	require.Equal(t, `{"a":2}`, readGzip(t, stub.objects["archive/dt=2026-10-18/device=d2/data-1000.json"]))
+}
+
+func readGzip(t *testing.T, b []byte) string {
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(data)
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/io/file"
+)
+
+var errClosed = errors.New("s3 object is closed")
+
+// upload streams the written data of an object by the multipart upload. Each part is uploaded once the buffer reaches
+// the part size, so only one part is kept in memory. The small object which never reaches the part size is uploaded
+// by one request when closing.
+type upload struct {
+	ctx      api.StreamContext
+	client   *client
+	key      string
+	partSize int
+
+	buf      bytes.Buffer
+	written  bool
+	uploadId string
+	parts    []completedPart
+	// err is the error of the failed part, the object cannot be written anymore
+	err error
+}
+
+func (u *upload) Write(p []byte) (int, error) {
+	if u.err != nil {
+		return 0, u.err
+	}
+	if len(p) > 0 {
+		u.written = true
+	}
+	u.buf.Write(p)
+	for u.buf.Len() >= u.partSize {
+		if err := u.uploadPart(u.buf.Next(u.partSize)); err != nil {
+			u.err = err
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (u *upload) uploadPart(data []byte) error {
+	if u.uploadId == "" {
+		id, err := u.client.createMultipartUpload(u.ctx, u.key)
+		if err != nil {
+			return err
+		}
+		u.uploadId = id
+	}
+	partNumber := len(u.parts) + 1
+	etag, err := u.client.uploadPart(u.ctx, u.key, u.uploadId, partNumber, data)
+	if err != nil {
+		return err
+	}
+	u.ctx.GetLogger().Debugf("uploaded part %d of s3 object %s", partNumber, u.key)
+	u.parts = append(u.parts, completedPart{PartNumber: partNumber, ETag: etag})
+	return nil
+}
+
+func (u *upload) Name() string {
+	return u.key
+}
+
+// Sync does nothing because the parts can only be uploaded by the part size
+func (u *upload) Sync() error {
+	return nil
+}
+
+// Close uploads the remaining data and completes the object. The multipart upload is aborted if it fails so that
+// the uploaded parts are not left in the bucket.
+func (u *upload) Close() error {
+	if errors.Is(u.err, errClosed) {
+		return nil
+	}
+	err := u.err
+	u.err = errClosed
+	if err == nil {
+		err = u.complete()
+	}
+	if err != nil && u.uploadId != "" {
+		if e := u.client.abortMultipartUpload(u.ctx, u.key, u.uploadId); e != nil {
+			u.ctx.GetLogger().Errorf("fail to abort the multipart upload of s3 object %s: %v", u.key, e)
+		}
+	}
+	u.buf.Reset()
+	if err != nil {
+		return fmt.Errorf("fail to upload s3 object %s: %v", u.key, err)
+	}
+	return nil
+}
+
+func (u *upload) complete() error {
+	if !u.written {
+		return nil
+	}
+	if u.uploadId == "" {
+		return u.client.putObject(u.ctx, u.key, u.buf.Bytes())
+	}
+	if u.buf.Len() > 0 {
+		if err := u.uploadPart(u.buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	if err := u.client.completeMultipartUpload(u.ctx, u.key, u.uploadId, u.parts); err != nil {
+		return err
+	}
+	u.ctx.GetLogger().Infof("Completed s3 object %s with %d parts", u.key, len(u.parts))
+	return nil
+}
+
+var _ file.StorageFile = &upload{}
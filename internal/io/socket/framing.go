@@ -0,0 +1,177 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package socket
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+const (
+	FramingNewline = "newline"
+	FramingLength  = "length"
+	FramingFixed   = "fixed"
+)
+
+// FramingConf defines how the frames are separated in the stream of a TCP connection or a UDP datagram
+type FramingConf struct {
+	// Framing is newline, length or fixed
+	Framing string `json:"framing"`
+	// LengthSize is the byte count of the big endian length prefix of the length framing, 1, 2 or 4
+	LengthSize int `json:"lengthSize"`
+	// FrameSize is the byte count of each frame of the fixed framing
+	FrameSize int `json:"frameSize"`
+	// MaxFrameLength is the maximum byte count of a frame
+	MaxFrameLength int `json:"maxFrameLength"`
+}
+
+// ParseFraming reads and validates the framing properties
+func ParseFraming(props map[string]any) (*FramingConf, error) {
+	fc := &FramingConf{
+		Framing:        FramingNewline,
+		LengthSize:     4,
+		MaxFrameLength: 1024 * 1024,
+	}
+	if err := cast.MapToStruct(props, fc); err != nil {
+		return nil, fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if fc.MaxFrameLength <= 0 {
+		return nil, fmt.Errorf("invalid maxFrameLength %d, must be positive", fc.MaxFrameLength)
+	}
+	switch fc.Framing {
+	case FramingNewline:
+	case FramingLength:
+		if fc.LengthSize != 1 && fc.LengthSize != 2 && fc.LengthSize != 4 {
+			return nil, fmt.Errorf("invalid lengthSize %d, must be 1, 2 or 4", fc.LengthSize)
+		}
+	case FramingFixed:
+		if fc.FrameSize <= 0 || fc.FrameSize > fc.MaxFrameLength {
+			return nil, fmt.Errorf("invalid frameSize %d, must be in range 1-%d", fc.FrameSize, fc.MaxFrameLength)
+		}
+	default:
+		return nil, fmt.Errorf("invalid framing %s, must be newline, length or fixed", fc.Framing)
+	}
+	return fc, nil
+}
+
+// SplitFunc returns the split function to read the frames by a bufio.Scanner
+func (fc *FramingConf) SplitFunc() bufio.SplitFunc {
+	switch fc.Framing {
+	case FramingLength:
+		return fc.splitLength
+	case FramingFixed:
+		return fc.splitFixed
+	default:
+		return fc.splitNewline
+	}
+}
+
+// BufferSize is the maximum buffer size of the scanner to read a frame
+func (fc *FramingConf) BufferSize() int {
+	return fc.MaxFrameLength + fc.LengthSize + 2
+}
+
+func (fc *FramingConf) splitNewline(data []byte, atEOF bool) (int, []byte, error) {
+	advance, token, err := bufio.ScanLines(data, atEOF)
+	if err != nil {
+		return advance, token, err
+	}
+	if len(token) > fc.MaxFrameLength || (token == nil && !atEOF && len(data) > fc.MaxFrameLength) {
+		return 0, nil, fmt.Errorf("frame exceeds maxFrameLength %d", fc.MaxFrameLength)
+	}
+	return advance, token, nil
+}
+
+func (fc *FramingConf) splitLength(data []byte, atEOF bool) (int, []byte, error) {
+	if len(data) < fc.LengthSize {
+		return truncated(data, atEOF)
+	}
+	var length int
+	switch fc.LengthSize {
+	case 1:
+		length = int(data[0])
+	case 2:
+		length = int(binary.BigEndian.Uint16(data))
+	default:
+		length = int(binary.BigEndian.Uint32(data))
+	}
+	if length > fc.MaxFrameLength || length < 0 {
+		return 0, nil, fmt.Errorf("frame length %d exceeds maxFrameLength %d", length, fc.MaxFrameLength)
+	}
+	end := fc.LengthSize + length
+	if len(data) < end {
+		return truncated(data, atEOF)
+	}
+	return end, data[fc.LengthSize:end], nil
+}
+
+func (fc *FramingConf) splitFixed(data []byte, atEOF bool) (int, []byte, error) {
+	if len(data) < fc.FrameSize {
+		return truncated(data, atEOF)
+	}
+	return fc.FrameSize, data[:fc.FrameSize], nil
+}
+
+// truncated requests more data or reports the incomplete frame at the end of the stream
+func truncated(data []byte, atEOF bool) (int, []byte, error) {
+	if !atEOF {
+		return 0, nil, nil
+	}
+	if len(data) == 0 {
+		return 0, nil, nil
+	}
+	return 0, nil, fmt.Errorf("incomplete frame of %d bytes at the end", len(data))
+}
+
+// Frame encodes the payload as a frame
+func (fc *FramingConf) Frame(payload []byte) ([]byte, error) {
+	if len(payload) > fc.MaxFrameLength {
+		return nil, fmt.Errorf("payload length %d exceeds maxFrameLength %d", len(payload), fc.MaxFrameLength)
+	}
+	switch fc.Framing {
+	case FramingLength:
+		if fc.LengthSize < 4 && len(payload) >= 1<<(8*fc.LengthSize) {
+			return nil, fmt.Errorf("payload length %d exceeds the limit of lengthSize %d", len(payload), fc.LengthSize)
+		}
+		frame := make([]byte, fc.LengthSize+len(payload))
+		switch fc.LengthSize {
+		case 1:
+			frame[0] = byte(len(payload))
+		case 2:
+			binary.BigEndian.PutUint16(frame, uint16(len(payload)))
+		default:
+			binary.BigEndian.PutUint32(frame, uint32(len(payload)))
+		}
+		copy(frame[fc.LengthSize:], payload)
+		return frame, nil
+	case FramingFixed:
+		if len(payload) != fc.FrameSize {
+			return nil, fmt.Errorf("payload length %d does not match frameSize %d", len(payload), fc.FrameSize)
+		}
+		return payload, nil
+	default:
+		if bytes.ContainsAny(payload, "\n") {
+			return nil, fmt.Errorf("payload must not contain newline for newline framing")
+		}
+		frame := make([]byte, len(payload)+1)
+		copy(frame, payload)
+		frame[len(payload)] = '\n'
+		return frame, nil
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package socket
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func scanAll(fc *FramingConf, data []byte) ([]string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 16), fc.BufferSize())
+	scanner.Split(fc.SplitFunc())
+	var frames []string
+	for scanner.Scan() {
+		frames = append(frames, scanner.Text())
+	}
+	return frames, scanner.Err()
+}
+
+func TestFramingRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		props   map[string]any
+		frames  []string
+		encoded []byte
+	}{
+		{
+			name:    "newline",
+			props:   map[string]any{},
+			frames:  []string{`{"a":1}`, "", `{"a":2}`},
+			encoded: []byte("{\"a\":1}\n\n{\"a\":2}\n"),
+		},
+		{
+			name:    "length1",
+			props:   map[string]any{"framing": "length", "lengthSize": 1},
+			frames:  []string{"ab", "c"},
+			encoded: []byte{2, 'a', 'b', 1, 'c'},
+		},
+		{
+			name:    "length2",
+			props:   map[string]any{"framing": "length", "lengthSize": 2},
+			frames:  []string{"ab", ""},
+			encoded: []byte{0, 2, 'a', 'b', 0, 0},
+		},
+		{
+			name:    "length4",
+			props:   map[string]any{"framing": "length"},
+			frames:  []string{"abc"},
+			encoded: []byte{0, 0, 0, 3, 'a', 'b', 'c'},
+		},
+		{
+			name:    "fixed",
+			props:   map[string]any{"framing": "fixed", "frameSize": 3},
+			frames:  []string{"abc", "def"},
+			encoded: []byte("abcdef"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc, err := ParseFraming(tt.props)
+			require.NoError(t, err)
+			var encoded []byte
+			for _, f := range tt.frames {
+				b, err := fc.Frame([]byte(f))
+				require.NoError(t, err)
+				encoded = append(encoded, b...)
+			}
+			require.Equal(t, tt.encoded, encoded)
+			frames, err := scanAll(fc, encoded)
+			require.NoError(t, err)
+			require.Equal(t, tt.frames, frames)
+		})
+	}
+}
+
+func TestFramingSplit(t *testing.T) {
+	tests := []struct {
+		name   string
+		props  map[string]any
+		data   []byte
+		frames []string
+		err    string
+	}{
+		{
+			name:   "crlf and no trailing newline",
+			props:  map[string]any{},
+			data:   []byte("a\r\nb"),
+			frames: []string{"a", "b"},
+		},
+		{
+			name:   "newline too long",
+			props:  map[string]any{"maxFrameLength": 4},
+			data:   []byte("abcdefghij\n"),
+			frames: nil,
+			err:    "frame exceeds maxFrameLength 4",
+		},
+		{
+			name:   "length too long",
+			props:  map[string]any{"framing": "length", "lengthSize": 2, "maxFrameLength": 4},
+			data:   []byte{0, 5, 'a', 'b', 'c', 'd', 'e'},
+			frames: nil,
+			err:    "frame length 5 exceeds maxFrameLength 4",
+		},
+		{
+			name:   "length truncated",
+			props:  map[string]any{"framing": "length", "lengthSize": 1},
+			data:   []byte{1, 'a', 3, 'b'},
+			frames: []string{"a"},
+			err:    "incomplete frame of 2 bytes at the end",
+		},
+		{
+			name:   "fixed truncated",
+			props:  map[string]any{"framing": "fixed", "frameSize": 2},
+			data:   []byte("abc"),
+			frames: []string{"ab"},
+			err:    "incomplete frame of 1 bytes at the end",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc, err := ParseFraming(tt.props)
+			require.NoError(t, err)
+			frames, err := scanAll(fc, tt.data)
+			require.Equal(t, tt.frames, frames)
+			if tt.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestFrameErr(t *testing.T) {
+	tests := []struct {
+		props   map[string]any
+		payload string
+		err     string
+	}{
+		{
+			props:   map[string]any{},
+			payload: "a\nb",
+			err:     "payload must not contain newline for newline framing",
+		},
+		{
+			props:   map[string]any{"framing": "fixed", "frameSize": 2},
+			payload: "abc",
+			err:     "payload length 3 does not match frameSize 2",
+		},
+		{
+			props:   map[string]any{"framing": "length", "maxFrameLength": 2},
+			payload: "abc",
+			err:     "payload length 3 exceeds maxFrameLength 2",
+		},
+		{
+			props:   map[string]any{"framing": "length", "lengthSize": 1},
+			payload: string(make([]byte, 256)),
+			err:     "payload length 256 exceeds the limit of lengthSize 1",
+		},
+	}
+	for _, tt := range tests {
+		fc, err := ParseFraming(tt.props)
+		require.NoError(t, err)
+		_, err = fc.Frame([]byte(tt.payload))
+		require.EqualError(t, err, tt.err)
+	}
+}
+
+func TestParseFramingErr(t *testing.T) {
+	tests := []struct {
+		props map[string]any
+		err   string
+	}{
+		{
+			props: map[string]any{"framing": "xml"},
+			err:   "invalid framing xml, must be newline, length or fixed",
+		},
+		{
+			props: map[string]any{"framing": "length", "lengthSize": 3},
+			err:   "invalid lengthSize 3, must be 1, 2 or 4",
+		},
+		{
+			props: map[string]any{"framing": "fixed"},
+			err:   "invalid frameSize 0, must be in range 1-1048576",
+		},
+		{
+			props: map[string]any{"maxFrameLength": 0},
+			err:   "invalid maxFrameLength 0, must be positive",
+		},
+	}
+	for _, tt := range tests {
+		_, err := ParseFraming(tt.props)
+		require.EqualError(t, err, tt.err)
+	}
+}
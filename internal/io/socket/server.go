@@ -0,0 +1,199 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package socket
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+)
+
+const (
+	ProtocolTCP = "tcp"
+	ProtocolUDP = "udp"
+	// maxDatagramSize is the maximum size of a UDP datagram
+	maxDatagramSize = 65535
+)
+
+// FrameHandler handles a frame received from the remote address. The frame is owned by the handler.
+type FrameHandler func(frame []byte, remoteAddr string)
+
+// Server listens on a TCP or UDP address and splits the received data into frames. For TCP, each connection is a
+// stream of frames. For UDP, each datagram is split into frames independently.
+type Server struct {
+	protocol   string
+	addr       string
+	split      bufio.SplitFunc
+	bufferSize int
+
+	ln net.Listener
+	pc net.PacketConn
+
+	lock   sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewServer creates a server which splits the data by the split function with the maximum frame buffer size
+func NewServer(protocol, addr string, split bufio.SplitFunc, bufferSize int) *Server {
+	return &Server{
+		protocol:   protocol,
+		addr:       addr,
+		split:      split,
+		bufferSize: bufferSize,
+		conns:      make(map[net.Conn]struct{}),
+	}
+}
+
+// ValidateProtocol checks the protocol is tcp or udp
+func ValidateProtocol(protocol string) error {
+	if protocol != ProtocolTCP && protocol != ProtocolUDP {
+		return fmt.Errorf("invalid protocol %s, must be tcp or udp", protocol)
+	}
+	return nil
+}
+
+// Listen binds the address so that the error is found before serving
+func (s *Server) Listen() error {
+	var err error
+	if s.protocol == ProtocolUDP {
+		s.pc, err = net.ListenPacket("udp", s.addr)
+	} else {
+		s.ln, err = net.Listen("tcp", s.addr)
+	}
+	if err != nil {
+		return fmt.Errorf("listen %s %s failed: %v", s.protocol, s.addr, err)
+	}
+	return nil
+}
+
+// Addr returns the actual listening address
+func (s *Server) Addr() net.Addr {
+	if s.pc != nil {
+		return s.pc.LocalAddr()
+	}
+	return s.ln.Addr()
+}
+
+// Serve reads the frames in the background until the server is closed
+func (s *Server) Serve(ctx api.StreamContext, handler FrameHandler) {
+	s.wg.Add(1)
+	if s.protocol == ProtocolUDP {
+		go s.serveUDP(ctx, handler)
+	} else {
+		go s.serveTCP(ctx, handler)
+	}
+}
+
+func (s *Server) serveUDP(ctx api.StreamContext, handler FrameHandler) {
+	defer s.wg.Done()
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, addr, err := s.pc.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			ctx.GetLogger().Errorf("read udp datagram failed: %v", err)
+			continue
+		}
+		s.scan(ctx, bytes.NewReader(buf[:n]), addr.String(), handler)
+	}
+}
+
+func (s *Server) serveTCP(ctx api.StreamContext, handler FrameHandler) {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			ctx.GetLogger().Errorf("accept tcp connection failed: %v", err)
+			continue
+		}
+		if !s.track(conn) {
+			_ = conn.Close()
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer s.untrack(conn)
+			remote := conn.RemoteAddr().String()
+			ctx.GetLogger().Debugf("tcp connection from %s is accepted", remote)
+			s.scan(ctx, conn, remote, handler)
+			ctx.GetLogger().Debugf("tcp connection from %s is closed", remote)
+		}()
+	}
+}
+
+// scan reads the frames until the end. The invalid data ends the scan, which closes the TCP connection.
+func (s *Server) scan(ctx api.StreamContext, r io.Reader, remote string, handler FrameHandler) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, min(4096, s.bufferSize)), s.bufferSize)
+	scanner.Split(s.split)
+	for scanner.Scan() {
+		frame := make([]byte, len(scanner.Bytes()))
+		copy(frame, scanner.Bytes())
+		handler(frame, remote)
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, net.ErrClosed) {
+		ctx.GetLogger().Warnf("read frames from %s failed: %v", remote, err)
+	}
+}
+
+func (s *Server) track(conn net.Conn) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	return true
+}
+
+func (s *Server) untrack(conn net.Conn) {
+	s.lock.Lock()
+	delete(s.conns, conn)
+	s.lock.Unlock()
+	_ = conn.Close()
+}
+
+// Close stops listening, closes all connections and waits for the handlers to exit
+func (s *Server) Close() error {
+	s.lock.Lock()
+	s.closed = true
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.lock.Unlock()
+	var err error
+	if s.pc != nil {
+		err = s.pc.Close()
+	}
+	if s.ln != nil {
+		err = s.ln.Close()
+	}
+	s.wg.Wait()
+	return err
+}
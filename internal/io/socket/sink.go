@@ -0,0 +1,145 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package socket
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+)
+
+type SinkConf struct {
+	// Protocol is tcp or udp
+	Protocol string `json:"protocol"`
+	// Server is the remote address like 127.0.0.1:9000
+	Server string `json:"server"`
+	// Timeout is the timeout to connect and write
+	Timeout cast.DurationConf `json:"timeout"`
+}
+
+// Sink writes each payload as a frame to the remote address. The TCP connection is reconnected lazily after it
+// breaks.
+type Sink struct {
+	cfg     *SinkConf
+	framing *FramingConf
+	conn    net.Conn
+}
+
+func (s *Sink) Provision(_ api.StreamContext, props map[string]any) error {
+	cfg := &SinkConf{
+		Protocol: ProtocolTCP,
+		Timeout:  cast.DurationConf(5 * time.Second),
+	}
+	err := cast.MapToStruct(props, cfg)
+	if err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if err := ValidateProtocol(cfg.Protocol); err != nil {
+		return err
+	}
+	if cfg.Server == "" {
+		return fmt.Errorf("server is required")
+	}
+	if _, _, err := net.SplitHostPort(cfg.Server); err != nil {
+		return fmt.Errorf("invalid server %s: %v", cfg.Server, err)
+	}
+	if cfg.Timeout <= 0 {
+		return fmt.Errorf("invalid timeout %v, must be positive", time.Duration(cfg.Timeout))
+	}
+	s.framing, err = ParseFraming(props)
+	if err != nil {
+		return err
+	}
+	s.cfg = cfg
+	return nil
+}
+
+func (s *Sink) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	ctx.GetLogger().Infof("Connecting to %s %s", s.cfg.Protocol, s.cfg.Server)
+	err := s.dial()
+	if err != nil {
+		sch(api.ConnectionDisconnected, err.Error())
+		return err
+	}
+	sch(api.ConnectionConnected, "")
+	return nil
+}
+
+func (s *Sink) dial() error {
+	conn, err := net.DialTimeout(s.cfg.Protocol, s.cfg.Server, time.Duration(s.cfg.Timeout))
+	if err != nil {
+		return errorx.NewIOErr(fmt.Sprintf("connect to %s %s failed: %v", s.cfg.Protocol, s.cfg.Server, err))
+	}
+	s.conn = conn
+	return nil
+}
+
+func (s *Sink) Collect(ctx api.StreamContext, item api.RawTuple) error {
+	frame, err := s.framing.Frame(item.Raw())
+	if err != nil {
+		return err
+	}
+	if s.conn == nil {
+		if err := s.dial(); err != nil {
+			return err
+		}
+		ctx.GetLogger().Infof("reconnected to %s %s", s.cfg.Protocol, s.cfg.Server)
+	}
+	_ = s.conn.SetWriteDeadline(time.Now().Add(time.Duration(s.cfg.Timeout)))
+	_, err = s.conn.Write(frame)
+	if err != nil {
+		// drop the broken connection so that the next collect reconnects
+		_ = s.conn.Close()
+		s.conn = nil
+		return errorx.NewIOErr(fmt.Sprintf("write to %s %s failed: %v", s.cfg.Protocol, s.cfg.Server, err))
+	}
+	return nil
+}
+
+func (s *Sink) Close(ctx api.StreamContext) error {
+	ctx.GetLogger().Infof("Closing socket sink")
+	if s.conn != nil {
+		return s.conn.Close()
+	}
+	return nil
+}
+
+// Ping checks whether the remote tcp server is connectable. UDP is connectionless, so only the address is checked.
+func (s *Sink) Ping(ctx api.StreamContext, props map[string]any) error {
+	if err := s.Provision(ctx, props); err != nil {
+		return err
+	}
+	if err := s.dial(); err != nil {
+		return err
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+func GetSink() api.Sink {
+	return &Sink{}
+}
+
+var (
+	_ api.BytesCollector = &Sink{}
+	_ util.PingableConn  = &Sink{}
+)
@@ -0,0 +1,104 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package socket
+
+import (
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/infra"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+type SourceConf struct {
+	// Protocol is tcp or udp
+	Protocol string `json:"protocol"`
+	// ListenAddr is the address to listen like :9000
+	ListenAddr string `json:"listenAddr"`
+}
+
+// Source listens on a TCP or UDP address and ingests each received frame. The frames are decoded by the configured
+// format.
+type Source struct {
+	cfg     *SourceConf
+	framing *FramingConf
+	server  *Server
+}
+
+func (s *Source) Provision(_ api.StreamContext, props map[string]any) error {
+	cfg := &SourceConf{
+		Protocol: ProtocolTCP,
+	}
+	err := cast.MapToStruct(props, cfg)
+	if err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if err := ValidateProtocol(cfg.Protocol); err != nil {
+		return err
+	}
+	if cfg.ListenAddr == "" {
+		return fmt.Errorf("listenAddr is required")
+	}
+	s.framing, err = ParseFraming(props)
+	if err != nil {
+		return err
+	}
+	s.cfg = cfg
+	return nil
+}
+
+func (s *Source) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	ctx.GetLogger().Infof("Listening %s %s", s.cfg.Protocol, s.cfg.ListenAddr)
+	server := NewServer(s.cfg.Protocol, s.cfg.ListenAddr, s.framing.SplitFunc(), s.framing.BufferSize())
+	if err := server.Listen(); err != nil {
+		sch(api.ConnectionDisconnected, err.Error())
+		return err
+	}
+	s.server = server
+	sch(api.ConnectionConnected, "")
+	return nil
+}
+
+func (s *Source) Subscribe(ctx api.StreamContext, ingest api.BytesIngest, ingestError api.ErrorIngest) error {
+	s.server.Serve(ctx, func(frame []byte, remoteAddr string) {
+		meta := map[string]any{
+			"remoteAddr": remoteAddr,
+		}
+		e := infra.SafeRun(func() error {
+			ingest(ctx, frame, meta, timex.GetNow())
+			return nil
+		})
+		if e != nil {
+			ingestError(ctx, e)
+		}
+	})
+	return nil
+}
+
+func (s *Source) Close(ctx api.StreamContext) error {
+	ctx.GetLogger().Infof("Closing socket source")
+	if s.server != nil {
+		return s.server.Close()
+	}
+	return nil
+}
+
+func GetSource() api.Source {
+	return &Source{}
+}
+
+var _ api.BytesSource = &Source{}
@@ -0,0 +1,265 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package socket
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+type received struct {
+	payload string
+	meta    map[string]any
+}
+
+// startSource starts the source on a random port and returns the actual listening address
+func startSource(t *testing.T, ctx api.StreamContext, props map[string]any) (*Source, string, chan received) {
+	props["listenAddr"] = "127.0.0.1:0"
+	s := GetSource().(*Source)
+	require.NoError(t, s.Provision(ctx, props))
+	require.NoError(t, s.Connect(ctx, func(string, string) {}))
+	ch := make(chan received, 10)
+	require.NoError(t, s.Subscribe(ctx, func(_ api.StreamContext, payload []byte, meta map[string]any, _ time.Time) {
+		ch <- received{payload: string(payload), meta: meta}
+	}, func(_ api.StreamContext, err error) {
+		require.NoError(t, err)
+	}))
+	return s, s.server.Addr().String(), ch
+}
+
+func expectReceived(t *testing.T, ch chan received) received {
+	select {
+	case r := <-ch:
+		return r
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "timeout")
+		return received{}
+	}
+}
+
+func startSink(t *testing.T, ctx api.StreamContext, props map[string]any) *Sink {
+	s := GetSink().(*Sink)
+	require.NoError(t, s.Provision(ctx, props))
+	require.NoError(t, s.Connect(ctx, func(string, string) {}))
+	return s
+}
+
+func TestSourceSink(t *testing.T) {
+	tests := []struct {
+		name  string
+		props map[string]any
+		data  []string
+	}{
+		{
+			name:  "tcp newline",
+			props: map[string]any{"protocol": "tcp"},
+			data:  []string{`{"a":1}`, `{"a":2}`, `{"a":3}`},
+		},
+		{
+			name:  "tcp length",
+			props: map[string]any{"protocol": "tcp", "framing": "length", "lengthSize": 2},
+			data:  []string{"{\"a\":\n1}", `{"a":2}`},
+		},
+		{
+			name:  "tcp fixed",
+			props: map[string]any{"protocol": "tcp", "framing": "fixed", "frameSize": 4},
+			data:  []string{"abcd", "efgh"},
+		},
+		{
+			name:  "udp newline",
+			props: map[string]any{"protocol": "udp"},
+			data:  []string{`{"a":1}`, `{"a":2}`},
+		},
+		{
+			name:  "udp length",
+			props: map[string]any{"protocol": "udp", "framing": "length"},
+			data:  []string{`{"a":1}`, `{"a":2}`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := mockContext.NewMockContext("rule1", "op1").WithCancel()
+			defer cancel()
+			srcProps := make(map[string]any)
+			sinkProps := make(map[string]any)
+			for k, v := range tt.props {
+				srcProps[k] = v
+				sinkProps[k] = v
+			}
+			src, addr, ch := startSource(t, ctx, srcProps)
+			defer src.Close(ctx)
+			sinkProps["server"] = addr
+			sink := startSink(t, ctx, sinkProps)
+			defer sink.Close(ctx)
+			for _, d := range tt.data {
+				require.NoError(t, sink.Collect(ctx, &xsql.RawTuple{Rawdata: []byte(d)}))
+			}
+			for _, d := range tt.data {
+				r := expectReceived(t, ch)
+				require.Equal(t, d, r.payload)
+				require.Equal(t, sink.conn.LocalAddr().String(), r.meta["remoteAddr"])
+			}
+		})
+	}
+}
+
+func TestSourceMultipleFramesInDatagram(t *testing.T) {
+	ctx, cancel := mockContext.NewMockContext("rule1", "op1").WithCancel()
+	defer cancel()
+	src, addr, ch := startSource(t, ctx, map[string]any{"protocol": "udp"})
+	defer src.Close(ctx)
+	conn, err := net.Dial("udp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("a\nb\n"))
+	require.NoError(t, err)
+	require.Equal(t, "a", expectReceived(t, ch).payload)
+	require.Equal(t, "b", expectReceived(t, ch).payload)
+}
+
+func TestSourceInvalidFrame(t *testing.T) {
+	ctx, cancel := mockContext.NewMockContext("rule1", "op1").WithCancel()
+	defer cancel()
+	src, addr, ch := startSource(t, ctx, map[string]any{"framing": "length", "lengthSize": 1, "maxFrameLength": 4})
+	defer src.Close(ctx)
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte{2, 'a', 'b', 5, 'c'})
+	require.NoError(t, err)
+	require.Equal(t, "ab", expectReceived(t, ch).payload)
+	// the connection is closed by the server after the invalid frame
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, err = conn.Read(make([]byte, 1))
+	require.Error(t, err)
+	var ne net.Error
+	if errors.As(err, &ne) {
+		require.False(t, ne.Timeout())
+	}
+}
+
+func TestSinkReconnect(t *testing.T) {
+	ctx, cancel := mockContext.NewMockContext("rule1", "op1").WithCancel()
+	defer cancel()
+	src, addr, ch := startSource(t, ctx, map[string]any{})
+	sink := startSink(t, ctx, map[string]any{"server": addr})
+	defer sink.Close(ctx)
+	require.NoError(t, sink.Collect(ctx, &xsql.RawTuple{Rawdata: []byte("a")}))
+	require.Equal(t, "a", expectReceived(t, ch).payload)
+	require.NoError(t, src.Close(ctx))
+	// the write fails after the peer is closed, the first write may still succeed
+	var err error
+	for i := 0; i < 10 && err == nil; i++ {
+		err = sink.Collect(ctx, &xsql.RawTuple{Rawdata: []byte("b")})
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.Error(t, err)
+	require.True(t, errorx.IsIOError(err))
+	require.Nil(t, sink.conn)
+	// the server is back on the same address
+	src2 := GetSource().(*Source)
+	require.NoError(t, src2.Provision(ctx, map[string]any{"listenAddr": addr}))
+	require.NoError(t, src2.Connect(ctx, func(string, string) {}))
+	defer src2.Close(ctx)
+	ch2 := make(chan received, 10)
+	require.NoError(t, src2.Subscribe(ctx, func(_ api.StreamContext, payload []byte, meta map[string]any, _ time.Time) {
+		ch2 <- received{payload: string(payload), meta: meta}
+	}, func(_ api.StreamContext, err error) {}))
+	require.NoError(t, sink.Collect(ctx, &xsql.RawTuple{Rawdata: []byte("c")}))
+	require.Equal(t, "c", expectReceived(t, ch2).payload)
+}
+
+func TestListenErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	s := GetSource().(*Source)
+	require.NoError(t, s.Provision(ctx, map[string]any{"listenAddr": ln.Addr().String()}))
+	var status string
+	err = s.Connect(ctx, func(st string, _ string) {
+		status = st
+	})
+	require.Error(t, err)
+	require.Equal(t, api.ConnectionDisconnected, status)
+}
+
+func TestPing(t *testing.T) {
+	ctx, cancel := mockContext.NewMockContext("rule1", "op1").WithCancel()
+	defer cancel()
+	src, addr, _ := startSource(t, ctx, map[string]any{})
+	require.NoError(t, GetSink().(*Sink).Ping(ctx, map[string]any{"server": addr}))
+	require.NoError(t, src.Close(ctx))
+	err := GetSink().(*Sink).Ping(ctx, map[string]any{"server": addr})
+	require.Error(t, err)
+	require.True(t, errorx.IsIOError(err))
+}
+
+func TestProvisionErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	srcTests := []struct {
+		props map[string]any
+		err   string
+	}{
+		{
+			props: map[string]any{"listenAddr": ":9000", "protocol": "http"},
+			err:   "invalid protocol http, must be tcp or udp",
+		},
+		{
+			props: map[string]any{},
+			err:   "listenAddr is required",
+		},
+		{
+			props: map[string]any{"listenAddr": ":9000", "framing": "fixed", "frameSize": -1},
+			err:   "invalid frameSize -1, must be in range 1-1048576",
+		},
+	}
+	for _, tt := range srcTests {
+		require.EqualError(t, GetSource().Provision(ctx, tt.props), tt.err)
+	}
+	sinkTests := []struct {
+		props map[string]any
+		err   string
+	}{
+		{
+			props: map[string]any{},
+			err:   "server is required",
+		},
+		{
+			props: map[string]any{"server": "localhost"},
+			err:   "invalid server localhost: address localhost: missing port in address",
+		},
+		{
+			props: map[string]any{"server": "localhost:9000", "timeout": "0s"},
+			err:   "invalid timeout 0s, must be positive",
+		},
+		{
+			props: map[string]any{"server": "localhost:9000", "framing": "length", "lengthSize": 8},
+			err:   "invalid lengthSize 8, must be 1, 2 or 4",
+		},
+	}
+	for _, tt := range sinkTests {
+		require.EqualError(t, GetSink().Provision(ctx, tt.props), tt.err)
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syslog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	rfcAuto = "auto"
+	rfc3164 = "3164"
+	rfc5424 = "5424"
+	// nilValue is the NILVALUE of RFC 5424
+	nilValue = "-"
+	// defaultPriority is user.notice which is assigned to the messages without PRI by RFC 3164 section 4.3.3
+	defaultPriority = 13
+)
+
+var bom = []byte{0xEF, 0xBB, 0xBF}
+
+// parser parses a syslog message into the fields facility, severity, timestamp, hostname, appName, procId, msgId,
+// structuredData and message. The absent fields are not set.
+type parser struct {
+	// rfc is auto, 3164 or 5424
+	rfc string
+	// loc is the time zone of the RFC 3164 timestamps which do not have the zone
+	loc *time.Location
+}
+
+func (p *parser) parse(data []byte, now time.Time) (map[string]any, error) {
+	data = bytes.TrimRight(data, "\r\n\x00")
+	if len(data) == 0 {
+		return nil, errors.New("empty syslog message")
+	}
+	pri, rest, ok := parsePriority(data)
+	if !ok {
+		if p.rfc == rfc5424 {
+			return nil, fmt.Errorf("invalid syslog message %q: invalid PRI", data)
+		}
+		result := withPriority(defaultPriority)
+		result["message"] = string(data)
+		return result, nil
+	}
+	switch p.rfc {
+	case rfc5424:
+		return parse5424(pri, rest)
+	case rfc3164:
+		return p.parse3164(pri, rest, now), nil
+	default:
+		// The version of RFC 5424 is 1, while RFC 3164 has no version after PRI
+		if bytes.HasPrefix(rest, []byte("1 ")) {
+			return parse5424(pri, rest)
+		}
+		return p.parse3164(pri, rest, now), nil
+	}
+}
+
+func withPriority(pri int) map[string]any {
+	return map[string]any{
+		"facility": int64(pri / 8),
+		"severity": int64(pri % 8),
+	}
+}
+
+// parsePriority parses the PRI part like <34>
+func parsePriority(data []byte) (int, []byte, bool) {
+	if len(data) < 3 || data[0] != '<' {
+		return 0, nil, false
+	}
+	end := bytes.IndexByte(data[:min(len(data), 5)], '>')
+	if end < 2 {
+		return 0, nil, false
+	}
+	pri, err := strconv.Atoi(string(data[1:end]))
+	if err != nil || pri < 0 || pri > 191 {
+		return 0, nil, false
+	}
+	return pri, data[end+1:], true
+}
+
+// parse3164 parses the BSD syslog format leniently like "Oct 11 22:14:15 mymachine su[123]: message". The parts
+// which cannot be recognized are kept in the message.
+func (p *parser) parse3164(pri int, data []byte, now time.Time) map[string]any {
+	result := withPriority(pri)
+	rest := string(data)
+	ts, rest, ok := p.parse3164Timestamp(rest, now)
+	if ok {
+		result["timestamp"] = ts
+		// the hostname is omitted by some senders, so the token like "su:" or "su[123]:" is the tag
+		host, after, found := strings.Cut(rest, " ")
+		if found && host != "" && !strings.HasSuffix(host, ":") && !strings.Contains(host, "[") {
+			result["hostname"] = host
+			rest = after
+		}
+	}
+	rest = parseTag(rest, result)
+	result["message"] = rest
+	return result
+}
+
+// parse3164Timestamp parses the timestamp like "Oct 11 22:14:15" or the RFC 3339 timestamp used by some senders
+func (p *parser) parse3164Timestamp(data string, now time.Time) (time.Time, string, bool) {
+	if len(data) >= 15 {
+		ts, err := time.ParseInLocation(time.Stamp, data[:15], p.loc)
+		if err == nil {
+			now = now.In(p.loc)
+			ts = time.Date(now.Year(), ts.Month(), ts.Day(), ts.Hour(), ts.Minute(), ts.Second(), 0, p.loc)
+			// the year is missing, the message of December received in January belongs to the last year
+			if ts.After(now.Add(24 * time.Hour)) {
+				ts = ts.AddDate(-1, 0, 0)
+			}
+			return ts, strings.TrimPrefix(data[15:], " "), true
+		}
+	}
+	token, rest, _ := strings.Cut(data, " ")
+	ts, err := time.Parse(time.RFC3339Nano, token)
+	if err == nil {
+		return ts, rest, true
+	}
+	return time.Time{}, data, false
+}
+
+// parseTag parses the tag like "su[123]: " into appName and procId, and returns the content after it
+func parseTag(data string, result map[string]any) string {
+	for i := 0; i < len(data) && i <= 48; i++ {
+		switch c := data[i]; c {
+		case ':':
+			if i == 0 {
+				return data
+			}
+			result["appName"] = data[:i]
+			return strings.TrimPrefix(data[i+1:], " ")
+		case '[':
+			end := strings.Index(data[i:], "]:")
+			if i == 0 || end < 0 {
+				return data
+			}
+			result["appName"] = data[:i]
+			result["procId"] = data[i+1 : i+end]
+			return strings.TrimPrefix(data[i+end+2:], " ")
+		case ' ', '\t':
+			return data
+		}
+	}
+	return data
+}
+
+// parse5424 parses the format "VERSION SP TIMESTAMP SP HOSTNAME SP APP-NAME SP PROCID SP MSGID SP STRUCTURED-DATA
+// [SP MSG]"
+func parse5424(pri int, data []byte) (map[string]any, error) {
+	result := withPriority(pri)
+	rest := string(data)
+	if version, _, _ := strings.Cut(rest, " "); version != "1" {
+		return nil, fmt.Errorf("invalid syslog message %q: unsupported version %s", data, version)
+	}
+	fields := make([]string, 6)
+	for i := range fields {
+		var found bool
+		fields[i], rest, found = strings.Cut(rest, " ")
+		if !found || fields[i] == "" {
+			return nil, fmt.Errorf("invalid syslog message %q: missing header fields", data)
+		}
+	}
+	if fields[1] != nilValue {
+		ts, err := time.Parse(time.RFC3339Nano, fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid syslog message %q: invalid timestamp %s", data, fields[1])
+		}
+		result["timestamp"] = ts
+	}
+	for i, name := range []string{"hostname", "appName", "procId", "msgId"} {
+		if fields[i+2] != nilValue {
+			result[name] = fields[i+2]
+		}
+	}
+	sd, rest, err := parseStructuredData(rest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid syslog message %q: %v", data, err)
+	}
+	if sd != nil {
+		result["structuredData"] = sd
+	}
+	if rest != "" {
+		if rest[0] != ' ' {
+			return nil, fmt.Errorf("invalid syslog message %q: missing space before message", data)
+		}
+		result["message"] = strings.TrimPrefix(rest[1:], string(bom))
+	}
+	return result, nil
+}
+
+// parseStructuredData parses the structured data like [id@32473 a="1" b="2"][id2 c="3"] into a map of the SD-ID to
+// the map of the params
+func parseStructuredData(data string) (map[string]any, string, error) {
+	if strings.HasPrefix(data, nilValue) {
+		return nil, data[1:], nil
+	}
+	if !strings.HasPrefix(data, "[") {
+		return nil, "", errors.New("invalid structured data")
+	}
+	sd := make(map[string]any)
+	for strings.HasPrefix(data, "[") {
+		end := strings.IndexAny(data, " ]")
+		if end <= 1 {
+			return nil, "", errors.New("invalid structured data id")
+		}
+		id := data[1:end]
+		data = data[end:]
+		params := make(map[string]any)
+		for data != "" && data[0] == ' ' {
+			eq := strings.IndexByte(data, '=')
+			if eq <= 1 || len(data) < eq+2 || data[eq+1] != '"' {
+				return nil, "", fmt.Errorf("invalid param of structured data %s", id)
+			}
+			name := data[1:eq]
+			value, n, err := parseParamValue(data[eq+2:])
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid param %s of structured data %s: %v", name, id, err)
+			}
+			params[name] = value
+			data = data[eq+2+n:]
+		}
+		if data == "" || data[0] != ']' {
+			return nil, "", fmt.Errorf("unterminated structured data %s", id)
+		}
+		data = data[1:]
+		sd[id] = params
+	}
+	return sd, data, nil
+}
+
+// parseParamValue parses the escaped value until the closing quote and returns the consumed length
+func parseParamValue(data string) (string, int, error) {
+	var b strings.Builder
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; c {
+		case '"':
+			return b.String(), i + 1, nil
+		case '\\':
+			// only ", \ and ] are escaped, otherwise the backslash is kept
+			if i+1 < len(data) && (data[i+1] == '"' || data[i+1] == '\\' || data[i+1] == ']') {
+				i++
+				b.WriteByte(data[i])
+			} else {
+				b.WriteByte(c)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, errors.New("unterminated value")
+}
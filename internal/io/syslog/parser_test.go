@@ -0,0 +1,216 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syslog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		rfc    string
+		data   string
+		result map[string]any
+	}{
+		{
+			name: "rfc5424 full",
+			data: `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog 1234 ID47 [exampleSDID@32473 iut="3" eventSource="Application" eventID="1011"][examplePriority@32473 class="high"] ` + "\xEF\xBB\xBF" + `An application event log entry...`,
+			result: map[string]any{
+				"facility":  int64(20),
+				"severity":  int64(5),
+				"timestamp": time.Date(2003, 10, 11, 22, 14, 15, 3000000, time.UTC),
+				"hostname":  "mymachine.example.com",
+				"appName":   "evntslog",
+				"procId":    "1234",
+				"msgId":     "ID47",
+				"structuredData": map[string]any{
+					"exampleSDID@32473": map[string]any{
+						"iut":         "3",
+						"eventSource": "Application",
+						"eventID":     "1011",
+					},
+					"examplePriority@32473": map[string]any{
+						"class": "high",
+					},
+				},
+				"message": "An application event log entry...",
+			},
+		},
+		{
+			name: "rfc5424 nil values",
+			data: `<34>1 - - su - - - 'su root' failed for lonvick on /dev/pts/8`,
+			result: map[string]any{
+				"facility": int64(4),
+				"severity": int64(2),
+				"appName":  "su",
+				"message":  "'su root' failed for lonvick on /dev/pts/8",
+			},
+		},
+		{
+			name: "rfc5424 escaped sd without message",
+			rfc:  rfc5424,
+			data: `<13>1 2026-10-18T20:00:00+08:00 host app - - [meta path="C:\\dir \"a\" \]" empty=""]`,
+			result: map[string]any{
+				"facility":  int64(1),
+				"severity":  int64(5),
+				"timestamp": time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+				"hostname":  "host",
+				"appName":   "app",
+				"structuredData": map[string]any{
+					"meta": map[string]any{
+						"path":  `C:\dir "a" ]`,
+						"empty": "",
+					},
+				},
+			},
+		},
+		{
+			name: "rfc3164",
+			data: "<34>Oct 11 22:14:15 mymachine su: 'su root' failed for lonvick on /dev/pts/8\n",
+			result: map[string]any{
+				"facility":  int64(4),
+				"severity":  int64(2),
+				"timestamp": time.Date(2026, 10, 11, 22, 14, 15, 0, time.UTC),
+				"hostname":  "mymachine",
+				"appName":   "su",
+				"message":   "'su root' failed for lonvick on /dev/pts/8",
+			},
+		},
+		{
+			name: "rfc3164 pid and last year",
+			rfc:  rfc3164,
+			data: "<13>Dec  1 08:00:00 router sshd[4321]: Accepted password",
+			result: map[string]any{
+				"facility":  int64(1),
+				"severity":  int64(5),
+				"timestamp": time.Date(2025, 12, 1, 8, 0, 0, 0, time.UTC),
+				"hostname":  "router",
+				"appName":   "sshd",
+				"procId":    "4321",
+				"message":   "Accepted password",
+			},
+		},
+		{
+			name: "rfc3164 without hostname",
+			data: "<14>Oct 18 11:00:00 kernel: link down",
+			result: map[string]any{
+				"facility":  int64(1),
+				"severity":  int64(6),
+				"timestamp": time.Date(2026, 10, 18, 11, 0, 0, 0, time.UTC),
+				"appName":   "kernel",
+				"message":   "link down",
+			},
+		},
+		{
+			name: "rfc3164 rfc3339 timestamp",
+			data: "<14>2026-10-18T11:00:00Z fw01 %ASA-6-302013: Built connection",
+			result: map[string]any{
+				"facility":  int64(1),
+				"severity":  int64(6),
+				"timestamp": time.Date(2026, 10, 18, 11, 0, 0, 0, time.UTC),
+				"hostname":  "fw01",
+				"appName":   "%ASA-6-302013",
+				"message":   "Built connection",
+			},
+		},
+		{
+			name: "rfc3164 content only",
+			data: "<190>link is down on port 3",
+			result: map[string]any{
+				"facility": int64(23),
+				"severity": int64(6),
+				"message":  "link is down on port 3",
+			},
+		},
+		{
+			name: "no priority",
+			data: "hello world",
+			result: map[string]any{
+				"facility": int64(1),
+				"severity": int64(5),
+				"message":  "hello world",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rfc := tt.rfc
+			if rfc == "" {
+				rfc = rfcAuto
+			}
+			p := &parser{rfc: rfc, loc: time.UTC}
+			result, err := p.parse([]byte(tt.data), now)
+			require.NoError(t, err)
+			if ts, ok := result["timestamp"].(time.Time); ok {
+				result["timestamp"] = ts.UTC()
+			}
+			require.Equal(t, tt.result, result)
+		})
+	}
+}
+
+func TestParseErr(t *testing.T) {
+	tests := []struct {
+		data string
+		err  string
+	}{
+		{
+			data: "",
+			err:  "empty syslog message",
+		},
+		{
+			data: "<34>Oct 11 22:14:15 mymachine su: failed",
+			err:  `invalid syslog message "Oct 11 22:14:15 mymachine su: failed": unsupported version Oct`,
+		},
+		{
+			data: "hello",
+			err:  `invalid syslog message "hello": invalid PRI`,
+		},
+		{
+			data: "<34>1 2003-10-11 host app - -",
+			err:  `invalid syslog message "1 2003-10-11 host app - -": missing header fields`,
+		},
+		{
+			data: "<34>1 2003-10-11 host app - - -",
+			err:  `invalid syslog message "1 2003-10-11 host app - - -": invalid timestamp 2003-10-11`,
+		},
+		{
+			data: `<34>1 - host app - - [id a="1"`,
+			err:  `invalid syslog message "1 - host app - - [id a=\"1\"": unterminated structured data id`,
+		},
+		{
+			data: `<34>1 - host app - - [id a=1]`,
+			err:  `invalid syslog message "1 - host app - - [id a=1]": invalid param of structured data id`,
+		},
+		{
+			data: `<34>1 - host app - - [id a="1]`,
+			err:  `invalid syslog message "1 - host app - - [id a=\"1]": invalid param a of structured data id: unterminated value`,
+		},
+		{
+			data: `<34>1 - host app - - msg`,
+			err:  `invalid syslog message "1 - host app - - msg": invalid structured data`,
+		},
+	}
+	p := &parser{rfc: rfc5424, loc: time.UTC}
+	for _, tt := range tests {
+		_, err := p.parse([]byte(tt.data), time.Now())
+		require.EqualError(t, err, tt.err)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syslog
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/io/socket"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/infra"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+type SourceConf struct {
+	// Protocol is udp or tcp
+	Protocol string `json:"protocol"`
+	// ListenAddr is the address to listen like :514
+	ListenAddr string `json:"listenAddr"`
+	// Rfc is the message format to parse, auto, 3164 or 5424
+	Rfc              string `json:"rfc"`
+	MaxMessageLength int    `json:"maxMessageLength"`
+}
+
+// Source receives the syslog messages over UDP or TCP and parses them into tuples
+type Source struct {
+	cfg    *SourceConf
+	parser *parser
+	server *socket.Server
+}
+
+func (s *Source) Provision(_ api.StreamContext, props map[string]any) error {
+	cfg := &SourceConf{
+		Protocol:         socket.ProtocolUDP,
+		ListenAddr:       ":514",
+		Rfc:              rfcAuto,
+		MaxMessageLength: 8192,
+	}
+	err := cast.MapToStruct(props, cfg)
+	if err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if err := socket.ValidateProtocol(cfg.Protocol); err != nil {
+		return err
+	}
+	if cfg.ListenAddr == "" {
+		return fmt.Errorf("listenAddr is required")
+	}
+	if cfg.Rfc != rfcAuto && cfg.Rfc != rfc3164 && cfg.Rfc != rfc5424 {
+		return fmt.Errorf("invalid rfc %s, must be auto, 3164 or 5424", cfg.Rfc)
+	}
+	if cfg.MaxMessageLength <= 0 {
+		return fmt.Errorf("invalid maxMessageLength %d, must be positive", cfg.MaxMessageLength)
+	}
+	s.cfg = cfg
+	s.parser = &parser{rfc: cfg.Rfc, loc: cast.GetConfiguredTimeZone()}
+	return nil
+}
+
+func (s *Source) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	ctx.GetLogger().Infof("Listening syslog on %s %s", s.cfg.Protocol, s.cfg.ListenAddr)
+	var server *socket.Server
+	if s.cfg.Protocol == socket.ProtocolUDP {
+		// each datagram is a message
+		server = socket.NewServer(s.cfg.Protocol, s.cfg.ListenAddr, splitDatagram, s.cfg.MaxMessageLength+1)
+	} else {
+		server = socket.NewServer(s.cfg.Protocol, s.cfg.ListenAddr, splitOctetCounting(s.cfg.MaxMessageLength), s.cfg.MaxMessageLength+12)
+	}
+	if err := server.Listen(); err != nil {
+		sch(api.ConnectionDisconnected, err.Error())
+		return err
+	}
+	s.server = server
+	sch(api.ConnectionConnected, "")
+	return nil
+}
+
+func (s *Source) Subscribe(ctx api.StreamContext, ingest api.TupleIngest, ingestError api.ErrorIngest) error {
+	s.server.Serve(ctx, func(frame []byte, remoteAddr string) {
+		// skip the empty lines between the messages
+		if len(bytes.TrimSpace(frame)) == 0 {
+			return
+		}
+		rcvTime := timex.GetNow()
+		msg, err := s.parser.parse(frame, rcvTime)
+		if err != nil {
+			ingestError(ctx, err)
+			return
+		}
+		e := infra.SafeRun(func() error {
+			ingest(ctx, msg, map[string]any{"remoteAddr": remoteAddr}, rcvTime)
+			return nil
+		})
+		if e != nil {
+			ingestError(ctx, e)
+		}
+	})
+	return nil
+}
+
+func (s *Source) Close(ctx api.StreamContext) error {
+	ctx.GetLogger().Infof("Closing syslog source")
+	if s.server != nil {
+		return s.server.Close()
+	}
+	return nil
+}
+
+// splitDatagram returns the whole datagram as a message
+func splitDatagram(data []byte, atEOF bool) (int, []byte, error) {
+	if !atEOF || len(data) == 0 {
+		return 0, nil, nil
+	}
+	return len(data), data, nil
+}
+
+// splitOctetCounting splits the TCP stream by RFC 6587. The message prefixed with the length like "25 <34>1 ..." uses
+// the octet counting framing, otherwise the message is terminated by LF.
+func splitOctetCounting(maxLength int) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if len(data) == 0 {
+			return 0, nil, nil
+		}
+		if data[0] >= '1' && data[0] <= '9' {
+			sp := bytes.IndexByte(data, ' ')
+			if sp < 0 {
+				if len(data) > 10 || atEOF {
+					return 0, nil, fmt.Errorf("invalid octet count %q", data[:min(len(data), 10)])
+				}
+				return 0, nil, nil
+			}
+			n, err := strconv.Atoi(string(data[:sp]))
+			if err != nil {
+				return 0, nil, fmt.Errorf("invalid octet count %q", data[:sp])
+			}
+			if n > maxLength {
+				return 0, nil, fmt.Errorf("message length %d exceeds maxMessageLength %d", n, maxLength)
+			}
+			end := sp + 1 + n
+			if len(data) < end {
+				if atEOF {
+					return 0, nil, fmt.Errorf("incomplete message of %d bytes at the end", len(data))
+				}
+				return 0, nil, nil
+			}
+			return end, data[sp+1 : end], nil
+		}
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		if err != nil {
+			return advance, token, err
+		}
+		if len(token) > maxLength || (token == nil && !atEOF && len(data) > maxLength) {
+			return 0, nil, fmt.Errorf("message exceeds maxMessageLength %d", maxLength)
+		}
+		return advance, token, nil
+	}
+}
+
+func GetSource() api.Source {
+	return &Source{}
+}
+
+var _ api.TupleSource = &Source{}
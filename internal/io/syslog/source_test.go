@@ -0,0 +1,181 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syslog
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/require"
+
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+type received struct {
+	data map[string]any
+	meta map[string]any
+	err  error
+}
+
+func startSource(t *testing.T, ctx api.StreamContext, props map[string]any) (*Source, string, chan received) {
+	props["listenAddr"] = "127.0.0.1:0"
+	s := GetSource().(*Source)
+	require.NoError(t, s.Provision(ctx, props))
+	require.NoError(t, s.Connect(ctx, func(string, string) {}))
+	ch := make(chan received, 10)
+	require.NoError(t, s.Subscribe(ctx, func(_ api.StreamContext, data any, meta map[string]any, _ time.Time) {
+		ch <- received{data: data.(map[string]any), meta: meta}
+	}, func(_ api.StreamContext, err error) {
+		ch <- received{err: err}
+	}))
+	return s, s.server.Addr().String(), ch
+}
+
+func expectReceived(t *testing.T, ch chan received) received {
+	select {
+	case r := <-ch:
+		return r
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "timeout")
+		return received{}
+	}
+}
+
+func TestUDPSource(t *testing.T) {
+	ctx, cancel := mockContext.NewMockContext("rule1", "op1").WithCancel()
+	defer cancel()
+	s, addr, ch := startSource(t, ctx, map[string]any{})
+	defer s.Close(ctx)
+	conn, err := net.Dial("udp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("<34>1 - host su - - - 'su root' failed\nsecond line"))
+	require.NoError(t, err)
+	r := expectReceived(t, ch)
+	require.NoError(t, r.err)
+	require.Equal(t, map[string]any{
+		"facility": int64(4),
+		"severity": int64(2),
+		"hostname": "host",
+		"appName":  "su",
+		"message":  "'su root' failed\nsecond line",
+	}, r.data)
+	require.Equal(t, conn.LocalAddr().String(), r.meta["remoteAddr"])
+	// the invalid message is reported as error
+	_, err = conn.Write([]byte("<34>1 - host"))
+	require.NoError(t, err)
+	r = expectReceived(t, ch)
+	require.EqualError(t, r.err, `invalid syslog message "1 - host": missing header fields`)
+}
+
+func TestTCPSource(t *testing.T) {
+	ctx, cancel := mockContext.NewMockContext("rule1", "op1").WithCancel()
+	defer cancel()
+	s, addr, ch := startSource(t, ctx, map[string]any{"protocol": "tcp", "maxMessageLength": 64})
+	defer s.Close(ctx)
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	// octet counting frame may contain LF, mixed with the non-transparent frames
+	msg := "<13>1 - h a - - - line1\nline2"
+	_, err = conn.Write([]byte("29 " + msg + "<14>Oct 11 22:14:15 h b: c\r\n\n<15>d\n"))
+	require.NoError(t, err)
+	expected := []string{"line1\nline2", "c", "d"}
+	for _, e := range expected {
+		r := expectReceived(t, ch)
+		require.NoError(t, r.err)
+		require.Equal(t, e, r.data["message"])
+	}
+	// too long message closes the connection
+	_, err = conn.Write([]byte("65 <13>"))
+	require.NoError(t, err)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, err = conn.Read(make([]byte, 1))
+	require.Error(t, err)
+	var ne net.Error
+	if errors.As(err, &ne) {
+		require.False(t, ne.Timeout())
+	}
+	// other connections are not affected
+	conn2, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn2.Close()
+	_, err = conn2.Write([]byte("<15>e\n"))
+	require.NoError(t, err)
+	require.Equal(t, "e", expectReceived(t, ch).data["message"])
+}
+
+func TestSplitOctetCounting(t *testing.T) {
+	split := splitOctetCounting(10)
+	tests := []struct {
+		data    string
+		atEOF   bool
+		advance int
+		token   string
+		err     string
+	}{
+		{data: "3 abcd", advance: 5, token: "abc"},
+		{data: "3 ab"},
+		{data: "3 ab", atEOF: true, err: "incomplete message of 4 bytes at the end"},
+		{data: "12345"},
+		{data: "123456789012", err: `invalid octet count "1234567890"`},
+		{data: "1a abc", err: `invalid octet count "1a"`},
+		{data: "11 abc", err: "message length 11 exceeds maxMessageLength 10"},
+		{data: "abc\r\ndef", advance: 5, token: "abc"},
+		{data: "abcdefghijkl", err: "message exceeds maxMessageLength 10"},
+		{data: "abc", atEOF: true, advance: 3, token: "abc"},
+	}
+	for _, tt := range tests {
+		advance, token, err := split([]byte(tt.data), tt.atEOF)
+		if tt.err != "" {
+			require.EqualError(t, err, tt.err, tt.data)
+			continue
+		}
+		require.NoError(t, err, tt.data)
+		require.Equal(t, tt.advance, advance, tt.data)
+		require.Equal(t, tt.token, string(token), tt.data)
+	}
+}
+
+func TestProvisionErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	tests := []struct {
+		props map[string]any
+		err   string
+	}{
+		{
+			props: map[string]any{"protocol": "http"},
+			err:   "invalid protocol http, must be tcp or udp",
+		},
+		{
+			props: map[string]any{"listenAddr": ""},
+			err:   "listenAddr is required",
+		},
+		{
+			props: map[string]any{"rfc": "5425"},
+			err:   "invalid rfc 5425, must be auto, 3164 or 5424",
+		},
+		{
+			props: map[string]any{"maxMessageLength": -1},
+			err:   "invalid maxMessageLength -1, must be positive",
+		},
+	}
+	for _, tt := range tests {
+		require.EqualError(t, GetSource().Provision(ctx, tt.props), tt.err)
+	}
+}
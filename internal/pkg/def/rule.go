@@ -29,6 +29,8 @@ type RuleOption struct {
 	LogFilename               string                   `json:"logFilename,omitempty" yaml:"logFilename,omitempty"`
 	IsEventTime               bool                     `json:"isEventTime" yaml:"isEventTime"`
 	LateTol                   cast.DurationConf        `json:"lateTolerance,omitempty" yaml:"lateTolerance,omitempty"`
+	AllowedLateness           cast.DurationConf        `json:"allowedLateness,omitempty" yaml:"allowedLateness,omitempty"`
+	LateEventTopic            string                   `json:"lateEventTopic,omitempty" yaml:"lateEventTopic,omitempty"`
 	Concurrency               int                      `json:"concurrency" yaml:"concurrency"`
 	BufferLength              int                      `json:"bufferLength" yaml:"bufferLength"`
 	SendMetaToSink            bool                     `json:"sendMetaToSink" yaml:"sendMetaToSink"`
@@ -46,6 +48,14 @@ type RuleOption struct {
 	EnableSaveStateBeforeStop bool                     `json:"enableSaveStateBeforeStop,omitempty" yaml:"enableSaveStateBeforeStop,omitempty"`
 	ForceExitTimeout          cast.DurationConf        `json:"forceExitTimeout,omitempty" yaml:"forceExitTimeout,omitempty"`
 	Experiment                *ExpOpts                 `json:"experiment,omitempty" yaml:"experiment,omitempty"`
+	DeadLetter                *DeadLetterOption        `json:"deadLetter,omitempty" yaml:"deadLetter,omitempty"`
+}
+
+// DeadLetterOption defines where to send the data which fails to be processed.
+// The Action is like a rule action which has only one sink type as the key.
+type DeadLetterOption struct {
+	Topic  string         `json:"topic,omitempty" yaml:"topic,omitempty"`
+	Action map[string]any `json:"action,omitempty" yaml:"action,omitempty"`
 }
 
 type ExpOpts struct {
@@ -1,4 +1,4 @@
-// Copyright 2024 EMQ Technologies Co., Ltd.
+// Copyright 2024-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -23,3 +23,9 @@ type PropsValidator interface {
 type PingableConn interface {
 	Ping(api.StreamContext, map[string]any) error
 }
+
+// Browsable is implemented by the sources which can browse the address space of the server, so that the UI can pick
+// the data to read
+type Browsable interface {
+	Browse(api.StreamContext, map[string]any) (any, error)
+}
@@ -228,12 +228,15 @@ func clone(opt def.RuleOption) *def.RuleOption {
 	return &def.RuleOption{
 		IsEventTime:        opt.IsEventTime,
 		LateTol:            opt.LateTol,
+		AllowedLateness:    opt.AllowedLateness,
+		LateEventTopic:     opt.LateEventTopic,
 		Concurrency:        opt.Concurrency,
 		BufferLength:       opt.BufferLength,
 		SendMetaToSink:     opt.SendMetaToSink,
 		SendError:          opt.SendError,
 		Qos:                opt.Qos,
 		CheckpointInterval: opt.CheckpointInterval,
+		DeadLetter:         opt.DeadLetter,
 		RestartStrategy: &def.RestartStrategy{
 			Attempts: opt.RestartStrategy.Attempts,
 		},
@@ -0,0 +1,134 @@
+//go:build schema || !core
+
+package schema
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hamba/avro/v2"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+)
+
+type AvroType struct{}
+
+func (a *AvroType) Scan(logger api.Logger, schemaDir string) (map[string]*modules.Files, error) {
+	files, err := os.ReadDir(schemaDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read schema directory: %s", err)
+	}
+	newSchemas := make(map[string]*modules.Files, len(files))
+	for _, file := range files {
+		fileName := filepath.Base(file.Name())
+		if filepath.Ext(fileName) != ".avsc" {
+			continue
+		}
+		schemaId := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+		newSchemas[schemaId] = &modules.Files{SchemaFile: filepath.Join(schemaDir, file.Name())}
+		logger.Infof("schema file %s/%s loaded", schemaDir, schemaId)
+	}
+	return newSchemas, nil
+}
+
+// Infer converts the fields of the avro record schema. The messageId is not used because an avro schema file has only one top level schema.
+func (a *AvroType) Infer(_ api.Logger, filePath string, _ string) (ast.StreamFields, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("read schema file %s failed: %s", filePath, err)
+	}
+	s, err := avro.Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("parse schema file %s failed: %s", filePath, err)
+	}
+	rs, ok := s.(*avro.RecordSchema)
+	if !ok {
+		return nil, fmt.Errorf("schema file %s must define a record but got %s", filePath, s.Type())
+	}
+	return convertAvroRecord(rs)
+}
+
+func convertAvroRecord(rs *avro.RecordSchema) (ast.StreamFields, error) {
+	result := make(ast.StreamFields, 0, len(rs.Fields()))
+	for _, f := range rs.Fields() {
+		ft, err := convertAvroType(f.Type())
+		if err != nil {
+			return nil, fmt.Errorf("invalid type for field '%s': %v", f.Name(), err)
+		}
+		result = append(result, ast.StreamField{Name: f.Name(), FieldType: ft})
+	}
+	return result, nil
+}
+
+func convertAvroType(s avro.Schema) (ast.FieldType, error) {
+	switch t := s.(type) {
+	case *avro.RefSchema:
+		return convertAvroType(t.Schema())
+	case *avro.RecordSchema:
+		sfs, err := convertAvroRecord(t)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.RecType{StreamFields: sfs}, nil
+	case *avro.MapSchema:
+		// the keys of a map are dynamic, so no sub fields are defined
+		return &ast.RecType{}, nil
+	case *avro.ArraySchema:
+		it, err := convertAvroType(t.Items())
+		if err != nil {
+			return nil, err
+		}
+		switch itt := it.(type) {
+		case *ast.BasicType:
+			return &ast.ArrayType{Type: itt.Type}, nil
+		case *ast.RecType:
+			return &ast.ArrayType{Type: ast.STRUCT, FieldType: itt}, nil
+		default:
+			return &ast.ArrayType{Type: ast.ARRAY, FieldType: itt}, nil
+		}
+	case *avro.UnionSchema:
+		// only the nullable union of one type can be typed
+		var types []avro.Schema
+		for _, ut := range t.Types() {
+			if ut.Type() != avro.Null {
+				types = append(types, ut)
+			}
+		}
+		if len(types) != 1 {
+			return nil, fmt.Errorf("union %s is not supported", t.String())
+		}
+		return convertAvroType(types[0])
+	case *avro.EnumSchema:
+		return &ast.BasicType{Type: ast.STRINGS}, nil
+	case *avro.FixedSchema:
+		return &ast.BasicType{Type: ast.BYTEA}, nil
+	case *avro.PrimitiveSchema:
+		if l := t.Logical(); l != nil {
+			switch l.Type() {
+			case avro.TimestampMillis, avro.TimestampMicros, avro.LocalTimestampMillis, avro.LocalTimestampMicros, avro.Date:
+				return &ast.BasicType{Type: ast.DATETIME}, nil
+			case avro.Decimal:
+				return &ast.BasicType{Type: ast.FLOAT}, nil
+			}
+		}
+		switch t.Type() {
+		case avro.Boolean:
+			return &ast.BasicType{Type: ast.BOOLEAN}, nil
+		case avro.Int, avro.Long:
+			return &ast.BasicType{Type: ast.BIGINT}, nil
+		case avro.Float, avro.Double:
+			return &ast.BasicType{Type: ast.FLOAT}, nil
+		case avro.String:
+			return &ast.BasicType{Type: ast.STRINGS}, nil
+		case avro.Bytes:
+			return &ast.BasicType{Type: ast.BYTEA}, nil
+		}
+	}
+	return nil, fmt.Errorf("type %s is not supported", s.Type())
+}
+
+var _ modules.SchemaTypeDef = &AvroType{}
@@ -0,0 +1,32 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+func TestInferAvro(t *testing.T) {
+	at := &AvroType{}
+	result, err := at.Infer(nil, "test/order.avsc", "")
+	require.NoError(t, err)
+	expected := ast.StreamFields{
+		{Name: "id", FieldType: &ast.BasicType{Type: ast.BIGINT}},
+		{Name: "name", FieldType: &ast.BasicType{Type: ast.STRINGS}},
+		{Name: "price", FieldType: &ast.BasicType{Type: ast.FLOAT}},
+		{Name: "ts", FieldType: &ast.BasicType{Type: ast.DATETIME}},
+		{Name: "status", FieldType: &ast.BasicType{Type: ast.STRINGS}},
+		{Name: "tags", FieldType: &ast.ArrayType{Type: ast.STRINGS}},
+		{Name: "attrs", FieldType: &ast.RecType{}},
+		{Name: "item", FieldType: &ast.RecType{StreamFields: []ast.StreamField{
+			{Name: "sku", FieldType: &ast.BasicType{Type: ast.STRINGS}},
+			{Name: "qty", FieldType: &ast.BasicType{Type: ast.BIGINT}},
+		}}},
+	}
+	require.Equal(t, expected, result)
+
+	_, err = at.Infer(nil, "test/notexist.avsc", "")
+	require.EqualError(t, err, "read schema file test/notexist.avsc failed: open test/notexist.avsc: no such file or directory")
+}
@@ -1,4 +1,4 @@
-// Copyright 2022-2023 EMQ Technologies Co., Ltd.
+// Copyright 2022-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -23,6 +23,8 @@ import (
 )
 
 func InferFromSchemaFile(schemaType string, schemaId string) (ast.StreamFields, error) {
+	// the format is case-insensitive in the stream definition like FORMAT="JSON"
+	schemaType = strings.ToLower(schemaType)
 	if c, ok := modules.SchemaTypeDefs[schemaType]; ok {
 		fileId := ""
 		messageId := ""
@@ -0,0 +1,145 @@
+//go:build schema || !core
+
+package schema
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+)
+
+type JsonSchemaType struct{}
+
+func (j *JsonSchemaType) Scan(logger api.Logger, schemaDir string) (map[string]*modules.Files, error) {
+	files, err := os.ReadDir(schemaDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read schema directory: %s", err)
+	}
+	newSchemas := make(map[string]*modules.Files, len(files))
+	for _, file := range files {
+		fileName := filepath.Base(file.Name())
+		if filepath.Ext(fileName) != ".json" {
+			continue
+		}
+		schemaId := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+		newSchemas[schemaId] = &modules.Files{SchemaFile: filepath.Join(schemaDir, file.Name())}
+		logger.Infof("schema file %s/%s loaded", schemaDir, schemaId)
+	}
+	return newSchemas, nil
+}
+
+// Infer converts the properties of the top level object schema. The messageId is not used because a JSON schema file has only one root schema.
+func (j *JsonSchemaType) Infer(_ api.Logger, filePath string, _ string) (ast.StreamFields, error) {
+	s, err := CompileJsonSchema(filePath)
+	if err != nil {
+		return nil, err
+	}
+	s = derefJsonSchema(s)
+	if jsonSchemaType(s) != "object" {
+		return nil, fmt.Errorf("schema file %s must define an object", filePath)
+	}
+	return convertJsonObject(s)
+}
+
+func convertJsonObject(s *jsonschema.Schema) (ast.StreamFields, error) {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	// the properties of JSON schema are unordered, sort them to have a stable result
+	sort.Strings(names)
+	result := make(ast.StreamFields, 0, len(names))
+	for _, name := range names {
+		ft, err := convertJsonSchemaType(s.Properties[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid type for field '%s': %v", name, err)
+		}
+		result = append(result, ast.StreamField{Name: name, FieldType: ft})
+	}
+	return result, nil
+}
+
+func convertJsonSchemaType(s *jsonschema.Schema) (ast.FieldType, error) {
+	s = derefJsonSchema(s)
+	switch t := jsonSchemaType(s); t {
+	case "object":
+		sfs, err := convertJsonObject(s)
+		if err != nil {
+			return nil, err
+		}
+		if len(sfs) == 0 {
+			// the keys are dynamic, so no sub fields are defined
+			return &ast.RecType{}, nil
+		}
+		return &ast.RecType{StreamFields: sfs}, nil
+	case "array":
+		items := s.Items2020
+		if is, ok := s.Items.(*jsonschema.Schema); ok {
+			items = is
+		}
+		if items == nil {
+			return nil, fmt.Errorf("array items must be defined")
+		}
+		it, err := convertJsonSchemaType(items)
+		if err != nil {
+			return nil, err
+		}
+		switch itt := it.(type) {
+		case *ast.BasicType:
+			return &ast.ArrayType{Type: itt.Type}, nil
+		case *ast.RecType:
+			return &ast.ArrayType{Type: ast.STRUCT, FieldType: itt}, nil
+		default:
+			return &ast.ArrayType{Type: ast.ARRAY, FieldType: itt}, nil
+		}
+	case "integer":
+		return &ast.BasicType{Type: ast.BIGINT}, nil
+	case "number":
+		return &ast.BasicType{Type: ast.FLOAT}, nil
+	case "string":
+		return &ast.BasicType{Type: ast.STRINGS}, nil
+	case "boolean":
+		return &ast.BasicType{Type: ast.BOOLEAN}, nil
+	case "":
+		return nil, fmt.Errorf("type is not defined")
+	default:
+		return nil, fmt.Errorf("type %s is not supported", t)
+	}
+}
+
+// jsonSchemaType returns the only non-null type of the schema. An object is implied if the properties are defined.
+func jsonSchemaType(s *jsonschema.Schema) string {
+	if s.Types == nil {
+		if len(s.Properties) > 0 {
+			return "object"
+		}
+		return ""
+	}
+	var types []string
+	for _, t := range s.Types.ToStrings() {
+		if t != "null" {
+			types = append(types, t)
+		}
+	}
+	if len(types) != 1 {
+		return strings.Join(types, "|")
+	}
+	return types[0]
+}
+
+func derefJsonSchema(s *jsonschema.Schema) *jsonschema.Schema {
+	for s.Ref != nil && s.Types == nil && len(s.Properties) == 0 {
+		s = s.Ref
+	}
+	return s
+}
+
+var _ modules.SchemaTypeDef = &JsonSchemaType{}
@@ -0,0 +1,33 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+func TestInferJsonSchema(t *testing.T) {
+	jt := &JsonSchemaType{}
+	result, err := jt.Infer(nil, "test/order.json", "")
+	require.NoError(t, err)
+	item := &ast.RecType{StreamFields: []ast.StreamField{
+		{Name: "qty", FieldType: &ast.BasicType{Type: ast.BIGINT}},
+		{Name: "sku", FieldType: &ast.BasicType{Type: ast.STRINGS}},
+	}}
+	expected := ast.StreamFields{
+		{Name: "attrs", FieldType: &ast.RecType{}},
+		{Name: "id", FieldType: &ast.BasicType{Type: ast.BIGINT}},
+		{Name: "item", FieldType: item},
+		{Name: "items", FieldType: &ast.ArrayType{Type: ast.STRUCT, FieldType: item}},
+		{Name: "name", FieldType: &ast.BasicType{Type: ast.STRINGS}},
+		{Name: "paid", FieldType: &ast.BasicType{Type: ast.BOOLEAN}},
+		{Name: "price", FieldType: &ast.BasicType{Type: ast.FLOAT}},
+		{Name: "tags", FieldType: &ast.ArrayType{Type: ast.STRINGS}},
+	}
+	require.Equal(t, expected, result)
+
+	_, err = jt.Infer(nil, "test/notexist.json", "")
+	require.EqualError(t, err, "read schema file test/notexist.json failed: stat test/notexist.json: no such file or directory")
+}
@@ -1,4 +1,4 @@
-// Copyright 2022 EMQ Technologies Co., Ltd.
+// Copyright 2022-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -17,6 +17,9 @@ package schema
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
 
 	"github.com/lf-edge/ekuiper/v2/pkg/modules"
 )
@@ -49,7 +52,7 @@ func (i *Info) Validate() error {
 		return fmt.Errorf("unsupported schema type %s", i.Type)
 	}
 	switch i.Type {
-	case modules.PROTOBUF:
+	case modules.PROTOBUF, modules.AVRO, modules.JSON:
 		if i.Content == "" && i.FilePath == "" {
 			return fmt.Errorf("must specify content or file")
 		}
@@ -60,3 +63,15 @@ func (i *Info) Validate() error {
 	}
 	return nil
 }
+
+// CompileJsonSchema compiles the JSON schema file. The relative $ref are resolved by the file location.
+func CompileJsonSchema(filePath string) (*jsonschema.Schema, error) {
+	if _, err := os.Stat(filePath); err != nil {
+		return nil, fmt.Errorf("read schema file %s failed: %s", filePath, err)
+	}
+	s, err := jsonschema.NewCompiler().Compile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("parse schema file %s failed: %s", filePath, err)
+	}
+	return s, nil
+}
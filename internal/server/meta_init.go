@@ -1,4 +1,4 @@
-// Copyright 2022 EMQ Technologies Co., Ltd.
+// Copyright 2022-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -63,6 +63,7 @@ func (m metaComp) rest(r *mux.Router) {
 	r.HandleFunc("/metadata/resource", resourceHandler).Methods(http.MethodGet)
 	r.HandleFunc("/metadata/resources", resourcesHandler).Methods(http.MethodGet)
 	r.HandleFunc("/metadata/sources/connection/{name}", sourceConnectionHandler).Methods(http.MethodPost)
+	r.HandleFunc("/metadata/sources/browse/{name}", sourceBrowseHandler).Methods(http.MethodPost)
 	r.HandleFunc("/metadata/sinks/connection/{name}", sinkConnectionHandler).Methods(http.MethodPost)
 	r.HandleFunc("/metadata/lookups/connection/{name}", lookupConnectionHandler).Methods(http.MethodPost)
 	for _, endpoint := range metaEndpoints {
@@ -373,6 +374,26 @@ func sourceConnectionHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+func sourceBrowseHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	vars := mux.Vars(r)
+
+	sourceNm := vars["name"]
+	config := map[string]interface{}{}
+	v, _ := io.ReadAll(r.Body)
+	err := json.Unmarshal(v, &config)
+	if err != nil {
+		handleError(w, err, "", logger)
+		return
+	}
+	result, err := node.SourceBrowse(sourceNm, config)
+	if err != nil {
+		handleError(w, err, "", logger)
+		return
+	}
+	jsonResponse(result, w, logger)
+}
+
 func lookupConnectionHandler(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 	vars := mux.Vars(r)
@@ -1,4 +1,4 @@
-// Copyright 2023 EMQ Technologies Co., Ltd.
+// Copyright 2023-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -96,6 +96,20 @@ func (suite *MetaTestSuite) TestLookupPing() {
 	require.Equal(suite.T(), http.StatusOK, w.Code)
 }
 
+func (suite *MetaTestSuite) TestSourceBrowse() {
+	req, _ := http.NewRequest(http.MethodPost, "/metadata/sources/browse/mqtt", bytes.NewBufferString(`{"server": "tcp://127.0.0.1:1883"}`))
+	w := httptest.NewRecorder()
+	suite.r.ServeHTTP(w, req)
+	require.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	require.Contains(suite.T(), w.Body.String(), "source mqtt doesn't support browse")
+
+	req, _ = http.NewRequest(http.MethodPost, "/metadata/sources/browse/opcua", bytes.NewBufferString(`{"endpoint": "tcp://127.0.0.1:4840"}`))
+	w = httptest.NewRecorder()
+	suite.r.ServeHTTP(w, req)
+	require.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	require.Contains(suite.T(), w.Body.String(), "invalid endpoint tcp://127.0.0.1:4840, must start with opc.tcp://")
+}
+
 func (suite *MetaTestSuite) TestSinksMetaHandler() {
 	req, _ := http.NewRequest(http.MethodGet, "/metadata/sinks", bytes.NewBufferString("any"))
 	w := httptest.NewRecorder()
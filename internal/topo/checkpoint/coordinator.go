@@ -1,4 +1,4 @@
-// Copyright 2021-2024 EMQ Technologies Co., Ltd.
+// Copyright 2021-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -94,6 +94,7 @@ type Coordinator struct {
 	toBeClean               int
 	tasksToTrigger          []Responder
 	tasksToWaitFor          []Responder
+	sourceTasks             []StreamTask
 	sinkTasks               []SinkTask
 	pendingCheckpoints      *sync.Map
 	completedCheckpoints    *checkpointStore
@@ -143,6 +144,7 @@ func NewCoordinator(ruleId string, sources []StreamTask, operators []NonSourceTa
 	return &Coordinator{
 		tasksToTrigger:     sourceResponders,
 		tasksToWaitFor:     allResponders,
+		sourceTasks:        sources,
 		sinkTasks:          sinks,
 		pendingCheckpoints: new(sync.Map),
 		completedCheckpoints: &checkpointStore{
@@ -315,6 +317,16 @@ func (c *Coordinator) complete(checkpointId int64) {
 			}
 			return true
 		})
+		for _, t := range c.sourceTasks {
+			if l, ok := t.(CheckpointListener); ok {
+				l.NotifyCheckpointComplete(checkpointId)
+			}
+		}
+		for _, t := range c.sinkTasks {
+			if l, ok := t.(CheckpointListener); ok {
+				l.NotifyCheckpointComplete(checkpointId)
+			}
+		}
 		logger.Debugf("Totally complete checkpoint %d", checkpointId)
 	} else {
 		logger.Infof("Cannot find checkpoint %d to complete", checkpointId)
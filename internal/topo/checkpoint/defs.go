@@ -1,4 +1,4 @@
-// Copyright 2021-2024 EMQ Technologies Co., Ltd.
+// Copyright 2021-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -46,6 +46,17 @@ type SinkTask interface {
 	NonSourceTask
 }
 
+// CheckpointListener is implemented by the source and sink tasks which need to know the checkpoint lifecycle, for
+// example, to commit the offset to the upstream system or the transaction to the downstream system after the
+// checkpoint completes
+type CheckpointListener interface {
+	// PrepareCheckpoint is called right before the task sends out the barrier and snapshots its state. The checkpoint
+	// is declined if it returns an error
+	PrepareCheckpoint(checkpointId int64) error
+	// NotifyCheckpointComplete is called after all tasks have saved the state of the checkpoint
+	NotifyCheckpointComplete(checkpointId int64)
+}
+
 type BufferOrEvent struct {
 	Data    interface{}
 	Channel string
@@ -1,4 +1,4 @@
-// Copyright 2021-2024 EMQ Technologies Co., Ltd.
+// Copyright 2021-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -55,6 +55,15 @@ func (re *ResponderExecutor) TriggerCheckpoint(checkpointId int64) error {
 		CheckpointId: checkpointId,
 		OpId:         name,
 	}
+	if l, ok := re.task.(CheckpointListener); ok {
+		if err := l.PrepareCheckpoint(checkpointId); err != nil {
+			re.responder <- &Signal{
+				Message: DEC,
+				Barrier: Barrier{CheckpointId: checkpointId, OpId: name},
+			}
+			return fmt.Errorf("prepare checkpoint %d error: %v", checkpointId, err)
+		}
+	}
 	// broadcast barrier
 	if nonSink, ok := re.task.(NonSinkTask); ok {
 		nonSink.Broadcast(barrier)
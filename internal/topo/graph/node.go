@@ -37,6 +37,7 @@ type Window struct {
 	Unit     string `json:"unit"`
 	Size     int    `json:"size"`
 	Interval int    `json:"interval"`
+	TimeZone string `json:"timeZone"`
 }
 
 type Join struct {
@@ -0,0 +1,235 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/io/memory/pubsub"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/infra"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+// DeadLetterOp is the side output of the rule. It collects the data which cannot be processed by the rule,
+// such as the payload failed to decode, the events later than the allowed lateness and the data failed to send by the sinks.
+// It publishes them with the error context to a memory topic and/or sends them by a sink.
+// The late events are also published as they are to the late event topic if set.
+// It is not connected with other nodes, the topo sets it to the nodes which send to it by sendDeadLetter.
+type DeadLetterOp struct {
+	*defaultSinkNode
+	topic     string
+	lateTopic string
+	sink      api.Sink
+	// converter encodes the records for the bytes collector sink by the format of the sink
+	converter message.Converter
+}
+
+// DeadLetterNode is the node which can send the unprocessable data to the dead letter
+type DeadLetterNode interface {
+	SetDeadLetter(dl *DeadLetterOp)
+}
+
+// sideOutput is the item sent to the dead letter op
+type sideOutput struct {
+	// record is the dead letter with the error context, nil if the dead letter is not configured
+	record *xsql.Tuple
+	// late is the late event to publish to the late topic as it is
+	late *xsql.Tuple
+}
+
+func NewDeadLetterOp(name string, topic string, lateTopic string, sink api.Sink, converter message.Converter, options *def.RuleOption) (*DeadLetterOp, error) {
+	if topic == "" && sink == nil && lateTopic == "" {
+		return nil, fmt.Errorf("dead letter requires a topic or an action")
+	}
+	if sink != nil {
+		switch sink.(type) {
+		case api.TupleCollector:
+		case api.BytesCollector:
+			if converter == nil {
+				return nil, fmt.Errorf("dead letter bytes sink requires a converter")
+			}
+		default:
+			return nil, fmt.Errorf("dead letter sink does not implement any collector")
+		}
+	}
+	return &DeadLetterOp{
+		defaultSinkNode: newDefaultSinkNode(name, options),
+		topic:           topic,
+		lateTopic:       lateTopic,
+		sink:            sink,
+		converter:       converter,
+	}, nil
+}
+
+// Exec publishes or sends the dead letters one by one. The error of sending is only logged.
+func (o *DeadLetterOp) Exec(ctx api.StreamContext, errCh chan<- error) {
+	o.prepareExec(ctx, errCh, "op")
+	if o.topic != "" {
+		pubsub.CreatePub(o.topic)
+	}
+	if o.lateTopic != "" {
+		pubsub.CreatePub(o.lateTopic)
+	}
+	go func() {
+		defer func() {
+			if o.topic != "" {
+				pubsub.RemovePub(o.topic)
+			}
+			if o.lateTopic != "" {
+				pubsub.RemovePub(o.lateTopic)
+			}
+			if o.sink != nil {
+				_ = o.sink.Close(ctx)
+			}
+			o.Close()
+		}()
+		err := infra.SafeRun(func() error {
+			if o.sink != nil {
+				err := o.sink.Connect(ctx, func(status string, message string) {
+					ctx.GetLogger().Infof("dead letter sink %s status %s: %s", o.name, status, message)
+				})
+				if err != nil {
+					return err
+				}
+			}
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case item := <-o.input:
+					so, ok := item.(*sideOutput)
+					if !ok {
+						break
+					}
+					o.statManager.IncTotalRecordsIn()
+					if so.late != nil && o.lateTopic != "" {
+						pubsub.Produce(ctx, o.lateTopic, so.late)
+					}
+					if so.record == nil {
+						o.statManager.IncTotalRecordsOut()
+						break
+					}
+					if o.topic != "" {
+						pubsub.Produce(ctx, o.topic, so.record)
+					}
+					if o.sink != nil {
+						if err := o.collect(ctx, so.record); err != nil {
+							ctx.GetLogger().Errorf("send dead letter %v error: %v", so.record.Message, err)
+							o.statManager.IncTotalExceptions(err.Error())
+							break
+						}
+					}
+					o.statManager.IncTotalRecordsOut()
+				}
+			}
+		})
+		if err != nil {
+			infra.DrainError(ctx, err, errCh)
+		}
+	}()
+}
+
+func (o *DeadLetterOp) collect(ctx api.StreamContext, t *xsql.Tuple) error {
+	switch ss := o.sink.(type) {
+	case api.TupleCollector:
+		return ss.Collect(ctx, t)
+	case api.BytesCollector:
+		b, err := o.converter.Encode(ctx, t.ToMap())
+		if err != nil {
+			return err
+		}
+		return ss.Collect(ctx, &xsql.RawTuple{Rawdata: b, Timestamp: t.Timestamp})
+	}
+	return nil
+}
+
+// hasDeadLetter returns whether the records with the error context are published or sent
+func (o *DeadLetterOp) hasDeadLetter() bool {
+	return o.topic != "" || o.sink != nil
+}
+
+// write adds a dead letter. It blocks the caller node when the buffer is full so that no dead letter is lost,
+// until the rule is stopped. The late event is also published as it is if set.
+func (o *DeadLetterOp) write(ctx api.StreamContext, nodeName string, err error, payload any, late *xsql.Tuple) {
+	so := &sideOutput{}
+	if late != nil && o.lateTopic != "" {
+		so.late = late
+	}
+	if o.hasDeadLetter() {
+		now := timex.GetNow()
+		so.record = &xsql.Tuple{
+			Message: map[string]any{
+				"ruleId":    ctx.GetRuleId(),
+				"node":      nodeName,
+				"error":     err.Error(),
+				"timestamp": now.UnixMilli(),
+				"payload":   deadLetterPayload(payload),
+			},
+			Timestamp: now,
+		}
+	}
+	if so.record == nil && so.late == nil {
+		return
+	}
+	select {
+	case o.input <- so:
+	case <-ctx.Done():
+		ctx.GetLogger().Warnf("rule stopped, drop the dead letter of node %s: %v", nodeName, err)
+	}
+}
+
+// deadLetterPayload converts the original data to a form that can be encoded and replayed
+func deadLetterPayload(payload any) any {
+	switch p := payload.(type) {
+	case []byte:
+		if utf8.Valid(p) {
+			return string(p)
+		}
+		return p
+	case api.RawTuple:
+		return deadLetterPayload(p.Raw())
+	case api.MessageTupleList:
+		return p.ToMaps()
+	case api.MessageTuple:
+		return p.ToMap()
+	default:
+		return p
+	}
+}
+
+// SetDeadLetter sets the rule level dead letter which the node sends the unprocessable data to
+func (o *defaultNode) SetDeadLetter(dl *DeadLetterOp) {
+	o.deadLetter = dl
+}
+
+// sendDeadLetter sends the data to the dead letter of the rule if configured
+func (o *defaultNode) sendDeadLetter(ctx api.StreamContext, err error, payload any) {
+	if o.deadLetter != nil {
+		o.deadLetter.write(ctx, o.name, err, payload, nil)
+	}
+}
+
+// sendLateEvent sends the dropped late event to the dead letter and the late event topic if configured
+func (o *defaultNode) sendLateEvent(ctx api.StreamContext, err error, d *xsql.Tuple) {
+	if o.deadLetter != nil {
+		o.deadLetter.write(ctx, o.name, err, d, d)
+	}
+}
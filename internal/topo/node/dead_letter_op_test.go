@@ -0,0 +1,173 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/converter"
+	"github.com/lf-edge/ekuiper/v2/internal/io/memory/pubsub"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/context"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/state"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+func TestNewDeadLetterOp(t *testing.T) {
+	_, err := NewDeadLetterOp("deadLetter", "", "", nil, nil, &def.RuleOption{BufferLength: 10})
+	require.EqualError(t, err, "dead letter requires a topic or an action")
+	_, err = NewDeadLetterOp("deadLetter", "", "", &mockBytesSink{}, nil, &def.RuleOption{BufferLength: 10})
+	require.EqualError(t, err, "dead letter bytes sink requires a converter")
+}
+
+func TestDeadLetterDecodeError(t *testing.T) {
+	dl, err := NewDeadLetterOp("deadLetter", "dlTopic", "", nil, nil, &def.RuleOption{BufferLength: 10})
+	require.NoError(t, err)
+	dlCh := pubsub.CreateSub("dlTopic", nil, "TestDeadLetterDecodeError", 10)
+	defer pubsub.CloseSourceConsumerChannel("dlTopic", "TestDeadLetterDecodeError")
+
+	contextLogger := conf.Log.WithField("rule", "TestDeadLetterDecodeError")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("TestDeadLetterDecodeError", def.AtMostOnce)
+	errCh := make(chan error)
+	dl.Exec(ctx.WithMeta("TestDeadLetterDecodeError", "deadLetter", tempStore), errCh)
+
+	op, err := NewDecodeOp(ctx, false, "decode", &def.RuleOption{BufferLength: 10, Concurrency: 1}, nil, nil)
+	require.NoError(t, err)
+	out := make(chan any, 10)
+	require.NoError(t, op.AddOutput(out, "test"))
+	op.SetDeadLetter(dl)
+	op.Exec(ctx.WithMeta("TestDeadLetterDecodeError", "decode", tempStore), errCh)
+
+	op.input <- &xsql.RawTuple{Emitter: "test", Rawdata: []byte(`{"a":1`), Timestamp: time.UnixMilli(111)}
+	select {
+	case r := <-dlCh:
+		tuple, ok := r.(*xsql.Tuple)
+		require.True(t, ok)
+		assert.Equal(t, xsql.Message{
+			"ruleId":    "TestDeadLetterDecodeError",
+			"node":      "decode",
+			"error":     "cannot parse JSON: cannot parse object: unexpected end of object; unparsed tail: \"\"",
+			"timestamp": timex.GetNowInMilli(),
+			"payload":   `{"a":1`,
+		}, tuple.Message)
+	case <-time.After(5 * time.Second):
+		t.Fatal("receive dead letter timeout")
+	}
+}
+
+func TestDeadLetterBufferFull(t *testing.T) {
+	dl, err := NewDeadLetterOp("deadLetter", "dlTopic", "", nil, nil, &def.RuleOption{BufferLength: 1})
+	require.NoError(t, err)
+	contextLogger := conf.Log.WithField("rule", "TestDeadLetterBufferFull")
+	tempStore, _ := state.CreateStore("TestDeadLetterBufferFull", def.AtMostOnce)
+	ctx, cancel := context.WithValue(context.Background(), context.LoggerKey, contextLogger).WithMeta("TestDeadLetterBufferFull", "decode", tempStore).(*context.DefaultContext).WithCancel()
+
+	dl.write(ctx, "decode", errors.New("e1"), []byte("1"), nil)
+	// The buffer is full, the second write waits until the first one is consumed
+	done := make(chan struct{})
+	go func() {
+		dl.write(ctx, "decode", errors.New("e2"), []byte("2"), nil)
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("write should block when the buffer is full")
+	case <-time.After(100 * time.Millisecond):
+	}
+	r := <-dl.input
+	assert.Equal(t, "e1", r.(*sideOutput).record.Message["error"])
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("write is not unblocked")
+	}
+	r = <-dl.input
+	assert.Equal(t, "e2", r.(*sideOutput).record.Message["error"])
+
+	// Stopping the rule unblocks the writer
+	dl.write(ctx, "decode", errors.New("e3"), []byte("3"), nil)
+	done = make(chan struct{})
+	go func() {
+		dl.write(ctx, "decode", errors.New("e4"), []byte("4"), nil)
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("write is not unblocked after the rule stops")
+	}
+}
+
+func TestDeadLetterPayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload any
+		exp     any
+	}{
+		{"text", []byte("hello"), "hello"},
+		{"binary", []byte{0xff, 0xfe}, []byte{0xff, 0xfe}},
+		{"raw", &xsql.RawTuple{Rawdata: []byte(`{"a":1}`)}, `{"a":1}`},
+		{"tuple", &xsql.Tuple{Message: map[string]any{"a": 1}}, map[string]any{"a": 1}},
+		{"other", 12, 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.exp, deadLetterPayload(tt.payload))
+		})
+	}
+}
+
+type mockBytesSink struct {
+	result [][]byte
+}
+
+func (m *mockBytesSink) Provision(_ api.StreamContext, _ map[string]any) error {
+	return nil
+}
+
+func (m *mockBytesSink) Close(_ api.StreamContext) error {
+	return nil
+}
+
+func (m *mockBytesSink) Connect(_ api.StreamContext, _ api.StatusChangeHandler) error {
+	return nil
+}
+
+func (m *mockBytesSink) Collect(_ api.StreamContext, item api.RawTuple) error {
+	m.result = append(m.result, item.Raw())
+	return nil
+}
+
+func TestDeadLetterSinkFormat(t *testing.T) {
+	ctx := mockContext.NewMockContext("TestDeadLetterSinkFormat", "deadLetter")
+	c, err := converter.GetOrCreateConverter(ctx, "delimited", "", nil, map[string]any{"fields": []string{"node", "error"}, "delimiter": ","})
+	require.NoError(t, err)
+	snk := &mockBytesSink{}
+	dl, err := NewDeadLetterOp("deadLetter", "", "", snk, c, &def.RuleOption{BufferLength: 10})
+	require.NoError(t, err)
+	require.NoError(t, dl.collect(ctx, &xsql.Tuple{Message: map[string]any{"node": "decode", "error": "invalid"}}))
+	require.Equal(t, [][]byte{[]byte("decode,invalid")}, snk.result)
+}
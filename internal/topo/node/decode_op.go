@@ -148,6 +148,7 @@ func (o *DecodeOp) Worker(ctx api.StreamContext, item any) []any {
 	case *xsql.RawTuple:
 		result, err := o.converter.Decode(ctx, d.Raw())
 		if err != nil {
+			o.sendDeadLetter(ctx, err, d.Raw())
 			return []any{err}
 		}
 
@@ -236,10 +237,13 @@ func (o *DecodeOp) PayloadDecodeWorker(ctx api.StreamContext, item any) []any {
 		delete(d.Message, o.c.PayloadField)
 		raw, err := cast.ToByteA(payload, cast.CONVERT_SAMEKIND)
 		if err != nil {
-			return []any{fmt.Errorf("payload is not bytes: %v", err)}
+			err = fmt.Errorf("payload is not bytes: %v", err)
+			o.sendDeadLetter(ctx, err, payload)
+			return []any{err}
 		}
 		result, err := o.converter.Decode(ctx, raw)
 		if err != nil {
+			o.sendDeadLetter(ctx, err, raw)
 			return []any{err}
 		}
 		return transTuple(d, result)
@@ -342,6 +346,7 @@ func (o *DecodeOp) PayloadBatchDecodeWorker(ctx api.StreamContext, item any) []a
 			result, err := o.converter.Decode(ctx, raw)
 			if err != nil {
 				ctx.GetLogger().Warnf("cannot decode payload: %v", err)
+				o.sendDeadLetter(ctx, err, raw)
 				continue
 			}
 			if sv, ok := result.(model.SliceVal); ok {
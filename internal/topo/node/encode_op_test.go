@@ -1,4 +1,4 @@
-// Copyright 2021-2025 EMQ Technologies Co., Ltd.
+// Copyright 2021-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -15,13 +15,17 @@
 package node
 
 import (
+	"encoding/json"
 	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
@@ -148,3 +152,75 @@ func TestEncodeValidate(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, "format type cann not supported", err.Error())
 }
+
+func TestEncodeXMLProps(t *testing.T) {
+	conf.InitConf()
+	ctx := mockContext.NewMockContext("test1", "encode_test")
+	sc, err := ParseConf(ctx.GetLogger(), map[string]any{
+		"format":         "xml",
+		"xmlRootElement": "tags",
+		"xmlItemElement": "tag",
+	})
+	require.NoError(t, err)
+	op, err := NewEncodeOp(ctx, "test", &def.RuleOption{BufferLength: 10, SendError: true}, nil, sc)
+	require.NoError(t, err)
+	out := make(chan any, 100)
+	err = op.AddOutput(out, "test")
+	require.NoError(t, err)
+	errCh := make(chan error)
+	op.Exec(ctx, errCh)
+	op.input <- &xsql.Tuple{Message: map[string]any{"name": "t1", "@id": 1}}
+	r := <-out
+	rt, ok := r.(*xsql.RawTuple)
+	require.True(t, ok)
+	assert.Equal(t, `<tags id="1"><name>t1</name></tags>`, string(rt.Rawdata))
+}
+
+func TestSinkConfConverterProps(t *testing.T) {
+	conf.InitConf()
+	ctx := mockContext.NewMockContext("test1", "encode_test")
+	sc, err := ParseConf(ctx.GetLogger(), map[string]any{
+		"format":         "xml",
+		"topic":          "demo",
+		"delimiter":      ";",
+		"xmlRootElement": "tags",
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"delimiter":      ";",
+		"hasHeader":      false,
+		"fields":         []string(nil),
+		"xmlRootElement": "tags",
+	}, sc.ConverterProps())
+}
+
+// TestEncodeAvroRegistry verifies the schema registry props reach the avro converter so that the payload is framed
+// with the schema id
+func TestEncodeAvroRegistry(t *testing.T) {
+	conf.InitConf()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet || req.URL.Path != "/subjects/orders-value/versions/latest" {
+			http.NotFound(w, req)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 7, "schema": `{"type":"record","name":"Order","fields":[{"name":"id","type":"long"}]}`})
+	}))
+	defer server.Close()
+	ctx := mockContext.NewMockContext("test1", "encode_test")
+	sc, err := ParseConf(ctx.GetLogger(), map[string]any{
+		"format":                "avro",
+		"schemaRegistryUrl":     server.URL,
+		"schemaRegistrySubject": "orders-value",
+	})
+	require.NoError(t, err)
+	op, err := NewEncodeOp(ctx, "test", &def.RuleOption{BufferLength: 10, SendError: true}, nil, sc)
+	require.NoError(t, err)
+	out := make(chan any, 10)
+	require.NoError(t, op.AddOutput(out, "test"))
+	errCh := make(chan error)
+	op.Exec(ctx, errCh)
+	op.input <- &xsql.Tuple{Message: map[string]any{"id": int64(3)}}
+	r := <-out
+	require.IsType(t, &xsql.RawTuple{}, r)
+	require.Equal(t, []byte{0, 0, 0, 0, 7, 0x06}, r.(*xsql.RawTuple).Rawdata)
+}
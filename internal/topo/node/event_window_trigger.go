@@ -16,6 +16,7 @@ package node
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
@@ -58,13 +59,13 @@ func (w *EventTimeTrigger) getNextWindow(inputs []xsql.EventRow, current time.Ti
 	switch w.window.Type {
 	case ast.TUMBLING_WINDOW, ast.HOPPING_WINDOW:
 		if !current.IsZero() {
-			return current.Add(w.interval)
+			return w.window.nextWindowEnd(current, w.interval)
 		} else { // first run without a previous window
 			nextTs := getEarliestEventTs(inputs, current, watermark)
 			if nextTs.Equal(timex.Maxtime) {
 				return nextTs
 			}
-			return getAlignedWindowEndTime(nextTs, w.window.RawInterval, w.window.TimeUnit)
+			return getAlignedWindowEndTime(nextTs.In(w.window.location()), w.window.RawInterval, w.window.TimeUnit)
 		}
 	case ast.SLIDING_WINDOW:
 		nextTs := getEarliestEventTs(inputs, current, watermark)
@@ -78,7 +79,7 @@ func (w *EventTimeTrigger) getNextSessionWindow(inputs []xsql.EventRow, now time
 	if len(inputs) > 0 {
 		timeout, duration := w.window.Interval, w.window.Length
 		et := inputs[0].GetTimestamp()
-		tick := getAlignedWindowEndTime(et, w.window.RawInterval, w.window.TimeUnit)
+		tick := getAlignedWindowEndTime(et.In(w.window.location()), w.window.RawInterval, w.window.TimeUnit)
 		p := time.Time{}
 		ticked := false
 		for _, tuple := range inputs {
@@ -179,6 +180,10 @@ func (o *WindowOperator) execEventWindow(ctx api.StreamContext, inputs []xsql.Ev
 				}
 				nextWindowEndTs = windowEndTs
 				log.Debugf("next window end %d", nextWindowEndTs.UnixMilli())
+				if o.window.AllowedLateness > 0 {
+					o.lastWatermark = watermarkTs
+					o.gcLateWindows(ctx, watermarkTs)
+				}
 			case xsql.EventRow:
 				o.onProcessStart(ctx, d)
 				o.handleTraceIngestTuple(ctx, d)
@@ -190,7 +195,11 @@ func (o *WindowOperator) execEventWindow(ctx api.StreamContext, inputs []xsql.Ev
 				if o.window.Type == ast.SLIDING_WINDOW && o.isMatchCondition(ctx, d) {
 					o.triggerTS = append(o.triggerTS, d.GetTimestamp())
 				}
-				inputs = append(inputs, d)
+				if o.window.AllowedLateness > 0 && d.GetTimestamp().Before(o.lastWatermark) {
+					inputs = o.handleLateEvent(ctx, inputs, d, prevWindowEndTs)
+				} else {
+					inputs = append(inputs, d)
+				}
 				o.span = nil
 				o.onProcessEnd(ctx)
 				_ = ctx.PutState(WindowInputsKey, inputs)
@@ -208,6 +217,111 @@ func (o *WindowOperator) execEventWindow(ctx api.StreamContext, inputs []xsql.Ev
 	}
 }
 
+// LateWindow is a closed window which is kept during the allowed lateness to update the result for the late events
+type LateWindow struct {
+	Start   time.Time
+	End     time.Time
+	Content []xsql.EventRow
+}
+
+func (o *WindowOperator) keepLateWindow(ctx api.StreamContext, start, end time.Time, content []xsql.EventRow) {
+	o.lateWindows = append(o.lateWindows, &LateWindow{Start: start, End: end, Content: content})
+	_ = ctx.PutState(LateWindowsKey, o.lateWindows)
+}
+
+// gcLateWindows removes the closed windows whose allowed lateness has passed
+func (o *WindowOperator) gcLateWindows(ctx api.StreamContext, watermark time.Time) {
+	i := 0
+	for ; i < len(o.lateWindows); i++ {
+		if o.lateWindows[i].End.Add(o.window.AllowedLateness).After(watermark) {
+			break
+		}
+	}
+	if i > 0 {
+		o.lateWindows = o.lateWindows[i:]
+		_ = ctx.PutState(LateWindowsKey, o.lateWindows)
+	}
+}
+
+// handleLateEvent updates all the closed windows which contain the late event and emit them again with update rowkind.
+// If the event also belongs to the windows which are not triggered yet, insert it into the inputs in order.
+func (o *WindowOperator) handleLateEvent(ctx api.StreamContext, inputs []xsql.EventRow, d xsql.EventRow, prevWindowEnd time.Time) []xsql.EventRow {
+	ts := d.GetTimestamp()
+	ctx.GetLogger().Debugf("late event at %d with watermark %d", ts.UnixMilli(), o.lastWatermark.UnixMilli())
+	isPending := false
+	end := getAlignedWindowEndTime(ts.In(o.window.location()), o.window.RawInterval, o.window.TimeUnit)
+	// the aligned end may not be after the event at the boundary, find the first window end after it
+	for !end.After(ts) {
+		end = o.window.nextWindowEnd(end, o.trigger.interval)
+	}
+	for ; !o.window.windowStart(end).After(ts); end = o.window.nextWindowEnd(end, o.trigger.interval) {
+		if prevWindowEnd.IsZero() || end.After(prevWindowEnd) {
+			isPending = true
+			break
+		}
+		if !end.Add(o.window.AllowedLateness).After(o.lastWatermark) {
+			continue
+		}
+		o.updateLateWindow(ctx, o.window.windowStart(end), end, d)
+	}
+	if isPending {
+		index := sort.Search(len(inputs), func(i int) bool {
+			return inputs[i].GetTimestamp().After(ts)
+		})
+		inputs = append(inputs, nil)
+		copy(inputs[index+1:], inputs[index:])
+		inputs[index] = d
+	} else {
+		o.handleTraceDiscardTuple(ctx, []xsql.EventRow{d})
+	}
+	return inputs
+}
+
+func (o *WindowOperator) updateLateWindow(ctx api.StreamContext, start, end time.Time, d xsql.EventRow) {
+	var lw *LateWindow
+	for _, w := range o.lateWindows {
+		if w.End.Equal(end) {
+			lw = w
+			break
+		}
+	}
+	rowkind := ast.RowkindUpdate
+	// The window has no result before if there was no event when it closed
+	if lw == nil || len(lw.Content) == 0 {
+		rowkind = ast.RowkindInsert
+	}
+	if lw == nil {
+		lw = &LateWindow{Start: start, End: end}
+		index := sort.Search(len(o.lateWindows), func(i int) bool {
+			return o.lateWindows[i].End.After(end)
+		})
+		o.lateWindows = append(o.lateWindows, nil)
+		copy(o.lateWindows[index+1:], o.lateWindows[index:])
+		o.lateWindows[index] = lw
+	}
+	index := sort.Search(len(lw.Content), func(i int) bool {
+		return lw.Content[i].GetTimestamp().After(d.GetTimestamp())
+	})
+	content := make([]xsql.EventRow, 0, len(lw.Content)+1)
+	content = append(content, lw.Content[:index]...)
+	content = append(content, d)
+	lw.Content = append(content, lw.Content[index:]...)
+	_ = ctx.PutState(LateWindowsKey, o.lateWindows)
+
+	rowContent := make([]xsql.Row, len(lw.Content))
+	for i, tuple := range lw.Content {
+		rowContent[i] = tuple
+	}
+	results := &xsql.WindowTuples{
+		Content:     rowContent,
+		WindowRange: xsql.NewWindowRange(start.UnixMilli(), end.UnixMilli(), end.UnixMilli()).WithRowkind(rowkind),
+	}
+	o.handleTraceEmitTuple(ctx, results)
+	ctx.GetLogger().Debugf("window %s updated by late event: %v", o.name, results)
+	o.Broadcast(results)
+	o.onSend(ctx, results)
+}
+
 func getEarliestEventTs(inputs []xsql.EventRow, startTs time.Time, endTs time.Time) time.Time {
 	minTs := timex.Maxtime
 	for _, t := range inputs {
@@ -0,0 +1,243 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/infra"
+)
+
+const IntervalJoinKey = "$$intervalJoin"
+
+func init() {
+	gob.Register(&IntervalJoinState{})
+}
+
+// IntervalJoinRow is a buffered row of one side. Matched is used to emit the unmatched rows for outer join.
+type IntervalJoinRow struct {
+	Tuple   *xsql.Tuple
+	Matched bool
+}
+
+type IntervalJoinState struct {
+	Lefts  []*IntervalJoinRow
+	Rights []*IntervalJoinRow
+}
+
+// IntervalJoinOp joins two unbounded streams without window. A left row joins the right rows
+// whose timestamp is in [left timestamp + lower, left timestamp + upper]. The rows of both sides are buffered
+// until no row of the other side can join them, which is decided by the watermark. Thus, it only works in event time.
+type IntervalJoinOp struct {
+	*defaultSinkNode
+	left  string
+	right string
+	join  ast.Join
+	lower int64
+	upper int64
+	state *IntervalJoinState
+}
+
+func NewIntervalJoinOp(name string, from *ast.Table, join ast.Join, lower, upper int64, options *def.RuleOption) (*IntervalJoinOp, error) {
+	if lower > upper {
+		return nil, fmt.Errorf("invalid interval [%d, %d] for interval join", lower, upper)
+	}
+	if !options.IsEventTime {
+		return nil, errors.New("interval join requires event time")
+	}
+	switch join.JoinType {
+	case ast.INNER_JOIN, ast.LEFT_JOIN, ast.RIGHT_JOIN, ast.FULL_JOIN:
+	default:
+		return nil, fmt.Errorf("interval join does not support %s", join.JoinType)
+	}
+	return &IntervalJoinOp{
+		defaultSinkNode: newDefaultSinkNode(name, options),
+		left:            from.Name,
+		right:           join.Name,
+		join:            join,
+		lower:           lower,
+		upper:           upper,
+		state:           &IntervalJoinState{},
+	}, nil
+}
+
+func (o *IntervalJoinOp) Exec(ctx api.StreamContext, errCh chan<- error) {
+	o.prepareExec(ctx, errCh, "op")
+	log := ctx.GetLogger()
+	go func() {
+		defer func() {
+			o.Close()
+		}()
+		err := infra.SafeRun(func() error {
+			if s, err := ctx.GetState(IntervalJoinKey); err == nil {
+				switch st := s.(type) {
+				case *IntervalJoinState:
+					o.state = st
+					log.Infof("Restore interval join state %+v", st)
+				case nil:
+					log.Debugf("Restore interval join state, nothing")
+				default:
+					infra.DrainError(ctx, fmt.Errorf("restore interval join state %v error, invalid type", st), errCh)
+				}
+			} else {
+				log.Warnf("Restore interval join state fails: %s", err)
+			}
+			fv, _ := xsql.NewFunctionValuersForOp(ctx)
+			for {
+				select {
+				case <-ctx.Done():
+					log.Info("Cancelling interval join node....")
+					return nil
+				case item := <-o.input:
+					data, processed := o.preprocess(ctx, item)
+					if processed {
+						break
+					}
+					// evict before forwarding the watermark
+					if wt, ok := data.(*xsql.WatermarkTuple); ok {
+						o.evict(ctx, wt.GetTimestamp().UnixMilli())
+					}
+					data, processed = o.commonIngest(ctx, data)
+					if processed {
+						break
+					}
+					o.onProcessStart(ctx, data)
+					switch d := data.(type) {
+					case *xsql.Tuple:
+						if err := o.process(ctx, d, fv); err != nil {
+							o.onError(ctx, err)
+						}
+					default:
+						o.onError(ctx, fmt.Errorf("run interval join error: invalid input type but got %[1]T(%[1]v)", d))
+					}
+					o.onProcessEnd(ctx)
+					o.statManager.SetBufferLength(int64(len(o.input)))
+				}
+			}
+		})
+		if err != nil {
+			infra.DrainError(ctx, err, errCh)
+		}
+	}()
+}
+
+// process buffers the tuple and joins it with the buffered rows of the other side in the interval
+func (o *IntervalJoinOp) process(ctx api.StreamContext, d *xsql.Tuple, fv *xsql.FunctionValuer) error {
+	ts := d.Timestamp.UnixMilli()
+	row := &IntervalJoinRow{Tuple: d}
+	sets := &xsql.JoinTuples{Content: make([]*xsql.JoinTuple, 0)}
+	switch d.Emitter {
+	case o.left:
+		for _, r := range o.state.Rights {
+			rts := r.Tuple.Timestamp.UnixMilli()
+			if rts < ts+o.lower || rts > ts+o.upper {
+				continue
+			}
+			if err := o.joinRows(row, r, fv, sets); err != nil {
+				return err
+			}
+		}
+		o.state.Lefts = append(o.state.Lefts, row)
+	case o.right:
+		for _, l := range o.state.Lefts {
+			lts := l.Tuple.Timestamp.UnixMilli()
+			if lts < ts-o.upper || lts > ts-o.lower {
+				continue
+			}
+			if err := o.joinRows(l, row, fv, sets); err != nil {
+				return err
+			}
+		}
+		o.state.Rights = append(o.state.Rights, row)
+	default:
+		return fmt.Errorf("interval join receives tuple from unknown stream %s", d.Emitter)
+	}
+	_ = ctx.PutState(IntervalJoinKey, o.state)
+	o.emit(ctx, sets)
+	return nil
+}
+
+func (o *IntervalJoinOp) joinRows(l, r *IntervalJoinRow, fv *xsql.FunctionValuer, sets *xsql.JoinTuples) error {
+	merged := &xsql.JoinTuple{}
+	merged.AddTuple(l.Tuple)
+	merged.AddTuple(r.Tuple)
+	ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(merged, fv)}
+	switch val := ve.Eval(o.join.Expr).(type) {
+	case error:
+		return val
+	case bool:
+		if val {
+			l.Matched = true
+			r.Matched = true
+			sets.Content = append(sets.Content, merged)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid join condition that returns non-bool value %[1]T(%[1]v)", val)
+	}
+}
+
+// evict removes the rows which cannot be joined by any later row of the other side whose timestamp is no less than now.
+// The unmatched rows are emitted for outer joins.
+func (o *IntervalJoinOp) evict(ctx api.StreamContext, now int64) {
+	sets := &xsql.JoinTuples{Content: make([]*xsql.JoinTuple, 0)}
+	emitLeft := o.join.JoinType == ast.LEFT_JOIN || o.join.JoinType == ast.FULL_JOIN
+	emitRight := o.join.JoinType == ast.RIGHT_JOIN || o.join.JoinType == ast.FULL_JOIN
+	lefts := o.state.Lefts[:0]
+	for _, l := range o.state.Lefts {
+		if l.Tuple.Timestamp.UnixMilli()+o.upper < now {
+			if emitLeft && !l.Matched {
+				merged := &xsql.JoinTuple{}
+				merged.AddTuple(l.Tuple)
+				sets.Content = append(sets.Content, merged)
+			}
+			continue
+		}
+		lefts = append(lefts, l)
+	}
+	rights := o.state.Rights[:0]
+	for _, r := range o.state.Rights {
+		if r.Tuple.Timestamp.UnixMilli()-o.lower < now {
+			if emitRight && !r.Matched {
+				merged := &xsql.JoinTuple{}
+				merged.AddTuple(r.Tuple)
+				sets.Content = append(sets.Content, merged)
+			}
+			continue
+		}
+		rights = append(rights, r)
+	}
+	if len(lefts) == len(o.state.Lefts) && len(rights) == len(o.state.Rights) {
+		return
+	}
+	o.state.Lefts = lefts
+	o.state.Rights = rights
+	_ = ctx.PutState(IntervalJoinKey, o.state)
+	o.emit(ctx, sets)
+}
+
+func (o *IntervalJoinOp) emit(ctx api.StreamContext, sets *xsql.JoinTuples) {
+	if sets.Len() > 0 {
+		o.Broadcast(sets)
+		o.onSend(ctx, sets)
+	}
+}
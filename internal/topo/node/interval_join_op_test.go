@@ -0,0 +1,87 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestEventIntervalJoin(t *testing.T) {
+	stmt, err := xsql.NewParser(strings.NewReader("SELECT * FROM a LEFT JOIN b ON a.id = b.id AND b.ts BETWEEN a.ts - 5 AND a.ts + 10")).Parse()
+	require.NoError(t, err)
+	op, err := NewIntervalJoinOp("test", stmt.Sources[0].(*ast.Table), stmt.Joins[0], -5, 10, &def.RuleOption{BufferLength: 10, IsEventTime: true})
+	require.NoError(t, err)
+	out := make(chan any, 10)
+	require.NoError(t, op.AddOutput(out, "test"))
+	ctx := mockContext.NewMockContext("TestEventIntervalJoin", "test")
+	errCh := make(chan error)
+	op.Exec(ctx, errCh)
+
+	a1 := &xsql.Tuple{Emitter: "a", Message: map[string]any{"id": 1, "ts": 10}, Timestamp: time.UnixMilli(10)}
+	b1 := &xsql.Tuple{Emitter: "b", Message: map[string]any{"id": 1, "ts": 15}, Timestamp: time.UnixMilli(15)}
+	a2 := &xsql.Tuple{Emitter: "a", Message: map[string]any{"id": 2, "ts": 20}, Timestamp: time.UnixMilli(20)}
+	b2 := &xsql.Tuple{Emitter: "b", Message: map[string]any{"id": 1, "ts": 28}, Timestamp: time.UnixMilli(28)}
+	inputs := []any{a1, b1, a2, b2, &xsql.WatermarkTuple{Timestamp: time.UnixMilli(31)}}
+	expects := [][][]xsql.Row{
+		// b1 joins a1 in the interval
+		{{a1, b1}},
+		// b2 is out of the interval of a1, a2 is unmatched and expired by the watermark
+		{{a2}},
+	}
+	for _, in := range inputs {
+		op.input <- in
+	}
+	for _, exp := range expects {
+		select {
+		case r := <-out:
+			jt, ok := r.(*xsql.JoinTuples)
+			require.True(t, ok, "expect join tuples but got %v", r)
+			result := make([][]xsql.Row, 0, len(jt.Content))
+			for _, c := range jt.Content {
+				result = append(result, c.Tuples)
+			}
+			assert.Equal(t, exp, result)
+		case <-time.After(5 * time.Second):
+			t.Fatal("receive output timeout")
+		}
+	}
+	select {
+	case r := <-out:
+		assert.Equal(t, &xsql.WatermarkTuple{Timestamp: time.UnixMilli(31)}, r)
+	case <-time.After(5 * time.Second):
+		t.Fatal("receive watermark timeout")
+	}
+	assert.Len(t, op.state.Lefts, 0)
+	assert.Len(t, op.state.Rights, 1)
+}
+
+func TestNewIntervalJoinOpErr(t *testing.T) {
+	_, err := NewIntervalJoinOp("test", &ast.Table{Name: "a"}, ast.Join{Name: "b", JoinType: ast.INNER_JOIN}, 10, 5, &def.RuleOption{})
+	require.EqualError(t, err, "invalid interval [10, 5] for interval join")
+	_, err = NewIntervalJoinOp("test", &ast.Table{Name: "a"}, ast.Join{Name: "b", JoinType: ast.INNER_JOIN}, 0, 5, &def.RuleOption{})
+	require.EqualError(t, err, "interval join requires event time")
+	_, err = NewIntervalJoinOp("test", &ast.Table{Name: "a"}, ast.Join{Name: "b", JoinType: ast.CROSS_JOIN}, 0, 5, &def.RuleOption{IsEventTime: true})
+	require.EqualError(t, err, "interval join does not support CROSS_JOIN")
+}
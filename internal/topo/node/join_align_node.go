@@ -1,4 +1,4 @@
-// Copyright 2021-2024 EMQ Technologies Co., Ltd.
+// Copyright 2021-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -15,15 +15,24 @@
 package node
 
 import (
+	"encoding/gob"
 	"fmt"
+	"math"
+	"sort"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/infra"
 )
 
+func init() {
+	gob.Register(map[string]map[string][]*xsql.Tuple{})
+}
+
 // JoinAlignNode will block the stream and buffer all the table tuples. Once buffered, it will combine the later input with the buffer
 // The input for batch table MUST be *WindowTuples
 type JoinAlignNode struct {
@@ -31,14 +40,34 @@ type JoinAlignNode struct {
 	// table states
 	batch map[string][]*xsql.Tuple
 	size  map[string]int
+	// temporal table states, the versions of each key are sorted by timestamp
+	temporals map[string]*TemporalTable
+	versions  map[string]map[string][]*xsql.Tuple
 }
 
-const BatchKey = "$$batchInputs"
+// TemporalTable is a scan table joined by FOR SYSTEM_TIME AS OF. Each stream tuple joins the latest version of each key
+// whose timestamp is not later than the AsOf time of the tuple.
+type TemporalTable struct {
+	// Key is the key field to identify the versions of a row
+	Key string
+	// AsOf is evaluated against the stream tuple to get the version time
+	AsOf ast.Expr
+}
+
+const (
+	BatchKey    = "$$batchInputs"
+	VersionsKey = "$$temporalVersions"
+)
 
-func NewJoinAlignNode(name string, emitters []string, sizes []int, options *def.RuleOption) (*JoinAlignNode, error) {
+func NewJoinAlignNode(name string, emitters []string, sizes []int, temporals map[string]*TemporalTable, options *def.RuleOption) (*JoinAlignNode, error) {
 	batch := make(map[string][]*xsql.Tuple, len(emitters))
 	size := make(map[string]int, len(emitters))
+	versions := make(map[string]map[string][]*xsql.Tuple, len(temporals))
 	for i, e := range emitters {
+		if _, ok := temporals[e]; ok {
+			versions[e] = make(map[string][]*xsql.Tuple)
+			continue
+		}
 		s := sizes[i]
 		if s >= 9999 {
 			s = 100
@@ -47,8 +76,10 @@ func NewJoinAlignNode(name string, emitters []string, sizes []int, options *def.
 		size[e] = sizes[i]
 	}
 	n := &JoinAlignNode{
-		batch: batch,
-		size:  size,
+		batch:     batch,
+		size:      size,
+		temporals: temporals,
+		versions:  versions,
 	}
 	n.defaultSinkNode = newDefaultSinkNode(name, options)
 	return n, nil
@@ -79,12 +110,32 @@ func (n *JoinAlignNode) Exec(ctx api.StreamContext, errCh chan<- error) {
 			if n.batch == nil {
 				n.batch = make(map[string][]*xsql.Tuple)
 			}
+			if len(n.temporals) > 0 {
+				if s, err := ctx.GetState(VersionsKey); err == nil {
+					switch st := s.(type) {
+					case map[string]map[string][]*xsql.Tuple:
+						n.versions = st
+						log.Infof("Restore temporal versions state %+v", st)
+					case nil:
+						log.Debugf("Restore temporal versions state, nothing")
+					default:
+						infra.DrainError(ctx, fmt.Errorf("restore temporal versions state %v error, invalid type", st), errCh)
+					}
+				} else {
+					log.Warnf("Restore temporal versions state fails: %s", err)
+				}
+			}
+			fv, _ := xsql.NewFunctionValuersForOp(ctx)
 
 			for {
 				log.Debugf("JoinAlignNode %s is looping", n.name)
 				select {
 				// process incoming item from both streams(transformed) and tables
 				case item := <-n.input:
+					// expire the versions before forwarding the watermark
+					if wt, ok := item.(*xsql.WatermarkTuple); ok {
+						n.expire(ctx, wt.GetTimestamp().UnixMilli())
+					}
 					data, processed := n.commonIngest(ctx, item)
 					if processed {
 						break
@@ -93,7 +144,13 @@ func (n *JoinAlignNode) Exec(ctx api.StreamContext, errCh chan<- error) {
 					switch d := data.(type) {
 					case *xsql.Tuple:
 						log.Debugf("JoinAlignNode receive tuple input %v", d)
-						if b, ok := n.batch[d.Emitter]; ok {
+						if tt, ok := n.temporals[d.Emitter]; ok {
+							if err := n.addVersion(d, tt); err != nil {
+								n.onError(ctx, err)
+							} else {
+								_ = ctx.PutState(VersionsKey, n.versions)
+							}
+						} else if b, ok := n.batch[d.Emitter]; ok {
 							s := n.size[d.Emitter]
 							if len(b) >= s {
 								b = b[s-len(b)+1:]
@@ -101,12 +158,19 @@ func (n *JoinAlignNode) Exec(ctx api.StreamContext, errCh chan<- error) {
 							b = append(b, d)
 							n.batch[d.Emitter] = b
 							_ = ctx.PutState(BatchKey, n.batch)
-						} else {
-							n.alignBatch(ctx, d)
+						} else if err := n.alignBatch(ctx, d, fv); err != nil {
+							n.onError(ctx, err)
 						}
 					case *xsql.WindowTuples:
 						log.Debugf("JoinAlignNode receive window input %v", d)
-						n.alignBatch(ctx, d)
+						if err := n.alignBatch(ctx, d, fv); err != nil {
+							n.onError(ctx, err)
+						}
+						// windows are emitted in order, so the window end is the progress of the event time
+						if len(n.temporals) > 0 && d.WindowRange != nil {
+							end, _ := d.WindowRange.FuncValue("window_end")
+							n.expire(ctx, end.(int64))
+						}
 					default:
 						n.onError(ctx, fmt.Errorf("run JoinAlignNode error: invalid input type but got %[1]T(%[1]v)", d))
 					}
@@ -123,7 +187,7 @@ func (n *JoinAlignNode) Exec(ctx api.StreamContext, errCh chan<- error) {
 	}()
 }
 
-func (n *JoinAlignNode) alignBatch(ctx api.StreamContext, input any) {
+func (n *JoinAlignNode) alignBatch(ctx api.StreamContext, input any, fv *xsql.FunctionValuer) error {
 	var w *xsql.WindowTuples
 	switch t := input.(type) {
 	case *xsql.Tuple:
@@ -131,8 +195,26 @@ func (n *JoinAlignNode) alignBatch(ctx api.StreamContext, input any) {
 			Content: make([]xsql.Row, 0),
 		}
 		w.AddTuple(t)
+		for e, tt := range n.temporals {
+			asOf, err := evalAsOf(t, tt.AsOf, fv)
+			if err != nil {
+				return err
+			}
+			n.addVersionsAsOf(w, e, asOf)
+		}
 	case *xsql.WindowTuples:
 		w = t
+		if len(n.temporals) > 0 {
+			// a window joins the versions valid at the window end
+			var asOf int64 = math.MaxInt64
+			if t.WindowRange != nil {
+				end, _ := t.WindowRange.FuncValue("window_end")
+				asOf = end.(int64)
+			}
+			for e := range n.temporals {
+				n.addVersionsAsOf(w, e, asOf)
+			}
+		}
 	}
 	for _, contents := range n.batch {
 		for _, v := range contents {
@@ -142,4 +224,67 @@ func (n *JoinAlignNode) alignBatch(ctx api.StreamContext, input any) {
 	n.Broadcast(w)
 	n.onSend(ctx, w)
 	n.statManager.SetBufferLength(int64(len(n.input)))
+	return nil
+}
+
+func evalAsOf(t *xsql.Tuple, expr ast.Expr, fv *xsql.FunctionValuer) (int64, error) {
+	ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(t, fv)}
+	v := ve.Eval(expr)
+	if err, ok := v.(error); ok {
+		return 0, err
+	}
+	ts, err := cast.InterfaceToUnixMilli(v, "")
+	if err != nil {
+		return 0, fmt.Errorf("invalid FOR SYSTEM_TIME AS OF value: %v", err)
+	}
+	return ts, nil
+}
+
+// addVersion inserts the table tuple into the version history of its key by timestamp
+func (n *JoinAlignNode) addVersion(t *xsql.Tuple, tt *TemporalTable) error {
+	k, ok := t.Value(tt.Key, "")
+	if !ok {
+		return fmt.Errorf("temporal table %s misses the key field %s", t.Emitter, tt.Key)
+	}
+	key := fmt.Sprintf("%v", k)
+	vs := n.versions[t.Emitter][key]
+	i := sort.Search(len(vs), func(i int) bool {
+		return vs[i].Timestamp.After(t.Timestamp)
+	})
+	vs = append(vs, nil)
+	copy(vs[i+1:], vs[i:])
+	vs[i] = t
+	n.versions[t.Emitter][key] = vs
+	return nil
+}
+
+// addVersionsAsOf adds the latest version of each key whose timestamp is not later than asOf
+func (n *JoinAlignNode) addVersionsAsOf(w *xsql.WindowTuples, emitter string, asOf int64) {
+	for _, vs := range n.versions[emitter] {
+		i := sort.Search(len(vs), func(i int) bool {
+			return vs[i].Timestamp.UnixMilli() > asOf
+		})
+		if i > 0 {
+			w.AddTuple(vs[i-1])
+		}
+	}
+}
+
+// expire drops the versions which are superseded before the watermark because later stream tuples cannot join them
+func (n *JoinAlignNode) expire(ctx api.StreamContext, watermark int64) {
+	changed := false
+	for _, versions := range n.versions {
+		for key, vs := range versions {
+			i := sort.Search(len(vs), func(i int) bool {
+				return vs[i].Timestamp.UnixMilli() > watermark
+			})
+			if i > 1 {
+				versions[key] = vs[i-1:]
+				changed = true
+			}
+		}
+	}
+	if changed {
+		_ = ctx.PutState(VersionsKey, n.versions)
+	}
 }
@@ -1,4 +1,4 @@
-// Copyright 2024-2025 EMQ Technologies Co., Ltd.
+// Copyright 2024-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -18,11 +18,14 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
 )
 
@@ -186,7 +189,7 @@ func TestAlignTable(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			n, e := NewJoinAlignNode("align", []string{"table1", "table2"}, []int{2, 9999}, &def.RuleOption{
+			n, e := NewJoinAlignNode("align", []string{"table1", "table2"}, []int{2, 9999}, nil, &def.RuleOption{
 				SendError: true,
 			})
 			assert.NoError(t, e)
@@ -215,3 +218,52 @@ func TestAlignTable(t *testing.T) {
 		})
 	}
 }
+
+func TestTemporalAlignTable(t *testing.T) {
+	asOf := &ast.FieldRef{StreamName: "stream1", Name: "ts"}
+	n, err := NewJoinAlignNode("align", []string{"table1"}, []int{1}, map[string]*TemporalTable{
+		"table1": {Key: "id", AsOf: asOf},
+	}, &def.RuleOption{SendError: true})
+	require.NoError(t, err)
+	out := make(chan any, 100)
+	require.NoError(t, n.AddOutput(out, "test"))
+	ctx := mockContext.NewMockContext("TestTemporalAlignTable", "test")
+	errCh := make(chan error)
+	n.Exec(ctx, errCh)
+	defer n.Close()
+
+	v1 := &xsql.Tuple{Emitter: "table1", Message: map[string]any{"id": 1, "v": "v1"}, Timestamp: time.UnixMilli(10)}
+	v3 := &xsql.Tuple{Emitter: "table1", Message: map[string]any{"id": 1, "v": "v3"}, Timestamp: time.UnixMilli(30)}
+	v2 := &xsql.Tuple{Emitter: "table1", Message: map[string]any{"id": 1, "v": "v2"}, Timestamp: time.UnixMilli(20)}
+	s1 := &xsql.Tuple{Emitter: "stream1", Message: map[string]any{"id": 1, "ts": int64(25)}, Timestamp: time.UnixMilli(25)}
+	s2 := &xsql.Tuple{Emitter: "stream1", Message: map[string]any{"id": 1, "ts": int64(5)}, Timestamp: time.UnixMilli(5)}
+	s3 := &xsql.Tuple{Emitter: "stream1", Message: map[string]any{"id": 1, "ts": int64(40)}, Timestamp: time.UnixMilli(40)}
+	inputs := []any{
+		v1, v3, v2, s1, s2,
+		&xsql.Tuple{Emitter: "table1", Message: map[string]any{"v": "nokey"}},
+		&xsql.WatermarkTuple{Timestamp: time.UnixMilli(35)},
+		s3,
+	}
+	expects := []any{
+		// the out of order version v2 is valid at 25
+		&xsql.WindowTuples{Content: []xsql.Row{s1, v2}},
+		// no version is valid at 5
+		&xsql.WindowTuples{Content: []xsql.Row{s2}},
+		errors.New("temporal table table1 misses the key field id"),
+		&xsql.WatermarkTuple{Timestamp: time.UnixMilli(35)},
+		&xsql.WindowTuples{Content: []xsql.Row{s3, v3}},
+	}
+	for _, in := range inputs {
+		n.input <- in
+	}
+	for _, exp := range expects {
+		select {
+		case r := <-out:
+			assert.Equal(t, exp, r)
+		case <-time.After(5 * time.Second):
+			t.Fatal("receive output timeout")
+		}
+	}
+	// the versions superseded before the watermark are expired
+	assert.Equal(t, []*xsql.Tuple{v3}, n.versions["table1"]["1"])
+}
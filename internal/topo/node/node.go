@@ -1,4 +1,4 @@
-// Copyright 2021-2025 EMQ Technologies Co., Ltd.
+// Copyright 2021-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -51,6 +51,8 @@ type defaultNode struct {
 	spanCtx                  api.StreamContext
 	disableBufferFullDiscard bool
 	isStatManagerHostBySink  bool
+	// deadLetter is the side output of the rule to send the unprocessable data, nil if not configured
+	deadLetter *DeadLetterOp
 }
 
 func newDefaultNode(name string, options *def.RuleOption) *defaultNode {
@@ -364,6 +366,18 @@ func SourcePing(sourceType string, config map[string]any) error {
 	return fmt.Errorf("source %v doesn't support ping connection", sourceType)
 }
 
+// SourceBrowse browses the data of the source server such as the address space of an OPC UA server
+func SourceBrowse(sourceType string, config map[string]any) (any, error) {
+	source, err := io.Source(sourceType)
+	if err != nil {
+		return nil, err
+	}
+	if browsable, ok := source.(util.Browsable); ok {
+		return browsable.Browse(context.Background(), config)
+	}
+	return nil, fmt.Errorf("source %v doesn't support browse", sourceType)
+}
+
 func SinkPing(sinkType string, config map[string]any) error {
 	sink, err := io.Sink(sinkType)
 	if err != nil {
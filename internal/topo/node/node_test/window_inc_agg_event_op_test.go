@@ -637,3 +637,68 @@ func TestIncEventCountWindowState(t *testing.T) {
 	op.Close()
 	op2.Close()
 }
+
+func TestIncEventCumulateWindow(t *testing.T) {
+	conf.IsTesting = true
+	o := &def.RuleOption{
+		PlanOptimizeStrategy: &def.PlanOptimizeStrategy{
+			EnableIncrementalWindow: true,
+		},
+		IsEventTime:  true,
+		Qos:          0,
+		BufferLength: 10,
+	}
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+	sql := "select count(*) from stream group by cumulateWindow(ss,3,1)"
+	stmt, err := xsql.NewParser(strings.NewReader(sql)).Parse()
+	require.NoError(t, err)
+	p, err := planner.CreateLogicalPlan(stmt, &def.RuleOption{
+		PlanOptimizeStrategy: &def.PlanOptimizeStrategy{
+			EnableIncrementalWindow: true,
+		},
+		Qos: 0,
+	}, kv)
+	require.NoError(t, err)
+	require.NotNil(t, p)
+	incPlan := extractIncWindowPlan(p)
+	require.NotNil(t, incPlan)
+	op, err := node.NewWindowIncAggOp("1", &node.WindowConfig{
+		Type:        incPlan.WType,
+		Length:      3 * time.Second,
+		Interval:    time.Second,
+		RawInterval: 1,
+		RawLength:   3,
+		TimeUnit:    ast.SS,
+	}, incPlan.Dimensions, incPlan.IncAggFuncs, o)
+	require.NoError(t, err)
+	require.NotNil(t, op)
+	input, _ := op.GetInput()
+	output := make(chan any, 10)
+	op.AddOutput(output, "output")
+	errCh := make(chan error, 10)
+	ctx, cancel := mockContext.NewMockContext("1", "2").WithCancel()
+	op.Exec(ctx, errCh)
+	waitExecute()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(1)}, Timestamp: now.Add(500 * time.Millisecond)}
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(2)}, Timestamp: now.Add(1500 * time.Millisecond)}
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(3)}, Timestamp: now.Add(3500 * time.Millisecond)}
+	input <- &xsql.WatermarkTuple{Timestamp: now.Add(4 * time.Second)}
+	expected := [][]map[string]any{
+		{{"a": int64(1), "inc_agg_col_1": int64(1)}},
+		{{"a": int64(2), "inc_agg_col_1": int64(2)}},
+		{{"a": int64(2), "inc_agg_col_1": int64(2)}},
+		{{"a": int64(3), "inc_agg_col_1": int64(1)}},
+	}
+	for _, e := range expected {
+		got := <-output
+		wt, ok := got.(*xsql.WindowTuples)
+		require.True(t, ok)
+		require.Equal(t, e, wt.ToMaps())
+	}
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	op.Close()
+}
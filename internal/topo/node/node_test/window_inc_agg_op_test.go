@@ -680,3 +680,84 @@ func prepareStream() error {
 	}
 	return nil
 }
+
+func TestIncAggCumulateWindow(t *testing.T) {
+	conf.IsTesting = true
+	node.EnableAlignWindow = true
+	// align the mock time to minute so that the window start is predictable
+	now := timex.GetNow().Truncate(time.Minute).Add(time.Minute)
+	timex.SetNow(now)
+	o := &def.RuleOption{
+		BufferLength: 10,
+	}
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+	sql := "select count(*) from stream group by cumulateWindow(ss,3,1)"
+	stmt, err := xsql.NewParser(strings.NewReader(sql)).Parse()
+	require.NoError(t, err)
+	p, err := planner.CreateLogicalPlan(stmt, &def.RuleOption{
+		PlanOptimizeStrategy: &def.PlanOptimizeStrategy{
+			EnableIncrementalWindow: true,
+		},
+		Qos: 0,
+	}, kv)
+	require.NoError(t, err)
+	require.NotNil(t, p)
+	incPlan := extractIncWindowPlan(p)
+	require.NotNil(t, incPlan)
+	op, err := node.NewWindowIncAggOp("1", &node.WindowConfig{
+		Type:        incPlan.WType,
+		Length:      3 * time.Second,
+		Interval:    time.Second,
+		RawInterval: 1,
+		RawLength:   3,
+		TimeUnit:    ast.SS,
+	}, incPlan.Dimensions, incPlan.IncAggFuncs, o)
+	require.NoError(t, err)
+	require.NotNil(t, op)
+	input, _ := op.GetInput()
+	output := make(chan any, 10)
+	op.AddOutput(output, "output")
+	errCh := make(chan error, 10)
+	ctx, cancel := mockContext.NewMockContext("1", "2").WithCancel()
+	op.Exec(ctx, errCh)
+	waitExecute()
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(1)}}
+	waitExecute()
+	timex.Add(time.Second)
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(2)}}
+	waitExecute()
+	timex.Add(time.Second)
+	waitExecute()
+	timex.Add(time.Second)
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(3)}}
+	waitExecute()
+	timex.Add(time.Second)
+	waitExecute()
+	expected := [][]map[string]any{
+		{{"a": int64(1), "inc_agg_col_1": int64(1)}},
+		{{"a": int64(2), "inc_agg_col_1": int64(2)}},
+		{{"a": int64(2), "inc_agg_col_1": int64(2)}},
+		{{"a": int64(3), "inc_agg_col_1": int64(1)}},
+	}
+	for _, e := range expected {
+		got := <-output
+		wt, ok := got.(*xsql.WindowTuples)
+		require.True(t, ok)
+		require.Equal(t, e, wt.ToMaps())
+	}
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	op.Close()
+}
+
+func TestIncAggCalendarWindowErr(t *testing.T) {
+	_, err := node.NewWindowIncAggOp("1", &node.WindowConfig{
+		Type:        ast.TUMBLING_WINDOW,
+		RawInterval: 1,
+		RawLength:   1,
+		TimeUnit:    ast.MM,
+	}, nil, nil, &def.RuleOption{BufferLength: 10})
+	require.EqualError(t, err, "incremental TUMBLING_WINDOW does not support the calendar unit MM")
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/lf-edge/ekuiper/v2/internal/topo/node"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/planner"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
 	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
@@ -41,7 +43,9 @@ func TestSlidingWindowSendTwice(t *testing.T) {
 	require.NotNil(t, p)
 	windowPlan := extractWindowPlan(p)
 	require.NotNil(t, windowPlan)
-	op, err := node.NewWindowOp("1", *windowPlan.GenWindowConfig(), o)
+	wc, err := windowPlan.GenWindowConfig()
+	require.NoError(t, err)
+	op, err := node.NewWindowOp("1", *wc, o)
 	require.NoError(t, err)
 	require.NotNil(t, op)
 	input, _ := op.GetInput()
@@ -96,3 +100,66 @@ func extractWindowPlan(cur planner.LogicalPlan) *planner.WindowPlan {
 	}
 	return nil
 }
+
+func TestEventTumblingWindowAllowedLateness(t *testing.T) {
+	conf.IsTesting = true
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	o := &def.RuleOption{
+		BufferLength:    10,
+		IsEventTime:     true,
+		AllowedLateness: cast.DurationConf(2 * time.Second),
+	}
+	op, err := node.NewWindowOp("window", node.WindowConfig{
+		Type:            ast.TUMBLING_WINDOW,
+		Length:          2 * time.Second,
+		RawInterval:     2,
+		RawLength:       2,
+		TimeUnit:        ast.SS,
+		Location:        time.UTC,
+		AllowedLateness: 2 * time.Second,
+	}, o)
+	require.NoError(t, err)
+	input, _ := op.GetInput()
+	output := make(chan any, 10)
+	op.AddOutput(output, "output")
+	errCh := make(chan error, 10)
+	ctx, cancel := mockContext.NewMockContext("1", "2").WithCancel()
+	op.Exec(ctx, errCh)
+	waitExecute()
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(1)}, Timestamp: now.Add(500 * time.Millisecond)}
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(2)}, Timestamp: now.Add(1500 * time.Millisecond)}
+	input <- &xsql.WatermarkTuple{Timestamp: now.Add(2 * time.Second)}
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(3)}, Timestamp: now.Add(2500 * time.Millisecond)}
+	input <- &xsql.WatermarkTuple{Timestamp: now.Add(3 * time.Second)}
+	// Late for the closed window
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(4)}, Timestamp: now.Add(time.Second)}
+	// Late for the open window
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(5)}, Timestamp: now.Add(2200 * time.Millisecond)}
+	input <- &xsql.WatermarkTuple{Timestamp: now.Add(4 * time.Second)}
+	// Beyond the allowed lateness
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(6)}, Timestamp: now.Add(1200 * time.Millisecond)}
+	input <- &xsql.WatermarkTuple{Timestamp: now.Add(6 * time.Second)}
+	expected := []struct {
+		end     time.Time
+		rowkind string
+		rows    []map[string]any
+	}{
+		{end: now.Add(2 * time.Second), rowkind: ast.RowkindInsert, rows: []map[string]any{{"a": int64(1)}, {"a": int64(2)}}},
+		{end: now.Add(2 * time.Second), rowkind: ast.RowkindUpdate, rows: []map[string]any{{"a": int64(1)}, {"a": int64(4)}, {"a": int64(2)}}},
+		{end: now.Add(4 * time.Second), rowkind: ast.RowkindInsert, rows: []map[string]any{{"a": int64(5)}, {"a": int64(3)}}},
+		{end: now.Add(6 * time.Second), rowkind: ast.RowkindInsert, rows: []map[string]any{}},
+	}
+	for _, e := range expected {
+		got := <-output
+		wt, ok := got.(*xsql.WindowTuples)
+		require.True(t, ok)
+		require.Equal(t, e.rows, wt.ToMaps())
+		end, _ := wt.WindowRange.FuncValue("window_end")
+		require.Equal(t, e.end.UnixMilli(), end)
+		rowkind, _ := wt.WindowRange.FuncValue("window_rowkind")
+		require.Equal(t, e.rowkind, rowkind)
+	}
+	cancel()
+	waitExecute()
+	op.Close()
+}
@@ -15,6 +15,8 @@
 package node_test
 
 import (
+	"bytes"
+	"encoding/gob"
 	"strings"
 	"testing"
 	"time"
@@ -198,3 +200,197 @@ func TestEventSlidingWindowV2(t *testing.T) {
 	waitExecute()
 	op.Close()
 }
+
+func TestEventCumulateWindowV2(t *testing.T) {
+	conf.IsTesting = true
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	o := &def.RuleOption{
+		BufferLength: 10,
+		IsEventTime:  true,
+	}
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+	sql := "select count(*) from eventStream group by cumulateWindow(ss,3,1)"
+	stmt, err := xsql.NewParser(strings.NewReader(sql)).Parse()
+	require.NoError(t, err)
+	p, err := planner.CreateLogicalPlan(stmt, o, kv)
+	require.NoError(t, err)
+	require.NotNil(t, p)
+	windowPlan := extractWindowPlan(p)
+	require.NotNil(t, windowPlan)
+	op, err := node.NewWindowV2Op("window", node.WindowConfig{
+		Type:        windowPlan.WindowType(),
+		Length:      3 * time.Second,
+		Interval:    time.Second,
+		RawInterval: 1,
+		RawLength:   3,
+		TimeUnit:    ast.SS,
+	}, o)
+	require.NoError(t, err)
+	require.NotNil(t, op)
+	input, _ := op.GetInput()
+	output := make(chan any, 10)
+	op.AddOutput(output, "output")
+	errCh := make(chan error, 10)
+	ctx, cancel := mockContext.NewMockContext("1", "2").WithCancel()
+	op.Exec(ctx, errCh)
+	waitExecute()
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(1)}, Timestamp: now.Add(500 * time.Millisecond)}
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(2)}, Timestamp: now.Add(1500 * time.Millisecond)}
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(3)}, Timestamp: now.Add(3500 * time.Millisecond)}
+	input <- &xsql.WatermarkTuple{Timestamp: now.Add(4 * time.Second)}
+	expected := []struct {
+		end  time.Time
+		rows []map[string]any
+	}{
+		{end: now.Add(time.Second), rows: []map[string]any{{"a": int64(1)}}},
+		{end: now.Add(2 * time.Second), rows: []map[string]any{{"a": int64(1)}, {"a": int64(2)}}},
+		{end: now.Add(3 * time.Second), rows: []map[string]any{{"a": int64(1)}, {"a": int64(2)}}},
+		{end: now.Add(4 * time.Second), rows: []map[string]any{{"a": int64(3)}}},
+	}
+	for _, e := range expected {
+		got := <-output
+		wt, ok := got.(*xsql.WindowTuples)
+		require.True(t, ok)
+		require.Equal(t, e.rows, wt.ToMaps())
+		end, _ := wt.WindowRange.FuncValue("window_end")
+		require.Equal(t, e.end.UnixMilli(), end)
+	}
+	cancel()
+	waitExecute()
+	op.Close()
+}
+
+// The day of DST change has 23 hours, so the steps follow the calendar instead of the fixed length
+func TestEventCumulateWindowV2DST(t *testing.T) {
+	conf.IsTesting = true
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	o := &def.RuleOption{
+		BufferLength: 10,
+		IsEventTime:  true,
+	}
+	op, err := node.NewWindowV2Op("window", node.WindowConfig{
+		Type:        ast.CUMULATE_WINDOW,
+		Length:      48 * time.Hour,
+		Interval:    24 * time.Hour,
+		RawInterval: 1,
+		RawLength:   2,
+		TimeUnit:    ast.DD,
+		Location:    newYork,
+	}, o)
+	require.NoError(t, err)
+	input, _ := op.GetInput()
+	output := make(chan any, 10)
+	op.AddOutput(output, "output")
+	errCh := make(chan error, 10)
+	ctx, cancel := mockContext.NewMockContext("1", "2").WithCancel()
+	op.Exec(ctx, errCh)
+	waitExecute()
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(1)}, Timestamp: time.Date(2024, 3, 9, 10, 0, 0, 0, newYork)}
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(2)}, Timestamp: time.Date(2024, 3, 10, 10, 0, 0, 0, newYork)}
+	input <- &xsql.WatermarkTuple{Timestamp: time.Date(2024, 3, 11, 0, 0, 0, 0, newYork)}
+	expected := []struct {
+		end  time.Time
+		rows []map[string]any
+	}{
+		{end: time.Date(2024, 3, 10, 0, 0, 0, 0, newYork), rows: []map[string]any{{"a": int64(1)}}},
+		{end: time.Date(2024, 3, 11, 0, 0, 0, 0, newYork), rows: []map[string]any{{"a": int64(1)}, {"a": int64(2)}}},
+	}
+	for _, e := range expected {
+		select {
+		case got := <-output:
+			wt, ok := got.(*xsql.WindowTuples)
+			require.True(t, ok)
+			require.Equal(t, e.rows, wt.ToMaps())
+			end, _ := wt.WindowRange.FuncValue("window_end")
+			require.Equal(t, e.end.UnixMilli(), end)
+		case <-time.After(time.Second):
+			require.Fail(t, "timeout")
+		}
+	}
+	cancel()
+	waitExecute()
+	op.Close()
+}
+
+func TestEventCumulateWindowV2Restore(t *testing.T) {
+	conf.IsTesting = true
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	o := &def.RuleOption{
+		BufferLength: 10,
+		IsEventTime:  true,
+	}
+	wc := node.WindowConfig{
+		Type:        ast.CUMULATE_WINDOW,
+		Length:      3 * time.Second,
+		Interval:    time.Second,
+		RawInterval: 1,
+		RawLength:   3,
+		TimeUnit:    ast.SS,
+	}
+	checkWindow := func(output chan any, end time.Time, rows []map[string]any) {
+		var got any
+		select {
+		case got = <-output:
+		case <-time.After(time.Second):
+			require.Fail(t, "window is not emitted", "window end %v", end)
+		}
+		wt, ok := got.(*xsql.WindowTuples)
+		require.True(t, ok)
+		require.Equal(t, rows, wt.ToMaps())
+		e, _ := wt.WindowRange.FuncValue("window_end")
+		require.Equal(t, end.UnixMilli(), e)
+	}
+
+	op, err := node.NewWindowV2Op("window", wc, o)
+	require.NoError(t, err)
+	input, _ := op.GetInput()
+	output := make(chan any, 10)
+	op.AddOutput(output, "output")
+	errCh := make(chan error, 10)
+	ctx, cancel := mockContext.NewMockContext("1", "2").WithCancel()
+	op.Exec(ctx, errCh)
+	waitExecute()
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(1)}, Timestamp: now.Add(500 * time.Millisecond)}
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(2)}, Timestamp: now.Add(1500 * time.Millisecond)}
+	input <- &xsql.WatermarkTuple{Timestamp: now.Add(time.Second)}
+	checkWindow(output, now.Add(time.Second), []map[string]any{{"a": int64(1)}})
+	waitExecute()
+	cancel()
+	waitExecute()
+	op.Close()
+
+	// restore from the encoded state like a checkpoint
+	st, err := ctx.GetState(node.V2WindowInputsKey)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(&st))
+	var restored any
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&restored))
+	ctx2, cancel2 := mockContext.NewMockContext("2", "2").WithCancel()
+	require.NoError(t, ctx2.PutState(node.V2WindowInputsKey, restored))
+
+	op2, err := node.NewWindowV2Op("window", wc, o)
+	require.NoError(t, err)
+	input2, _ := op2.GetInput()
+	output2 := make(chan any, 10)
+	op2.AddOutput(output2, "output")
+	op2.Exec(ctx2, errCh)
+	waitExecute()
+	input2 <- &xsql.WatermarkTuple{Timestamp: now.Add(2 * time.Second)}
+	checkWindow(output2, now.Add(2*time.Second), []map[string]any{{"a": int64(1)}, {"a": int64(2)}})
+	input2 <- &xsql.Tuple{Message: map[string]any{"a": int64(3)}, Timestamp: now.Add(3500 * time.Millisecond)}
+	input2 <- &xsql.WatermarkTuple{Timestamp: now.Add(4 * time.Second)}
+	checkWindow(output2, now.Add(3*time.Second), []map[string]any{{"a": int64(1)}, {"a": int64(2)}})
+	checkWindow(output2, now.Add(4*time.Second), []map[string]any{{"a": int64(3)}})
+	select {
+	case err := <-errCh:
+		require.NoError(t, err)
+	default:
+	}
+	cancel2()
+	waitExecute()
+	op2.Close()
+}
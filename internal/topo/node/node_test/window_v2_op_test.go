@@ -435,3 +435,133 @@ func TestWindowV2SlidingWindow(t *testing.T) {
 	waitExecute()
 	op.Close()
 }
+
+func TestWindowV2CumulateWindow(t *testing.T) {
+	conf.IsTesting = true
+	// align the mock time to minute so that the window start is predictable
+	now := timex.GetNow().Truncate(time.Minute).Add(time.Minute)
+	timex.SetNow(now)
+	o := &def.RuleOption{
+		BufferLength: 10,
+	}
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+	sql := "select count(*) from stream group by cumulateWindow(ss,3,1)"
+	stmt, err := xsql.NewParser(strings.NewReader(sql)).Parse()
+	require.NoError(t, err)
+	p, err := planner.CreateLogicalPlan(stmt, o, kv)
+	require.NoError(t, err)
+	require.NotNil(t, p)
+	windowPlan := extractWindowPlan(p)
+	require.NotNil(t, windowPlan)
+	op, err := node.NewWindowV2Op("window", node.WindowConfig{
+		Type:        windowPlan.WindowType(),
+		Length:      3 * time.Second,
+		Interval:    time.Second,
+		RawInterval: 1,
+		RawLength:   3,
+		TimeUnit:    ast.SS,
+	}, o)
+	require.NoError(t, err)
+	require.NotNil(t, op)
+	input, _ := op.GetInput()
+	output := make(chan any, 10)
+	op.AddOutput(output, "output")
+	errCh := make(chan error, 10)
+	ctx, cancel := mockContext.NewMockContext("1", "2").WithCancel()
+	op.Exec(ctx, errCh)
+	waitExecute()
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(1)}, Timestamp: now}
+	waitExecute()
+	timex.Add(time.Second)
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(2)}, Timestamp: now.Add(time.Second)}
+	waitExecute()
+	timex.Add(time.Second)
+	waitExecute()
+	timex.Add(time.Second)
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(3)}, Timestamp: now.Add(3 * time.Second)}
+	waitExecute()
+	timex.Add(time.Second)
+	waitExecute()
+	expected := [][]map[string]any{
+		{{"a": int64(1)}},
+		{{"a": int64(1)}, {"a": int64(2)}},
+		{{"a": int64(1)}, {"a": int64(2)}},
+		{{"a": int64(3)}},
+	}
+	for _, e := range expected {
+		got := <-output
+		wt, ok := got.(*xsql.WindowTuples)
+		require.True(t, ok)
+		require.Equal(t, e, wt.ToMaps())
+	}
+	cancel()
+	waitExecute()
+	op.Close()
+}
+
+func TestWindowV2CumulateWindowCalendar(t *testing.T) {
+	conf.IsTesting = true
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	// The day of 2024-03-10 has 23 hours in New York because of DST
+	now := time.Date(2024, 3, 9, 12, 0, 0, 0, newYork)
+	timex.SetNow(now)
+	o := &def.RuleOption{
+		BufferLength: 10,
+	}
+	op, err := node.NewWindowV2Op("window", node.WindowConfig{
+		Type:        ast.CUMULATE_WINDOW,
+		Length:      48 * time.Hour,
+		Interval:    24 * time.Hour,
+		RawInterval: 1,
+		RawLength:   2,
+		TimeUnit:    ast.DD,
+		Location:    newYork,
+	}, o)
+	require.NoError(t, err)
+	input, _ := op.GetInput()
+	output := make(chan any, 10)
+	op.AddOutput(output, "output")
+	errCh := make(chan error, 10)
+	ctx, cancel := mockContext.NewMockContext("1", "2").WithCancel()
+	op.Exec(ctx, errCh)
+	waitExecute()
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(1)}, Timestamp: now}
+	waitExecute()
+	timex.Add(12 * time.Hour)
+	waitExecute()
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(2)}, Timestamp: now.Add(24 * time.Hour)}
+	waitExecute()
+	timex.Add(23 * time.Hour)
+	waitExecute()
+	expected := []struct {
+		rows       []map[string]any
+		start, end time.Time
+	}{
+		{
+			rows:  []map[string]any{{"a": int64(1)}},
+			start: time.Date(2024, 3, 9, 0, 0, 0, 0, newYork),
+			end:   time.Date(2024, 3, 10, 0, 0, 0, 0, newYork),
+		},
+		{
+			rows:  []map[string]any{{"a": int64(1)}, {"a": int64(2)}},
+			start: time.Date(2024, 3, 9, 0, 0, 0, 0, newYork),
+			end:   time.Date(2024, 3, 11, 0, 0, 0, 0, newYork),
+		},
+	}
+	for _, e := range expected {
+		got := <-output
+		wt, ok := got.(*xsql.WindowTuples)
+		require.True(t, ok)
+		require.Equal(t, e.rows, wt.ToMaps())
+		start, _ := wt.WindowRange.FuncValue("window_start")
+		require.Equal(t, e.start.UnixMilli(), start)
+		end, _ := wt.WindowRange.FuncValue("window_end")
+		require.Equal(t, e.end.UnixMilli(), end)
+	}
+	cancel()
+	waitExecute()
+	op.Close()
+}
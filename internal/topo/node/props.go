@@ -1,4 +1,4 @@
-// Copyright 2024-2025 EMQ Technologies Co., Ltd.
+// Copyright 2024-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -44,6 +44,8 @@ type SinkConf struct {
 	EncProps         map[string]any    `json:"encProps"`
 	HasHeader        bool              `json:"hasHeader"`
 	model.SinkConf
+	// props is the raw props to pass the format specific props to the converter
+	props map[string]any
 }
 
 func ParseConf(logger api.Logger, props map[string]any) (*SinkConf, error) {
@@ -54,6 +56,7 @@ func ParseConf(logger api.Logger, props map[string]any) (*SinkConf, error) {
 		DataTemplate: "",
 		SinkConf:     *conf.Config.Sink,
 		BufferLength: 1024,
+		props:        props,
 	}
 	err := cast.MapToStruct(props, sconf)
 	if err != nil {
@@ -87,3 +90,26 @@ func ParseConf(logger api.Logger, props map[string]any) (*SinkConf, error) {
 	}
 	return sconf, err
 }
+
+// formatPropKeys are the format specific sink props which are passed through to the converter, such as the xml mapping
+// and the avro schema registry
+var formatPropKeys = []string{
+	"xmlRootElement", "xmlItemElement", "xmlAttrPrefix", "xmlTextKey",
+	"schemaRegistryUrl", "schemaRegistrySubject", "schemaRegistryUsername", "schemaRegistryPassword",
+}
+
+// ConverterProps returns the props to create the converter. Only the format specific props such as the xml mapping
+// are passed through from the sink props, so that the other sink props do not reach the converters.
+func (sc *SinkConf) ConverterProps() map[string]any {
+	result := map[string]any{
+		"delimiter": sc.Delimiter,
+		"hasHeader": sc.HasHeader,
+		"fields":    sc.Fields,
+	}
+	for _, k := range formatPropKeys {
+		if v, ok := sc.props[k]; ok {
+			result[k] = v
+		}
+	}
+	return result
+}
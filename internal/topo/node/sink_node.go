@@ -1,4 +1,4 @@
-// Copyright 2024-2025 EMQ Technologies Co., Ltd.
+// Copyright 2024-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -28,6 +28,7 @@ import (
 	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
 	"github.com/lf-edge/ekuiper/v2/pkg/infra"
 	"github.com/lf-edge/ekuiper/v2/pkg/model"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
 	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
 
@@ -85,6 +86,9 @@ func (s *SinkNode) Exec(ctx api.StreamContext, errCh chan<- error) {
 	go func() {
 		err := infra.SafeRun(func() error {
 			s.setKafkaSinkStatsManager(ctx)
+			if tc, ok := s.sink.(modules.TwoPhaseCommitter); ok && s.qos >= def.ExactlyOnce {
+				tc.EnableTwoPhaseCommit()
+			}
 			err := s.sink.Connect(ctx, s.connectionStatusChange)
 			if err != nil {
 				infra.DrainError(ctx, err, errCh)
@@ -116,11 +120,13 @@ func (s *SinkNode) Exec(ctx api.StreamContext, errCh chan<- error) {
 									// rule stop so stop waiting
 								default:
 									s.onError(ctx, fmt.Errorf("buffer full, drop message from %s to resend sink", s.name))
+									s.sendDeadLetter(ctx, err, val)
 								}
 							})
 						} else if s.resendInterval > 0 {
 							if !errorx.IsIOError(err) {
 								ctx.GetLogger().Errorf("no io error %v, drop %v", err, xsql.GetId(data))
+								s.sendDeadLetter(ctx, err, data)
 							} else {
 								ticker := timex.GetTicker(s.resendInterval)
 								defer ticker.Stop()
@@ -140,8 +146,11 @@ func (s *SinkNode) Exec(ctx api.StreamContext, errCh chan<- error) {
 									s.onSend(ctx, data)
 								} else {
 									ctx.GetLogger().Debugf("no io error %v", err)
+									s.sendDeadLetter(ctx, err, data)
 								}
 							}
+						} else {
+							s.sendDeadLetter(ctx, err, data)
 						}
 					} else {
 						s.onSend(ctx, data)
@@ -157,6 +166,30 @@ func (s *SinkNode) Exec(ctx api.StreamContext, errCh chan<- error) {
 	}()
 }
 
+// PrepareCheckpoint pre-commits the data collected before the barrier if the sink supports two-phase commit. The data
+// cannot be delivered exactly once if it fails, so the rule fails to restart from the last completed checkpoint.
+func (s *SinkNode) PrepareCheckpoint(checkpointId int64) error {
+	if tc, ok := s.sink.(modules.TwoPhaseCommitter); ok && s.qos >= def.ExactlyOnce {
+		if err := tc.PreCommit(s.ctx, checkpointId); err != nil {
+			s.onErrorOpt(s.ctx, err, false)
+			infra.DrainError(s.ctx, fmt.Errorf("fail to pre-commit checkpoint %d: %v", checkpointId, err), s.ctrlCh)
+			return err
+		}
+	}
+	return nil
+}
+
+// NotifyCheckpointComplete commits the pre-committed data of the completed checkpoint. If it fails, the rule fails
+// to restart, and the sink commits the pre-committed data of the restored checkpoint again.
+func (s *SinkNode) NotifyCheckpointComplete(checkpointId int64) {
+	if tc, ok := s.sink.(modules.TwoPhaseCommitter); ok && s.qos >= def.ExactlyOnce {
+		if err := tc.Commit(s.ctx, checkpointId); err != nil {
+			s.onErrorOpt(s.ctx, err, false)
+			infra.DrainError(s.ctx, fmt.Errorf("fail to commit checkpoint %d: %v", checkpointId, err), s.ctrlCh)
+		}
+	}
+}
+
 func (s *SinkNode) SetResendOutput(output chan<- any) {
 	s.resendOut = output
 }
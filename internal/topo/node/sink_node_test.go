@@ -1,4 +1,4 @@
-// Copyright 2024-2025 EMQ Technologies Co., Ltd.
+// Copyright 2024-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -15,11 +15,14 @@
 package node
 
 import (
+	"errors"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
@@ -197,3 +200,64 @@ func (m *mockResendSink) Collect(ctx api.StreamContext, item api.RawTuple) error
 }
 
 var _ api.BytesCollector = &mockResendSink{}
+
+type mockTwoPhaseSink struct {
+	mockResendSink
+	enabled   atomic.Bool
+	preCommit []int64
+	committed []int64
+}
+
+func (m *mockTwoPhaseSink) EnableTwoPhaseCommit() {
+	m.enabled.Store(true)
+}
+
+func (m *mockTwoPhaseSink) PreCommit(_ api.StreamContext, checkpointId int64) error {
+	if checkpointId < 0 {
+		return errors.New("fake error")
+	}
+	m.preCommit = append(m.preCommit, checkpointId)
+	return nil
+}
+
+func (m *mockTwoPhaseSink) Commit(_ api.StreamContext, checkpointId int64) error {
+	if checkpointId < 0 {
+		return errors.New("fake commit error")
+	}
+	m.committed = append(m.committed, checkpointId)
+	return nil
+}
+
+func TestTwoPhaseCommit(t *testing.T) {
+	for _, qos := range []def.Qos{def.AtLeastOnce, def.ExactlyOnce} {
+		ctx, cancel := mockContext.NewMockContext("twophase", "sink").WithCancel()
+		s := &mockTwoPhaseSink{}
+		n, err := NewBytesSinkNode(ctx, "twophase_sink", s, def.RuleOption{
+			BufferLength: 1024,
+		}, 1, &SinkConf{}, false)
+		require.NoError(t, err)
+		n.SetQos(qos)
+		errCh := make(chan error, 1)
+		n.Exec(ctx, errCh)
+		if qos == def.ExactlyOnce {
+			require.Eventually(t, s.enabled.Load, time.Second, 10*time.Millisecond)
+		}
+		require.NoError(t, n.PrepareCheckpoint(1))
+		require.NoError(t, n.PrepareCheckpoint(2))
+		n.NotifyCheckpointComplete(2)
+		if qos == def.ExactlyOnce {
+			// the failures are rule errors
+			require.Error(t, n.PrepareCheckpoint(-1))
+			require.EqualError(t, <-errCh, "fail to pre-commit checkpoint -1: fake error")
+			n.NotifyCheckpointComplete(-1)
+			require.EqualError(t, <-errCh, "fail to commit checkpoint -1: fake commit error")
+			require.Equal(t, []int64{1, 2}, s.preCommit)
+			require.Equal(t, []int64{2}, s.committed)
+		} else {
+			require.False(t, s.enabled.Load())
+			require.Empty(t, s.preCommit)
+			require.Empty(t, s.committed)
+		}
+		cancel()
+	}
+}
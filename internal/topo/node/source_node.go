@@ -1,4 +1,4 @@
-// Copyright 2024-2025 EMQ Technologies Co., Ltd.
+// Copyright 2024-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -16,6 +16,7 @@ package node
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
@@ -31,6 +32,7 @@ import (
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/infra"
 	"github.com/lf-edge/ekuiper/v2/pkg/model"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
 	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
 
@@ -43,6 +45,11 @@ type SourceNode struct {
 	s         api.Source
 	interval  time.Duration
 	notifySub bool
+	// the interval of the rule checkpoint, which is used by the source to acknowledge the data
+	checkpointInterval time.Duration
+	// the offsets of the pending checkpoints to commit once completed
+	offsetLock        sync.Mutex
+	checkpointOffsets map[int64]any
 }
 
 type sourceConf struct {
@@ -65,10 +72,11 @@ func NewSourceNode(ctx api.StreamContext, name string, ss api.Source, props map[
 		return nil, err
 	}
 	m := &SourceNode{
-		defaultNode: newDefaultNode(name, rOpt),
-		s:           ss,
-		interval:    time.Duration(cc.Interval),
-		notifySub:   rOpt.NotifySub,
+		defaultNode:        newDefaultNode(name, rOpt),
+		s:                  ss,
+		interval:           time.Duration(cc.Interval),
+		notifySub:          rOpt.NotifySub,
+		checkpointInterval: time.Duration(rOpt.CheckpointInterval),
 	}
 	switch st := ss.(type) {
 	case api.Bounded:
@@ -253,6 +261,47 @@ func (m *SourceNode) updateState(ctx api.StreamContext) error {
 	return nil
 }
 
+// PrepareCheckpoint records the current offset which is committed when the checkpoint completes
+func (m *SourceNode) PrepareCheckpoint(checkpointId int64) error {
+	if _, ok := m.s.(modules.CheckpointCommitter); !ok || m.ctx == nil {
+		return nil
+	}
+	offset, err := m.ctx.GetState(OffsetKey)
+	if err != nil || offset == nil {
+		return nil
+	}
+	m.offsetLock.Lock()
+	defer m.offsetLock.Unlock()
+	if m.checkpointOffsets == nil {
+		m.checkpointOffsets = make(map[int64]any)
+	}
+	m.checkpointOffsets[checkpointId] = offset
+	return nil
+}
+
+// NotifyCheckpointComplete commits the offset of the completed checkpoint. The offsets of the previous checkpoints
+// are discarded because they are covered.
+func (m *SourceNode) NotifyCheckpointComplete(checkpointId int64) {
+	cc, ok := m.s.(modules.CheckpointCommitter)
+	if !ok {
+		return
+	}
+	m.offsetLock.Lock()
+	offset, found := m.checkpointOffsets[checkpointId]
+	for id := range m.checkpointOffsets {
+		if id <= checkpointId {
+			delete(m.checkpointOffsets, id)
+		}
+	}
+	m.offsetLock.Unlock()
+	if !found {
+		return
+	}
+	if err := cc.Commit(m.ctx, offset); err != nil {
+		m.ctx.GetLogger().Errorf("fail to commit offset %v for checkpoint %d: %v", offset, checkpointId, err)
+	}
+}
+
 // Run Subscribe could be a long-running function
 func (m *SourceNode) Run(ctx api.StreamContext, ctrlCh chan<- error) {
 	defer func() {
@@ -271,6 +320,11 @@ func (m *SourceNode) Run(ctx api.StreamContext, ctrlCh chan<- error) {
 		if err := m.Rewind(ctx); err != nil {
 			return err
 		}
+		if cc, ok := m.s.(modules.CheckpointCommitter); ok && m.qos >= def.AtLeastOnce {
+			if err := cc.EnableCheckpointCommit(m.checkpointInterval); err != nil {
+				return err
+			}
+		}
 		switch ss := m.s.(type) {
 		case api.BytesSource:
 			err = ss.Subscribe(ctx, m.ingestBytes, m.ingestError)
@@ -1,4 +1,4 @@
-// Copyright 2024 EMQ Technologies Co., Ltd.
+// Copyright 2024-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -434,3 +434,49 @@ func TestMockRewind(t *testing.T) {
 	v, _ := ctx.GetState(OffsetKey)
 	require.Equal(t, 11, v)
 }
+
+type MockCommitSource struct {
+	MockRewindSource
+	enabled   atomic.Bool
+	committed []any
+}
+
+func (m *MockCommitSource) EnableCheckpointCommit(_ time.Duration) error {
+	m.enabled.Store(true)
+	return nil
+}
+
+func (m *MockCommitSource) Commit(_ api.StreamContext, offset any) error {
+	m.committed = append(m.committed, offset)
+	return nil
+}
+
+func TestCheckpointCommit(t *testing.T) {
+	m := &MockCommitSource{
+		MockRewindSource: MockRewindSource{notify: make(chan struct{})},
+	}
+	ctx := mockContext.NewMockContext("rule1", "src1")
+	errCh := make(chan error)
+	scn, err := NewSourceNode(ctx, "mock_connector", m, map[string]any{"datasource": "demo"}, &def.RuleOption{
+		BufferLength: 1024,
+		SendError:    true,
+	})
+	require.NoError(t, err)
+	scn.SetQos(def.AtLeastOnce)
+	scn.Open(ctx, errCh)
+	require.Eventually(t, m.enabled.Load, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, ctx.PutState(OffsetKey, 10))
+	scn.PrepareCheckpoint(1)
+	require.NoError(t, ctx.PutState(OffsetKey, 12))
+	scn.PrepareCheckpoint(2)
+	require.NoError(t, ctx.PutState(OffsetKey, 15))
+	scn.PrepareCheckpoint(3)
+	scn.NotifyCheckpointComplete(2)
+	require.Equal(t, []any{12}, m.committed)
+	// the previous checkpoint is discarded
+	scn.NotifyCheckpointComplete(1)
+	require.Equal(t, []any{12}, m.committed)
+	scn.NotifyCheckpointComplete(3)
+	require.Equal(t, []any{12, 15}, m.committed)
+}
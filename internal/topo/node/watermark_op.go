@@ -36,6 +36,14 @@ type WatermarkOp struct {
 	// config
 	lateTolerance time.Duration
 	sendWatermark bool
+	// allowedLateness is the period after the watermark that the late events are still sent out to update the closed windows
+	allowedLateness time.Duration
+	// ordered are the emitters whose events are already in time order such as the derived tables, which are ordered by
+	// the watermark of the sub query. They are not delayed by the late tolerance again.
+	ordered map[string]struct{}
+	// derivedTable is set if the op is in the sub query of a derived table. The watermarks are sent to the outer query
+	// with the derived table name.
+	derivedTable string
 	// state
 	events          []*xsql.Tuple // All the cached events in order
 	rowHandle       map[any]trace.Span
@@ -51,15 +59,27 @@ const (
 	StreamWMKey   = "$$streamwms"
 )
 
-func NewWatermarkOp(name string, sendWatermark bool, streams []string, options *def.RuleOption) *WatermarkOp {
+// NewWatermarkOp creates the watermark op. The allowedLateness is only set when the downstream window can update the closed windows.
+// The ordered streams are the subset of the streams whose events are already in time order.
+func NewWatermarkOp(name string, sendWatermark bool, streams []string, ordered []string, allowedLateness time.Duration, options *def.RuleOption) *WatermarkOp {
+	orderedSet := make(map[string]struct{}, len(ordered))
+	for _, s := range ordered {
+		orderedSet[s] = struct{}{}
+	}
 	wms := make(map[string]time.Time, len(streams))
 	for _, s := range streams {
-		wms[s] = time.Time{}.Add(time.Duration(options.LateTol))
+		if _, ok := orderedSet[s]; ok {
+			wms[s] = time.Time{}
+		} else {
+			wms[s] = time.Time{}.Add(time.Duration(options.LateTol))
+		}
 	}
 	return &WatermarkOp{
 		defaultSinkNode: newDefaultSinkNode(name, options),
 		lateTolerance:   time.Duration(options.LateTol),
 		sendWatermark:   sendWatermark,
+		allowedLateness: allowedLateness,
+		ordered:         orderedSet,
 		streamWMs:       wms,
 		lastWatermarkTs: time.Time{},
 		rowHandle:       make(map[any]trace.Span),
@@ -112,6 +132,12 @@ func (w *WatermarkOp) Exec(ctx api.StreamContext, errCh chan<- error) {
 					ctx.GetLogger().Infof("watermark node %s is finished", w.name)
 					return nil
 				case item := <-w.input:
+					if wt, ok := item.(*xsql.WatermarkTuple); ok && wt.Emitter != "" {
+						// the watermark of a derived table advances its event time like a row
+						w.track(ctx, wt.Emitter, wt.Timestamp)
+						w.trigger(ctx)
+						break
+					}
 					data, processed := w.commonIngest(ctx, item)
 					if processed {
 						break
@@ -126,6 +152,8 @@ func (w *WatermarkOp) Exec(ctx api.StreamContext, errCh chan<- error) {
 						if w.track(ctx, d.Emitter, d.Timestamp) {
 							// If not drop, check if it can be sent out
 							w.addAndTrigger(ctx, d)
+						} else {
+							w.handleLate(ctx, d)
 						}
 					default:
 						w.onError(ctx, fmt.Errorf("run watermark op error: expect *xsql.Tuple type but got %[1]T(%[1]v)", d))
@@ -152,6 +180,25 @@ func (w *WatermarkOp) track(ctx api.StreamContext, emitter string, ts time.Time)
 	return r
 }
 
+// handleLate sends out the late event directly if it is in the allowed lateness so that the window can update the result.
+// Otherwise, send it to the side output of the rule, which publishes it to the late event topic and the dead letter if set.
+func (w *WatermarkOp) handleLate(ctx api.StreamContext, d *xsql.Tuple) {
+	if span, stored := w.rowHandle[d]; stored {
+		defer func() {
+			span.End()
+			delete(w.rowHandle, d)
+		}()
+	}
+	if w.allowedLateness > 0 && !d.Timestamp.Before(w.lastWatermarkTs.Add(-w.allowedLateness)) {
+		ctx.GetLogger().Debugf("send out late event at %d with watermark %d", d.Timestamp.UnixMilli(), w.lastWatermarkTs.UnixMilli())
+		w.Broadcast(d)
+		w.onSend(ctx, d)
+		return
+	}
+	ctx.GetLogger().Debugf("drop late event at %d with watermark %d", d.Timestamp.UnixMilli(), w.lastWatermarkTs.UnixMilli())
+	w.sendLateEvent(ctx, fmt.Errorf("late event at %d is dropped by watermark %d", d.Timestamp.UnixMilli(), w.lastWatermarkTs.UnixMilli()), d)
+}
+
 // Add an event and check if watermark proceeds
 // If yes, send out all events before the watermark
 func (w *WatermarkOp) addAndTrigger(ctx api.StreamContext, d *xsql.Tuple) {
@@ -166,13 +213,17 @@ func (w *WatermarkOp) addAndTrigger(ctx api.StreamContext, d *xsql.Tuple) {
 		copy(w.events[index+1:], w.events[index:])
 		w.events[index] = d
 	}
+	w.trigger(ctx)
+}
 
+// trigger sends out all the events before the watermark if the watermark proceeds
+func (w *WatermarkOp) trigger(ctx api.StreamContext) {
 	watermark := w.computeWatermarkTs()
 	ctx.GetLogger().Debugf("compute watermark event at %d with last %d", watermark.UnixMilli(), w.lastWatermarkTs.UnixMilli())
 	// Make sure watermark time proceeds
 	if watermark.After(w.lastWatermarkTs) {
 		// Send out all events before the watermark
-		if watermark.After(w.events[0].Timestamp) || watermark.Equal(w.events[0].Timestamp) {
+		if len(w.events) > 0 && !watermark.Before(w.events[0].Timestamp) {
 			// Find out the last event to send in this watermark change
 			c := len(w.events)
 			for i, e := range w.events {
@@ -205,7 +256,7 @@ func (w *WatermarkOp) addAndTrigger(ctx api.StreamContext, d *xsql.Tuple) {
 		}
 		// Update watermark
 		if w.sendWatermark {
-			w.Broadcast(&xsql.WatermarkTuple{Timestamp: watermark})
+			w.Broadcast(&xsql.WatermarkTuple{Timestamp: watermark, Emitter: w.derivedTable})
 		}
 		w.lastWatermarkTs = watermark
 		_ = ctx.PutState(WatermarkKey, w.lastWatermarkTs)
@@ -213,13 +264,21 @@ func (w *WatermarkOp) addAndTrigger(ctx api.StreamContext, d *xsql.Tuple) {
 	}
 }
 
-// watermark is the minimum timestamp of all input topics
+// SetDerivedTable sets the derived table name of the sub query which the op belongs to
+func (w *WatermarkOp) SetDerivedTable(name string) {
+	w.derivedTable = name
+}
+
+// watermark is the minimum timestamp of all input topics minus the late tolerance except the ordered ones
 func (w *WatermarkOp) computeWatermarkTs() time.Time {
 	ts := timex.Maxtime
-	for _, wm := range w.streamWMs {
+	for emitter, wm := range w.streamWMs {
+		if _, ok := w.ordered[emitter]; !ok {
+			wm = wm.Add(-w.lateTolerance)
+		}
 		if ts.After(wm) {
 			ts = wm
 		}
 	}
-	return ts.Add(-w.lateTolerance)
+	return ts
 }
@@ -19,8 +19,10 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/io/memory/pubsub"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/context"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/state"
@@ -201,7 +203,7 @@ func TestSingleStreamWatermark(t *testing.T) {
 			ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
 			tempStore, _ := state.CreateStore("TestWatermark", def.AtMostOnce)
 			nctx := ctx.WithMeta("TestWatermark", "test", tempStore)
-			w := NewWatermarkOp("mock", false, []string{"demo"}, &def.RuleOption{
+			w := NewWatermarkOp("mock", false, []string{"demo"}, nil, 0, &def.RuleOption{
 				IsEventTime:    true,
 				LateTol:        cast.DurationConf(tt.latetol),
 				Concurrency:    0,
@@ -452,7 +454,7 @@ func TestMultiStreamWatermark(t *testing.T) {
 			ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
 			tempStore, _ := state.CreateStore("TestWatermark", def.AtMostOnce)
 			nctx := ctx.WithMeta("TestWatermark", "test", tempStore)
-			w := NewWatermarkOp("mock", true, []string{"demo1", "demo2"}, &def.RuleOption{
+			w := NewWatermarkOp("mock", true, []string{"demo1", "demo2"}, nil, 0, &def.RuleOption{
 				IsEventTime:        true,
 				LateTol:            cast.DurationConf(tt.latetol),
 				Concurrency:        0,
@@ -506,3 +508,92 @@ func TestMultiStreamWatermark(t *testing.T) {
 		})
 	}
 }
+
+func TestWatermarkAllowedLateness(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "TestWatermarkAllowedLateness")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("TestWatermarkAllowedLateness", def.AtMostOnce)
+	nctx := ctx.WithMeta("TestWatermarkAllowedLateness", "test", tempStore)
+	opt := &def.RuleOption{
+		IsEventTime:    true,
+		BufferLength:   10,
+		LateEventTopic: "lateTopic",
+	}
+	w := NewWatermarkOp("mock", false, []string{"demo"}, nil, 10*time.Millisecond, opt)
+	dl, err := NewDeadLetterOp("deadLetter", "", opt.LateEventTopic, nil, nil, opt)
+	require.NoError(t, err)
+	w.SetDeadLetter(dl)
+	lateCh := pubsub.CreateSub("lateTopic", nil, "TestWatermarkAllowedLateness", 10)
+	defer pubsub.CloseSourceConsumerChannel("lateTopic", "TestWatermarkAllowedLateness")
+	errCh := make(chan error)
+	dl.Exec(ctx.WithMeta("TestWatermarkAllowedLateness", "deadLetter", tempStore), errCh)
+	outputCh := make(chan any, 50)
+	w.outputs["mock"] = outputCh
+	w.Exec(nctx, errCh)
+
+	inputs := []*xsql.Tuple{
+		{Emitter: "demo", Message: map[string]any{"a": 1}, Timestamp: time.UnixMilli(10)},
+		{Emitter: "demo", Message: map[string]any{"a": 2}, Timestamp: time.UnixMilli(30)},
+		// late but in the allowed lateness
+		{Emitter: "demo", Message: map[string]any{"a": 3}, Timestamp: time.UnixMilli(25)},
+		// later than the allowed lateness
+		{Emitter: "demo", Message: map[string]any{"a": 4}, Timestamp: time.UnixMilli(15)},
+	}
+	for _, in := range inputs {
+		w.input <- in
+	}
+	for _, exp := range inputs[:3] {
+		select {
+		case out := <-outputCh:
+			assert.Equal(t, exp, out)
+		case <-time.After(5 * time.Second):
+			t.Fatal("receive output timeout")
+		}
+	}
+	select {
+	case late := <-lateCh:
+		assert.Equal(t, inputs[3], late)
+	case <-time.After(5 * time.Second):
+		t.Fatal("receive late event timeout")
+	}
+}
+
+func TestWatermarkDerivedTable(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "TestWatermarkDerivedTable")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("TestWatermarkDerivedTable", def.AtMostOnce)
+	nctx := ctx.WithMeta("TestWatermarkDerivedTable", "test", tempStore)
+	opt := &def.RuleOption{
+		IsEventTime:  true,
+		BufferLength: 10,
+		LateTol:      cast.DurationConf(10 * time.Millisecond),
+	}
+	// the derived table t is ordered by the watermark of its sub query, so the late tolerance only applies to demo
+	w := NewWatermarkOp("mock", true, []string{"demo", "t"}, []string{"t"}, 0, opt)
+	w.SetDerivedTable("outer")
+	errCh := make(chan error)
+	outputCh := make(chan any, 50)
+	w.outputs["mock"] = outputCh
+	w.Exec(nctx, errCh)
+
+	demo := &xsql.Tuple{Emitter: "demo", Message: map[string]any{"a": 1}, Timestamp: time.UnixMilli(120)}
+	derived := &xsql.Tuple{Emitter: "t", Message: map[string]any{"a": 2}, Timestamp: time.UnixMilli(100)}
+	w.input <- demo
+	w.input <- derived
+	// the watermark of the sub query advances the derived table without a row
+	w.input <- &xsql.WatermarkTuple{Emitter: "t", Timestamp: time.UnixMilli(150)}
+	expected := []any{
+		derived,
+		&xsql.WatermarkTuple{Emitter: "outer", Timestamp: time.UnixMilli(100)},
+		// demo is delayed by the late tolerance
+		&xsql.WatermarkTuple{Emitter: "outer", Timestamp: time.UnixMilli(110)},
+	}
+	for _, exp := range expected {
+		select {
+		case out := <-outputCh:
+			assert.Equal(t, exp, out)
+		case <-time.After(5 * time.Second):
+			t.Fatal("receive output timeout")
+		}
+	}
+}
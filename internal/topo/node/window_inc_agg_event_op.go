@@ -128,7 +128,7 @@ func (ho *HoppingWindowIncAggEventOp) calIncAggWindowInEvent(ctx api.StreamConte
 }
 
 func (ho *HoppingWindowIncAggEventOp) triggerWindow(ctx api.StreamContext, now time.Time) {
-	next := getAlignedWindowEndTime(now, ho.op.windowConfig.RawInterval, ho.op.windowConfig.TimeUnit)
+	next := getAlignedWindowEndTime(now.In(ho.op.windowConfig.location()), ho.op.windowConfig.RawInterval, ho.op.windowConfig.TimeUnit)
 	if ho.NextTriggerWindowTime.Before(now) {
 		ho.NextTriggerWindowTime = next
 		ho.CurrWindowList = append(ho.CurrWindowList, newIncAggWindow(ctx, next.Add(-ho.op.Interval)))
@@ -436,3 +436,69 @@ func (co *CountWindowIncAggEventOp) RestoreFromState(ctx api.StreamContext) erro
 	co.CurrWindow.restoreState(ctx)
 	return nil
 }
+
+type CumulateWindowIncAggEventOp struct {
+	op *CumulateWindowIncAggOp
+}
+
+func NewCumulateWindowIncAggEventOp(o *WindowIncAggOperator) *CumulateWindowIncAggEventOp {
+	return &CumulateWindowIncAggEventOp{op: NewCumulateWindowIncAggOp(o)}
+}
+
+func (co *CumulateWindowIncAggEventOp) PutState(ctx api.StreamContext) {
+	co.op.PutState(ctx)
+}
+
+func (co *CumulateWindowIncAggEventOp) RestoreFromState(ctx api.StreamContext) error {
+	return co.op.RestoreFromState(ctx)
+}
+
+func (co *CumulateWindowIncAggEventOp) exec(ctx api.StreamContext, errCh chan<- error) {
+	if err := co.RestoreFromState(ctx); err != nil {
+		errCh <- err
+		return
+	}
+	fv, _ := xsql.NewFunctionValuersForOp(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case input := <-co.op.input:
+			data, processed := co.op.ingest(ctx, input)
+			if processed {
+				break
+			}
+			switch tuple := data.(type) {
+			case *xsql.WatermarkTuple:
+				co.emitUntil(ctx, errCh, tuple.GetTimestamp())
+				co.PutState(ctx)
+			case *xsql.Tuple:
+				co.op.onProcessStart(ctx, data)
+				now := tuple.GetTimestamp()
+				// tuples are sorted by the watermark op, so the steps before the tuple are complete
+				co.emitUntil(ctx, errCh, now)
+				if co.op.CurrWindow == nil {
+					start := co.op.windowConfig.cumulateWindowStart(now)
+					co.op.CurrWindow = newIncAggWindow(ctx, start)
+					co.op.NextEmitTime = co.op.windowConfig.nextWindowEnd(start, co.op.Interval)
+				}
+				name := calDimension(fv, co.op.Dimensions, tuple)
+				incAggCal(ctx, name, tuple, co.op.CurrWindow, co.op.aggFields)
+				co.PutState(ctx)
+				co.op.onProcessEnd(ctx)
+			}
+		}
+	}
+}
+
+// emitUntil emits all the steps which end before the given time
+func (co *CumulateWindowIncAggEventOp) emitUntil(ctx api.StreamContext, errCh chan<- error, now time.Time) {
+	for co.op.CurrWindow != nil && !co.op.NextEmitTime.After(now) {
+		co.op.emit(ctx, errCh, co.op.CurrWindow, co.op.NextEmitTime)
+		if co.op.NextEmitTime.Before(co.op.windowConfig.cumulateWindowEnd(co.op.CurrWindow.StartTime)) {
+			co.op.NextEmitTime = co.op.windowConfig.nextWindowEnd(co.op.NextEmitTime, co.op.Interval)
+		} else {
+			co.op.CurrWindow = nil
+		}
+	}
+}
@@ -46,6 +46,7 @@ func init() {
 	gob.Register(TumblingWindowIncAggOpState{})
 	gob.Register(SlidingWindowIncAggOpState{})
 	gob.Register(SlidingWindowIncAggEventOpState{})
+	gob.Register(CumulateWindowIncAggOpState{})
 }
 
 type WindowIncAggOperator struct {
@@ -57,6 +58,10 @@ type WindowIncAggOperator struct {
 }
 
 func NewWindowIncAggOp(name string, w *WindowConfig, dimensions ast.Dimensions, aggFields []*ast.Field, options *def.RuleOption) (*WindowIncAggOperator, error) {
+	// The incremental windows trigger by ticker which cannot follow the calendar
+	if w.isCalendar() {
+		return nil, fmt.Errorf("incremental %s does not support the calendar unit %s", w.Type, w.TimeUnit)
+	}
 	o := new(WindowIncAggOperator)
 	o.defaultSinkNode = newDefaultSinkNode(name, options)
 	o.windowConfig = w
@@ -96,6 +101,12 @@ func NewWindowIncAggOp(name string, w *WindowConfig, dimensions ast.Dimensions,
 		} else {
 			o.WindowExec = NewHoppingWindowIncAggOp(o)
 		}
+	case ast.CUMULATE_WINDOW:
+		if options.IsEventTime {
+			o.WindowExec = NewCumulateWindowIncAggEventOp(o)
+		} else {
+			o.WindowExec = NewCumulateWindowIncAggOp(o)
+		}
 	}
 	return o, nil
 }
@@ -172,6 +183,14 @@ func (r *IncAggRange) Clone(ctx api.StreamContext) *IncAggRange {
 	fstore, _ := state.CreateStore("incAggWindow", 0)
 	fctx := topoContext.Background().WithMeta(ctx.GetRuleId(), ctx.GetOpId(), fstore)
 	for k, v := range r.generateFunctionState() {
+		// the map state like the seen values of the distinct functions is updated in place, so copy it
+		if m, ok := v.(map[string]interface{}); ok {
+			cm := make(map[string]interface{}, len(m))
+			for mk, mv := range m {
+				cm[mk] = mv
+			}
+			v = cm
+		}
 		fctx.PutState(k, v)
 	}
 	fv, _ := xsql.NewFunctionValuersForOp(fctx)
@@ -369,7 +388,7 @@ func (to *TumblingWindowIncAggOp) exec(ctx api.StreamContext, errCh chan<- error
 	if !EnableAlignWindow {
 		to.ticker = timex.GetTicker(to.Interval)
 	} else {
-		_, to.FirstTimer = getFirstTimer(ctx, to.windowConfig.RawInterval, to.windowConfig.TimeUnit)
+		_, to.FirstTimer = getFirstTimer(ctx, to.windowConfig.RawInterval, to.windowConfig.TimeUnit, to.windowConfig.location())
 		if to.CurrWindow == nil {
 			to.CurrWindow = newIncAggWindow(ctx, now)
 		}
@@ -694,7 +713,7 @@ func (ho *HoppingWindowIncAggOp) exec(ctx api.StreamContext, errCh chan<- error)
 		ho.ticker = timex.GetTicker(ho.Interval)
 		ho.newIncWindow(ctx, now)
 	} else {
-		_, ho.FirstTimer = getFirstTimer(ctx, ho.windowConfig.RawInterval, ho.windowConfig.TimeUnit)
+		_, ho.FirstTimer = getFirstTimer(ctx, ho.windowConfig.RawInterval, ho.windowConfig.TimeUnit, ho.windowConfig.location())
 		ho.CurrWindowList = append(ho.CurrWindowList, newIncAggWindow(ctx, now))
 	}
 	fv, _ := xsql.NewFunctionValuersForOp(ctx)
@@ -789,6 +808,154 @@ func (ho *HoppingWindowIncAggOp) calIncAggWindow(ctx api.StreamContext, fv *xsql
 	}
 }
 
+type CumulateWindowIncAggOp struct {
+	*WindowIncAggOperator
+	ticker     *clock.Ticker
+	FirstTimer *clock.Timer
+	Length     time.Duration
+	Interval   time.Duration
+	CumulateWindowIncAggOpState
+}
+
+type CumulateWindowIncAggOpState struct {
+	CurrWindow *IncAggWindow
+	// NextEmitTime is the end of the next step to emit
+	NextEmitTime time.Time
+}
+
+func NewCumulateWindowIncAggOp(o *WindowIncAggOperator) *CumulateWindowIncAggOp {
+	return &CumulateWindowIncAggOp{
+		WindowIncAggOperator: o,
+		Length:               o.windowConfig.Length,
+		Interval:             o.windowConfig.Interval,
+	}
+}
+
+func (co *CumulateWindowIncAggOp) PutState(ctx api.StreamContext) {
+	co.CurrWindow.GenerateAllFunctionState()
+	ctx.PutState(buildStateKey(ctx), co.CumulateWindowIncAggOpState)
+}
+
+func (co *CumulateWindowIncAggOp) RestoreFromState(ctx api.StreamContext) error {
+	s, err := ctx.GetState(buildStateKey(ctx))
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		return nil
+	}
+	coState, ok := s.(CumulateWindowIncAggOpState)
+	if !ok {
+		return fmt.Errorf("not CumulateWindowIncAggOpState")
+	}
+	co.CumulateWindowIncAggOpState = coState
+	co.CumulateWindowIncAggOpState.CurrWindow.restoreState(ctx)
+	return nil
+}
+
+func (co *CumulateWindowIncAggOp) exec(ctx api.StreamContext, errCh chan<- error) {
+	if err := co.RestoreFromState(ctx); err != nil {
+		errCh <- err
+		return
+	}
+	defer func() {
+		if co.FirstTimer != nil {
+			co.FirstTimer.Stop()
+		}
+		if co.ticker != nil {
+			co.ticker.Stop()
+		}
+	}()
+	now := timex.GetNow()
+	windowStart := now
+	var firstC, tickC <-chan time.Time
+	if !EnableAlignWindow {
+		co.NextEmitTime = now.Add(co.Interval)
+		co.ticker = timex.GetTicker(co.Interval)
+		tickC = co.ticker.C
+	} else {
+		windowStart = co.windowConfig.cumulateWindowStart(now)
+		co.NextEmitTime, co.FirstTimer = getFirstTimer(ctx, co.windowConfig.RawInterval, co.windowConfig.TimeUnit, co.windowConfig.location())
+		firstC = co.FirstTimer.C
+	}
+	// the restored window is outdated
+	if co.CurrWindow != nil && !co.windowConfig.cumulateWindowEnd(co.CurrWindow.StartTime).After(now) {
+		co.CurrWindow = nil
+	}
+	if co.CurrWindow != nil {
+		windowStart = co.CurrWindow.StartTime
+	}
+	fv, _ := xsql.NewFunctionValuersForOp(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-firstC:
+			co.FirstTimer.Stop()
+			firstC = nil
+			co.FirstTimer = nil
+			co.ticker = timex.GetTicker(co.Interval)
+			tickC = co.ticker.C
+			windowStart = co.trigger(ctx, errCh, windowStart)
+			co.PutState(ctx)
+		case <-tickC:
+			windowStart = co.trigger(ctx, errCh, windowStart)
+			co.PutState(ctx)
+		case input := <-co.input:
+			data, processed := co.commonIngest(ctx, input)
+			if processed {
+				continue
+			}
+			co.onProcessStart(ctx, input)
+			switch row := data.(type) {
+			case *xsql.Tuple:
+				if co.CurrWindow == nil {
+					co.CurrWindow = newIncAggWindow(ctx, windowStart)
+				}
+				name := calDimension(fv, co.Dimensions, row)
+				incAggCal(ctx, name, row, co.CurrWindow, co.aggFields)
+			}
+			co.PutState(ctx)
+			co.onProcessEnd(ctx)
+		}
+	}
+}
+
+// trigger emits the current step and returns the start of the window for the next step
+func (co *CumulateWindowIncAggOp) trigger(ctx api.StreamContext, errCh chan<- error, windowStart time.Time) time.Time {
+	stepEnd := co.NextEmitTime
+	co.NextEmitTime = co.windowConfig.nextWindowEnd(stepEnd, co.Interval)
+	if co.CurrWindow != nil {
+		co.emit(ctx, errCh, co.CurrWindow, stepEnd)
+	}
+	if stepEnd.Before(co.windowConfig.cumulateWindowEnd(windowStart)) {
+		return windowStart
+	}
+	co.CurrWindow = nil
+	if !EnableAlignWindow {
+		return stepEnd
+	}
+	return co.windowConfig.cumulateWindowStart(stepEnd)
+}
+
+// emit sends the accumulated result of the window until now. The window keeps accumulating after the emission,
+// so the last rows are copied to avoid being modified by the later calculation.
+func (co *CumulateWindowIncAggOp) emit(ctx api.StreamContext, errCh chan<- error, window *IncAggWindow, now time.Time) {
+	results := &xsql.WindowTuples{
+		Content: make([]xsql.Row, 0, len(window.DimensionsIncAggRange)),
+	}
+	for _, incAggRange := range window.DimensionsIncAggRange {
+		row := cloneTuple(incAggRange.LastRow, len(incAggRange.LastRow.Message)+len(incAggRange.Fields))
+		for name, value := range incAggRange.Fields {
+			row.Set(name, value)
+		}
+		results.Content = append(results.Content, row)
+	}
+	results.WindowRange = xsql.NewWindowRange(window.StartTime.UnixMilli(), now.UnixMilli(), now.UnixMilli())
+	co.Broadcast(results)
+	co.onSend(ctx, results)
+}
+
 func incAggCal(ctx api.StreamContext, dimension string, row *xsql.Tuple, incAggWindow *IncAggWindow, aggFields []*ast.Field) {
 	dimensionsRange, ok := incAggWindow.DimensionsIncAggRange[dimension]
 	if !ok {
@@ -0,0 +1,41 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestIncAggRangeCloneState(t *testing.T) {
+	ctx := mockContext.NewMockContext("testIncAggRangeClone", "op1")
+	r := newIncAggRange(ctx)
+	r.LastRow = &xsql.Tuple{Message: xsql.Message{"a": 1}}
+	r.fctx.PutState("seen", map[string]interface{}{"a": true})
+	c := r.Clone(ctx)
+	v, err := c.fctx.GetState("seen")
+	require.NoError(t, err)
+	seen := v.(map[string]interface{})
+	require.Equal(t, map[string]interface{}{"a": true}, seen)
+	// the map state updated in place by the clone must not change the original one
+	seen["b"] = true
+	v, err = r.fctx.GetState("seen")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"a": true}, v)
+}
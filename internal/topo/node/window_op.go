@@ -31,6 +31,7 @@ import (
 	"github.com/lf-edge/ekuiper/v2/internal/topo/node/tracenode"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/infra"
 	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
@@ -49,6 +50,13 @@ type WindowConfig struct {
 	RawInterval   int
 	TimeUnit      ast.Token
 
+	// For CumulateWindow, the raw size to align the window start
+	RawLength int
+	// The time zone to align the time window. Use the configured time zone if nil
+	Location *time.Location
+	// For event time tumbling and hopping window, the period to keep the closed windows for the late events
+	AllowedLateness time.Duration
+
 	// For SlidingWindow
 	enableSlidingWindowSendTwice bool
 
@@ -77,6 +85,9 @@ type WindowOperator struct {
 	triggerTS        []time.Time
 	triggerCondition ast.Expr
 	stateFuncs       []*ast.Call
+	// For allowed lateness only
+	lateWindows   []*LateWindow
+	lastWatermark time.Time
 
 	nextLink     trace.Link
 	nextSpanCtx  context.Context
@@ -88,18 +99,22 @@ const (
 	WindowInputsKey = "$$windowInputs"
 	TriggerTimeKey  = "$$triggerTime"
 	MsgCountKey     = "$$msgCount"
+	LateWindowsKey  = "$$lateWindows"
 )
 
 func init() {
 	gob.Register([]xsql.EventRow{})
 	gob.Register([]map[string]interface{}{})
 	gob.Register(map[string]time.Time{})
+	gob.Register([]*LateWindow{})
 }
 
 func validateWindowConfig(w WindowConfig) error {
 	switch w.Type {
 	case ast.STATE_WINDOW:
 		return fmt.Errorf("v1 window op didn't support state window, use v2 window")
+	case ast.CUMULATE_WINDOW:
+		return fmt.Errorf("v1 window op didn't support cumulate window, use v2 window")
 	default:
 		return nil
 	}
@@ -119,6 +134,9 @@ func NewWindowOp(name string, w WindowConfig, options *def.RuleOption) (*WindowO
 		// if no interval value is set, and it's a count window, then set interval to length value.
 		o.window.CountInterval = o.window.CountLength
 	}
+	if !options.IsEventTime || (w.Type != ast.TUMBLING_WINDOW && w.Type != ast.HOPPING_WINDOW) {
+		o.window.AllowedLateness = 0
+	}
 	if options.IsEventTime {
 		// Create watermark generator
 		if w, err := NewEventTimeTrigger(o.window); err != nil {
@@ -174,6 +192,14 @@ func (o *WindowOperator) Exec(ctx api.StreamContext, errCh chan<- error) {
 			errCh <- fmt.Errorf("restore window state `triggerTime` %v error, invalid type", s)
 		}
 	}
+	if s, err := ctx.GetState(LateWindowsKey); err == nil && s != nil {
+		if si, ok := s.([]*LateWindow); ok {
+			o.lateWindows = si
+		} else {
+			infra.DrainError(ctx, fmt.Errorf("restore window state `lateWindows` %v error, invalid type", s), errCh)
+			return
+		}
+	}
 	o.msgCount = 0
 	if s, err := ctx.GetState(MsgCountKey); err == nil && s != nil {
 		if si, ok := s.(int); ok {
@@ -211,6 +237,12 @@ func (o *WindowOperator) Exec(ctx api.StreamContext, errCh chan<- error) {
 
 func getAlignedWindowEndTime(n time.Time, interval int, timeUnit ast.Token) time.Time {
 	switch timeUnit {
+	case ast.MM: // The month is aligned to the year, so that a 3 months window is a quarter
+		gap := interval * (int(n.Month()-time.January)/interval + 1)
+		return time.Date(n.Year(), time.January+time.Month(gap), 1, 0, 0, 0, 0, n.Location())
+	case ast.WW: // The interval * weeks starting this Monday
+		weekday := (int(n.Weekday()) + 6) % 7
+		return time.Date(n.Year(), n.Month(), n.Day()-weekday+7*interval, 0, 0, 0, 0, n.Location())
 	case ast.DD: // The interval * days starting today
 		return time.Date(n.Year(), n.Month(), n.Day()+interval, 0, 0, 0, 0, n.Location())
 	case ast.HH:
@@ -244,12 +276,68 @@ func getAlignedWindowEndTime(n time.Time, interval int, timeUnit ast.Token) time
 	}
 }
 
-func getFirstTimer(ctx api.StreamContext, rawInerval int, timeUnit ast.Token) (time.Time, *clock.Timer) {
-	next := getAlignedWindowEndTime(timex.GetNow(), rawInerval, timeUnit)
+func getFirstTimer(ctx api.StreamContext, rawInerval int, timeUnit ast.Token, loc *time.Location) (time.Time, *clock.Timer) {
+	next := getAlignedWindowEndTime(timex.GetNow().In(loc), rawInerval, timeUnit)
 	ctx.GetLogger().Infof("align window timer to %v(%d)", next, next.UnixMilli())
 	return next, timex.GetTimerByTime(next)
 }
 
+// addTimeUnit adds n units to t. The calendar units are added by the date in the location.
+func addTimeUnit(t time.Time, n int, timeUnit ast.Token, loc *time.Location) time.Time {
+	switch timeUnit {
+	case ast.MM:
+		return t.In(loc).AddDate(0, n, 0)
+	case ast.WW:
+		return t.In(loc).AddDate(0, 0, 7*n)
+	case ast.DD:
+		return t.In(loc).AddDate(0, 0, n)
+	case ast.HH:
+		return t.Add(time.Duration(n) * time.Hour)
+	case ast.MI:
+		return t.Add(time.Duration(n) * time.Minute)
+	case ast.SS:
+		return t.Add(time.Duration(n) * time.Second)
+	case ast.MS:
+		return t.Add(time.Duration(n) * time.Millisecond)
+	default:
+		return t
+	}
+}
+
+func (w *WindowConfig) location() *time.Location {
+	if w.Location != nil {
+		return w.Location
+	}
+	return cast.GetConfiguredTimeZone()
+}
+
+// isCalendar returns true if the window is aligned to the calendar, so its length varies by the date.
+// The sliding and session windows are relative to the events, so their units are always fixed durations.
+func (w *WindowConfig) isCalendar() bool {
+	switch w.Type {
+	case ast.TUMBLING_WINDOW, ast.HOPPING_WINDOW, ast.CUMULATE_WINDOW:
+		return w.TimeUnit.IsCalendarUnit()
+	default:
+		return false
+	}
+}
+
+// nextWindowEnd returns the next trigger time of the aligned window after the end
+func (w *WindowConfig) nextWindowEnd(end time.Time, interval time.Duration) time.Time {
+	if w.isCalendar() {
+		return addTimeUnit(end, w.RawInterval, w.TimeUnit, w.location())
+	}
+	return end.Add(interval)
+}
+
+// windowStart returns the start time of the aligned window which ends at the end
+func (w *WindowConfig) windowStart(end time.Time) time.Time {
+	if w.isCalendar() && w.RawLength > 0 {
+		return addTimeUnit(end, -w.RawLength, w.TimeUnit, w.location())
+	}
+	return end.Add(-w.Length)
+}
+
 func (o *WindowOperator) execProcessingWindow(ctx api.StreamContext, inputs []xsql.EventRow, errCh chan<- error) {
 	log := ctx.GetLogger()
 	var (
@@ -265,15 +353,15 @@ func (o *WindowOperator) execProcessingWindow(ctx api.StreamContext, inputs []xs
 	switch o.window.Type {
 	case ast.NOT_WINDOW:
 	case ast.TUMBLING_WINDOW:
-		firstTime, firstTicker = getFirstTimer(ctx, o.window.RawInterval, o.window.TimeUnit)
+		firstTime, firstTicker = getFirstTimer(ctx, o.window.RawInterval, o.window.TimeUnit, o.window.location())
 		o.interval = o.window.Length
 	case ast.HOPPING_WINDOW:
-		firstTime, firstTicker = getFirstTimer(ctx, o.window.RawInterval, o.window.TimeUnit)
+		firstTime, firstTicker = getFirstTimer(ctx, o.window.RawInterval, o.window.TimeUnit, o.window.location())
 		o.interval = o.window.Interval
 	case ast.SLIDING_WINDOW:
 		o.interval = o.window.Length
 	case ast.SESSION_WINDOW:
-		firstTime, firstTicker = getFirstTimer(ctx, o.window.RawInterval, o.window.TimeUnit)
+		firstTime, firstTicker = getFirstTimer(ctx, o.window.RawInterval, o.window.TimeUnit, o.window.location())
 		o.interval = o.window.Interval
 	case ast.COUNT_WINDOW:
 		o.interval = o.window.Interval
@@ -292,7 +380,7 @@ func (o *WindowOperator) execProcessingWindow(ctx api.StreamContext, inputs []xs
 			switch o.window.Type {
 			case ast.TUMBLING_WINDOW, ast.HOPPING_WINDOW:
 				for {
-					next = next.Add(o.interval)
+					next = o.window.nextWindowEnd(next, o.interval)
 					if next.After(nextTick) {
 						break
 					}
@@ -446,6 +534,13 @@ func (o *WindowOperator) execProcessingWindow(ctx api.StreamContext, inputs []xs
 		case now := <-firstC:
 			log.Infof("First tick at %v(%d), defined at %d", now, now.UnixMilli(), firstTime.UnixMilli())
 			firstTicker.Stop()
+			// The duration of calendar units varies, so align every tick to the calendar instead of using a ticker
+			if o.window.isCalendar() {
+				inputs = o.tick(ctx, inputs, firstTime, log)
+				firstTime, firstTicker = getFirstTimer(ctx, o.window.RawInterval, o.window.TimeUnit, o.window.location())
+				firstC = firstTicker.C
+				break
+			}
 			o.setupTicker()
 			c = o.ticker.C
 			inputs = o.tick(ctx, inputs, firstTime, log)
@@ -459,7 +554,7 @@ func (o *WindowOperator) execProcessingWindow(ctx api.StreamContext, inputs []xs
 			} else {
 				log.Infof("Skip the tick at %v(%d) since it's too late", now, now.UnixMilli())
 				o.ticker.Stop()
-				firstTime, firstTicker = getFirstTimer(ctx, o.window.RawInterval, o.window.TimeUnit)
+				firstTime, firstTicker = getFirstTimer(ctx, o.window.RawInterval, o.window.TimeUnit, o.window.location())
 				firstC = firstTicker.C
 			}
 		case now := <-timeout:
@@ -628,7 +723,7 @@ func (o *WindowOperator) handleInputs(ctx api.StreamContext, inputs []xsql.Event
 	}
 	content := make([]xsql.EventRow, 0, len(inputs))
 	// Sync table
-	left := right.Add(-length).Add(-delta)
+	left := o.window.windowStart(right).Add(-o.window.Delay).Add(-delta)
 	log.Debugf("triggerTime: %d, length: %d, delta: %d, leftmost: %d", right.UnixMilli(), length, delta, left.UnixMilli())
 	nextleft := -1
 	// this is to avoid always scan all tuples. better for performance if a window is big.
@@ -671,10 +766,10 @@ func (o *WindowOperator) handleInputs(ctx api.StreamContext, inputs []xsql.Event
 }
 
 func (o *WindowOperator) gcInputs(inputs []xsql.EventRow, triggerTime time.Time, ctx api.StreamContext) []xsql.EventRow {
-	length := o.window.Length + o.window.Delay
+	left := o.window.windowStart(triggerTime).Add(-o.window.Delay)
 	gcIndex := -1
 	for i, tuple := range inputs {
-		if tuple.GetTimestamp().Add(length).Compare(triggerTime) >= 0 {
+		if tuple.GetTimestamp().Compare(left) >= 0 {
 			break
 		}
 		gcIndex = i
@@ -718,6 +813,10 @@ func (o *WindowOperator) scan(inputs []xsql.EventRow, triggerTime time.Time, ctx
 	case ast.SLIDING_WINDOW:
 		windowStart = triggerTime.Add(-length).UnixMilli()
 	}
+	// The length of the calendar window varies, so its start is derived from the end by the calendar
+	if o.window.isCalendar() {
+		windowStart = o.window.windowStart(windowEnd).UnixMilli()
+	}
 	if windowStart <= 0 {
 		windowStart = windowEnd.Add(-length).UnixMilli()
 	}
@@ -730,6 +829,9 @@ func (o *WindowOperator) scan(inputs []xsql.EventRow, triggerTime time.Time, ctx
 	log.Debugf("Sent: %v", results)
 	o.Broadcast(results)
 	o.onSend(ctx, results)
+	if o.window.AllowedLateness > 0 {
+		o.keepLateWindow(ctx, o.window.windowStart(windowEnd), windowEnd, content)
+	}
 
 	o.triggerTime = triggerTime
 	log.Debugf("new trigger time %d", o.triggerTime.UnixMilli())
@@ -743,7 +845,7 @@ func (o *WindowOperator) calDelta(triggerTime time.Time, log api.Logger) time.Du
 		delta = math.MaxInt16 // max int, all events for the initial window
 	} else {
 		if !o.isEventTime && o.window.Interval > 0 {
-			delta = triggerTime.Sub(lastTriggerTime) - o.window.Interval
+			delta = triggerTime.Sub(o.window.nextWindowEnd(lastTriggerTime, o.window.Interval))
 			if delta > 100 {
 				log.Warnf("Possible long computation in window; Previous eviction time: %d, current eviction time: %d", lastTriggerTime.UnixMilli(), triggerTime.UnixMilli())
 			}
@@ -378,3 +378,164 @@ func TestGCInputsForConditionNotMatch(t *testing.T) {
 		},
 	}, inputs)
 }
+
+func TestCalendarWindowTime(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	tests := []struct {
+		name     string
+		n        time.Time
+		interval int
+		unit     ast.Token
+		end      time.Time
+	}{
+		{
+			name:     "day in time zone",
+			n:        time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC).In(shanghai),
+			interval: 1,
+			unit:     ast.DD,
+			end:      time.Date(2024, 1, 3, 0, 0, 0, 0, shanghai),
+		},
+		{
+			name:     "week starts on Monday",
+			n:        time.Date(2024, 3, 6, 10, 0, 0, 0, newYork),
+			interval: 1,
+			unit:     ast.WW,
+			end:      time.Date(2024, 3, 11, 0, 0, 0, 0, newYork),
+		},
+		{
+			name:     "month",
+			n:        time.Date(2024, 1, 31, 15, 0, 0, 0, shanghai),
+			interval: 1,
+			unit:     ast.MM,
+			end:      time.Date(2024, 2, 1, 0, 0, 0, 0, shanghai),
+		},
+		{
+			name:     "quarter",
+			n:        time.Date(2024, 11, 15, 0, 0, 0, 0, shanghai),
+			interval: 3,
+			unit:     ast.MM,
+			end:      time.Date(2025, 1, 1, 0, 0, 0, 0, shanghai),
+		},
+		// The cases below keep the alignment of the existing rules
+		{
+			name:     "hour at the boundary",
+			n:        time.Date(2024, 1, 1, 6, 0, 0, 0, shanghai),
+			interval: 6,
+			unit:     ast.HH,
+			end:      time.Date(2024, 1, 1, 6, 0, 0, 0, shanghai),
+		},
+		{
+			name:     "hour in the first window",
+			n:        time.Date(2024, 1, 1, 3, 20, 0, 0, shanghai),
+			interval: 6,
+			unit:     ast.HH,
+			end:      time.Date(2024, 1, 1, 6, 0, 0, 0, shanghai),
+		},
+		{
+			name:     "hour after the first window",
+			n:        time.Date(2024, 1, 1, 13, 20, 0, 0, shanghai),
+			interval: 6,
+			unit:     ast.HH,
+			end:      time.Date(2024, 1, 1, 18, 0, 0, 0, shanghai),
+		},
+		{
+			name:     "minute at the boundary",
+			n:        time.Date(2024, 1, 1, 10, 15, 0, 0, shanghai),
+			interval: 15,
+			unit:     ast.MI,
+			end:      time.Date(2024, 1, 1, 10, 15, 0, 0, shanghai),
+		},
+		{
+			name:     "minute after the first window",
+			n:        time.Date(2024, 1, 1, 10, 47, 0, 0, shanghai),
+			interval: 15,
+			unit:     ast.MI,
+			end:      time.Date(2024, 1, 1, 11, 0, 0, 0, shanghai),
+		},
+		{
+			name:     "second at the boundary",
+			n:        time.Date(2024, 1, 1, 10, 15, 10, 0, shanghai),
+			interval: 10,
+			unit:     ast.SS,
+			end:      time.Date(2024, 1, 1, 10, 15, 10, 0, shanghai),
+		},
+		{
+			name:     "second in the first window",
+			n:        time.Date(2024, 1, 1, 10, 15, 4, 0, shanghai),
+			interval: 10,
+			unit:     ast.SS,
+			end:      time.Date(2024, 1, 1, 10, 15, 10, 0, shanghai),
+		},
+		{
+			name:     "millisecond at the boundary",
+			n:        time.Date(2024, 1, 1, 10, 15, 4, 100*int(time.Millisecond), shanghai),
+			interval: 100,
+			unit:     ast.MS,
+			end:      time.Date(2024, 1, 1, 10, 15, 4, 100*int(time.Millisecond), shanghai),
+		},
+		{
+			name:     "millisecond after the first window",
+			n:        time.Date(2024, 1, 1, 10, 15, 4, 250*int(time.Millisecond), shanghai),
+			interval: 100,
+			unit:     ast.MS,
+			end:      time.Date(2024, 1, 1, 10, 15, 4, 300*int(time.Millisecond), shanghai),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.True(t, tt.end.Equal(getAlignedWindowEndTime(tt.n, tt.interval, tt.unit)))
+		})
+	}
+	// The day of DST change has 23 hours
+	w := &WindowConfig{Type: ast.TUMBLING_WINDOW, RawInterval: 1, RawLength: 1, TimeUnit: ast.DD, Length: 24 * time.Hour, Location: newYork}
+	start := time.Date(2024, 3, 10, 0, 0, 0, 0, newYork)
+	end := w.nextWindowEnd(start, w.Length)
+	require.Equal(t, 23*time.Hour, end.Sub(start))
+	require.True(t, start.Equal(w.windowStart(end)))
+	// The month window follows the month length in event time
+	w = &WindowConfig{Type: ast.TUMBLING_WINDOW, RawInterval: 1, RawLength: 1, TimeUnit: ast.MM, Length: 30 * 24 * time.Hour, Location: shanghai}
+	trigger, err := NewEventTimeTrigger(w)
+	require.NoError(t, err)
+	next := trigger.getNextWindow(nil, time.Date(2024, 2, 1, 0, 0, 0, 0, shanghai), time.Date(2024, 2, 10, 0, 0, 0, 0, shanghai))
+	require.True(t, time.Date(2024, 3, 1, 0, 0, 0, 0, shanghai).Equal(next))
+	// The hopping window start follows the calendar
+	w = &WindowConfig{Type: ast.HOPPING_WINDOW, RawInterval: 1, RawLength: 3, TimeUnit: ast.MM, Location: shanghai}
+	require.True(t, time.Date(2024, 1, 1, 0, 0, 0, 0, shanghai).Equal(w.windowStart(time.Date(2024, 4, 1, 0, 0, 0, 0, shanghai))))
+}
+
+func TestCalendarHoppingWindowInputs(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+	// The hopping window of 3 months which hops by 1 month, the fixed length of 90 days is shorter than the quarter
+	o := &WindowOperator{
+		defaultSinkNode: &defaultSinkNode{
+			defaultNode: &defaultNode{
+				name: "1",
+			},
+		},
+		window: &WindowConfig{
+			Type:        ast.HOPPING_WINDOW,
+			Length:      90 * 24 * time.Hour,
+			Interval:    30 * 24 * time.Hour,
+			RawLength:   3,
+			RawInterval: 1,
+			TimeUnit:    ast.MM,
+			Location:    shanghai,
+		},
+		isOverlapWindow: true,
+		triggerTime:     time.Date(2024, 3, 1, 0, 0, 0, 0, shanghai),
+	}
+	tuples := []xsql.EventRow{
+		&xsql.Tuple{Timestamp: time.Date(2023, 12, 31, 23, 0, 0, 0, shanghai)},
+		&xsql.Tuple{Timestamp: time.Date(2024, 1, 1, 1, 0, 0, 0, shanghai)},
+		&xsql.Tuple{Timestamp: time.Date(2024, 3, 31, 23, 0, 0, 0, shanghai)},
+		&xsql.Tuple{Timestamp: time.Date(2024, 4, 1, 1, 0, 0, 0, shanghai)},
+	}
+	inputs, discarded, content := o.handleInputs(context.Background(), tuples, time.Date(2024, 4, 1, 0, 0, 0, 0, shanghai))
+	require.Equal(t, tuples[1:3], content)
+	require.Equal(t, tuples[1:], inputs)
+	require.Equal(t, tuples[:1], discarded)
+}
@@ -15,12 +15,14 @@
 package node
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/infra"
 )
 
 type EventSlidingWindowOp struct {
@@ -94,6 +96,112 @@ func (s *EventSlidingWindowOp) exec(ctx api.StreamContext, errCh chan<- error) {
 	}
 }
 
+// EventCumulateWindowOp is the event time cumulate window. Each step is emitted once the watermark passes it.
+type EventCumulateWindowOp struct {
+	*WindowV2Operator
+	Length      time.Duration
+	Interval    time.Duration
+	windowStart time.Time
+	nextEmit    time.Time
+}
+
+// EventCumulateWindowState is the checkpoint state of the event time cumulate window.
+// The window start and the next step must be restored with the tuples, or the restored window emits wrong steps.
+type EventCumulateWindowState struct {
+	Scanner     *WindowScanner
+	WindowStart time.Time
+	NextEmit    time.Time
+}
+
+func NewEventCumulateWindowOp(o *WindowV2Operator) *EventCumulateWindowOp {
+	return &EventCumulateWindowOp{
+		WindowV2Operator: o,
+		Length:           o.windowConfig.Length,
+		Interval:         o.windowConfig.Interval,
+	}
+}
+
+func (c *EventCumulateWindowOp) exec(ctx api.StreamContext, errCh chan<- error) {
+	if err := c.restoreState(ctx); err != nil {
+		infra.DrainError(ctx, err, errCh)
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case input := <-c.input:
+			data, processed := c.ingest(ctx, input)
+			if processed {
+				continue
+			}
+			switch tuple := data.(type) {
+			case *xsql.WatermarkTuple:
+				c.emitUntil(ctx, tuple.GetTimestamp())
+				c.putState(ctx)
+			case *xsql.Tuple:
+				c.onProcessStart(ctx, input)
+				c.scanner.addTuple(tuple)
+				if c.windowStart.IsZero() {
+					c.startWindow(tuple.Timestamp)
+				}
+				c.putState(ctx)
+				c.onProcessEnd(ctx)
+			}
+		}
+	}
+}
+
+func (c *EventCumulateWindowOp) putState(ctx api.StreamContext) {
+	_ = ctx.PutState(V2WindowInputsKey, &EventCumulateWindowState{
+		Scanner:     c.scanner,
+		WindowStart: c.windowStart,
+		NextEmit:    c.nextEmit,
+	})
+}
+
+func (c *EventCumulateWindowOp) restoreState(ctx api.StreamContext) error {
+	v, err := ctx.GetState(V2WindowInputsKey)
+	if err != nil || v == nil {
+		return err
+	}
+	st, ok := v.(*EventCumulateWindowState)
+	if !ok {
+		return fmt.Errorf("restore cumulate window state %v error, invalid type", v)
+	}
+	if st.Scanner != nil {
+		c.scanner = st.Scanner
+	}
+	c.windowStart = st.WindowStart
+	c.nextEmit = st.NextEmit
+	ctx.GetLogger().Infof("Restore cumulate window state, window start: %d, next emit: %d", c.windowStart.UnixMilli(), c.nextEmit.UnixMilli())
+	return nil
+}
+
+func (c *EventCumulateWindowOp) startWindow(ts time.Time) {
+	c.windowStart = c.windowConfig.cumulateWindowStart(ts)
+	c.nextEmit = c.windowConfig.nextWindowEnd(c.windowStart, c.Interval)
+}
+
+// emitUntil emits all the steps which end before the watermark
+func (c *EventCumulateWindowOp) emitUntil(ctx api.StreamContext, watermark time.Time) {
+	for !c.windowStart.IsZero() && !c.nextEmit.After(watermark) {
+		c.emitCumulateWindow(ctx, c.windowStart, c.nextEmit)
+		windowEnd := c.windowConfig.cumulateWindowEnd(c.windowStart)
+		if c.nextEmit.Before(windowEnd) {
+			c.nextEmit = c.windowConfig.nextWindowEnd(c.nextEmit, c.Interval)
+			continue
+		}
+		c.scanner.gc(windowEnd.Add(-time.Nanosecond))
+		// skip the empty windows, the next window starts by the next tuple
+		if len(c.scanner.Tuples) == 0 {
+			c.windowStart = time.Time{}
+		} else {
+			c.startWindow(c.scanner.Tuples[0].Timestamp)
+		}
+	}
+}
+
 func (o *WindowV2Operator) ingest(ctx api.StreamContext, item any) (any, bool) {
 	ctx.GetLogger().Debugf("receive %v", item)
 	item, processed := o.preprocess(ctx, item)
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/benbjohnson/clock"
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
@@ -40,6 +41,7 @@ func init() {
 	gob.Register(time.Time{})
 	gob.Register(&StateWindowStatus{})
 	gob.Register(map[string]*StateWindowStatus{})
+	gob.Register(&EventCumulateWindowState{})
 }
 
 type WindowV2Operator struct {
@@ -63,6 +65,12 @@ func NewWindowV2Op(name string, w WindowConfig, options *def.RuleOption) (*Windo
 		}
 	case ast.STATE_WINDOW:
 		o.wExec = NewStateWindowOp(o)
+	case ast.CUMULATE_WINDOW:
+		if options.IsEventTime {
+			o.wExec = NewEventCumulateWindowOp(o)
+		} else {
+			o.wExec = NewCumulateWindowOp(o)
+		}
 	default:
 		return nil, fmt.Errorf("unsupported window type:%v", w.Type.String())
 	}
@@ -100,6 +108,20 @@ func (o *WindowV2Operator) emitWindow(ctx api.StreamContext, startTime, endTime
 	o.onSend(ctx, results)
 }
 
+// emitCumulateWindow emits the tuples in the left-closed, right-open range
+func (o *WindowV2Operator) emitCumulateWindow(ctx api.StreamContext, startTime, endTime time.Time) {
+	tuples := o.scanner.scanRange(startTime, endTime)
+	results := &xsql.WindowTuples{
+		Content: make([]xsql.Row, 0, len(tuples)),
+	}
+	for _, tuple := range tuples {
+		results.Content = append(results.Content, tuple)
+	}
+	results.WindowRange = xsql.NewWindowRange(startTime.UnixMilli(), endTime.UnixMilli(), endTime.UnixMilli())
+	o.Broadcast(results)
+	o.onSend(ctx, results)
+}
+
 type WindowV2Exec interface {
 	exec(ctx api.StreamContext, errCh chan<- error)
 }
@@ -314,6 +336,110 @@ func (s *SlidingWindowOp) exec(ctx api.StreamContext, errCh chan<- error) {
 	}
 }
 
+// CumulateWindowOp is the processing time cumulate window. The window is aligned to the size.
+// Each step emits all the tuples from the window start, and the window resets when reaching the size.
+type CumulateWindowOp struct {
+	*WindowV2Operator
+	Length      time.Duration
+	Interval    time.Duration
+	windowStart time.Time
+}
+
+func NewCumulateWindowOp(o *WindowV2Operator) *CumulateWindowOp {
+	return &CumulateWindowOp{
+		WindowV2Operator: o,
+		Length:           o.windowConfig.Length,
+		Interval:         o.windowConfig.Interval,
+	}
+}
+
+func (c *CumulateWindowOp) exec(ctx api.StreamContext, errCh chan<- error) {
+	v, err := ctx.GetState(V2WindowInputsKey)
+	if err == nil && v != nil {
+		if scanner, ok := v.(*WindowScanner); ok {
+			c.scanner = scanner
+		}
+	}
+	c.windowStart = c.windowConfig.cumulateWindowStart(timex.GetNow())
+	// align the first step to the nature time, then trigger by the step interval
+	nextTime, firstTimer := getFirstTimer(ctx, c.windowConfig.RawInterval, c.windowConfig.TimeUnit, c.windowConfig.location())
+	var (
+		ticker *clock.Ticker
+		tickC  <-chan time.Time
+	)
+	firstC := firstTimer.C
+	defer func() {
+		firstTimer.Stop()
+		if ticker != nil {
+			ticker.Stop()
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-firstC:
+			c.trigger(ctx, nextTime)
+			// The duration of calendar units varies, so align every step to the calendar instead of using a ticker
+			if c.windowConfig.isCalendar() {
+				firstTimer.Stop()
+				nextTime, firstTimer = getFirstTimer(ctx, c.windowConfig.RawInterval, c.windowConfig.TimeUnit, c.windowConfig.location())
+				firstC = firstTimer.C
+				break
+			}
+			firstC = nil
+			ticker = timex.GetTicker(c.Interval)
+			tickC = ticker.C
+		case <-tickC:
+			nextTime = nextTime.Add(c.Interval)
+			c.trigger(ctx, nextTime)
+		case input := <-c.input:
+			data, processed := c.commonIngest(ctx, input)
+			if processed {
+				continue
+			}
+			c.onProcessStart(ctx, input)
+			if row, ok := data.(*xsql.Tuple); ok {
+				c.scanner.addTuple(row)
+			}
+			ctx.PutState(V2WindowInputsKey, c.scanner)
+			c.onProcessEnd(ctx)
+		}
+	}
+}
+
+func (c *CumulateWindowOp) trigger(ctx api.StreamContext, stepEnd time.Time) {
+	c.emitCumulateWindow(ctx, c.windowStart, stepEnd)
+	if !stepEnd.Before(c.windowConfig.cumulateWindowEnd(c.windowStart)) {
+		c.scanner.gc(stepEnd.Add(-time.Nanosecond))
+		c.windowStart = c.windowConfig.cumulateWindowStart(stepEnd)
+		ctx.PutState(V2WindowInputsKey, c.scanner)
+	}
+}
+
+// cumulateWindowStart returns the start time of the cumulate window which contains n.
+// Like tumbling window, the window is aligned to the nature time by the size.
+func (w *WindowConfig) cumulateWindowStart(n time.Time) time.Time {
+	end := getAlignedWindowEndTime(n.In(w.location()), w.RawLength, w.TimeUnit)
+	for !end.After(n) {
+		end = w.cumulateWindowEnd(end)
+	}
+	start := w.windowStart(end)
+	for start.After(n) {
+		start = w.windowStart(start)
+	}
+	return start
+}
+
+// cumulateWindowEnd returns the end time of the cumulate window which starts at the start.
+// The calendar units are added by the date in the location, so that a day window follows the DST changes.
+func (w *WindowConfig) cumulateWindowEnd(start time.Time) time.Time {
+	if w.isCalendar() {
+		return addTimeUnit(start, w.RawLength, w.TimeUnit, w.location())
+	}
+	return start.Add(w.Length)
+}
+
 func isMatchCondition(ctx api.StreamContext, condition ast.Expr, fv *xsql.FunctionValuer, d *xsql.Tuple, stateFuncs []*ast.Call) bool {
 	if condition == nil {
 		return true
@@ -362,6 +488,20 @@ func (s *WindowScanner) scanWindow(windowStart, windowEnd time.Time) []*xsql.Tup
 	return result
 }
 
+// scan left-closed, right-open window
+func (s *WindowScanner) scanRange(windowStart, windowEnd time.Time) []*xsql.Tuple {
+	result := make([]*xsql.Tuple, 0)
+	for _, tuple := range s.Tuples {
+		if !tuple.Timestamp.Before(windowEnd) {
+			break
+		}
+		if !tuple.Timestamp.Before(windowStart) {
+			result = append(result, tuple)
+		}
+	}
+	return result
+}
+
 // gc the tuples which earlier than gcTime
 func (s *WindowScanner) gc(gcTime time.Time) {
 	if len(s.Tuples) < 1 {
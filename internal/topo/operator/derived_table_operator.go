@@ -0,0 +1,98 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+// DerivedTableOp converts the projected result of a sub query to new tuples emitted by the derived table.
+// The outer query then reads them like the tuples from a stream.
+// For a window result, each row becomes a tuple whose timestamp is the window end time,
+// so that the outer query can run another window on it. Otherwise, the event time of the input is kept.
+type DerivedTableOp struct {
+	Name string
+}
+
+type timestamped interface {
+	GetTimestamp() time.Time
+}
+
+func (p *DerivedTableOp) Apply(ctx api.StreamContext, data any, _ *xsql.FunctionValuer, _ *xsql.AggregateFunctionValuer) any {
+	ctx.GetLogger().Debugf("derived table %s receive %v", p.Name, data)
+	switch input := data.(type) {
+	case error:
+		return input
+	case xsql.Row:
+		var ts time.Time
+		if t, ok := input.(timestamped); ok {
+			ts = t.GetTimestamp()
+		}
+		return &xsql.Tuple{Ctx: input.GetTracerCtx(), Emitter: p.Name, Message: input.ToMap(), Timestamp: ts}
+	case xsql.Collection:
+		ts := collectionTimestamp(input)
+		maps := input.ToMaps()
+		result := make([]xsql.Row, 0, len(maps))
+		for _, m := range maps {
+			result = append(result, &xsql.Tuple{Ctx: input.GetTracerCtx(), Emitter: p.Name, Message: m, Timestamp: ts})
+		}
+		return result
+	default:
+		return fmt.Errorf("run derived table error: invalid input %[1]T(%[1]v)", input)
+	}
+}
+
+// collectionTimestamp returns the window end time of a window result. A collection without window, such as the join of
+// a stream and a table, is produced by the events, so the latest event time is used.
+func collectionTimestamp(input xsql.Collection) time.Time {
+	if wr := input.GetWindowRange(); wr != nil {
+		if end, ok := wr.FuncValue("window_end"); ok {
+			return time.UnixMilli(end.(int64))
+		}
+	}
+	var ts time.Time
+	_ = input.Range(func(_ int, r xsql.ReadonlyRow) (bool, error) {
+		if t := rowTimestamp(r); t.After(ts) {
+			ts = t
+		}
+		return true, nil
+	})
+	if ts.IsZero() {
+		ts = timex.GetNow()
+	}
+	return ts
+}
+
+func rowTimestamp(r any) time.Time {
+	switch t := r.(type) {
+	case timestamped:
+		return t.GetTimestamp()
+	case *xsql.JoinTuple:
+		var ts time.Time
+		for _, jr := range t.Tuples {
+			if rt := rowTimestamp(jr); rt.After(ts) {
+				ts = rt
+			}
+		}
+		return ts
+	}
+	return time.Time{}
+}
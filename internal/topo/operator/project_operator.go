@@ -39,6 +39,10 @@ type ProjectOp struct {
 	IsAggregate bool // Whether the project is used in an aggregate context. This is set by planner by analyzing the SQL query
 	EnableLimit bool
 	LimitCount  int
+	// LimitOffset is the number of rows to skip before applying the limit
+	LimitOffset int
+	// Distinct removes the duplicated rows of a collection after projection. The limit is applied after the deduplication.
+	Distinct bool
 
 	SendMeta bool
 	SendNil  bool
@@ -76,24 +80,38 @@ func (pp *ProjectOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.Fun
 			}
 		}
 	case xsql.Collection:
-		var err error
+		var (
+			err   error
+			keys  []string
+			count int
+		)
+		// the rows after offset+limit are not needed if no deduplication
+		end := -1
+		if !pp.Distinct && pp.EnableLimit && pp.LimitCount > 0 {
+			end = pp.LimitOffset + pp.LimitCount
+		}
 		if pp.IsAggregate {
 			input.SetIsAgg(true)
 			err = input.GroupRange(func(i int, aggRow xsql.CollectionRow) (bool, error) {
-				if pp.EnableLimit && pp.LimitCount > 0 && i >= pp.LimitCount {
+				if end >= 0 && i >= end {
 					return false, nil
 				}
+				count++
 				ve := pp.getVE(aggRow, aggRow, input.GetWindowRange(), fv, afv)
 				if err := pp.project(aggRow, ve); err != nil {
 					return false, fmt.Errorf("run Select error: %s", err)
 				}
+				if pp.Distinct {
+					keys = append(keys, cast.ToKey(aggRow.ToMap()))
+				}
 				return true, nil
 			})
 		} else {
 			err = input.RangeSet(func(i int, row xsql.Row) (bool, error) {
-				if pp.EnableLimit && pp.LimitCount > 0 && i >= pp.LimitCount {
+				if end >= 0 && i >= end {
 					return false, nil
 				}
+				count++
 				aggData, ok := input.(xsql.AggregateData)
 				if !ok {
 					return false, fmt.Errorf("unexpected type, cannot find aggregate data")
@@ -102,18 +120,58 @@ func (pp *ProjectOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.Fun
 				if err := pp.project(row, ve); err != nil {
 					return false, fmt.Errorf("run Select error: %s", err)
 				}
+				if pp.Distinct {
+					keys = append(keys, cast.ToKey(row.ToMap()))
+				}
 				return true, nil
 			})
 		}
 		if err != nil {
 			return err
 		}
+		return pp.selectRows(input, count, keys)
 	default:
 		return fmt.Errorf("run Select error: invalid input %[1]T(%[1]v)", input)
 	}
 	return data
 }
 
+// selectRows applies distinct, offset and limit to the projected rows of the collection.
+// The count is the number of projected rows and keys are the distinct keys of them in order.
+func (pp *ProjectOp) selectRows(input xsql.Collection, count int, keys []string) any {
+	indexes := make([]int, 0, count)
+	if pp.Distinct {
+		keyset := make(map[string]struct{}, len(keys))
+		for i, key := range keys {
+			if _, ok := keyset[key]; !ok {
+				keyset[key] = struct{}{}
+				indexes = append(indexes, i)
+			}
+		}
+	} else {
+		for i := 0; i < count; i++ {
+			indexes = append(indexes, i)
+		}
+	}
+	if pp.LimitOffset > 0 {
+		if pp.LimitOffset >= len(indexes) {
+			return []xsql.Row{}
+		}
+		indexes = indexes[pp.LimitOffset:]
+	}
+	if pp.EnableLimit && pp.LimitCount > 0 && len(indexes) > pp.LimitCount {
+		indexes = indexes[:pp.LimitCount]
+	}
+	// The aggregate without group by projects the whole collection to a single row, which is kept if not skipped by the offset
+	if _, grouped := input.(*xsql.GroupedTuplesSet); pp.IsAggregate && !grouped {
+		return input
+	}
+	if len(indexes) == input.Len() {
+		return input
+	}
+	return input.Filter(indexes)
+}
+
 func (pp *ProjectOp) getVE(tuple xsql.RawRow, agg xsql.AggregateData, wr *xsql.WindowRange, fv *xsql.FunctionValuer, afv *xsql.AggregateFunctionValuer) *xsql.ValuerEval {
 	afv.SetData(agg)
 	if pp.IsAggregate {
@@ -3358,3 +3358,66 @@ func TestSliceNilField(t *testing.T) {
 		})
 	}
 }
+
+func TestProjectDistinct(t *testing.T) {
+	data := &xsql.WindowTuples{
+		Content: []xsql.Row{
+			&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"a": 1}},
+			&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"a": "1"}},
+			&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"a": 1.0}},
+			&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"a": "1"}},
+		},
+	}
+	tests := []struct {
+		sql    string
+		result []map[string]interface{}
+	}{
+		{
+			sql:    "SELECT DISTINCT a FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10)",
+			result: []map[string]interface{}{{"a": 1}, {"a": "1"}},
+		},
+		{
+			sql:    "SELECT count(DISTINCT a) AS c FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10)",
+			result: []map[string]interface{}{{"c": 2}},
+		},
+	}
+	ctx := context.WithValue(context.Background(), context.LoggerKey, conf.Log.WithField("rule", "TestProjectDistinct"))
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			pp := &ProjectOp{IsAggregate: xsql.WithAggFields(stmt), Distinct: stmt.Distinct}
+			parseStmt(pp, stmt.Fields)
+			fv, afv := xsql.NewFunctionValuersForOp(nil)
+			result, err := parseResult(pp.Apply(ctx, data, fv, afv), pp.IsAggregate)
+			require.NoError(t, err)
+			require.Equal(t, tt.result, result)
+		})
+	}
+}
+
+func TestProjectAggregateOffset(t *testing.T) {
+	ctx := context.WithValue(context.Background(), context.LoggerKey, conf.Log.WithField("rule", "TestProjectAggregateOffset"))
+	stmt, err := xsql.NewParser(strings.NewReader("SELECT count(*) AS c FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10)")).Parse()
+	require.NoError(t, err)
+	newData := func() *xsql.WindowTuples {
+		return &xsql.WindowTuples{
+			Content: []xsql.Row{
+				&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"a": 1}},
+				&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"a": 2}},
+			},
+		}
+	}
+	fv, afv := xsql.NewFunctionValuersForOp(nil)
+
+	pp := &ProjectOp{IsAggregate: true, EnableLimit: true, LimitCount: 1}
+	parseStmt(pp, stmt.Fields)
+	result, err := parseResult(pp.Apply(ctx, newData(), fv, afv), pp.IsAggregate)
+	require.NoError(t, err)
+	require.Equal(t, []map[string]any{{"c": 2}}, result)
+
+	// The single aggregate row is skipped by the offset
+	pp = &ProjectOp{IsAggregate: true, EnableLimit: true, LimitCount: 1, LimitOffset: 1}
+	parseStmt(pp, stmt.Fields)
+	require.Equal(t, []xsql.Row{}, pp.Apply(ctx, newData(), fv, afv))
+}
@@ -26,6 +26,8 @@ type ProjectSetOperator struct {
 	SrfMapping  map[string]struct{}
 	EnableLimit bool
 	LimitCount  int
+	// LimitOffset is the number of extracted rows to skip before applying the limit
+	LimitOffset int
 }
 
 // Apply implement UnOperation
@@ -51,9 +53,9 @@ func (ps *ProjectSetOperator) Apply(ctx api.StreamContext, data interface{}, _ *
 		}
 		return results.rows
 	case xsql.Collection:
-		if ps.EnableLimit && ps.LimitCount > 0 && input.Len() > ps.LimitCount {
-			sel := make([]int, 0, ps.LimitCount)
-			for i := 0; i < ps.LimitCount; i++ {
+		if ps.EnableLimit && ps.LimitCount > 0 && input.Len() > ps.LimitOffset+ps.LimitCount {
+			sel := make([]int, 0, ps.LimitOffset+ps.LimitCount)
+			for i := 0; i < ps.LimitOffset+ps.LimitCount; i++ {
 				sel = append(sel, i)
 			}
 			input = input.Filter(sel)
@@ -61,10 +63,14 @@ func (ps *ProjectSetOperator) Apply(ctx api.StreamContext, data interface{}, _ *
 		if err := ps.handleSRFRowForCollection(ctx, input); err != nil {
 			return err
 		}
-		if ps.EnableLimit && ps.LimitCount > 0 && input.Len() > ps.LimitCount {
-			sel := make([]int, ps.LimitCount)
-			for i := 0; i < ps.LimitCount; i++ {
-				sel[i] = i
+		start, end := ps.limitRange(input.Len())
+		if start == end {
+			return []xsql.Row{}
+		}
+		if end-start < input.Len() {
+			sel := make([]int, 0, end-start)
+			for i := start; i < end; i++ {
+				sel = append(sel, i)
 			}
 			return input.Filter(sel)
 		}
@@ -74,6 +80,19 @@ func (ps *ProjectSetOperator) Apply(ctx api.StreamContext, data interface{}, _ *
 	}
 }
 
+// limitRange returns the range of the rows to keep after applying the offset and limit to n rows
+func (ps *ProjectSetOperator) limitRange(n int) (int, int) {
+	start := ps.LimitOffset
+	if start > n {
+		start = n
+	}
+	end := n
+	if ps.EnableLimit && ps.LimitCount > 0 && start+ps.LimitCount < n {
+		end = start + ps.LimitCount
+	}
+	return start, end
+}
+
 func (ps *ProjectSetOperator) handleSRFRowForCollection(ctx api.StreamContext, data xsql.Collection) error {
 	switch collection := data.(type) {
 	case *xsql.JoinTuples:
@@ -34,11 +34,18 @@ type streamInfo struct {
 
 // Analyze the select statement by decorating the info from stream statement.
 // Typically, set the correct stream name for fieldRefs
-func decorateStmt(s *ast.SelectStatement, opt *def.RuleOption, isTemp bool) ([]*streamInfo, []*ast.Call, []*ast.Call, error) {
-	streamsFromStmt := xsql.GetStreams(s)
+func decorateStmt(s *ast.SelectStatement, opt *def.RuleOption, isTemp bool, derived map[string]*derivedTable) ([]*streamInfo, []*ast.Call, []*ast.Call, error) {
+	streamsFromStmt := xsql.GetSourceNames(s)
 	streamStmts := make([]*streamInfo, len(streamsFromStmt))
 	isSchemaless := false
 	for i, s := range streamsFromStmt {
+		if dt, ok := derived[s]; ok {
+			streamStmts[i] = dt.info
+			if dt.info.schema == nil {
+				isSchemaless = true
+			}
+			continue
+		}
 		streamStmt, err := processor.GetStreamProcessorDataSource(s)
 		if err != nil {
 			return nil, nil, nil, fmt.Errorf("fail to get stream %s, please check if stream is created", s)
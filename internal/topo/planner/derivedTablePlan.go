@@ -0,0 +1,181 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/kv"
+)
+
+// DerivedTablePlan feeds the result of a sub query (derived table or CTE) to the outer query
+// as if it is a stream named by the alias. Its only child is the full plan of the sub query.
+type DerivedTablePlan struct {
+	baseLogicalPlan
+	name ast.StreamName
+}
+
+func (p DerivedTablePlan) Init() *DerivedTablePlan {
+	p.baseLogicalPlan.self = &p
+	p.baseLogicalPlan.setPlanType(DERIVEDTABLE)
+	return &p
+}
+
+func (p *DerivedTablePlan) BuildExplainInfo() {
+	p.baseLogicalPlan.ExplainInfo.Info = "Name: " + string(p.name)
+}
+
+// PushDownPredicate The sub query is optimized separately and the condition refers to the derived columns, so stop here.
+func (p *DerivedTablePlan) PushDownPredicate(condition ast.Expr) (ast.Expr, LogicalPlan) {
+	return condition, p.self
+}
+
+// PruneColumns The sub query has projected its columns already.
+func (p *DerivedTablePlan) PruneColumns(_ []ast.Expr) error {
+	return nil
+}
+
+type derivedTable struct {
+	plan LogicalPlan
+	info *streamInfo
+	// the streams used by the sub query
+	streams []string
+}
+
+// planDerivedTables plans the sub queries in the from/join clause separately.
+// The derived table is then bound in the outer query like a stream whose schema is the selected columns of the sub query.
+func planDerivedTables(stmt *ast.SelectStatement, opt *def.RuleOption, store kv.KeyValue, isTemp bool) (map[string]*derivedTable, error) {
+	var queries []*ast.SelectStatement
+	var names []string
+	for _, s := range stmt.Sources {
+		if t, ok := s.(*ast.Table); ok && t.Query != nil {
+			queries = append(queries, t.Query)
+			names = append(names, t.Name)
+		}
+	}
+	for _, j := range stmt.Joins {
+		if j.Query != nil {
+			queries = append(queries, j.Query)
+			names = append(names, j.Name)
+		}
+	}
+	if len(queries) == 0 {
+		return nil, nil
+	}
+	if opt.Experiment != nil && opt.Experiment.UseSliceTuple {
+		return nil, errors.New("slice tuple mode do not support sub query yet")
+	}
+	result := make(map[string]*derivedTable, len(queries))
+	// The source node is named by the stream name, so a stream can only be used once in the whole rule
+	used := make(map[string]string)
+	useStream := func(stream, by string) error {
+		if prev, ok := used[stream]; ok {
+			return fmt.Errorf("stream %s is used by both %s and %s, each stream can only be used once in a rule", stream, prev, by)
+		}
+		used[stream] = by
+		return nil
+	}
+	for i, q := range queries {
+		if _, ok := result[names[i]]; ok {
+			return nil, fmt.Errorf("duplicate derived table name %s", names[i])
+		}
+		streams := xsql.GetStreams(q)
+		for _, s := range streams {
+			if err := useStream(s, names[i]); err != nil {
+				return nil, err
+			}
+		}
+		lp, _, _, err := createLogicalPlanFull(q, opt, store, isTemp)
+		if err != nil {
+			return nil, fmt.Errorf("fail to plan sub query %s: %v", names[i], err)
+		}
+		if _, isUnion := lp.(*UnionPlan); !isUnion {
+			passWatermark(lp, names[i])
+		}
+		result[names[i]] = &derivedTable{
+			plan:    lp,
+			info:    derivedStreamInfo(names[i], q, lp),
+			streams: streams,
+		}
+	}
+	for _, name := range xsql.GetSourceNames(stmt) {
+		if _, ok := result[name]; !ok {
+			if err := useStream(name, "the outer query"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// passWatermark sends the watermarks of the sub query to the outer query, so that the outer query proceeds its event time
+// without waiting for the next row of the derived table. The nested derived tables have their own watermarks.
+func passWatermark(lp LogicalPlan, name string) {
+	switch p := lp.(type) {
+	case *DerivedTablePlan:
+		return
+	case *WatermarkPlan:
+		p.SendWatermark = true
+		p.DerivedTable = name
+	}
+	for _, c := range lp.Children() {
+		passWatermark(c, name)
+	}
+}
+
+// derivedStreamInfo builds the virtual stream definition for the derived table.
+// If the sub query selects wildcard, the derived table is schemaless.
+func derivedStreamInfo(name string, q *ast.SelectStatement, lp LogicalPlan) *streamInfo {
+	si := &streamInfo{
+		stmt: &ast.StreamStmt{
+			Name:       ast.StreamName(name),
+			StreamType: ast.TypeStream,
+			Options:    &ast.Options{},
+		},
+	}
+	schemas := make(map[ast.StreamName]map[string]*ast.JsonStreamField)
+	collectStreamFields(lp, schemas)
+	fields := visibleFields(q)
+	sf := make(ast.StreamFields, 0, len(fields))
+	for _, f := range fields {
+		if isWildcardField(f) {
+			return si
+		}
+		sf = append(sf, ast.StreamField{
+			Name:      f.GetName(),
+			FieldType: &ast.BasicType{Type: ast.GetDataType(inferFieldType(f.Expr, schemas))},
+		})
+	}
+	si.schema = sf
+	return si
+}
+
+func hasDerivedTable(stmt *ast.SelectStatement) bool {
+	for _, s := range stmt.Sources {
+		if t, ok := s.(*ast.Table); ok && t.Query != nil {
+			return true
+		}
+	}
+	for _, j := range stmt.Joins {
+		if j.Query != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+func TestExplainDerivedTable(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+
+	testcases := []struct {
+		sql     string
+		explain string
+	}{
+		{
+			sql: `select a from (select a, b from stream where a > 1) as t where b > 2`,
+			explain: `{"op":"ProjectPlan_0","info":"Fields:[ t.a ]"}
+	{"op":"FilterPlan_1","info":"Condition:{ binaryExpr:{ t.b > 2 } }, "}
+			{"op":"DerivedTablePlan_2","info":"Name: t"}
+					{"op":"ProjectPlan_3","info":"Fields:[ stream.a, stream.b ]"}
+							{"op":"FilterPlan_4","info":"Condition:{ binaryExpr:{ stream.a > 1 } }, "}
+									{"op":"DataSourcePlan_5","info":"StreamName: stream, StreamFields:[ a, b ]"}`,
+		},
+		{
+			sql: `with t as (select a from stream union all select a from sharedStream) select count(*) from t group by countwindow(2)`,
+			explain: `{"op":"ProjectPlan_0","info":"Fields:[ Call:{ name:count, args:[*] } ]"}
+	{"op":"WindowPlan_1","info":"{ length:2, windowType:COUNT_WINDOW, limit: 0 }"}
+			{"op":"DerivedTablePlan_2","info":"Name: t"}
+					{"op":"UnionPlan_3","info":"Branches: 2, All: true"}
+							{"op":"ProjectPlan_4","info":"Fields:[ stream.a ]"}
+									{"op":"DataSourcePlan_5","info":"StreamName: stream, StreamFields:[ a ]"}
+
+							{"op":"ProjectPlan_6","info":"Fields:[ sharedStream.a ]"}
+									{"op":"DataSourcePlan_7","info":"StreamName: sharedStream, StreamFields:[ a ]"}`,
+		},
+	}
+	for _, tc := range testcases {
+		stmt, err := xsql.NewParser(strings.NewReader(tc.sql)).Parse()
+		require.NoError(t, err)
+		p, err := CreateLogicalPlan(stmt, &def.RuleOption{
+			PlanOptimizeStrategy: &def.PlanOptimizeStrategy{},
+		}, kv)
+		require.NoError(t, err)
+		explain, err := ExplainFromLogicalPlan(p, "")
+		require.NoError(t, err)
+		require.Equal(t, tc.explain, explain, tc.sql)
+	}
+}
+
+func TestDerivedTablePlanError(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+
+	testcases := []struct {
+		sql string
+		err string
+	}{
+		{
+			sql: `with t as (select a from stream) select t1.a from t as t1 inner join t as t2 on t1.a = t2.a group by tumblingwindow(ss, 10)`,
+			err: "CTE t can only be referenced once, but it is referenced as both t1 and t2",
+		},
+		{
+			sql: `with t as (select a from stream) select a from t union all select a from t`,
+			err: "CTE t can only be referenced once, but it is referenced as both t and t",
+		},
+		{
+			sql: `with t as (select a from stream), t2 as (select a from t) select t.a from t inner join t2 on t.a = t2.a group by tumblingwindow(ss, 10)`,
+			err: "CTE t can only be referenced once, but it is referenced as both t and t",
+		},
+		{
+			sql: `select t.a from (select a from stream) as t inner join stream on t.a = stream.a group by tumblingwindow(ss, 10)`,
+			err: "stream stream is used by both t and the outer query, each stream can only be used once in a rule",
+		},
+		{
+			sql: `select a from (select a from nonexist) as t`,
+			err: "fail to plan sub query t: fail to get stream nonexist, please check if stream is created",
+		},
+		{
+			sql: `select c from (select a, b from stream) as t`,
+			err: "unknown field c",
+		},
+	}
+	for _, tc := range testcases {
+		stmt, err := xsql.NewParser(strings.NewReader(tc.sql)).Parse()
+		require.NoError(t, err)
+		_, err = CreateLogicalPlan(stmt, &def.RuleOption{
+			PlanOptimizeStrategy: &def.PlanOptimizeStrategy{},
+		}, kv)
+		require.EqualError(t, err, tc.err, tc.sql)
+	}
+}
+
+func TestPlanCTETwice(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+
+	stmt, err := xsql.NewParser(strings.NewReader(`with t as (select a, b from stream where a > 1) select a from t where b > 2`)).Parse()
+	require.NoError(t, err)
+	explains := make([]string, 0, 2)
+	for i := 0; i < 2; i++ {
+		p, err := CreateLogicalPlan(stmt, &def.RuleOption{
+			PlanOptimizeStrategy: &def.PlanOptimizeStrategy{},
+		}, kv)
+		require.NoError(t, err)
+		explain, err := ExplainFromLogicalPlan(p, "")
+		require.NoError(t, err)
+		explains = append(explains, explain)
+		// the parsed statement is not modified by inlining
+		require.Len(t, stmt.With, 1)
+		require.Nil(t, stmt.Sources[0].(*ast.Table).Query)
+	}
+	require.Equal(t, explains[0], explains[1])
+}
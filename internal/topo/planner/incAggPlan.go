@@ -23,6 +23,7 @@ type IncWindowPlan struct {
 	Delay            int64
 	Interval         int // If interval is not set, it is equals to Length
 	TimeUnit         ast.Token
+	TimeZone         string
 	Dimensions       ast.Dimensions
 	IncAggFuncs      []*ast.Field
 	TriggerCondition ast.Expr
@@ -0,0 +1,189 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// IntervalJoinPlan joins two streams without window. A left row joins the right rows whose timestamp is in
+// [left timestamp + lower, left timestamp + upper]
+type IntervalJoinPlan struct {
+	baseLogicalPlan
+	from  *ast.Table
+	join  ast.Join
+	lower int64
+	upper int64
+}
+
+func (p IntervalJoinPlan) Init() *IntervalJoinPlan {
+	p.baseLogicalPlan.self = &p
+	p.baseLogicalPlan.setPlanType(INTERVALJOIN)
+	return &p
+}
+
+func (p *IntervalJoinPlan) BuildExplainInfo() {
+	info := "Join:{ joinType:" + p.join.JoinType.String()
+	if p.join.Expr != nil {
+		info += ", expr:" + p.join.Expr.String()
+	}
+	info += " }, Interval:[ " + strconv.FormatInt(p.lower, 10) + ", " + strconv.FormatInt(p.upper, 10) + " ]"
+	p.baseLogicalPlan.ExplainInfo.Info = info
+}
+
+func (p *IntervalJoinPlan) PushDownPredicate(condition ast.Expr) (ast.Expr, LogicalPlan) {
+	switch p.join.JoinType {
+	case ast.INNER_JOIN:
+		a := combine(condition, p.join.Expr)
+		multipleSourcesCondition, singleSourceCondition := extractCondition(a)
+		rest, _ := p.baseLogicalPlan.PushDownPredicate(singleSourceCondition)
+		p.join.Expr = combine(multipleSourcesCondition, rest)
+		return nil, p
+	default:
+		multipleSourcesCondition, singleSourceCondition := extractCondition(condition)
+		rest, _ := p.baseLogicalPlan.PushDownPredicate(singleSourceCondition)
+		return combine(multipleSourcesCondition, rest), p
+	}
+}
+
+func (p *IntervalJoinPlan) PruneColumns(fields []ast.Expr) error {
+	f := getFields(&p.join)
+	return p.baseLogicalPlan.PruneColumns(append(fields, f...))
+}
+
+// intervalCond is the interval condition found in the join condition
+type intervalCond struct {
+	// the bounds of right timestamp minus left timestamp in milliseconds
+	lower, upper int64
+	// the time fields of the left and right stream used in the condition
+	leftField, rightField string
+}
+
+// extractInterval finds the interval condition like b.ts BETWEEN a.ts - INTERVAL 5s AND a.ts + INTERVAL 10s in the join
+// condition. It returns nil if no interval condition is found.
+func extractInterval(from *ast.Table, join ast.Join) (*intervalCond, error) {
+	left, right := ast.StreamName(from.Name), ast.StreamName(join.Name)
+	if from.Alias != "" {
+		left = ast.StreamName(from.Alias)
+	}
+	if join.Alias != "" {
+		right = ast.StreamName(join.Alias)
+	}
+	var (
+		cond *intervalCond
+		err  error
+	)
+	if join.Expr == nil {
+		return nil, nil
+	}
+	ast.WalkFunc(join.Expr, func(n ast.Node) bool {
+		if cond != nil || err != nil {
+			return false
+		}
+		be, ok := n.(*ast.BinaryExpr)
+		if !ok {
+			return false
+		}
+		if be.OP == ast.AND {
+			return true
+		}
+		if be.OP != ast.BETWEEN {
+			return false
+		}
+		ref, ok := be.LHS.(*ast.FieldRef)
+		if !ok {
+			return false
+		}
+		bt, ok := be.RHS.(*ast.BetweenExpr)
+		if !ok {
+			return false
+		}
+		lr, lo, ok1 := timeOffset(bt.Lower)
+		hr, hi, ok2 := timeOffset(bt.Higher)
+		if !ok1 || !ok2 || lr.StreamName != hr.StreamName || lr.Name != hr.Name {
+			return false
+		}
+		switch {
+		case ref.StreamName == right && lr.StreamName == left:
+			cond = &intervalCond{lower: lo, upper: hi, leftField: lr.Name, rightField: ref.Name}
+		case ref.StreamName == left && lr.StreamName == right:
+			cond = &intervalCond{lower: -hi, upper: -lo, leftField: ref.Name, rightField: lr.Name}
+		default:
+			return false
+		}
+		if cond.lower > cond.upper {
+			err = fmt.Errorf("invalid interval join condition %s, the lower bound is greater than the upper bound", be)
+		}
+		return false
+	})
+	return cond, err
+}
+
+// validateIntervalJoin checks the interval condition is on the event time. The join evicts the rows by the tuple
+// timestamp, so the condition must compare the timestamp fields of both streams with event time enabled.
+func validateIntervalJoin(cond *intervalCond, from *ast.Table, join ast.Join, streamStmts []*streamInfo, isEventTime bool) error {
+	if !isEventTime {
+		return errors.New("interval join requires event time, please set isEventTime to true in the rule options")
+	}
+	for _, side := range []struct {
+		name  string
+		field string
+	}{{from.Name, cond.leftField}, {join.Name, cond.rightField}} {
+		var ts string
+		for _, s := range streamStmts {
+			if string(s.stmt.Name) == side.name && s.stmt.Options != nil {
+				ts = s.stmt.Options.TIMESTAMP
+				break
+			}
+		}
+		if ts == "" {
+			return fmt.Errorf("interval join requires stream %s to define the TIMESTAMP option", side.name)
+		}
+		if ts != side.field {
+			return fmt.Errorf("interval join condition must use the timestamp field %s of stream %s, but got %s", ts, side.name, side.field)
+		}
+	}
+	return nil
+}
+
+// timeOffset parses the bound expression like a.ts, a.ts + 1000 or a.ts - INTERVAL 5s to the field and the offset
+func timeOffset(expr ast.Expr) (*ast.FieldRef, int64, bool) {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return timeOffset(e.Expr)
+	case *ast.FieldRef:
+		return e, 0, true
+	case *ast.BinaryExpr:
+		ref, ok := e.LHS.(*ast.FieldRef)
+		if !ok {
+			return nil, 0, false
+		}
+		val, ok := e.RHS.(*ast.IntegerLiteral)
+		if !ok {
+			return nil, 0, false
+		}
+		switch e.OP {
+		case ast.ADD:
+			return ref, val.Val, true
+		case ast.SUB:
+			return ref, -val.Val, true
+		}
+	}
+	return nil, 0, false
+}
@@ -14,7 +14,10 @@
 
 package planner
 
-import "github.com/lf-edge/ekuiper/v2/pkg/ast"
+import (
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
 
 const (
 	DefaultRetainSize = 1
@@ -26,6 +29,8 @@ type JoinAlignPlan struct {
 	Emitters []string
 	// retain size for each table emitter
 	Sizes []int
+	// the table emitters joined by FOR SYSTEM_TIME AS OF
+	Temporals map[string]*node.TemporalTable
 }
 
 func (p *JoinAlignPlan) BuildExplainInfo() {
@@ -40,6 +45,20 @@ func (p *JoinAlignPlan) BuildExplainInfo() {
 		}
 		info += " ]"
 	}
+	if len(p.Temporals) != 0 {
+		info += ", Temporals:[ "
+		first := true
+		for _, emitter := range p.Emitters {
+			if tt, ok := p.Temporals[emitter]; ok {
+				if !first {
+					info += ", "
+				}
+				first = false
+				info += emitter + " AS OF " + tt.AsOf.String()
+			}
+		}
+		info += " ]"
+	}
 	p.baseLogicalPlan.ExplainInfo.Info = info
 }
 
@@ -64,3 +83,13 @@ func (p *JoinAlignPlan) PushDownPredicate(condition ast.Expr) (ast.Expr, Logical
 	}
 	return rest, p.self
 }
+
+// PruneColumns keeps the key fields of the temporal tables to maintain the versions
+func (p *JoinAlignPlan) PruneColumns(fields []ast.Expr) error {
+	for _, emitter := range p.Emitters {
+		if tt, ok := p.Temporals[emitter]; ok {
+			fields = append(fields, &ast.FieldRef{StreamName: ast.StreamName(emitter), Name: tt.Key})
+		}
+	}
+	return p.baseLogicalPlan.PruneColumns(fields)
+}
@@ -149,6 +149,7 @@ const (
 	HAVING        PlanType = "HavingPlan"
 	JOINALIGN     PlanType = "JoinAlignPlan"
 	JOIN          PlanType = "JoinPlan"
+	INTERVALJOIN  PlanType = "IntervalJoinPlan"
 	LOOKUP        PlanType = "LookupPlan"
 	ORDER         PlanType = "OrderPlan"
 	PROJECT       PlanType = "ProjectPlan"
@@ -158,4 +159,7 @@ const (
 	WATERMARK     PlanType = "WatermarkPlan"
 	IncAggWindow  PlanType = "IncAggWindowPlan"
 	AggFunc       PlanType = "AggFunc"
+	UNION         PlanType = "UnionPlan"
+	DERIVEDTABLE  PlanType = "DerivedTablePlan"
+	QUALIFY       PlanType = "QualifyPlan"
 )
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -26,6 +27,7 @@ import (
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 )
 
 func TestExplainPlan(t *testing.T) {
@@ -124,6 +126,301 @@ func TestExplainPlan(t *testing.T) {
 	}
 }
 
+func TestExplainDistinct(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+
+	testcases := []struct {
+		sql     string
+		explain string
+	}{
+		{
+			sql: `select distinct a from stream group by countwindow(2) limit 1`,
+			explain: `{"op":"ProjectPlan_0","info":"Fields:[ stream.a ], Distinct:true, Limit:1"}
+	{"op":"WindowPlan_1","info":"{ length:2, windowType:COUNT_WINDOW, limit: 0 }"}
+			{"op":"DataSourcePlan_2","info":"StreamName: stream, StreamFields:[ a ]"}`,
+		},
+		{
+			sql: `select count(distinct a), sum(a) from stream group by countwindow(2)`,
+			explain: `{"op":"ProjectPlan_0","info":"Fields:[ Call:{ name:bypass, args:[$$default.inc_agg_col_1] }, Call:{ name:bypass, args:[$$default.inc_agg_col_2] } ]"}
+	{"op":"IncAggWindowPlan_1","info":"wType:COUNT_WINDOW, funcs:[Call:{ name:inc_count_distinct, args:[stream.a] }->inc_agg_col_1,Call:{ name:inc_sum, args:[stream.a] }->inc_agg_col_2]"}
+			{"op":"DataSourcePlan_2","info":"StreamName: stream, StreamFields:[ a ]"}`,
+		},
+		{
+			sql: `select last_value(distinct a, true) from stream group by countwindow(2)`,
+			explain: `{"op":"ProjectPlan_0","info":"Fields:[ Call:{ name:last_value, distinct:true, args:[stream.a, true] } ]"}
+	{"op":"WindowPlan_1","info":"{ length:2, windowType:COUNT_WINDOW, limit: 0 }"}
+			{"op":"DataSourcePlan_2","info":"StreamName: stream, StreamFields:[ a ]"}`,
+		},
+	}
+	for _, tc := range testcases {
+		stmt, err := xsql.NewParser(strings.NewReader(tc.sql)).Parse()
+		require.NoError(t, err)
+		p, err := CreateLogicalPlan(stmt, &def.RuleOption{
+			PlanOptimizeStrategy: &def.PlanOptimizeStrategy{
+				EnableIncrementalWindow: true,
+			},
+		}, kv)
+		require.NoError(t, err)
+		explain, err := ExplainFromLogicalPlan(p, "")
+		require.NoError(t, err)
+		require.Equal(t, tc.explain, explain, tc.sql)
+	}
+}
+
+func TestExplainLimitOffsetAndQualify(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+
+	testcases := []struct {
+		sql     string
+		explain string
+	}{
+		{
+			sql: `select a from stream group by countwindow(2) limit 1 offset 1`,
+			explain: `{"op":"ProjectPlan_0","info":"Fields:[ stream.a ], Limit:1, Offset:1"}
+	{"op":"WindowPlan_1","info":"{ length:2, windowType:COUNT_WINDOW, limit: 0 }"}
+			{"op":"DataSourcePlan_2","info":"StreamName: stream, StreamFields:[ a ]"}`,
+		},
+		{
+			sql: `select a, b from stream group by countwindow(4) qualify row_number() over (partition by a order by b desc) <= 2 order by a`,
+			explain: `{"op":"ProjectPlan_0","info":"Fields:[ stream.a, stream.b ]"}
+	{"op":"OrderPlan_1","info":"SortFields:[ sortField:{ name:a, ascending:true, fieldExpr:{ stream.a } } ]"}
+			{"op":"QualifyPlan_2","info":"Condition:{ binaryExpr:{ Call:{ name:bypass, args:[wf_row_number_1] } <= 2 } }"}
+					{"op":"WindowFuncPlan_3","info":"windowFuncField:{name:wf_row_number_1, expr:Call:{ name:row_number }}"}
+							{"op":"WindowPlan_4","info":"{ length:4, windowType:COUNT_WINDOW, limit: 0 }"}
+									{"op":"DataSourcePlan_5","info":"StreamName: stream, StreamFields:[ a, b ]"}`,
+		},
+	}
+	for _, tc := range testcases {
+		stmt, err := xsql.NewParser(strings.NewReader(tc.sql)).Parse()
+		require.NoError(t, err)
+		p, err := CreateLogicalPlan(stmt, &def.RuleOption{
+			PlanOptimizeStrategy: &def.PlanOptimizeStrategy{},
+		}, kv)
+		require.NoError(t, err)
+		explain, err := ExplainFromLogicalPlan(p, "")
+		require.NoError(t, err)
+		require.Equal(t, tc.explain, explain, tc.sql)
+	}
+}
+
+func TestDistinctPlanError(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+
+	stmt, err := xsql.NewParser(strings.NewReader(`select distinct a from stream`)).Parse()
+	require.NoError(t, err)
+	_, err = CreateLogicalPlan(stmt, &def.RuleOption{
+		PlanOptimizeStrategy: &def.PlanOptimizeStrategy{},
+	}, kv)
+	require.EqualError(t, err, "SELECT DISTINCT must be used with a window or join")
+}
+
+func TestOffsetPlanError(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+
+	for _, sql := range []string{
+		`select a from stream limit 1 offset 1`,
+		`select unnest(a) from stream limit 1 offset 1`,
+	} {
+		stmt, err := xsql.NewParser(strings.NewReader(sql)).Parse()
+		require.NoError(t, err)
+		_, err = CreateLogicalPlan(stmt, &def.RuleOption{
+			PlanOptimizeStrategy: &def.PlanOptimizeStrategy{},
+		}, kv)
+		require.EqualError(t, err, "OFFSET must be used with a window or join", sql)
+	}
+}
+
+func TestAllowedLatenessPlanError(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+
+	testcases := []struct {
+		sql      string
+		strategy *def.PlanOptimizeStrategy
+		err      string
+	}{
+		{
+			sql:      `select count(*) from eventStream group by tumblingwindow(ss, 10)`,
+			strategy: &def.PlanOptimizeStrategy{},
+		},
+		{
+			sql:      `select count(*) from eventStream group by slidingwindow(ss, 10)`,
+			strategy: &def.PlanOptimizeStrategy{},
+			err:      "allowedLateness is only supported by tumbling window and hopping window, but got SLIDING_WINDOW",
+		},
+		{
+			sql:      `select count(*) from eventStream group by cumulatewindow(ss, 10, 2)`,
+			strategy: &def.PlanOptimizeStrategy{},
+			err:      "allowedLateness is only supported by tumbling window and hopping window, but got CUMULATE_WINDOW",
+		},
+		{
+			sql:      `select count(*) from eventStream group by tumblingwindow(ss, 10)`,
+			strategy: &def.PlanOptimizeStrategy{EnableIncrementalWindow: true},
+			err:      "allowedLateness is not supported by the incremental window, please disable the incremental computation",
+		},
+		{
+			sql:      `select count(*) from eventStream group by tumblingwindow(ss, 10)`,
+			strategy: &def.PlanOptimizeStrategy{WindowOption: &def.WindowOption{WindowVersion: "v2"}},
+			err:      "allowedLateness is not supported by the v2 window",
+		},
+	}
+	for _, tc := range testcases {
+		stmt, err := xsql.NewParser(strings.NewReader(tc.sql)).Parse()
+		require.NoError(t, err)
+		_, err = CreateLogicalPlan(stmt, &def.RuleOption{
+			IsEventTime:          true,
+			AllowedLateness:      cast.DurationConf(time.Second),
+			PlanOptimizeStrategy: tc.strategy,
+		}, kv)
+		if tc.err == "" {
+			require.NoError(t, err, tc.sql)
+		} else {
+			require.EqualError(t, err, tc.err, tc.sql)
+		}
+	}
+}
+
+func TestExplainIntervalJoin(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+
+	testcases := []struct {
+		sql         string
+		processTime bool
+		explain     string
+		err         string
+	}{
+		{
+			sql: `select eventStream.id, eventStream2.ts from eventStream inner join eventStream2 on eventStream.id = eventStream2.id and eventStream2.ts between eventStream.ts - INTERVAL 5s and eventStream.ts + INTERVAL 10s`,
+			explain: `{"op":"ProjectPlan_0","info":"Fields:[ eventStream.id, eventStream2.ts ]"}
+	{"op":"IntervalJoinPlan_1","info":"Join:{ joinType:INNER_JOIN, expr:binaryExpr:{ binaryExpr:{ eventStream.id = eventStream2.id } AND binaryExpr:{ eventStream2.ts BETWEEN betweenExpr:{ binaryExpr:{ eventStream.ts - 5000 }, binaryExpr:{ eventStream.ts + 10000 } } } } }, Interval:[ -5000, 10000 ]"}
+			{"op":"WatermarkPlan_2","info":"Emitters:[ eventStream, eventStream2 ], SendWatermark:true"}
+					{"op":"DataSourcePlan_3","info":"StreamName: eventStream, StreamFields:[ id, ts ]"}
+					{"op":"DataSourcePlan_4","info":"StreamName: eventStream2, StreamFields:[ id, ts ]"}`,
+		},
+		{
+			sql: `select * from eventStream left join eventStream2 on eventStream.ts between eventStream2.ts - 1000 and eventStream2.ts + 3000`,
+			explain: `{"op":"ProjectPlan_0","info":"Fields:[ * ]"}
+	{"op":"IntervalJoinPlan_1","info":"Join:{ joinType:LEFT_JOIN, expr:binaryExpr:{ eventStream.ts BETWEEN betweenExpr:{ binaryExpr:{ eventStream2.ts - 1000 }, binaryExpr:{ eventStream2.ts + 3000 } } } }, Interval:[ -3000, 1000 ]"}
+			{"op":"WatermarkPlan_2","info":"Emitters:[ eventStream, eventStream2 ], SendWatermark:true"}
+					{"op":"DataSourcePlan_3","info":"StreamName: eventStream, StreamFields:[ id, ts ]"}
+					{"op":"DataSourcePlan_4","info":"StreamName: eventStream2, StreamFields:[ id, ts ]"}`,
+		},
+		{
+			sql: `select * from eventStream inner join eventStream2 on eventStream2.ts between eventStream.ts + INTERVAL 5s and eventStream.ts - INTERVAL 5s`,
+			err: "invalid interval join condition binaryExpr:{ eventStream2.ts BETWEEN betweenExpr:{ binaryExpr:{ eventStream.ts + 5000 }, binaryExpr:{ eventStream.ts - 5000 } } }, the lower bound is greater than the upper bound",
+		},
+		{
+			sql:         `select * from eventStream inner join eventStream2 on eventStream2.ts between eventStream.ts - INTERVAL 5s and eventStream.ts + INTERVAL 10s`,
+			processTime: true,
+			err:         "interval join requires event time, please set isEventTime to true in the rule options",
+		},
+		{
+			sql: `select * from eventStream inner join eventStream2 on eventStream2.id between eventStream.ts - INTERVAL 5s and eventStream.ts + INTERVAL 10s`,
+			err: "interval join condition must use the timestamp field ts of stream eventStream2, but got id",
+		},
+		{
+			sql: `select * from eventStream inner join stream on stream.b between eventStream.ts - 1000 and eventStream.ts + 3000`,
+			err: "interval join requires stream stream to define the TIMESTAMP option",
+		},
+		{
+			sql: `select * from eventStream inner join eventStream2 on eventStream.id = eventStream2.id`,
+			err: "a time window, count window or interval condition is required to join multiple streams",
+		},
+	}
+	for _, tc := range testcases {
+		stmt, err := xsql.NewParser(strings.NewReader(tc.sql)).Parse()
+		require.NoError(t, err)
+		p, err := CreateLogicalPlan(stmt, &def.RuleOption{
+			IsEventTime:          !tc.processTime,
+			PlanOptimizeStrategy: &def.PlanOptimizeStrategy{},
+		}, kv)
+		if tc.err != "" {
+			require.EqualError(t, err, tc.err, tc.sql)
+			continue
+		}
+		require.NoError(t, err)
+		explain, err := ExplainFromLogicalPlan(p, "")
+		require.NoError(t, err)
+		require.Equal(t, tc.explain, explain, tc.sql)
+	}
+}
+
+func TestExplainTemporalJoin(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+
+	testcases := []struct {
+		sql       string
+		eventTime bool
+		explain   string
+		err       string
+	}{
+		{
+			sql:       `select eventStream.id, versionTable.v from eventStream left join versionTable for system_time as of eventStream.ts on eventStream.id = versionTable.id`,
+			eventTime: true,
+			explain: `{"op":"ProjectPlan_0","info":"Fields:[ eventStream.id, versionTable.v ]"}
+	{"op":"JoinPlan_1","info":"Joins:[ { joinType:LEFT_JOIN, binaryExpr:{ eventStream.id = versionTable.id } } ]"}
+			{"op":"JoinAlignPlan_2","info":"Emitters:[ versionTable ], Temporals:[ versionTable AS OF eventStream.ts ]"}
+					{"op":"WatermarkPlan_3","info":"Emitters:[ eventStream ], SendWatermark:true"}
+							{"op":"DataSourcePlan_4","info":"StreamName: eventStream, StreamFields:[ id, ts ]"}
+
+					{"op":"DataSourcePlan_5","info":"StreamName: versionTable, StreamFields:[ id, ts, v ]"}`,
+		},
+		{
+			sql: `select * from eventStream left join versionTable for system_time as of eventStream.ts on eventStream.id = versionTable.id`,
+			err: "FOR SYSTEM_TIME AS OF of table versionTable requires event time",
+		},
+		{
+			sql:       `select * from eventStream left join scanTable for system_time as of eventStream.ts on eventStream.id = scanTable.id`,
+			eventTime: true,
+			err:       "FOR SYSTEM_TIME AS OF of table scanTable requires the KEY option",
+		},
+		{
+			sql:       `select * from eventStream left join memlookup for system_time as of eventStream.ts on eventStream.id = memlookup.id`,
+			eventTime: true,
+			err:       "FOR SYSTEM_TIME AS OF is only supported for scan table, but got memlookup",
+		},
+		{
+			sql:       `select * from stream left join versionTable for system_time as of stream.b on stream.a = versionTable.id`,
+			eventTime: true,
+			err:       "FOR SYSTEM_TIME AS OF requires stream to define the TIMESTAMP option",
+		},
+		{
+			sql:       `select * from eventStream left join keyTable for system_time as of eventStream.ts on eventStream.id = keyTable.id`,
+			eventTime: true,
+			err:       "FOR SYSTEM_TIME AS OF requires keyTable to define the TIMESTAMP option",
+		},
+	}
+	for _, tc := range testcases {
+		stmt, err := xsql.NewParser(strings.NewReader(tc.sql)).Parse()
+		require.NoError(t, err)
+		p, err := CreateLogicalPlan(stmt, &def.RuleOption{
+			IsEventTime:          tc.eventTime,
+			PlanOptimizeStrategy: &def.PlanOptimizeStrategy{},
+		}, kv)
+		if tc.err != "" {
+			require.EqualError(t, err, tc.err, tc.sql)
+			continue
+		}
+		require.NoError(t, err)
+		explain, err := ExplainFromLogicalPlan(p, "")
+		require.NoError(t, err)
+		require.Equal(t, tc.explain, explain, tc.sql)
+	}
+}
+
 func prepareStream() error {
 	kv, err := store.GetKV("stream")
 	if err != nil {
@@ -143,6 +440,27 @@ func prepareStream() error {
 					b BIGINT,
 				) WITH (DATASOURCE="src1");`,
 		"memlookup": `CREATE TABLE memlookup() WITH (DATASOURCE="topicB", KEY="key" TYPE="memory", KIND="lookup")`,
+		"eventStream": `CREATE STREAM eventStream (
+					id BIGINT,
+					ts BIGINT,
+				) WITH (DATASOURCE="src2", TIMESTAMP="ts");`,
+		"eventStream2": `CREATE STREAM eventStream2 (
+					id BIGINT,
+					ts BIGINT,
+				) WITH (DATASOURCE="src3", TIMESTAMP="ts");`,
+		"versionTable": `CREATE TABLE versionTable (
+					id BIGINT,
+					v STRING,
+					ts BIGINT,
+				) WITH (DATASOURCE="topicC", KEY="id", TIMESTAMP="ts", TYPE="memory");`,
+		"keyTable": `CREATE TABLE keyTable (
+					id BIGINT,
+					v STRING,
+				) WITH (DATASOURCE="topicE", KEY="id", TYPE="memory");`,
+		"scanTable": `CREATE TABLE scanTable (
+					id BIGINT,
+					ts BIGINT,
+				) WITH (DATASOURCE="topicD", TIMESTAMP="ts", TYPE="memory");`,
 	}
 
 	types := map[string]ast.StreamType{
@@ -150,6 +468,11 @@ func prepareStream() error {
 		"stream":       ast.TypeStream,
 		"stream2":      ast.TypeStream,
 		"memlookup":    ast.TypeTable,
+		"eventStream":  ast.TypeStream,
+		"eventStream2": ast.TypeStream,
+		"versionTable": ast.TypeTable,
+		"scanTable":    ast.TypeTable,
+		"keyTable":     ast.TypeTable,
 	}
 	for name, sql := range streamSqls {
 		s, err := json.Marshal(&xsql.StreamInfo{
@@ -212,6 +535,27 @@ func TestSupportedWindowType(t *testing.T) {
 			},
 			ok: false,
 		},
+		{
+			w: &ast.Window{
+				WindowType: ast.TUMBLING_WINDOW,
+				TimeUnit:   &ast.TimeLiteral{Val: ast.DD},
+			},
+			ok: false,
+		},
+		{
+			w: &ast.Window{
+				WindowType: ast.CUMULATE_WINDOW,
+				TimeUnit:   &ast.TimeLiteral{Val: ast.DD},
+			},
+			ok: false,
+		},
+		{
+			w: &ast.Window{
+				WindowType: ast.SLIDING_WINDOW,
+				TimeUnit:   &ast.TimeLiteral{Val: ast.DD},
+			},
+			ok: true,
+		},
 	}
 	for _, tc := range testcases {
 		require.Equal(t, tc.ok, supportedWindowType(tc.w))
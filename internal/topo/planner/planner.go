@@ -231,7 +231,15 @@ func validateStmt(stmt *ast.SelectStatement) error {
 		}
 		return true
 	})
-	return vErr
+	if vErr != nil {
+		return vErr
+	}
+	for _, u := range stmt.Unions {
+		if err := validateStmt(u.Stmt); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func createTopo(rule *def.Rule, lp LogicalPlan, mockSourcesProp map[string]map[string]any, streamsFromStmt []string, schema map[string]*ast.JsonStreamField) (t *topo.Topo, err error) {
@@ -248,6 +256,9 @@ func createTopo(rule *def.Rule, lp LogicalPlan, mockSourcesProp map[string]map[s
 	}
 	tp.SetStreams(streamsFromStmt)
 	tp.SetSinkSchema(schema)
+	if err := buildDeadLetter(tp, rule); err != nil {
+		return nil, err
+	}
 
 	input, _, err := buildOps(lp, tp, rule.Options, mockSourcesProp, streamsFromStmt, 0)
 	if err != nil {
@@ -290,16 +301,19 @@ func GetExplainInfoFromLogicalPlan(rule *def.Rule) (string, error) {
 }
 
 func ExplainFromLogicalPlan(lp LogicalPlan, ruleID string) (string, error) {
-	var setId func(p LogicalPlan, id int64)
-	setId = func(p LogicalPlan, id int64) {
+	// The ids are allocated in pre-order so that they are unique even if a plan has several children with descendants
+	var (
+		id    int64
+		setId func(p LogicalPlan)
+	)
+	setId = func(p LogicalPlan) {
 		p.SetID(id)
-		children := p.Children()
-		for i := 0; i < len(children); i++ {
-			id++
-			setId(children[i], id)
+		id++
+		for _, c := range p.Children() {
+			setId(c)
 		}
 	}
-	setId(lp, 0)
+	setId(lp)
 	var getExplainInfo func(p LogicalPlan, level int) string
 	getExplainInfo = func(p LogicalPlan, level int) string {
 		tmp := ""
@@ -365,7 +379,11 @@ func buildOps(lp LogicalPlan, tp *topo.Topo, options *def.RuleOption, sources ma
 			newIndex += indexInc
 		}
 	case *WatermarkPlan:
-		op = node.NewWatermarkOp(fmt.Sprintf("%d_watermark", newIndex), t.SendWatermark, t.Emitters, options)
+		wop := node.NewWatermarkOp(fmt.Sprintf("%d_watermark", newIndex), t.SendWatermark, t.Emitters, t.OrderedEmitters, t.AllowedLateness, options)
+		if t.DerivedTable != "" {
+			wop.SetDerivedTable(t.DerivedTable)
+		}
+		op = wop
 	case *AnalyticFuncsPlan:
 		op = Transform(&operator.AnalyticFuncsOp{Funcs: t.funcs, FieldFuncs: t.fieldFuncs}, fmt.Sprintf("%d_analytic", newIndex), options)
 	case *IncWindowPlan:
@@ -375,11 +393,15 @@ func buildOps(lp LogicalPlan, tp *topo.Topo, options *def.RuleOption, sources ma
 			inputs = []node.Emitter{wfilterOp}
 		}
 		l, i, d := convertFromDuration(t.TimeUnit, t.Length, t.Interval, t.Delay)
+		loc, err := getWindowLocation(t.TimeZone)
+		if err != nil {
+			return nil, 0, err
+		}
 		var rawInterval int
 		switch t.WType {
 		case ast.TUMBLING_WINDOW, ast.SESSION_WINDOW:
 			rawInterval = t.Length
-		case ast.HOPPING_WINDOW:
+		case ast.HOPPING_WINDOW, ast.CUMULATE_WINDOW:
 			rawInterval = t.Interval
 		}
 		op, err = node.NewWindowIncAggOp(fmt.Sprintf("%d_inc_agg_window", newIndex), &node.WindowConfig{
@@ -388,6 +410,8 @@ func buildOps(lp LogicalPlan, tp *topo.Topo, options *def.RuleOption, sources ma
 			Length:           l,
 			Interval:         i,
 			RawInterval:      rawInterval,
+			RawLength:        t.Length,
+			Location:         loc,
 			CountLength:      t.Length,
 			TriggerCondition: t.TriggerCondition,
 			TimeUnit:         t.TimeUnit,
@@ -402,11 +426,15 @@ func buildOps(lp LogicalPlan, tp *topo.Topo, options *def.RuleOption, sources ma
 			inputs = []node.Emitter{wfilterOp}
 		}
 		l, i, d := convertFromDuration(t.timeUnit, t.length, t.interval, t.delay)
+		loc, err := getWindowLocation(t.timeZone)
+		if err != nil {
+			return nil, 0, err
+		}
 		var rawInterval int
 		switch t.wtype {
 		case ast.TUMBLING_WINDOW, ast.SESSION_WINDOW:
 			rawInterval = t.length
-		case ast.HOPPING_WINDOW:
+		case ast.HOPPING_WINDOW, ast.CUMULATE_WINDOW:
 			rawInterval = t.interval
 		}
 		t.ExtractStateFunc()
@@ -418,6 +446,9 @@ func buildOps(lp LogicalPlan, tp *topo.Topo, options *def.RuleOption, sources ma
 			CountInterval:    t.interval,
 			CountLength:      t.length,
 			RawInterval:      rawInterval,
+			RawLength:        t.length,
+			Location:         loc,
+			AllowedLateness:  t.allowedLateness,
 			TimeUnit:         t.timeUnit,
 			TriggerCondition: t.triggerCondition,
 			BeginCondition:   t.beginCondition,
@@ -426,8 +457,8 @@ func buildOps(lp LogicalPlan, tp *topo.Topo, options *def.RuleOption, sources ma
 			PartitionExpr:    t.PartitionExpr,
 			StateFuncs:       t.stateFuncs,
 		}
-		// state window only support v2 window
-		if wc.Type == ast.STATE_WINDOW {
+		// state window and cumulate window only support v2 window
+		if wc.Type == ast.STATE_WINDOW || wc.Type == ast.CUMULATE_WINDOW {
 			op, err = node.NewWindowV2Op(fmt.Sprintf("%d_window", newIndex), wc, options)
 			if err != nil {
 				return nil, 0, err
@@ -450,9 +481,11 @@ func buildOps(lp LogicalPlan, tp *topo.Topo, options *def.RuleOption, sources ma
 	case *LookupPlan:
 		op, err = planLookupSource(tp.GetContext(), t, options)
 	case *JoinAlignPlan:
-		op, err = node.NewJoinAlignNode(fmt.Sprintf("%d_join_aligner", newIndex), t.Emitters, t.Sizes, options)
+		op, err = node.NewJoinAlignNode(fmt.Sprintf("%d_join_aligner", newIndex), t.Emitters, t.Sizes, t.Temporals, options)
 	case *JoinPlan:
 		op = Transform(&operator.JoinOp{Joins: t.joins, From: t.from}, fmt.Sprintf("%d_join", newIndex), options)
+	case *IntervalJoinPlan:
+		op, err = node.NewIntervalJoinOp(fmt.Sprintf("%d_interval_join", newIndex), t.from, t.join, t.lower, t.upper, options)
 	case *AggFuncPlan:
 		op = Transform(&operator.AggFuncOp{AggFields: t.aggFields}, fmt.Sprintf("%d_agg_func", newIndex), options)
 	case *FilterPlan:
@@ -466,11 +499,17 @@ func buildOps(lp LogicalPlan, tp *topo.Topo, options *def.RuleOption, sources ma
 	case *OrderPlan:
 		op = Transform(&operator.OrderOp{SortFields: t.SortFields}, fmt.Sprintf("%d_order", newIndex), options)
 	case *ProjectPlan:
-		op = Transform(&operator.ProjectOp{Fields: t.fields, FieldLen: t.fieldLen, ColNames: t.colNames, AliasFields: t.aliasFields, ExprFields: t.exprFields, ExceptNames: t.exceptNames, IsAggregate: t.isAggregate, AllWildcard: t.allWildcard, WildcardEmitters: t.wildcardEmitters, SendMeta: t.sendMeta, SendNil: t.sendNil, LimitCount: t.limitCount, EnableLimit: t.enableLimit}, fmt.Sprintf("%d_project", newIndex), options)
+		op = Transform(&operator.ProjectOp{Fields: t.fields, FieldLen: t.fieldLen, ColNames: t.colNames, AliasFields: t.aliasFields, ExprFields: t.exprFields, ExceptNames: t.exceptNames, IsAggregate: t.isAggregate, AllWildcard: t.allWildcard, WildcardEmitters: t.wildcardEmitters, SendMeta: t.sendMeta, SendNil: t.sendNil, LimitCount: t.limitCount, LimitOffset: t.limitOffset, EnableLimit: t.enableLimit, Distinct: t.distinct}, fmt.Sprintf("%d_project", newIndex), options)
 	case *ProjectSetPlan:
-		op = Transform(&operator.ProjectSetOperator{SrfMapping: t.SrfMapping, LimitCount: t.limitCount, EnableLimit: t.enableLimit}, fmt.Sprintf("%d_projectset", newIndex), options)
+		op = Transform(&operator.ProjectSetOperator{SrfMapping: t.SrfMapping, LimitCount: t.limitCount, LimitOffset: t.limitOffset, EnableLimit: t.enableLimit}, fmt.Sprintf("%d_projectset", newIndex), options)
 	case *WindowFuncPlan:
 		op = Transform(&operator.WindowFuncOperator{WindowFuncField: t.windowFuncField}, fmt.Sprintf("%d_windowFunc", newIndex), options)
+	case *QualifyPlan:
+		op = Transform(&operator.FilterOp{Condition: t.condition}, fmt.Sprintf("%d_qualify", newIndex), options)
+	case *UnionPlan:
+		op = Transform(&operator.UnionOp{}, fmt.Sprintf("%d_union", newIndex), options)
+	case *DerivedTablePlan:
+		op = Transform(&operator.DerivedTableOp{Name: string(t.name)}, fmt.Sprintf("%d_derived_%s", newIndex, t.name), options)
 	default:
 		err = fmt.Errorf("unknown logical plan %v", t)
 	}
@@ -496,10 +535,43 @@ func convertFromDuration(timeUnit ast.Token, length, interval int, delay int64)
 		unit = time.Second
 	case ast.MS:
 		unit = time.Millisecond
+	// The calendar units are aligned by the date when running, the durations are only the estimations
+	case ast.WW:
+		unit = 7 * 24 * time.Hour
+	case ast.MM:
+		unit = 30 * 24 * time.Hour
 	}
 	return time.Duration(length) * unit, time.Duration(interval) * unit, time.Duration(delay) * unit
 }
 
+// getAllowedLateness returns the allowed lateness if the window can update the closed windows for the late events.
+// Only the v1 tumbling and hopping window without incremental computation support it.
+func getAllowedLateness(w *ast.Window, isIncAgg bool, opt *def.RuleOption) (time.Duration, error) {
+	if opt.AllowedLateness <= 0 {
+		return 0, nil
+	}
+	if isIncAgg {
+		return 0, errors.New("allowedLateness is not supported by the incremental window, please disable the incremental computation")
+	}
+	if opt.PlanOptimizeStrategy.GetWindowVersion() == "v2" {
+		return 0, errors.New("allowedLateness is not supported by the v2 window")
+	}
+	switch w.WindowType {
+	case ast.TUMBLING_WINDOW, ast.HOPPING_WINDOW:
+		return time.Duration(opt.AllowedLateness), nil
+	default:
+		return 0, fmt.Errorf("allowedLateness is only supported by tumbling window and hopping window, but got %s", w.WindowType)
+	}
+}
+
+// getWindowLocation loads the time zone to align the window. Return nil to use the configured time zone.
+func getWindowLocation(timeZone string) (*time.Location, error) {
+	if timeZone == "" {
+		return nil, nil
+	}
+	return time.LoadLocation(timeZone)
+}
+
 func CreateLogicalPlan(stmt *ast.SelectStatement, opt *def.RuleOption, store kv.KeyValue) (LogicalPlan, error) {
 	lp, _, _, err := createLogicalPlanFull(stmt, opt, store, false)
 	return lp, err
@@ -517,7 +589,57 @@ func checkSharedSourceOption(streams []*streamInfo, opt *def.RuleOption) error {
 	return nil
 }
 
+// getTemporalTables validates the joins with FOR SYSTEM_TIME AS OF and returns the temporal tables by name
+func getTemporalTables(from *ast.Table, joins ast.Joins, streams []*streamInfo, opt *def.RuleOption) (map[string]*node.TemporalTable, error) {
+	var temporals map[string]*node.TemporalTable
+	for _, join := range joins {
+		if join.AsOf == nil {
+			continue
+		}
+		stmt := findStreamStmt(streams, join.Name)
+		if stmt == nil || stmt.StreamType != ast.TypeTable || stmt.Options.KIND == ast.StreamKindLookup {
+			return nil, fmt.Errorf("FOR SYSTEM_TIME AS OF is only supported for scan table, but got %s", join.Name)
+		}
+		if !opt.IsEventTime {
+			return nil, fmt.Errorf("FOR SYSTEM_TIME AS OF of table %s requires event time", join.Name)
+		}
+		if stmt.Options.KEY == "" {
+			return nil, fmt.Errorf("FOR SYSTEM_TIME AS OF of table %s requires the KEY option", join.Name)
+		}
+		// The versions of the table are kept by the table timestamp and looked up by the stream timestamp
+		for _, name := range []string{from.Name, join.Name} {
+			if s := findStreamStmt(streams, name); s == nil || s.Options == nil || s.Options.TIMESTAMP == "" {
+				return nil, fmt.Errorf("FOR SYSTEM_TIME AS OF requires %s to define the TIMESTAMP option", name)
+			}
+		}
+		if temporals == nil {
+			temporals = make(map[string]*node.TemporalTable)
+		}
+		temporals[join.Name] = &node.TemporalTable{Key: stmt.Options.KEY, AsOf: join.AsOf}
+	}
+	return temporals, nil
+}
+
+func findStreamStmt(streams []*streamInfo, name string) *ast.StreamStmt {
+	for _, sInfo := range streams {
+		if string(sInfo.stmt.Name) == name {
+			return sInfo.stmt
+		}
+	}
+	return nil
+}
+
 func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store kv.KeyValue, isTemp bool) (LogicalPlan, []*ast.Call, []*ast.Call, error) {
+	if len(stmt.With) > 0 {
+		inlined, err := xsql.InlineCTEs(stmt)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		stmt = inlined
+	}
+	if len(stmt.Unions) > 0 {
+		return createUnionPlan(stmt, opt, store, isTemp)
+	}
 	dimensions := stmt.Dimensions
 	var (
 		p        LogicalPlan
@@ -528,11 +650,16 @@ func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store
 		scanTableEmitters   []string
 		scanTableSizes      []int
 		streamEmitters      []string
+		orderedEmitters     []string
 		w                   *ast.Window
 		ds                  ast.Dimensions
 	)
 
-	streamStmts, analyticFuncs, analyticFieldFuncs, err := decorateStmt(stmt, opt, isTemp)
+	derived, err := planDerivedTables(stmt, opt, store, isTemp)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	streamStmts, analyticFuncs, analyticFieldFuncs, err := decorateStmt(stmt, opt, isTemp, derived)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -543,6 +670,17 @@ func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store
 	rewriteRes := rewriteStmt(stmt, opt)
 
 	for _, sInfo := range streamStmts {
+		if dt, ok := derived[string(sInfo.stmt.Name)]; ok {
+			p = DerivedTablePlan{name: sInfo.stmt.Name}.Init()
+			p.SetChildren([]LogicalPlan{dt.plan})
+			children = append(children, p)
+			streamEmitters = append(streamEmitters, string(sInfo.stmt.Name))
+			// The rows of a sub query are sent in time order by its watermark, but the rows of the union branches are not
+			if _, isUnion := dt.plan.(*UnionPlan); !isUnion {
+				orderedEmitters = append(orderedEmitters, string(sInfo.stmt.Name))
+			}
+			continue
+		}
 		if sInfo.stmt.StreamType == ast.TypeTable && sInfo.stmt.Options.KIND == ast.StreamKindLookup {
 			if opt.Experiment != nil && opt.Experiment.UseSliceTuple {
 				return nil, nil, nil, fmt.Errorf("slice tuple mode do not support table yet %s", sInfo.stmt.Name)
@@ -584,13 +722,46 @@ func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store
 		}
 	}
 	hasWindow := dimensions != nil && dimensions.GetWindow() != nil
+	temporals, err := getTemporalTables(stmt.Sources[0].(*ast.Table), stmt.Joins, streamStmts, opt)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// Join two streams without window by the time interval condition
+	var intervalJoin *IntervalJoinPlan
+	if len(stmt.Joins) == 1 && !hasWindow && len(lookupTableChildren) == 0 && len(scanTableChildren) == 0 && stmt.Joins[0].JoinType != ast.CROSS_JOIN {
+		from := stmt.Sources[0].(*ast.Table)
+		cond, err := extractInterval(from, stmt.Joins[0])
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if cond != nil {
+			if err := validateIntervalJoin(cond, from, stmt.Joins[0], streamStmts, opt.IsEventTime); err != nil {
+				return nil, nil, nil, err
+			}
+			intervalJoin = IntervalJoinPlan{
+				from:  from,
+				join:  stmt.Joins[0],
+				lower: cond.lower,
+				upper: cond.upper,
+			}.Init()
+		}
+	}
+	var allowedLateness time.Duration
 	if opt.IsEventTime {
 		if opt.Experiment != nil && opt.Experiment.UseSliceTuple {
 			return nil, nil, nil, errors.New("slice tuple mode do not support event time yet")
 		}
+		if hasWindow {
+			allowedLateness, err = getAllowedLateness(dimensions.GetWindow(), len(rewriteRes.incAggFields) > 0, opt)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+		}
 		p = WatermarkPlan{
-			SendWatermark: hasWindow,
-			Emitters:      streamEmitters,
+			SendWatermark:   hasWindow || intervalJoin != nil || len(temporals) > 0,
+			Emitters:        streamEmitters,
+			OrderedEmitters: orderedEmitters,
+			AllowedLateness: allowedLateness,
 		}.Init()
 		p.SetChildren(children)
 		children = []LogicalPlan{p}
@@ -633,6 +804,9 @@ func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store
 				if w.TimeUnit != nil {
 					incWp.TimeUnit = w.TimeUnit.Val
 				}
+				if w.TimeZone != nil {
+					incWp.TimeZone = w.TimeZone.Val
+				}
 				incWp = incWp.Init()
 				incWp.SetChildren(children)
 				children = []LogicalPlan{incWp}
@@ -645,6 +819,7 @@ func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store
 					emitCondition:   w.EmitCondition,
 					singleCondition: w.SingleCondition,
 					PartitionExpr:   w.PartitionExpr,
+					allowedLateness: allowedLateness,
 				}.Init()
 				if w.Length != nil {
 					wp.length = int(w.Length.Val)
@@ -661,6 +836,9 @@ func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store
 				if w.TimeUnit != nil {
 					wp.timeUnit = w.TimeUnit.Val
 				}
+				if w.TimeZone != nil {
+					wp.timeZone = w.TimeZone.Val
+				}
 				if w.Filter != nil {
 					wp.condition = w.Filter
 				}
@@ -679,8 +857,12 @@ func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store
 		if opt.Experiment != nil && opt.Experiment.UseSliceTuple {
 			return nil, nil, nil, errors.New("slice tuple mode do not support join yet")
 		}
-		if len(lookupTableChildren) == 0 && len(scanTableChildren) == 0 && w == nil {
-			return nil, nil, nil, errors.New("a time window or count window is required to join multiple streams")
+		if intervalJoin != nil {
+			intervalJoin.SetChildren(children)
+			p = intervalJoin
+			children = []LogicalPlan{p}
+		} else if len(lookupTableChildren) == 0 && len(scanTableChildren) == 0 && w == nil {
+			return nil, nil, nil, errors.New("a time window, count window or interval condition is required to join multiple streams")
 		}
 		var lookupJoins ast.Joins
 		if len(lookupTableChildren) > 0 {
@@ -695,11 +877,12 @@ func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store
 			stmt.Joins = joins
 		}
 		// Not all joins are lookup joins, so we need to create a join plan for the remaining joins
-		if len(stmt.Joins) > 0 {
+		if len(stmt.Joins) > 0 && intervalJoin == nil {
 			if len(scanTableChildren) > 0 {
 				p = JoinAlignPlan{
-					Emitters: scanTableEmitters,
-					Sizes:    scanTableSizes,
+					Emitters:  scanTableEmitters,
+					Sizes:     scanTableSizes,
+					Temporals: temporals,
 				}.Init()
 				p.SetChildren(append(children, scanTableChildren...))
 				children = []LogicalPlan{p}
@@ -780,6 +963,16 @@ func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store
 			children = []LogicalPlan{p}
 		}
 	}
+	if stmt.Qualify != nil {
+		if opt.Experiment != nil && opt.Experiment.UseSliceTuple {
+			return nil, nil, nil, errors.New("slice tuple mode do not support qualify yet")
+		}
+		p = QualifyPlan{
+			condition: stmt.Qualify,
+		}.Init()
+		p.SetChildren(children)
+		children = []LogicalPlan{p}
+	}
 	if stmt.SortFields != nil {
 		if opt.Experiment != nil && opt.Experiment.UseSliceTuple {
 			return nil, nil, nil, errors.New("slice tuple mode do not support sort yet")
@@ -841,10 +1034,22 @@ func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store
 			}
 		}
 		enableLimit := false
-		limitCount := 0
+		limitCount, limitOffset := 0, 0
 		if stmt.Limit != nil && len(srfMapping) == 0 {
 			enableLimit = true
-			limitCount = int(stmt.Limit.(*ast.LimitExpr).LimitCount.Val)
+			limitCount, limitOffset, err = getLimit(stmt)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+		}
+		if stmt.Distinct {
+			if opt.Experiment != nil && opt.Experiment.UseSliceTuple {
+				return nil, nil, nil, errors.New("slice tuple mode do not support distinct yet")
+			}
+			// Each event is a single row without window or join, so there is nothing to deduplicate
+			if stmt.Dimensions.GetWindow() == nil && len(stmt.Joins) == 0 {
+				return nil, nil, nil, errors.New("SELECT DISTINCT must be used with a window or join")
+			}
 		}
 		p = ProjectPlan{
 			fields:      fields,
@@ -854,6 +1059,8 @@ func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store
 			sendNil:     opt.SendNil,
 			enableLimit: enableLimit,
 			limitCount:  limitCount,
+			limitOffset: limitOffset,
+			distinct:    stmt.Distinct,
 		}.Init()
 		p.SetChildren(children)
 		children = []LogicalPlan{p}
@@ -864,15 +1071,19 @@ func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store
 			return nil, nil, nil, errors.New("slice tuple mode do not support project set yet")
 		}
 		enableLimit := false
-		limitCount := 0
+		limitCount, limitOffset := 0, 0
 		if stmt.Limit != nil {
 			enableLimit = true
-			limitCount = int(stmt.Limit.(*ast.LimitExpr).LimitCount.Val)
+			limitCount, limitOffset, err = getLimit(stmt)
+			if err != nil {
+				return nil, nil, nil, err
+			}
 		}
 		p = ProjectSetPlan{
 			SrfMapping:  srfMapping,
 			enableLimit: enableLimit,
 			limitCount:  limitCount,
+			limitOffset: limitOffset,
 		}.Init()
 		p.SetChildren(children)
 	}
@@ -881,6 +1092,20 @@ func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store
 	return lp, analyticFuncs, analyticFieldFuncs, err
 }
 
+// getLimit returns the limit count and offset of the statement
+func getLimit(stmt *ast.SelectStatement) (int, int, error) {
+	le := stmt.Limit.(*ast.LimitExpr)
+	offset := 0
+	if le.Offset != nil {
+		// Each event is a single row without window or join, so there is nothing to skip
+		if stmt.Dimensions.GetWindow() == nil && len(stmt.Joins) == 0 {
+			return 0, 0, errors.New("OFFSET must be used with a window or join")
+		}
+		offset = int(le.Offset.Val)
+	}
+	return int(le.LimitCount.Val), offset, nil
+}
+
 // extractSRFMapping extracts the set-returning-function in the field
 func extractSRFMapping(stmt *ast.SelectStatement) (map[string]struct{}, error) {
 	srfFuncCnt := 0
@@ -915,14 +1140,16 @@ func Transform(op node.UnOperation, name string, options *def.RuleOption) *node.
 func extractWindowFuncFields(stmt *ast.SelectStatement) []*ast.Field {
 	windowFuncFields := make([]*ast.Field, 0)
 	windowFunctionCount := 0
-	ast.WalkFunc(stmt.Fields, func(n ast.Node) bool {
+	extract := func(n ast.Node) bool {
 		switch wf := n.(type) {
 		case *ast.Call:
 			if wf.FuncType == ast.FuncTypeWindow {
 				newWf := &ast.Call{
-					Name:     wf.Name,
-					FuncType: wf.FuncType,
-					Args:     wf.Args,
+					Name:       wf.Name,
+					FuncType:   wf.FuncType,
+					Args:       wf.Args,
+					Partition:  wf.Partition,
+					SortFields: wf.SortFields,
 				}
 				windowFunctionCount++
 				newName := fmt.Sprintf("wf_%s_%d", wf.Name, windowFunctionCount)
@@ -938,7 +1165,10 @@ func extractWindowFuncFields(stmt *ast.SelectStatement) []*ast.Field {
 			}
 		}
 		return true
-	})
+	}
+	ast.WalkFunc(stmt.Fields, extract)
+	// window functions in the qualify clause are calculated before filtering
+	ast.WalkFunc(stmt.Qualify, extract)
 	return windowFuncFields
 }
 
@@ -979,7 +1209,7 @@ func extractNodeIncAgg(node ast.Node, index *int) ([]*ast.Field, bool) {
 		case *ast.Call:
 			if f.FuncType == ast.FuncTypeAgg {
 				hasAgg = true
-				if !function.IsSupportedIncAgg(f.Name) {
+				if !function.IsSupportedIncAgg(f.Name) || (f.Distinct && !function.IsSupportedIncDistinctAgg(f.Name)) {
 					canIncAgg = false
 					return false
 				}
@@ -1000,8 +1230,12 @@ func extractNodeIncAgg(node ast.Node, index *int) ([]*ast.Field, bool) {
 			if aggFunc.FuncType == ast.FuncTypeAgg {
 				if function.IsSupportedIncAgg(aggFunc.Name) {
 					*index++
+					incName := fmt.Sprintf("inc_%s", aggFunc.Name)
+					if aggFunc.Distinct {
+						incName += "_distinct"
+					}
 					newAggFunc := &ast.Call{
-						Name:     fmt.Sprintf("inc_%s", aggFunc.Name),
+						Name:     incName,
 						FuncType: ast.FuncTypeScalar,
 						Args:     aggFunc.Args,
 						FuncId:   *index,
@@ -1029,6 +1263,7 @@ func rewriteIntoBypass(newFieldRef *ast.FieldRef, f *ast.Call) {
 	f.FuncType = ast.FuncTypeScalar
 	f.Args = []ast.Expr{newFieldRef}
 	f.Name = "bypass"
+	f.Distinct = false
 }
 
 func supportedWindowType(window *ast.Window) bool {
@@ -1041,6 +1276,13 @@ func supportedWindowType(window *ast.Window) bool {
 			return false
 		}
 	}
+	// The incremental window triggers by ticker which cannot follow the variable calendar units
+	if window.TimeUnit != nil && window.TimeUnit.Val.IsCalendarUnit() {
+		switch window.WindowType {
+		case ast.TUMBLING_WINDOW, ast.HOPPING_WINDOW, ast.CUMULATE_WINDOW:
+			return false
+		}
+	}
 	return true
 }
 
@@ -1049,6 +1291,7 @@ var supportedWType = map[ast.WindowType]struct{}{
 	ast.SLIDING_WINDOW:  {},
 	ast.HOPPING_WINDOW:  {},
 	ast.TUMBLING_WINDOW: {},
+	ast.CUMULATE_WINDOW: {},
 }
 
 func rewriteIfPushdownAlias(stmt *ast.SelectStatement, opt *def.RuleOption) map[ast.StreamName]map[string]string {
@@ -1058,7 +1301,7 @@ func rewriteIfPushdownAlias(stmt *ast.SelectStatement, opt *def.RuleOption) map[
 	if !opt.PlanOptimizeStrategy.EnableAliasPushdown {
 		return nil
 	}
-	if hasWildcard(stmt) {
+	if hasWildcard(stmt) || hasDerivedTable(stmt) {
 		return nil
 	}
 	dsColAliasMapping := make(map[ast.StreamName]map[string]string)
@@ -56,6 +56,9 @@ func PlanByGraph(rule *def.Rule) (*topo.Topo, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := buildDeadLetter(tp, rule); err != nil {
+		return nil, err
+	}
 	var (
 		nodeMap             = make(map[string]node.TopNode)
 		sinks               = make(map[string]bool)
@@ -126,7 +129,7 @@ func PlanByGraph(rule *def.Rule) (*topo.Topo, error) {
 				if err != nil {
 					return nil, fmt.Errorf("parse watermark %s with %v error: %w", nodeName, gn.Props, err)
 				}
-				op := node.NewWatermarkOp(nodeName, n.SendWatermark, n.Emitters, rule.Options)
+				op := node.NewWatermarkOp(nodeName, n.SendWatermark, n.Emitters, nil, 0, rule.Options)
 				nodeMap[nodeName] = op
 			case "function":
 				fop, err := parseFunc(gn.Props, sourceNames)
@@ -695,14 +698,26 @@ func parseWindow(props map[string]interface{}) (*node.WindowConfig, error) {
 		case "ms":
 			unit = time.Millisecond
 			timeUnit = ast.MS
+		case "ww":
+			unit = 7 * 24 * time.Hour
+			timeUnit = ast.WW
+		case "mm":
+			unit = 30 * 24 * time.Hour
+			timeUnit = ast.MM
 		default:
 			return nil, fmt.Errorf("Invalid unit %s", n.Unit)
 		}
 		length = time.Duration(n.Size) * unit
 		interval = time.Duration(n.Interval) * unit
 	}
+	loc, err := getWindowLocation(n.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %s: %v", n.TimeZone, err)
+	}
 	return &node.WindowConfig{
 		RawInterval:   rawInterval,
+		RawLength:     n.Size,
+		Location:      loc,
 		Type:          wt,
 		Length:        length,
 		Interval:      interval,
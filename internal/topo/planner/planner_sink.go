@@ -1,4 +1,4 @@
-// Copyright 2024-2025 EMQ Technologies Co., Ltd.
+// Copyright 2024-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -22,12 +22,15 @@ import (
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
 	"github.com/lf-edge/ekuiper/v2/internal/binder/io"
+	"github.com/lf-edge/ekuiper/v2/internal/converter"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/topo"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/node"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/node/conf"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
 	"github.com/lf-edge/ekuiper/v2/pkg/model"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
 )
 
 // SinkPlanner is the planner for sink node. It transforms logical sink plan to multiple physical nodes.
@@ -55,6 +58,61 @@ func buildActions(tp *topo.Topo, rule *def.Rule, inputs []node.Emitter, streamCo
 	return nil
 }
 
+// buildDeadLetter creates the side output node of the rule if the dead letter or the late event topic is configured.
+// The dead letter sink is owned by the node and is not split into the sink operators, so the records are encoded
+// by the converter of the sink format directly.
+func buildDeadLetter(tp *topo.Topo, rule *def.Rule) error {
+	opt := rule.Options.DeadLetter
+	if opt == nil && rule.Options.LateEventTopic == "" {
+		return nil
+	}
+	var (
+		topic string
+		snk   api.Sink
+		c     message.Converter
+	)
+	if opt != nil {
+		topic = opt.Topic
+		if len(opt.Action) > 1 {
+			return fmt.Errorf("dead letter action must have only one sink type, but found %d", len(opt.Action))
+		}
+		for name, action := range opt.Action {
+			props, ok := action.(map[string]any)
+			if !ok {
+				return fmt.Errorf("expect map[string]interface{} type for the dead letter action properties, but found %v", action)
+			}
+			props, err := conf.OverwriteByConnectionConf(name, props)
+			if err != nil {
+				return err
+			}
+			s, _ := io.Sink(name)
+			if s == nil {
+				return fmt.Errorf("sink %s is not defined", name)
+			}
+			if err := s.Provision(tp.GetContext(), copyProps(props)); err != nil {
+				return err
+			}
+			if _, ok := s.(api.BytesCollector); ok {
+				sc, err := node.ParseConf(tp.GetContext().GetLogger(), props)
+				if err != nil {
+					return err
+				}
+				c, err = converter.GetOrCreateConverter(tp.GetContext(), sc.Format, sc.SchemaId, nil, sc.ConverterProps())
+				if err != nil {
+					return err
+				}
+			}
+			snk = s
+		}
+	}
+	dl, err := node.NewDeadLetterOp("deadLetter", topic, rule.Options.LateEventTopic, snk, c, rule.Options)
+	if err != nil {
+		return err
+	}
+	tp.SetDeadLetter(dl)
+	return nil
+}
+
 func copyProps(raw map[string]any) map[string]any {
 	newProps := make(map[string]any, len(raw))
 	for k, v := range raw {
@@ -101,6 +159,9 @@ func SinkToComp(tp *topo.Topo, sinkType string, sinkName string, props map[strin
 	if err != nil {
 		return nil, fmt.Errorf("fail to parse sink configuration: %v", err)
 	}
+	if sc, ok := s.(modules.SchemaConsumer); ok {
+		sc.SetSchema(washSchema(commonConf, schema))
+	}
 	templates := findTemplateProps(props)
 	// Split sink node
 	sinkOps, err := splitSink(tp, s, sinkName, rule.Options, commonConf, templates, schema)
@@ -137,6 +198,9 @@ func SinkToComp(tp *topo.Topo, sinkType string, sinkName string, props map[strin
 			return nil, err
 		}
 		tp.GetContext().GetLogger().Infof("provision sink %s with props %+v", sinkName, props)
+		if sc, ok := s.(modules.SchemaConsumer); ok {
+			sc.SetSchema(washSchema(commonConf, schema))
+		}
 
 		cacheOp, err := node.NewCacheOp(tp.GetContext(), fmt.Sprintf("%s_cache", sinkName), rule.Options, &commonConf.SinkConf)
 		if err != nil {
@@ -4677,3 +4677,13 @@ func TestCheckSharedSourceOption(t *testing.T) {
 	}
 	require.Error(t, checkSharedSourceOption(s1, r1))
 }
+
+func TestGenWindowConfigLocation(t *testing.T) {
+	p := &WindowPlan{wtype: ast.TUMBLING_WINDOW, timeUnit: ast.DD, length: 1, timeZone: "Asia/Shanghai"}
+	wc, err := p.GenWindowConfig()
+	require.NoError(t, err)
+	require.Equal(t, "Asia/Shanghai", wc.Location.String())
+	p.timeZone = "Invalid/Zone"
+	_, err = p.GenWindowConfig()
+	require.Error(t, err)
+}
@@ -35,6 +35,8 @@ type ProjectPlan struct {
 	exprFields       ast.Fields
 	enableLimit      bool
 	limitCount       int
+	limitOffset      int
+	distinct         bool
 }
 
 func (p ProjectPlan) Init() *ProjectPlan {
@@ -83,9 +85,15 @@ func (p *ProjectPlan) BuildExplainInfo() {
 		}
 		info += " ]"
 	}
+	if p.distinct {
+		info += ", Distinct:true"
+	}
 	if p.enableLimit {
 		info += ", Limit:" + strconv.Itoa(p.limitCount)
 	}
+	if p.limitOffset > 0 {
+		info += ", Offset:" + strconv.Itoa(p.limitOffset)
+	}
 	p.baseLogicalPlan.ExplainInfo.Info = info
 }
 
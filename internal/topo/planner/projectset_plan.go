@@ -25,6 +25,7 @@ type ProjectSetPlan struct {
 	SrfMapping  map[string]struct{}
 	enableLimit bool
 	limitCount  int
+	limitOffset int
 }
 
 func (p ProjectSetPlan) Init() *ProjectSetPlan {
@@ -49,5 +50,8 @@ func (p *ProjectSetPlan) BuildExplainInfo() {
 		info += "}"
 	}
 	info += ", EnableLimit:" + strconv.FormatBool(p.enableLimit)
+	if p.limitOffset > 0 {
+		info += ", Offset:" + strconv.Itoa(p.limitOffset)
+	}
 	p.baseLogicalPlan.ExplainInfo.Info = info
 }
@@ -56,7 +56,7 @@ func (pp *pushProjectionPlan) searchWindowPlan(plan LogicalPlan) bool {
 
 func (pp *pushProjectionPlan) searchJoinPlan(plan LogicalPlan) bool {
 	switch plan.(type) {
-	case *JoinPlan:
+	case *JoinPlan, *IntervalJoinPlan:
 		return true
 	default:
 	}
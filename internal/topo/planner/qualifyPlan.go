@@ -0,0 +1,45 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// QualifyPlan filters the rows after the window functions are calculated.
+// The window functions in the condition are extracted to the window func plans below it.
+type QualifyPlan struct {
+	baseLogicalPlan
+	condition ast.Expr
+}
+
+func (p QualifyPlan) Init() *QualifyPlan {
+	p.baseLogicalPlan.self = &p
+	p.baseLogicalPlan.setPlanType(QUALIFY)
+	return &p
+}
+
+func (p *QualifyPlan) BuildExplainInfo() {
+	info := ""
+	if p.condition != nil {
+		info += "Condition:{ " + p.condition.String() + " }"
+	}
+	p.baseLogicalPlan.ExplainInfo.Info = info
+}
+
+func (p *QualifyPlan) PruneColumns(fields []ast.Expr) error {
+	f := getFields(p.condition)
+	return p.baseLogicalPlan.PruneColumns(append(fields, f...))
+}
@@ -0,0 +1,464 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/kv"
+)
+
+// UnionPlan merges the output of several independently planned select statements.
+// Each child is the full plan of a union branch.
+type UnionPlan struct {
+	baseLogicalPlan
+	all bool
+}
+
+func (p UnionPlan) Init() *UnionPlan {
+	p.baseLogicalPlan.self = &p
+	p.baseLogicalPlan.setPlanType(UNION)
+	return &p
+}
+
+func (p *UnionPlan) BuildExplainInfo() {
+	info := fmt.Sprintf("Branches: %d", len(p.children))
+	if p.all {
+		info += ", All: true"
+	}
+	p.baseLogicalPlan.ExplainInfo.Info = info
+}
+
+// PushDownPredicate The branches have different sources, so the condition cannot be pushed down.
+func (p *UnionPlan) PushDownPredicate(condition ast.Expr) (ast.Expr, LogicalPlan) {
+	return condition, p.self
+}
+
+// PruneColumns The branches are pruned when planning them separately.
+func (p *UnionPlan) PruneColumns(_ []ast.Expr) error {
+	return nil
+}
+
+// createUnionPlan plans each branch of the union separately and merges them with a union plan.
+// The columns of all branches are renamed to the column names of the first branch.
+// UNION without ALL removes the duplicated rows, which is only possible per window for unbounded streams.
+func createUnionPlan(stmt *ast.SelectStatement, opt *def.RuleOption, store kv.KeyValue, isTemp bool) (LogicalPlan, []*ast.Call, []*ast.Call, error) {
+	if opt.Experiment != nil && opt.Experiment.UseSliceTuple {
+		return nil, nil, nil, errors.New("slice tuple mode do not support union yet")
+	}
+	head := *stmt
+	head.Unions = nil
+	branches := []*ast.SelectStatement{&head}
+	distinct := !stmt.Unions[0].All
+	for _, u := range stmt.Unions {
+		if u.All == distinct {
+			return nil, nil, nil, errors.New("UNION and UNION ALL cannot be mixed in one statement")
+		}
+		branches = append(branches, u.Stmt)
+	}
+	if err := validateUnionStreams(branches); err != nil {
+		return nil, nil, nil, err
+	}
+	for _, branch := range branches {
+		if isAggregateBranch(branch) {
+			return createAggregateUnionPlan(stmt, branches, distinct, opt, store, isTemp)
+		}
+	}
+	if distinct {
+		return nil, nil, nil, errUnionDistinct
+	}
+	if err := alignUnionFields(branches); err != nil {
+		return nil, nil, nil, err
+	}
+	var (
+		children           []LogicalPlan
+		analyticFuncs      []*ast.Call
+		analyticFieldFuncs []*ast.Call
+	)
+	for _, branch := range branches {
+		lp, af, aff, err := createLogicalPlanFull(branch, opt, store, isTemp)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		children = append(children, lp)
+		analyticFuncs = append(analyticFuncs, af...)
+		analyticFieldFuncs = append(analyticFieldFuncs, aff...)
+	}
+	if err := checkUnionFieldTypes(branches, children); err != nil {
+		return nil, nil, nil, err
+	}
+	// Copy back the decorated fields so that the sink schema is derived from the bound statement
+	stmt.Fields = head.Fields
+	p := UnionPlan{all: true}.Init()
+	p.SetChildren(children)
+	return p, analyticFuncs, analyticFieldFuncs, nil
+}
+
+// unionTableName is the name of the derived table which holds the merged rows of an aggregate union
+const unionTableName = "$$union"
+
+var errUnionDistinct = errors.New("UNION without ALL is only supported if the union has a window, use UNION ALL instead")
+
+func isAggregateBranch(stmt *ast.SelectStatement) bool {
+	return len(stmt.Dimensions) > 0 || stmt.Having != nil || xsql.WithAggFields(stmt)
+}
+
+// createAggregateUnionPlan plans the union with window or aggregation. The window and the aggregation are shared by all
+// branches: each branch only filters its rows and projects the referenced columns, and the merged rows are fed to one
+// window and aggregation planned from the first branch. It is planned as the outer query of a derived table.
+// If distinct, the duplicated rows of each window are removed as SELECT DISTINCT of the outer query.
+//
+// The branches must have the same window, group by, aggregation, having and order by except the referenced columns,
+// which are matched by position and named after the columns of the first branch. The output names, including the
+// aliases, also come from the first branch, so the aliases of the other branches are ignored.
+func createAggregateUnionPlan(stmt *ast.SelectStatement, branches []*ast.SelectStatement, distinct bool, opt *def.RuleOption, store kv.KeyValue, isTemp bool) (LogicalPlan, []*ast.Call, []*ast.Call, error) {
+	var (
+		headCols     []*ast.FieldRef
+		headTemplate string
+		rows         = make([]*ast.SelectStatement, 0, len(branches))
+	)
+	for i, branch := range branches {
+		if len(branch.Sources) != 1 || len(branch.Joins) > 0 {
+			return nil, nil, nil, fmt.Errorf("union branch %d must select from exactly one stream without join if the union has window or aggregation", i)
+		}
+		for _, f := range visibleFields(branch) {
+			if isWildcardField(f) {
+				return nil, nil, nil, errors.New("wildcard in union is not supported if the union has window or aggregation")
+			}
+		}
+		cols := unionColumns(branch)
+		template := unionTemplate(branch, cols)
+		if i == 0 {
+			headCols, headTemplate = cols, template
+		} else if template != headTemplate || len(cols) != len(headCols) {
+			return nil, nil, nil, fmt.Errorf("union branch %d must have the same window, group by, aggregation, having and order by as the first branch except the column names", i)
+		}
+		row := &ast.SelectStatement{Sources: branch.Sources, Condition: branch.Condition}
+		for k, c := range cols {
+			f := ast.Field{Name: c.Name, Expr: &ast.FieldRef{StreamName: c.StreamName, Name: c.Name}}
+			if name := headCols[k].Name; name != c.Name {
+				f.AName = name
+			}
+			row.Fields = append(row.Fields, f)
+		}
+		// nothing is referenced, such as count(*), so pass the whole rows
+		if len(row.Fields) == 0 {
+			row.Fields = ast.Fields{{Name: "*", Expr: &ast.Wildcard{Token: ast.ASTERISK}}}
+		}
+		rows = append(rows, row)
+	}
+	union := rows[0]
+	for _, row := range rows[1:] {
+		union.Unions = append(union.Unions, ast.Union{All: true, Stmt: row})
+	}
+	head := branches[0]
+	if distinct && head.Dimensions.GetWindow() == nil {
+		return nil, nil, nil, errUnionDistinct
+	}
+	// The shared clauses refer to the merged rows of the derived table
+	headIndex := make(map[string]struct{}, len(headCols))
+	for _, c := range headCols {
+		headIndex[c.Name] = struct{}{}
+	}
+	walkUnionClauses(head, func(n ast.Node) bool {
+		if fr, ok := n.(*ast.FieldRef); ok && !fr.IsAlias() {
+			if _, ok := headIndex[fr.Name]; ok {
+				fr.StreamName = ast.DefaultStream
+			}
+		}
+		return true
+	})
+	outer := &ast.SelectStatement{
+		Distinct:   head.Distinct || distinct,
+		Fields:     head.Fields,
+		Sources:    ast.Sources{&ast.Table{Name: unionTableName, Query: union}},
+		Dimensions: head.Dimensions,
+		Having:     head.Having,
+		Qualify:    head.Qualify,
+		SortFields: head.SortFields,
+		Limit:      head.Limit,
+	}
+	lp, analyticFuncs, analyticFieldFuncs, err := createLogicalPlanFull(outer, opt, store, isTemp)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// Copy back the decorated fields so that the sink schema is derived from the bound statement
+	stmt.Fields = outer.Fields
+	return lp, analyticFuncs, analyticFieldFuncs, nil
+}
+
+// unionColumns returns the distinct columns referenced by the clauses shared by an aggregate union in a fixed order.
+// The references to the aliases of the select fields are not columns of the stream.
+func unionColumns(stmt *ast.SelectStatement) []*ast.FieldRef {
+	aliases := make(map[string]struct{})
+	for _, f := range visibleFields(stmt) {
+		if f.AName != "" {
+			aliases[f.AName] = struct{}{}
+		}
+	}
+	var (
+		cols  []*ast.FieldRef
+		index = make(map[string]int)
+	)
+	walkUnionClauses(stmt, func(n ast.Node) bool {
+		fr, ok := n.(*ast.FieldRef)
+		if !ok || fr.IsAlias() {
+			return true
+		}
+		if _, ok := aliases[fr.Name]; ok && fr.StreamName == ast.DefaultStream {
+			return true
+		}
+		if _, ok := index[fr.Name]; !ok {
+			index[fr.Name] = len(cols)
+			cols = append(cols, fr)
+		}
+		return true
+	})
+	return cols
+}
+
+// unionTemplate prints the shared clauses with the columns replaced by their positions to compare the branches.
+func unionTemplate(stmt *ast.SelectStatement, cols []*ast.FieldRef) string {
+	index := make(map[string]int, len(cols))
+	for i, c := range cols {
+		index[c.Name] = i
+	}
+	type origin struct {
+		ref        *ast.FieldRef
+		streamName ast.StreamName
+		name       string
+	}
+	var origins []origin
+	walkUnionClauses(stmt, func(n ast.Node) bool {
+		if fr, ok := n.(*ast.FieldRef); ok {
+			if i, ok := index[fr.Name]; ok && !fr.IsAlias() {
+				origins = append(origins, origin{ref: fr, streamName: fr.StreamName, name: fr.Name})
+				fr.StreamName, fr.Name = ast.DefaultStream, fmt.Sprintf("$$col_%d", i)
+			}
+		}
+		return true
+	})
+	defer func() {
+		for _, o := range origins {
+			o.ref.StreamName, o.ref.Name = o.streamName, o.name
+		}
+	}()
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "distinct:%t;", stmt.Distinct)
+	for _, f := range visibleFields(stmt) {
+		fmt.Fprintf(b, "field:%s;", f.Expr.String())
+	}
+	for _, d := range stmt.Dimensions {
+		if w, ok := d.Expr.(*ast.Window); ok {
+			fmt.Fprintf(b, "window:%s;", windowTemplate(w))
+		} else {
+			fmt.Fprintf(b, "group:%s;", exprString(d.Expr))
+		}
+	}
+	fmt.Fprintf(b, "having:%s;qualify:%s;limit:%s;", exprString(stmt.Having), exprString(stmt.Qualify), exprString(stmt.Limit))
+	for i := range stmt.SortFields {
+		fmt.Fprintf(b, "sort:%s;", stmt.SortFields[i].String())
+	}
+	return b.String()
+}
+
+// walkUnionClauses walks the clauses shared by an aggregate union, including the window conditions.
+func walkUnionClauses(stmt *ast.SelectStatement, fn func(ast.Node) bool) {
+	for _, f := range visibleFields(stmt) {
+		ast.WalkFunc(f.Expr, fn)
+	}
+	for _, d := range stmt.Dimensions {
+		ast.WalkFunc(d.Expr, fn)
+		if w, ok := d.Expr.(*ast.Window); ok {
+			ast.WalkFunc(w.SingleCondition, fn)
+			if w.PartitionExpr != nil {
+				for _, e := range w.PartitionExpr.Exprs {
+					ast.WalkFunc(e, fn)
+				}
+			}
+		}
+	}
+	ast.WalkFunc(stmt.Having, fn)
+	ast.WalkFunc(stmt.Qualify, fn)
+	ast.WalkFunc(stmt.SortFields, fn)
+}
+
+func windowTemplate(w *ast.Window) string {
+	lit := func(l *ast.IntegerLiteral) string {
+		if l == nil {
+			return ""
+		}
+		return l.String()
+	}
+	tu, tz, partition := "", "", ""
+	if w.TimeUnit != nil {
+		tu = w.TimeUnit.String()
+	}
+	if w.TimeZone != nil {
+		tz = w.TimeZone.Val
+	}
+	if w.PartitionExpr != nil {
+		partition = w.PartitionExpr.String()
+	}
+	return fmt.Sprintf("%s(%s,%s,%s,%s,%s),filter:%s,trigger:%s,single:%s,begin:%s,emit:%s,partition:%s",
+		w.WindowType, tu, lit(w.Length), lit(w.Interval), lit(w.Delay), tz,
+		exprString(w.Filter), exprString(w.TriggerCondition), exprString(w.SingleCondition), exprString(w.BeginCondition), exprString(w.EmitCondition), partition)
+}
+
+func exprString(e ast.Expr) string {
+	if e == nil {
+		return ""
+	}
+	return e.String()
+}
+
+// validateUnionStreams A stream can only be used in one branch because the source node is named by the stream name.
+func validateUnionStreams(branches []*ast.SelectStatement) error {
+	used := make(map[string]int)
+	for i, branch := range branches {
+		names := make(map[string]struct{})
+		for _, name := range xsql.GetStreams(branch) {
+			names[name] = struct{}{}
+		}
+		for name := range names {
+			if prev, ok := used[name]; ok {
+				return fmt.Errorf("stream %s is used in union branch %d and %d, each stream can only be used in one branch", name, prev, i)
+			}
+			used[name] = i
+		}
+	}
+	return nil
+}
+
+func visibleFields(stmt *ast.SelectStatement) []*ast.Field {
+	var result []*ast.Field
+	for i := range stmt.Fields {
+		if !stmt.Fields[i].Invisible {
+			result = append(result, &stmt.Fields[i])
+		}
+	}
+	return result
+}
+
+func isWildcardField(f *ast.Field) bool {
+	_, ok := f.Expr.(*ast.Wildcard)
+	return ok
+}
+
+// alignUnionFields validates that all branches produce the same number of columns
+// and renames the columns of the following branches to the names of the first branch.
+func alignUnionFields(branches []*ast.SelectStatement) error {
+	headFields := visibleFields(branches[0])
+	wildcard := false
+	for _, f := range headFields {
+		if isWildcardField(f) {
+			wildcard = true
+		}
+	}
+	for i, branch := range branches {
+		fields := visibleFields(branch)
+		for _, f := range fields {
+			if isWildcardField(f) != wildcard || (wildcard && len(fields) > 1) {
+				return fmt.Errorf("wildcard in union is only supported when all branches select * only")
+			}
+		}
+		if wildcard || i == 0 {
+			continue
+		}
+		if len(fields) != len(headFields) {
+			return fmt.Errorf("each union branch must select the same number of columns, expect %d but branch %d selects %d", len(headFields), i, len(fields))
+		}
+		for j, f := range fields {
+			if name := headFields[j].GetName(); f.GetName() != name {
+				f.AName = name
+			}
+		}
+	}
+	return nil
+}
+
+// checkUnionFieldTypes checks the column types of each branch if they can be inferred from the stream schema.
+func checkUnionFieldTypes(branches []*ast.SelectStatement, plans []LogicalPlan) error {
+	headFields := visibleFields(branches[0])
+	headTypes := make([]string, len(headFields))
+	for i, branch := range branches {
+		schemas := make(map[ast.StreamName]map[string]*ast.JsonStreamField)
+		collectStreamFields(plans[i], schemas)
+		for j, f := range visibleFields(branch) {
+			if j >= len(headTypes) {
+				break
+			}
+			t := inferFieldType(f.Expr, schemas)
+			if t == "" {
+				continue
+			}
+			if headTypes[j] == "" {
+				headTypes[j] = t
+			} else if !compatibleType(headTypes[j], t) {
+				return fmt.Errorf("column %s of union branch %d has type %s which is incompatible with %s", headFields[j].GetName(), i, t, headTypes[j])
+			}
+		}
+	}
+	return nil
+}
+
+func collectStreamFields(lp LogicalPlan, schemas map[ast.StreamName]map[string]*ast.JsonStreamField) {
+	if ds, ok := lp.(*DataSourcePlan); ok && ds.streamFields != nil {
+		schemas[ds.name] = ds.streamFields
+	}
+	for _, c := range lp.Children() {
+		collectStreamFields(c, schemas)
+	}
+}
+
+func inferFieldType(expr ast.Expr, schemas map[ast.StreamName]map[string]*ast.JsonStreamField) string {
+	switch e := expr.(type) {
+	case *ast.FieldRef:
+		if e.IsAlias() {
+			return inferFieldType(e.Expression, schemas)
+		}
+		if sf, ok := schemas[e.StreamName]; ok {
+			if f, ok := sf[e.Name]; ok {
+				return f.Type
+			}
+		}
+	case *ast.IntegerLiteral:
+		return ast.BIGINT.String()
+	case *ast.NumberLiteral:
+		return ast.FLOAT.String()
+	case *ast.StringLiteral:
+		return ast.STRINGS.String()
+	case *ast.BooleanLiteral:
+		return ast.BOOLEAN.String()
+	case *ast.ParenExpr:
+		return inferFieldType(e.Expr, schemas)
+	}
+	return ""
+}
+
+func compatibleType(a, b string) bool {
+	if a == b {
+		return true
+	}
+	isNumber := func(t string) bool {
+		return t == ast.BIGINT.String() || t == ast.FLOAT.String()
+	}
+	return isNumber(a) && isNumber(b)
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+)
+
+func TestExplainUnion(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+
+	testcases := []struct {
+		sql     string
+		explain string
+	}{
+		{
+			sql: `select a, b from stream where a > 1 union all select a, b as c from sharedStream`,
+			explain: `{"op":"UnionPlan_0","info":"Branches: 2, All: true"}
+	{"op":"ProjectPlan_1","info":"Fields:[ stream.a, stream.b ]"}
+			{"op":"FilterPlan_2","info":"Condition:{ binaryExpr:{ stream.a > 1 } }, "}
+					{"op":"DataSourcePlan_3","info":"StreamName: stream, StreamFields:[ a, b ]"}
+
+
+	{"op":"ProjectPlan_4","info":"Fields:[ $$alias.b,aliasRef:sharedStream.b, sharedStream.a ]"}
+			{"op":"DataSourcePlan_5","info":"StreamName: sharedStream, StreamFields:[ a, b ]"}`,
+		},
+		{
+			sql: `select count(*) as c, sum(a) as s from stream where b = "x" group by countwindow(2) union all select count(*) as c2, sum(b) from sharedStream group by countwindow(2)`,
+			explain: `{"op":"ProjectPlan_0","info":"Fields:[ $$alias.c,aliasRef:Call:{ name:count, args:[*] }, $$alias.s,aliasRef:Call:{ name:sum, args:[$$union.a] } ]"}
+	{"op":"WindowPlan_1","info":"{ length:2, windowType:COUNT_WINDOW, limit: 0 }"}
+			{"op":"DerivedTablePlan_2","info":"Name: $$union"}
+					{"op":"UnionPlan_3","info":"Branches: 2, All: true"}
+							{"op":"ProjectPlan_4","info":"Fields:[ stream.a ]"}
+									{"op":"FilterPlan_5","info":"Condition:{ binaryExpr:{ stream.b = x } }, "}
+											{"op":"DataSourcePlan_6","info":"StreamName: stream, StreamFields:[ a, b ]"}
+
+
+							{"op":"ProjectPlan_7","info":"Fields:[ $$alias.a,aliasRef:sharedStream.b ]"}
+									{"op":"DataSourcePlan_8","info":"StreamName: sharedStream, StreamFields:[ b ]"}`,
+		},
+		{
+			sql: `select a from stream group by countwindow(2) union select b from sharedStream group by countwindow(2)`,
+			explain: `{"op":"ProjectPlan_0","info":"Fields:[ $$union.a ], Distinct:true"}
+	{"op":"WindowPlan_1","info":"{ length:2, windowType:COUNT_WINDOW, limit: 0 }"}
+			{"op":"DerivedTablePlan_2","info":"Name: $$union"}
+					{"op":"UnionPlan_3","info":"Branches: 2, All: true"}
+							{"op":"ProjectPlan_4","info":"Fields:[ stream.a ]"}
+									{"op":"DataSourcePlan_5","info":"StreamName: stream, StreamFields:[ a ]"}
+
+							{"op":"ProjectPlan_6","info":"Fields:[ $$alias.a,aliasRef:sharedStream.b ]"}
+									{"op":"DataSourcePlan_7","info":"StreamName: sharedStream, StreamFields:[ b ]"}`,
+		},
+	}
+	for _, tc := range testcases {
+		stmt, err := xsql.NewParser(strings.NewReader(tc.sql)).Parse()
+		require.NoError(t, err)
+		p, err := CreateLogicalPlan(stmt, &def.RuleOption{
+			PlanOptimizeStrategy: &def.PlanOptimizeStrategy{},
+		}, kv)
+		require.NoError(t, err)
+		explain, err := ExplainFromLogicalPlan(p, "")
+		require.NoError(t, err)
+		require.Equal(t, tc.explain, explain, tc.sql)
+	}
+}
+
+func TestUnionPlanError(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+
+	testcases := []struct {
+		sql string
+		err string
+	}{
+		{
+			sql: `select a, b from stream union all select a from sharedStream`,
+			err: "each union branch must select the same number of columns, expect 2 but branch 1 selects 1",
+		},
+		{
+			sql: `select * from stream union all select a from sharedStream`,
+			err: "wildcard in union is only supported when all branches select * only",
+		},
+		{
+			sql: `select a from stream union all select a from stream`,
+			err: "stream stream is used in union branch 0 and 1, each stream can only be used in one branch",
+		},
+		{
+			sql: `select a from stream union all select "abc" from sharedStream`,
+			err: "column a of union branch 1 has type string which is incompatible with bigint",
+		},
+		{
+			sql: `select count(*) from stream group by countwindow(2) union all select count(*) from sharedStream group by countwindow(3)`,
+			err: "union branch 1 must have the same window, group by, aggregation, having and order by as the first branch except the column names",
+		},
+		{
+			sql: `select sum(a) from stream group by countwindow(2) union all select a from sharedStream`,
+			err: "union branch 1 must have the same window, group by, aggregation, having and order by as the first branch except the column names",
+		},
+		{
+			sql: `select sum(a) from stream group by countwindow(2) union all select sum(a + 1) from sharedStream group by countwindow(2)`,
+			err: "union branch 1 must have the same window, group by, aggregation, having and order by as the first branch except the column names",
+		},
+		{
+			sql: `select *, count(*) from stream group by countwindow(2) union all select *, count(*) from sharedStream group by countwindow(2)`,
+			err: "wildcard in union is not supported if the union has window or aggregation",
+		},
+		{
+			sql: `select count(*) from stream inner join sharedStream on stream.a = sharedStream.a group by tumblingwindow(ss, 1) union all select count(*) from src1 group by tumblingwindow(ss, 1)`,
+			err: "union branch 0 must select from exactly one stream without join if the union has window or aggregation",
+		},
+		{
+			sql: `select a from stream union select a from sharedStream`,
+			err: "UNION without ALL is only supported if the union has a window, use UNION ALL instead",
+		},
+		{
+			sql: `select a from stream group by countwindow(2) union select a from sharedStream group by countwindow(2) union all select a from src1 group by countwindow(2)`,
+			err: "UNION and UNION ALL cannot be mixed in one statement",
+		},
+	}
+	for _, tc := range testcases {
+		stmt, err := xsql.NewParser(strings.NewReader(tc.sql)).Parse()
+		require.NoError(t, err)
+		_, err = CreateLogicalPlan(stmt, &def.RuleOption{
+			PlanOptimizeStrategy: &def.PlanOptimizeStrategy{},
+		}, kv)
+		require.EqualError(t, err, tc.err, tc.sql)
+	}
+}
@@ -16,14 +16,22 @@ package planner
 
 import (
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 )
 
 type WatermarkPlan struct {
 	baseLogicalPlan
-	Emitters      []string
-	SendWatermark bool
+	Emitters []string
+	// OrderedEmitters are the derived tables in Emitters whose rows are already ordered by the watermark of the sub query
+	OrderedEmitters []string
+	SendWatermark   bool
+	// DerivedTable is the name of the derived table if the plan is in its sub query
+	DerivedTable string
+	// AllowedLateness is set only if the downstream window can update the closed windows for the late events
+	AllowedLateness time.Duration
 }
 
 func (p WatermarkPlan) Init() *WatermarkPlan {
@@ -44,7 +52,13 @@ func (p *WatermarkPlan) BuildExplainInfo() {
 		}
 		info += " ], "
 	}
+	if len(p.OrderedEmitters) != 0 {
+		info += "OrderedEmitters:[ " + strings.Join(p.OrderedEmitters, ", ") + " ], "
+	}
 	info += "SendWatermark:" + strconv.FormatBool(p.SendWatermark)
+	if p.AllowedLateness > 0 {
+		info += ", AllowedLateness:" + p.AllowedLateness.String()
+	}
 	p.baseLogicalPlan.ExplainInfo.Info = info
 }
 
@@ -16,6 +16,7 @@ package planner
 
 import (
 	"strconv"
+	"time"
 
 	"github.com/lf-edge/ekuiper/v2/internal/topo/node"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
@@ -35,8 +36,10 @@ type WindowPlan struct {
 	length           int
 	interval         int // If interval is not set, it is equals to Length
 	timeUnit         ast.Token
+	timeZone         string
 	limit            int // If limit is not positive, there will be no limit
 	isEventTime      bool
+	allowedLateness  time.Duration
 
 	stateFuncs []*ast.Call
 }
@@ -150,13 +153,17 @@ func (p *WindowPlan) transform(f *ast.Call) {
 	}
 }
 
-func (p *WindowPlan) GenWindowConfig() *node.WindowConfig {
+func (p *WindowPlan) GenWindowConfig() (*node.WindowConfig, error) {
 	l, i, d := convertFromDuration(p.timeUnit, p.length, p.interval, p.delay)
+	loc, err := getWindowLocation(p.timeZone)
+	if err != nil {
+		return nil, err
+	}
 	var rawInterval int
 	switch p.wtype {
 	case ast.TUMBLING_WINDOW, ast.SESSION_WINDOW:
 		rawInterval = p.length
-	case ast.HOPPING_WINDOW:
+	case ast.HOPPING_WINDOW, ast.CUMULATE_WINDOW:
 		rawInterval = p.interval
 	}
 	return &node.WindowConfig{
@@ -167,8 +174,11 @@ func (p *WindowPlan) GenWindowConfig() *node.WindowConfig {
 		CountInterval:    p.interval,
 		CountLength:      p.length,
 		RawInterval:      rawInterval,
+		RawLength:        p.length,
+		Location:         loc,
+		AllowedLateness:  p.allowedLateness,
 		TimeUnit:         p.timeUnit,
 		TriggerCondition: p.triggerCondition,
 		StateFuncs:       p.stateFuncs,
-	}
+	}, nil
 }
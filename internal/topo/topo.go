@@ -71,6 +71,8 @@ type Topo struct {
 	topo         *def.PrintableTopo
 	sinkSchema   map[string]*ast.JsonStreamField
 	opsWg        *sync.WaitGroup
+	// deadLetter is not connected to other nodes and not checkpointed
+	deadLetter *node.DeadLetterOp
 	// all other things are read only during lifecycle except state
 	state atomic.Value
 }
@@ -110,6 +112,19 @@ func (s *Topo) Open() <-chan error {
 			return err
 		}
 		topoStore := s.store
+		if s.deadLetter != nil {
+			s.deadLetter.Exec(s.ctx.WithMeta(s.name, s.deadLetter.GetName(), topoStore), s.drain)
+			for _, snk := range s.sinks {
+				if dn, ok := snk.(node.DeadLetterNode); ok {
+					dn.SetDeadLetter(s.deadLetter)
+				}
+			}
+			for _, op := range s.ops {
+				if dn, ok := op.(node.DeadLetterNode); ok {
+					dn.SetDeadLetter(s.deadLetter)
+				}
+			}
+		}
 		// open stream sink, after log sink is ready.
 		for _, snk := range s.sinks {
 			snk.Exec(s.ctx.WithMeta(s.name, snk.GetName(), topoStore), s.drain)
@@ -274,6 +289,13 @@ func (s *Topo) AddSink(inputs []node.Emitter, snk node.DataSinkNode) *Topo {
 	return s
 }
 
+// SetDeadLetter sets the rule level dead letter which all nodes can send the unprocessable data to.
+// It is set to the nodes when opening the topo.
+func (s *Topo) SetDeadLetter(dl *node.DeadLetterOp) *Topo {
+	s.deadLetter = dl
+	return s
+}
+
 func (s *Topo) AddSinkAlterOperator(sink *node.SinkNode, operator node.OperatorNode) *Topo {
 	ch, _ := operator.GetInput()
 	sink.SetResendOutput(ch)
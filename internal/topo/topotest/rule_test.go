@@ -446,6 +446,18 @@ func TestSRFSQL(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name: `TestSingleSQLRuleLimit`,
+			Sql:  `SELECT unnest(arr2) FROM demoArr where x=1 limit 1`,
+			R: [][]map[string]interface{}{
+				{
+					{
+						"a": 1,
+						"b": 2,
+					},
+				},
+			},
+		},
 		// The mapping schema created by unnest function will cover the original column if they have the same column name
 		{
 			Name: `TestSingleSQLRule19`,
@@ -1948,3 +1960,185 @@ func TestRuleDumpState(t *testing.T) {
 	err = tp2.GracefulStop(0)
 	require.Error(t, err)
 }
+
+func TestSubQuerySQL(t *testing.T) {
+	streamList := []string{"demo"}
+	HandleStream(false, streamList, t)
+	tests := []RuleTest{
+		{
+			Name: "TestDerivedTable",
+			Sql:  `select color from (select color, size from demo where size > 2) as t where size < 5`,
+			R: [][]map[string]any{
+				{{"color": "red"}},
+				{{"color": "yellow"}},
+			},
+		},
+		{
+			Name: "TestCTEWindow",
+			Sql:  `with t as (select color, size * 2 as s from demo) select count(*) as c, sum(s) as total from t group by countwindow(5)`,
+			R: [][]map[string]any{
+				{{"c": 5, "total": int64(32)}},
+			},
+		},
+	}
+	HandleStream(true, streamList, t)
+	options := []*def.RuleOption{
+		{
+			BufferLength: 100,
+			SendError:    true,
+		},
+	}
+	for _, opt := range options {
+		DoRuleTest(t, tests, opt, 0)
+	}
+}
+
+func TestUnionSQL(t *testing.T) {
+	streamList := []string{"demo", "demo1"}
+	HandleStream(false, streamList, t)
+	tests := []RuleTest{
+		{
+			Name: "TestUnionRows",
+			Sql:  `select color as name, size as v from demo where size > 4 union all select "demo1", hum from demo1 where hum > 79`,
+			R: [][]map[string]any{
+				{{"name": "blue", "v": 6}},
+				{{"name": "demo1", "v": 80}},
+			},
+		},
+		{
+			Name: "TestUnionCountWindow",
+			Sql:  `select count(*) as c, sum(size) as total from demo group by countwindow(10) union all select count(*) as c, sum(hum) as total from demo1 group by countwindow(10)`,
+			R: [][]map[string]any{
+				{{"c": 10, "total": int64(357)}},
+			},
+		},
+		{
+			Name: "TestUnionFilterHaving",
+			Sql:  `select count(*) as c, max(size) as m from demo where size > 2 group by countwindow(6) having m > 10 union all select count(*), max(hum) as m from demo1 where hum < 70 group by countwindow(6) having m > 10`,
+			R: [][]map[string]any{
+				{{"c": 6, "m": int64(65)}},
+			},
+		},
+		{
+			Name: "TestUnionDistinct",
+			Sql:  `select size % 2 as p from demo group by countwindow(10) order by p union select hum % 2 as p from demo1 group by countwindow(10) order by p`,
+			R: [][]map[string]any{
+				{{"p": int64(0)}, {"p": int64(1)}},
+			},
+		},
+		{
+			Name: "TestUnionDerivedTable",
+			Sql:  `select count(*) as c, sum(v) as total from (select size as v from demo union all select hum from demo1) as t group by countwindow(10)`,
+			R: [][]map[string]any{
+				{{"c": 10, "total": int64(357)}},
+			},
+		},
+	}
+	HandleStream(true, streamList, t)
+	DoRuleTest(t, tests, &def.RuleOption{
+		BufferLength: 100,
+		SendError:    true,
+	}, 0)
+}
+
+func TestDistinctSQL(t *testing.T) {
+	streamList := []string{"demo"}
+	HandleStream(false, streamList, t)
+	tests := []RuleTest{
+		{
+			Name: "TestSelectDistinct",
+			Sql:  `select distinct color from demo group by countwindow(5)`,
+			R: [][]map[string]any{
+				{{"color": "red"}, {"color": "blue"}, {"color": "yellow"}},
+			},
+		},
+		{
+			Name: "TestSelectDistinctLimit",
+			Sql:  `select distinct color from demo group by countwindow(5) limit 2`,
+			R: [][]map[string]any{
+				{{"color": "red"}, {"color": "blue"}},
+			},
+		},
+		{
+			Name: "TestCountDistinct",
+			Sql:  `select count(distinct color) as c, sum(distinct size) as s, count(color) as total from demo group by countwindow(5)`,
+			R: [][]map[string]any{
+				{{"c": 3, "s": int64(16), "total": 5}},
+			},
+		},
+	}
+	HandleStream(true, streamList, t)
+	DoRuleTest(t, tests, &def.RuleOption{
+		BufferLength: 100,
+		SendError:    true,
+	}, 0)
+}
+
+func TestIncAggDistinctSQL(t *testing.T) {
+	streamList := []string{"demo"}
+	HandleStream(false, streamList, t)
+	tests := []RuleTest{
+		{
+			Name: "TestIncCountDistinct",
+			Sql:  `select count(distinct color) as c, sum(distinct size) as s, count(color) as total from demo group by countwindow(5)`,
+			R: [][]map[string]any{
+				{{"c": int64(3), "s": float64(16), "total": int64(5)}},
+			},
+		},
+		{
+			Name: "TestIncCollectDistinct",
+			Sql:  `select collect(distinct color) as colors from demo group by countwindow(5)`,
+			R: [][]map[string]any{
+				{{"colors": []any{"red", "blue", "yellow"}}},
+			},
+		},
+	}
+	HandleStream(true, streamList, t)
+	DoRuleTest(t, tests, &def.RuleOption{
+		BufferLength: 100,
+		SendError:    true,
+		PlanOptimizeStrategy: &def.PlanOptimizeStrategy{
+			EnableIncrementalWindow: true,
+		},
+	}, 0)
+}
+
+func TestLimitOffsetAndQualifySQL(t *testing.T) {
+	streamList := []string{"demo"}
+	HandleStream(false, streamList, t)
+	tests := []RuleTest{
+		{
+			Name: "TestLimitOffset",
+			Sql:  `select color, size from demo group by countwindow(5) limit 2 offset 1`,
+			R: [][]map[string]any{
+				{{"color": "blue", "size": 6}, {"color": "blue", "size": 2}},
+			},
+		},
+		{
+			Name: "TestGroupLimitOffset",
+			Sql:  `select color, count(*) as c from demo group by countwindow(5), color order by color limit 1 offset 1`,
+			R: [][]map[string]any{
+				{{"color": "red", "c": 2}},
+			},
+		},
+		{
+			Name: "TestQualifyRowNumber",
+			Sql:  `select color, size from demo group by countwindow(5) qualify row_number() over (partition by color order by size desc) <= 1`,
+			R: [][]map[string]any{
+				{{"color": "blue", "size": 6}, {"color": "red", "size": 3}, {"color": "yellow", "size": 4}},
+			},
+		},
+		{
+			Name: "TestQualifyAlias",
+			Sql:  `select color, size, row_number() over (partition by color order by size) as rn from demo group by countwindow(5) qualify rn = 2`,
+			R: [][]map[string]any{
+				{{"color": "blue", "size": 6, "rn": 2}, {"color": "red", "size": 3, "rn": 2}},
+			},
+		},
+	}
+	HandleStream(true, streamList, t)
+	DoRuleTest(t, tests, &def.RuleOption{
+		BufferLength: 100,
+		SendError:    true,
+	}, 0)
+}
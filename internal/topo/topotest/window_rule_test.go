@@ -1414,6 +1414,51 @@ func TestEventWindow(t *testing.T) {
 	}
 }
 
+// TestEventTimeCTE verifies the rows and the watermark of a CTE are passed to the outer window without being delayed again
+func TestEventTimeCTE(t *testing.T) {
+	streamList := []string{"demoE"}
+	HandleStream(false, streamList, t)
+	tests := []RuleTest{
+		{
+			Name: `TestEventTimeCTEWindow`,
+			Sql:  `WITH t AS (SELECT color, ts FROM demoE WHERE size > 1) SELECT window_start(), window_end(), count(*) AS c FROM t GROUP BY TUMBLINGWINDOW(ss, 1)`,
+			R: [][]map[string]interface{}{
+				{{
+					"window_start": int64(1541152486013),
+					"window_end":   int64(1541152487000),
+					"c":            1,
+				}},
+				{{
+					"window_start": int64(1541152487000),
+					"window_end":   int64(1541152488000),
+					"c":            1,
+				}},
+				{{
+					"window_start": int64(1541152488000),
+					"window_end":   int64(1541152489000),
+					"c":            1,
+				}},
+				// the empty windows are emitted until the watermark of the CTE, which is the last event time minus the late tolerance
+				{{
+					"window_start": int64(1541152489000),
+					"window_end":   int64(1541152490000),
+				}},
+				{{
+					"window_start": int64(1541152490000),
+					"window_end":   int64(1541152491000),
+				}},
+			},
+		},
+	}
+	HandleStream(true, streamList, t)
+	DoRuleTest(t, tests, &def.RuleOption{
+		BufferLength: 100,
+		SendError:    true,
+		IsEventTime:  true,
+		LateTol:      cast.DurationConf(time.Second),
+	}, 10)
+}
+
 func TestWindowError(t *testing.T) {
 	// Reset
 	streamList := []string{"ldemo", "ldemo1"}
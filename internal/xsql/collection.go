@@ -593,10 +593,19 @@ type WindowRange struct {
 	windowStart   int64
 	windowEnd     int64
 	windowTrigger int64
+	// rowkind is update if the window is emitted again for the late events
+	rowkind string
 }
 
 func NewWindowRange(windowStart int64, windowEnd int64, windowTrigger int64) *WindowRange {
-	return &WindowRange{windowStart, windowEnd, windowTrigger}
+	return &WindowRange{windowStart: windowStart, windowEnd: windowEnd, windowTrigger: windowTrigger}
+}
+
+// WithRowkind returns a copy of the window range with the rowkind
+func (r *WindowRange) WithRowkind(rowkind string) *WindowRange {
+	nr := *r
+	nr.rowkind = rowkind
+	return &nr
 }
 
 func (r *WindowRange) FuncValue(key string) (interface{}, bool) {
@@ -607,6 +616,11 @@ func (r *WindowRange) FuncValue(key string) (interface{}, bool) {
 		return r.windowEnd, true
 	case "event_time", "window_trigger":
 		return r.windowTrigger, true
+	case "window_rowkind":
+		if r.rowkind == "" {
+			return ast.RowkindInsert, true
+		}
+		return r.rowkind, true
 	default:
 		return nil, false
 	}
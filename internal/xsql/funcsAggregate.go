@@ -17,6 +17,7 @@ package xsql
 import (
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
 )
 
@@ -85,3 +86,35 @@ func (v *AggregateFunctionValuer) Call(name string, funcId int, args []interface
 func (v *AggregateFunctionValuer) GetAllTuples() AggregateData {
 	return v.data
 }
+
+// distinctAggArgs removes the rows with duplicated first argument for the aggregate function with DISTINCT modifier.
+// The other arguments of the same rows are removed too to keep them aligned.
+func distinctAggArgs(args []interface{}) {
+	col, ok := args[0].([]interface{})
+	if !ok {
+		return
+	}
+	keyset := make(map[string]struct{}, len(col))
+	indexes := make([]int, 0, len(col))
+	for i, v := range col {
+		key := cast.ToKey(v)
+		if _, ok := keyset[key]; !ok {
+			keyset[key] = struct{}{}
+			indexes = append(indexes, i)
+		}
+	}
+	if len(indexes) == len(col) {
+		return
+	}
+	for i, arg := range args {
+		vals, ok := arg.([]interface{})
+		if !ok || len(vals) != len(col) {
+			continue
+		}
+		result := make([]interface{}, len(indexes))
+		for j, index := range indexes {
+			result[j] = vals[index]
+		}
+		args[i] = result
+	}
+}
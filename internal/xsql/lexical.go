@@ -233,6 +233,12 @@ func (s *Scanner) ScanIdent() (tok ast.Token, lit string) {
 		return ast.EXCEPT, lit
 	case "INVISIBLE":
 		return ast.INVISIBLE, lit
+	case "UNION":
+		return ast.UNION, lit
+	case "DISTINCT":
+		return ast.DISTINCT, lit
+	case "QUALIFY":
+		return ast.QUALIFY, lit
 	case "TRUE":
 		return ast.TRUE, lit
 	case "FALSE":
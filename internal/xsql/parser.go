@@ -22,6 +22,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/golang-collections/collections/stack"
 
@@ -73,7 +74,21 @@ func (p *Parser) ParseLimit() (ast.Expr, error) {
 	if !ok {
 		return nil, fmt.Errorf("limit should be integer")
 	}
-	return &ast.LimitExpr{LimitCount: limitCount}, nil
+	le := &ast.LimitExpr{LimitCount: limitCount}
+	if tok, lit := p.scanIgnoreWhitespace(); tok == ast.IDENT && strings.EqualFold(lit, ast.OFFSET) {
+		expr, err = p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+		offset, ok := expr.(*ast.IntegerLiteral)
+		if !ok || offset.Val < 0 {
+			return nil, fmt.Errorf("offset should be non-negative integer")
+		}
+		le.Offset = offset
+	} else {
+		p.unscan()
+	}
+	return le, nil
 }
 
 func (p *Parser) scan() (tok ast.Token, lit string) {
@@ -149,14 +164,57 @@ func (p *Parser) ParseQueries() ([]ast.SelectStatement, error) {
 }
 
 func (p *Parser) Parse() (*ast.SelectStatement, error) {
-	selects := &ast.SelectStatement{}
-
-	if tok, lit := p.scanIgnoreWhitespace(); tok == ast.EOF {
+	var ctes ast.CTEs
+	tok, lit := p.scanIgnoreWhitespace()
+	if tok == ast.IDENT && strings.EqualFold(lit, ast.WITH) {
+		p.clause = "with"
+		var err error
+		if ctes, err = p.parseCTEs(); err != nil {
+			return nil, err
+		}
+		tok, lit = p.scanIgnoreWhitespace()
+	}
+	if tok == ast.EOF {
 		return nil, nil
 	} else if tok != ast.SELECT {
 		return nil, fmt.Errorf("Found %q, Expected SELECT.\n", lit)
 	}
+	selects, err := p.parseSelect()
+	if err != nil {
+		return nil, err
+	}
+	selects.With = ctes
+	p.clause = "union"
+	if unions, err := p.parseUnions(); err != nil {
+		return nil, err
+	} else {
+		selects.Unions = unions
+	}
+	p.clause = ""
+	if tok, lit := p.scanIgnoreWhitespace(); tok == ast.SEMICOLON {
+		validateFields(selects, p.sourceNames)
+		p.unscan()
+		return selects, nil
+	} else if tok != ast.EOF {
+		return nil, fmt.Errorf("found %q, expected EOF.", lit)
+	}
+
+	if err := Validate(selects); err != nil {
+		return nil, err
+	}
+	validateFields(selects, p.sourceNames)
+	return selects, nil
+}
+
+// parseSelect parses the clauses of a select statement after the SELECT keyword
+func (p *Parser) parseSelect() (*ast.SelectStatement, error) {
+	selects := &ast.SelectStatement{}
 	p.clause = "select"
+	if tok, _ := p.scanIgnoreWhitespace(); tok == ast.DISTINCT {
+		selects.Distinct = true
+	} else {
+		p.unscan()
+	}
 	if fields, err := p.parseFields(); err != nil {
 		return nil, err
 	} else {
@@ -199,6 +257,12 @@ func (p *Parser) Parse() (*ast.SelectStatement, error) {
 	} else {
 		selects.Having = having
 	}
+	p.clause = "qualify"
+	if qualify, err := p.parseQualify(); err != nil {
+		return nil, err
+	} else {
+		selects.Qualify = qualify
+	}
 	p.clause = "orderby"
 	if sorts, err := p.parseSorts(); err != nil {
 		return nil, err
@@ -213,20 +277,126 @@ func (p *Parser) Parse() (*ast.SelectStatement, error) {
 			selects.Limit = expr
 		}
 	}
-	p.clause = ""
-	if tok, lit := p.scanIgnoreWhitespace(); tok == ast.SEMICOLON {
-		validateFields(selects, p.sourceNames)
-		p.unscan()
-		return selects, nil
-	} else if tok != ast.EOF {
-		return nil, fmt.Errorf("found %q, expected EOF.", lit)
+	return selects, nil
+}
+
+// parseUnions parses the trailing UNION [ALL] SELECT ... statements.
+// Each union statement has its own sources, so the source names are resolved separately.
+func (p *Parser) parseUnions() (ast.Unions, error) {
+	var unions ast.Unions
+	for {
+		if tok, _ := p.scanIgnoreWhitespace(); tok != ast.UNION {
+			p.unscan()
+			return unions, nil
+		}
+		u := ast.Union{}
+		tok, lit := p.scanIgnoreWhitespace()
+		if tok == ast.IDENT && strings.EqualFold(lit, ast.ALL) {
+			u.All = true
+			tok, lit = p.scanIgnoreWhitespace()
+		}
+		if tok != ast.SELECT {
+			return nil, fmt.Errorf("found %q, expected SELECT after UNION.", lit)
+		}
+		stmt, err := p.parseSubSelect()
+		if err != nil {
+			return nil, err
+		}
+		u.Stmt = stmt
+		unions = append(unions, u)
 	}
+}
 
-	if err := Validate(selects); err != nil {
+// parseSubSelect parses a nested select statement after the SELECT keyword.
+// The nested statement has its own sources, so the source names are resolved separately.
+func (p *Parser) parseSubSelect() (*ast.SelectStatement, error) {
+	sourceNames, clause := p.sourceNames, p.clause
+	p.sourceNames = nil
+	stmt, err := p.parseSelect()
+	if err != nil {
 		return nil, err
 	}
-	validateFields(selects, p.sourceNames)
-	return selects, nil
+	if err := Validate(stmt); err != nil {
+		return nil, err
+	}
+	validateFields(stmt, p.sourceNames)
+	p.sourceNames, p.clause = sourceNames, clause
+	return stmt, nil
+}
+
+// parseSubquery parses a select statement in parentheses, which may include unions.
+// The left parenthesis is already consumed.
+func (p *Parser) parseSubquery() (*ast.SelectStatement, error) {
+	if tok, lit := p.scanIgnoreWhitespace(); tok != ast.SELECT {
+		return nil, fmt.Errorf("found %q, expected SELECT in sub query.", lit)
+	}
+	stmt, err := p.parseSubSelect()
+	if err != nil {
+		return nil, err
+	}
+	clause := p.clause
+	p.clause = "union"
+	if unions, err := p.parseUnions(); err != nil {
+		return nil, err
+	} else {
+		stmt.Unions = unions
+	}
+	p.clause = clause
+	if tok, lit := p.scanIgnoreWhitespace(); tok != ast.RPAREN {
+		return nil, fmt.Errorf("found %q, expected right paren for sub query.", lit)
+	}
+	return stmt, nil
+}
+
+// parseCTEs parses the common table expressions in the form of name AS (SELECT ...), ...
+// The WITH keyword is already consumed.
+func (p *Parser) parseCTEs() (ast.CTEs, error) {
+	var ctes ast.CTEs
+	for {
+		tok, lit := p.scanIgnoreWhitespace()
+		if tok != ast.IDENT {
+			return nil, fmt.Errorf("found %q, expected CTE name.", lit)
+		}
+		for _, c := range ctes {
+			if c.Name == lit {
+				return nil, fmt.Errorf("duplicate CTE name %s", lit)
+			}
+		}
+		cte := ast.CTE{Name: lit}
+		if tok, lit := p.scanIgnoreWhitespace(); tok != ast.AS {
+			return nil, fmt.Errorf("found %q, expected AS after CTE name.", lit)
+		}
+		if tok, lit := p.scanIgnoreWhitespace(); tok != ast.LPAREN {
+			return nil, fmt.Errorf("found %q, expected left paren for CTE %s.", lit, cte.Name)
+		}
+		stmt, err := p.parseSubquery()
+		if err != nil {
+			return nil, err
+		}
+		cte.Stmt = stmt
+		ctes = append(ctes, cte)
+		if tok, _ := p.scanIgnoreWhitespace(); tok != ast.COMMA {
+			p.unscan()
+			return ctes, nil
+		}
+	}
+}
+
+// parseDerivedTable parses the sub query and the alias of a derived table.
+// The left parenthesis is already consumed.
+func (p *Parser) parseDerivedTable() (*ast.SelectStatement, string, error) {
+	stmt, err := p.parseSubquery()
+	if err != nil {
+		return nil, "", err
+	}
+	tok, lit := p.scanIgnoreWhitespace()
+	if tok == ast.AS {
+		tok, lit = p.scanIgnoreWhitespace()
+	}
+	if tok != ast.IDENT {
+		return nil, "", fmt.Errorf("found %q, expected alias for derived table.", lit)
+	}
+	return stmt, lit, nil
 }
 
 func (p *Parser) parseSource() (ast.Sources, error) {
@@ -234,6 +404,14 @@ func (p *Parser) parseSource() (ast.Sources, error) {
 	if tok, lit := p.scanIgnoreWhitespace(); tok != ast.FROM {
 		return nil, fmt.Errorf("found %q, expected FROM.", lit)
 	}
+	if tok, _ := p.scanIgnoreWhitespace(); tok == ast.LPAREN {
+		stmt, alias, err := p.parseDerivedTable()
+		if err != nil {
+			return nil, err
+		}
+		return append(sources, &ast.Table{Name: alias, Query: stmt}), nil
+	}
+	p.unscan()
 
 	if src, alias, err := p.parseSourceLiteral(); err != nil {
 		return nil, err
@@ -250,7 +428,7 @@ func (p *Parser) parseSourceLiteral() (string, string, error) {
 	var alias string
 	for {
 		// HASH, DIV & ADD token is specially support for MQTT topic name patterns.
-		if tok, lit := p.scanIgnoreWhitespace(); tok.AllowedSourceToken() {
+		if tok, lit := p.scanIgnoreWhitespace(); tok.AllowedSourceToken() && !isForKeyword(tok, lit) {
 			sourceSeg = append(sourceSeg, lit)
 			if tok1, lit1 := p.scanIgnoreWhitespace(); tok1 == ast.AS {
 				if tok2, lit2 := p.scanIgnoreWhitespace(); tok2 == ast.IDENT {
@@ -258,6 +436,9 @@ func (p *Parser) parseSourceLiteral() (string, string, error) {
 				} else {
 					return "", "", fmt.Errorf("found %q, expected JOIN key word.", lit)
 				}
+			} else if isForKeyword(tok1, lit1) {
+				p.unscan()
+				break
 			} else if tok1.AllowedSourceToken() {
 				sourceSeg = append(sourceSeg, lit1)
 			} else {
@@ -335,11 +516,28 @@ func (p *Parser) parseJoins() (ast.Joins, error) {
 
 func (p *Parser) ParseJoin(joinType ast.JoinType) (*ast.Join, error) {
 	j := &ast.Join{JoinType: joinType}
-	if src, alias, err := p.parseSourceLiteral(); err != nil {
+	var (
+		src, alias string
+		err        error
+	)
+	if tok, _ := p.scanIgnoreWhitespace(); tok == ast.LPAREN {
+		j.Query, src, err = p.parseDerivedTable()
+	} else {
+		p.unscan()
+		src, alias, err = p.parseSourceLiteral()
+	}
+	if err != nil {
 		return nil, err
 	} else {
 		j.Name = src
 		j.Alias = alias
+		if tok, lit := p.scanIgnoreWhitespace(); isForKeyword(tok, lit) {
+			if j.AsOf, err = p.parseAsOf(); err != nil {
+				return nil, err
+			}
+		} else {
+			p.unscan()
+		}
 		if tok1, _ := p.scanIgnoreWhitespace(); tok1 == ast.ON {
 			if ast.CROSS_JOIN == joinType {
 				return nil, fmt.Errorf("On expression is not required for cross join type.\n")
@@ -356,6 +554,25 @@ func (p *Parser) ParseJoin(joinType ast.JoinType) (*ast.Join, error) {
 	return j, nil
 }
 
+// isForKeyword checks the FOR of FOR SYSTEM_TIME AS OF in temporal join, which is not a reserved keyword
+func isForKeyword(tok ast.Token, lit string) bool {
+	return tok == ast.IDENT && strings.EqualFold(lit, "FOR")
+}
+
+// parseAsOf parses SYSTEM_TIME AS OF expr after the FOR keyword
+func (p *Parser) parseAsOf() (ast.Expr, error) {
+	if tok, lit := p.scanIgnoreWhitespace(); tok != ast.IDENT || !strings.EqualFold(lit, "SYSTEM_TIME") {
+		return nil, fmt.Errorf("found %q, expected SYSTEM_TIME.", lit)
+	}
+	if tok, lit := p.scanIgnoreWhitespace(); tok != ast.AS {
+		return nil, fmt.Errorf("found %q, expected AS.", lit)
+	}
+	if tok, lit := p.scanIgnoreWhitespace(); tok != ast.IDENT || !strings.EqualFold(lit, "OF") {
+		return nil, fmt.Errorf("found %q, expected OF.", lit)
+	}
+	return p.ParseExpr()
+}
+
 func (p *Parser) parseDimensions() (ast.Dimensions, error) {
 	var ds ast.Dimensions
 	if t, _ := p.scanIgnoreWhitespace(); t == ast.GROUP {
@@ -394,6 +611,18 @@ func (p *Parser) parseHaving() (ast.Expr, error) {
 	return expr, nil
 }
 
+func (p *Parser) parseQualify() (ast.Expr, error) {
+	if tok, _ := p.scanIgnoreWhitespace(); tok != ast.QUALIFY {
+		p.unscan()
+		return nil, nil
+	}
+	expr, err := p.ParseExpr()
+	if err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
 func (p *Parser) parseSorts() (ast.SortFields, error) {
 	var ss ast.SortFields
 	if t, _ := p.scanIgnoreWhitespace(); t == ast.ORDER {
@@ -628,7 +857,7 @@ func (p *Parser) ParseExpr() (ast.Expr, error) {
 }
 
 func (p *Parser) parseBetween(lhs ast.Expr, op ast.Token) (ast.Expr, error) {
-	alhs, err := p.parseUnaryExpr(false)
+	alhs, err := p.parseBetweenOperand()
 	if err != nil {
 		return nil, err
 	}
@@ -636,7 +865,7 @@ func (p *Parser) parseBetween(lhs ast.Expr, op ast.Token) (ast.Expr, error) {
 	if opp != ast.AND {
 		return nil, fmt.Errorf("expect AND expression after between but found %s", opp)
 	}
-	arhs, err := p.parseUnaryExpr(false)
+	arhs, err := p.parseBetweenOperand()
 	if err != nil {
 		return nil, err
 	}
@@ -650,6 +879,82 @@ func (p *Parser) parseBetween(lhs ast.Expr, op ast.Token) (ast.Expr, error) {
 	}, nil
 }
 
+// parseBetweenOperand parses the bound of between which can be an arithmetic expression such as a.ts - INTERVAL 5s.
+// The logical operators are not consumed so that the AND of between is kept.
+func (p *Parser) parseBetweenOperand() (ast.Expr, error) {
+	var err error
+	root := &ast.BinaryExpr{}
+	if root.RHS, err = p.parseBoundUnary(); err != nil {
+		return nil, err
+	}
+	for {
+		op, _ := p.scanIgnoreWhitespace()
+		switch op {
+		case ast.ASTERISK:
+			op = ast.MUL
+		case ast.ADD, ast.SUB, ast.DIV, ast.MOD:
+		default:
+			p.unscan()
+			return root.RHS, nil
+		}
+		rhs, err := p.parseBoundUnary()
+		if err != nil {
+			return nil, err
+		}
+		for node := root; ; {
+			r, ok := node.RHS.(*ast.BinaryExpr)
+			if !ok || r.OP.Precedence() >= op.Precedence() {
+				node.RHS = &ast.BinaryExpr{LHS: node.RHS, RHS: rhs, OP: op}
+				break
+			}
+			node = r
+		}
+	}
+}
+
+// parseBoundUnary parses the operand of the between bound. The interval literal is only parsed in the join condition,
+// so that interval can still be used as a field name in other clauses.
+func (p *Parser) parseBoundUnary() (ast.Expr, error) {
+	if p.clause == "join" {
+		tok, lit := p.scanIgnoreWhitespace()
+		if tok == ast.IDENT && strings.EqualFold(lit, "INTERVAL") {
+			if tok1, _ := p.scanIgnoreWhitespace(); tok1 == ast.INTEGER {
+				p.unscan()
+				return p.parseInterval()
+			}
+			p.unscan()
+		}
+		p.unscan()
+	}
+	return p.parseUnaryExpr(false)
+}
+
+// parseInterval parses the interval literal like INTERVAL 5s or INTERVAL 5 SECOND to an integer in milliseconds
+func (p *Parser) parseInterval() (ast.Expr, error) {
+	_, lit := p.scanIgnoreWhitespace()
+	val, err := strconv.ParseInt(lit, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("found %q, invalid interval value.", lit)
+	}
+	_, unit := p.scanIgnoreWhitespace()
+	var d int64
+	switch strings.ToUpper(unit) {
+	case "MS", "MILLISECOND", "MILLISECONDS":
+		d = 1
+	case "S", "SS", "SECOND", "SECONDS":
+		d = 1000
+	case "M", "MI", "MINUTE", "MINUTES":
+		d = 60 * 1000
+	case "H", "HH", "HOUR", "HOURS":
+		d = 60 * 60 * 1000
+	case "D", "DD", "DAY", "DAYS":
+		d = 24 * 60 * 60 * 1000
+	default:
+		return nil, fmt.Errorf("found %q, expected interval unit.", unit)
+	}
+	return &ast.IntegerLiteral{Val: val * d}, nil
+}
+
 func (p *Parser) parseUnaryExpr(isSubField bool) (ast.Expr, error) {
 	if tok1, _ := p.scanIgnoreWhitespace(); tok1 == ast.LPAREN {
 		expr, err := p.ParseExpr()
@@ -876,9 +1181,37 @@ var WindowFuncs = map[string]struct{}{
 	"slidingwindow":  {},
 	"countwindow":    {},
 	"statewindow":    {},
+	"cumulatewindow": {},
 	"dedup_trigger":  {},
 }
 
+// alignedWindowFuncs are the windows aligned to the nature time. They support calendar units and time zone.
+var alignedWindowFuncs = map[string]struct{}{
+	"tumblingwindow": {},
+	"hoppingwindow":  {},
+	"cumulatewindow": {},
+}
+
+// convAlignedWindowArgs converts the calendar units ww and mm to time literal since they are not reserved words,
+// and splits the optional time zone argument at the end.
+func convAlignedWindowArgs(args []ast.Expr) ([]ast.Expr, *ast.StringLiteral) {
+	if len(args) == 0 {
+		return args, nil
+	}
+	if f, ok := args[0].(*ast.FieldRef); ok && f.StreamName == ast.DefaultStream {
+		switch strings.ToUpper(f.Name) {
+		case ast.Tokens[ast.WW]:
+			args[0] = &ast.TimeLiteral{Val: ast.WW}
+		case ast.Tokens[ast.MM]:
+			args[0] = &ast.TimeLiteral{Val: ast.MM}
+		}
+	}
+	if tz, ok := args[len(args)-1].(*ast.StringLiteral); ok {
+		return args[:len(args)-1], tz
+	}
+	return args, nil
+}
+
 func convFuncName(n string) (string, bool) {
 	lname := strings.ToLower(n)
 	if _, ok := WindowFuncs[lname]; ok {
@@ -901,8 +1234,20 @@ func (p *Parser) parseCall(n string) (ast.Expr, error) {
 		return nil, fmt.Errorf("function %s can only be used inside the select clause", n)
 	}
 	var args []ast.Expr
+	distinct := false
+	if tok, _ := p.scanIgnoreWhitespace(); tok == ast.DISTINCT {
+		if ft != ast.FuncTypeAgg {
+			return nil, fmt.Errorf("DISTINCT can only be used in aggregate functions but got %s", n)
+		}
+		distinct = true
+	} else {
+		p.unscan()
+	}
 	for {
 		if tok, _ := p.scanIgnoreWhitespace(); tok == ast.RPAREN {
+			if distinct && len(args) == 0 {
+				return nil, fmt.Errorf("expected expression after DISTINCT in function %s", n)
+			}
 			break
 		}
 		p.unscan()
@@ -925,6 +1270,10 @@ func (p *Parser) parseCall(n string) (ast.Expr, error) {
 			break
 		}
 	}
+	var tz *ast.StringLiteral
+	if _, ok := alignedWindowFuncs[name]; ok {
+		args, tz = convAlignedWindowArgs(args)
+	}
 	if wt, err := validateWindows(name, args); wt == ast.NOT_WINDOW {
 		switch name {
 		case "dedup_trigger":
@@ -952,7 +1301,12 @@ func (p *Parser) parseCall(n string) (ast.Expr, error) {
 		if name == "deduplicate" {
 			args = append([]ast.Expr{&ast.Wildcard{Token: ast.ASTERISK}}, args...)
 		}
-		c := &ast.Call{Name: name, Args: args, FuncId: p.fn, FuncType: ft}
+		if distinct {
+			if _, ok := args[0].(*ast.Wildcard); ok {
+				return nil, fmt.Errorf("DISTINCT is not allowed with * in function %s", n)
+			}
+		}
+		c := &ast.Call{Name: name, Args: args, FuncId: p.fn, FuncType: ft, Distinct: distinct}
 		p.fn += 1
 		e := p.parseOver(c)
 		return c, e
@@ -964,6 +1318,12 @@ func (p *Parser) parseCall(n string) (ast.Expr, error) {
 		if err != nil {
 			return nil, err
 		}
+		if tz != nil {
+			if _, err := time.LoadLocation(tz.Val); err != nil {
+				return nil, fmt.Errorf("invalid time zone %s for %s: %v", tz.Val, name, err)
+			}
+			win.TimeZone = tz
+		}
 		// parse filter clause
 		f, err := p.parseFilter()
 		if err != nil {
@@ -1060,6 +1420,21 @@ func validateWindows(fname string, args []ast.Expr) (ast.WindowType, error) {
 			return ast.HOPPING_WINDOW, err
 		}
 		return ast.HOPPING_WINDOW, nil
+	case "cumulatewindow":
+		if err := validateWindow(fname, 3, args); err != nil {
+			return ast.CUMULATE_WINDOW, err
+		}
+		if tl, ok := args[0].(*ast.TimeLiteral); ok && (tl.Val == ast.WW || tl.Val == ast.MM) {
+			return ast.CUMULATE_WINDOW, fmt.Errorf("The week and month units are not supported by %s.\n", fname)
+		}
+		size, step := args[1].(*ast.IntegerLiteral).Val, args[2].(*ast.IntegerLiteral).Val
+		if size <= 0 || step <= 0 {
+			return ast.CUMULATE_WINDOW, fmt.Errorf("The size and step for %s should be positive.\n", fname)
+		}
+		if step > size || size%step != 0 {
+			return ast.CUMULATE_WINDOW, fmt.Errorf("The size for %s should be a multiple of the step.\n", fname)
+		}
+		return ast.CUMULATE_WINDOW, nil
 	case "sessionwindow":
 		if err := validateWindow(fname, 3, args); err != nil {
 			return ast.SESSION_WINDOW, err
@@ -1136,7 +1511,7 @@ func (p *Parser) ConvertToWindows(wtype ast.WindowType, args []ast.Expr) (*ast.W
 	}
 	if tl, ok := args[0].(*ast.TimeLiteral); ok {
 		switch tl.Val {
-		case ast.DD, ast.HH, ast.MI, ast.SS, ast.MS:
+		case ast.DD, ast.HH, ast.MI, ast.SS, ast.MS, ast.WW, ast.MM:
 			win.TimeUnit = tl
 		default:
 			return nil, fmt.Errorf("Invalid timeliteral %s", tl.Val)
@@ -1416,6 +1416,103 @@ func TestParser_ParseStatement(t *testing.T) {
 				},
 			},
 		},
+		{
+			s: `SELECT a FROM tbl INNER JOIN t2 ON f1 BETWEEN f2 - INTERVAL 5s AND f2 + INTERVAL 1 MINUTE * 2 AND f3 > 4`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						AName: "",
+						Name:  "a",
+						Expr:  &ast.FieldRef{Name: "a", StreamName: ast.DefaultStream},
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+				Joins: []ast.Join{
+					{
+						Name: "t2", JoinType: ast.INNER_JOIN,
+						Expr: &ast.BinaryExpr{
+							OP: ast.AND,
+							LHS: &ast.BinaryExpr{
+								LHS: &ast.FieldRef{Name: "f1", StreamName: ast.DefaultStream},
+								OP:  ast.BETWEEN,
+								RHS: &ast.BetweenExpr{
+									Lower: &ast.BinaryExpr{
+										OP:  ast.SUB,
+										LHS: &ast.FieldRef{Name: "f2", StreamName: ast.DefaultStream},
+										RHS: &ast.IntegerLiteral{Val: 5000},
+									},
+									Higher: &ast.BinaryExpr{
+										OP:  ast.ADD,
+										LHS: &ast.FieldRef{Name: "f2", StreamName: ast.DefaultStream},
+										RHS: &ast.BinaryExpr{
+											OP:  ast.MUL,
+											LHS: &ast.IntegerLiteral{Val: 60000},
+											RHS: &ast.IntegerLiteral{Val: 2},
+										},
+									},
+								},
+							},
+							RHS: &ast.BinaryExpr{
+								OP:  ast.GT,
+								LHS: &ast.FieldRef{Name: "f3", StreamName: ast.DefaultStream},
+								RHS: &ast.IntegerLiteral{Val: 4},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			s:   `SELECT a FROM tbl INNER JOIN t2 ON f1 BETWEEN f2 - INTERVAL 5 week AND f2`,
+			err: "found \"week\", expected interval unit.",
+		},
+		// interval is a field name outside the join condition
+		{
+			s: `SELECT interval FROM tbl WHERE interval BETWEEN 1 AND interval + 5`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						AName: "",
+						Name:  "interval",
+						Expr:  &ast.FieldRef{Name: "interval", StreamName: ast.DefaultStream},
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+				Condition: &ast.BinaryExpr{
+					LHS: &ast.FieldRef{Name: "interval", StreamName: ast.DefaultStream},
+					OP:  ast.BETWEEN,
+					RHS: &ast.BetweenExpr{
+						Lower: &ast.IntegerLiteral{Val: 1},
+						Higher: &ast.BinaryExpr{
+							OP:  ast.ADD,
+							LHS: &ast.FieldRef{Name: "interval", StreamName: ast.DefaultStream},
+							RHS: &ast.IntegerLiteral{Val: 5},
+						},
+					},
+				},
+			},
+		},
+		{
+			s: `SELECT interval + 1 AS i FROM tbl WHERE interval > 1`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						AName: "i",
+						Expr: &ast.BinaryExpr{
+							OP:  ast.ADD,
+							LHS: &ast.FieldRef{Name: "interval", StreamName: ast.DefaultStream},
+							RHS: &ast.IntegerLiteral{Val: 1},
+						},
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+				Condition: &ast.BinaryExpr{
+					LHS: &ast.FieldRef{Name: "interval", StreamName: ast.DefaultStream},
+					OP:  ast.GT,
+					RHS: &ast.IntegerLiteral{Val: 1},
+				},
+			},
+		},
 		{
 			s:   `SELECT a FROM tbl WHERE f1 NOT BETWEEN b`,
 			err: "expect AND expression after between but found EOF",
@@ -3455,6 +3552,100 @@ func TestParser_ParseWindowsExpr(t *testing.T) {
 			},
 		},
 
+		{
+			s: `SELECT f1 FROM tbl GROUP BY CUMULATEWINDOW(hh, 24, 1)`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr:  &ast.FieldRef{Name: "f1", StreamName: ast.DefaultStream},
+						Name:  "f1",
+						AName: "",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+				Dimensions: ast.Dimensions{
+					ast.Dimension{
+						Expr: &ast.Window{
+							WindowType: ast.CUMULATE_WINDOW,
+							Length:     &ast.IntegerLiteral{Val: 24},
+							Interval:   &ast.IntegerLiteral{Val: 1},
+							TimeUnit:   &ast.TimeLiteral{Val: ast.HH},
+							Delay:      &ast.IntegerLiteral{Val: 0},
+						},
+					},
+				},
+			},
+		},
+		{
+			s:    `SELECT f1 FROM tbl GROUP BY CUMULATEWINDOW(hh, 24, 5)`,
+			stmt: nil,
+			err:  "The size for cumulatewindow should be a multiple of the step.\n",
+		},
+		{
+			s:    `SELECT f1 FROM tbl GROUP BY CUMULATEWINDOW(hh, 24, 0)`,
+			stmt: nil,
+			err:  "The size and step for cumulatewindow should be positive.\n",
+		},
+		{
+			s: `SELECT f1 FROM tbl GROUP BY TUMBLINGWINDOW(mm, 1, 'Asia/Shanghai')`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr:  &ast.FieldRef{Name: "f1", StreamName: ast.DefaultStream},
+						Name:  "f1",
+						AName: "",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+				Dimensions: ast.Dimensions{
+					ast.Dimension{
+						Expr: &ast.Window{
+							WindowType: ast.TUMBLING_WINDOW,
+							Length:     &ast.IntegerLiteral{Val: 1},
+							Interval:   &ast.IntegerLiteral{Val: 0},
+							TimeUnit:   &ast.TimeLiteral{Val: ast.MM},
+							TimeZone:   &ast.StringLiteral{Val: "Asia/Shanghai"},
+							Delay:      &ast.IntegerLiteral{Val: 0},
+						},
+					},
+				},
+			},
+		},
+		{
+			s: `SELECT f1 FROM tbl GROUP BY HOPPINGWINDOW(ww, 2, 1)`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr:  &ast.FieldRef{Name: "f1", StreamName: ast.DefaultStream},
+						Name:  "f1",
+						AName: "",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+				Dimensions: ast.Dimensions{
+					ast.Dimension{
+						Expr: &ast.Window{
+							WindowType: ast.HOPPING_WINDOW,
+							Length:     &ast.IntegerLiteral{Val: 2},
+							Interval:   &ast.IntegerLiteral{Val: 1},
+							TimeUnit:   &ast.TimeLiteral{Val: ast.WW},
+							Delay:      &ast.IntegerLiteral{Val: 0},
+						},
+					},
+				},
+			},
+		},
+		{
+			s:    `SELECT f1 FROM tbl GROUP BY TUMBLINGWINDOW(dd, 1, 'Mars/Olympus')`,
+			stmt: nil,
+			err:  "invalid time zone Mars/Olympus for tumblingwindow: unknown time zone Mars/Olympus",
+		},
+		{
+			s:    `SELECT f1 FROM tbl GROUP BY CUMULATEWINDOW(mm, 12, 1)`,
+			stmt: nil,
+			err:  "The week and month units are not supported by cumulatewindow.\n",
+		},
+
 		{
 			s: `SELECT f1 FROM tbl GROUP BY SESSIONWINDOW(hh, 5, 1)`,
 			stmt: &ast.SelectStatement{
@@ -4407,6 +4598,35 @@ func TestParser_ParseJoins(t *testing.T) {
 				},
 			},
 		},
+
+		{
+			s: `SELECT * FROM demo LEFT JOIN tbl AS t FOR SYSTEM_TIME AS OF demo.ts ON demo.id = t.id`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr:  &ast.Wildcard{Token: ast.ASTERISK},
+						Name:  "*",
+						AName: "",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "demo"}},
+				Joins: []ast.Join{
+					{
+						Name: "tbl", Alias: "t", JoinType: ast.LEFT_JOIN, Expr: &ast.BinaryExpr{
+							LHS: &ast.FieldRef{StreamName: ast.StreamName("demo"), Name: "id"},
+							OP:  ast.EQ,
+							RHS: &ast.FieldRef{StreamName: ast.StreamName("t"), Name: "id"},
+						},
+						AsOf: &ast.FieldRef{StreamName: ast.StreamName("demo"), Name: "ts"},
+					},
+				},
+			},
+		},
+
+		{
+			s:   `SELECT * FROM demo LEFT JOIN tbl FOR SYSTEM_TIME OF demo.ts ON demo.id = tbl.id`,
+			err: `found "OF", expected AS.`,
+		},
 	}
 
 	fmt.Printf("The test bucket size is %d.\n\n", len(tests))
@@ -4543,3 +4763,319 @@ func TestParser_ParseLimit(t *testing.T) {
 		require.Equal(t, tt.stmt, stmt)
 	}
 }
+
+func TestParser_ParseUnion(t *testing.T) {
+	tests := []struct {
+		s    string
+		stmt *ast.SelectStatement
+		err  string
+	}{
+		{
+			s: "SELECT name FROM tbl UNION ALL SELECT name FROM tbl2 WHERE tbl2.age > 1",
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr: &ast.FieldRef{Name: "name", StreamName: ast.DefaultStream},
+						Name: "name",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+				Unions: ast.Unions{
+					{
+						All: true,
+						Stmt: &ast.SelectStatement{
+							Fields: []ast.Field{
+								{
+									Expr: &ast.FieldRef{Name: "name", StreamName: ast.DefaultStream},
+									Name: "name",
+								},
+							},
+							Sources: []ast.Source{&ast.Table{Name: "tbl2"}},
+							Condition: &ast.BinaryExpr{
+								OP:  ast.GT,
+								LHS: &ast.FieldRef{Name: "age", StreamName: "tbl2"},
+								RHS: &ast.IntegerLiteral{Val: 1},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			s: "SELECT name FROM tbl UNION SELECT name FROM tbl2",
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr: &ast.FieldRef{Name: "name", StreamName: ast.DefaultStream},
+						Name: "name",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+				Unions: ast.Unions{
+					{
+						Stmt: &ast.SelectStatement{
+							Fields: []ast.Field{
+								{
+									Expr: &ast.FieldRef{Name: "name", StreamName: ast.DefaultStream},
+									Name: "name",
+								},
+							},
+							Sources: []ast.Source{&ast.Table{Name: "tbl2"}},
+						},
+					},
+				},
+			},
+		},
+		{
+			s:   "SELECT name FROM tbl UNION name FROM tbl2",
+			err: `found "name", expected SELECT after UNION.`,
+		},
+		{
+			s:   "SELECT name FROM tbl UNION ALL name FROM tbl2",
+			err: `found "name", expected SELECT after UNION.`,
+		},
+	}
+
+	for _, tt := range tests {
+		stmt, err := NewParser(strings.NewReader(tt.s)).Parse()
+		if tt.err != "" {
+			require.EqualError(t, err, tt.err, tt.s)
+			continue
+		}
+		require.NoError(t, err, tt.s)
+		require.Equal(t, tt.stmt, stmt, tt.s)
+	}
+}
+
+func TestParser_ParseSubQuery(t *testing.T) {
+	tests := []struct {
+		s    string
+		stmt *ast.SelectStatement
+		err  string
+	}{
+		{
+			s: "SELECT name FROM (SELECT name, age FROM tbl WHERE age > 1) AS t",
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr: &ast.FieldRef{Name: "name", StreamName: ast.DefaultStream},
+						Name: "name",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "t", Query: &ast.SelectStatement{
+					Fields: []ast.Field{
+						{
+							Expr: &ast.FieldRef{Name: "name", StreamName: ast.DefaultStream},
+							Name: "name",
+						},
+						{
+							Expr: &ast.FieldRef{Name: "age", StreamName: ast.DefaultStream},
+							Name: "age",
+						},
+					},
+					Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+					Condition: &ast.BinaryExpr{
+						OP:  ast.GT,
+						LHS: &ast.FieldRef{Name: "age", StreamName: ast.DefaultStream},
+						RHS: &ast.IntegerLiteral{Val: 1},
+					},
+				}}},
+			},
+		},
+		{
+			s: "WITH t AS (SELECT name FROM tbl UNION ALL SELECT name FROM tbl2) SELECT name FROM t",
+			stmt: &ast.SelectStatement{
+				With: ast.CTEs{
+					{
+						Name: "t",
+						Stmt: &ast.SelectStatement{
+							Fields: []ast.Field{
+								{
+									Expr: &ast.FieldRef{Name: "name", StreamName: ast.DefaultStream},
+									Name: "name",
+								},
+							},
+							Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+							Unions: ast.Unions{
+								{
+									All: true,
+									Stmt: &ast.SelectStatement{
+										Fields: []ast.Field{
+											{
+												Expr: &ast.FieldRef{Name: "name", StreamName: ast.DefaultStream},
+												Name: "name",
+											},
+										},
+										Sources: []ast.Source{&ast.Table{Name: "tbl2"}},
+									},
+								},
+							},
+						},
+					},
+				},
+				Fields: []ast.Field{
+					{
+						Expr: &ast.FieldRef{Name: "name", StreamName: ast.DefaultStream},
+						Name: "name",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "t"}},
+			},
+		},
+		{
+			s:   "SELECT name FROM (SELECT name FROM tbl)",
+			err: `found "EOF", expected alias for derived table.`,
+		},
+		{
+			s:   "WITH t AS (SELECT name FROM tbl), t AS (SELECT name FROM tbl2) SELECT name FROM t",
+			err: "duplicate CTE name t",
+		},
+		{
+			s:   "WITH t (SELECT name FROM tbl) SELECT name FROM t",
+			err: `found "(", expected AS after CTE name.`,
+		},
+	}
+
+	for _, tt := range tests {
+		stmt, err := NewParser(strings.NewReader(tt.s)).Parse()
+		if tt.err != "" {
+			require.EqualError(t, err, tt.err, tt.s)
+			continue
+		}
+		require.NoError(t, err, tt.s)
+		require.Equal(t, tt.stmt, stmt, tt.s)
+	}
+}
+
+func TestParser_ParseDistinct(t *testing.T) {
+	tests := []struct {
+		s    string
+		stmt *ast.SelectStatement
+		err  string
+	}{
+		{
+			s: "SELECT DISTINCT name FROM tbl",
+			stmt: &ast.SelectStatement{
+				Distinct: true,
+				Fields: []ast.Field{
+					{
+						Expr: &ast.FieldRef{Name: "name", StreamName: ast.DefaultStream},
+						Name: "name",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+			},
+		},
+		{
+			s: "SELECT count(DISTINCT name) AS c FROM tbl",
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr: &ast.Call{
+							Name:     "count",
+							FuncType: ast.FuncTypeAgg,
+							Args:     []ast.Expr{&ast.FieldRef{Name: "name", StreamName: ast.DefaultStream}},
+							Distinct: true,
+						},
+						Name:  "count",
+						AName: "c",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+			},
+		},
+		{
+			s:   "SELECT count(DISTINCT *) FROM tbl",
+			err: "DISTINCT is not allowed with * in function count",
+		},
+		{
+			s:   "SELECT abs(DISTINCT name) FROM tbl",
+			err: "DISTINCT can only be used in aggregate functions but got abs",
+		},
+		{
+			s:   "SELECT count(DISTINCT) FROM tbl",
+			err: "expected expression after DISTINCT in function count",
+		},
+	}
+
+	for _, tt := range tests {
+		stmt, err := NewParser(strings.NewReader(tt.s)).Parse()
+		if tt.err != "" {
+			require.EqualError(t, err, tt.err, tt.s)
+			continue
+		}
+		require.NoError(t, err, tt.s)
+		require.Equal(t, tt.stmt, stmt, tt.s)
+	}
+}
+
+func TestParser_ParseLimitOffsetAndQualify(t *testing.T) {
+	tests := []struct {
+		s    string
+		stmt *ast.SelectStatement
+		err  string
+	}{
+		{
+			s: "SELECT name FROM tbl LIMIT 3 OFFSET 2",
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr: &ast.FieldRef{Name: "name", StreamName: ast.DefaultStream},
+						Name: "name",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+				Limit:   &ast.LimitExpr{LimitCount: &ast.IntegerLiteral{Val: 3}, Offset: &ast.IntegerLiteral{Val: 2}},
+			},
+		},
+		{
+			s: "SELECT name FROM tbl QUALIFY row_number() OVER (PARTITION BY site ORDER BY temp DESC) <= 3",
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr: &ast.FieldRef{Name: "name", StreamName: ast.DefaultStream},
+						Name: "name",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+				Qualify: &ast.BinaryExpr{
+					OP: ast.LTE,
+					LHS: &ast.Call{
+						Name:     "row_number",
+						FuncType: ast.FuncTypeWindow,
+						Partition: &ast.PartitionExpr{
+							Exprs: []ast.Expr{&ast.FieldRef{Name: "site", StreamName: ast.DefaultStream}},
+						},
+						SortFields: ast.SortFields{
+							{
+								Name:      "temp",
+								Uname:     "temp",
+								Ascending: false,
+								FieldExpr: &ast.FieldRef{Name: "temp", StreamName: ast.DefaultStream},
+							},
+						},
+					},
+					RHS: &ast.IntegerLiteral{Val: 3},
+				},
+			},
+		},
+		{
+			s:   "SELECT name FROM tbl LIMIT 3 OFFSET -1",
+			err: "offset should be non-negative integer",
+		},
+		{
+			s:   "SELECT name FROM tbl LIMIT 3 OFFSET 1.5",
+			err: "offset should be non-negative integer",
+		},
+	}
+
+	for _, tt := range tests {
+		stmt, err := NewParser(strings.NewReader(tt.s)).Parse()
+		if tt.err != "" {
+			require.EqualError(t, err, tt.err, tt.s)
+			continue
+		}
+		require.NoError(t, err, tt.s)
+		require.Equal(t, tt.stmt, stmt, tt.s)
+	}
+}
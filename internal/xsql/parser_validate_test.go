@@ -156,6 +156,10 @@ func TestParser_ParserWindowFunctionStatement(t *testing.T) {
 			s:   "select * from demo order by row_number()",
 			err: "window functions can only be in select fields",
 		},
+		{
+			s:   "select * from demo qualify row_number() over (partition by a order by b desc) <= 3",
+			err: "",
+		},
 	}
 	for _, tt := range tests {
 		_, err := NewParser(strings.NewReader(tt.s)).Parse()
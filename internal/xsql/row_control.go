@@ -18,6 +18,8 @@ import "time"
 
 type WatermarkTuple struct {
 	Timestamp time.Time
+	// Emitter is the derived table name if the watermark is sent from a sub query to the outer query
+	Emitter string
 }
 
 func (t *WatermarkTuple) GetTimestamp() time.Time {
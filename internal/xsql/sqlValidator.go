@@ -42,7 +42,10 @@ func Validate(stmt *ast.SelectStatement) error {
 }
 
 func validateWindowFunction(stmt *ast.SelectStatement) error {
-	if exists := isWindowFunctionExists(stmt); exists {
+	// window functions are allowed in the qualify clause to filter by their results
+	s := *stmt
+	s.Qualify = nil
+	if exists := isWindowFunctionExists(&s); exists {
 		return fmt.Errorf("window functions can only be in select fields")
 	}
 	return nil
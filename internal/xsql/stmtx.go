@@ -25,11 +25,74 @@ import (
 	"github.com/lf-edge/ekuiper/v2/pkg/kv"
 )
 
+// GetStreams returns all the streams/tables used by the statement, including the ones in the sub queries.
 func GetStreams(stmt *ast.SelectStatement) (result []string) {
+	return getStreams(stmt, nil)
+}
+
+// cteScope is the CTEs visible to a statement. A CTE can only see the CTEs defined before it.
+type cteScope map[string]cteDef
+
+type cteDef struct {
+	stmt  *ast.SelectStatement
+	scope cteScope
+}
+
+func (c cteScope) with(ctes ast.CTEs) cteScope {
+	if len(ctes) == 0 {
+		return c
+	}
+	scope := make(cteScope, len(c)+len(ctes))
+	for k, v := range c {
+		scope[k] = v
+	}
+	for _, cte := range ctes {
+		// copy the scope so that the CTE cannot see itself and the following ones
+		prev := make(cteScope, len(scope))
+		for k, v := range scope {
+			prev[k] = v
+		}
+		scope[cte.Name] = cteDef{stmt: cte.Stmt, scope: prev}
+	}
+	return scope
+}
+
+func getStreams(stmt *ast.SelectStatement, ctes cteScope) (result []string) {
 	if stmt == nil {
 		return nil
 	}
+	ctes = ctes.with(stmt.With)
+	source := func(name string, query *ast.SelectStatement) []string {
+		if query != nil {
+			return getStreams(query, ctes)
+		}
+		if def, ok := ctes[name]; ok {
+			return getStreams(def.stmt, def.scope)
+		}
+		return []string{name}
+	}
 	// TODO sources must be a stream
+	for _, s := range stmt.Sources {
+		if t, ok := s.(*ast.Table); ok {
+			result = append(result, source(t.Name, t.Query)...)
+		}
+	}
+
+	for _, join := range stmt.Joins {
+		result = append(result, source(join.Name, join.Query)...)
+	}
+	for _, u := range stmt.Unions {
+		result = append(result, getStreams(u.Stmt, ctes)...)
+	}
+	return
+}
+
+// GetSourceNames returns the names in the from and join clause of the statement.
+// Derived tables are named by their alias.
+func GetSourceNames(stmt *ast.SelectStatement) (result []string) {
+	if stmt == nil {
+		return nil
+	}
 	for _, source := range stmt.Sources {
 		if s, ok := source.(*ast.Table); ok {
 			result = append(result, s.Name)
@@ -95,3 +158,77 @@ func GetDataSource(m kv.KeyValue, name string) (stmt *ast.StreamStmt, err error)
 	}
 	return
 }
+
+// InlineCTEs returns a statement whose CTE references in the from/join clauses are bound to the CTE statements so that
+// they can be planned as derived tables. The parsed statement is not modified, so it can be planned again.
+// A CTE is inlined into the plan and its streams can only be used once in a rule, so it can only be referenced once.
+func InlineCTEs(stmt *ast.SelectStatement) (*ast.SelectStatement, error) {
+	return inlineCTEs(stmt, nil, make(map[*ast.SelectStatement]string))
+}
+
+// inlineCTEs copies the statement and the sources, joins and unions which refer to the CTEs. The expressions are not copied.
+func inlineCTEs(stmt *ast.SelectStatement, ctes cteScope, used map[*ast.SelectStatement]string) (*ast.SelectStatement, error) {
+	ctes = ctes.with(stmt.With)
+	result := *stmt
+	result.With = nil
+	resolve := func(name, alias string, query *ast.SelectStatement) (string, string, *ast.SelectStatement, error) {
+		if query != nil {
+			q, err := inlineCTEs(query, ctes, used)
+			return name, alias, q, err
+		}
+		def, ok := ctes[name]
+		if !ok {
+			return name, alias, nil, nil
+		}
+		ref := name
+		if alias != "" {
+			ref = alias
+		}
+		if prev, ok := used[def.stmt]; ok {
+			return "", "", nil, fmt.Errorf("CTE %s can only be referenced once, but it is referenced as both %s and %s", name, prev, ref)
+		}
+		used[def.stmt] = ref
+		q, err := inlineCTEs(def.stmt, def.scope, used)
+		// derived tables are always referred by the alias
+		return ref, "", q, err
+	}
+	if len(stmt.Sources) > 0 {
+		result.Sources = make(ast.Sources, len(stmt.Sources))
+		for i, s := range stmt.Sources {
+			t, ok := s.(*ast.Table)
+			if !ok {
+				result.Sources[i] = s
+				continue
+			}
+			nt := *t
+			var err error
+			if nt.Name, nt.Alias, nt.Query, err = resolve(t.Name, t.Alias, t.Query); err != nil {
+				return nil, err
+			}
+			result.Sources[i] = &nt
+		}
+	}
+	if len(stmt.Joins) > 0 {
+		result.Joins = make(ast.Joins, len(stmt.Joins))
+		copy(result.Joins, stmt.Joins)
+		for i := range result.Joins {
+			j := &result.Joins[i]
+			var err error
+			if j.Name, j.Alias, j.Query, err = resolve(j.Name, j.Alias, j.Query); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(stmt.Unions) > 0 {
+		result.Unions = make(ast.Unions, len(stmt.Unions))
+		copy(result.Unions, stmt.Unions)
+		for i := range result.Unions {
+			u, err := inlineCTEs(result.Unions[i].Stmt, ctes, used)
+			if err != nil {
+				return nil, err
+			}
+			result.Unions[i].Stmt = u
+		}
+	}
+	return &result, nil
+}
@@ -34,6 +34,7 @@ var (
 		"window_end":     true,
 		"event_time":     true,
 		"window_trigger": true,
+		"window_rowkind": true,
 	}
 	// ImplicitStateFuncs is a set of functions that read/update global state implicitly.
 	ImplicitStateFuncs = map[string]bool{
@@ -388,6 +389,9 @@ func (v *ValuerEval) Eval(expr ast.Expr) interface{} {
 								}
 							}
 						}
+						if et.Distinct {
+							distinctAggArgs(args)
+						}
 					case ast.FuncTypeScalar, ast.FuncTypeSrf:
 						args = make([]interface{}, len(et.Args))
 						for i, arg := range et.Args {
@@ -229,6 +229,8 @@ type Call struct {
 
 	// This is used for window functions.
 	SortFields SortFields
+	// Distinct is set by the DISTINCT modifier of aggregate functions like COUNT(DISTINCT col).
+	Distinct bool
 }
 
 func (c *Call) expr()    {}
@@ -246,6 +248,9 @@ func (c *Call) String() string {
 		}
 		args += "]"
 	}
+	if c.Distinct {
+		args = ", distinct:true" + args
+	}
 	when := ""
 	if c.WhenExpr != nil {
 		when += ", when:{ " + c.WhenExpr.String() + " }"
@@ -404,12 +409,16 @@ func (b *BetweenExpr) String() string {
 
 type LimitExpr struct {
 	LimitCount *IntegerLiteral
+	Offset     *IntegerLiteral
 }
 
 func (l *LimitExpr) expr() {}
 func (l *LimitExpr) node() {}
 func (l *LimitExpr) String() string {
 	if l.LimitCount != nil {
+		if l.Offset != nil {
+			return "limitExpr:{ " + l.LimitCount.String() + ", offset:" + l.Offset.String() + " }"
+		}
 		return "limitExpr:{ " + l.LimitCount.String() + " }"
 	}
 	return ""
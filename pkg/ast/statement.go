@@ -22,6 +22,10 @@ type Statement interface {
 }
 
 type SelectStatement struct {
+	// With are the common table expressions defined before the select
+	With CTEs
+	// Distinct is set by SELECT DISTINCT to remove the duplicated rows of the result
+	Distinct   bool
 	Fields     Fields
 	Sources    Sources
 	Joins      Joins
@@ -29,11 +33,40 @@ type SelectStatement struct {
 	Limit      Expr
 	Dimensions Dimensions
 	Having     Expr
+	// Qualify filters the rows by the result of the window functions, such as row_number() for top-N per partition
+	Qualify    Expr
 	SortFields SortFields
+	// Unions are the statements appended by UNION [ALL] in order.
+	// Each of them is a complete select statement with its own sources.
+	Unions Unions
 
 	Statement
 }
 
+// Union is a select statement whose result is merged into the preceding one
+type Union struct {
+	All  bool
+	Stmt *SelectStatement
+
+	Node
+}
+
+type Unions []Union
+
+func (u Unions) node() {}
+
+// CTE is a named select statement defined by WITH name AS (SELECT ...)
+type CTE struct {
+	Name string
+	Stmt *SelectStatement
+
+	Node
+}
+
+type CTEs []CTE
+
+func (c CTEs) node() {}
+
 type Fields []Field
 
 func (f Fields) node() {}
@@ -102,6 +135,8 @@ type Source interface {
 type Table struct {
 	Name  string
 	Alias string
+	// Query is the select statement of a derived table or a referenced CTE
+	Query *SelectStatement
 	Source
 }
 
@@ -137,6 +172,10 @@ type Join struct {
 	Alias    string
 	JoinType JoinType
 	Expr     Expr
+	// Query is the select statement of a derived table or a referenced CTE
+	Query *SelectStatement
+	// AsOf is the time expression of FOR SYSTEM_TIME AS OF to join the table version valid at that time
+	AsOf Expr
 
 	Node
 }
@@ -184,6 +223,7 @@ const (
 	SESSION_WINDOW
 	COUNT_WINDOW
 	STATE_WINDOW
+	CUMULATE_WINDOW
 )
 
 func (w WindowType) String() string {
@@ -200,6 +240,10 @@ func (w WindowType) String() string {
 		return "SESSION_WINDOW"
 	case COUNT_WINDOW:
 		return "COUNT_WINDOW"
+	case STATE_WINDOW:
+		return "STATE_WINDOW"
+	case CUMULATE_WINDOW:
+		return "CUMULATE_WINDOW"
 	}
 	return ""
 }
@@ -215,6 +259,7 @@ type Window struct {
 	Length           *IntegerLiteral
 	Interval         *IntegerLiteral
 	TimeUnit         *TimeLiteral
+	TimeZone         *StringLiteral // The time zone to align the window. Use the configured time zone if nil
 	Filter           Expr
 	Expr
 }
@@ -242,6 +287,9 @@ func (wd *Window) String() string {
 	if wd.TimeUnit != nil {
 		tu += ", timeUnit: " + wd.TimeUnit.String() + " "
 	}
+	if wd.TimeZone != nil {
+		tu += ", timeZone: " + wd.TimeZone.Val + " "
+	}
 	filter := ""
 	if wd.Filter != nil {
 		filter += ", " + wd.Filter.String()
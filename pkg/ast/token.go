@@ -110,6 +110,9 @@ const (
 	OVER
 	PARTITION
 	INVISIBLE
+	UNION
+	DISTINCT
+	QUALIFY
 
 	TRUE
 	FALSE
@@ -119,6 +122,9 @@ const (
 	MI
 	SS
 	MS
+	// WW and MM are calendar units which are not reserved words
+	WW
+	MM
 )
 
 var Tokens = []string{
@@ -188,6 +194,9 @@ var Tokens = []string{
 	OVER:      "OVER",
 	PARTITION: "PARTITION",
 	INVISIBLE: "INVISIBLE",
+	UNION:     "UNION",
+	DISTINCT:  "DISTINCT",
+	QUALIFY:   "QUALIFY",
 
 	AND:        "AND",
 	OR:         "OR",
@@ -206,6 +215,8 @@ var Tokens = []string{
 	MI: "MI",
 	SS: "SS",
 	MS: "MS",
+	WW: "WW",
+	MM: "MM",
 }
 
 const (
@@ -220,6 +231,8 @@ const (
 	STREAMS    = "STREAMS"
 	TABLES     = "TABLES"
 	WITH       = "WITH"
+	ALL        = "ALL"
+	OFFSET     = "OFFSET"
 
 	DATASOURCE        = "DATASOURCE"
 	KEY               = "KEY"
@@ -297,7 +310,10 @@ func (tok Token) IsOperator() bool {
 	return (tok > operatorBeg && tok < operatorEnd) || tok == ASTERISK || tok == LBRACKET || tok == DOT
 }
 
-func (tok Token) IsTimeLiteral() bool { return tok >= DD && tok <= MS }
+func (tok Token) IsTimeLiteral() bool { return tok >= DD && tok <= MM }
+
+// IsCalendarUnit returns true if the duration of the time unit varies by the calendar, such as DST and the month length
+func (tok Token) IsCalendarUnit() bool { return tok == DD || tok == WW || tok == MM }
 
 func (tok Token) AllowedSourceToken() bool {
 	return tok == IDENT || tok == DIV || tok == HASH || tok == ADD
@@ -39,6 +39,7 @@ func Walk(v Visitor, node Node) {
 		Walk(v, n.Condition)
 		Walk(v, n.Dimensions)
 		Walk(v, n.Having)
+		Walk(v, n.Qualify)
 		Walk(v, n.SortFields)
 		Walk(v, n.Limit)
 
@@ -71,6 +72,7 @@ func Walk(v Visitor, node Node) {
 		}
 
 	case *Join:
+		Walk(v, n.AsOf)
 		Walk(v, n.Expr)
 
 	case Dimensions:
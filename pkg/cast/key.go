@@ -0,0 +1,141 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cast
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ToKey encodes the value as a string which is only equal for the equal values, so that it can be used as a map key
+// to deduplicate the values. Unlike the %v format, the kind of the value is encoded, so the number 1 and the string "1"
+// have different keys. The numbers of different types are equal if they have the same value.
+func ToKey(v any) string {
+	b := &strings.Builder{}
+	writeKey(b, v)
+	return b.String()
+}
+
+func writeKey(b *strings.Builder, v any) {
+	switch t := v.(type) {
+	case nil:
+		b.WriteString("n")
+	case bool:
+		if t {
+			b.WriteString("T")
+		} else {
+			b.WriteString("F")
+		}
+	case string:
+		writeLenPrefixed(b, 's', t)
+	case []byte:
+		writeLenPrefixed(b, 'b', string(t))
+	case int:
+		writeInt(b, int64(t))
+	case int8:
+		writeInt(b, int64(t))
+	case int16:
+		writeInt(b, int64(t))
+	case int32:
+		writeInt(b, int64(t))
+	case int64:
+		writeInt(b, t)
+	case uint:
+		writeUint(b, uint64(t))
+	case uint8:
+		writeUint(b, uint64(t))
+	case uint16:
+		writeUint(b, uint64(t))
+	case uint32:
+		writeUint(b, uint64(t))
+	case uint64:
+		writeUint(b, t)
+	case float32:
+		writeFloat(b, float64(t))
+	case float64:
+		writeFloat(b, t)
+	case time.Time:
+		b.WriteByte('t')
+		b.WriteString(strconv.FormatInt(t.UnixNano(), 10))
+		b.WriteByte(';')
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b.WriteString("m")
+		b.WriteString(strconv.Itoa(len(t)))
+		b.WriteByte('{')
+		for _, k := range keys {
+			writeLenPrefixed(b, 's', k)
+			writeKey(b, t[k])
+		}
+		b.WriteByte('}')
+	case []any:
+		b.WriteString("a")
+		b.WriteString(strconv.Itoa(len(t)))
+		b.WriteByte('[')
+		for _, e := range t {
+			writeKey(b, e)
+		}
+		b.WriteByte(']')
+	case []map[string]any:
+		b.WriteString("a")
+		b.WriteString(strconv.Itoa(len(t)))
+		b.WriteByte('[')
+		for _, e := range t {
+			writeKey(b, e)
+		}
+		b.WriteByte(']')
+	default:
+		writeLenPrefixed(b, 'o', fmt.Sprintf("%T:%v", v, v))
+	}
+}
+
+// writeLenPrefixed writes the string with its length so that the content cannot be confused with the following keys
+func writeLenPrefixed(b *strings.Builder, kind byte, s string) {
+	b.WriteByte(kind)
+	b.WriteString(strconv.Itoa(len(s)))
+	b.WriteByte(':')
+	b.WriteString(s)
+}
+
+func writeInt(b *strings.Builder, i int64) {
+	b.WriteByte('d')
+	b.WriteString(strconv.FormatInt(i, 10))
+	b.WriteByte(';')
+}
+
+func writeUint(b *strings.Builder, u uint64) {
+	b.WriteByte('d')
+	b.WriteString(strconv.FormatUint(u, 10))
+	b.WriteByte(';')
+}
+
+func writeFloat(b *strings.Builder, f float64) {
+	// the integral floats have the same key as the integers
+	if f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+		writeInt(b, int64(f))
+		return
+	}
+	b.WriteByte('d')
+	b.WriteString(strconv.FormatFloat(f, 'g', -1, 64))
+	b.WriteByte(';')
+}
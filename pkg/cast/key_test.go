@@ -0,0 +1,66 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cast
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToKey(t *testing.T) {
+	ts := time.UnixMilli(1700000000000)
+	equals := []struct {
+		a any
+		b any
+	}{
+		{a: 1, b: int64(1)},
+		{a: int8(1), b: uint8(1)},
+		{a: uint64(1), b: 1.0},
+		{a: float32(1.5), b: 1.5},
+		{a: nil, b: nil},
+		{a: ts, b: ts.UTC()},
+		{a: map[string]any{"a": 1, "b": "x"}, b: map[string]any{"b": "x", "a": int64(1)}},
+		{a: []any{1, "a"}, b: []any{1.0, "a"}},
+		{a: []map[string]any{{"a": 1}}, b: []map[string]any{{"a": int64(1)}}},
+	}
+	for _, tt := range equals {
+		require.Equal(t, ToKey(tt.a), ToKey(tt.b), "%v and %v", tt.a, tt.b)
+	}
+	diffs := []struct {
+		a any
+		b any
+	}{
+		{a: 1, b: "1"},
+		{a: 1, b: 1.5},
+		{a: true, b: "true"},
+		{a: nil, b: "n"},
+		{a: nil, b: ""},
+		{a: "a", b: []byte("a")},
+		{a: int64(math.MaxInt64), b: uint64(math.MaxInt64) + 1},
+		{a: ts, b: ts.UnixNano()},
+		{a: map[string]any{"a": 1}, b: map[string]any{"a": "1"}},
+		{a: map[string]any{"a": "b", "c": "d"}, b: map[string]any{"a": "b,c:d"}},
+		{a: []any{"a", "b"}, b: []any{"a b"}},
+		{a: []any{"ab", ""}, b: []any{"a", "b"}},
+		{a: []any{}, b: map[string]any{}},
+		{a: struct{ A int }{1}, b: struct{ A string }{"1"}},
+	}
+	for _, tt := range diffs {
+		require.NotEqual(t, ToKey(tt.a), ToKey(tt.b), "%v and %v", tt.a, tt.b)
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright 2021-2025 EMQ Technologies Co., Ltd.
+// Copyright 2021-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -28,6 +28,9 @@ const (
 	FormatUrlEncoded = "urlencoded"
 	FormatXML        = "xml"
 	FormatCustom     = "custom"
+	FormatAvro       = "avro"
+	FormatCbor       = "cbor"
+	FormatMsgpack    = "msgpack"
 
 	DefaultField = "self"
 	MetaKey      = "__meta"
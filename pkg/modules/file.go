@@ -1,4 +1,4 @@
-// Copyright 2024 EMQ Technologies Co., Ltd.
+// Copyright 2024-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -98,3 +98,46 @@ func GetFileStreamDecorator(ctx api.StreamContext, name string) (FileStreamDecor
 	}
 	return nil, false
 }
+
+// FileStreamWriter writes the rows into a type of file which has its own layout like the columnar formats.
+// The rows may be buffered, so the file is only complete after Close.
+type FileStreamWriter interface {
+	// Provision Set up the static properties
+	Provision(ctx api.StreamContext, props map[string]any) error
+	// Bind set the file stream and the schema shared by all the files of the sink. If no columns, they are inferred
+	// from the rows
+	Bind(ctx api.StreamContext, fileStream io.Writer, schema *FileSchema) error
+	// Write a row
+	Write(ctx api.StreamContext, row map[string]any) error
+	// Close flushes the buffered rows and writes the footer. The file stream is not closed
+	api.Closable
+}
+
+// FileColumn is a column of the file stream writer
+type FileColumn struct {
+	Name string
+	// Type is the stream field type such as bigint, float, boolean and string. If empty, it is inferred from the rows
+	Type string
+}
+
+// FileSchema is the ordered columns of the file stream writer. It is shared by all the rolled files of a sink. The
+// columns resolved by the first file are saved and marked as fixed, so that all the files have the same schema.
+type FileSchema struct {
+	Columns []FileColumn
+	Fixed   bool
+}
+
+type FileStreamWriterProvider func(ctx api.StreamContext) FileStreamWriter
+
+var fileStreamWriters = map[string]FileStreamWriterProvider{}
+
+func RegisterFileStreamWriter(name string, provider FileStreamWriterProvider) {
+	fileStreamWriters[name] = provider
+}
+
+func GetFileStreamWriter(ctx api.StreamContext, name string) (FileStreamWriter, bool) {
+	if p, ok := fileStreamWriters[name]; ok {
+		return p(ctx), true
+	}
+	return nil, false
+}
@@ -1,4 +1,4 @@
-// Copyright 2024 EMQ Technologies Co., Ltd.
+// Copyright 2024-2026 EMQ Technologies Co., Ltd.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -15,7 +15,11 @@
 package modules
 
 import (
+	"time"
+
 	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 )
 
 type (
@@ -24,6 +28,33 @@ type (
 	NewSinkFunc         func() api.Sink
 )
 
+// SchemaConsumer is implemented by the sink which needs the output schema of the rule. The schema is tailored by the
+// sink fields. It is nil if it is unknown like selecting wildcard.
+type SchemaConsumer interface {
+	SetSchema(schema map[string]*ast.JsonStreamField)
+}
+
+// CheckpointCommitter is implemented by the rewindable source which acknowledges the consumed data to the upstream
+// only after the data is checkpointed. EnableCheckpointCommit is called with the checkpoint interval before subscribing
+// if the rule enables the checkpoint, otherwise the source should acknowledge the data once ingested. It returns error
+// if the source cannot work with the interval. Commit is called with the offset of each completed checkpoint.
+type CheckpointCommitter interface {
+	EnableCheckpointCommit(checkpointInterval time.Duration) error
+	Commit(ctx api.StreamContext, offset any) error
+}
+
+// TwoPhaseCommitter is implemented by the sink which writes the data of each checkpoint in a transaction to deliver
+// the data exactly once. EnableTwoPhaseCommit is called before connecting if the rule qos is exactly once, otherwise
+// the sink should write the data once collected. PreCommit is called when the sink receives the barrier of a
+// checkpoint to flush the collected data into a transaction. Commit is called after the checkpoint completes to commit
+// the transactions up to it. If either fails, the rule fails and restarts from the last completed checkpoint, so the
+// sink should commit the transactions of the restored checkpoint when connecting.
+type TwoPhaseCommitter interface {
+	EnableTwoPhaseCommit()
+	PreCommit(ctx api.StreamContext, checkpointId int64) error
+	Commit(ctx api.StreamContext, checkpointId int64) error
+}
+
 var (
 	Sources       = map[string]NewSourceFunc{}
 	Sinks         = map[string]NewSinkFunc{}
@@ -26,6 +26,8 @@ func RegisterSchemaType(name string, t SchemaTypeDef, ext string) {
 const (
 	PROTOBUF = "protobuf"
 	CUSTOM   = "custom"
+	AVRO     = "avro"
+	JSON     = "json"
 )
 
 type Files struct {